@@ -24,6 +24,14 @@ func main() {
 		return
 	}
 
+	// plugnmeet-server -config config.yaml migrate up|down [steps]|status
+	if flag.Arg(0) == "migrate" {
+		if err := app.RunMigrateCommand(*configFile, flag.Args()[1:]); err != nil {
+			logrus.WithError(err).Fatal("Migration failed")
+		}
+		return
+	}
+
 	// Read config early to determine if fx.NopLogger should be used
 	isDebug, err := getClientDebugStatus(*configFile)
 	if err != nil {
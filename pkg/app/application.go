@@ -25,9 +25,13 @@ type ApplicationControllers struct {
 	NotepadController      *controllers.SharedNotepadController
 	FileController         *controllers.FileController
 	LtiV1Controller        *controllers.LtiV1Controller
+	LtiV1p3Controller      *controllers.LtiV1p3Controller
 	PollsController        *controllers.PollsController
 	RecordingController    *controllers.RecordingController
 	RoomController         *controllers.RoomController
+	ScheduleController     *controllers.ScheduleController
+	TemplateController     *controllers.TemplateController
+	TenantController       *controllers.TenantController
 	UserController         *controllers.UserController
 	WebhookController      *controllers.WebhookController
 	NatsController         *controllers.NatsController
@@ -38,15 +42,14 @@ type ApplicationControllers struct {
 
 // Application is the root struct holding all dependencies for lifecycle management.
 type Application struct {
-	ctx           context.Context
-	log           *logrus.Entry
-	shutDowner    fx.Shutdowner
-	appConfig     *config.AppConfig
-	router        *Router
-	janitorModel  *models.JanitorModel
-	artifactModel *models.ArtifactModel
-	lkServices    *livekitservice.LivekitService
-	appWg         sync.WaitGroup
+	ctx          context.Context
+	log          *logrus.Entry
+	shutDowner   fx.Shutdowner
+	appConfig    *config.AppConfig
+	router       *Router
+	janitorModel *models.JanitorModel
+	lkServices   *livekitservice.LivekitService
+	appWg        sync.WaitGroup
 }
 
 // NewApplication creates a new Application instance.
@@ -55,20 +58,18 @@ func NewApplication(
 	shutDowner fx.Shutdowner,
 	appConfig *config.AppConfig,
 	janitorModel *models.JanitorModel,
-	artifactModel *models.ArtifactModel,
 	lkServices *livekitservice.LivekitService,
 	router *Router,
 	logger *logrus.Logger,
 ) *Application {
 	return &Application{
-		ctx:           ctx,
-		shutDowner:    shutDowner,
-		appConfig:     appConfig,
-		janitorModel:  janitorModel,
-		artifactModel: artifactModel,
-		lkServices:    lkServices,
-		router:        router,
-		log:           logger.WithField("controller", "Application"),
+		ctx:          ctx,
+		shutDowner:   shutDowner,
+		appConfig:    appConfig,
+		janitorModel: janitorModel,
+		lkServices:   lkServices,
+		router:       router,
+		log:          logger.WithField("controller", "Application"),
 	}
 }
 
@@ -100,12 +101,6 @@ func (a *Application) Start(_ context.Context) error {
 		defer a.appWg.Done()
 		a.janitorModel.StartJanitor()
 	}()
-	// TODO: will remove in future
-	a.appWg.Add(1)
-	go func() {
-		defer a.appWg.Done()
-		a.artifactModel.MigrateAnalyticsToArtifacts()
-	}()
 
 	// Initialize NATS controller.
 	if err := a.router.ctrl.NatsController.Initialize(); err != nil {
@@ -119,6 +114,24 @@ func (a *Application) Start(_ context.Context) error {
 		return err
 	}
 
+	// Initialize LTI controller.
+	if err := a.router.ctrl.LtiV1Controller.Initialize(); err != nil {
+		log.WithError(err).Error("Failed to initialize LTI controller")
+		return err
+	}
+
+	// Initialize Webhook controller.
+	if err := a.router.ctrl.WebhookController.Initialize(); err != nil {
+		log.WithError(err).Error("Failed to initialize Webhook controller")
+		return err
+	}
+
+	// Initialize Recording controller.
+	if err := a.router.ctrl.RecordingController.Initialize(); err != nil {
+		log.WithError(err).Error("Failed to initialize Recording controller")
+		return err
+	}
+
 	// Start the HTTP server in a background goroutine.
 	go func() {
 		log.WithFields(logrus.Fields{
@@ -152,7 +165,9 @@ func (a *Application) Stop(ctx context.Context) error {
 		defer close(done)
 		a.router.ctrl.NatsController.Stop()
 		a.router.ctrl.InsightsController.Shutdown()
+		a.router.ctrl.LtiV1Controller.Shutdown()
 		a.router.ctrl.WebhookController.Shutdown()
+		a.router.ctrl.RecordingController.Shutdown()
 		a.janitorModel.Shutdown()
 	}()
 
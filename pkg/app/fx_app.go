@@ -16,6 +16,7 @@ import (
 	livekitservice "github.com/mynaparrot/plugnmeet-server/pkg/services/livekit"
 	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
 	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+	storageservice "github.com/mynaparrot/plugnmeet-server/pkg/services/storage"
 	turnservice "github.com/mynaparrot/plugnmeet-server/pkg/services/turn"
 	"github.com/sirupsen/logrus"
 	"go.uber.org/fx"
@@ -97,8 +98,9 @@ var ServiceModule = fx.Module("services",
 		natsservice.New,
 		livekitservice.New,
 		turnservice.New,
+		storageservice.New,
 	),
-	fx.Invoke((*dbservice.DatabaseService).AutoMigrate, (*natsservice.NatsService).Initialized),
+	fx.Invoke(runStartupMigrations, (*natsservice.NatsService).Initialized),
 )
 
 var HelperModule = fx.Module("helpers",
@@ -109,9 +111,10 @@ var HelperModule = fx.Module("helpers",
 )
 
 // wireCircularModels is a dedicated Invoke function for wiring circular model dependencies.
-func wireCircularModels(rm *models.RoomModel, bm *models.BreakoutRoomModel, analyticsModel *models.AnalyticsModel, artifactModel *models.ArtifactModel) {
+func wireCircularModels(rm *models.RoomModel, bm *models.BreakoutRoomModel, analyticsModel *models.AnalyticsModel, artifactModel *models.ArtifactModel, ltiGradeModel *models.LtiGradeModel) {
 	rm.SetBreakoutRoomModel(bm)
 	analyticsModel.SetArtifactModel(artifactModel)
+	analyticsModel.SetLtiGradeModel(ltiGradeModel)
 }
 
 var ModelModule = fx.Module("models",
@@ -124,11 +127,16 @@ var ModelModule = fx.Module("models",
 		models.NewSharedNotepadModel,
 		models.NewFileModel,
 		models.NewLtiV1Model,
+		models.NewLtiV1p3Model,
+		models.NewLtiGradeModel,
 		models.NewNatsModel,
 		models.NewPollModel,
 		models.NewRecordingModel,
 		models.NewRoomModel,
 		models.NewBreakoutRoomModel,
+		models.NewScheduleModel,
+		models.NewTemplateModel,
+		models.NewTenantModel,
 		models.NewJanitorModel,
 		models.NewUserModel,
 		models.NewWebhookModel,
@@ -147,9 +155,13 @@ var ControllerModule = fx.Module("controllers",
 		controllers.NewSharedNotepadController,
 		controllers.NewFileController,
 		controllers.NewLtiV1Controller,
+		controllers.NewLtiV1p3Controller,
 		controllers.NewPollsController,
 		controllers.NewRecordingController,
 		controllers.NewRoomController,
+		controllers.NewScheduleController,
+		controllers.NewTemplateController,
+		controllers.NewTenantController,
 		controllers.NewUserController,
 		controllers.NewWebhookController,
 		controllers.NewNatsController,
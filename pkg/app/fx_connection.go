@@ -4,9 +4,11 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
 
+	"github.com/glebarez/sqlite"
 	"github.com/mynaparrot/plugnmeet-protocol/utils"
 	"github.com/mynaparrot/plugnmeet-server/pkg/config"
 	"github.com/nats-io/nats.go"
@@ -15,6 +17,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"go.uber.org/fx"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"gorm.io/plugin/dbresolver"
@@ -23,30 +26,24 @@ import (
 func provideDBConnection(lc fx.Lifecycle, ctx context.Context, appCnf *config.AppConfig, ll *logrus.Logger) (*gorm.DB, error) {
 	log := ll.WithField("method", "provideDBConnection")
 	info := appCnf.DatabaseInfo
-	charset := "utf8mb4"
-	loc := "UTC"
 	connMaxLifetime := time.Minute * 4
 	maxOpenConns := 10
 
-	if info.Charset != nil && *info.Charset != "" {
-		charset = *info.Charset
-	}
-	if info.Loc != nil && *info.Loc != "" {
-		loc = strings.ReplaceAll(*info.Loc, "/", "%2F")
-	}
 	if info.ConnMaxLifetime != nil && *info.ConnMaxLifetime > 0 {
 		connMaxLifetime = *info.ConnMaxLifetime
 	}
 	if info.MaxOpenConns != nil && *info.MaxOpenConns > 0 {
 		maxOpenConns = *info.MaxOpenConns
 	}
+	if info.DriverName == config.DBDriverSQLite {
+		// sqlite allows only one writer at a time
+		maxOpenConns = 1
+	}
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=%s", info.Username, info.Password, info.Host, info.Port, info.DBName, charset, loc)
-
-	mysqlCnf := mysql.Config{
-		DSN: dsn,
+	// TranslateError will give us the same errors, e.g. gorm.ErrDuplicatedKey, for all the drivers
+	cnf := &gorm.Config{
+		TranslateError: true,
 	}
-	cnf := &gorm.Config{}
 
 	loggerCnf := logger.Config{
 		SlowThreshold:             time.Second,
@@ -63,13 +60,15 @@ func provideDBConnection(lc fx.Lifecycle, ctx context.Context, appCnf *config.Ap
 		cnf.Logger = logger.New(ll, loggerCnf)
 	}
 
-	db, err := gorm.Open(mysql.New(mysqlCnf), cnf)
+	db, err := gorm.Open(newDBDialector(&info, info.Host, info.Port, info.Username, info.Password), cnf)
 	if err != nil {
 		log.WithError(err).Error("failed to connect to database")
 		return nil, err
 	}
 
-	if len(info.Replicas) > 0 {
+	if len(info.Replicas) > 0 && info.DriverName == config.DBDriverSQLite {
+		log.Warn("read replicas are not supported with sqlite, ignoring")
+	} else if len(info.Replicas) > 0 {
 		log.Infof("Found %d read replicas, configuring dbresolver", len(info.Replicas))
 		var replicaDialectors []gorm.Dialector
 
@@ -84,8 +83,7 @@ func provideDBConnection(lc fx.Lifecycle, ctx context.Context, appCnf *config.Ap
 				r.Port = info.Port
 			}
 
-			replicaDsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=%s", r.Username, r.Password, r.Host, r.Port, info.DBName, charset, loc)
-			replicaDialectors = append(replicaDialectors, mysql.Open(replicaDsn))
+			replicaDialectors = append(replicaDialectors, newDBDialector(&info, r.Host, r.Port, r.Username, r.Password))
 		}
 		resolverCnf := dbresolver.Config{
 			Replicas: replicaDialectors,
@@ -120,9 +118,16 @@ func provideDBConnection(lc fx.Lifecycle, ctx context.Context, appCnf *config.Ap
 		return nil, err
 	}
 
+	versionQuery := "SELECT VERSION()"
+	if info.DriverName == config.DBDriverSQLite {
+		versionQuery = "SELECT sqlite_version()"
+	}
 	var dbVersion string
-	db.Raw("SELECT VERSION()").Scan(&dbVersion)
-	log.WithField("version", dbVersion).Info("Successfully connected to database")
+	db.Raw(versionQuery).Scan(&dbVersion)
+	log.WithFields(logrus.Fields{
+		"driver":  info.DriverName,
+		"version": dbVersion,
+	}).Info("Successfully connected to database")
 
 	lc.Append(fx.Hook{OnStop: func(_ context.Context) error {
 		log.Info("Closing database connection")
@@ -136,6 +141,56 @@ func provideDBConnection(lc fx.Lifecycle, ctx context.Context, appCnf *config.Ap
 	return db, nil
 }
 
+// newDBDialector returns the gorm dialector for the configured driver.
+// host, port & credentials are separate, so the same can be used for replicas.
+func newDBDialector(info *config.DatabaseInfo, host string, port int32, username, password string) gorm.Dialector {
+	switch info.DriverName {
+	case config.DBDriverPostgres:
+		sslMode := "disable"
+		if info.SslMode != nil && *info.SslMode != "" {
+			sslMode = *info.SslMode
+		}
+		timeZone := "UTC"
+		if info.Loc != nil && *info.Loc != "" {
+			timeZone = *info.Loc
+		}
+		q := url.Values{}
+		q.Set("sslmode", sslMode)
+		q.Set("TimeZone", timeZone)
+
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(username, password),
+			Host:     fmt.Sprintf("%s:%d", host, port),
+			Path:     "/" + info.DBName,
+			RawQuery: q.Encode(),
+		}
+		return postgres.Open(dsn.String())
+
+	case config.DBDriverSQLite:
+		// busy_timeout to wait instead of failing immediately when the db is locked
+		dsn := info.DBName + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+		return sqlite.Open(dsn)
+
+	default:
+		charset := "utf8mb4"
+		loc := "UTC"
+		if info.Charset != nil && *info.Charset != "" {
+			charset = *info.Charset
+		}
+		if info.Loc != nil && *info.Loc != "" {
+			loc = strings.ReplaceAll(*info.Loc, "/", "%2F")
+		}
+		// use datetime without fraction, same as our existing tables
+		datetimePrecision := 0
+
+		return mysql.New(mysql.Config{
+			DSN:                      fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=%s", username, password, host, port, info.DBName, charset, loc),
+			DefaultDatetimePrecision: &datetimePrecision,
+		})
+	}
+}
+
 func provideNATSConnection(lc fx.Lifecycle, appCnf *config.AppConfig, ll *logrus.Logger) (*nats.Conn, error) {
 	log := ll.WithField("method", "provideNATSConnection")
 	info := appCnf.NatsInfo
@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/migrations"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// runStartupMigrations should be run before any service starts to use the DB.
+// If auto_migrate is disabled, it will only make sure nothing is pending.
+func runStartupMigrations(db *gorm.DB, appCnf *config.AppConfig, logger *logrus.Logger) error {
+	log := logger.WithField("method", "runStartupMigrations")
+	m := migrations.New(db, appCnf, logger)
+
+	if appCnf.DatabaseInfo.AutoMigrate != nil && !*appCnf.DatabaseInfo.AutoMigrate {
+		pending, err := m.Pending()
+		if err != nil {
+			log.WithError(err).Error("failed to check database migrations")
+			return err
+		}
+		if pending > 0 {
+			return fmt.Errorf("found %d pending database migrations, please run `plugnmeet-server migrate up` first", pending)
+		}
+		return nil
+	}
+
+	applied, err := m.Up()
+	if err != nil {
+		log.WithError(err).Error("Failed to migrate database")
+		return err
+	}
+	if applied > 0 {
+		log.Infof("applied %d database migrations", applied)
+	}
+	return nil
+}
+
+// RunMigrateCommand handles the `migrate up|down [steps]|status` subcommand.
+// Only the database connection will be initialized, not the full application.
+func RunMigrateCommand(configFile string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate command, usage: migrate up|down [steps]|status")
+	}
+
+	var cmdErr error
+	a := fx.New(
+		fx.NopLogger,
+		fx.Provide(func() (context.Context, context.CancelFunc) {
+			return context.WithCancel(context.Background())
+		}),
+		fx.Supply(configFile),
+		fx.Provide(provideAppConfig, provideLogger, provideDBConnection),
+		fx.Invoke(func(db *gorm.DB, appCnf *config.AppConfig, logger *logrus.Logger) {
+			cmdErr = runMigrateCommand(migrations.New(db, appCnf, logger), args)
+		}),
+	)
+	if err := a.Err(); err != nil {
+		return err
+	}
+	// start & stop, so that the db connection will be closed properly
+	if err := a.Start(context.Background()); err != nil {
+		return err
+	}
+	_ = a.Stop(context.Background())
+
+	return cmdErr
+}
+
+func runMigrateCommand(m *migrations.Migrator, args []string) error {
+	switch args[0] {
+	case "up":
+		applied, err := m.Up()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("applied %d migrations\n", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of steps: %s", args[1])
+			}
+			steps = n
+		}
+		reverted, err := m.Down(steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("reverted %d migrations\n", reverted)
+
+	case "status":
+		list, err := m.Status()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range list {
+			status, appliedAt := "pending", "-"
+			if s.Applied {
+				status = "applied"
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Unknown {
+				status = "unknown"
+			}
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, status, appliedAt)
+		}
+		return w.Flush()
+
+	default:
+		return fmt.Errorf("unknown migrate command '%s', usage: migrate up|down [steps]|status", args[0])
+	}
+
+	return nil
+}
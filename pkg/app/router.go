@@ -125,6 +125,7 @@ func (r *Router) registerBaseRoutes() {
 	r.fiberApp.Post("/webhook", r.ctrl.WebhookController.HandleWebhook)
 	r.fiberApp.Add([]string{"GET", "HEAD"}, "/download/uploadedFile/*", r.ctrl.FileController.HandleDownloadUploadedFile)
 	r.fiberApp.Add([]string{"GET", "HEAD"}, "/download/recording/:token", r.ctrl.RecordingController.HandleDownloadRecording)
+	r.fiberApp.Add([]string{"GET", "HEAD"}, "/download/recording/hls/:token/*", r.ctrl.RecordingController.HandleDownloadRecordingHls)
 	r.fiberApp.Add([]string{"GET", "HEAD"}, "/download/analytics/:token", r.ctrl.AnalyticsController.HandleDownloadAnalytics)
 	r.fiberApp.Add([]string{"GET", "HEAD"}, "/download/artifact/:token", r.ctrl.ArtifactController.HandleDownloadArtifact)
 	r.fiberApp.Use("/healthCheck", r.ctrl.HealthCheckController.HandleHealthCheck)
@@ -141,6 +142,15 @@ func (r *Router) registerLtiRoutes() {
 	ltiV1API.Post("/recording/fetch", r.ctrl.LtiV1Controller.HandleLTIV1FetchRecordings)
 	ltiV1API.Post("/recording/download", r.ctrl.LtiV1Controller.HandleLTIV1GetRecordingDownloadToken)
 	ltiV1API.Post("/recording/delete", r.ctrl.LtiV1Controller.HandleLTIV1DeleteRecordings)
+
+	ltiV1p3 := lti.Group("/v1p3")
+	ltiV1p3.Get("/login", r.ctrl.LtiV1p3Controller.HandleLTIV1p3Login)
+	ltiV1p3.Post("/login", r.ctrl.LtiV1p3Controller.HandleLTIV1p3Login)
+	ltiV1p3.Post("/launch", r.ctrl.LtiV1p3Controller.HandleLTIV1p3Launch)
+	ltiV1p3.Get("/jwks", r.ctrl.LtiV1p3Controller.HandleLTIV1p3JWKS)
+	// same token as LTI 1.1 will be used, so rest of the APIs are available under /lti/v1/api
+	ltiV1p3API := ltiV1p3.Group("/api", r.ctrl.LtiV1Controller.HandleLTIV1VerifyHeaderToken)
+	ltiV1p3API.Post("/memberships", r.ctrl.LtiV1p3Controller.HandleLTIV1p3FetchMemberships)
 }
 
 func (r *Router) registerAuthRoutes() {
@@ -158,6 +168,30 @@ func (r *Router) registerAuthRoutes() {
 	room.Post("/broadcastToRoom", r.ctrl.RoomController.HandleBroadcastToRoom)
 	room.Post("/uploadWhiteboardFile", r.ctrl.FileController.HandleUploadWhiteboardFile)
 
+	schedule := auth.Group("/schedule")
+	schedule.Post("/create", r.ctrl.ScheduleController.HandleCreateSchedule)
+	schedule.Post("/update", r.ctrl.ScheduleController.HandleUpdateSchedule)
+	schedule.Post("/delete", r.ctrl.ScheduleController.HandleDeleteSchedule)
+	schedule.Post("/list", r.ctrl.ScheduleController.HandleListSchedules)
+
+	template := auth.Group("/template")
+	template.Post("/create", r.ctrl.TemplateController.HandleCreateTemplate)
+	template.Post("/update", r.ctrl.TemplateController.HandleUpdateTemplate)
+	template.Post("/delete", r.ctrl.TemplateController.HandleDeleteTemplate)
+	template.Post("/info", r.ctrl.TemplateController.HandleTemplateInfo)
+	template.Post("/list", r.ctrl.TemplateController.HandleListTemplates)
+
+	tenant := auth.Group("/tenant", r.ctrl.TenantController.HandleRootApiKeyOnly)
+	tenant.Post("/create", r.ctrl.TenantController.HandleCreateTenant)
+	tenant.Post("/update", r.ctrl.TenantController.HandleUpdateTenant)
+	tenant.Post("/delete", r.ctrl.TenantController.HandleDeleteTenant)
+	tenant.Post("/list", r.ctrl.TenantController.HandleListTenants)
+
+	apiKey := auth.Group("/apiKey")
+	apiKey.Post("/rotate", r.ctrl.TenantController.HandleRotateSecret)
+	apiKey.Post("/status", r.ctrl.TenantController.HandleSecretStatus)
+	apiKey.Post("/revokePrevious", r.ctrl.TenantController.HandleRevokePreviousSecret)
+
 	recording := auth.Group("/recording")
 	recording.Post("/fetch", r.ctrl.RecordingController.HandleFetchRecordings)
 	recording.Post("/info", r.ctrl.RecordingController.HandleRecordingInfo)
@@ -165,6 +199,7 @@ func (r *Router) registerAuthRoutes() {
 	recording.Post("/mergeRecordings", r.ctrl.RecordingController.HandleMergeRecordings)
 	recording.Post("/delete", r.ctrl.RecordingController.HandleDeleteRecording)
 	recording.Post("/getDownloadToken", r.ctrl.RecordingController.HandleGetDownloadToken)
+	recording.Post("/accessLog", r.ctrl.RecordingController.HandleRecordingAccessLog)
 	// TODO: remove deprecated: use /info
 	recording.Post("/recordingInfo", r.ctrl.RecordingController.HandleRecordingInfo)
 
@@ -180,7 +215,17 @@ func (r *Router) registerAuthRoutes() {
 	artifact.Post("/delete", r.ctrl.ArtifactController.HandleDeleteArtifact)
 	artifact.Post("/getDownloadToken", r.ctrl.ArtifactController.HandleGetArtifactDownloadToken)
 
-	recorder := auth.Group("/recorder")
+	webhook := auth.Group("/webhook")
+	webhook.Post("/deliveries", r.ctrl.WebhookController.HandleFetchWebhookDeliveries)
+	webhook.Post("/redeliver", r.ctrl.WebhookController.HandleRedeliverWebhook)
+	webhookSubscriptions := webhook.Group("/subscriptions")
+	webhookSubscriptions.Post("/create", r.ctrl.WebhookController.HandleCreateWebhookSubscription)
+	webhookSubscriptions.Post("/update", r.ctrl.WebhookController.HandleUpdateWebhookSubscription)
+	webhookSubscriptions.Post("/delete", r.ctrl.WebhookController.HandleDeleteWebhookSubscription)
+	webhookSubscriptions.Post("/info", r.ctrl.WebhookController.HandleWebhookSubscriptionInfo)
+	webhookSubscriptions.Post("/list", r.ctrl.WebhookController.HandleListWebhookSubscriptions)
+
+	recorder := auth.Group("/recorder", r.ctrl.TenantController.HandleRootApiKeyOnly)
 	recorder.Post("/notify", r.ctrl.RecordingController.HandleRecorderEvents)
 }
 
@@ -196,6 +241,13 @@ func (r *Router) registerBBBRoutes() {
 	bbb.All("/deleteRecordings", r.ctrl.BBBController.HandleBBBDeleteRecordings)
 	bbb.All("/updateRecordings", r.ctrl.BBBController.HandleBBBUpdateRecordings)
 	bbb.All("/publishRecordings", r.ctrl.BBBController.HandleBBBPublishRecordings)
+	bbb.All("/insertDocument", r.ctrl.BBBController.HandleBBBInsertDocument)
+	bbb.All("/getRecordingTextTracks", r.ctrl.BBBController.HandleBBBGetRecordingTextTracks)
+	bbb.All("/putRecordingTextTrack", r.ctrl.BBBController.HandleBBBPutRecordingTextTrack)
+	bbb.All("/getDefaultConfigXML", r.ctrl.BBBController.HandleBBBGetDefaultConfigXML)
+	bbb.All("/hooks/create", r.ctrl.BBBController.HandleBBBHooksCreate)
+	bbb.All("/hooks/destroy", r.ctrl.BBBController.HandleBBBHooksDestroy)
+	bbb.All("/hooks/list", r.ctrl.BBBController.HandleBBBHooksList)
 }
 
 func (r *Router) registerAPIRoutes() {
@@ -238,6 +290,9 @@ func (r *Router) registerAPIRoutes() {
 	polls.Get("/pollResponsesResult/:pollId", r.ctrl.PollsController.HandleGetResponsesResult)
 	polls.Post("/submitResponse", r.ctrl.PollsController.HandleUserSubmitResponse)
 	polls.Post("/closePoll", r.ctrl.PollsController.HandleClosePoll)
+	polls.Post("/createAdvanced", r.ctrl.PollsController.HandleCreateAdvancedPoll)
+	polls.Post("/submitAdvancedResponse", r.ctrl.PollsController.HandleUserSubmitAdvancedResponse)
+	polls.Get("/pollsSettings", r.ctrl.PollsController.HandleGetPollsSettings)
 
 	breakoutRoom := api.Group("/breakoutRoom")
 	breakoutRoom.Post("/create", r.ctrl.BreakoutRoomController.HandleCreateBreakoutRooms)
@@ -301,4 +356,9 @@ func (r *Router) registerInsightsRegisterAPIRoutes(api fiber.Router) {
 	aiMeetingSummarization := ai.Group("/meetingSummarization")
 	aiMeetingSummarization.Post("/configure", r.ctrl.InsightsController.HandleAIMeetingSummarizationConfig)
 	aiMeetingSummarization.Post("/end", r.ctrl.InsightsController.HandleEndAIMeetingSummarization)
+
+	aiLiveSummary := ai.Group("/liveSummary")
+	aiLiveSummary.Post("/start", r.ctrl.InsightsController.HandleStartLiveSummary)
+	aiLiveSummary.Post("/end", r.ctrl.InsightsController.HandleEndLiveSummary)
+	aiLiveSummary.Get("/get", r.ctrl.InsightsController.HandleGetLiveSummary)
 }
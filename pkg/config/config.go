@@ -32,32 +32,54 @@ type AppConfig struct {
 	RecorderInfo        RecorderInfo               `yaml:"recorder_info"`
 	AnalyticsSettings   *AnalyticsSettings         `yaml:"analytics_settings"`
 	ArtifactsSettings   *ArtifactsSettings         `yaml:"artifacts_settings"`
+	ScheduleSettings    *ScheduleSettings          `yaml:"schedule_settings"`
+	ChatTranscript      *ChatTranscriptSettings    `yaml:"chat_transcript_settings"`
+	Storage             *StorageConfig             `yaml:"storage"`
 	NatsInfo            NatsInfo                   `yaml:"nats_info"`
 	Insights            *InsightsConfig            `yaml:"insights"`
 	TurnServer          *TurnConfig                `yaml:"turn_server"`
 	Hooks               *Hooks                     `yaml:"hooks"`
+	LtiV1p3             *LtiV1p3Config             `yaml:"lti_v1p3"`
+	LtiGradeSettings    *LtiGradeSettings          `yaml:"lti_grade_settings"`
 }
 
 type ClientInfo struct {
-	Port               int                 `yaml:"port"`
-	Debug              bool                `yaml:"debug"`
-	Path               string              `yaml:"path"`
-	AssetHost          *string             `yaml:"asset_host"`
-	ApiKey             string              `yaml:"api_key"`
-	Secret             string              `yaml:"secret"`
-	TokenValidity      *time.Duration      `yaml:"token_validity"`
-	WebhookConf        WebhookConf         `yaml:"webhook_conf"`
-	PrometheusConf     PrometheusConf      `yaml:"prometheus"`
-	ProxyConf          *ProxyConf          `yaml:"proxy_conf"`
-	CopyrightConf      *CopyrightConf      `yaml:"copyright_conf"`
-	BBBJoinHost        *string             `yaml:"bbb_join_host"`
-	AutoClientDownload *AutoClientDownload `yaml:"auto_client_download"`
+	Port                      int                 `yaml:"port"`
+	Debug                     bool                `yaml:"debug"`
+	Path                      string              `yaml:"path"`
+	AssetHost                 *string             `yaml:"asset_host"`
+	ApiKey                    string              `yaml:"api_key"`
+	Secret                    string              `yaml:"secret"`
+	TokenValidity             *time.Duration      `yaml:"token_validity"`
+	SecretRotationGracePeriod *time.Duration      `yaml:"secret_rotation_grace_period"`
+	WebhookConf               WebhookConf         `yaml:"webhook_conf"`
+	PrometheusConf            PrometheusConf      `yaml:"prometheus"`
+	ProxyConf                 *ProxyConf          `yaml:"proxy_conf"`
+	CopyrightConf             *CopyrightConf      `yaml:"copyright_conf"`
+	BBBJoinHost               *string             `yaml:"bbb_join_host"`
+	AutoClientDownload        *AutoClientDownload `yaml:"auto_client_download"`
 }
 
 type WebhookConf struct {
 	Enable              bool   `yaml:"enable"`
 	Url                 string `yaml:"url,omitempty"`
 	EnableForPerMeeting bool   `yaml:"enable_for_per_meeting"`
+	// secret to sign the requests to the global url, default: api_secret
+	SigningSecret string `yaml:"signing_secret"`
+	// secrets of the endpoints, the url will match the longest endpoint it starts with
+	EndpointSecrets map[string]string `yaml:"endpoint_secrets"`
+	// how many times a delivery will be tried before moving to the dead-letter, default: 8
+	MaxAttempts int `yaml:"max_attempts"`
+	// delay before the first retry, it will be doubled on each try, default: 10s
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	// maximum delay between the tries, default: 1h
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+	// timeout of a single request, default: 10s
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+	// delivery logs older than this will be removed, default: 168h (7 days)
+	DeliveryLogRetention time.Duration `yaml:"delivery_log_retention"`
+	// allow the urls of the API users to point to private networks, default: false
+	AllowPrivateUrls bool `yaml:"allow_private_urls"`
 }
 
 type PrometheusConf struct {
@@ -101,11 +123,63 @@ type RecorderInfo struct {
 	RecordingFilesPath string `yaml:"recording_files_path"`
 	// How long generated token will valid to download the file
 	TokenValidity time.Duration `yaml:"token_validity"`
+	// How long to keep the download & playback logs of the recordings. default: 2160h (90 days)
+	AccessLogRetention time.Duration `yaml:"access_log_retention"`
 	// How long to wait before considering a recorder inactive. default: 8 seconds
 	PingTimeout                time.Duration `yaml:"ping_timeout"`
 	EnableDelRecordingBackup   bool          `yaml:"enable_del_recording_backup"`
 	DelRecordingBackupPath     string        `yaml:"del_recording_backup_path"`
 	DelRecordingBackupDuration time.Duration `yaml:"del_recording_backup_duration"`
+	// Generate additional renditions of the recordings using ffmpeg
+	PostProcessing *RecordingPostProcessing `yaml:"post_processing"`
+	// Generate chapters & speaker timeline of the recordings at the end of the session
+	EnableChapters bool `yaml:"enable_chapters"`
+	// Delete the recordings automatically, can be overridden per tenant & room template
+	Retention *RecordingRetention `yaml:"retention"`
+	// Queue the recording requests when there is no free recorder
+	Queue *RecorderQueue `yaml:"queue"`
+}
+
+// RecorderQueue keeps the start requests of recording & RTMP in NATS
+// until a recorder with free capacity is available.
+type RecorderQueue struct {
+	Enabled bool `yaml:"enabled"`
+	// the request will be dropped if no recorder became free within this time. default: 30m
+	MaxWaitTime time.Duration `yaml:"max_wait_time"`
+}
+
+// RecordingRetention is the default number of days to keep the recordings.
+type RecordingRetention struct {
+	Enabled bool `yaml:"enabled"`
+	// 0 means keep forever
+	PublishedDays   int `yaml:"published_days"`
+	UnpublishedDays int `yaml:"unpublished_days"`
+	// recording_expiring webhook will be sent before these many days. default: 7
+	NotifyBeforeDays int `yaml:"notify_before_days"`
+}
+
+// RecordingPostProcessing runs ffmpeg jobs on the proceeded recordings.
+// Jobs are shared between all the servers using the same NATS.
+type RecordingPostProcessing struct {
+	Enabled bool `yaml:"enabled"`
+	// default: ffmpeg
+	FfmpegPath string `yaml:"ffmpeg_path"`
+	// generate a poster image
+	EnableThumbnail bool `yaml:"enable_thumbnail"`
+	// audio only rendition: mp3 or opus, empty to disable
+	AudioFormat string `yaml:"audio_format"`
+	// package the recording as HLS with multiple variants for adaptive playback
+	EnableHls bool `yaml:"enable_hls"`
+	// heights of the HLS variants. default: [720, 360]
+	HlsVariants []int `yaml:"hls_variants"`
+	// default: 6s
+	HlsSegmentDuration time.Duration `yaml:"hls_segment_duration"`
+	// maximum number of jobs running at the same time in this server. default: 2
+	MaxWorkers int `yaml:"max_workers"`
+	// a running job will be killed after this duration. default: 2h
+	JobTimeout time.Duration `yaml:"job_timeout"`
+	// default: 3
+	MaxAttempts int `yaml:"max_attempts"`
 }
 
 type AnalyticsSettings struct {
@@ -122,6 +196,23 @@ type ArtifactsSettings struct {
 	DelArtifactsBackupDuration time.Duration  `yaml:"del_artifacts_backup_duration"`
 }
 
+type ScheduleSettings struct {
+	// How long before the scheduled start time the room should be created. default: 5 minutes
+	CreateLeadTime time.Duration `yaml:"create_lead_time"`
+}
+
+// ChatTranscriptSettings to store the chat messages of the session as artifact.
+// Rooms need to opt in using "chat_transcript": "true" in metadata.extra_data,
+// unless enabled_for_all_rooms is true.
+type ChatTranscriptSettings struct {
+	Enabled            bool `yaml:"enabled"`
+	EnabledForAllRooms bool `yaml:"enabled_for_all_rooms"`
+	// private messages won't be stored if true
+	ExcludePrivateMessages bool `yaml:"exclude_private_messages"`
+	// messages will be removed after this time if the room wasn't ended properly, default: 24h
+	MaxAge time.Duration `yaml:"max_age"`
+}
+
 type CopyrightConf struct {
 	Display       bool   `yaml:"display"`
 	AllowOverride bool   `yaml:"allow_override"`
@@ -129,18 +220,23 @@ type CopyrightConf struct {
 }
 
 type DatabaseInfo struct {
-	DriverName      string          `yaml:"driver_name"`
-	Host            string          `yaml:"host"`
-	Port            int32           `yaml:"port"`
-	Username        string          `yaml:"username"`
-	Password        string          `yaml:"password"`
+	// mysql, postgres or sqlite, default: mysql
+	DriverName string `yaml:"driver_name"`
+	Host       string `yaml:"host"`
+	Port       int32  `yaml:"port"`
+	Username   string `yaml:"username"`
+	Password   string `yaml:"password"`
+	// for sqlite, this will be the path of the database file
 	DBName          string          `yaml:"db"`
 	Prefix          string          `yaml:"prefix"`
 	Charset         *string         `yaml:"charset"`
 	Loc             *string         `yaml:"loc"`
+	SslMode         *string         `yaml:"ssl_mode"`
 	ConnMaxLifetime *time.Duration  `yaml:"conn_max_lifetime"`
 	MaxOpenConns    *int            `yaml:"max_open_conns"`
 	Replicas        []ReplicaDBInfo `yaml:"replicas"`
+	// apply pending migrations during startup, default: true
+	AutoMigrate *bool `yaml:"auto_migrate"`
 }
 
 // ReplicaDBInfo holds connection details for a read replica database.
@@ -200,6 +296,9 @@ func InitAppConfig(appCnf *AppConfig) (*AppConfig, error) {
 	if appCnf.Client.TokenValidity == nil || *appCnf.Client.TokenValidity < 0 {
 		appCnf.Client.TokenValidity = new(10 * time.Minute)
 	}
+	if appCnf.Client.SecretRotationGracePeriod == nil || *appCnf.Client.SecretRotationGracePeriod < 0 {
+		appCnf.Client.SecretRotationGracePeriod = new(24 * time.Hour)
+	}
 
 	if appCnf.NatsInfo.RoomStreamName == "" {
 		appCnf.NatsInfo.RoomStreamName = "pnm-room-stream"
@@ -250,12 +349,43 @@ func InitAppConfig(appCnf *AppConfig) (*AppConfig, error) {
 	if appCnf.RecorderInfo.PingTimeout == 0 {
 		appCnf.RecorderInfo.PingTimeout = time.Second * 8
 	}
+	if appCnf.RecorderInfo.AccessLogRetention <= 0 {
+		appCnf.RecorderInfo.AccessLogRetention = time.Hour * 2160
+	}
+
+	handleRecordingPostProcessingSettings(appCnf)
+	handleRecordingRetentionSettings(appCnf)
+	handleRecorderQueueSettings(appCnf)
+	handleWebhookSettings(appCnf)
+
+	if appCnf.ScheduleSettings == nil {
+		appCnf.ScheduleSettings = &ScheduleSettings{}
+	}
+	if appCnf.ScheduleSettings.CreateLeadTime <= 0 {
+		appCnf.ScheduleSettings.CreateLeadTime = time.Minute * 5
+	}
+
+	if appCnf.ChatTranscript == nil {
+		appCnf.ChatTranscript = &ChatTranscriptSettings{}
+	}
+	if appCnf.ChatTranscript.MaxAge <= 0 {
+		appCnf.ChatTranscript.MaxAge = time.Hour * 24
+	}
 
 	// setup everything for artifacts
 	if err := handleArtifactsSettings(appCnf); err != nil {
 		return nil, err
 	}
 
+	if err := handleStorageSettings(appCnf); err != nil {
+		return nil, err
+	}
+
+	if err := handleLtiV1p3Settings(appCnf); err != nil {
+		return nil, err
+	}
+	handleLtiGradeSettings(appCnf)
+
 	// set default
 	if appCnf.RecorderInfo.EnableDelRecordingBackup {
 		if appCnf.RecorderInfo.DelRecordingBackupDuration == 0 {
@@ -275,6 +405,20 @@ func InitAppConfig(appCnf *AppConfig) (*AppConfig, error) {
 	if appCnf.DatabaseInfo.Prefix != "" {
 		dbTablePrefix = appCnf.DatabaseInfo.Prefix
 	}
+	switch appCnf.DatabaseInfo.DriverName {
+	case "":
+		appCnf.DatabaseInfo.DriverName = DBDriverMySQL
+	case DBDriverMySQL, DBDriverPostgres:
+	case DBDriverSQLite:
+		if appCnf.DatabaseInfo.DBName == "" {
+			return nil, fmt.Errorf("database_info: db is required as file path for sqlite")
+		}
+		if !filepath.IsAbs(appCnf.DatabaseInfo.DBName) && appCnf.DatabaseInfo.DBName != ":memory:" {
+			appCnf.DatabaseInfo.DBName = filepath.Join(appCnf.RootWorkingDir, appCnf.DatabaseInfo.DBName)
+		}
+	default:
+		return nil, fmt.Errorf("database_info: unsupported driver_name '%s', allowed: mysql, postgres, sqlite", appCnf.DatabaseInfo.DriverName)
+	}
 	if appCnf.NatsInfo.Recorder.TranscodingJobs == "" {
 		appCnf.NatsInfo.Recorder.TranscodingJobs = "pnm-RecorderTranscoderJobs"
 	}
@@ -318,6 +462,75 @@ func InitAppConfig(appCnf *AppConfig) (*AppConfig, error) {
 	return appCnf, nil
 }
 
+func handleRecordingPostProcessingSettings(appCnf *AppConfig) {
+	if appCnf.RecorderInfo.PostProcessing == nil {
+		appCnf.RecorderInfo.PostProcessing = &RecordingPostProcessing{}
+	}
+	pp := appCnf.RecorderInfo.PostProcessing
+	if pp.FfmpegPath == "" {
+		pp.FfmpegPath = "ffmpeg"
+	}
+	pp.AudioFormat = strings.ToLower(pp.AudioFormat)
+	if pp.AudioFormat != "" && pp.AudioFormat != "mp3" && pp.AudioFormat != "opus" {
+		pp.AudioFormat = "mp3"
+	}
+	if len(pp.HlsVariants) == 0 {
+		pp.HlsVariants = []int{720, 360}
+	}
+	if pp.HlsSegmentDuration <= 0 {
+		pp.HlsSegmentDuration = time.Second * 6
+	}
+	if pp.MaxWorkers <= 0 {
+		pp.MaxWorkers = 2
+	}
+	if pp.JobTimeout <= 0 {
+		pp.JobTimeout = time.Hour * 2
+	}
+	if pp.MaxAttempts <= 0 {
+		pp.MaxAttempts = 3
+	}
+}
+
+func handleRecordingRetentionSettings(appCnf *AppConfig) {
+	if appCnf.RecorderInfo.Retention == nil {
+		appCnf.RecorderInfo.Retention = &RecordingRetention{}
+	}
+	rt := appCnf.RecorderInfo.Retention
+	rt.PublishedDays = max(rt.PublishedDays, 0)
+	rt.UnpublishedDays = max(rt.UnpublishedDays, 0)
+	if rt.NotifyBeforeDays <= 0 {
+		rt.NotifyBeforeDays = 7
+	}
+}
+
+func handleRecorderQueueSettings(appCnf *AppConfig) {
+	if appCnf.RecorderInfo.Queue == nil {
+		appCnf.RecorderInfo.Queue = &RecorderQueue{}
+	}
+	if appCnf.RecorderInfo.Queue.MaxWaitTime <= 0 {
+		appCnf.RecorderInfo.Queue.MaxWaitTime = time.Minute * 30
+	}
+}
+
+func handleWebhookSettings(appCnf *AppConfig) {
+	wc := &appCnf.Client.WebhookConf
+	if wc.MaxAttempts <= 0 {
+		wc.MaxAttempts = 8
+	}
+	if wc.InitialBackoff <= 0 {
+		wc.InitialBackoff = time.Second * 10
+	}
+	if wc.MaxBackoff <= 0 {
+		wc.MaxBackoff = time.Hour
+	}
+	if wc.RequestTimeout <= 0 {
+		wc.RequestTimeout = time.Second * 10
+	}
+	if wc.DeliveryLogRetention <= 0 {
+		wc.DeliveryLogRetention = time.Hour * 168
+	}
+}
+
 func handleArtifactsSettings(appCnf *AppConfig) error {
 	// Add initialization logic for ArtifactsSettings
 	if appCnf.ArtifactsSettings == nil {
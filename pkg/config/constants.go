@@ -19,6 +19,11 @@ const (
 	// the LiveKit identity of their publish-only native twin: "[userID]-native".
 	NativeTwinIdentitySuffix = "-native"
 
+	// supported database drivers
+	DBDriverMySQL    = "mysql"
+	DBDriverPostgres = "postgres"
+	DBDriverSQLite   = "sqlite"
+
 	// all the time.Sleep() values
 	WaitBeforeTriggerOnAfterRoomEnded      = 10 * time.Second
 	WaitBeforeAnalyticsStartProcessing     = 50 * time.Second
@@ -17,4 +17,15 @@ var (
 	NoBreakoutRoomsFound           = errors.New("no breakout rooms found")
 	InvalidNilRoomMetadata         = errors.New("invalid nil room metadata information")
 	ErrRequestedRecordingsNotFound = errors.New("one or more of the requested recordings were not found or did not match the room_id")
+	ErrTenantAccessDenied          = errors.New("requested resource doesn't belong to this api key")
+	ErrTenantRoomsQuotaExceeded    = errors.New("maximum number of concurrent rooms for this tenant has been reached")
+	ErrTenantUsersQuotaExceeded    = errors.New("maximum number of concurrent participants for this tenant has been reached")
+	ErrLtiV1p3NotEnabled           = errors.New("lti 1.3 isn't enabled")
+	ErrLtiV1p3UnknownPlatform      = errors.New("unknown lti 1.3 platform")
+	ErrLtiV1p3InvalidState         = errors.New("invalid or expired lti 1.3 state")
+	ErrLtiV1p3ServiceNotAvailable  = errors.New("requested lti service isn't available for this launch")
+	ErrBBBHooksNotEnabled          = errors.New("per-meeting webhooks are not enabled")
+	ErrNoRecorderAvailable         = errors.New("notifications.no-recorder-available")
+	ErrRecorderTaskQueued          = errors.New("notifications.recorder-task-queued")
+	ErrInsightsBudgetExceeded      = errors.New("insights.budget-exceeded")
 )
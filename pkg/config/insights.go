@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
 )
@@ -14,6 +15,8 @@ const (
 	ProviderAzure  ProviderType = "azure"
 	ProviderGoogle ProviderType = "google"
 	ProviderOpenAI ProviderType = "openai"
+	// ProviderLocal uses self-hosted servers, so no data leaves the infrastructure
+	ProviderLocal ProviderType = "local"
 )
 
 // ModelPricing holds pricing information for a service.
@@ -31,6 +34,93 @@ type InsightsConfig struct {
 	// The key is the provider type ("azure", "google", "openai"), the value is a list of accounts.
 	Providers map[ProviderType][]ProviderAccount      `yaml:"providers"`
 	Services  map[insights.ServiceType]*ServiceConfig `yaml:"services"`
+	// Failover controls the circuit breaker of the accounts used by the services
+	Failover *InsightsFailover `yaml:"failover"`
+	// Budgets caps the usage of the services, nil means unlimited
+	Budgets *InsightsBudgets `yaml:"budgets"`
+}
+
+// InsightsBudget caps the usage of the insights services, 0 means unlimited.
+// Cost is calculated using the pricing of the services.
+type InsightsBudget struct {
+	TranscriptionMinutes int64   `yaml:"transcription_minutes" json:"transcription_minutes,omitempty"`
+	Tokens               int64   `yaml:"tokens" json:"tokens,omitempty"`
+	Characters           int64   `yaml:"characters" json:"characters,omitempty"`
+	Cost                 float64 `yaml:"cost" json:"cost,omitempty"`
+}
+
+// IsUnlimited returns true if none of the caps was set
+func (b *InsightsBudget) IsUnlimited() bool {
+	return b == nil || (b.TranscriptionMinutes <= 0 && b.Tokens <= 0 && b.Characters <= 0 && b.Cost <= 0)
+}
+
+// InsightsBudgets are the default budgets, a room can lower its budget using metadata extra_data
+// & a tenant using the tenant api.
+type InsightsBudgets struct {
+	// moderators will be warned once the usage reaches this percentage of a cap. default: 80
+	WarningPercent int `yaml:"warning_percent"`
+	// interval to check the running transcription sessions. default: 30s
+	CheckInterval time.Duration `yaml:"check_interval"`
+	// Room is the budget of every room
+	Room *InsightsBudget `yaml:"room"`
+	// Tenant is the budget of every tenant per calendar month (UTC)
+	Tenant *InsightsBudget `yaml:"tenant"`
+}
+
+// GetBudgets returns the budgets, nil if insights or budgets weren't configured
+func (c *InsightsConfig) GetBudgets() *InsightsBudgets {
+	if c == nil {
+		return nil
+	}
+	return c.Budgets
+}
+
+// GetWarningPercent returns the warning percent with its default
+func (b *InsightsBudgets) GetWarningPercent() int {
+	if b == nil || b.WarningPercent <= 0 || b.WarningPercent > 100 {
+		return 80
+	}
+	return b.WarningPercent
+}
+
+// GetCheckInterval returns the check interval with its default
+func (b *InsightsBudgets) GetCheckInterval() time.Duration {
+	if b == nil || b.CheckInterval <= 0 {
+		return 30 * time.Second
+	}
+	return b.CheckInterval
+}
+
+const (
+	// ServiceStrategyFailover uses the accounts in the order of the list
+	ServiceStrategyFailover = "failover"
+	// ServiceStrategyRoundRobin spreads the load between the accounts
+	ServiceStrategyRoundRobin = "round_robin"
+)
+
+// InsightsFailover defines when an account is considered unhealthy.
+// The health is shared between all the servers using Redis.
+type InsightsFailover struct {
+	// consecutive errors to open the circuit of the account. default: 3
+	FailureThreshold int `yaml:"failure_threshold"`
+	// the account won't be used during this time, unless all others are unhealthy too. default: 1m
+	OpenDuration time.Duration `yaml:"open_duration"`
+}
+
+// GetFailureThreshold returns the threshold with its default
+func (f *InsightsFailover) GetFailureThreshold() int {
+	if f == nil || f.FailureThreshold <= 0 {
+		return 3
+	}
+	return f.FailureThreshold
+}
+
+// GetOpenDuration returns the open duration with its default
+func (f *InsightsFailover) GetOpenDuration() time.Duration {
+	if f == nil || f.OpenDuration <= 0 {
+		return time.Minute
+	}
+	return f.OpenDuration
 }
 
 // ProviderAccount defines a single, uniquely identified set of credentials for a provider.
@@ -56,6 +146,26 @@ type ServiceConfig struct {
 	ID       string                  `yaml:"id"`
 	Options  map[string]interface{}  `yaml:"options"` // Generic options, e.g., model
 	Pricing  map[string]ModelPricing `yaml:"pricing"`
+	// Accounts is an ordered list of provider accounts to fail over or balance the load.
+	// If empty, Provider & ID will be used.
+	Accounts []ServiceAccount `yaml:"accounts"`
+	// Strategy is failover (default) or round_robin
+	Strategy string `yaml:"strategy"`
+}
+
+// ServiceAccount is one of the accounts of a service
+type ServiceAccount struct {
+	Provider ProviderType `yaml:"provider"`
+	ID       string       `yaml:"id"`
+	// Options override the options of the service for this account, e.g., model of the provider
+	Options map[string]interface{} `yaml:"options"`
+}
+
+// ServiceCandidate is an account which can serve the service.
+// Service is a copy of the service config with the provider, id & options of this account.
+type ServiceCandidate struct {
+	Account *ProviderAccount
+	Service *ServiceConfig
 }
 
 // GetOptionsString is a helper to safely get a string value from the generic options map.
@@ -86,6 +196,23 @@ func (sc *ServiceConfig) GetIntOption(key string, fallback int) int {
 	return value
 }
 
+// GetBoolOption accepts a YAML bool or a string like "true"
+func (sc *ServiceConfig) GetBoolOption(key string, fallback bool) bool {
+	if sc.Options == nil {
+		return fallback
+	}
+
+	switch v := sc.Options[key].(type) {
+	case bool:
+		return v
+	case string:
+		if value, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			return value
+		}
+	}
+	return fallback
+}
+
 // GetVoiceMappings safely extracts the voice mappings from the generic options map.
 func (sc *ServiceConfig) GetVoiceMappings() map[string]string {
 	mappings := make(map[string]string)
@@ -112,27 +239,72 @@ type CredentialsConfig struct {
 }
 
 // GetProviderAccountForService is a helper to find the correct provider account configuration for a given service.
+// If the service has multiple accounts, the first one will be returned.
 func (c *InsightsConfig) GetProviderAccountForService(serviceType insights.ServiceType) (*ProviderAccount, *ServiceConfig, error) {
+	candidates, err := c.GetServiceCandidates(serviceType)
+	if err != nil {
+		return nil, nil, err
+	}
+	return candidates[0].Account, candidates[0].Service, nil
+}
+
+// GetServiceCandidates returns all the accounts of the service in the configured order.
+func (c *InsightsConfig) GetServiceCandidates(serviceType insights.ServiceType) ([]*ServiceCandidate, error) {
 	// 1. Get the service configuration
 	serviceConfig, configOk := c.Services[serviceType]
 	if !configOk {
-		return nil, nil, fmt.Errorf("service '%s' is not defined in config", serviceType)
+		return nil, fmt.Errorf("service '%s' is not defined in config", serviceType)
+	}
+
+	if len(serviceConfig.Accounts) == 0 {
+		account, err := c.findProviderAccount(serviceType, serviceConfig.Provider, serviceConfig.ID)
+		if err != nil {
+			return nil, err
+		}
+		return []*ServiceCandidate{{Account: account, Service: serviceConfig}}, nil
 	}
 
-	// 2. Get the list of accounts for the provider type
-	providerAccounts, providerOk := c.Providers[serviceConfig.Provider]
+	candidates := make([]*ServiceCandidate, 0, len(serviceConfig.Accounts))
+	for _, sa := range serviceConfig.Accounts {
+		account, err := c.findProviderAccount(serviceType, sa.Provider, sa.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		sc := &ServiceConfig{
+			Provider: sa.Provider,
+			ID:       sa.ID,
+			Options:  make(map[string]interface{}, len(serviceConfig.Options)+len(sa.Options)),
+			Pricing:  serviceConfig.Pricing,
+			Strategy: serviceConfig.Strategy,
+		}
+		for k, v := range serviceConfig.Options {
+			sc.Options[k] = v
+		}
+		for k, v := range sa.Options {
+			sc.Options[k] = v
+		}
+		candidates = append(candidates, &ServiceCandidate{Account: account, Service: sc})
+	}
+
+	return candidates, nil
+}
+
+func (c *InsightsConfig) findProviderAccount(serviceType insights.ServiceType, provider ProviderType, id string) (*ProviderAccount, error) {
+	// Get the list of accounts for the provider type
+	providerAccounts, providerOk := c.Providers[provider]
 	if !providerOk {
-		return nil, nil, fmt.Errorf("provider '%s' (referenced by service '%s') is not defined in config", serviceConfig.Provider, serviceType)
+		return nil, fmt.Errorf("provider '%s' (referenced by service '%s') is not defined in config", provider, serviceType)
 	}
 
-	// 3. Find the specific account within the list by its ID.
+	// Find the specific account within the list by its ID.
 	for i := range providerAccounts {
-		if providerAccounts[i].ID == serviceConfig.ID {
-			return &providerAccounts[i], serviceConfig, nil
+		if providerAccounts[i].ID == id {
+			return &providerAccounts[i], nil
 		}
 	}
 
-	return nil, nil, fmt.Errorf("account with id '%s' not found for provider '%s'", serviceConfig.ID, serviceConfig.Provider)
+	return nil, fmt.Errorf("account with id '%s' not found for provider '%s'", id, provider)
 }
 
 // GetServiceModelPricing is a helper to get pricing for a specific model within a service.
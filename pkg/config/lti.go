@@ -0,0 +1,155 @@
+package config
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// LtiV1p3Config holds the settings for LTI 1.3 (LTI Advantage) launches.
+type LtiV1p3Config struct {
+	Enabled bool `yaml:"enabled"`
+	// PEM encoded RSA private key of the tool. It will be used to sign
+	// deep linking responses & the client assertions of service requests.
+	// The public key will be available at /lti/v1p3/jwks
+	PrivateKeyFile string            `yaml:"private_key_file"`
+	Platforms      []LtiV1p3Platform `yaml:"platforms"`
+
+	PrivateKey *rsa.PrivateKey `yaml:"-"`
+	KeyId      string          `yaml:"-"`
+}
+
+// LtiV1p3Platform is a registered LMS, e.g. Moodle, Canvas or Blackboard.
+type LtiV1p3Platform struct {
+	Issuer   string `yaml:"issuer"`
+	ClientId string `yaml:"client_id"`
+	// if empty, all the deployments of this client will be accepted
+	DeploymentIds []string `yaml:"deployment_ids"`
+	// OIDC authentication request url of the platform
+	AuthLoginUrl string `yaml:"auth_login_url"`
+	// OAuth2 access token url of the platform, required for NRPS & AGS
+	AuthTokenUrl string `yaml:"auth_token_url"`
+	// audience of the client assertion, default: auth_token_url
+	AuthTokenAudience string `yaml:"auth_token_audience"`
+	// public keys of the platform to verify the id_token
+	KeySetUrl string `yaml:"key_set_url"`
+}
+
+// LtiGradeSettings to send the attendance of LTI users back to the LMS as score,
+// using LTI 1.1 Basic Outcomes or LTI 1.3 Assignment and Grade Services.
+// analytics_settings must be enabled as attendance will be calculated from the analytics events.
+type LtiGradeSettings struct {
+	Enabled bool `yaml:"enabled"`
+	// how many times a failed submission will be tried, default: 5
+	MaxRetries int `yaml:"max_retries"`
+	// delay between the tries, default: 5 minutes
+	RetryDelay time.Duration `yaml:"retry_delay"`
+}
+
+// GetPlatform returns the registered platform by issuer & client id.
+// clientId is optional as platforms may not send it during login initiation.
+func (l *LtiV1p3Config) GetPlatform(issuer, clientId string) *LtiV1p3Platform {
+	for i := range l.Platforms {
+		p := &l.Platforms[i]
+		if p.Issuer == issuer && (clientId == "" || p.ClientId == clientId) {
+			return p
+		}
+	}
+	return nil
+}
+
+// IsValidDeployment checks if the deployment is allowed for this platform.
+func (p *LtiV1p3Platform) IsValidDeployment(deploymentId string) bool {
+	if len(p.DeploymentIds) == 0 {
+		return deploymentId != ""
+	}
+	for _, id := range p.DeploymentIds {
+		if id == deploymentId {
+			return true
+		}
+	}
+	return false
+}
+
+func handleLtiV1p3Settings(appCnf *AppConfig) error {
+	l := appCnf.LtiV1p3
+	if l == nil || !l.Enabled {
+		return nil
+	}
+	if l.PrivateKeyFile == "" {
+		return fmt.Errorf("lti_v1p3: private_key_file is required")
+	}
+
+	for i, p := range l.Platforms {
+		if p.Issuer == "" || p.ClientId == "" || p.AuthLoginUrl == "" || p.KeySetUrl == "" {
+			return fmt.Errorf("lti_v1p3: issuer, client_id, auth_login_url & key_set_url are required for platform #%d", i+1)
+		}
+		if p.AuthTokenAudience == "" {
+			l.Platforms[i].AuthTokenAudience = p.AuthTokenUrl
+		}
+	}
+
+	keyFile := l.PrivateKeyFile
+	if !filepath.IsAbs(keyFile) {
+		keyFile = filepath.Join(appCnf.RootWorkingDir, keyFile)
+	}
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return fmt.Errorf("lti_v1p3: failed to read private key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("lti_v1p3: invalid PEM data in %s", keyFile)
+	}
+
+	var key *rsa.PrivateKey
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		var k any
+		k, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err == nil {
+			var ok bool
+			if key, ok = k.(*rsa.PrivateKey); !ok {
+				err = fmt.Errorf("only RSA keys are supported")
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("lti_v1p3: failed to parse private key: %w", err)
+	}
+
+	// kid will be the thumbprint of the public key, so it changes with the key
+	jwk := jose.JSONWebKey{Key: &key.PublicKey}
+	thumb, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("lti_v1p3: %w", err)
+	}
+
+	l.PrivateKey = key
+	l.KeyId = base64.RawURLEncoding.EncodeToString(thumb)
+
+	return nil
+}
+
+func handleLtiGradeSettings(appCnf *AppConfig) {
+	if appCnf.LtiGradeSettings == nil {
+		appCnf.LtiGradeSettings = &LtiGradeSettings{}
+	}
+	if appCnf.LtiGradeSettings.MaxRetries <= 0 {
+		appCnf.LtiGradeSettings.MaxRetries = 5
+	}
+	if appCnf.LtiGradeSettings.RetryDelay <= 0 {
+		appCnf.LtiGradeSettings.RetryDelay = time.Minute * 5
+	}
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	StorageDriverLocal = "local"
+	StorageDriverS3    = "s3"
+)
+
+// StorageConfig selects where recordings, artifacts and uploaded files will be kept.
+type StorageConfig struct {
+	// local or s3, default: local
+	Driver string           `yaml:"driver"`
+	S3     *S3StorageConfig `yaml:"s3"`
+}
+
+// S3StorageConfig holds the settings for any S3 compatible storage, e.g. AWS S3, MinIO etc.
+type S3StorageConfig struct {
+	Endpoint  string `yaml:"endpoint"`
+	Region    string `yaml:"region"`
+	Bucket    string `yaml:"bucket"`
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `yaml:"secret_key"`
+	// MinIO & most of the self-hosted services need path style
+	UsePathStyle bool `yaml:"use_path_style"`
+	// optional prefix for all the object keys
+	Prefix string `yaml:"prefix"`
+	// How long a presigned download url will be valid, default: 10 minutes
+	PresignedUrlExpiry time.Duration `yaml:"presigned_url_expiry"`
+}
+
+func handleStorageSettings(appCnf *AppConfig) error {
+	if appCnf.Storage == nil {
+		appCnf.Storage = &StorageConfig{}
+	}
+	if appCnf.Storage.Driver == "" {
+		appCnf.Storage.Driver = StorageDriverLocal
+	}
+
+	switch appCnf.Storage.Driver {
+	case StorageDriverLocal:
+		return nil
+	case StorageDriverS3:
+		s3 := appCnf.Storage.S3
+		if s3 == nil || s3.Endpoint == "" || s3.Bucket == "" {
+			return fmt.Errorf("storage: s3 endpoint & bucket are required")
+		}
+		if s3.AccessKey == "" || s3.SecretKey == "" {
+			return fmt.Errorf("storage: s3 access_key & secret_key are required")
+		}
+		if s3.Region == "" {
+			s3.Region = "us-east-1"
+		}
+		if s3.PresignedUrlExpiry <= 0 {
+			s3.PresignedUrlExpiry = time.Minute * 10
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown storage driver: '%s'", appCnf.Storage.Driver)
+}
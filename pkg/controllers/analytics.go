@@ -68,7 +68,7 @@ func (ac *AnalyticsController) HandleFetchAnalytics(c fiber.Ctx) error {
 		return utils.SendCommonProtoJsonResponse(c, false, err.Error(), plugnmeet.StatusCode_INVALID_PARAMETERS)
 	}
 
-	result, err := ac.AnalyticsModel.FetchAnalytics(req)
+	result, err := ac.AnalyticsModel.FetchAnalytics(getTenantId(c), req)
 	if err != nil {
 		if errors.Is(err, config.NotFoundErr) {
 			return utils.SendCommonProtoJsonResponse(c, false, "no artifact found", plugnmeet.StatusCode_NOT_FOUND)
@@ -37,7 +37,7 @@ func (ac *ArtifactController) HandleFetchArtifacts(c fiber.Ctx) error {
 		return utils.SendCommonProtoJsonResponse(c, false, err.Error(), plugnmeet.StatusCode_INVALID_PARAMETERS)
 	}
 
-	result, err := ac.ArtifactModel.FetchArtifacts(req)
+	result, err := ac.ArtifactModel.FetchArtifacts(getTenantId(c), req)
 	if err != nil {
 		if errors.Is(err, config.NotFoundErr) {
 			return utils.SendCommonProtoJsonResponse(c, false, "no artifact found", plugnmeet.StatusCode_NOT_FOUND)
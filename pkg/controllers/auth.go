@@ -1,10 +1,12 @@
 package controllers
 
 import (
+	"cmp"
 	"crypto/hmac"
 	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"strings"
 
@@ -25,6 +27,7 @@ type AuthController struct {
 	AppConfig   *config.AppConfig
 	AuthModel   *models.AuthModel
 	RoomModel   *models.RoomModel
+	TenantModel *models.TenantModel
 	NatsService *natsservice.NatsService
 }
 
@@ -33,6 +36,7 @@ type AuthControllerArgs struct {
 	AppConfig   *config.AppConfig
 	AuthModel   *models.AuthModel
 	RoomModel   *models.RoomModel
+	TenantModel *models.TenantModel
 	NatsService *natsservice.NatsService
 }
 
@@ -42,6 +46,7 @@ func NewAuthController(args AuthControllerArgs) *AuthController {
 		AppConfig:   args.AppConfig,
 		AuthModel:   args.AuthModel,
 		RoomModel:   args.RoomModel,
+		TenantModel: args.TenantModel,
 		NatsService: args.NatsService,
 	}
 }
@@ -55,9 +60,14 @@ func (ac *AuthController) HandleAuthHeaderCheck(c fiber.Ctx) error {
 		c.Status(fiber.StatusUnauthorized)
 		return ac.sendVerificationRes(c, false, "Missing API-KEY header.", plugnmeet.StatusCode_MISSING_REQUIRED_PARAMETER)
 	}
-	if apiKey != ac.AppConfig.Client.ApiKey {
-		c.Status(fiber.StatusUnauthorized)
-		return ac.sendVerificationRes(c, false, "Invalid API key provided.", plugnmeet.StatusCode_INVALID_API_KEY)
+	tenantId, secrets, err := ac.TenantModel.ResolveApiKey(apiKey)
+	if err != nil {
+		if errors.Is(err, config.InvalidConsumerKey) {
+			c.Status(fiber.StatusUnauthorized)
+			return ac.sendVerificationRes(c, false, "Invalid API key provided.", plugnmeet.StatusCode_INVALID_API_KEY)
+		}
+		c.Status(fiber.StatusInternalServerError)
+		return ac.sendVerificationRes(c, false, err.Error(), plugnmeet.StatusCode_INTERNAL_SERVER_ERROR)
 	}
 
 	if signature == "" {
@@ -65,7 +75,7 @@ func (ac *AuthController) HandleAuthHeaderCheck(c fiber.Ctx) error {
 		return ac.sendVerificationRes(c, false, "Missing HASH-SIGNATURE header.", plugnmeet.StatusCode_MISSING_REQUIRED_PARAMETER)
 	}
 
-	mac := hmac.New(sha256.New, []byte(ac.AppConfig.Client.Secret))
+	var signedData []byte
 	// we should use strings.Contains to match as different platform may add extra data
 	if strings.Contains(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
 		// For multipart/form-data, we sign the Room-Id header to ensure its integrity.
@@ -75,21 +85,84 @@ func (ac *AuthController) HandleAuthHeaderCheck(c fiber.Ctx) error {
 			c.Status(fiber.StatusUnauthorized)
 			return ac.sendVerificationRes(c, false, "Missing Room-Id header for multipart request.", plugnmeet.StatusCode_MISSING_REQUIRED_PARAMETER)
 		}
-		mac.Write([]byte(roomId))
+		signedData = []byte(roomId)
 	} else {
 		// For all other requests, we sign the raw body.
-		mac.Write(c.Body())
+		signedData = c.Body()
 	}
 
-	expectedSignature := hex.EncodeToString(mac.Sum(nil))
-	if subtle.ConstantTimeCompare([]byte(expectedSignature), []byte(signature)) != 1 {
+	// during the grace period of a rotation, both the new & old secrets are valid
+	verified := false
+	for _, secret := range secrets {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(signedData)
+		expectedSignature := hex.EncodeToString(mac.Sum(nil))
+		if subtle.ConstantTimeCompare([]byte(expectedSignature), []byte(signature)) == 1 {
+			verified = true
+			break
+		}
+	}
+	if !verified {
 		c.Status(fiber.StatusUnauthorized)
 		return ac.sendVerificationRes(c, false, "Failed to verify provided authentication details.", plugnmeet.StatusCode_INVALID_TOKEN_OR_SIGNATURE)
 	}
 
+	c.Locals(ctxKeyTenantID, tenantId)
+	if tenantId != "" {
+		if err := ac.checkTenantAccess(c, tenantId); err != nil {
+			if errors.Is(err, config.ErrTenantAccessDenied) {
+				c.Status(fiber.StatusForbidden)
+				return ac.sendVerificationRes(c, false, err.Error(), plugnmeet.StatusCode_INVALID_API_KEY)
+			}
+			c.Status(fiber.StatusInternalServerError)
+			return ac.sendVerificationRes(c, false, err.Error(), plugnmeet.StatusCode_INTERNAL_SERVER_ERROR)
+		}
+	}
+
 	return c.Next()
 }
 
+// checkTenantAccess makes sure that the room, recording or artifact
+// of the request belongs to the tenant. Lists are filtered by the handlers.
+func (ac *AuthController) checkTenantAccess(c fiber.Ctx, tenantId string) error {
+	var ids struct {
+		RoomId        string `json:"room_id"`
+		RoomIdAlt     string `json:"roomId"`
+		RecordId      string `json:"record_id"`
+		RecordIdAlt   string `json:"recordId"`
+		ArtifactId    string `json:"artifact_id"`
+		ArtifactIdAlt string `json:"artifactId"`
+	}
+	if strings.Contains(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		ids.RoomId = c.Get(config.HeaderRoomId)
+	} else if len(c.Body()) > 0 {
+		// invalid body will be handled by the actual handler
+		_ = json.Unmarshal(c.Body(), &ids)
+	}
+
+	checks := []struct {
+		id    string
+		check func(tenantId, id string) (bool, error)
+	}{
+		{cmp.Or(ids.RoomId, ids.RoomIdAlt), ac.TenantModel.CanAccessRoom},
+		{cmp.Or(ids.RecordId, ids.RecordIdAlt), ac.TenantModel.CanAccessRecording},
+		{cmp.Or(ids.ArtifactId, ids.ArtifactIdAlt), ac.TenantModel.CanAccessArtifact},
+	}
+	for _, ch := range checks {
+		if ch.id == "" {
+			continue
+		}
+		ok, err := ch.check(tenantId, ch.id)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return config.ErrTenantAccessDenied
+		}
+	}
+	return nil
+}
+
 // HandleVerifyHeaderToken is a middleware to verify the Authorization header token.
 func (ac *AuthController) HandleVerifyHeaderToken(c fiber.Ctx) error {
 	authToken := c.Get("Authorization")
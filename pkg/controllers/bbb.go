@@ -1,9 +1,12 @@
 package controllers
 
 import (
+	"cmp"
 	"crypto/subtle"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"strings"
 	"time"
@@ -26,6 +29,8 @@ type BBBController struct {
 	UserModel          *models.UserModel
 	BBBApiWrapperModel *models.BBBApiWrapperModel
 	RecordingModel     *models.RecordingModel
+	TemplateModel      *models.TemplateModel
+	TenantModel        *models.TenantModel
 	NatsService        *natsservice.NatsService
 }
 
@@ -36,6 +41,8 @@ type BBBControllerArgs struct {
 	UserModel          *models.UserModel
 	BBBApiWrapperModel *models.BBBApiWrapperModel
 	RecordingModel     *models.RecordingModel
+	TemplateModel      *models.TemplateModel
+	TenantModel        *models.TenantModel
 	NatsService        *natsservice.NatsService
 }
 
@@ -47,6 +54,8 @@ func NewBBBController(args BBBControllerArgs) *BBBController {
 		UserModel:          args.UserModel,
 		BBBApiWrapperModel: args.BBBApiWrapperModel,
 		RecordingModel:     args.RecordingModel,
+		TemplateModel:      args.TemplateModel,
+		TenantModel:        args.TenantModel,
 		NatsService:        args.NatsService,
 	}
 }
@@ -54,7 +63,11 @@ func NewBBBController(args BBBControllerArgs) *BBBController {
 // HandleVerifyApiRequest is a middleware to verify BBB API requests.
 func (bc *BBBController) HandleVerifyApiRequest(c fiber.Ctx) error {
 	apiKey := c.Params("apiKey")
-	if apiKey == "" || apiKey != bc.AppConfig.Client.ApiKey {
+	if apiKey == "" {
+		return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "apiKeyError", "invalid api key"))
+	}
+	tenantId, secrets, err := bc.TenantModel.ResolveApiKey(apiKey)
+	if err != nil {
 		return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "apiKeyError", "invalid api key"))
 	}
 
@@ -100,6 +113,11 @@ func (bc *BBBController) HandleVerifyApiRequest(c fiber.Ctx) error {
 		data = s1[1]
 	}
 
+	// bbb-webhooks calculates the checksum using the full name, e.g. hooks/create
+	if strings.Contains(c.Path(), "/api/hooks/") {
+		method = "hooks/" + method
+	}
+
 	s3 := strings.Split(data, "checksum=")
 	if len(s3) < 1 {
 		return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "checksumError", "Checksums do not match"))
@@ -115,14 +133,54 @@ func (bc *BBBController) HandleVerifyApiRequest(c fiber.Ctx) error {
 		queries = strings.TrimSuffix(s3[0], "&")
 	}
 
-	ourSum := bbbapiwrapper.CalculateCheckSum(bc.AppConfig.Client.Secret, method, queries)
-	if subtle.ConstantTimeCompare([]byte(checksum), []byte(ourSum)) != 1 {
+	verified := false
+	for _, secret := range secrets {
+		ourSum := bbbapiwrapper.CalculateCheckSum(secret, method, queries)
+		if subtle.ConstantTimeCompare([]byte(checksum), []byte(ourSum)) == 1 {
+			verified = true
+			break
+		}
+	}
+	if !verified {
 		return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "checksumError", "Checksums do not match"))
 	}
 
+	c.Locals(ctxKeyTenantID, tenantId)
+	if tenantId != "" {
+		if err := bc.checkTenantAccess(c, tenantId); err != nil {
+			return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "apiKeyError", err.Error()))
+		}
+	}
+
 	return c.Next()
 }
 
+// checkTenantAccess makes sure that the meeting or recordings of the request belong to the tenant
+func (bc *BBBController) checkTenantAccess(c fiber.Ctx, tenantId string) error {
+	if meetingID := cmp.Or(c.Query("meetingID"), c.FormValue("meetingID")); meetingID != "" {
+		ok, err := bc.TenantModel.CanAccessRoom(tenantId, bbbapiwrapper.CheckMeetingIdToMatchFormat(meetingID))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return config.ErrTenantAccessDenied
+		}
+	}
+
+	if recordID := cmp.Or(c.Query("recordID"), c.FormValue("recordID")); recordID != "" {
+		for _, rId := range strings.Split(recordID, ",") {
+			ok, err := bc.TenantModel.CanAccessRecording(tenantId, rId)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return config.ErrTenantAccessDenied
+			}
+		}
+	}
+	return nil
+}
+
 // HandleBBBCreate handles BBB create meeting requests.
 func (bc *BBBController) HandleBBBCreate(c fiber.Ctx) error {
 	q := new(bbbapiwrapper.CreateMeetingReq)
@@ -163,11 +221,19 @@ func (bc *BBBController) HandleBBBCreate(c fiber.Ctx) error {
 		return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "error", err.Error()))
 	}
 
+	// templateID isn't part of BBB API, but this way integrations can pick one of our room templates
+	if templateId := c.Query("templateID", c.FormValue("templateID")); templateId != "" {
+		pnmReq, err = bc.TemplateModel.ApplyTemplateToReq(getTenantId(c), templateId, pnmReq)
+		if err != nil {
+			return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "invalidTemplate", err.Error()))
+		}
+	}
+
 	if err = validateRequest(pnmReq); err != nil {
 		return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "validationError", err.Error()))
 	}
 
-	room, err := bc.RoomModel.CreateRoom(c.RequestCtx(), pnmReq)
+	room, err := bc.RoomModel.CreateRoom(tenantCtx(c), pnmReq)
 	if err != nil {
 		return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "error", err.Error()))
 	}
@@ -351,7 +417,7 @@ func (bc *BBBController) HandleBBBGetMeetingInfo(c fiber.Ctx) error {
 
 // HandleBBBGetMeetings handles BBB getMeetings requests.
 func (bc *BBBController) HandleBBBGetMeetings(c fiber.Ctx) error {
-	_, _, _, rooms := bc.RoomModel.GetActiveRoomsInfo(c.RequestCtx())
+	_, _, _, rooms := bc.RoomModel.GetActiveRoomsInfo(tenantCtx(c))
 	if rooms == nil {
 		return c.XML(bbbapiwrapper.CommonResponseMsg("SUCCESS", "noMeetings", "no meetings were found on this server"))
 	}
@@ -406,7 +472,7 @@ func (bc *BBBController) HandleBBBGetRecordings(c fiber.Ctx) error {
 	}
 
 	host := fmt.Sprintf("%s://%s", c.Protocol(), c.Hostname())
-	recordings, pagination, err := bc.BBBApiWrapperModel.GetRecordings(host, q)
+	recordings, pagination, err := bc.BBBApiWrapperModel.GetRecordings(host, getTenantId(c), q)
 	if err != nil {
 		return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "error", err.Error()))
 	}
@@ -464,3 +530,160 @@ func (bc *BBBController) HandleBBBUpdateRecordings(c fiber.Ctx) error {
 		Updated:    true,
 	})
 }
+
+// HandleBBBInsertDocument handles BBB insertDocument requests.
+func (bc *BBBController) HandleBBBInsertDocument(c fiber.Ctx) error {
+	meetingID := cmp.Or(c.Query("meetingID"), c.FormValue("meetingID"))
+	if meetingID == "" {
+		return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "missingParamMeetingID", "You must specify a meeting ID for the meeting."))
+	}
+	if c.Method() != "POST" || len(c.Body()) == 0 {
+		return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "missingDocuments", "You must send the documents in the request body."))
+	}
+
+	req := new(models.BBBInsertDocumentReq)
+	if err := xml.Unmarshal(c.Body(), req); err != nil {
+		return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "parsingError", err.Error()))
+	}
+
+	err := bc.BBBApiWrapperModel.InsertDocuments(bbbapiwrapper.CheckMeetingIdToMatchFormat(meetingID), req.GetDocuments())
+	if err != nil {
+		return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "error", err.Error()))
+	}
+
+	return c.XML(bbbapiwrapper.CommonResponseMsg("SUCCESS", "", "Presentation is being uploaded"))
+}
+
+// HandleBBBGetRecordingTextTracks handles BBB getRecordingTextTracks requests.
+// Like BBB, the response of text track APIs is in JSON format.
+func (bc *BBBController) HandleBBBGetRecordingTextTracks(c fiber.Ctx) error {
+	recordID := cmp.Or(c.Query("recordID"), c.FormValue("recordID"))
+	if recordID == "" {
+		return sendBBBJSONResponse(c, "FAILED", fiber.Map{"messageKey": "missingParamRecordID", "message": "You must specify a recordID."})
+	}
+
+	host := fmt.Sprintf("%s://%s", c.Protocol(), c.Hostname())
+	tracks, err := bc.BBBApiWrapperModel.GetRecordingTextTracks(host, recordID)
+	if err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendBBBJSONResponse(c, "FAILED", fiber.Map{"messageKey": "noRecordings", "message": "No recording found for " + recordID})
+		}
+		return sendBBBJSONResponse(c, "FAILED", fiber.Map{"messageKey": "error", "message": err.Error()})
+	}
+
+	return sendBBBJSONResponse(c, "SUCCESS", fiber.Map{"tracks": tracks})
+}
+
+// HandleBBBPutRecordingTextTrack handles BBB putRecordingTextTrack requests.
+func (bc *BBBController) HandleBBBPutRecordingTextTrack(c fiber.Ctx) error {
+	req := new(models.PutRecordingTextTrackReq)
+	if err := c.Bind().Query(req); err != nil {
+		return sendBBBJSONResponse(c, "FAILED", fiber.Map{"messageKey": "parsingError", "message": "We can not parse request"})
+	}
+	req.RecordID = cmp.Or(req.RecordID, c.FormValue("recordID"))
+	req.Kind = cmp.Or(req.Kind, c.FormValue("kind"))
+	req.Lang = cmp.Or(req.Lang, c.FormValue("lang"))
+	req.Label = cmp.Or(req.Label, c.FormValue("label"))
+	if req.RecordID == "" {
+		return sendBBBJSONResponse(c, "FAILED", fiber.Map{"messageKey": "missingParamRecordID", "message": "You must specify a recordID."})
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return sendBBBJSONResponse(c, "FAILED", fiber.Map{"messageKey": "empty_uploaded_text_track", "message": "Empty uploaded text track."})
+	}
+	f, err := file.Open()
+	if err != nil {
+		return sendBBBJSONResponse(c, "FAILED", fiber.Map{"messageKey": "error", "message": err.Error()})
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return sendBBBJSONResponse(c, "FAILED", fiber.Map{"messageKey": "error", "message": err.Error()})
+	}
+
+	if err = bc.BBBApiWrapperModel.PutRecordingTextTrack(req, content); err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendBBBJSONResponse(c, "FAILED", fiber.Map{"messageKey": "noRecordings", "message": "No recording found for " + req.RecordID})
+		}
+		return sendBBBJSONResponse(c, "FAILED", fiber.Map{"messageKey": "invalidParams", "message": err.Error()})
+	}
+
+	return sendBBBJSONResponse(c, "SUCCESS", fiber.Map{
+		"messageKey": "upload_text_track_success",
+		"message":    "Text track uploaded successfully",
+		"recordId":   req.RecordID,
+	})
+}
+
+// HandleBBBHooksCreate handles BBB hooks/create requests.
+func (bc *BBBController) HandleBBBHooksCreate(c fiber.Ctx) error {
+	callbackURL := cmp.Or(c.Query("callbackURL"), c.FormValue("callbackURL"))
+	if callbackURL == "" {
+		return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "missingParamCallbackURL", "You must specify a callbackURL."))
+	}
+	meetingID := cmp.Or(c.Query("meetingID"), c.FormValue("meetingID"))
+	rawData := strings.ToLower(cmp.Or(c.Query("getRaw"), c.FormValue("getRaw"))) == "true"
+
+	hook, err := bc.BBBApiWrapperModel.CreateHook(getTenantId(c), meetingID, callbackURL, rawData)
+	if err != nil {
+		return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "createHookError", err.Error()))
+	}
+
+	return c.XML(models.BBBHookCreateRes{
+		ReturnCode:    "SUCCESS",
+		HookID:        hook.HookID,
+		PermanentHook: hook.PermanentHook,
+		RawData:       hook.RawData,
+	})
+}
+
+// HandleBBBHooksDestroy handles BBB hooks/destroy requests.
+func (bc *BBBController) HandleBBBHooksDestroy(c fiber.Ctx) error {
+	hookID := cmp.Or(c.Query("hookID"), c.FormValue("hookID"))
+	if hookID == "" {
+		return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "missingParamHookID", "You must specify a hookID."))
+	}
+
+	err := bc.BBBApiWrapperModel.DestroyHook(getTenantId(c), hookID)
+	if err != nil {
+		if errors.Is(err, config.NotFoundErr) || errors.Is(err, config.ErrTenantAccessDenied) {
+			return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "destroyMissingHook", "The hook informed was not found."))
+		}
+		return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "destroyHookError", err.Error()))
+	}
+
+	return c.XML(models.BBBHookDestroyRes{
+		ReturnCode: "SUCCESS",
+		Removed:    true,
+	})
+}
+
+// HandleBBBHooksList handles BBB hooks/list requests.
+func (bc *BBBController) HandleBBBHooksList(c fiber.Ctx) error {
+	hooks, err := bc.BBBApiWrapperModel.ListHooks(getTenantId(c), cmp.Or(c.Query("meetingID"), c.FormValue("meetingID")))
+	if err != nil {
+		return c.XML(bbbapiwrapper.CommonResponseMsg("FAILED", "error", err.Error()))
+	}
+
+	res := models.BBBHookListRes{
+		ReturnCode: "SUCCESS",
+	}
+	res.Hooks.Hooks = hooks
+	return c.XML(res)
+}
+
+// HandleBBBGetDefaultConfigXML handles BBB getDefaultConfigXML requests.
+// The config.xml was used by the old flash client only,
+// so we return a minimal one to keep the front-ends happy.
+func (bc *BBBController) HandleBBBGetDefaultConfigXML(c fiber.Ctx) error {
+	c.Set("Content-Type", "application/xml")
+	return c.SendString(`<?xml version="1.0" ?><config><localeversion suppressWarning="false">0.9.0</localeversion><version>0.9</version><modules></modules></config>`)
+}
+
+func sendBBBJSONResponse(c fiber.Ctx, returnCode string, data fiber.Map) error {
+	data["returncode"] = returnCode
+	return c.JSON(fiber.Map{
+		"response": data,
+	})
+}
@@ -171,6 +171,15 @@ func (fc *FileController) HandleDownloadUploadedFile(c fiber.Ctx) error {
 	}
 
 	absFile, mType, err := helpers.ValidateAndGetAbsFilePath(fc.AppConfig.UploadFileSettings.Path, relativePath)
+	if errors.Is(err, config.ErrFileNotFound) {
+		// the file may be uploaded to storage by another node
+		res, sErr := fc.FileModel.GetStoredFileDownloadInfo(c.RequestCtx(), relativePath)
+		if sErr != nil {
+			fc.logger.WithError(sErr).Warn("failed to get file from storage")
+		} else if res != nil {
+			return c.Redirect().Status(fiber.StatusTemporaryRedirect).To(res.RedirectUrl)
+		}
+	}
 	if err != nil {
 		fc.logger.WithError(err).Warn("file path validation failed")
 		if errors.Is(err, config.ErrFileNotFound) {
@@ -1,8 +1,11 @@
 package controllers
 
 import (
+	"sort"
+
 	"github.com/gofiber/fiber/v3"
 	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
 	"github.com/nats-io/nats.go"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/fx"
@@ -10,29 +13,43 @@ import (
 )
 
 type HealthCheckController struct {
-	app *config.AppConfig
-	rds *redis.Client
-	db  *gorm.DB
-	nc  *nats.Conn
+	app          *config.AppConfig
+	rds          *redis.Client
+	db           *gorm.DB
+	nc           *nats.Conn
+	redisService *redisservice.RedisService
 }
 
 type HealthCheckControllerArgs struct {
 	fx.In
-	App      *config.AppConfig
-	RDS      *redis.Client
-	DB       *gorm.DB
-	NatsConn *nats.Conn
+	App          *config.AppConfig
+	RDS          *redis.Client
+	DB           *gorm.DB
+	NatsConn     *nats.Conn
+	RedisService *redisservice.RedisService
 }
 
 func NewHealthCheckController(args HealthCheckControllerArgs) *HealthCheckController {
 	return &HealthCheckController{
-		app: args.App,
-		rds: args.RDS,
-		db:  args.DB,
-		nc:  args.NatsConn,
+		app:          args.App,
+		rds:          args.RDS,
+		db:           args.DB,
+		nc:           args.NatsConn,
+		redisService: args.RedisService,
 	}
 }
 
+// insightsAccountHealthStatus is public, so account ids & errors of the providers must not be part of it.
+// Index is the position of the account in the service.
+type insightsAccountHealthStatus struct {
+	Service             string `json:"service"`
+	Provider            string `json:"provider"`
+	Index               int    `json:"index"`
+	Healthy             bool   `json:"healthy"`
+	ConsecutiveFailures int64  `json:"consecutive_failures"`
+	OpenUntil           int64  `json:"open_until,omitempty"`
+}
+
 func (h *HealthCheckController) HandleHealthCheck(c fiber.Ctx) error {
 	db, err := h.db.DB()
 	if err != nil {
@@ -50,5 +67,55 @@ func (h *HealthCheckController) HandleHealthCheck(c fiber.Ctx) error {
 		return c.Status(fiber.StatusServiceUnavailable).SendString("Nats connection error")
 	}
 
+	if c.Query("format") == "json" {
+		// unhealthy insights accounts won't make the server unhealthy,
+		// as the services can fail over to the other accounts.
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"status":   "healthy",
+			"insights": h.insightsHealth(),
+		})
+	}
+
 	return c.Status(fiber.StatusOK).SendString("Healthy")
 }
+
+func (h *HealthCheckController) insightsHealth() []*insightsAccountHealthStatus {
+	list := make([]*insightsAccountHealthStatus, 0)
+	if h.app.Insights == nil || !h.app.Insights.Enabled {
+		return list
+	}
+
+	var refs []redisservice.InsightsAccountRef
+	for serviceType := range h.app.Insights.Services {
+		candidates, err := h.app.Insights.GetServiceCandidates(serviceType)
+		if err != nil {
+			continue
+		}
+		for i, cd := range candidates {
+			list = append(list, &insightsAccountHealthStatus{
+				Service:  string(serviceType),
+				Provider: string(cd.Service.Provider),
+				Index:    i,
+				Healthy:  true,
+			})
+			refs = append(refs, redisservice.InsightsAccountRef{Provider: string(cd.Service.Provider), AccountId: cd.Account.ID})
+		}
+	}
+
+	if health, err := h.redisService.GetInsightsAccountsHealth(refs); err == nil {
+		for i, hl := range health {
+			st := list[i]
+			st.Healthy = !hl.IsOpen()
+			st.ConsecutiveFailures = hl.ConsecutiveFailures
+			if !st.Healthy {
+				st.OpenUntil = hl.OpenUntil
+			}
+		}
+	}
+
+	// keep the order of the accounts within the service
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Service < list[j].Service
+	})
+	return list
+}
@@ -383,3 +383,57 @@ func (i *InsightsController) HandleEndAIMeetingSummarization(c fiber.Ctx) error
 	}
 	return utils.SendCommonProtobufResponse(c, true, "success")
 }
+
+// HandleStartLiveSummary starts summarizing the transcription periodically for the moderators.
+func (i *InsightsController) HandleStartLiveSummary(c fiber.Ctx) error {
+	if i.app.Insights == nil || !i.app.Insights.Enabled {
+		return sendErrorResponse(c, fiber.StatusBadRequest, "insights feature wasn't configured")
+	}
+	if !fiber.Locals[bool](c, "isAdmin") {
+		return sendErrorResponse(c, fiber.StatusForbidden, "only admin can perform this task")
+	}
+
+	roomId := fiber.Locals[string](c, "roomId")
+	if err := i.insightsModel.StartLiveSummary(roomId, fiber.Locals[string](c, "requestedUserId")); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status": true,
+		"msg":    "success",
+	})
+}
+
+// HandleEndLiveSummary stops the updates of the live summary.
+func (i *InsightsController) HandleEndLiveSummary(c fiber.Ctx) error {
+	if !fiber.Locals[bool](c, "isAdmin") {
+		return sendErrorResponse(c, fiber.StatusForbidden, "only admin can perform this task")
+	}
+
+	if err := i.insightsModel.EndLiveSummary(fiber.Locals[string](c, "roomId")); err != nil {
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status": true,
+		"msg":    "success",
+	})
+}
+
+// HandleGetLiveSummary returns the latest live summary with the action items & decisions.
+func (i *InsightsController) HandleGetLiveSummary(c fiber.Ctx) error {
+	if !fiber.Locals[bool](c, "isAdmin") {
+		return sendErrorResponse(c, fiber.StatusForbidden, "only admin can perform this task")
+	}
+
+	info, err := i.insightsModel.GetLiveSummary(fiber.Locals[string](c, "roomId"))
+	if err != nil {
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status": true,
+		"msg":    "success",
+		"result": info,
+	})
+}
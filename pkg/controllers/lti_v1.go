@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
@@ -12,6 +13,9 @@ import (
 	"github.com/mynaparrot/plugnmeet-protocol/utils"
 	"github.com/mynaparrot/plugnmeet-server/pkg/config"
 	"github.com/mynaparrot/plugnmeet-server/pkg/models"
+	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/sirupsen/logrus"
 	"go.uber.org/fx"
 )
 
@@ -30,34 +34,120 @@ const (
 	ctxKeyName         = "name"
 	ctxKeyIsAdmin      = "isAdmin"
 	ctxKeyCustomParams = "customParams"
+	ctxKeyTemplateID   = "templateId"
+	ctxKeyLtiV1p3      = "ltiV1p3"
+	ctxKeyLtiOutcome   = "ltiOutcome"
 )
 
 // LtiV1Controller holds dependencies for LTI v1 related handlers.
 type LtiV1Controller struct {
+	ctx            context.Context
 	app            *config.AppConfig
+	natsService    *natsservice.NatsService
+	gradeJobSub    jetstream.ConsumeContext
+	logger         *logrus.Entry
 	LtiV1Model     *models.LtiV1Model
+	LtiGradeModel  *models.LtiGradeModel
 	RoomModel      *models.RoomModel
 	RecordingModel *models.RecordingModel
 }
 
 type LtiV1ControllerArgs struct {
 	fx.In
+	Ctx            context.Context
 	App            *config.AppConfig
+	NatsService    *natsservice.NatsService
 	LtiV1Model     *models.LtiV1Model
+	LtiGradeModel  *models.LtiGradeModel
 	RoomModel      *models.RoomModel
 	RecordingModel *models.RecordingModel
+	Logger         *logrus.Logger
 }
 
 // NewLtiV1Controller creates a new LtiV1Controller.
 func NewLtiV1Controller(args LtiV1ControllerArgs) *LtiV1Controller {
 	return &LtiV1Controller{
+		ctx:            args.Ctx,
 		app:            args.App,
+		natsService:    args.NatsService,
+		logger:         args.Logger.WithField("controller", "lti"),
 		LtiV1Model:     args.LtiV1Model,
+		LtiGradeModel:  args.LtiGradeModel,
 		RoomModel:      args.RoomModel,
 		RecordingModel: args.RecordingModel,
 	}
 }
 
+// Initialize subscribes to the grade submission jobs, if enabled.
+func (lc *LtiV1Controller) Initialize() error {
+	if !lc.app.LtiGradeSettings.Enabled {
+		return nil
+	}
+
+	consumer, err := lc.natsService.CreateLtiGradeJobStreamWithConsumer(lc.ctx, lc.logger)
+	if err != nil {
+		return err
+	}
+
+	consumeCtx, err := consumer.Consume(func(msg jetstream.Msg) {
+		job := new(models.LtiGradeJob)
+		if err := json.Unmarshal(msg.Data(), job); err != nil {
+			lc.logger.WithError(err).Error("failed to unmarshal lti grade job, dropping")
+			if err := msg.Term(); err != nil {
+				lc.logger.WithError(err).Error("failed to send TERM")
+			}
+			return
+		}
+
+		log := lc.logger.WithFields(logrus.Fields{
+			"roomId": job.RoomId,
+			"userId": job.UserId,
+		})
+		var numDelivered uint64
+		if metadata, err := msg.Metadata(); err == nil {
+			numDelivered = metadata.NumDelivered
+			log = log.WithField("numDelivered", numDelivered)
+		}
+
+		if err := lc.LtiGradeModel.SubmitScore(job); err != nil {
+			log.WithError(err).Error("failed to submit lti score")
+			if numDelivered >= uint64(lc.app.LtiGradeSettings.MaxRetries) {
+				// no more tries left, remove it from the queue
+				log.Error("giving up submitting lti score")
+				_ = msg.Term()
+				return
+			}
+			if err := msg.NakWithDelay(lc.app.LtiGradeSettings.RetryDelay); err != nil {
+				log.WithError(err).Error("failed to send NAK with delay")
+			}
+			return
+		}
+		log.Info("lti score submitted")
+
+		if err := msg.Ack(); err != nil {
+			log.WithError(err).Error("failed to send ACK")
+		}
+	}, jetstream.ConsumeErrHandler(func(consumeCtx jetstream.ConsumeContext, err error) {
+		if lc.ctx.Err() == nil && !errors.Is(err, jetstream.ErrConnectionClosed) {
+			lc.logger.WithError(err).Warn("jetstream consume error for lti grade jobs")
+		}
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to NATS for lti grade jobs: %w", err)
+	}
+
+	lc.logger.Infof("Successfully connected with %s queue", natsservice.LtiGradeJobsSubject)
+	lc.gradeJobSub = consumeCtx
+	return nil
+}
+
+// Shutdown stops consuming the grade submission jobs.
+func (lc *LtiV1Controller) Shutdown() {
+	if lc.gradeJobSub != nil {
+		lc.gradeJobSub.Stop()
+	}
+}
+
 // HandleLTIV1Landing handles the initial LTI v1 landing request.
 func (lc *LtiV1Controller) HandleLTIV1Landing(c fiber.Ctx) error {
 	b := c.Body()
@@ -65,6 +155,14 @@ func (lc *LtiV1Controller) HandleLTIV1Landing(c fiber.Ctx) error {
 		return c.Status(fiber.StatusUnauthorized).SendString("empty body")
 	}
 
+	signingURL := getLtiBaseURL(lc.app, c) + c.Path()
+
+	return lc.LtiV1Model.LTIV1Landing(c, string(b), signingURL)
+}
+
+// getLtiBaseURL returns scheme & host of the request as LMS sees it,
+// BBBJoinHost will be preferred if configured
+func getLtiBaseURL(app *config.AppConfig, c fiber.Ctx) string {
 	hostName := c.Hostname()
 	proto := "https"
 	if c.Protocol() == "http" {
@@ -74,8 +172,8 @@ func (lc *LtiV1Controller) HandleLTIV1Landing(c fiber.Ctx) error {
 	parts := strings.Split(hostName, ":")
 
 	// we can use BBBJoinHost to build correct info
-	if lc.app.Client.BBBJoinHost != nil && *lc.app.Client.BBBJoinHost != "" {
-		if u, err := url.Parse(*lc.app.Client.BBBJoinHost); err == nil {
+	if app.Client.BBBJoinHost != nil && *app.Client.BBBJoinHost != "" {
+		if u, err := url.Parse(*app.Client.BBBJoinHost); err == nil {
 			hostName = u.Hostname()
 			proto = u.Scheme
 
@@ -86,9 +184,7 @@ func (lc *LtiV1Controller) HandleLTIV1Landing(c fiber.Ctx) error {
 		}
 	}
 
-	signingURL := fmt.Sprintf("%s://%s%s", proto, hostName, c.Path())
-
-	return lc.LtiV1Model.LTIV1Landing(c, string(b), signingURL)
+	return fmt.Sprintf("%s://%s", proto, hostName)
 }
 
 // HandleLTIV1GETREQUEST handles GET requests to LTI endpoints, which are not allowed.
@@ -113,6 +209,13 @@ func (lc *LtiV1Controller) HandleLTIV1VerifyHeaderToken(c fiber.Ctx) error {
 	c.Locals(ctxKeyUserID, auth.UserId)
 	c.Locals(ctxKeyName, auth.Name)
 	c.Locals(ctxKeyIsAdmin, auth.IsAdmin)
+	c.Locals(ctxKeyTemplateID, auth.TemplateId)
+	if auth.V1p3 != nil {
+		c.Locals(ctxKeyLtiV1p3, auth.V1p3)
+	}
+	if auth.Outcome != nil {
+		c.Locals(ctxKeyLtiOutcome, auth.Outcome)
+	}
 
 	if auth.LtiCustomParameters != nil {
 		customParams, err := json.Marshal(auth.LtiCustomParameters)
@@ -155,7 +258,8 @@ func (lc *LtiV1Controller) HandleLTIV1JoinRoom(c fiber.Ctx) error {
 		}
 	}
 
-	token, err := lc.LtiV1Model.LTIV1JoinRoom(c.RequestCtx(), claim)
+	outcome, _ := c.Locals(ctxKeyLtiOutcome).(*models.LtiOutcome)
+	token, err := lc.LtiV1Model.LTIV1JoinRoom(c.RequestCtx(), claim, fiber.Locals[string](c, ctxKeyTemplateID), outcome)
 	if err != nil {
 		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -199,7 +303,7 @@ func (lc *LtiV1Controller) HandleLTIV1FetchRecordings(c fiber.Ctx) error {
 		return sendErrorResponse(c, fiber.StatusBadRequest, errRoomIdMissing)
 	}
 	req.RoomIds = []string{roomId}
-	result, err := lc.RecordingModel.FetchRecordings(req)
+	result, err := lc.RecordingModel.FetchRecordings("", req)
 	if err != nil {
 		if errors.Is(err, config.NotFoundErr) {
 			return sendErrorResponse(c, fiber.StatusNotFound, "no recording found")
@@ -221,7 +325,9 @@ func (lc *LtiV1Controller) HandleLTIV1GetRecordingDownloadToken(c fiber.Ctx) err
 		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
 	}
 
-	token, err := lc.RecordingModel.GetDownloadToken(req)
+	token, err := lc.RecordingModel.GetDownloadToken(req, &models.RecordingTokenRestrictions{
+		ViewerId: fiber.Locals[string](c, ctxKeyUserID),
+	})
 	if err != nil {
 		if errors.Is(err, config.NotFoundErr) {
 			return sendErrorResponse(c, fiber.StatusNotFound, "recording not found")
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/models"
+	"go.uber.org/fx"
+)
+
+const ltiV1p3LaunchPath = "/lti/v1p3/launch"
+
+// LtiV1p3Controller holds dependencies for LTI 1.3 related handlers.
+type LtiV1p3Controller struct {
+	app          *config.AppConfig
+	LtiV1p3Model *models.LtiV1p3Model
+}
+
+type LtiV1p3ControllerArgs struct {
+	fx.In
+	App          *config.AppConfig
+	LtiV1p3Model *models.LtiV1p3Model
+}
+
+// NewLtiV1p3Controller creates a new LtiV1p3Controller.
+func NewLtiV1p3Controller(args LtiV1p3ControllerArgs) *LtiV1p3Controller {
+	return &LtiV1p3Controller{
+		app:          args.App,
+		LtiV1p3Model: args.LtiV1p3Model,
+	}
+}
+
+// HandleLTIV1p3Login handles the OIDC login initiation, platforms may use both GET & POST.
+func (lc *LtiV1p3Controller) HandleLTIV1p3Login(c fiber.Ctx) error {
+	param := func(key string) string {
+		if v := c.FormValue(key); v != "" {
+			return v
+		}
+		return c.Query(key)
+	}
+
+	req := &models.LtiV1p3LoginReq{
+		Issuer:         param("iss"),
+		LoginHint:      param("login_hint"),
+		TargetLinkUri:  param("target_link_uri"),
+		LtiMessageHint: param("lti_message_hint"),
+		ClientId:       param("client_id"),
+		DeploymentId:   param("lti_deployment_id"),
+	}
+
+	redirectTo, err := lc.LtiV1p3Model.LTIV1p3Login(c, req, getLtiBaseURL(lc.app, c)+ltiV1p3LaunchPath)
+	if err != nil {
+		return sendLtiV1p3ErrorResponse(c, err, fiber.StatusUnauthorized)
+	}
+
+	return c.Redirect().To(redirectTo)
+}
+
+// HandleLTIV1p3Launch handles the id_token posted by the platform after the OIDC login.
+func (lc *LtiV1p3Controller) HandleLTIV1p3Launch(c fiber.Ctx) error {
+	if errMsg := c.FormValue("error"); errMsg != "" {
+		return sendErrorResponse(c, fiber.StatusUnauthorized, errMsg+": "+c.FormValue("error_description"))
+	}
+
+	err := lc.LtiV1p3Model.LTIV1p3Launch(c, c.FormValue("id_token"), c.FormValue("state"), getLtiBaseURL(lc.app, c)+ltiV1p3LaunchPath)
+	if err != nil {
+		return sendLtiV1p3ErrorResponse(c, err, fiber.StatusUnauthorized)
+	}
+
+	return nil
+}
+
+// HandleLTIV1p3JWKS returns the public key set of the tool.
+func (lc *LtiV1p3Controller) HandleLTIV1p3JWKS(c fiber.Ctx) error {
+	keys, err := lc.LtiV1p3Model.GetToolKeySet()
+	if err != nil {
+		return sendLtiV1p3ErrorResponse(c, err, fiber.StatusUnauthorized)
+	}
+
+	return c.JSON(keys)
+}
+
+// HandleLTIV1p3FetchMemberships fetches the members of the course using NRPS.
+func (lc *LtiV1p3Controller) HandleLTIV1p3FetchMemberships(c fiber.Ctx) error {
+	if isAdmin, ok := c.Locals(ctxKeyIsAdmin).(bool); !ok || !isAdmin {
+		return sendErrorResponse(c, fiber.StatusForbidden, errOnlyAdminCanPerform)
+	}
+
+	v1p3, _ := c.Locals(ctxKeyLtiV1p3).(*models.LtiV1p3Context)
+	members, err := lc.LtiV1p3Model.LTIV1p3FetchMemberships(v1p3)
+	if err != nil {
+		return sendLtiV1p3ErrorResponse(c, err, fiber.StatusInternalServerError)
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  true,
+		"msg":     "success",
+		"members": members,
+	})
+}
+
+func sendLtiV1p3ErrorResponse(c fiber.Ctx, err error, defaultCode int) error {
+	switch {
+	case errors.Is(err, config.ErrLtiV1p3NotEnabled):
+		return sendErrorResponse(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, config.ErrLtiV1p3ServiceNotAvailable):
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	return sendErrorResponse(c, defaultCode, err.Error())
+}
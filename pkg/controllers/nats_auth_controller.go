@@ -170,6 +170,8 @@ func (s *NatsAuthController) setPermissionForClient(data *plugnmeet.PlugNmeetTok
 				fmt.Sprintf("%s.%s.%s", s.app.NatsInfo.Subjects.SystemCoreWorker, roomId, userId),
 				// permission to publish in core pub/sub
 				fmt.Sprintf("%s.%s", s.app.NatsInfo.Subjects.Chat, roomId),
+				// chat of the user, the sender can't be faked, so only these will be stored in the transcript
+				fmt.Sprintf("%s.%s.%s", s.app.NatsInfo.Subjects.Chat, roomId, userId),
 				fmt.Sprintf("%s.%s", s.app.NatsInfo.Subjects.Whiteboard, roomId),
 				fmt.Sprintf("%s.%s", s.app.NatsInfo.Subjects.DataChannel, roomId),
 			},
@@ -181,6 +183,7 @@ func (s *NatsAuthController) setPermissionForClient(data *plugnmeet.PlugNmeetTok
 				fmt.Sprintf("%s.%s", s.app.NatsInfo.Subjects.SystemPublic, roomId),
 				// other pub/sub channels
 				fmt.Sprintf("%s.%s", s.app.NatsInfo.Subjects.Chat, roomId),
+				fmt.Sprintf("%s.%s.*", s.app.NatsInfo.Subjects.Chat, roomId),
 				fmt.Sprintf("%s.%s", s.app.NatsInfo.Subjects.Whiteboard, roomId),
 				fmt.Sprintf("%s.%s", s.app.NatsInfo.Subjects.DataChannel, roomId),
 			},
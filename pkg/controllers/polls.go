@@ -283,8 +283,13 @@ func (pc *PollsController) HandleGetResponsesResult(c fiber.Ctx) error {
 }
 
 // HandleGetPollsStats gets statistics for all polls in a room.
+// With ?include=quiz_scores admins will get the scores of the quizzes as well in JSON format.
 func (pc *PollsController) HandleGetPollsStats(c fiber.Ctx) error {
 	roomId := fiber.Locals[string](c, "roomId")
+	if c.Query("include") == "quiz_scores" {
+		return pc.sendPollsStatsWithQuizScores(c, roomId)
+	}
+
 	res := new(plugnmeet.PollResponse)
 	res.Status = false
 
@@ -300,6 +305,97 @@ func (pc *PollsController) HandleGetPollsStats(c fiber.Ctx) error {
 	return sendPollResponse(c, res)
 }
 
+// HandleCreateAdvancedPoll handles creating a poll of any type, e.g. multiple choice, free-text, rating or quiz.
+func (pc *PollsController) HandleCreateAdvancedPoll(c fiber.Ctx) error {
+	if !fiber.Locals[bool](c, "isAdmin") {
+		return sendErrorResponse(c, fiber.StatusForbidden, "only admin can perform this task")
+	}
+
+	req := new(models.CreateAdvancedPollReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	req.RoomId = fiber.Locals[string](c, "roomId")
+	req.UserId = fiber.Locals[string](c, "requestedUserId")
+
+	pollId, err := pc.PollModel.CreateAdvancedPoll(req)
+	if err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  true,
+		"msg":     "success",
+		"poll_id": pollId,
+	})
+}
+
+// HandleUserSubmitAdvancedResponse handles a user's submission for any type of poll.
+func (pc *PollsController) HandleUserSubmitAdvancedResponse(c fiber.Ctx) error {
+	req := new(models.SubmitAdvancedPollResponseReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if req.PollId == "" {
+		return sendErrorResponse(c, fiber.StatusBadRequest, "poll_id required")
+	}
+	req.RoomId = fiber.Locals[string](c, "roomId")
+	req.UserId = fiber.Locals[string](c, "requestedUserId")
+
+	if err := pc.PollModel.UserSubmitAdvancedResponse(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  true,
+		"msg":     "success",
+		"poll_id": req.PollId,
+	})
+}
+
+// HandleGetPollsSettings returns the settings of all the polls of the room.
+// The correct option of the quizzes will only be sent to the admins.
+func (pc *PollsController) HandleGetPollsSettings(c fiber.Ctx) error {
+	settings, err := pc.PollModel.GetAllPollSettings(fiber.Locals[string](c, "roomId"))
+	if err != nil {
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	if !fiber.Locals[bool](c, "isAdmin") {
+		for _, s := range settings {
+			s.CorrectOption = 0
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"status":   true,
+		"msg":      "success",
+		"settings": settings,
+	})
+}
+
+// sendPollsStatsWithQuizScores sends the stats of the polls with the per-user scores of the quiz polls.
+func (pc *PollsController) sendPollsStatsWithQuizScores(c fiber.Ctx, roomId string) error {
+	if !fiber.Locals[bool](c, "isAdmin") {
+		return sendErrorResponse(c, fiber.StatusForbidden, "only admin can perform this task")
+	}
+
+	stats, err := pc.PollModel.GetPollsStats(roomId)
+	if err != nil {
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	scores, err := pc.PollModel.GetQuizScores(roomId)
+	if err != nil {
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status":      true,
+		"msg":         "success",
+		"stats":       stats,
+		"quiz_scores": scores,
+	})
+}
+
 func sendPollResponse(c fiber.Ctx, res *plugnmeet.PollResponse) error {
 	marshal, err := proto.Marshal(res)
 	if err != nil {
@@ -1,9 +1,14 @@
 package controllers
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"path/filepath"
+	"time"
 
+	"github.com/gammazero/workerpool"
 	"github.com/gofiber/fiber/v3"
 	"github.com/mynaparrot/plugnmeet-protocol/hooks"
 	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
@@ -11,21 +16,40 @@ import (
 	"github.com/mynaparrot/plugnmeet-server/pkg/config"
 	"github.com/mynaparrot/plugnmeet-server/pkg/models"
 	dbservice "github.com/mynaparrot/plugnmeet-server/pkg/services/db"
+	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
+	"github.com/nats-io/nats.go/jetstream"
 	"github.com/sirupsen/logrus"
 	"go.uber.org/fx"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
 
+// recordingPostProcessProgressInterval is how often a running job tells the queue it's still alive
+const recordingPostProcessProgressInterval = time.Minute
+
+// recorderQueueCheckInterval is how often a waiting request checks for a free recorder
+const recorderQueueCheckInterval = 5 * time.Second
+
 // RecordingController holds dependencies for recording-related handlers.
 type RecordingController struct {
+	ctx            context.Context
+	app            *config.AppConfig
 	ds             *dbservice.DatabaseService
+	natsService    *natsservice.NatsService
 	recordingModel *models.RecordingModel
 	logger         *logrus.Entry
+	postProcessWp  *workerpool.WorkerPool
+	postProcessSub jetstream.ConsumeContext
+
+	recorderQueueSub jetstream.ConsumeContext
 }
 
 type RecordingControllerArgs struct {
 	fx.In
+	Ctx            context.Context
+	App            *config.AppConfig
 	Ds             *dbservice.DatabaseService
+	NatsService    *natsservice.NatsService
 	RecordingModel *models.RecordingModel
 	Logger         *logrus.Logger
 }
@@ -33,12 +57,133 @@ type RecordingControllerArgs struct {
 // NewRecordingController creates a new RecordingController.
 func NewRecordingController(args RecordingControllerArgs) *RecordingController {
 	return &RecordingController{
+		ctx:            args.Ctx,
+		app:            args.App,
 		ds:             args.Ds,
+		natsService:    args.NatsService,
 		recordingModel: args.RecordingModel,
 		logger:         args.Logger.WithField("controller", "recording"),
 	}
 }
 
+// Initialize subscribes to the recorder queue & the recording post-processing queue, if those are enabled.
+func (rc *RecordingController) Initialize() error {
+	if q := rc.app.RecorderInfo.Queue; q != nil && q.Enabled {
+		if err := rc.subscribeRecorderQueue(); err != nil {
+			return err
+		}
+	}
+
+	pp := rc.app.RecorderInfo.PostProcessing
+	if pp == nil || !pp.Enabled {
+		return nil
+	}
+
+	consumer, err := rc.natsService.CreateRecordingPostProcessStreamWithConsumer(rc.ctx, rc.logger)
+	if err != nil {
+		return err
+	}
+
+	rc.postProcessWp = workerpool.New(pp.MaxWorkers)
+	consumeCtx, err := consumer.Consume(func(msg jetstream.Msg) {
+		rc.postProcessWp.Submit(func() {
+			rc.handlePostProcessMsg(msg)
+		})
+	}, jetstream.PullMaxMessages(pp.MaxWorkers), jetstream.ConsumeErrHandler(func(consumeCtx jetstream.ConsumeContext, err error) {
+		if rc.ctx.Err() == nil && !errors.Is(err, jetstream.ErrConnectionClosed) {
+			rc.logger.WithError(err).Warn("jetstream consume error for recording post-processing")
+		}
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to NATS for recording post-processing: %w", err)
+	}
+
+	rc.logger.Infof("Successfully connected with %s queue", natsservice.RecordingPostProcessSubject)
+	rc.postProcessSub = consumeCtx
+	return nil
+}
+
+// Shutdown stops the consumers & waits for the running post-processing jobs.
+func (rc *RecordingController) Shutdown() {
+	if rc.recorderQueueSub != nil {
+		rc.recorderQueueSub.Stop()
+	}
+	if rc.postProcessSub != nil {
+		rc.postProcessSub.Stop()
+	}
+	if rc.postProcessWp != nil {
+		rc.postProcessWp.Stop()
+	}
+}
+
+func (rc *RecordingController) subscribeRecorderQueue() error {
+	consumer, err := rc.natsService.CreateRecorderQueueStreamWithConsumer(rc.ctx, rc.logger)
+	if err != nil {
+		return err
+	}
+
+	consumeCtx, err := consumer.Consume(rc.handleRecorderQueueMsg, jetstream.PullMaxMessages(1), jetstream.ConsumeErrHandler(func(consumeCtx jetstream.ConsumeContext, err error) {
+		if rc.ctx.Err() == nil && !errors.Is(err, jetstream.ErrConnectionClosed) {
+			rc.logger.WithError(err).Warn("jetstream consume error for recorder queue")
+		}
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to NATS for recorder queue: %w", err)
+	}
+
+	rc.logger.Infof("Successfully connected with %s queue", natsservice.RecorderQueueSubject)
+	rc.recorderQueueSub = consumeCtx
+	return nil
+}
+
+// handleRecorderQueueMsg tries to dispatch the request. If it has to wait for a free recorder,
+// it will be delivered again after the interval & the following requests will be tried meanwhile,
+// so a request whose affinity can't be met won't hold back the others.
+func (rc *RecordingController) handleRecorderQueueMsg(msg jetstream.Msg) {
+	if !rc.recordingModel.ProcessQueuedRecorderTask(msg.Data()) {
+		_ = msg.NakWithDelay(recorderQueueCheckInterval)
+		return
+	}
+	if err := msg.Ack(); err != nil {
+		rc.logger.WithError(err).Error("failed to send ACK")
+	}
+}
+
+func (rc *RecordingController) handlePostProcessMsg(msg jetstream.Msg) {
+	recordId := string(msg.Data())
+	log := rc.logger.WithField("recordId", recordId)
+
+	// ffmpeg may run longer than the ack wait of the consumer
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(recordingPostProcessProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				_ = msg.InProgress()
+			}
+		}
+	}()
+
+	retry, err := rc.recordingModel.ProcessRecordingRenditions(recordId)
+	if err != nil {
+		log.WithError(err).Error("failed to process recording renditions")
+	}
+	if retry {
+		if err := msg.NakWithDelay(time.Minute); err != nil {
+			log.WithError(err).Error("failed to send NAK with delay")
+		}
+		return
+	}
+	if err := msg.Ack(); err != nil {
+		log.WithError(err).Error("failed to send ACK")
+	}
+}
+
 // HandleFetchRecordings handles fetching recordings.
 func (rc *RecordingController) HandleFetchRecordings(c fiber.Ctx) error {
 	req := new(plugnmeet.FetchRecordingsReq)
@@ -46,7 +191,7 @@ func (rc *RecordingController) HandleFetchRecordings(c fiber.Ctx) error {
 		return utils.SendCommonProtoJsonResponse(c, false, err.Error(), plugnmeet.StatusCode_INVALID_PARAMETERS)
 	}
 
-	result, err := rc.recordingModel.FetchRecordings(req)
+	result, err := rc.recordingModel.FetchRecordings(getTenantId(c), req)
 	if err != nil {
 		if errors.Is(err, config.NotFoundErr) {
 			return utils.SendCommonProtoJsonResponse(c, false, "no recording found", plugnmeet.StatusCode_NOT_FOUND)
@@ -78,7 +223,35 @@ func (rc *RecordingController) HandleRecordingInfo(c fiber.Ctx) error {
 		return utils.SendCommonProtoJsonResponse(c, false, err.Error(), plugnmeet.StatusCode_INTERNAL_SERVER_ERROR)
 	}
 
-	return utils.SendProtoJsonResponse(c, result)
+	// restrictions of the rendition tokens, same as /recording/getDownloadToken
+	restrictions := new(models.RecordingTokenRestrictions)
+	if err := json.Unmarshal(c.Body(), restrictions); err != nil {
+		return utils.SendCommonProtoJsonResponse(c, false, err.Error(), plugnmeet.StatusCode_INVALID_PARAMETERS)
+	}
+	renditions, err := rc.recordingModel.GetRecordingRenditions(req.RecordId, restrictions)
+	if err != nil {
+		return utils.SendCommonProtoJsonResponse(c, false, err.Error(), plugnmeet.StatusCode_INTERNAL_SERVER_ERROR)
+	}
+	if len(renditions) == 0 {
+		return utils.SendProtoJsonResponse(c, result)
+	}
+
+	// renditions aren't part of the protocol, so we'll add those to the same response
+	op := protojson.MarshalOptions{
+		EmitUnpopulated: true,
+		UseProtoNames:   true,
+	}
+	marshal, err := op.Marshal(result)
+	if err != nil {
+		return utils.SendCommonProtoJsonResponse(c, false, err.Error(), plugnmeet.StatusCode_INTERNAL_SERVER_ERROR)
+	}
+	res := make(map[string]any)
+	if err := json.Unmarshal(marshal, &res); err != nil {
+		return utils.SendCommonProtoJsonResponse(c, false, err.Error(), plugnmeet.StatusCode_INTERNAL_SERVER_ERROR)
+	}
+	res["renditions"] = renditions
+
+	return c.JSON(res)
 }
 
 // HandleUpdateRecordingMetadata handles update metadata information for a single recording.
@@ -138,8 +311,13 @@ func (rc *RecordingController) HandleGetDownloadToken(c fiber.Ctx) error {
 	if err := parseAndValidateRequest(c.Body(), req); err != nil {
 		return utils.SendCommonProtoJsonResponse(c, false, err.Error(), plugnmeet.StatusCode_INVALID_PARAMETERS)
 	}
+	// restrictions aren't part of the protocol, so those will be read from the same body
+	restrictions := new(models.RecordingTokenRestrictions)
+	if err := json.Unmarshal(c.Body(), restrictions); err != nil {
+		return utils.SendCommonProtoJsonResponse(c, false, err.Error(), plugnmeet.StatusCode_INVALID_PARAMETERS)
+	}
 
-	token, err := rc.recordingModel.GetDownloadToken(req)
+	token, err := rc.recordingModel.GetDownloadToken(req, restrictions)
 	if err != nil {
 		if errors.Is(err, config.NotFoundErr) {
 			return utils.SendCommonProtoJsonResponse(c, false, "recording not found", plugnmeet.StatusCode_NOT_FOUND)
@@ -164,7 +342,7 @@ func (rc *RecordingController) HandleDownloadRecording(c fiber.Ctx) error {
 		return c.Status(fiber.StatusUnauthorized).SendString("token require or invalid url")
 	}
 
-	res, status, err := rc.recordingModel.VerifyRecordingToken(token)
+	res, status, err := rc.recordingModel.VerifyRecordingToken(token, newRecordingAccessReq(c))
 	if err != nil {
 		return c.Status(status).SendString(err.Error())
 	}
@@ -190,6 +368,63 @@ func (rc *RecordingController) HandleDownloadRecording(c fiber.Ctx) error {
 	}
 }
 
+// HandleDownloadRecordingHls handles the playlists & segments of the HLS rendition.
+func (rc *RecordingController) HandleDownloadRecordingHls(c fiber.Ctx) error {
+	token := c.Params("token")
+	file := c.Params("*")
+
+	if len(token) == 0 || len(file) == 0 {
+		return c.Status(fiber.StatusUnauthorized).SendString("token require or invalid url")
+	}
+
+	res, status, err := rc.recordingModel.VerifyRecordingHlsToken(token, file, newRecordingAccessReq(c))
+	if err != nil {
+		return c.Status(status).SendString(err.Error())
+	}
+
+	c.Set(fiber.HeaderContentType, res.MimeType)
+	switch {
+	case res.RedirectUrl != "":
+		return c.Redirect().Status(fiber.StatusTemporaryRedirect).To(res.RedirectUrl)
+	case res.Content != nil:
+		return c.Send(res.Content)
+	default:
+		return c.SendFile(res.LocalPath)
+	}
+}
+
+// HandleRecordingAccessLog returns the download & playback logs of the recordings.
+func (rc *RecordingController) HandleRecordingAccessLog(c fiber.Ctx) error {
+	req := new(models.FetchRecordingAccessLogsReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	result, err := rc.recordingModel.FetchRecordingAccessLogs(getTenantId(c), req)
+	if err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendErrorResponse(c, fiber.StatusNotFound, "no access log found")
+		}
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status": true,
+		"msg":    "success",
+		"result": result,
+	})
+}
+
+// newRecordingAccessReq collects the client information of the download request.
+// Players send many range requests for the same playback,
+// so only the first one will be counted as a new access.
+func newRecordingAccessReq(c fiber.Ctx) *models.RecordingAccessReq {
+	return &models.RecordingAccessReq{
+		ClientIp:  c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+}
+
 // HandleRecorderTasks handles start/stop recording & RTMP requests.
 func (rc *RecordingController) HandleRecorderTasks(c fiber.Ctx) error {
 	isAdmin := fiber.Locals[bool](c, "isAdmin")
@@ -230,6 +465,9 @@ func (rc *RecordingController) HandleRecorderTasks(c fiber.Ctx) error {
 		}
 	case plugnmeet.RecordingTasks_STOP_RECORDING:
 		if room.IsRecording == 0 {
+			if rc.recordingModel.CancelQueuedRecorderTask(room.RoomId, plugnmeet.RecordingTasks_START_RECORDING) {
+				return utils.SendCommonProtobufResponse(c, true, "notifications.recorder-task-cancelled")
+			}
 			return utils.SendCommonProtobufResponse(c, false, "notifications.recording-not-running")
 		}
 	case plugnmeet.RecordingTasks_START_RTMP:
@@ -241,6 +479,9 @@ func (rc *RecordingController) HandleRecorderTasks(c fiber.Ctx) error {
 		}
 	case plugnmeet.RecordingTasks_STOP_RTMP:
 		if room.IsActiveRtmp == 0 {
+			if rc.recordingModel.CancelQueuedRecorderTask(room.RoomId, plugnmeet.RecordingTasks_START_RTMP) {
+				return utils.SendCommonProtobufResponse(c, true, "notifications.recorder-task-cancelled")
+			}
 			return utils.SendCommonProtobufResponse(c, false, "notifications.rtmp-not-running")
 		}
 	}
@@ -249,6 +490,10 @@ func (rc *RecordingController) HandleRecorderTasks(c fiber.Ctx) error {
 	req.RoomTableId = int64(room.ID)
 
 	err = rc.recordingModel.DispatchRecorderTask(req)
+	if errors.Is(err, config.ErrRecorderTaskQueued) {
+		// the position will be broadcast using room metadata
+		return utils.SendCommonProtobufResponse(c, true, err.Error())
+	}
 	if err != nil {
 		return utils.SendCommonProtobufResponse(c, false, err.Error())
 	}
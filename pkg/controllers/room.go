@@ -14,29 +14,37 @@ import (
 
 // RoomController holds dependencies for room-related handlers.
 type RoomController struct {
-	RoomModel *models.RoomModel
+	RoomModel     *models.RoomModel
+	TemplateModel *models.TemplateModel
 }
 
 type RoomControllerArgs struct {
 	fx.In
-	RoomModel *models.RoomModel
+	RoomModel     *models.RoomModel
+	TemplateModel *models.TemplateModel
 }
 
 // NewRoomController creates a new RoomController.
 func NewRoomController(args RoomControllerArgs) *RoomController {
 	return &RoomController{
-		RoomModel: args.RoomModel,
+		RoomModel:     args.RoomModel,
+		TemplateModel: args.TemplateModel,
 	}
 }
 
 // HandleRoomCreate handles creating a new room.
 func (rc *RoomController) HandleRoomCreate(c fiber.Ctx) error {
+	body, err := applyRoomTemplate(rc.TemplateModel, getTenantId(c), c.Body())
+	if err != nil {
+		return utils.SendCommonProtoJsonResponse(c, false, err.Error(), plugnmeet.StatusCode_INVALID_PARAMETERS)
+	}
+
 	req := new(plugnmeet.CreateRoomReq)
-	if err := parseAndValidateRequest(c.Body(), req); err != nil {
+	if err := parseAndValidateRequest(body, req); err != nil {
 		return utils.SendCommonProtoJsonResponse(c, false, err.Error(), plugnmeet.StatusCode_INVALID_PARAMETERS)
 	}
 
-	room, err := rc.RoomModel.CreateRoom(c.RequestCtx(), req)
+	room, err := rc.RoomModel.CreateRoom(tenantCtx(c), req)
 	if err != nil {
 		return utils.SendCommonProtoJsonResponse(c, false, err.Error(), plugnmeet.StatusCode_INTERNAL_SERVER_ERROR)
 	}
@@ -82,7 +90,7 @@ func (rc *RoomController) HandleGetActiveRoomInfo(c fiber.Ctx) error {
 
 // HandleGetActiveRoomsInfo gets information about all active rooms.
 func (rc *RoomController) HandleGetActiveRoomsInfo(c fiber.Ctx) error {
-	status, msg, statusCode, res := rc.RoomModel.GetActiveRoomsInfo(c.RequestCtx())
+	status, msg, statusCode, res := rc.RoomModel.GetActiveRoomsInfo(tenantCtx(c))
 
 	r := &plugnmeet.GetActiveRoomsInfoRes{
 		Status:     status,
@@ -112,7 +120,7 @@ func (rc *RoomController) HandleFetchPastRooms(c fiber.Ctx) error {
 		return utils.SendCommonProtoJsonResponse(c, false, err.Error(), plugnmeet.StatusCode_INVALID_PARAMETERS)
 	}
 
-	result, err := rc.RoomModel.FetchPastRooms(c.RequestCtx(), req)
+	result, err := rc.RoomModel.FetchPastRooms(tenantCtx(c), req)
 	if err != nil {
 		if errors.Is(err, config.NotFoundErr) {
 			return utils.SendCommonProtoJsonResponse(c, false, "no room found", plugnmeet.StatusCode_NOT_FOUND)
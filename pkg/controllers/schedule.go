@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/models"
+	"go.uber.org/fx"
+)
+
+// ScheduleController holds dependencies for room schedule related handlers.
+type ScheduleController struct {
+	ScheduleModel *models.ScheduleModel
+	TemplateModel *models.TemplateModel
+	TenantModel   *models.TenantModel
+}
+
+type ScheduleControllerArgs struct {
+	fx.In
+	ScheduleModel *models.ScheduleModel
+	TemplateModel *models.TemplateModel
+	TenantModel   *models.TenantModel
+}
+
+// NewScheduleController creates a new ScheduleController.
+func NewScheduleController(args ScheduleControllerArgs) *ScheduleController {
+	return &ScheduleController{
+		ScheduleModel: args.ScheduleModel,
+		TemplateModel: args.TemplateModel,
+		TenantModel:   args.TenantModel,
+	}
+}
+
+// HandleCreateSchedule handles creating a new room schedule.
+func (sc *ScheduleController) HandleCreateSchedule(c fiber.Ctx) error {
+	req := new(models.CreateScheduleReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if len(req.Room) == 0 {
+		return sendErrorResponse(c, fiber.StatusBadRequest, "room is required")
+	}
+
+	// template will be applied now, so later changes of the template won't affect this schedule
+	roomBody, err := applyRoomTemplate(sc.TemplateModel, getTenantId(c), req.Room)
+	if err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	roomReq := new(plugnmeet.CreateRoomReq)
+	if err := parseAndValidateRequest(roomBody, roomReq); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	// room_id is inside the room object, so it wasn't checked by the auth middleware
+	if ok, err := sc.TenantModel.CanAccessRoom(getTenantId(c), roomReq.GetRoomId()); err != nil {
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	} else if !ok {
+		return sendErrorResponse(c, fiber.StatusForbidden, config.ErrTenantAccessDenied.Error())
+	}
+
+	info, err := sc.ScheduleModel.CreateSchedule(getTenantId(c), req, roomReq)
+	if err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status":   true,
+		"msg":      "success",
+		"schedule": info,
+	})
+}
+
+// HandleUpdateSchedule handles updating an existing room schedule.
+func (sc *ScheduleController) HandleUpdateSchedule(c fiber.Ctx) error {
+	req := new(models.UpdateScheduleReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if req.ScheduleId == "" {
+		return sendErrorResponse(c, fiber.StatusBadRequest, "schedule_id is required")
+	}
+
+	var roomReq *plugnmeet.CreateRoomReq
+	if len(req.Room) > 0 {
+		roomBody, err := applyRoomTemplate(sc.TemplateModel, getTenantId(c), req.Room)
+		if err != nil {
+			return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+		}
+		roomReq = new(plugnmeet.CreateRoomReq)
+		if err := parseAndValidateRequest(roomBody, roomReq); err != nil {
+			return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+		}
+	}
+
+	info, err := sc.ScheduleModel.UpdateSchedule(getTenantId(c), req, roomReq)
+	if err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendErrorResponse(c, fiber.StatusNotFound, "schedule not found")
+		}
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status":   true,
+		"msg":      "success",
+		"schedule": info,
+	})
+}
+
+// HandleDeleteSchedule handles deleting a room schedule.
+func (sc *ScheduleController) HandleDeleteSchedule(c fiber.Ctx) error {
+	req := new(models.DeleteScheduleReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if req.ScheduleId == "" {
+		return sendErrorResponse(c, fiber.StatusBadRequest, "schedule_id is required")
+	}
+
+	if err := sc.ScheduleModel.DeleteSchedule(getTenantId(c), req); err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendErrorResponse(c, fiber.StatusNotFound, "schedule not found")
+		}
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status": true,
+		"msg":    "success",
+	})
+}
+
+// HandleListSchedules handles fetching a paginated list of room schedules.
+func (sc *ScheduleController) HandleListSchedules(c fiber.Ctx) error {
+	req := new(models.FetchSchedulesReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	result, err := sc.ScheduleModel.FetchSchedules(getTenantId(c), req)
+	if err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendErrorResponse(c, fiber.StatusNotFound, "no schedule found")
+		}
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status": true,
+		"msg":    "success",
+		"result": result,
+	})
+}
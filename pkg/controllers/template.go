@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/models"
+	"go.uber.org/fx"
+)
+
+// TemplateController holds dependencies for room template related handlers.
+type TemplateController struct {
+	TemplateModel *models.TemplateModel
+}
+
+type TemplateControllerArgs struct {
+	fx.In
+	TemplateModel *models.TemplateModel
+}
+
+// NewTemplateController creates a new TemplateController.
+func NewTemplateController(args TemplateControllerArgs) *TemplateController {
+	return &TemplateController{
+		TemplateModel: args.TemplateModel,
+	}
+}
+
+// HandleCreateTemplate handles creating a new room template.
+func (tc *TemplateController) HandleCreateTemplate(c fiber.Ctx) error {
+	req := new(models.CreateTemplateReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	info, err := tc.TemplateModel.CreateTemplate(getTenantId(c), req)
+	if err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status":   true,
+		"msg":      "success",
+		"template": info,
+	})
+}
+
+// HandleUpdateTemplate handles updating an existing room template.
+func (tc *TemplateController) HandleUpdateTemplate(c fiber.Ctx) error {
+	req := new(models.UpdateTemplateReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if req.TemplateId == "" {
+		return sendErrorResponse(c, fiber.StatusBadRequest, "template_id is required")
+	}
+
+	info, err := tc.TemplateModel.UpdateTemplate(getTenantId(c), req)
+	if err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendErrorResponse(c, fiber.StatusNotFound, "template not found")
+		}
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status":   true,
+		"msg":      "success",
+		"template": info,
+	})
+}
+
+// HandleDeleteTemplate handles deleting a room template.
+func (tc *TemplateController) HandleDeleteTemplate(c fiber.Ctx) error {
+	req := new(models.TemplateIdReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if req.TemplateId == "" {
+		return sendErrorResponse(c, fiber.StatusBadRequest, "template_id is required")
+	}
+
+	if err := tc.TemplateModel.DeleteTemplate(getTenantId(c), req); err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendErrorResponse(c, fiber.StatusNotFound, "template not found")
+		}
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status": true,
+		"msg":    "success",
+	})
+}
+
+// HandleTemplateInfo handles fetching a single room template.
+func (tc *TemplateController) HandleTemplateInfo(c fiber.Ctx) error {
+	req := new(models.TemplateIdReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if req.TemplateId == "" {
+		return sendErrorResponse(c, fiber.StatusBadRequest, "template_id is required")
+	}
+
+	info, err := tc.TemplateModel.GetTemplateInfo(getTenantId(c), req)
+	if err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendErrorResponse(c, fiber.StatusNotFound, "template not found")
+		}
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status":   true,
+		"msg":      "success",
+		"template": info,
+	})
+}
+
+// HandleListTemplates handles fetching a paginated list of room templates.
+func (tc *TemplateController) HandleListTemplates(c fiber.Ctx) error {
+	req := new(models.FetchTemplatesReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	result, err := tc.TemplateModel.FetchTemplates(getTenantId(c), req)
+	if err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendErrorResponse(c, fiber.StatusNotFound, "no template found")
+		}
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status": true,
+		"msg":    "success",
+		"result": result,
+	})
+}
+
+// applyRoomTemplate merges the create room body with the template
+// if template_id was provided, otherwise the body will be returned as it is.
+func applyRoomTemplate(tm *models.TemplateModel, tenantId string, body []byte) ([]byte, error) {
+	r := struct {
+		TemplateId string `json:"template_id"`
+	}{}
+	if err := json.Unmarshal(body, &r); err != nil || r.TemplateId == "" {
+		// invalid body will be handled by the parser
+		return body, nil
+	}
+	return tm.ApplyTemplate(tenantId, r.TemplateId, body)
+}
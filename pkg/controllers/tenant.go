@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/models"
+	"go.uber.org/fx"
+)
+
+// ctxKeyTenantID is set by HandleAuthHeaderCheck & HandleVerifyApiRequest,
+// it will be empty for the root api key of the config file
+const ctxKeyTenantID = "tenantId"
+
+// TenantController holds dependencies for tenant related handlers.
+type TenantController struct {
+	TenantModel *models.TenantModel
+}
+
+type TenantControllerArgs struct {
+	fx.In
+	TenantModel *models.TenantModel
+}
+
+// NewTenantController creates a new TenantController.
+func NewTenantController(args TenantControllerArgs) *TenantController {
+	return &TenantController{
+		TenantModel: args.TenantModel,
+	}
+}
+
+// HandleRootApiKeyOnly is a middleware to allow the request only for the root api key.
+func (tc *TenantController) HandleRootApiKeyOnly(c fiber.Ctx) error {
+	if getTenantId(c) != "" {
+		return sendErrorResponse(c, fiber.StatusForbidden, "this api is only allowed for the root api key")
+	}
+	return c.Next()
+}
+
+// HandleCreateTenant handles creating a new tenant with its api key & secret.
+func (tc *TenantController) HandleCreateTenant(c fiber.Ctx) error {
+	req := new(models.CreateTenantReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	info, err := tc.TenantModel.CreateTenant(req)
+	if err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status": true,
+		"msg":    "success",
+		"tenant": info,
+	})
+}
+
+// HandleUpdateTenant handles updating name, limits or status of a tenant.
+func (tc *TenantController) HandleUpdateTenant(c fiber.Ctx) error {
+	req := new(models.UpdateTenantReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if req.TenantId == "" {
+		return sendErrorResponse(c, fiber.StatusBadRequest, "tenant_id is required")
+	}
+
+	info, err := tc.TenantModel.UpdateTenant(req)
+	if err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendErrorResponse(c, fiber.StatusNotFound, "tenant not found")
+		}
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status": true,
+		"msg":    "success",
+		"tenant": info,
+	})
+}
+
+// HandleDeleteTenant handles deleting a tenant.
+func (tc *TenantController) HandleDeleteTenant(c fiber.Ctx) error {
+	req := new(models.TenantIdReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if req.TenantId == "" {
+		return sendErrorResponse(c, fiber.StatusBadRequest, "tenant_id is required")
+	}
+
+	if err := tc.TenantModel.DeleteTenant(req); err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendErrorResponse(c, fiber.StatusNotFound, "tenant not found")
+		}
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status": true,
+		"msg":    "success",
+	})
+}
+
+// HandleListTenants handles fetching a paginated list of tenants.
+func (tc *TenantController) HandleListTenants(c fiber.Ctx) error {
+	req := new(models.FetchTenantsReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	result, err := tc.TenantModel.FetchTenants(req)
+	if err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendErrorResponse(c, fiber.StatusNotFound, "no tenant found")
+		}
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status": true,
+		"msg":    "success",
+		"result": result,
+	})
+}
+
+// HandleRotateSecret handles rotating the secret of an api key.
+// The old secret will be accepted till the end of the grace period.
+func (tc *TenantController) HandleRotateSecret(c fiber.Ctx) error {
+	req := new(models.RotateSecretReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	info, err := tc.TenantModel.RotateSecret(getTenantId(c), req)
+	if err != nil {
+		return sendApiKeyErrorResponse(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  true,
+		"msg":     "success",
+		"api_key": info,
+	})
+}
+
+// HandleSecretStatus handles fetching the rotation status of an api key.
+func (tc *TenantController) HandleSecretStatus(c fiber.Ctx) error {
+	req := new(models.ApiKeyReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	info, err := tc.TenantModel.GetSecretStatus(getTenantId(c), req)
+	if err != nil {
+		return sendApiKeyErrorResponse(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  true,
+		"msg":     "success",
+		"api_key": info,
+	})
+}
+
+// HandleRevokePreviousSecret handles ending the grace period of the old secret.
+func (tc *TenantController) HandleRevokePreviousSecret(c fiber.Ctx) error {
+	req := new(models.ApiKeyReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	info, err := tc.TenantModel.RevokePreviousSecret(getTenantId(c), req)
+	if err != nil {
+		return sendApiKeyErrorResponse(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  true,
+		"msg":     "success",
+		"api_key": info,
+	})
+}
+
+func sendApiKeyErrorResponse(c fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, config.NotFoundErr):
+		return sendErrorResponse(c, fiber.StatusNotFound, "api key not found")
+	case errors.Is(err, config.ErrTenantAccessDenied):
+		return sendErrorResponse(c, fiber.StatusForbidden, err.Error())
+	}
+	return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+}
+
+// getTenantId returns the tenant of the caller, empty for the root api key
+func getTenantId(c fiber.Ctx) string {
+	return fiber.Locals[string](c, ctxKeyTenantID)
+}
+
+// tenantCtx returns the request context carrying the tenant of the caller
+func tenantCtx(c fiber.Ctx) context.Context {
+	return models.WithTenantId(c.RequestCtx(), getTenantId(c))
+}
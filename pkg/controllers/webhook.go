@@ -1,45 +1,142 @@
 package controllers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/gammazero/workerpool"
 	"github.com/gofiber/fiber/v3"
 	"github.com/livekit/protocol/livekit"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/mynaparrot/plugnmeet-server/pkg/helpers"
 	"github.com/mynaparrot/plugnmeet-server/pkg/models"
+	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/sirupsen/logrus"
 	"go.uber.org/fx"
 )
 
 const (
 	// WebhookMaxWorkers sets the maximum number of concurrent workers for processing webhooks.
 	WebhookMaxWorkers = 100
+	// WebhookDeliveryMaxWorkers sets the maximum number of concurrent outgoing webhook requests.
+	WebhookDeliveryMaxWorkers = 20
+	// webhookDeliveryBusyDelay is the delay before trying again when another delivery of the url is being sent
+	webhookDeliveryBusyDelay = 2 * time.Second
 )
 
 // WebhookController holds dependencies for webhook-related handlers.
 type WebhookController struct {
+	ctx          context.Context
+	app          *config.AppConfig
+	natsService  *natsservice.NatsService
+	logger       *logrus.Entry
 	AuthModel    *models.AuthModel
 	WebhookModel *models.WebhookModel
 	wp           *workerpool.WorkerPool
+	deliverySub  jetstream.ConsumeContext
+	// deliverySem limits the concurrent outgoing requests
+	deliverySem chan struct{}
 }
 
 type WebhookControllerArgs struct {
 	fx.In
+	Ctx          context.Context
+	App          *config.AppConfig
+	NatsService  *natsservice.NatsService
 	AuthModel    *models.AuthModel
 	WebhookModel *models.WebhookModel
+	Logger       *logrus.Logger
 }
 
 // NewWebhookController creates a new WebhookController.
 func NewWebhookController(args WebhookControllerArgs) *WebhookController {
 	return &WebhookController{
+		ctx:          args.Ctx,
+		app:          args.App,
+		natsService:  args.NatsService,
+		logger:       args.Logger.WithField("controller", "webhook"),
 		AuthModel:    args.AuthModel,
 		WebhookModel: args.WebhookModel,
 		wp:           workerpool.New(WebhookMaxWorkers),
+		deliverySem:  make(chan struct{}, WebhookDeliveryMaxWorkers),
 	}
 }
 
+// Initialize subscribes to the outgoing webhook deliveries, if webhook is enabled.
+func (wc *WebhookController) Initialize() error {
+	if !wc.app.Client.WebhookConf.Enable {
+		return nil
+	}
+
+	consumer, err := wc.natsService.CreateWebhookDeliveryStreamWithConsumer(wc.ctx, wc.logger)
+	if err != nil {
+		return err
+	}
+
+	consumeCtx, err := consumer.Consume(func(msg jetstream.Msg) {
+		wc.deliverySem <- struct{}{}
+		go func() {
+			defer func() { <-wc.deliverySem }()
+			wc.handleDeliveryMsg(msg)
+		}()
+	}, jetstream.PullMaxMessages(WebhookDeliveryMaxWorkers), jetstream.ConsumeErrHandler(func(consumeCtx jetstream.ConsumeContext, err error) {
+		if wc.ctx.Err() == nil && !errors.Is(err, jetstream.ErrConnectionClosed) {
+			wc.logger.WithError(err).Warn("jetstream consume error for webhook deliveries")
+		}
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to NATS for webhook deliveries: %w", err)
+	}
+
+	wc.logger.Infof("Successfully connected with %s queue", natsservice.WebhookDeliveriesSubject)
+	wc.deliverySub = consumeCtx
+	return nil
+}
+
 // Shutdown stops the worker pool gracefully.
 func (wc *WebhookController) Shutdown() {
+	if wc.deliverySub != nil {
+		wc.deliverySub.Stop()
+	}
 	wc.wp.Stop()
 }
 
+// handleDeliveryMsg tries the delivery once. A failed one will be retried after the backoff,
+// the next deliveries of the same room & url won't wait in the queue meanwhile,
+// they will be published again one by one once this one is finished.
+func (wc *WebhookController) handleDeliveryMsg(msg jetstream.Msg) {
+	deliveryId := string(msg.Data())
+	log := wc.logger.WithField("deliveryId", deliveryId)
+
+	d, retryIn, err := wc.WebhookModel.AttemptWebhookDelivery(deliveryId)
+	switch {
+	case errors.Is(err, helpers.ErrWebhookDeliveryWaiting):
+		err = msg.Ack()
+	case errors.Is(err, helpers.ErrWebhookDeliveryBusy):
+		err = msg.NakWithDelay(webhookDeliveryBusyDelay)
+	case err != nil:
+		log.WithError(err).Error("failed to process webhook delivery")
+		err = msg.NakWithDelay(wc.app.Client.WebhookConf.InitialBackoff)
+	case d == nil:
+		// delivery log was removed, nothing to send
+		err = msg.Term()
+	case d.Status == dbmodels.WebhookDeliveryStatusDead:
+		// no more tries left, it stays in the delivery log as dead-letter
+		err = msg.Term()
+	case d.Status == dbmodels.WebhookDeliveryStatusRetrying:
+		err = msg.NakWithDelay(retryIn)
+	default:
+		err = msg.Ack()
+	}
+	if err != nil {
+		log.WithError(err).Error("failed to reply to webhook delivery message")
+	}
+}
+
 // HandleWebhook processes incoming webhook events from LiveKit.
 func (wc *WebhookController) HandleWebhook(c fiber.Ctx) error {
 	// Read raw request body
@@ -69,3 +166,165 @@ func (wc *WebhookController) HandleWebhook(c fiber.Ctx) error {
 
 	return c.SendStatus(fiber.StatusOK)
 }
+
+// HandleFetchWebhookDeliveries returns the delivery logs of the outgoing webhooks.
+func (wc *WebhookController) HandleFetchWebhookDeliveries(c fiber.Ctx) error {
+	req := new(models.FetchWebhookDeliveriesReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	result, err := wc.WebhookModel.FetchWebhookDeliveries(getTenantId(c), req)
+	if err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendErrorResponse(c, fiber.StatusNotFound, "no delivery found")
+		}
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status": true,
+		"msg":    "success",
+		"result": result,
+	})
+}
+
+// HandleRedeliverWebhook puts the deliveries in the queue again.
+func (wc *WebhookController) HandleRedeliverWebhook(c fiber.Ctx) error {
+	req := new(models.RedeliverWebhookReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if len(req.DeliveryIds) == 0 {
+		return sendErrorResponse(c, fiber.StatusBadRequest, "delivery_ids is required")
+	}
+
+	if err := wc.WebhookModel.RedeliverWebhook(getTenantId(c), req); err != nil {
+		switch {
+		case errors.Is(err, config.NotFoundErr):
+			return sendErrorResponse(c, fiber.StatusNotFound, "delivery not found")
+		case errors.Is(err, config.ErrTenantAccessDenied):
+			return sendErrorResponse(c, fiber.StatusForbidden, err.Error())
+		}
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status": true,
+		"msg":    "success",
+	})
+}
+
+// HandleCreateWebhookSubscription handles adding a new webhook endpoint.
+func (wc *WebhookController) HandleCreateWebhookSubscription(c fiber.Ctx) error {
+	req := new(models.CreateWebhookSubscriptionReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	info, err := wc.WebhookModel.CreateWebhookSubscription(getTenantId(c), req)
+	if err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status":       true,
+		"msg":          "success",
+		"subscription": info,
+	})
+}
+
+// HandleUpdateWebhookSubscription handles updating an existing webhook endpoint.
+func (wc *WebhookController) HandleUpdateWebhookSubscription(c fiber.Ctx) error {
+	req := new(models.UpdateWebhookSubscriptionReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if req.SubscriptionId == "" {
+		return sendErrorResponse(c, fiber.StatusBadRequest, "subscription_id is required")
+	}
+
+	info, err := wc.WebhookModel.UpdateWebhookSubscription(getTenantId(c), req)
+	if err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendErrorResponse(c, fiber.StatusNotFound, "subscription not found")
+		}
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status":       true,
+		"msg":          "success",
+		"subscription": info,
+	})
+}
+
+// HandleDeleteWebhookSubscription handles deleting a webhook endpoint.
+func (wc *WebhookController) HandleDeleteWebhookSubscription(c fiber.Ctx) error {
+	req := new(models.WebhookSubscriptionIdReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if req.SubscriptionId == "" {
+		return sendErrorResponse(c, fiber.StatusBadRequest, "subscription_id is required")
+	}
+
+	if err := wc.WebhookModel.DeleteWebhookSubscription(getTenantId(c), req); err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendErrorResponse(c, fiber.StatusNotFound, "subscription not found")
+		}
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status": true,
+		"msg":    "success",
+	})
+}
+
+// HandleWebhookSubscriptionInfo handles fetching a single webhook endpoint.
+func (wc *WebhookController) HandleWebhookSubscriptionInfo(c fiber.Ctx) error {
+	req := new(models.WebhookSubscriptionIdReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if req.SubscriptionId == "" {
+		return sendErrorResponse(c, fiber.StatusBadRequest, "subscription_id is required")
+	}
+
+	info, err := wc.WebhookModel.GetWebhookSubscriptionInfo(getTenantId(c), req)
+	if err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendErrorResponse(c, fiber.StatusNotFound, "subscription not found")
+		}
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status":       true,
+		"msg":          "success",
+		"subscription": info,
+	})
+}
+
+// HandleListWebhookSubscriptions handles fetching a paginated list of webhook endpoints.
+func (wc *WebhookController) HandleListWebhookSubscriptions(c fiber.Ctx) error {
+	req := new(models.FetchWebhookSubscriptionsReq)
+	if err := c.Bind().Body(req); err != nil {
+		return sendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	result, err := wc.WebhookModel.FetchWebhookSubscriptions(getTenantId(c), req)
+	if err != nil {
+		if errors.Is(err, config.NotFoundErr) {
+			return sendErrorResponse(c, fiber.StatusNotFound, "no subscription found")
+		}
+		return sendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"status": true,
+		"msg":    "success",
+		"result": result,
+	})
+}
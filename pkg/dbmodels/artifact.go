@@ -11,19 +11,47 @@ import (
 
 type RoomArtifactType plugnmeet.RoomArtifactType
 
+// RoomArtifactTypeChatTranscript holds the chat messages of a session.
+// It's created & used by the server only, so it isn't part of the protocol's enum.
+const RoomArtifactTypeChatTranscript = plugnmeet.RoomArtifactType(1001)
+
+// RoomArtifactTypePollResults holds the results of the polls of a session.
+const RoomArtifactTypePollResults = plugnmeet.RoomArtifactType(1002)
+
+// RoomArtifactTypeLiveSummary holds the final live summary with the action items & decisions.
+const RoomArtifactTypeLiveSummary = plugnmeet.RoomArtifactType(1003)
+
+// RoomArtifactTypeLiveSummaryUsage holds the token usage of the live summary.
+const RoomArtifactTypeLiveSummaryUsage = plugnmeet.RoomArtifactType(1004)
+
+// RoomArtifactTypeTalkTimeStats holds the talk-time statistics of the speakers from the transcription.
+const RoomArtifactTypeTalkTimeStats = plugnmeet.RoomArtifactType(1005)
+
+// serverArtifactTypes are the artifact types which aren't part of the protocol
+var serverArtifactTypes = map[plugnmeet.RoomArtifactType]string{
+	RoomArtifactTypeChatTranscript:   "CHAT_TRANSCRIPT",
+	RoomArtifactTypePollResults:      "POLL_RESULTS",
+	RoomArtifactTypeLiveSummary:      "LIVE_SUMMARY",
+	RoomArtifactTypeLiveSummaryUsage: "LIVE_SUMMARY_USAGE",
+	RoomArtifactTypeTalkTimeStats:    "TALK_TIME_STATS",
+}
+
 // Value implements the driver.Valuer interface.
 // This method is called when writing to the database.
 func (t RoomArtifactType) Value() (driver.Value, error) {
 	// Convert the enum integer to its string representation.
-	s, ok := plugnmeet.RoomArtifactType_name[int32(t)]
-	if !ok {
+	s := t.String()
+	if s == "" {
 		return nil, fmt.Errorf("invalid RoomArtifactType value: %d", t)
 	}
 	return s, nil
 }
 
 func (t RoomArtifactType) String() string {
-	return plugnmeet.RoomArtifactType_name[int32(t)]
+	if s, ok := plugnmeet.RoomArtifactType_name[int32(t)]; ok {
+		return s
+	}
+	return serverArtifactTypes[plugnmeet.RoomArtifactType(t)]
 }
 
 // Scan implements the sql.Scanner interface.
@@ -40,22 +68,28 @@ func (t *RoomArtifactType) Scan(value interface{}) error {
 	}
 
 	// Convert the string name back to the enum's integer value.
-	val, ok := plugnmeet.RoomArtifactType_value[s]
-	if !ok {
-		return fmt.Errorf("unknown RoomArtifactType value from DB: %s", s)
+	if val, ok := plugnmeet.RoomArtifactType_value[s]; ok {
+		*t = RoomArtifactType(val)
+		return nil
+	}
+	for k, v := range serverArtifactTypes {
+		if v == s {
+			*t = RoomArtifactType(k)
+			return nil
+		}
 	}
-	*t = RoomArtifactType(val)
-	return nil
+	return fmt.Errorf("unknown RoomArtifactType value from DB: %s", s)
 }
 
 type RoomArtifact struct {
 	ID          uint64           `gorm:"column:id;primaryKey;autoIncrement"`
 	ArtifactId  string           `gorm:"column:artifact_id;type:varchar(64);not null;uniqueIndex:idx_artifact_id"`
-	RoomTableID uint64           `gorm:"column:room_table_id;type:int(11);not null"`
+	RoomTableID uint64           `gorm:"column:room_table_id;not null"`
 	RoomId      string           `gorm:"column:room_id;type:varchar(255);not null;index:idx_room_id"`
+	TenantId    string           `gorm:"column:tenant_id;type:varchar(64);not null;default:'';index:idx_room_artifacts_tenant_id"`
 	Type        RoomArtifactType `gorm:"column:type;type:varchar(100);not null;index:idx_type"`
 	Metadata    string           `gorm:"column:metadata;type:json"`
-	Created     time.Time        `gorm:"column:created;type:datetime;not null;default:current_timestamp();autoCreateTime"`
+	Created     time.Time        `gorm:"column:created;not null;default:CURRENT_TIMESTAMP;autoCreateTime"`
 
 	RoomInfo RoomInfo `gorm:"foreignKey:room_table_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
 }
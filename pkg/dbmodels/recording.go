@@ -8,19 +8,21 @@ import (
 )
 
 type Recording struct {
-	ID               uint64         `gorm:"column:id;type:int(11);primarykey;autoIncrement"`
+	ID               uint64         `gorm:"column:id;primaryKey;autoIncrement"`
 	RecordID         string         `gorm:"column:record_id;type:varchar(64);not null;uniqueIndex:idx_record_id"`
 	RoomID           string         `gorm:"column:room_id;type:varchar(64);not null;index:idx_room_id"`
+	TenantId         string         `gorm:"column:tenant_id;type:varchar(64);not null;default:'';index:idx_recordings_tenant_id"`
+	TemplateId       string         `gorm:"column:template_id;type:varchar(64);not null;default:'';index:idx_recordings_template_id"`
 	RoomSid          sql.NullString `gorm:"column:room_sid;type:varchar(64);not null"`
 	RecorderID       string         `gorm:"column:recorder_id;type:varchar(36);not null"`
 	FilePath         string         `gorm:"column:file_path;type:varchar(255);not null"`
-	Size             string         `gorm:"column:size;type:double;not null"`
-	Published        int64          `gorm:"column:published;type:int(1);not null;default:1"`
+	Size             string         `gorm:"column:size;type:double precision;not null"`
+	Published        int64          `gorm:"column:published;type:smallint;not null;default:1"`
 	Metadata         string         `gorm:"column:metadata;type:json"`
-	CreationTime     int64          `gorm:"column:creation_time;type:int(10);not null;autoCreateTime"`
-	RoomCreationTime int64          `gorm:"column:room_creation_time;type:int(10);not null;default:0"`
-	Created          time.Time      `gorm:"column:created;type:datetime;not null;default:current_timestamp()"`
-	Modified         time.Time      `gorm:"column:modified;type:datetime;not null;default:'0000-00-00 00:00:00';autoUpdateTime"`
+	CreationTime     int64          `gorm:"column:creation_time;not null;autoCreateTime"`
+	RoomCreationTime int64          `gorm:"column:room_creation_time;not null;default:0"`
+	Created          time.Time      `gorm:"column:created;not null;default:CURRENT_TIMESTAMP"`
+	Modified         time.Time      `gorm:"column:modified;not null;autoUpdateTime"`
 
 	RoomInfo RoomInfo `gorm:"foreignKey:room_sid;references:sid;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
 }
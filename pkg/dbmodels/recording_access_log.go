@@ -0,0 +1,25 @@
+package dbmodels
+
+import (
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+)
+
+// RecordingAccessLog keeps a record of each download or playback of a recording.
+// It isn't linked with the recordings table, so the logs will stay after deleting the recording.
+type RecordingAccessLog struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	RecordID  string    `gorm:"column:record_id;type:varchar(64);not null;default:'';index:idx_recording_access_logs_record_id"`
+	TenantId  string    `gorm:"column:tenant_id;type:varchar(64);not null;default:'';index:idx_recording_access_logs_tenant_id"`
+	FilePath  string    `gorm:"column:file_path;type:varchar(255);not null"`
+	ViewerId  string    `gorm:"column:viewer_id;type:varchar(255);not null;default:'';index:idx_recording_access_logs_viewer_id"`
+	TokenId   string    `gorm:"column:token_id;type:varchar(64);not null;default:''"`
+	ClientIp  string    `gorm:"column:client_ip;type:varchar(64);not null;default:''"`
+	UserAgent string    `gorm:"column:user_agent;type:varchar(512);not null;default:''"`
+	Created   time.Time `gorm:"column:created;not null;default:CURRENT_TIMESTAMP;autoCreateTime;index:idx_recording_access_logs_created"`
+}
+
+func (t *RecordingAccessLog) TableName() string {
+	return config.FormatDBTable("recording_access_logs")
+}
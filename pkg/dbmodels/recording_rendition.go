@@ -0,0 +1,43 @@
+package dbmodels
+
+import (
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+)
+
+const (
+	RecordingRenditionTypeThumbnail = "thumbnail"
+	RecordingRenditionTypeAudio     = "audio"
+	RecordingRenditionTypeHls       = "hls"
+	// generated at the end of the session, not by post-processing
+	RecordingRenditionTypeChapters        = "chapters"
+	RecordingRenditionTypeSpeakerTimeline = "speaker_timeline"
+
+	RecordingRenditionStatusPending    = "pending"
+	RecordingRenditionStatusProcessing = "processing"
+	RecordingRenditionStatusCompleted  = "completed"
+	RecordingRenditionStatusFailed     = "failed"
+)
+
+// RecordingRendition is an additional output generated from a recording by the post-processing.
+// For HLS, FilePath is the path of the master playlist.
+type RecordingRendition struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	RecordID  string    `gorm:"column:record_id;type:varchar(64);not null;uniqueIndex:idx_recording_renditions_record_type"`
+	Type      string    `gorm:"column:type;type:varchar(20);not null;uniqueIndex:idx_recording_renditions_record_type"`
+	Status    string    `gorm:"column:status;type:varchar(20);not null;index:idx_recording_renditions_status"`
+	FilePath  string    `gorm:"column:file_path;type:varchar(255);not null;default:''"`
+	FileSize  int64     `gorm:"column:file_size;not null;default:0"`
+	MimeType  string    `gorm:"column:mime_type;type:varchar(100);not null;default:''"`
+	Attempts  int       `gorm:"column:attempts;not null;default:0"`
+	LastError string    `gorm:"column:last_error;type:varchar(1024);not null;default:''"`
+	Created   time.Time `gorm:"column:created;not null;default:CURRENT_TIMESTAMP;autoCreateTime"`
+	Modified  time.Time `gorm:"column:modified;not null;autoUpdateTime"`
+
+	Recording Recording `gorm:"foreignKey:record_id;references:record_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (t *RecordingRendition) TableName() string {
+	return config.FormatDBTable("recording_renditions")
+}
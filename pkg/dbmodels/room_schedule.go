@@ -0,0 +1,40 @@
+package dbmodels
+
+import (
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+)
+
+const (
+	RoomScheduleStatusScheduled = "scheduled"
+	RoomScheduleStatusCompleted = "completed"
+
+	RoomScheduleRecurrenceNone   = "none"
+	RoomScheduleRecurrenceDaily  = "daily"
+	RoomScheduleRecurrenceWeekly = "weekly"
+)
+
+type RoomSchedule struct {
+	ID              uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	ScheduleId      string    `gorm:"column:schedule_id;type:varchar(64);not null;uniqueIndex:idx_room_schedules_schedule_id"`
+	RoomId          string    `gorm:"column:room_id;type:varchar(64);not null;index:idx_room_schedules_room_id"`
+	TenantId        string    `gorm:"column:tenant_id;type:varchar(64);not null;default:'';index:idx_room_schedules_tenant_id"`
+	Title           string    `gorm:"column:title;type:varchar(255);not null;default:''"`
+	StartAt         int64     `gorm:"column:start_at;not null"`
+	NextStartAt     int64     `gorm:"column:next_start_at;not null;index:idx_room_schedules_status_next_start"`
+	Recurrence      string    `gorm:"column:recurrence;type:varchar(20);not null;default:'none'"`
+	RecurrenceEndAt int64     `gorm:"column:recurrence_end_at;not null;default:0"`
+	Timezone        string    `gorm:"column:timezone;type:varchar(64);not null;default:'UTC'"`
+	Status          string    `gorm:"column:status;type:varchar(20);not null;default:'scheduled';index:idx_room_schedules_status_next_start"`
+	CreateRoomReq   string    `gorm:"column:create_room_req;type:json"`
+	WebhookUrl      string    `gorm:"column:webhook_url;type:varchar(255);not null;default:''"`
+	LastRoomSid     string    `gorm:"column:last_room_sid;type:varchar(64);not null;default:''"`
+	LastCreatedAt   int64     `gorm:"column:last_created_at;not null;default:0"`
+	Created         time.Time `gorm:"column:created;not null;default:CURRENT_TIMESTAMP"`
+	Modified        time.Time `gorm:"column:modified;not null;autoUpdateTime"`
+}
+
+func (t *RoomSchedule) TableName() string {
+	return config.FormatDBTable("room_schedules")
+}
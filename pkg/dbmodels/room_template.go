@@ -0,0 +1,26 @@
+package dbmodels
+
+import (
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+)
+
+type RoomTemplate struct {
+	ID          uint64 `gorm:"column:id;primaryKey;autoIncrement"`
+	TemplateId  string `gorm:"column:template_id;type:varchar(64);not null;uniqueIndex:idx_room_templates_template_id"`
+	TenantId    string `gorm:"column:tenant_id;type:varchar(64);not null;default:'';index:idx_room_templates_tenant_id"`
+	Name        string `gorm:"column:name;type:varchar(255);not null;default:''"`
+	Description string `gorm:"column:description;type:varchar(255);not null;default:''"`
+	Settings    string `gorm:"column:settings;type:json"`
+	// days to keep the recordings of the rooms created using this template,
+	// NULL means the value of the tenant or config will be used
+	RecordingRetentionPublishedDays   *int      `gorm:"column:recording_retention_published_days"`
+	RecordingRetentionUnpublishedDays *int      `gorm:"column:recording_retention_unpublished_days"`
+	Created                           time.Time `gorm:"column:created;not null;default:CURRENT_TIMESTAMP;autoCreateTime"`
+	Modified                          time.Time `gorm:"column:modified;not null;autoUpdateTime"`
+}
+
+func (t *RoomTemplate) TableName() string {
+	return config.FormatDBTable("room_templates")
+}
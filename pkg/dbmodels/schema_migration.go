@@ -0,0 +1,18 @@
+package dbmodels
+
+import (
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+)
+
+// SchemaMigration keeps track of the applied migrations
+type SchemaMigration struct {
+	Version   uint      `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"column:name;type:varchar(255);not null"`
+	AppliedAt time.Time `gorm:"column:applied_at;not null;autoCreateTime"`
+}
+
+func (t *SchemaMigration) TableName() string {
+	return config.FormatDBTable("schema_migrations")
+}
@@ -0,0 +1,30 @@
+package dbmodels
+
+import (
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+)
+
+type Tenant struct {
+	ID       uint64 `gorm:"column:id;primaryKey;autoIncrement"`
+	TenantId string `gorm:"column:tenant_id;type:varchar(64);not null;uniqueIndex:idx_tenants_tenant_id"`
+	Name     string `gorm:"column:name;type:varchar(255);not null;default:''"`
+	ApiKey   string `gorm:"column:api_key;type:varchar(64);not null;uniqueIndex:idx_tenants_api_key"`
+	Secret   string `gorm:"column:secret;type:varchar(255);not null"`
+	// 0 means unlimited
+	MaxConcurrentRooms        int64 `gorm:"column:max_concurrent_rooms;not null;default:0"`
+	MaxConcurrentParticipants int64 `gorm:"column:max_concurrent_participants;not null;default:0"`
+	IsActive                  int   `gorm:"column:is_active;type:smallint;not null;default:1"`
+	// days to keep the recordings of the tenant, NULL means the value of the config will be used
+	RecordingRetentionPublishedDays   *int `gorm:"column:recording_retention_published_days"`
+	RecordingRetentionUnpublishedDays *int `gorm:"column:recording_retention_unpublished_days"`
+	// monthly budget of the insights services in JSON, NULL means the value of the config will be used
+	InsightsBudget *string   `gorm:"column:insights_budget;type:text"`
+	Created        time.Time `gorm:"column:created;not null;default:CURRENT_TIMESTAMP;autoCreateTime"`
+	Modified       time.Time `gorm:"column:modified;not null;autoUpdateTime"`
+}
+
+func (t *Tenant) TableName() string {
+	return config.FormatDBTable("tenants")
+}
@@ -0,0 +1,42 @@
+package dbmodels
+
+import (
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+)
+
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusRetrying  = "retrying"
+	WebhookDeliveryStatusDelivered = "delivered"
+	// WebhookDeliveryStatusDead is the dead-letter, no more tries will be made
+	WebhookDeliveryStatusDead = "dead"
+
+	// WebhookDeliveryFormatBBB is a callback registered using BBB hooks/create API,
+	// it will be sent in BBB webhook format instead of plugNmeet
+	WebhookDeliveryFormatBBB = "bbb"
+)
+
+type WebhookDelivery struct {
+	ID             uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	DeliveryId     string    `gorm:"column:delivery_id;type:varchar(64);not null;uniqueIndex:idx_webhook_deliveries_delivery_id"`
+	TenantId       string    `gorm:"column:tenant_id;type:varchar(64);not null;default:'';index:idx_webhook_deliveries_tenant_id"`
+	RoomId         string    `gorm:"column:room_id;type:varchar(255);not null;default:'';index:idx_webhook_deliveries_room_id"`
+	RoomSid        string    `gorm:"column:room_sid;type:varchar(64);not null;default:''"`
+	Event          string    `gorm:"column:event;type:varchar(100);not null;index:idx_webhook_deliveries_event"`
+	Url            string    `gorm:"column:url;type:varchar(2048);not null"`
+	SubscriptionId string    `gorm:"column:subscription_id;type:varchar(64);not null;default:''"`
+	Format         string    `gorm:"column:format;type:varchar(20);not null;default:''"`
+	Payload        string    `gorm:"column:payload;type:text;not null"`
+	Status         string    `gorm:"column:status;type:varchar(20);not null;index:idx_webhook_deliveries_status"`
+	Attempts       int       `gorm:"column:attempts;not null;default:0"`
+	LastStatusCode int       `gorm:"column:last_status_code;not null;default:0"`
+	LastError      string    `gorm:"column:last_error;type:varchar(1024);not null;default:''"`
+	Created        time.Time `gorm:"column:created;not null;default:CURRENT_TIMESTAMP;autoCreateTime;index:idx_webhook_deliveries_created"`
+	Modified       time.Time `gorm:"column:modified;not null;autoUpdateTime"`
+}
+
+func (t *WebhookDelivery) TableName() string {
+	return config.FormatDBTable("webhook_deliveries")
+}
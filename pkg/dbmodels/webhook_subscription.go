@@ -0,0 +1,25 @@
+package dbmodels
+
+import (
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+)
+
+type WebhookSubscription struct {
+	ID             uint64 `gorm:"column:id;primaryKey;autoIncrement"`
+	SubscriptionId string `gorm:"column:subscription_id;type:varchar(64);not null;uniqueIndex:idx_webhook_subscriptions_subscription_id"`
+	TenantId       string `gorm:"column:tenant_id;type:varchar(64);not null;default:'';index:idx_webhook_subscriptions_tenant_id"`
+	Url            string `gorm:"column:url;type:varchar(2048);not null"`
+	Secret         string `gorm:"column:secret;type:varchar(255);not null;default:''"`
+	// Events is comma separated event names, empty means all events
+	Events       string    `gorm:"column:events;type:varchar(1024);not null;default:''"`
+	RoomIdPrefix string    `gorm:"column:room_id_prefix;type:varchar(255);not null;default:''"`
+	IsActive     int       `gorm:"column:is_active;type:smallint;not null;default:1"`
+	Created      time.Time `gorm:"column:created;not null;default:CURRENT_TIMESTAMP;autoCreateTime"`
+	Modified     time.Time `gorm:"column:modified;not null;autoUpdateTime"`
+}
+
+func (t *WebhookSubscription) TableName() string {
+	return config.FormatDBTable("webhook_subscriptions")
+}
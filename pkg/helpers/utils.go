@@ -136,3 +136,11 @@ func ValidateAndGetAbsFilePath(basePath, relativePath string) (string, *mimetype
 
 	return absJoinedPath, mType, nil
 }
+
+// TruncateString cuts the string to the maximum length of bytes without breaking a multibyte character
+func TruncateString(s string, l int) string {
+	if len(s) > l {
+		return strings.ToValidUTF8(s[:l], "")
+	}
+	return s
+}
@@ -0,0 +1,157 @@
+package helpers
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+)
+
+// bbbHookEvents maps our events to the event ids of BBB webhooks,
+// other events will not be sent to the hooks as BBB clients won't understand them.
+var bbbHookEvents = map[string]string{
+	"room_created":        "meeting-created",
+	"room_finished":       "meeting-ended",
+	"participant_joined":  "user-joined",
+	"participant_left":    "user-left",
+	"start_recording":     "meeting-recording-started",
+	"end_recording":       "meeting-recording-stopped",
+	"recording_proceeded": "rap-publish-ended",
+}
+
+// bbbHookTarget is the callback url of a hook & the meeting id used by the BBB client
+type bbbHookTarget struct {
+	Url       string `json:"url"`
+	MeetingId string `json:"meeting_id"`
+}
+
+type bbbHookMessage struct {
+	Data bbbHookMessageData `json:"data"`
+}
+
+type bbbHookMessageData struct {
+	Type       string         `json:"type"`
+	Id         string         `json:"id"`
+	Attributes map[string]any `json:"attributes"`
+	Event      struct {
+		Ts int64 `json:"ts"`
+	} `json:"event"`
+}
+
+// bbbHookChecksumData is the form data of the request, the order of the fields must not be changed
+// as the checksum is calculated from its JSON same as bbb-webhooks does.
+type bbbHookChecksumData struct {
+	Event     string `json:"event"`
+	Timestamp int64  `json:"timestamp"`
+	Domain    string `json:"domain,omitempty"`
+}
+
+// toBBBHookPayload converts the event to the message of BBB webhooks,
+// returns false if the event has no BBB equivalent.
+func toBBBHookPayload(event *plugnmeet.CommonNotifyEvent, meetingId string) ([]byte, bool) {
+	id, ok := bbbHookEvents[strings.ToLower(event.GetEvent())]
+	if !ok {
+		return nil, false
+	}
+
+	meeting := map[string]any{
+		"internal-meeting-id": event.GetRoom().GetSid(),
+		"external-meeting-id": meetingId,
+	}
+	if name := event.GetRoom().GetName(); name != "" && name != event.GetRoom().GetRoomId() {
+		meeting["name"] = name
+	}
+
+	msg := &bbbHookMessage{
+		Data: bbbHookMessageData{
+			Type: "event",
+			Id:   id,
+			Attributes: map[string]any{
+				"meeting": meeting,
+			},
+		},
+	}
+	msg.Data.Event.Ts = time.Now().UnixMilli()
+
+	if p := event.GetParticipant(); p != nil {
+		msg.Data.Attributes["user"] = map[string]any{
+			"internal-user-id": p.GetIdentity(),
+			"external-user-id": p.GetIdentity(),
+			"name":             p.GetName(),
+		}
+	}
+	if r := event.GetRecordingInfo(); r != nil {
+		msg.Data.Attributes["record-id"] = r.GetRecordId()
+		if id == "rap-publish-ended" {
+			msg.Data.Attributes["success"] = true
+		}
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return nil, false
+	}
+	return payload, true
+}
+
+// newBBBHookRequest prepares the request same as bbb-webhooks: the events are sent as
+// form-encoded `event` with the checksum of the data in the callback url.
+func (w *WebhookNotifier) newBBBHookRequest(d *dbmodels.WebhookDelivery, secret string) (*http.Request, error) {
+	data := &bbbHookChecksumData{
+		Event:     "[" + d.Payload + "]",
+		Timestamp: time.Now().UnixMilli(),
+		Domain:    w.bbbDomain(),
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	// JSON.stringify doesn't escape html characters
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(data); err != nil {
+		return nil, err
+	}
+	sum := sha1.Sum([]byte(d.Url + strings.TrimSuffix(buf.String(), "\n") + secret))
+
+	callbackUrl := d.Url
+	if strings.Contains(callbackUrl, "?") {
+		callbackUrl += "&"
+	} else {
+		callbackUrl += "?"
+	}
+	callbackUrl += "checksum=" + hex.EncodeToString(sum[:])
+
+	form := url.Values{}
+	form.Set("event", data.Event)
+	form.Set("timestamp", strconv.FormatInt(data.Timestamp, 10))
+	if data.Domain != "" {
+		form.Set("domain", data.Domain)
+	}
+
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, callbackUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set(webhookDeliveryIdHeader, d.DeliveryId)
+
+	return req, nil
+}
+
+// bbbDomain is the domain of the server used by the BBB clients
+func (w *WebhookNotifier) bbbDomain() string {
+	if w.app.Client.BBBJoinHost == nil {
+		return ""
+	}
+	if u, err := url.Parse(*w.app.Client.BBBJoinHost); err == nil {
+		return u.Hostname()
+	}
+	return ""
+}
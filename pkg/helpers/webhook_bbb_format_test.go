@@ -0,0 +1,91 @@
+package helpers
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/url"
+	"testing"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+)
+
+func TestToBBBHookPayload(t *testing.T) {
+	event := &plugnmeet.CommonNotifyEvent{
+		Event: new("participant_joined"),
+		Room: &plugnmeet.NotifyEventRoom{
+			Sid:    new("RM_sid"),
+			RoomId: new("room01"),
+		},
+		Participant: &plugnmeet.NotifyEventParticipant{
+			Identity: "user01",
+			Name:     "User",
+		},
+	}
+
+	payload, ok := toBBBHookPayload(event, "meeting01")
+	if !ok {
+		t.Fatal("participant_joined should be sent to BBB hooks")
+	}
+	msg := new(bbbHookMessage)
+	if err := json.Unmarshal(payload, msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Data.Type != "event" || msg.Data.Id != "user-joined" || msg.Data.Event.Ts == 0 {
+		t.Errorf("unexpected message: %+v", msg.Data)
+	}
+	meeting, _ := msg.Data.Attributes["meeting"].(map[string]any)
+	if meeting["internal-meeting-id"] != "RM_sid" || meeting["external-meeting-id"] != "meeting01" {
+		t.Errorf("unexpected meeting: %v", meeting)
+	}
+	user, _ := msg.Data.Attributes["user"].(map[string]any)
+	if user["internal-user-id"] != "user01" || user["name"] != "User" {
+		t.Errorf("unexpected user: %v", user)
+	}
+
+	event.Event = new("track_published")
+	if _, ok = toBBBHookPayload(event, "meeting01"); ok {
+		t.Error("events without BBB equivalent must be skipped")
+	}
+}
+
+func TestNewBBBHookRequest(t *testing.T) {
+	w := &WebhookNotifier{ctx: context.Background(), app: &config.AppConfig{}}
+	d := &dbmodels.WebhookDelivery{
+		DeliveryId: "delivery01",
+		Url:        "https://lms.example.com/hook?id=1",
+		Format:     dbmodels.WebhookDeliveryFormatBBB,
+		Payload:    `{"data":{"id":"meeting-created","name":"<b>"}}`,
+	}
+
+	req, err := w.newBBBHookRequest(d, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "application/x-www-form-urlencoded" {
+		t.Errorf("unexpected content type: %s", ct)
+	}
+
+	body, _ := io.ReadAll(req.Body)
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form.Get("event") != "["+d.Payload+"]" {
+		t.Errorf("unexpected event: %s", form.Get("event"))
+	}
+
+	// same as bbb-webhooks: sha1(callbackURL + JSON.stringify({event, timestamp}) + secret)
+	data := `{"event":"[{\"data\":{\"id\":\"meeting-created\",\"name\":\"<b>\"}}]","timestamp":` + form.Get("timestamp") + `}`
+	sum := sha1.Sum([]byte(d.Url + data + "secret"))
+	if got := req.URL.Query().Get("checksum"); got != hex.EncodeToString(sum[:]) {
+		t.Errorf("unexpected checksum %s", got)
+	}
+	if req.URL.Query().Get("id") != "1" {
+		t.Error("existing query of the callback url must be kept")
+	}
+}
@@ -0,0 +1,187 @@
+package helpers
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+
+	"github.com/goccy/go-json"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
+	"github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+	"github.com/sirupsen/logrus"
+)
+
+// BBBHook is a callback url registered using BBB hooks/create API.
+// The events of the room will be delivered to it in BBB webhook format.
+type BBBHook struct {
+	HookId string `json:"hook_id"`
+	RoomId string `json:"room_id"`
+	// meeting id as used by the BBB client
+	MeetingId   string `json:"meeting_id"`
+	TenantId    string `json:"tenant_id"`
+	CallbackUrl string `json:"callback_url"`
+	RawData     bool   `json:"raw_data"`
+}
+
+// AddBBBHook registers a new hook & refreshes the urls if the room is already running.
+// If the same callback url was registered for the room before, the existing hook will be returned.
+func (w *WebhookNotifier) AddBBBHook(tenantId, roomId, meetingId, callbackUrl string, rawData bool) (*BBBHook, error) {
+	if !w.isEnabled || !w.enabledForPerMeeting {
+		return nil, config.ErrBBBHooksNotEnabled
+	}
+
+	hooks, err := w.ListBBBHooks(tenantId, roomId)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range hooks {
+		if h.CallbackUrl == callbackUrl {
+			return h, nil
+		}
+	}
+
+	hookId, err := w.rs.NewBBBHookId()
+	if err != nil {
+		return nil, err
+	}
+	hook := &BBBHook{
+		HookId:      hookId,
+		RoomId:      roomId,
+		MeetingId:   meetingId,
+		TenantId:    tenantId,
+		CallbackUrl: callbackUrl,
+		RawData:     rawData,
+	}
+	marshal, err := json.Marshal(hook)
+	if err != nil {
+		return nil, err
+	}
+	if err = w.rs.AddBBBHook(hookId, marshal); err != nil {
+		return nil, err
+	}
+
+	w.refreshRoomWebhook(roomId)
+	return hook, nil
+}
+
+// RemoveBBBHook removes a hook by id, hook must belong to the same tenant.
+func (w *WebhookNotifier) RemoveBBBHook(tenantId, hookId string) error {
+	data, err := w.rs.GetBBBHook(hookId)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return config.NotFoundErr
+	}
+
+	hook := new(BBBHook)
+	if err = json.Unmarshal(data, hook); err != nil {
+		return err
+	}
+	if hook.TenantId != tenantId {
+		return config.ErrTenantAccessDenied
+	}
+
+	if err = w.rs.DeleteBBBHooks(hookId); err != nil {
+		return err
+	}
+
+	w.refreshRoomWebhook(hook.RoomId)
+	return nil
+}
+
+// ListBBBHooks returns the hooks of the tenant, if roomId is empty then hooks of all rooms
+func (w *WebhookNotifier) ListBBBHooks(tenantId, roomId string) ([]*BBBHook, error) {
+	all, err := w.rs.GetAllBBBHooks()
+	if err != nil {
+		return nil, err
+	}
+
+	var hooks []*BBBHook
+	for _, v := range all {
+		hook := new(BBBHook)
+		if err := json.Unmarshal([]byte(v), hook); err != nil {
+			continue
+		}
+		if hook.TenantId != tenantId || (roomId != "" && hook.RoomId != roomId) {
+			continue
+		}
+		hooks = append(hooks, hook)
+	}
+
+	slices.SortFunc(hooks, func(a, b *BBBHook) int {
+		x, _ := strconv.Atoi(a.HookId)
+		y, _ := strconv.Atoi(b.HookId)
+		return cmp.Compare(x, y)
+	})
+
+	return hooks, nil
+}
+
+// getBBBHooks returns the hooks registered for the room using BBB hooks API
+func (w *WebhookNotifier) getBBBHooks(roomId string, log *logrus.Entry) []bbbHookTarget {
+	all, err := w.rs.GetAllBBBHooks()
+	if err != nil {
+		log.WithError(err).Error("failed to get bbb hooks")
+		return nil
+	}
+
+	var hooks []bbbHookTarget
+	for _, v := range all {
+		hook := new(BBBHook)
+		if err := json.Unmarshal([]byte(v), hook); err != nil {
+			continue
+		}
+		if hook.RoomId != roomId || slices.ContainsFunc(hooks, func(h bbbHookTarget) bool { return h.Url == hook.CallbackUrl }) {
+			continue
+		}
+		hooks = append(hooks, bbbHookTarget{Url: hook.CallbackUrl, MeetingId: hook.MeetingId})
+	}
+	return hooks
+}
+
+// deleteBBBHooks removes all the hooks of the room, like BBB does when the meeting ends
+func (w *WebhookNotifier) deleteBBBHooks(roomId string, log *logrus.Entry) {
+	all, err := w.rs.GetAllBBBHooks()
+	if err != nil {
+		log.WithError(err).Error("failed to get bbb hooks")
+		return
+	}
+
+	var ids []string
+	for id, v := range all {
+		hook := new(BBBHook)
+		if err := json.Unmarshal([]byte(v), hook); err == nil && hook.RoomId == roomId {
+			ids = append(ids, id)
+		}
+	}
+	if err := w.rs.DeleteBBBHooks(ids...); err != nil {
+		log.WithError(err).Error("failed to delete bbb hooks")
+	}
+}
+
+// refreshRoomWebhook registers the urls again if the room is running
+// & asks all the servers to drop their cached notifier,
+// so that the updated urls will be used from the next event.
+func (w *WebhookNotifier) refreshRoomWebhook(roomId string) {
+	info, err := w.natsService.GetRoomInfo(roomId)
+	if err != nil || info == nil || (info.Status != natsservice.RoomStatusCreated && info.Status != natsservice.RoomStatusActive) {
+		// urls will be collected during room creation
+		return
+	}
+
+	log := w.logger.WithFields(logrus.Fields{
+		"room_id": roomId,
+		"method":  "refreshRoomWebhook",
+	})
+	if err := w.rs.DeleteWebhookData(roomId); err != nil {
+		log.WithError(err).Error("failed to delete webhook data from redis")
+		return
+	}
+	w.RegisterWebhook(roomId, info.RoomSid)
+
+	if err := w.natsConn.Publish(redisservice.WebhookCleanupSubject, []byte(roomId)); err != nil {
+		log.WithError(err).Error("failed to publish webhook cleanup")
+	}
+}
@@ -0,0 +1,480 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	webhookDeliveryIdHeader        = "X-Webhook-Id"
+	webhookDeliveryTimestampHeader = "X-Webhook-Timestamp"
+	webhookDeliverySignatureHeader = "X-Webhook-Signature"
+	// maximum length of the error to keep in the delivery log
+	maxWebhookDeliveryErrorLen = 1024
+	// maximum number of deliveries to save in a single insert
+	maxWebhookDeliveryBatchSize = 100
+	// number of events can wait for the writer before enqueue blocks
+	webhookDeliveryQueueSize = 1000
+	// the lock of the room & url will be kept this long after the request timeout
+	webhookDeliveryLockMargin = 30 * time.Second
+	// how many times saving the deliveries will be tried before dropping the events
+	maxWebhookDeliveryInsertAttempts = 5
+	// maximum number of stale deliveries to publish again in a single run
+	maxWebhookStaleDeliveries = 500
+)
+
+var (
+	errWebhookSubscriptionRemoved = errors.New("webhook subscription not found")
+	// ErrWebhookDeliveryWaiting means an older delivery of the same room & url hasn't finished yet,
+	// the delivery will be put in the queue again when that one is delivered.
+	ErrWebhookDeliveryWaiting = errors.New("waiting for the previous delivery of the url")
+	// ErrWebhookDeliveryBusy means another delivery of the same room & url is being sent
+	ErrWebhookDeliveryBusy = errors.New("another delivery of the url is in progress")
+)
+
+// enqueue stores a delivery log for every url, subscription & BBB hook and puts them in the delivery queue.
+func (w *WebhookNotifier) enqueue(event *plugnmeet.CommonNotifyEvent, tenantId string, urls []string, subscriptions []*dbmodels.WebhookSubscription, bbbHooks []bbbHookTarget) {
+	log := w.logger.WithFields(logrus.Fields{
+		"room_id": event.GetRoom().GetRoomId(),
+		"event":   event.GetEvent(),
+		"method":  "enqueue",
+	})
+
+	op := protojson.MarshalOptions{
+		UseProtoNames: true,
+	}
+	payload, err := op.Marshal(event)
+	if err != nil {
+		log.WithError(err).Errorln("failed to marshal webhook event")
+		return
+	}
+
+	newDelivery := func(u, subscriptionId, format string, payload []byte) *dbmodels.WebhookDelivery {
+		return &dbmodels.WebhookDelivery{
+			DeliveryId:     uuid.NewString(),
+			TenantId:       tenantId,
+			RoomId:         event.GetRoom().GetRoomId(),
+			RoomSid:        event.GetRoom().GetSid(),
+			Event:          event.GetEvent(),
+			Url:            u,
+			SubscriptionId: subscriptionId,
+			Format:         format,
+			Payload:        string(payload),
+			Status:         dbmodels.WebhookDeliveryStatusPending,
+		}
+	}
+
+	deliveries := make([]*dbmodels.WebhookDelivery, 0, len(urls)+len(subscriptions)+len(bbbHooks))
+	for _, u := range urls {
+		deliveries = append(deliveries, newDelivery(u, "", "", payload))
+	}
+	for _, s := range subscriptions {
+		deliveries = append(deliveries, newDelivery(s.Url, s.SubscriptionId, "", payload))
+	}
+	for _, h := range bbbHooks {
+		if bbbPayload, ok := toBBBHookPayload(event, h.MeetingId); ok {
+			deliveries = append(deliveries, newDelivery(h.Url, "", dbmodels.WebhookDeliveryFormatBBB, bbbPayload))
+		}
+	}
+
+	if len(deliveries) == 0 {
+		return
+	}
+
+	// the logs will be saved & published by the writer, so the caller doesn't need to wait for the db
+	select {
+	case w.pendingDeliveries <- deliveries:
+	case <-w.ctx.Done():
+		log.Warnln("server is shutting down, webhook event was not queued")
+	}
+}
+
+// deliveryWriter saves the pending deliveries in batches & puts them in the delivery queue.
+// There is only one writer, so the deliveries will be published in the same order as the events.
+func (w *WebhookNotifier) deliveryWriter() {
+	log := w.logger.WithField("method", "deliveryWriter")
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			// try to save what we already have
+			for {
+				select {
+				case deliveries := <-w.pendingDeliveries:
+					w.saveAndPublishDeliveries(deliveries, log)
+				default:
+					return
+				}
+			}
+		case deliveries := <-w.pendingDeliveries:
+			// collect the others waiting in the channel to save them together
+		collect:
+			for len(deliveries) < maxWebhookDeliveryBatchSize {
+				select {
+				case more := <-w.pendingDeliveries:
+					deliveries = append(deliveries, more...)
+				default:
+					break collect
+				}
+			}
+			w.saveAndPublishDeliveries(deliveries, log)
+		}
+	}
+}
+
+func (w *WebhookNotifier) saveAndPublishDeliveries(deliveries []*dbmodels.WebhookDelivery, log *logrus.Entry) {
+	if err := w.insertDeliveries(deliveries, log); err != nil {
+		log.WithError(err).Errorf("failed to save %d webhook deliveries, events were dropped", len(deliveries))
+		return
+	}
+	// if publishing fails, RepublishStaleDeliveries will put them in the queue later
+	for _, d := range deliveries {
+		if err := w.natsService.PublishWebhookDelivery(d.DeliveryId, []byte(d.DeliveryId)); err != nil {
+			log.WithError(err).WithFields(logrus.Fields{
+				"room_id": d.RoomId,
+				"event":   d.Event,
+				"url":     d.Url,
+			}).Errorln("failed to publish webhook delivery")
+		}
+	}
+}
+
+// insertDeliveries saves the deliveries, it will try again with backoff if the db isn't available
+func (w *WebhookNotifier) insertDeliveries(deliveries []*dbmodels.WebhookDelivery, log *logrus.Entry) error {
+	var err error
+	for attempt := 1; attempt <= maxWebhookDeliveryInsertAttempts; attempt++ {
+		if _, err = w.ds.InsertWebhookDeliveries(deliveries); err == nil {
+			return nil
+		}
+		if attempt == maxWebhookDeliveryInsertAttempts {
+			break
+		}
+		log.WithError(err).Warnf("failed to save %d webhook deliveries, attempt %d", len(deliveries), attempt)
+
+		// the insert was rolled back, but the ids may be set already
+		for _, d := range deliveries {
+			d.ID = 0
+		}
+		select {
+		case <-time.After(time.Duration(attempt) * time.Second):
+		case <-w.ctx.Done():
+			// shutting down, the last try without waiting
+			attempt = maxWebhookDeliveryInsertAttempts - 1
+		}
+	}
+	return err
+}
+
+// RepublishStaleDeliveries puts the deliveries in the queue again if their message seems to be lost,
+// e.g. publishing has failed or the server has stopped before publishing the next delivery of the url.
+// The duplicates are safe, a delivery won't be sent again once it is finished.
+func (w *WebhookNotifier) RepublishStaleDeliveries(pendingFor time.Duration) (int, error) {
+	if !w.isEnabled {
+		return 0, nil
+	}
+	now := time.Now()
+	// the retrying ones will wait up to max_backoff for the next attempt
+	list, err := w.ds.GetStaleWebhookDeliveries(now.Add(-pendingFor), now.Add(-pendingFor-w.app.Client.WebhookConf.MaxBackoff), maxWebhookStaleDeliveries)
+	if err != nil {
+		return 0, err
+	}
+
+	suffix := strconv.FormatInt(now.Unix(), 10)
+	published := 0
+	for _, d := range list {
+		if err = w.natsService.PublishWebhookDelivery(d.DeliveryId+"-"+suffix, []byte(d.DeliveryId)); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+// webhookDeliveryKey identifies the deliveries of the same room & url, those are sent in order
+func webhookDeliveryKey(d *dbmodels.WebhookDelivery) string {
+	return d.RoomId + "|" + d.Url
+}
+
+// AttemptDelivery sends the webhook request of the delivery & updates the log with the result.
+// The returned delivery will have status delivered, retrying or dead.
+// It returns (nil, nil) if the delivery log does not exist anymore.
+// The deliveries of the same room & url are sent one by one in the cluster, ErrWebhookDeliveryWaiting
+// or ErrWebhookDeliveryBusy will be returned if it isn't the turn of this delivery.
+func (w *WebhookNotifier) AttemptDelivery(deliveryId string) (*dbmodels.WebhookDelivery, error) {
+	d, err := w.ds.GetWebhookDelivery(deliveryId)
+	if err != nil || d == nil || isWebhookDeliveryFinished(d) {
+		return d, err
+	}
+
+	lock := w.rs.NewLock(fmt.Sprintf(redisservice.WebhookDeliveryLockKey, hashWebhookDeliveryKey(d)), w.app.Client.WebhookConf.RequestTimeout+webhookDeliveryLockMargin)
+	locked, err := lock.TryLock(w.ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return d, ErrWebhookDeliveryBusy
+	}
+	defer func() {
+		_ = lock.Unlock(context.Background())
+	}()
+
+	// the status may have been changed before we got the lock
+	d, err = w.ds.GetWebhookDelivery(deliveryId)
+	if err != nil || d == nil || isWebhookDeliveryFinished(d) {
+		// may be the same message was delivered twice by the queue
+		return d, err
+	}
+	previous, err := w.ds.GetPreviousQueuedWebhookDelivery(d.RoomId, d.Url, d.ID)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		return d, ErrWebhookDeliveryWaiting
+	}
+
+	log := w.logger.WithFields(logrus.Fields{
+		"deliveryId": d.DeliveryId,
+		"room_id":    d.RoomId,
+		"event":      d.Event,
+		"url":        d.Url,
+		"method":     "AttemptDelivery",
+	})
+
+	d.Attempts++
+	d.LastStatusCode, err = w.sendRequest(d)
+	switch {
+	case err == nil:
+		d.Status = dbmodels.WebhookDeliveryStatusDelivered
+		d.LastError = ""
+	case errors.Is(err, errWebhookSubscriptionRemoved):
+		log.WithError(err).Warn("subscription was removed, won't try again")
+		d.Status = dbmodels.WebhookDeliveryStatusDead
+		d.LastError = err.Error()
+	case d.Attempts >= w.app.Client.WebhookConf.MaxAttempts:
+		log.WithError(err).Warnf("giving up after %d attempts", d.Attempts)
+		d.Status = dbmodels.WebhookDeliveryStatusDead
+		d.LastError = TruncateString(err.Error(), maxWebhookDeliveryErrorLen)
+	default:
+		log.WithError(err).Debugf("attempt %d failed", d.Attempts)
+		d.Status = dbmodels.WebhookDeliveryStatusRetrying
+		d.LastError = TruncateString(err.Error(), maxWebhookDeliveryErrorLen)
+	}
+
+	if _, err := w.ds.InsertOrUpdateWebhookDelivery(d); err != nil {
+		log.WithError(err).Errorln("failed to update webhook delivery")
+		return d, nil
+	}
+
+	switch d.Status {
+	case dbmodels.WebhookDeliveryStatusDelivered:
+		w.publishNextDelivery(d, log)
+	case dbmodels.WebhookDeliveryStatusDead:
+		// the endpoint is unreachable, the waiting ones would end the same way
+		// & they can be sent again using the redeliver API
+		n, err := w.ds.MarkQueuedWebhookDeliveriesDead(d.RoomId, d.Url, d.ID, "not sent, previous delivery "+d.DeliveryId+" is dead")
+		if err != nil {
+			log.WithError(err).Errorln("failed to move the waiting deliveries to the dead-letter")
+			// try them one by one instead
+			w.publishNextDelivery(d, log)
+		} else if n > 0 {
+			log.Warnf("moved %d waiting deliveries to the dead-letter", n)
+		}
+	}
+	return d, nil
+}
+
+// publishNextDelivery puts the next waiting delivery of the room & url in the queue
+func (w *WebhookNotifier) publishNextDelivery(d *dbmodels.WebhookDelivery, log *logrus.Entry) {
+	next, err := w.ds.GetNextQueuedWebhookDelivery(d.RoomId, d.Url, d.ID)
+	if err != nil {
+		log.WithError(err).Errorln("failed to get the next webhook delivery")
+		return
+	}
+	if next == nil {
+		return
+	}
+	// the message id is unique for this pair, so the same one won't be published twice
+	if err = w.natsService.PublishWebhookDelivery(next.DeliveryId+"-"+d.DeliveryId, []byte(next.DeliveryId)); err != nil {
+		log.WithError(err).Errorln("failed to publish the next webhook delivery")
+	}
+}
+
+func isWebhookDeliveryFinished(d *dbmodels.WebhookDelivery) bool {
+	return d.Status == dbmodels.WebhookDeliveryStatusDelivered || d.Status == dbmodels.WebhookDeliveryStatusDead
+}
+
+// hashWebhookDeliveryKey keeps the lock key short whatever the size of the url
+func hashWebhookDeliveryKey(d *dbmodels.WebhookDelivery) string {
+	sum := sha256.Sum256([]byte(webhookDeliveryKey(d)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// WebhookRetryDelay returns the exponential backoff before the next attempt
+func (w *WebhookNotifier) WebhookRetryDelay(attempts int) time.Duration {
+	wc := w.app.Client.WebhookConf
+	delay := wc.InitialBackoff
+	for i := 1; i < attempts && delay < wc.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, wc.MaxBackoff)
+}
+
+// Redeliver puts the deliveries in the queue again, even if they were marked as dead.
+// Deliveries must belong to the same tenant, unless tenantId is empty.
+func (w *WebhookNotifier) Redeliver(tenantId string, deliveryIds ...string) error {
+	if !w.isEnabled {
+		return fmt.Errorf("webhook is not enabled")
+	}
+
+	var deliveries []*dbmodels.WebhookDelivery
+	for _, id := range deliveryIds {
+		d, err := w.ds.GetWebhookDelivery(id)
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			return config.NotFoundErr
+		}
+		if tenantId != "" && d.TenantId != tenantId {
+			return config.ErrTenantAccessDenied
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	for _, d := range deliveries {
+		d.Status = dbmodels.WebhookDeliveryStatusPending
+		d.Attempts = 0
+		if _, err := w.ds.InsertOrUpdateWebhookDelivery(d); err != nil {
+			return err
+		}
+		// the original message id could be still in the duplicate window
+		msgId := fmt.Sprintf("%s-%d", d.DeliveryId, time.Now().UnixMilli())
+		if err := w.natsService.PublishWebhookDelivery(msgId, []byte(d.DeliveryId)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *WebhookNotifier) sendRequest(d *dbmodels.WebhookDelivery) (int, error) {
+	body := []byte(d.Payload)
+	apiKey, secret, err := w.deliveryCredentials(d)
+	if err != nil {
+		return 0, err
+	}
+
+	var req *http.Request
+	if d.Format == dbmodels.WebhookDeliveryFormatBBB {
+		req, err = w.newBBBHookRequest(d, secret)
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req, err = http.NewRequestWithContext(w.ctx, http.MethodPost, d.Url, bytes.NewReader(body))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if apiKey != "" {
+			req.Header.Set("API-KEY", apiKey)
+		}
+		req.Header.Set("HASH-SIGNATURE", signWebhookPayload(secret, body))
+		req.Header.Set(webhookDeliveryIdHeader, d.DeliveryId)
+		req.Header.Set(webhookDeliveryTimestampHeader, timestamp)
+		req.Header.Set(webhookDeliverySignatureHeader, "v1="+signWebhookPayload(secret, []byte(d.DeliveryId+"."+timestamp+"."), body))
+	}
+
+	client := w.httpClient
+	if d.Url == w.defaultUrl {
+		client = w.defaultHttpClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	// the body isn't kept, the delivery logs are visible to the tenants
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxWebhookDeliveryErrorLen))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+// deliveryCredentials returns the api key & secret to sign the delivery.
+// Subscriptions are signed using their own secret without any api key.
+// The root credentials are only used for the global url & the rooms without tenant,
+// the per-meeting url of a tenant's room will get the tenant's own.
+// A secret set for the endpoint in endpoint_secrets will override the secret.
+func (w *WebhookNotifier) deliveryCredentials(d *dbmodels.WebhookDelivery) (string, string, error) {
+	if d.SubscriptionId != "" {
+		s, err := w.ds.GetWebhookSubscription(d.SubscriptionId)
+		if err != nil {
+			return "", "", err
+		}
+		if s == nil || s.Secret == "" {
+			return "", "", errWebhookSubscriptionRemoved
+		}
+		return "", s.Secret, nil
+	}
+
+	apiKey, secret := w.app.Client.ApiKey, w.natsService.GetClientSecret()
+	if d.Url != w.defaultUrl && d.TenantId != "" {
+		t, err := w.ds.GetTenant(d.TenantId)
+		if err != nil {
+			return "", "", err
+		}
+		if t == nil {
+			return "", "", fmt.Errorf("tenant %s not found", d.TenantId)
+		}
+		apiKey, secret = t.ApiKey, t.Secret
+	} else if d.Url == w.defaultUrl && w.app.Client.WebhookConf.SigningSecret != "" {
+		secret = w.app.Client.WebhookConf.SigningSecret
+	}
+
+	if s := endpointSecret(w.app.Client.WebhookConf.EndpointSecrets, d.Url); s != "" {
+		secret = s
+	}
+	return apiKey, secret, nil
+}
+
+// endpointSecret returns the secret of the longest endpoint matching the beginning of the url
+func endpointSecret(secrets map[string]string, u string) string {
+	var matched, secret string
+	for endpoint, s := range secrets {
+		if strings.HasPrefix(u, endpoint) && len(endpoint) > len(matched) {
+			matched, secret = endpoint, s
+		}
+	}
+	return secret
+}
+
+func signWebhookPayload(secret string, parts ...[]byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	for _, p := range parts {
+		mac.Write(p)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,20 @@
+package helpers
+
+import "testing"
+
+func TestEndpointSecret(t *testing.T) {
+	secrets := map[string]string{
+		"https://lms.example.com/":        "lms",
+		"https://lms.example.com/hook/v2": "v2",
+	}
+
+	for u, want := range map[string]string{
+		"https://lms.example.com/hook?id=1":    "lms",
+		"https://lms.example.com/hook/v2?id=1": "v2",
+		"https://other.example.com/hook":       "",
+	} {
+		if got := endpointSecret(secrets, u); got != want {
+			t.Errorf("endpointSecret(%s) = %q, want %q", u, got, want)
+		}
+	}
+}
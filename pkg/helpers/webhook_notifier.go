@@ -2,23 +2,27 @@ package helpers
 
 import (
 	"context"
+	"net/http"
 	"sync"
 
 	"github.com/goccy/go-json"
 	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
-	"github.com/mynaparrot/plugnmeet-protocol/webhook"
 	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
 	"github.com/mynaparrot/plugnmeet-server/pkg/services/db"
+	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
 	"github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
 	"github.com/nats-io/nats.go"
 	"github.com/sirupsen/logrus"
 	"go.uber.org/fx"
 )
 
-// roomNotifier holds a dedicated worker and the cached webhook URLs for a single room.
+// roomNotifier holds the cached webhook URLs & subscriptions for a single room.
 type roomNotifier struct {
-	worker *webhook.Notifier
-	urls   []string
+	urls          []string
+	bbbHooks      []bbbHookTarget
+	tenantId      string
+	subscriptions []dbmodels.WebhookSubscription
 }
 
 type WebhookNotifier struct {
@@ -27,29 +31,38 @@ type WebhookNotifier struct {
 	rs                   *redisservice.RedisService
 	app                  *config.AppConfig
 	natsConn             *nats.Conn
+	natsService          *natsservice.NatsService
 	isEnabled            bool
 	enabledForPerMeeting bool
 	defaultUrl           string
 	// notifiers will hold our new wrapper for each room
 	notifiers map[string]*roomNotifier
 	// mu will protect access to the notifiers map
-	mu     sync.Mutex
-	logger *logrus.Entry
+	mu sync.Mutex
+	// httpClient is used for the urls of the API users, defaultHttpClient for the global url
+	httpClient        *http.Client
+	defaultHttpClient *http.Client
+	// pendingDeliveries will be saved & published by deliveryWriter
+	pendingDeliveries chan []*dbmodels.WebhookDelivery
+	logger            *logrus.Entry
 }
 
 type webhookRedisFields struct {
-	Urls            []string `json:"urls"`
-	PerformDeleting bool     `json:"perform_deleting"`
+	Urls            []string        `json:"urls"`
+	BBBHooks        []bbbHookTarget `json:"bbb_hooks,omitempty"`
+	TenantId        string          `json:"tenant_id,omitempty"`
+	PerformDeleting bool            `json:"perform_deleting"`
 }
 
 type WebhookNotifierArgs struct {
 	fx.In
-	Ctx      context.Context
-	App      *config.AppConfig
-	NatsConn *nats.Conn
-	Ds       *dbservice.DatabaseService
-	Rs       *redisservice.RedisService
-	Logger   *logrus.Logger
+	Ctx         context.Context
+	App         *config.AppConfig
+	NatsConn    *nats.Conn
+	NatsService *natsservice.NatsService
+	Ds          *dbservice.DatabaseService
+	Rs          *redisservice.RedisService
+	Logger      *logrus.Logger
 }
 
 func NewWebhookNotifier(args WebhookNotifierArgs) *WebhookNotifier {
@@ -59,13 +72,21 @@ func NewWebhookNotifier(args WebhookNotifierArgs) *WebhookNotifier {
 		ds:                   args.Ds,
 		rs:                   args.Rs,
 		natsConn:             args.NatsConn,
+		natsService:          args.NatsService,
 		isEnabled:            args.App.Client.WebhookConf.Enable,
 		enabledForPerMeeting: args.App.Client.WebhookConf.EnableForPerMeeting,
 		defaultUrl:           args.App.Client.WebhookConf.Url,
 		notifiers:            make(map[string]*roomNotifier),
+		httpClient:           newWebhookHttpClient(args.App.Client.WebhookConf.RequestTimeout, args.App.Client.WebhookConf.AllowPrivateUrls),
+		defaultHttpClient:    &http.Client{Timeout: args.App.Client.WebhookConf.RequestTimeout},
+		pendingDeliveries:    make(chan []*dbmodels.WebhookDelivery, webhookDeliveryQueueSize),
 		logger:               args.Logger.WithField("helper", "webhookNotifier"),
 	}
 
+	if w.isEnabled {
+		go w.deliveryWriter()
+	}
+
 	return w
 }
 
@@ -83,6 +104,11 @@ func (w *WebhookNotifier) SubscribeToCleanup(lc fx.Lifecycle) {
 					"room_id": roomId,
 					"method":  "subscribeToCleanup",
 				}).Info("received webhook cleanup signal")
+				if roomId == "" {
+					// subscriptions were changed, all the rooms need to be reloaded
+					w.cleanupAllNotifiers()
+					return
+				}
 				w.cleanupNotifier(roomId)
 			})
 			if err != nil {
@@ -114,18 +140,20 @@ func (w *WebhookNotifier) getOrCreateNotifier(roomId string) *roomNotifier {
 
 	// Notifier does not exist on this server instance. Create it.
 	d, err := w.getData(roomId)
-	if err != nil || d == nil || len(d.Urls) == 0 {
-		// Log the error but don't create a notifier if there are no URLs.
+	if err != nil || d == nil {
+		// Log the error but don't create a notifier if the webhook wasn't registered.
 		if err != nil {
 			w.logger.WithField("room_id", roomId).WithError(err).Error("failed to get webhook data for notifier creation")
 		}
 		return nil
 	}
 
-	// Create the new wrapper with the fetched URLs and the worker.
+	// Create the new wrapper with the fetched URLs & subscriptions of the tenant.
 	newNotifier := &roomNotifier{
-		worker: webhook.NewNotifier(w.ctx, 1, 2, w.logger),
-		urls:   d.Urls,
+		urls:          d.Urls,
+		bbbHooks:      d.BBBHooks,
+		tenantId:      d.TenantId,
+		subscriptions: w.getActiveSubscriptions(d.TenantId),
 	}
 
 	// Lock again to safely add to the map.
@@ -135,7 +163,6 @@ func (w *WebhookNotifier) getOrCreateNotifier(roomId string) *roomNotifier {
 	// Double-check in case another goroutine created it in the meantime.
 	if existingNotifier, exists := w.notifiers[roomId]; exists {
 		// Another goroutine won the race. Discard our new one and use the existing one.
-		newNotifier.worker.Kill()
 		return existingNotifier
 	}
 
@@ -144,18 +171,17 @@ func (w *WebhookNotifier) getOrCreateNotifier(roomId string) *roomNotifier {
 	return newNotifier
 }
 
-// cleanupNotifier stops the worker and removes the notifier for a room from the local map.
+// cleanupNotifier removes the notifier for a room from the local map.
 func (w *WebhookNotifier) cleanupNotifier(roomId string) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	if notifier, ok := w.notifiers[roomId]; ok {
-		notifier.worker.Kill() // This will call Kill() on the worker.
+	if _, ok := w.notifiers[roomId]; ok {
 		delete(w.notifiers, roomId)
 		w.logger.WithFields(logrus.Fields{
 			"room_id": roomId,
 			"method":  "cleanupNotifier",
-		}).Info("cleaned up webhook queue for room")
+		}).Info("cleaned up cached webhook urls for room")
 	}
 }
 
@@ -182,22 +208,28 @@ func (w *WebhookNotifier) RegisterWebhook(roomId, sid string) {
 		log.WithField("default_url", w.defaultUrl).Debug("added default webhook url")
 	}
 
+	var bbbHooks []bbbHookTarget
+	roomInfo, _ := w.ds.GetRoomInfoBySid(sid, nil)
 	if w.enabledForPerMeeting {
-		roomInfo, _ := w.ds.GetRoomInfoBySid(sid, nil)
 		if roomInfo != nil && roomInfo.WebhookUrl != "" {
 			urls = append(urls, roomInfo.WebhookUrl)
 			log.WithField("per_meeting_url", roomInfo.WebhookUrl).Debug("added per-meeting webhook url")
 		}
+		bbbHooks = w.getBBBHooks(roomId, log)
 	}
 
-	if len(urls) < 1 {
-		log.Info("no webhook urls found to register")
-		return
+	var tenantId string
+	if roomInfo != nil {
+		tenantId = roomInfo.TenantId
 	}
+	// we'll register even without any url, because the subscriptions
+	// created after the room was started should receive the events too.
 	log.WithField("urls", urls).Info("found webhook urls to register")
 
 	d := &webhookRedisFields{
 		Urls:            urls,
+		BBBHooks:        bbbHooks,
+		TenantId:        tenantId,
 		PerformDeleting: false,
 	}
 
@@ -229,8 +261,11 @@ func (w *WebhookNotifier) DeleteWebhook(roomId string, log *logrus.Entry) {
 		return
 	}
 
+	// hooks registered using BBB API will end with the meeting
+	w.deleteBBBHooks(roomId, log)
+
 	// Broadcast a cleanup message to all servers in the cluster.
-	// Only the server running the worker for this room will act on it.
+	// Only the servers having cached urls for this room will act on it.
 	if err := w.natsConn.Publish(redisservice.WebhookCleanupSubject, []byte(roomId)); err != nil {
 		log.WithError(err).Error("failed to publish webhook cleanup")
 	}
@@ -270,12 +305,16 @@ func (w *WebhookNotifier) SendWebhookEvent(event *plugnmeet.CommonNotifyEvent) e
 		return nil
 	}
 
-	// Send the event to the queue using the static app config and the cached URLs.
-	notifier.worker.AddInNotifyQueue(event, w.app.Client.ApiKey, w.app.Client.Secret, notifier.urls)
+	// Send the event to the delivery queue using the cached URLs & matching subscribers.
+	subscriptions := matchSubscriptions(notifier.urls, notifier.subscriptions, roomId, event.GetEvent())
+	if len(notifier.urls) < 1 && len(subscriptions) < 1 && len(notifier.bbbHooks) < 1 {
+		return nil
+	}
+	w.enqueue(event, notifier.tenantId, notifier.urls, subscriptions, notifier.bbbHooks)
 	return nil
 }
 
-// ForceToPutInQueue sends a webhook event synchronously without using the room's queue.
+// ForceToPutInQueue sends a webhook event without using the cached urls of the room.
 // This method should be used for one-shot events outside the normal room lifecycle.
 // It directly queries the database for webhook URLs.
 func (w *WebhookNotifier) ForceToPutInQueue(event *plugnmeet.CommonNotifyEvent) {
@@ -292,20 +331,53 @@ func (w *WebhookNotifier) ForceToPutInQueue(event *plugnmeet.CommonNotifyEvent)
 		urls = append(urls, w.defaultUrl)
 	}
 
+	var tenantId string
+	var bbbHooks []bbbHookTarget
+	roomInfo, _ := w.ds.GetRoomInfoBySid(event.Room.GetSid(), nil)
+	if roomInfo != nil {
+		tenantId = roomInfo.TenantId
+	}
 	if w.enabledForPerMeeting {
-		roomInfo, _ := w.ds.GetRoomInfoBySid(event.Room.GetSid(), nil)
 		if roomInfo != nil && roomInfo.WebhookUrl != "" {
 			urls = append(urls, roomInfo.WebhookUrl)
 		}
+		bbbHooks = w.getBBBHooks(event.Room.GetRoomId(), w.logger)
+	}
+	subscriptions := matchSubscriptions(urls, w.getActiveSubscriptions(tenantId), event.Room.GetRoomId(), event.GetEvent())
+
+	if len(urls) < 1 && len(subscriptions) < 1 && len(bbbHooks) < 1 {
+		return
+	}
+
+	w.enqueue(event, tenantId, urls, subscriptions, bbbHooks)
+}
+
+// SendScheduleWebhookEvent sends events of a room schedule.
+// Schedules live outside the room lifecycle, so there is no room sid or queue yet;
+// the per-meeting url will be taken from the schedule itself.
+func (w *WebhookNotifier) SendScheduleWebhookEvent(event *plugnmeet.CommonNotifyEvent, tenantId, scheduleWebhookUrl string) {
+	if !w.isEnabled {
+		return
+	}
+	if event.Room.GetRoomId() == "" {
+		w.logger.Errorln("empty room info for", event.GetEvent())
+		return
+	}
+
+	var urls []string
+	if w.defaultUrl != "" {
+		urls = append(urls, w.defaultUrl)
+	}
+	if w.enabledForPerMeeting && scheduleWebhookUrl != "" {
+		urls = append(urls, scheduleWebhookUrl)
 	}
+	subscriptions := matchSubscriptions(urls, w.getActiveSubscriptions(tenantId), event.Room.GetRoomId(), event.GetEvent())
 
-	if len(urls) < 1 {
+	if len(urls) < 1 && len(subscriptions) < 1 {
 		return
 	}
 
-	notifier := webhook.NewNotifier(w.ctx, 1, 2, w.logger)
-	defer notifier.StopGracefully()
-	notifier.AddInNotifyQueue(event, w.app.Client.ApiKey, w.app.Client.Secret, urls)
+	w.enqueue(event, tenantId, urls, subscriptions, nil)
 }
 
 func (w *WebhookNotifier) saveData(roomId string, d *webhookRedisFields) error {
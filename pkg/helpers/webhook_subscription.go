@@ -0,0 +1,62 @@
+package helpers
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+)
+
+// getActiveSubscriptions returns the subscriptions which may receive events of the tenant's rooms
+func (w *WebhookNotifier) getActiveSubscriptions(tenantId string) []dbmodels.WebhookSubscription {
+	subscriptions, err := w.ds.GetActiveWebhookSubscriptions(tenantId)
+	if err != nil {
+		w.logger.WithError(err).WithField("tenant_id", tenantId).Error("failed to get webhook subscriptions")
+		return nil
+	}
+	return subscriptions
+}
+
+// RefreshSubscriptions asks all the servers to drop their cached notifiers,
+// so that the changes of subscriptions will be used from the next event.
+func (w *WebhookNotifier) RefreshSubscriptions() {
+	if err := w.natsConn.Publish(redisservice.WebhookCleanupSubject, []byte("")); err != nil {
+		w.logger.WithError(err).Error("failed to publish webhook cleanup")
+	}
+}
+
+// cleanupAllNotifiers removes all the notifiers from the local map.
+func (w *WebhookNotifier) cleanupAllNotifiers() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	clear(w.notifiers)
+}
+
+// matchSubscriptions returns the subscriptions matching the room & event,
+// excluding the ones already receiving the event using the other urls.
+func matchSubscriptions(urls []string, subscriptions []dbmodels.WebhookSubscription, roomId, event string) []*dbmodels.WebhookSubscription {
+	var matched []*dbmodels.WebhookSubscription
+	for i := range subscriptions {
+		sub := &subscriptions[i]
+		if !subscriptionMatches(sub, roomId, event) || slices.Contains(urls, sub.Url) {
+			continue
+		}
+		if slices.ContainsFunc(matched, func(m *dbmodels.WebhookSubscription) bool { return m.Url == sub.Url }) {
+			continue
+		}
+		matched = append(matched, sub)
+	}
+	return matched
+}
+
+// subscriptionMatches checks if the event of the room should be sent to the subscription
+func subscriptionMatches(sub *dbmodels.WebhookSubscription, roomId, event string) bool {
+	if sub.IsActive != 1 || !strings.HasPrefix(roomId, sub.RoomIdPrefix) {
+		return false
+	}
+	if sub.Events == "" {
+		return true
+	}
+	return slices.Contains(strings.Split(sub.Events, ","), event)
+}
@@ -0,0 +1,32 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+)
+
+func TestMatchSubscriptions(t *testing.T) {
+	subscriptions := []dbmodels.WebhookSubscription{
+		{SubscriptionId: "all", Url: "https://a.example.com", IsActive: 1},
+		{SubscriptionId: "joined", Url: "https://b.example.com", Events: "participant_joined", IsActive: 1},
+		{SubscriptionId: "prefix", Url: "https://c.example.com", RoomIdPrefix: "class-", IsActive: 1},
+		{SubscriptionId: "inactive", Url: "https://d.example.com", IsActive: 0},
+		{SubscriptionId: "global", Url: "https://global.example.com", IsActive: 1},
+		{SubscriptionId: "duplicate", Url: "https://a.example.com", IsActive: 1},
+	}
+
+	matched := matchSubscriptions([]string{"https://global.example.com"}, subscriptions, "class-01", "room_started")
+	var ids []string
+	for _, s := range matched {
+		ids = append(ids, s.SubscriptionId)
+	}
+	if len(ids) != 2 || ids[0] != "all" || ids[1] != "prefix" {
+		t.Errorf("unexpected subscriptions: %v", ids)
+	}
+
+	matched = matchSubscriptions(nil, subscriptions, "room01", "participant_joined")
+	if len(matched) != 3 || matched[1].SubscriptionId != "joined" {
+		t.Errorf("unexpected subscriptions: %d", len(matched))
+	}
+}
@@ -0,0 +1,86 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const (
+	maxWebhookUrlLen        = 2048
+	webhookUrlLookupTimeout = 5 * time.Second
+)
+
+var errWebhookAddressNotAllowed = errors.New("address of a private network is not allowed")
+
+// ValidateWebhookUrl checks the url given by the API users, e.g. subscriptions, per-meeting urls & BBB hooks.
+// The host must resolve to public addresses unless allow_private_urls is enabled.
+func (w *WebhookNotifier) ValidateWebhookUrl(u string) error {
+	parsed, err := url.ParseRequestURI(u)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return fmt.Errorf("invalid url, only http & https urls are allowed")
+	}
+	if len(u) > maxWebhookUrlLen {
+		return fmt.Errorf("url is too long")
+	}
+	if w.app.Client.WebhookConf.AllowPrivateUrls {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(w.ctx, webhookUrlLookupTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, parsed.Hostname())
+	if err != nil {
+		return fmt.Errorf("unable to resolve host %s", parsed.Hostname())
+	}
+	for _, addr := range addrs {
+		if !isPublicWebhookIP(addr.IP) {
+			return fmt.Errorf("invalid url, %w", errWebhookAddressNotAllowed)
+		}
+	}
+	return nil
+}
+
+// isPublicWebhookIP reports if the webhook requests can be sent to the ip
+func isPublicWebhookIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast()
+}
+
+// webhookDialControl checks the address again when connecting,
+// so the host can't be changed to a private one after the url was validated.
+func webhookDialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicWebhookIP(ip) {
+		return errWebhookAddressNotAllowed
+	}
+	return nil
+}
+
+// newWebhookHttpClient returns the client for the urls of the API users,
+// it can't connect to private networks unless allowPrivate is true.
+func newWebhookHttpClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	if allowPrivate {
+		return &http.Client{Timeout: timeout}
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   webhookDialControl,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// the dialer would check the address of the proxy instead of the url
+	transport.Proxy = nil
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,53 @@
+package helpers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+)
+
+func TestWebhookDialControl(t *testing.T) {
+	for address, allowed := range map[string]bool{
+		"93.184.216.34:443":    true,
+		"[2606:4700::1]:443":   true,
+		"127.0.0.1:80":         false,
+		"[::1]:80":             false,
+		"169.254.169.254:80":   false,
+		"10.0.0.5:8080":        false,
+		"172.16.3.1:80":        false,
+		"192.168.1.1:80":       false,
+		"0.0.0.0:80":           false,
+		"[fe80::1]:80":         false,
+		"[fd00::1]:80":         false,
+		"[::ffff:10.0.0.1]:80": false,
+	} {
+		if err := webhookDialControl("tcp", address, nil); (err == nil) != allowed {
+			t.Errorf("%s: allowed %v, got error %v", address, allowed, err)
+		}
+	}
+}
+
+func TestValidateWebhookUrl(t *testing.T) {
+	w := &WebhookNotifier{ctx: context.Background(), app: &config.AppConfig{}}
+	for _, u := range []string{
+		"ftp://93.184.216.34/hook",
+		"http://127.0.0.1:8080/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"https://10.1.2.3/hook",
+		"http://[::1]/hook",
+		"http://localhost/hook",
+	} {
+		if err := w.ValidateWebhookUrl(u); err == nil {
+			t.Errorf("%s should be rejected", u)
+		}
+	}
+	if err := w.ValidateWebhookUrl("https://93.184.216.34/hook"); err != nil {
+		t.Errorf("public address should be allowed: %v", err)
+	}
+
+	w.app.Client.WebhookConf.AllowPrivateUrls = true
+	if err := w.ValidateWebhookUrl("http://10.1.2.3/hook"); err != nil {
+		t.Errorf("private address should be allowed by the config: %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/livekit/media-sdk"
 	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
@@ -41,6 +42,10 @@ type TranscriptionEvent struct {
 	Type   EventType                              `json:"type"`
 	Error  string                                 `json:"error,omitempty"`
 	Result *plugnmeet.InsightsTranscriptionResult `json:"result,omitempty"`
+	// SpeakerLabel of a diarized track, e.g. Guest-1
+	SpeakerLabel string `json:"speaker_label,omitempty"`
+	// Duration of the speech of a final result, if the provider knows it
+	Duration time.Duration `json:"duration,omitempty"`
 }
 
 // ServiceType defines the canonical name for an insights service.
@@ -55,6 +60,9 @@ const (
 	ServiceTypeSpeechSynthesis    ServiceType = "speech-synthesis"
 	ServiceTypeAITextChat         ServiceType = "ai_text_chat"
 	ServiceTypeMeetingSummarizing ServiceType = "meeting_summarizing"
+	// ServiceTypeLiveSummary summarizes the transcription periodically during the session.
+	// It's handled by the server only, so it isn't part of the protocol's enum.
+	ServiceTypeLiveSummary ServiceType = "live_summary"
 
 	// AITaskTypeChat is for regular chat interactions.
 	AITaskTypeChat AITaskType = "chat"
@@ -122,6 +130,8 @@ type TranscriptionOptions struct {
 	TransLangs                  []string `json:"transLangs"`
 	UserName                    string   `json:"userName"`
 	AllowedTranscriptionStorage bool     `json:"allowedTranscriptionStorage"`
+	// Diarization separates the speakers of the track, if the provider supports it
+	Diarization bool `json:"diarization,omitempty"`
 }
 
 // TranslationTaskOptions defines the structure for options passed to the translation service.
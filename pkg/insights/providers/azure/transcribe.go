@@ -35,12 +35,13 @@ func newTranscribeClient(creds *config.CredentialsConfig, model string, log *log
 
 func (c *transcribeClient) CreateTranscription(mainCtx context.Context, roomId, userId string, opts *insights.TranscriptionOptions) (insights.TranscriptionStream, error) {
 	log := c.log.WithFields(logrus.Fields{
-		"method":     "CreateTranscription",
-		"roomId":     roomId,
-		"userId":     userId,
-		"lang":       opts.SpokenLang,
-		"transLangs": opts.TransLangs,
-		"storage":    opts.AllowedTranscriptionStorage,
+		"method":      "CreateTranscription",
+		"roomId":      roomId,
+		"userId":      userId,
+		"lang":        opts.SpokenLang,
+		"transLangs":  opts.TransLangs,
+		"storage":     opts.AllowedTranscriptionStorage,
+		"diarization": opts.Diarization,
 	})
 	log.Infoln("starting transcription")
 
@@ -59,6 +60,14 @@ func (c *transcribeClient) CreateTranscription(mainCtx context.Context, roomId,
 		return nil, err
 	}
 
+	if opts.Diarization {
+		if len(opts.TransLangs) == 0 {
+			return c.createConversationTranscription(mainCtx, userId, opts, inputStream, audioConfig, log)
+		}
+		// the translation recognizer can't separate the speakers
+		log.Warnln("diarization isn't supported together with translation, continuing without it")
+	}
+
 	cnf, err := speech.NewSpeechTranslationConfigFromSubscription(c.creds.APIKey, c.creds.Region)
 	if err != nil {
 		return nil, err
@@ -81,27 +90,7 @@ func (c *transcribeClient) CreateTranscription(mainCtx context.Context, roomId,
 		return nil, err
 	}
 
-	resultsChan := make(chan *insights.TranscriptionEvent, 64)
-	var closeOnce sync.Once
-	safeClose := func() {
-		closeOnce.Do(func() {
-			close(resultsChan)
-		})
-	}
-
-	// safeSend is non-blocking; if the consumer is not keeping up the event is dropped.
-	safeSend := func(event *insights.TranscriptionEvent) {
-		defer func() {
-			if r := recover(); r != nil {
-				log.Warnln("could not send to resultsChan, likely closed:", r)
-			}
-		}()
-		select {
-		case resultsChan <- event:
-		default:
-			log.Warnf("resultsChan full, dropping event type=%s", event.Type)
-		}
-	}
+	resultsChan, safeSend, safeClose := newResultsChan(log)
 
 	recognizer.SessionStarted(func(e speech.SessionEventArgs) {
 		log.Infoln("azure transcription started")
@@ -146,8 +135,9 @@ func (c *transcribeClient) CreateTranscription(mainCtx context.Context, roomId,
 			result.Translations[GetLocaleFromCode(lang)] = text
 		}
 		safeSend(&insights.TranscriptionEvent{
-			Type:   insights.EventTypeFinalResult,
-			Result: result,
+			Type:     insights.EventTypeFinalResult,
+			Result:   result,
+			Duration: e.Result.Duration,
 		})
 	})
 
@@ -184,3 +174,29 @@ func (c *transcribeClient) CreateTranscription(mainCtx context.Context, roomId,
 
 	return stream, nil
 }
+
+// newResultsChan returns the results channel with the helpers to send & close it safely from the SDK callbacks
+func newResultsChan(log *logrus.Entry) (chan *insights.TranscriptionEvent, func(*insights.TranscriptionEvent), func()) {
+	resultsChan := make(chan *insights.TranscriptionEvent, 64)
+	var closeOnce sync.Once
+	safeClose := func() {
+		closeOnce.Do(func() {
+			close(resultsChan)
+		})
+	}
+
+	// safeSend is non-blocking; if the consumer is not keeping up the event is dropped.
+	safeSend := func(event *insights.TranscriptionEvent) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Warnln("could not send to resultsChan, likely closed:", r)
+			}
+		}()
+		select {
+		case resultsChan <- event:
+		default:
+			log.Warnf("resultsChan full, dropping event type=%s", event.Type)
+		}
+	}
+	return resultsChan, safeSend, safeClose
+}
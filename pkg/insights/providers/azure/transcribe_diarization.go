@@ -0,0 +1,106 @@
+package azure
+
+import (
+	"context"
+
+	"github.com/Microsoft/cognitive-services-speech-sdk-go/audio"
+	"github.com/Microsoft/cognitive-services-speech-sdk-go/speech"
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
+	"github.com/sirupsen/logrus"
+)
+
+// unknownSpeakerId is used by Azure until the speaker of the speech was identified
+const unknownSpeakerId = "Unknown"
+
+// createConversationTranscription uses the conversation transcriber, which labels the speakers
+// of the track, e.g. Guest-1. It doesn't support translation.
+func (c *transcribeClient) createConversationTranscription(mainCtx context.Context, userId string, opts *insights.TranscriptionOptions, inputStream *audio.PushAudioInputStream, audioConfig *audio.AudioConfig, log *logrus.Entry) (insights.TranscriptionStream, error) {
+	cnf, err := speech.NewSpeechConfigFromSubscription(c.creds.APIKey, c.creds.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cnf.SetSpeechRecognitionLanguage(opts.SpokenLang); err != nil {
+		return nil, err
+	}
+
+	transcriber, err := speech.NewConversationTranscriberFromConfig(cnf, audioConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsChan, safeSend, safeClose := newResultsChan(log)
+
+	transcriber.SessionStarted(func(e speech.SessionEventArgs) {
+		log.Infoln("azure conversation transcription started")
+		safeSend(&insights.TranscriptionEvent{Type: insights.EventTypeSessionStarted})
+	})
+	transcriber.SessionStopped(func(e speech.SessionEventArgs) {
+		log.Infoln("azure conversation transcription stopped")
+		safeSend(&insights.TranscriptionEvent{Type: insights.EventTypeSessionStopped})
+		safeClose()
+	})
+
+	newEvent := func(e speech.ConversationTranscriptionEventArgs, eventType insights.EventType) *insights.TranscriptionEvent {
+		speaker := e.Result.SpeakerID
+		if speaker == unknownSpeakerId {
+			speaker = ""
+		}
+		return &insights.TranscriptionEvent{
+			Type: eventType,
+			Result: &plugnmeet.InsightsTranscriptionResult{
+				FromUserId:                  userId,
+				FromUserName:                opts.UserName,
+				Lang:                        GetLocaleFromCode(opts.SpokenLang),
+				Text:                        e.Result.Text,
+				IsPartial:                   eventType == insights.EventTypePartialResult,
+				AllowedTranscriptionStorage: opts.AllowedTranscriptionStorage,
+				Translations:                make(map[string]string),
+			},
+			SpeakerLabel: speaker,
+		}
+	}
+
+	transcriber.Transcribing(func(e speech.ConversationTranscriptionEventArgs) {
+		safeSend(newEvent(e, insights.EventTypePartialResult))
+	})
+	transcriber.Transcribed(func(e speech.ConversationTranscriptionEventArgs) {
+		if e.Result.Text == "" {
+			return
+		}
+		event := newEvent(e, insights.EventTypeFinalResult)
+		event.Duration = e.Result.Duration
+		safeSend(event)
+	})
+
+	transcriber.Canceled(func(e speech.ConversationTranscriptionCanceledEventArgs) {
+		log.Infof("Azure conversation transcription canceled: %v\n", e.ErrorDetails)
+		safeSend(&insights.TranscriptionEvent{
+			Type:  insights.EventTypeError,
+			Error: e.ErrorDetails,
+		})
+		safeClose()
+	})
+
+	if err = <-transcriber.StartTranscribingAsync(); err != nil {
+		log.WithError(err).Errorln("Error starting Azure conversation transcription")
+		safeClose()
+		transcriber.Close()
+		audioConfig.Close()
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(mainCtx)
+	go func() {
+		<-ctx.Done()
+		<-transcriber.StopTranscribingAsync()
+		transcriber.Close()
+	}()
+
+	return &azureTranscribeStream{
+		pushStream: inputStream,
+		cancel:     cancel,
+		results:    resultsChan,
+	}, nil
+}
@@ -0,0 +1,202 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
+	"github.com/mynaparrot/plugnmeet-server/pkg/insights/providers/openai"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	sttEngineWhisper = "whisper"
+	sttEngineVosk    = "vosk"
+
+	defaultHttpTimeout = time.Minute
+)
+
+// LocalProvider implements the insights.Provider interface for self-hosted services,
+// so no audio or text leaves the infrastructure.
+// Speech-to-text uses a whisper.cpp/faster-whisper HTTP server or a vosk websocket server,
+// translation & chat use an OpenAI compatible LLM endpoint (Ollama, llama.cpp)
+// and text-to-speech uses a Piper HTTP server.
+type LocalProvider struct {
+	account    *config.ProviderAccount
+	service    *config.ServiceConfig
+	httpClient *http.Client
+	// llm is nil if llm_endpoint wasn't configured
+	llm    insights.Provider
+	logger *logrus.Entry
+}
+
+// NewProvider creates a new local provider.
+func NewProvider(ctx context.Context, providerAccount *config.ProviderAccount, serviceConfig *config.ServiceConfig, log *logrus.Entry) (insights.Provider, error) {
+	p := &LocalProvider{
+		account: providerAccount,
+		service: serviceConfig,
+		httpClient: &http.Client{
+			Timeout: defaultHttpTimeout,
+		},
+		logger: log,
+	}
+
+	if endpoint := providerAccount.GetOptionsString("llm_endpoint", ""); endpoint != "" {
+		llm, err := newLLMProvider(ctx, providerAccount, serviceConfig, endpoint, log)
+		if err != nil {
+			return nil, err
+		}
+		p.llm = llm
+	}
+
+	return p, nil
+}
+
+// llmModelOptions are the options of the OpenAI provider to select the models
+var llmModelOptions = []string{"model", "chat_model", "summarize_model"}
+
+// newLLMProvider uses the OpenAI provider with the local endpoint,
+// llm_model must be set because the defaults of OpenAI won't exist in the local server.
+func newLLMProvider(ctx context.Context, account *config.ProviderAccount, service *config.ServiceConfig, endpoint string, log *logrus.Entry) (insights.Provider, error) {
+	model := account.GetOptionsString("llm_model", "")
+	if model == "" {
+		return nil, fmt.Errorf("local provider requires llm_model with llm_endpoint")
+	}
+
+	apiKey := account.Credentials.APIKey
+	if apiKey == "" {
+		// Ollama & llama.cpp don't check the key, but the client needs one
+		apiKey = "local"
+	}
+	llmAccount := &config.ProviderAccount{
+		ID: account.ID,
+		Credentials: config.CredentialsConfig{
+			APIKey: apiKey,
+		},
+		Options: map[string]interface{}{
+			"endpoint": endpoint,
+		},
+	}
+
+	llmService := &config.ServiceConfig{
+		Provider: service.Provider,
+		ID:       service.ID,
+		Options:  make(map[string]interface{}, len(service.Options)+3),
+		Pricing:  service.Pricing,
+	}
+	for k, v := range service.Options {
+		llmService.Options[k] = v
+	}
+	// the model of the service is the speech model, e.g. large-v3 of whisper
+	for _, key := range llmModelOptions {
+		llmService.Options[key] = model
+	}
+
+	return openai.NewProvider(ctx, llmAccount, llmService, log.WithField("service", "local-llm"), nil)
+}
+
+func (p *LocalProvider) getLLM() (insights.Provider, error) {
+	if p.llm == nil {
+		return nil, fmt.Errorf("local provider requires llm_endpoint for this service")
+	}
+	return p.llm, nil
+}
+
+// CreateTranscription initializes a transcription stream with the configured stt_engine.
+func (p *LocalProvider) CreateTranscription(ctx context.Context, roomId, userId string, options []byte) (insights.TranscriptionStream, error) {
+	opts := &insights.TranscriptionOptions{}
+	if len(options) > 0 {
+		if err := json.Unmarshal(options, opts); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal transcription options: %w", err)
+		}
+	}
+
+	endpoint := p.account.GetOptionsString("stt_endpoint", "")
+	if endpoint == "" {
+		return nil, fmt.Errorf("local provider requires stt_endpoint for transcription")
+	}
+
+	log := p.logger.WithFields(logrus.Fields{
+		"method":     "CreateTranscription",
+		"roomId":     roomId,
+		"userId":     userId,
+		"lang":       opts.SpokenLang,
+		"transLangs": opts.TransLangs,
+	})
+
+	switch engine := strings.ToLower(p.account.GetOptionsString("stt_engine", sttEngineWhisper)); engine {
+	case sttEngineWhisper:
+		return newWhisperStream(ctx, p, endpoint, userId, opts, log.WithField("service", "local-whisper"))
+	case sttEngineVosk:
+		return newVoskStream(ctx, p, endpoint, userId, opts, log.WithField("service", "local-vosk"))
+	default:
+		return nil, fmt.Errorf("unknown stt_engine: %s", engine)
+	}
+}
+
+// TranslateText performs stateless translation using the local LLM.
+func (p *LocalProvider) TranslateText(ctx context.Context, text, sourceLang string, targetLangs []string) (*plugnmeet.InsightsTextTranslationResult, error) {
+	llm, err := p.getLLM()
+	if err != nil {
+		return nil, err
+	}
+	return llm.TranslateText(ctx, text, sourceLang, targetLangs)
+}
+
+// SynthesizeText performs stateless text-to-speech synthesis using the Piper server.
+func (p *LocalProvider) SynthesizeText(ctx context.Context, options []byte) (io.ReadCloser, error) {
+	opts := &insights.SynthesisTaskOptions{}
+	if len(options) > 0 {
+		if err := json.Unmarshal(options, opts); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal synthesis options: %w", err)
+		}
+	}
+
+	return p.synthesizeText(ctx, opts.Text, opts.Language, opts.Voice)
+}
+
+// GetSupportedLanguages returns the languages of the service configuration,
+// as those depend on the models installed in the local servers.
+func (p *LocalProvider) GetSupportedLanguages(serviceType insights.ServiceType) []*plugnmeet.InsightsSupportedLangInfo {
+	return getConfiguredLanguages(p.service)
+}
+
+// AITextChatStream sends a prompt with history and streams back the response of the local LLM.
+func (p *LocalProvider) AITextChatStream(ctx context.Context, chatModel string, history []*plugnmeet.InsightsAITextChatContent) (<-chan *plugnmeet.InsightsAITextChatStreamResult, error) {
+	llm, err := p.getLLM()
+	if err != nil {
+		return nil, err
+	}
+	return llm.AITextChatStream(ctx, chatModel, history)
+}
+
+// AIChatTextSummarize summarizes a conversation history using the local LLM.
+func (p *LocalProvider) AIChatTextSummarize(ctx context.Context, summarizeModel string, history []*plugnmeet.InsightsAITextChatContent) (summaryText string, promptTokens uint32, completionTokens uint32, err error) {
+	llm, err := p.getLLM()
+	if err != nil {
+		return "", 0, 0, err
+	}
+	return llm.AIChatTextSummarize(ctx, summarizeModel, history)
+}
+
+// StartBatchSummarizeAudioFile is not supported by the local provider.
+func (p *LocalProvider) StartBatchSummarizeAudioFile(ctx context.Context, filePath, summarizeModel, userPrompt string) (string, string, error) {
+	return "", "", fmt.Errorf("batch summarization is not supported by the local provider")
+}
+
+// CheckBatchJobStatus is not supported by the local provider.
+func (p *LocalProvider) CheckBatchJobStatus(ctx context.Context, jobId string) (*insights.BatchJobResponse, error) {
+	return nil, fmt.Errorf("batch summarization is not supported by the local provider")
+}
+
+// DeleteUploadedFile is a no-op as nothing was uploaded.
+func (p *LocalProvider) DeleteUploadedFile(ctx context.Context, fileName string) error {
+	return nil
+}
@@ -0,0 +1,230 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/livekit/media-sdk"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestProvider(t *testing.T, accountOptions, serviceOptions map[string]interface{}) *LocalProvider {
+	t.Helper()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	p, err := NewProvider(context.Background(), &config.ProviderAccount{
+		ID:      "on-prem",
+		Options: accountOptions,
+	}, &config.ServiceConfig{
+		Provider: "local",
+		ID:       "on-prem",
+		Options:  serviceOptions,
+	}, log.WithField("test", t.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.(*LocalProvider)
+}
+
+// loudSample is a second of audio above the speech threshold
+func loudSample() media.PCM16Sample {
+	pcm := make(media.PCM16Sample, inputSampleRate)
+	for i := range pcm {
+		pcm[i] = 2000
+	}
+	return pcm
+}
+
+// collectEvents returns the events until the results channel is closed
+func collectEvents(t *testing.T, results <-chan *insights.TranscriptionEvent) []*insights.TranscriptionEvent {
+	t.Helper()
+	var events []*insights.TranscriptionEvent
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e, ok := <-results:
+			if !ok {
+				return events
+			}
+			events = append(events, e)
+		case <-timeout:
+			t.Fatal("timed out waiting for the results")
+		}
+	}
+}
+
+func finalTexts(events []*insights.TranscriptionEvent) []string {
+	var texts []string
+	for _, e := range events {
+		if e.Type == insights.EventTypeFinalResult {
+			texts = append(texts, e.Result.Text)
+		}
+	}
+	return texts
+}
+
+func TestWhisperStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.FormValue("model") != "large-v3" || r.FormValue("language") != "en" {
+			http.Error(w, "unexpected fields", http.StatusBadRequest)
+			return
+		}
+		if _, _, err := r.FormFile("file"); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"text":" hello world "}`))
+	}))
+	defer srv.Close()
+
+	p := newTestProvider(t, map[string]interface{}{
+		"stt_endpoint": srv.URL,
+	}, map[string]interface{}{
+		"model": "large-v3",
+	})
+	opts, _ := json.Marshal(&insights.TranscriptionOptions{SpokenLang: "en-US"})
+	stream, err := p.CreateTranscription(context.Background(), "room01", "user01", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = stream.WriteSample(loudSample()); err != nil {
+		t.Fatal(err)
+	}
+	// the remaining speech will be transcribed on close
+	_ = stream.Close()
+
+	events := collectEvents(t, stream.Results())
+	for _, e := range events {
+		if e.Type == insights.EventTypeError {
+			t.Fatalf("unexpected error: %s", e.Error)
+		}
+	}
+	if texts := finalTexts(events); len(texts) != 1 || texts[0] != "hello world" {
+		t.Errorf("unexpected final results: %v", texts)
+	}
+}
+
+func TestVoskStream(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		cnf := new(voskConfigMsg)
+		if err = conn.ReadJSON(cnf); err != nil || cnf.Config.SampleRate != inputSampleRate {
+			return
+		}
+		for {
+			mt, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if mt == websocket.BinaryMessage {
+				_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"partial":"hello"}`))
+				continue
+			}
+			if strings.Contains(string(data), "eof") {
+				_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"text":"hello world"}`))
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	p := newTestProvider(t, map[string]interface{}{
+		"stt_engine":   "vosk",
+		"stt_endpoint": "ws" + strings.TrimPrefix(srv.URL, "http"),
+	}, nil)
+	stream, err := p.CreateTranscription(context.Background(), "room01", "user01", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = stream.WriteSample(loudSample()); err != nil {
+		t.Fatal(err)
+	}
+	// wait for the partial result before sending eof
+	time.Sleep(100 * time.Millisecond)
+	_ = stream.Close()
+
+	events := collectEvents(t, stream.Results())
+	hasPartial := false
+	for _, e := range events {
+		if e.Type == insights.EventTypePartialResult && e.Result.Text == "hello" {
+			hasPartial = true
+		}
+	}
+	if !hasPartial {
+		t.Error("partial result is missing")
+	}
+	if texts := finalTexts(events); len(texts) != 1 || texts[0] != "hello world" {
+		t.Errorf("unexpected final results: %v", texts)
+	}
+}
+
+func TestLLMTranslateUsesLLMModel(t *testing.T) {
+	var model string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		req := struct {
+			Model string `json:"model"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		model = req.Model
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"llama3.1:8b",` +
+			`"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"{\"es\":\"hola\"}"}}]}`))
+	}))
+	defer srv.Close()
+
+	p := newTestProvider(t, map[string]interface{}{
+		"llm_endpoint": srv.URL + "/v1",
+		"llm_model":    "llama3.1:8b",
+	}, map[string]interface{}{
+		// the speech model of the transcription service must not be sent to the LLM
+		"model": "large-v3",
+	})
+
+	res, err := p.TranslateText(context.Background(), "hello", "en", []string{"es"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model != "llama3.1:8b" {
+		t.Errorf("expected llm_model, got %q", model)
+	}
+	if res.Translations["es"] != "hola" {
+		t.Errorf("unexpected translations: %v", res.Translations)
+	}
+}
+
+func TestLLMRequiresModel(t *testing.T) {
+	_, err := NewProvider(context.Background(), &config.ProviderAccount{
+		ID: "on-prem",
+		Options: map[string]interface{}{
+			"llm_endpoint": "http://127.0.0.1:11434/v1",
+		},
+	}, &config.ServiceConfig{Provider: "local", ID: "on-prem"}, logrus.NewEntry(logrus.New()))
+	if err == nil {
+		t.Error("llm_endpoint without llm_model should fail")
+	}
+}
@@ -0,0 +1,68 @@
+package local
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+)
+
+// getConfiguredLanguages reads the languages option of the service, e.g.
+//
+//	languages:
+//	  en: "English"
+//	  de: "German"
+//
+// The code is used as the locale as well.
+func getConfiguredLanguages(service *config.ServiceConfig) []*plugnmeet.InsightsSupportedLangInfo {
+	if service == nil || service.Options == nil {
+		return []*plugnmeet.InsightsSupportedLangInfo{}
+	}
+
+	var langs []*plugnmeet.InsightsSupportedLangInfo
+	switch v := service.Options["languages"].(type) {
+	case map[string]interface{}:
+		for code, name := range v {
+			langs = append(langs, &plugnmeet.InsightsSupportedLangInfo{
+				Code:   code,
+				Name:   fmt.Sprint(name),
+				Locale: code,
+			})
+		}
+	case map[interface{}]interface{}:
+		for code, name := range v {
+			langs = append(langs, &plugnmeet.InsightsSupportedLangInfo{
+				Code:   fmt.Sprint(code),
+				Name:   fmt.Sprint(name),
+				Locale: fmt.Sprint(code),
+			})
+		}
+	case string:
+		// comma separated codes, e.g. "en,de"
+		for _, code := range strings.Split(v, ",") {
+			if code = strings.TrimSpace(code); code != "" {
+				langs = append(langs, &plugnmeet.InsightsSupportedLangInfo{
+					Code:   code,
+					Name:   code,
+					Locale: code,
+				})
+			}
+		}
+	}
+
+	sort.Slice(langs, func(i, j int) bool {
+		return langs[i].Code < langs[j].Code
+	})
+	if langs == nil {
+		return []*plugnmeet.InsightsSupportedLangInfo{}
+	}
+	return langs
+}
+
+// toModelLanguage converts the code to the one used by whisper & vosk, e.g. en-US to en
+func toModelLanguage(code string) string {
+	code, _, _ = strings.Cut(code, "-")
+	return strings.ToLower(code)
+}
@@ -0,0 +1,101 @@
+package local
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
+	"github.com/sirupsen/logrus"
+)
+
+// streamResults is shared by the transcription streams to send the events
+type streamResults struct {
+	ctx     context.Context
+	p       *LocalProvider
+	userId  string
+	opts    *insights.TranscriptionOptions
+	log     *logrus.Entry
+	results chan *insights.TranscriptionEvent
+}
+
+func newStreamResults(ctx context.Context, p *LocalProvider, userId string, opts *insights.TranscriptionOptions, log *logrus.Entry) *streamResults {
+	return &streamResults{
+		ctx:     ctx,
+		p:       p,
+		userId:  userId,
+		opts:    opts,
+		log:     log,
+		results: make(chan *insights.TranscriptionEvent, 50),
+	}
+}
+
+func (s *streamResults) safeSend(event *insights.TranscriptionEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.log.Warnln("could not send to resultsChan, likely closed:", r)
+		}
+	}()
+
+	select {
+	case <-s.ctx.Done():
+		return
+	case s.results <- event:
+	}
+}
+
+func (s *streamResults) newResult(text string, isPartial bool) *plugnmeet.InsightsTranscriptionResult {
+	return &plugnmeet.InsightsTranscriptionResult{
+		FromUserId:                  s.userId,
+		FromUserName:                s.opts.UserName,
+		Lang:                        s.opts.SpokenLang,
+		Text:                        text,
+		IsPartial:                   isPartial,
+		AllowedTranscriptionStorage: s.opts.AllowedTranscriptionStorage,
+		Translations:                make(map[string]string),
+	}
+}
+
+func (s *streamResults) sendPartial(text string) {
+	if text = strings.TrimSpace(text); text == "" {
+		return
+	}
+	s.safeSend(&insights.TranscriptionEvent{
+		Type:   insights.EventTypePartialResult,
+		Result: s.newResult(text, true),
+	})
+}
+
+// sendFinal translates the text using the local LLM if translation languages were requested.
+// The duration of the speech is optional.
+func (s *streamResults) sendFinal(text string, duration time.Duration) {
+	if text = strings.TrimSpace(text); text == "" {
+		return
+	}
+	result := s.newResult(text, false)
+
+	if len(s.opts.TransLangs) > 0 && s.p.llm != nil {
+		tr, err := s.p.llm.TranslateText(s.ctx, text, s.opts.SpokenLang, s.opts.TransLangs)
+		if err != nil {
+			if s.ctx.Err() == nil {
+				s.log.WithError(err).Error("failed to translate final transcript")
+			}
+		} else if tr != nil {
+			result.Translations = tr.Translations
+		}
+	}
+
+	s.safeSend(&insights.TranscriptionEvent{
+		Type:     insights.EventTypeFinalResult,
+		Result:   result,
+		Duration: duration,
+	})
+}
+
+func (s *streamResults) sendError(msg string) {
+	s.safeSend(&insights.TranscriptionEvent{
+		Type:  insights.EventTypeError,
+		Error: msg,
+	})
+}
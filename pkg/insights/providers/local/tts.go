@@ -0,0 +1,76 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// same default as the synthesis task uses to publish the audio
+	defaultTTSSampleRate = 16000
+	// a few minutes of speech is more than enough for a sentence
+	maxTTSResponseSize = 50 << 20
+)
+
+type piperRequest struct {
+	Text  string `json:"text"`
+	Voice string `json:"voice,omitempty"`
+}
+
+// synthesizeText sends the text to the Piper HTTP server & returns 16-bit mono PCM audio
+// with the rate of the tts_sample_rate option, as the voices of Piper use different rates.
+func (p *LocalProvider) synthesizeText(ctx context.Context, text, language, voice string) (io.ReadCloser, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("text is required")
+	}
+	endpoint := p.account.GetOptionsString("tts_endpoint", "")
+	if endpoint == "" {
+		return nil, fmt.Errorf("local provider requires tts_endpoint for speech synthesis")
+	}
+
+	if voice == "" {
+		voice = p.service.GetVoiceMappings()[language]
+	}
+	if voice == "" {
+		voice = p.service.GetOptionsString("default_voice", "")
+	}
+
+	body, err := json.Marshal(&piperRequest{Text: text, Voice: voice})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute tts request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := readLimited(res.Body, 1024)
+		return nil, fmt.Errorf("tts request failed with status %d: %s", res.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	data, err := readLimited(res.Body, maxTTSResponseSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tts response: %w", err)
+	}
+	pcm, rate, err := decodeWAV(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode tts response: %w", err)
+	}
+
+	pcm = resamplePCM(pcm, rate, p.service.GetIntOption("tts_sample_rate", defaultTTSSampleRate))
+	return io.NopCloser(bytes.NewReader(pcmToBytes(pcm))), nil
+}
@@ -0,0 +1,157 @@
+package local
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	// inputSampleRate is the rate of the audio coming from the transcoder
+	inputSampleRate = 16000
+)
+
+func samplesForDuration(sampleRate, ms int) int {
+	return sampleRate * ms / 1000
+}
+
+func pcmRMS(pcm []int16) float64 {
+	if len(pcm) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range pcm {
+		f := float64(v)
+		sum += f * f
+	}
+	return math.Sqrt(sum / float64(len(pcm)))
+}
+
+func pcmToBytes(pcm []int16) []byte {
+	out := make([]byte, len(pcm)*2)
+	for i, v := range pcm {
+		binary.LittleEndian.PutUint16(out[i*2:], uint16(v))
+	}
+	return out
+}
+
+func bytesToPCM(data []byte) []int16 {
+	out := make([]int16, len(data)/2)
+	for i := range out {
+		out[i] = int16(binary.LittleEndian.Uint16(data[i*2:]))
+	}
+	return out
+}
+
+// encodeWAV wraps 16-bit mono PCM with a WAV header
+func encodeWAV(pcm []int16, sampleRate int) []byte {
+	data := pcmToBytes(pcm)
+	buf := bytes.NewBuffer(make([]byte, 0, 44+len(data)))
+	buf.WriteString("RIFF")
+	_ = binary.Write(buf, binary.LittleEndian, uint32(36+len(data)))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	_ = binary.Write(buf, binary.LittleEndian, uint32(16))
+	_ = binary.Write(buf, binary.LittleEndian, uint16(1)) // PCM
+	_ = binary.Write(buf, binary.LittleEndian, uint16(1)) // mono
+	_ = binary.Write(buf, binary.LittleEndian, uint32(sampleRate))
+	_ = binary.Write(buf, binary.LittleEndian, uint32(sampleRate*2))
+	_ = binary.Write(buf, binary.LittleEndian, uint16(2))
+	_ = binary.Write(buf, binary.LittleEndian, uint16(16))
+	buf.WriteString("data")
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+// decodeWAV returns the samples & rate of a 16-bit PCM WAV file, stereo will be mixed to mono
+func decodeWAV(data []byte) ([]int16, int, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, 0, errors.New("invalid wav file")
+	}
+
+	var sampleRate, channels, bitsPerSample int
+	pos := 12
+	for pos+8 <= len(data) {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		body := data[pos+8:]
+		if size > len(body) {
+			// streamed files may have an unknown size
+			size = len(body)
+		}
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, 0, errors.New("invalid wav fmt chunk")
+			}
+			if format := binary.LittleEndian.Uint16(body[0:2]); format != 1 {
+				return nil, 0, fmt.Errorf("unsupported wav format: %d", format)
+			}
+			channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			sampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+			bitsPerSample = int(binary.LittleEndian.Uint16(body[14:16]))
+		case "data":
+			if sampleRate == 0 || bitsPerSample != 16 || channels < 1 {
+				return nil, 0, errors.New("unsupported wav file, 16-bit PCM is required")
+			}
+			pcm := bytesToPCM(body[:size])
+			if channels > 1 {
+				mono := make([]int16, len(pcm)/channels)
+				for i := range mono {
+					var sum int
+					for c := 0; c < channels; c++ {
+						sum += int(pcm[i*channels+c])
+					}
+					mono[i] = int16(sum / channels)
+				}
+				pcm = mono
+			}
+			return pcm, sampleRate, nil
+		}
+		// chunks are word aligned
+		pos += 8 + size + size%2
+	}
+
+	return nil, 0, errors.New("wav file has no data")
+}
+
+// resamplePCM uses linear interpolation, good enough for speech
+func resamplePCM(input []int16, inputRate, outputRate int) []int16 {
+	if len(input) == 0 || inputRate <= 0 || outputRate <= 0 || inputRate == outputRate {
+		return input
+	}
+
+	outputLen := int(math.Round(float64(len(input)) * float64(outputRate) / float64(inputRate)))
+	out := make([]int16, outputLen)
+	ratio := float64(inputRate) / float64(outputRate)
+	for i := range out {
+		srcPos := float64(i) * ratio
+		srcIdx := int(srcPos)
+		if srcIdx >= len(input)-1 {
+			out[i] = input[len(input)-1]
+			continue
+		}
+		frac := srcPos - float64(srcIdx)
+		a := float64(input[srcIdx])
+		b := float64(input[srcIdx+1])
+		out[i] = int16(math.Round(a + (b-a)*frac))
+	}
+	return out
+}
+
+// readLimited reads the body but not more than limit bytes
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("response is larger than %d bytes", limit)
+	}
+	return data, nil
+}
@@ -0,0 +1,165 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/livekit/media-sdk"
+	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
+	"github.com/sirupsen/logrus"
+)
+
+var _ insights.TranscriptionStream = (*voskStream)(nil)
+
+const (
+	voskDialTimeout = 10 * time.Second
+	// time to wait for the final result after sending eof
+	voskCloseTimeout = 5 * time.Second
+)
+
+type voskConfigMsg struct {
+	Config struct {
+		SampleRate int `json:"sample_rate"`
+	} `json:"config"`
+}
+
+type voskResultMsg struct {
+	Partial string `json:"partial"`
+	Text    string `json:"text"`
+}
+
+// voskStream streams the audio to a vosk-server using its websocket protocol,
+// the server sends partial results while speaking & the text at the end of the utterance.
+type voskStream struct {
+	*streamResults
+	cancel context.CancelFunc
+	conn   *websocket.Conn
+
+	writeMu sync.Mutex
+	closed  bool
+	readWg  sync.WaitGroup
+	once    sync.Once
+}
+
+func newVoskStream(mainCtx context.Context, p *LocalProvider, endpoint, userId string, opts *insights.TranscriptionOptions, log *logrus.Entry) (*voskStream, error) {
+	dialer := &websocket.Dialer{
+		HandshakeTimeout: voskDialTimeout,
+	}
+	header := http.Header{}
+	if key := p.account.Credentials.APIKey; key != "" {
+		header.Set("Authorization", "Bearer "+key)
+	}
+
+	dialCtx, dialCancel := context.WithTimeout(mainCtx, voskDialTimeout)
+	defer dialCancel()
+	conn, _, err := dialer.DialContext(dialCtx, endpoint, header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect with vosk server: %w", err)
+	}
+
+	cnf := new(voskConfigMsg)
+	cnf.Config.SampleRate = inputSampleRate
+	if err = conn.WriteJSON(cnf); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to send config to vosk server: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(mainCtx)
+	s := &voskStream{
+		streamResults: newStreamResults(ctx, p, userId, opts, log),
+		cancel:        cancel,
+		conn:          conn,
+	}
+
+	log.Infoln("starting local vosk transcription")
+	s.safeSend(&insights.TranscriptionEvent{Type: insights.EventTypeSessionStarted})
+
+	s.readWg.Add(1)
+	go s.readLoop()
+
+	return s, nil
+}
+
+func (s *voskStream) WriteSample(sample media.PCM16Sample) error {
+	if len(sample) == 0 {
+		return nil
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if s.closed {
+		return nil
+	}
+	return s.conn.WriteMessage(websocket.BinaryMessage, pcmToBytes(sample))
+}
+
+func (s *voskStream) readLoop() {
+	defer s.readWg.Done()
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			s.writeMu.Lock()
+			closed := s.closed
+			s.writeMu.Unlock()
+			if !closed && s.ctx.Err() == nil {
+				s.log.WithError(err).Errorln("vosk connection closed unexpectedly")
+				s.sendError(err.Error())
+			}
+			return
+		}
+
+		msg := new(voskResultMsg)
+		if err = json.Unmarshal(data, msg); err != nil {
+			s.log.WithError(err).Warnln("failed to unmarshal vosk message")
+			continue
+		}
+		if msg.Text != "" {
+			s.sendFinal(msg.Text, 0)
+		} else if msg.Partial != "" {
+			s.sendPartial(msg.Partial)
+		}
+	}
+}
+
+// Close asks for the last result, then closes the connection & the results channel.
+func (s *voskStream) Close() error {
+	var closeErr error
+	s.once.Do(func() {
+		s.writeMu.Lock()
+		s.closed = true
+		closeErr = s.conn.WriteMessage(websocket.TextMessage, []byte(`{"eof" : 1}`))
+		s.writeMu.Unlock()
+
+		done := make(chan struct{})
+		go func() {
+			s.readWg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(voskCloseTimeout):
+		}
+
+		_ = s.conn.Close()
+		s.readWg.Wait()
+
+		s.safeSend(&insights.TranscriptionEvent{Type: insights.EventTypeSessionStopped})
+		s.cancel()
+		close(s.results)
+	})
+	return closeErr
+}
+
+func (s *voskStream) SetProperty(key string, value string) error {
+	return nil
+}
+
+func (s *voskStream) Results() <-chan *insights.TranscriptionEvent {
+	return s.results
+}
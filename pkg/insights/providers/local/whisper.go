@@ -0,0 +1,251 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/livekit/media-sdk"
+	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
+	"github.com/sirupsen/logrus"
+)
+
+var _ insights.TranscriptionStream = (*whisperStream)(nil)
+
+const (
+	defaultWhisperMinSegmentMs      = 500
+	defaultWhisperSilenceSegmentMs  = 800
+	defaultWhisperMaxSegmentMs      = 10000
+	defaultWhisperPreRollMs         = 300
+	defaultWhisperSpeechRMS         = 500
+	maxWhisperPendingSegments       = 10
+	maxWhisperTranscriptionRespSize = 1 << 20
+)
+
+type whisperResponse struct {
+	Text string `json:"text"`
+}
+
+// whisperSegment is an utterance with the duration of its speech, without the trailing silence
+type whisperSegment struct {
+	pcm    []int16
+	speech time.Duration
+}
+
+// whisperStream splits the audio into utterances by detecting silence & transcribes each of those
+// with a whisper.cpp (/inference) or faster-whisper (/v1/audio/transcriptions) server.
+// Both accept the same multipart request & return the text in JSON.
+type whisperStream struct {
+	*streamResults
+	cancel   context.CancelFunc
+	endpoint string
+
+	minSegmentSamples  int
+	maxPreRollSamples  int
+	silenceDuration    time.Duration
+	maxSegmentDuration time.Duration
+	speechRMS          float64
+
+	mu               sync.Mutex
+	closed           bool
+	segment          []int16
+	preRoll          []int16
+	hasSpeech        bool
+	segmentStartedAt time.Time
+	lastSpeechAt     time.Time
+
+	segments chan whisperSegment
+	workerWg sync.WaitGroup
+	once     sync.Once
+}
+
+func newWhisperStream(mainCtx context.Context, p *LocalProvider, endpoint, userId string, opts *insights.TranscriptionOptions, log *logrus.Entry) (*whisperStream, error) {
+	ctx, cancel := context.WithCancel(mainCtx)
+	s := &whisperStream{
+		streamResults:      newStreamResults(ctx, p, userId, opts, log),
+		cancel:             cancel,
+		endpoint:           endpoint,
+		minSegmentSamples:  samplesForDuration(inputSampleRate, p.service.GetIntOption("transcription_min_commit_ms", defaultWhisperMinSegmentMs)),
+		maxPreRollSamples:  samplesForDuration(inputSampleRate, defaultWhisperPreRollMs),
+		silenceDuration:    time.Duration(p.service.GetIntOption("transcription_silence_commit_ms", defaultWhisperSilenceSegmentMs)) * time.Millisecond,
+		maxSegmentDuration: time.Duration(p.service.GetIntOption("transcription_max_commit_ms", defaultWhisperMaxSegmentMs)) * time.Millisecond,
+		speechRMS:          float64(p.service.GetIntOption("transcription_speech_rms", defaultWhisperSpeechRMS)),
+		segments:           make(chan whisperSegment, maxWhisperPendingSegments),
+	}
+
+	log.Infoln("starting local whisper transcription")
+	s.safeSend(&insights.TranscriptionEvent{Type: insights.EventTypeSessionStarted})
+
+	s.workerWg.Add(1)
+	go s.transcribeLoop()
+
+	return s, nil
+}
+
+func (s *whisperStream) WriteSample(sample media.PCM16Sample) error {
+	if len(sample) == 0 {
+		return nil
+	}
+	now := time.Now()
+	pcm := []int16(sample)
+	isSpeech := pcmRMS(pcm) >= s.speechRMS
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+
+	if !s.hasSpeech {
+		if !isSpeech {
+			// keep a little audio before the speech, so the first word won't be cut
+			s.preRoll = append(s.preRoll, pcm...)
+			if drop := len(s.preRoll) - s.maxPreRollSamples; drop > 0 {
+				s.preRoll = s.preRoll[drop:]
+			}
+			return nil
+		}
+		s.hasSpeech = true
+		s.segmentStartedAt = now
+		s.segment = append(s.segment[:0], s.preRoll...)
+		s.preRoll = s.preRoll[:0]
+	}
+
+	s.segment = append(s.segment, pcm...)
+	if isSpeech {
+		s.lastSpeechAt = now
+	}
+
+	if now.Sub(s.lastSpeechAt) >= s.silenceDuration || now.Sub(s.segmentStartedAt) >= s.maxSegmentDuration {
+		s.flushLocked()
+	}
+	return nil
+}
+
+// flushLocked hands over the current segment to the transcribe loop
+func (s *whisperStream) flushLocked() {
+	segment := whisperSegment{
+		pcm:    s.segment,
+		speech: s.lastSpeechAt.Sub(s.segmentStartedAt),
+	}
+	s.segment = nil
+	s.hasSpeech = false
+
+	if len(segment.pcm) < s.minSegmentSamples {
+		return
+	}
+	select {
+	case s.segments <- segment:
+	default:
+		s.log.Warnln("whisper server isn't keeping up, dropping audio segment")
+	}
+}
+
+func (s *whisperStream) transcribeLoop() {
+	defer s.workerWg.Done()
+
+	for segment := range s.segments {
+		text, err := s.transcribe(segment.pcm)
+		if err != nil {
+			if s.ctx.Err() == nil {
+				s.log.WithError(err).Errorln("failed to transcribe audio segment")
+				s.sendError(err.Error())
+			}
+			continue
+		}
+		s.sendFinal(text, segment.speech)
+	}
+}
+
+func (s *whisperStream) transcribe(segment []int16) (string, error) {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+
+	fw, err := mw.CreateFormFile("file", "audio.wav")
+	if err != nil {
+		return "", err
+	}
+	if _, err = fw.Write(encodeWAV(segment, inputSampleRate)); err != nil {
+		return "", err
+	}
+	fields := map[string]string{
+		"response_format": "json",
+		"language":        toModelLanguage(s.opts.SpokenLang),
+	}
+	if model := s.p.service.GetOptionsString("model", ""); model != "" && model != "default" {
+		fields["model"] = model
+	}
+	for k, v := range fields {
+		if v == "" {
+			continue
+		}
+		if err = mw.WriteField(k, v); err != nil {
+			return "", err
+		}
+	}
+	if err = mw.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, s.endpoint, body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if key := s.p.account.Credentials.APIKey; key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	res, err := s.p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute transcription request: %w", err)
+	}
+	defer res.Body.Close()
+
+	data, err := readLimited(res.Body, maxWhisperTranscriptionRespSize)
+	if err != nil {
+		return "", fmt.Errorf("failed to read transcription response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("transcription request failed with status %d: %s", res.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	wr := new(whisperResponse)
+	if err = json.Unmarshal(data, wr); err != nil {
+		return "", fmt.Errorf("failed to unmarshal transcription response: %w", err)
+	}
+	return wr.Text, nil
+}
+
+// Close transcribes the remaining audio and closes the results channel once all were sent.
+func (s *whisperStream) Close() error {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		if s.hasSpeech {
+			s.flushLocked()
+		}
+		close(s.segments)
+		s.mu.Unlock()
+
+		s.workerWg.Wait()
+		s.safeSend(&insights.TranscriptionEvent{Type: insights.EventTypeSessionStopped})
+		s.cancel()
+		close(s.results)
+	})
+	return nil
+}
+
+func (s *whisperStream) SetProperty(key string, value string) error {
+	return nil
+}
+
+func (s *whisperStream) Results() <-chan *insights.TranscriptionEvent {
+	return s.results
+}
@@ -0,0 +1,120 @@
+package migrations
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+// order is important because of foreign keys
+var baselineTables = []interface{}{
+	&dbmodels.RoomInfo{},
+	&dbmodels.Recording{},
+	&dbmodels.Analytics{},
+	&dbmodels.RoomArtifact{},
+	&dbmodels.RoomSchedule{},
+}
+
+// baselineUp creates the initial tables. Existing installations
+// which were set up using the old sql_dump/install.sql already have those,
+// so we'll only create the missing one & leave the rest untouched.
+func baselineUp(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	for _, t := range baselineTablesFor(tx) {
+		if m.HasTable(t) {
+			continue
+		}
+		if err := m.CreateTable(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// baselineDown drops all the tables, so all the data will be lost
+func baselineDown(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	for i := len(baselineTables) - 1; i >= 0; i-- {
+		if err := m.DropTable(baselineTables[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// baselineTablesFor returns the tables to create for the dialect.
+// The models keep the index names of install.sql, but those are only unique per table,
+// postgres & sqlite need unique names per database, so idx_room_id gets the table as prefix there.
+func baselineTablesFor(tx *gorm.DB) []interface{} {
+	if tx.Dialector.Name() == "mysql" {
+		return baselineTables
+	}
+	return []interface{}{
+		&dbmodels.RoomInfo{},
+		&baselineRecording{},
+		&baselineAnalytics{},
+		&baselineRoomArtifact{},
+		&dbmodels.RoomSchedule{},
+	}
+}
+
+// baselineRecording is dbmodels.Recording before the tenant & retention columns,
+// those will be added by their migrations.
+type baselineRecording struct {
+	ID               uint64         `gorm:"column:id;primaryKey;autoIncrement"`
+	RecordID         string         `gorm:"column:record_id;type:varchar(64);not null;uniqueIndex:idx_record_id"`
+	RoomID           string         `gorm:"column:room_id;type:varchar(64);not null;index:idx_recordings_room_id"`
+	RoomSid          sql.NullString `gorm:"column:room_sid;type:varchar(64);not null"`
+	RecorderID       string         `gorm:"column:recorder_id;type:varchar(36);not null"`
+	FilePath         string         `gorm:"column:file_path;type:varchar(255);not null"`
+	Size             string         `gorm:"column:size;type:double precision;not null"`
+	Published        int64          `gorm:"column:published;type:smallint;not null;default:1"`
+	Metadata         string         `gorm:"column:metadata;type:json"`
+	CreationTime     int64          `gorm:"column:creation_time;not null;autoCreateTime"`
+	RoomCreationTime int64          `gorm:"column:room_creation_time;not null;default:0"`
+	Created          time.Time      `gorm:"column:created;not null;default:CURRENT_TIMESTAMP"`
+	Modified         time.Time      `gorm:"column:modified;not null;autoUpdateTime"`
+
+	RoomInfo dbmodels.RoomInfo `gorm:"foreignKey:room_sid;references:sid;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+func (t *baselineRecording) TableName() string {
+	return config.FormatDBTable("recordings")
+}
+
+type baselineAnalytics struct {
+	ID               uint64  `gorm:"column:id;primaryKey;autoIncrement"`
+	RoomTableID      uint64  `gorm:"column:room_table_id;not null;uniqueIndex:idx_room_table_id"`
+	RoomID           string  `gorm:"column:room_id;type:varchar(64);not null;index:idx_room_analytics_room_id"`
+	FileID           string  `gorm:"column:file_id;type:varchar(255);not null;uniqueIndex:idx_file_id"`
+	FileName         string  `gorm:"column:file_name;type:varchar(255);not null"`
+	FileSize         float64 `gorm:"column:file_size;type:double precision;not null"`
+	RoomCreationTime int64   `gorm:"column:room_creation_time;not null"`
+	CreationTime     int64   `gorm:"column:creation_time;autoCreateTime;not null"`
+
+	RoomInfo dbmodels.RoomInfo `gorm:"foreignKey:room_table_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+func (t *baselineAnalytics) TableName() string {
+	return config.FormatDBTable("room_analytics")
+}
+
+// baselineRoomArtifact is dbmodels.RoomArtifact before the tenant column
+type baselineRoomArtifact struct {
+	ID          uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	ArtifactId  string    `gorm:"column:artifact_id;type:varchar(64);not null;uniqueIndex:idx_artifact_id"`
+	RoomTableID uint64    `gorm:"column:room_table_id;not null"`
+	RoomId      string    `gorm:"column:room_id;type:varchar(255);not null;index:idx_room_artifacts_room_id"`
+	Type        string    `gorm:"column:type;type:varchar(100);not null;index:idx_type"`
+	Metadata    string    `gorm:"column:metadata;type:json"`
+	Created     time.Time `gorm:"column:created;not null;default:CURRENT_TIMESTAMP;autoCreateTime"`
+
+	RoomInfo dbmodels.RoomInfo `gorm:"foreignKey:room_table_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+func (t *baselineRoomArtifact) TableName() string {
+	return config.FormatDBTable("room_artifacts")
+}
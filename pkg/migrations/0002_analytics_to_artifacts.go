@@ -0,0 +1,139 @@
+package migrations
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"google.golang.org/protobuf/encoding/protojson"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// analyticsToArtifactsUp moves the old analytics files to the room artifacts structure.
+// A file which can't be moved will be skipped & logged, the same as before,
+// so one broken record won't block the server from starting.
+func analyticsToArtifactsUp(tx *gorm.DB, env *Env) error {
+	log := env.Logger.WithField("migration", "analytics_to_artifacts")
+
+	var oldAnalytics []dbmodels.Analytics
+	if err := tx.Find(&oldAnalytics).Error; err != nil {
+		return err
+	}
+	if len(oldAnalytics) == 0 {
+		log.Info("no old analytics files found to migrate")
+		return nil
+	}
+
+	filesStorePath := "./analytics"
+	if env.App.AnalyticsSettings != nil && env.App.AnalyticsSettings.FilesStorePath != nil {
+		filesStorePath = *env.App.AnalyticsSettings.FilesStorePath
+	}
+
+	log.Infof("found %d old analytics records to process", len(oldAnalytics))
+	var migratedCount, skippedCount int
+
+	for _, analytic := range oldAnalytics {
+		oldPath := filepath.Join(filesStorePath, analytic.FileName)
+		oldStat, err := os.Stat(oldPath)
+		if err != nil {
+			log.Warnf("source analytics file not found, skipping: %s", oldPath)
+			skippedCount++
+			continue
+		}
+
+		roomInfo := new(dbmodels.RoomInfo)
+		if err := tx.Where("id = ?", analytic.RoomTableID).Take(roomInfo).Error; err != nil {
+			log.WithError(err).Errorf("failed to get room info for room_table_id %d, skipping", analytic.RoomTableID)
+			skippedCount++
+			continue
+		}
+
+		artifactType := plugnmeet.RoomArtifactType_MEETING_ANALYTICS
+		relativeDir := filepath.Join(strings.ToLower(artifactType.String()), roomInfo.RoomId)
+		absoluteDir := filepath.Join(*env.App.ArtifactsSettings.StoragePath, relativeDir)
+		if err := os.MkdirAll(absoluteDir, 0755); err != nil {
+			return fmt.Errorf("failed to create artifact directory: %w", err)
+		}
+		relativePath := filepath.Join(relativeDir, analytic.FileName)
+		absolutePath := filepath.Join(absoluteDir, analytic.FileName)
+
+		// Use copy then delete for robustness across filesystems.
+		if err := copyFile(oldPath, absolutePath); err != nil {
+			log.WithError(err).Errorf("failed to copy file from %s to %s", oldPath, absolutePath)
+			skippedCount++
+			continue
+		}
+
+		metadataBytes, err := protojson.Marshal(&plugnmeet.RoomArtifactMetadata{
+			FileInfo: &plugnmeet.RoomArtifactFileInfo{
+				FilePath: relativePath,
+				FileSize: oldStat.Size(),
+				MimeType: "application/json",
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		// Use old file_id as the new unique artifact_id,
+		// so an already migrated record will simply be ignored
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dbmodels.RoomArtifact{
+			ArtifactId:  analytic.FileID,
+			RoomTableID: analytic.RoomTableID,
+			RoomId:      roomInfo.RoomId,
+			Type:        dbmodels.RoomArtifactType(artifactType),
+			Metadata:    string(metadataBytes),
+		})
+		if result.Error != nil {
+			_ = os.Remove(absolutePath)
+			return fmt.Errorf("failed to create artifact record for file %s: %w", analytic.FileName, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			log.Warnf("artifact for file_id %s already exists, skipping.", analytic.FileID)
+			skippedCount++
+			continue
+		}
+
+		// the old file is still required if the transaction is rolled back
+		env.AfterCommit(func() {
+			_ = os.Remove(oldPath)
+		})
+		migratedCount++
+	}
+
+	log.Infof("analytics migration finished. Migrated: %d, Skipped: %d", migratedCount, skippedCount)
+	return nil
+}
+
+// analyticsToArtifactsDown has nothing to revert,
+// the created artifacts are valid records & will be kept as it is
+func analyticsToArtifactsDown(_ *gorm.DB, _ *Env) error {
+	return nil
+}
+
+// copyFile performs a copy of a file from a source to a destination.
+func copyFile(src, dst string) error {
+	sourceFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+
+	destFile, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer destFile.Close()
+
+	_, err = io.Copy(destFile, sourceFile)
+	if err != nil {
+		return err
+	}
+
+	return destFile.Sync()
+}
@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+func roomTemplatesUp(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	if m.HasTable(&dbmodels.RoomTemplate{}) {
+		return nil
+	}
+	return m.CreateTable(&dbmodels.RoomTemplate{})
+}
+
+func roomTemplatesDown(tx *gorm.DB, _ *Env) error {
+	return tx.Migrator().DropTable(&dbmodels.RoomTemplate{})
+}
@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+// tables which will get tenant_id column, existing records will belong to the default tenant
+var tenantScopedTables = []interface{}{
+	&dbmodels.RoomInfo{},
+	&dbmodels.Recording{},
+	&dbmodels.RoomArtifact{},
+	&dbmodels.RoomSchedule{},
+	&dbmodels.RoomTemplate{},
+}
+
+func tenantsUp(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	if !m.HasTable(&dbmodels.Tenant{}) {
+		if err := m.CreateTable(&dbmodels.Tenant{}); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range tenantScopedTables {
+		if !m.HasColumn(t, "TenantId") {
+			if err := m.AddColumn(t, "TenantId"); err != nil {
+				return err
+			}
+		}
+		if !m.HasIndex(t, "TenantId") {
+			if err := m.CreateIndex(t, "TenantId"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func tenantsDown(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	for _, t := range tenantScopedTables {
+		if m.HasIndex(t, "TenantId") {
+			if err := m.DropIndex(t, "TenantId"); err != nil {
+				return err
+			}
+		}
+		if m.HasColumn(t, "TenantId") {
+			if err := m.DropColumn(t, "TenantId"); err != nil {
+				return err
+			}
+		}
+	}
+	return m.DropTable(&dbmodels.Tenant{})
+}
@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+func webhookDeliveriesUp(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	if m.HasTable(&dbmodels.WebhookDelivery{}) {
+		return nil
+	}
+	return m.CreateTable(&dbmodels.WebhookDelivery{})
+}
+
+func webhookDeliveriesDown(tx *gorm.DB, _ *Env) error {
+	return tx.Migrator().DropTable(&dbmodels.WebhookDelivery{})
+}
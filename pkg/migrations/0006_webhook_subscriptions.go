@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+func webhookSubscriptionsUp(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	if m.HasTable(&dbmodels.WebhookSubscription{}) {
+		return nil
+	}
+	return m.CreateTable(&dbmodels.WebhookSubscription{})
+}
+
+func webhookSubscriptionsDown(tx *gorm.DB, _ *Env) error {
+	return tx.Migrator().DropTable(&dbmodels.WebhookSubscription{})
+}
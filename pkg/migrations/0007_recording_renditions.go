@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+func recordingRenditionsUp(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	if m.HasTable(&dbmodels.RecordingRendition{}) {
+		return nil
+	}
+	return m.CreateTable(&dbmodels.RecordingRendition{})
+}
+
+func recordingRenditionsDown(tx *gorm.DB, _ *Env) error {
+	return tx.Migrator().DropTable(&dbmodels.RecordingRendition{})
+}
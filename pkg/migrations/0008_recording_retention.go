@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+type migrationColumn struct {
+	model  interface{}
+	field  string
+	hasIdx bool
+}
+
+// columns required to find the retention policy of a recording
+var recordingRetentionColumns = []migrationColumn{
+	{model: &dbmodels.RoomInfo{}, field: "TemplateId"},
+	{model: &dbmodels.Recording{}, field: "TemplateId", hasIdx: true},
+	{model: &dbmodels.RoomTemplate{}, field: "RecordingRetentionPublishedDays"},
+	{model: &dbmodels.RoomTemplate{}, field: "RecordingRetentionUnpublishedDays"},
+	{model: &dbmodels.Tenant{}, field: "RecordingRetentionPublishedDays"},
+	{model: &dbmodels.Tenant{}, field: "RecordingRetentionUnpublishedDays"},
+}
+
+func recordingRetentionUp(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	for _, c := range recordingRetentionColumns {
+		if !m.HasColumn(c.model, c.field) {
+			if err := m.AddColumn(c.model, c.field); err != nil {
+				return err
+			}
+		}
+		if c.hasIdx && !m.HasIndex(c.model, c.field) {
+			if err := m.CreateIndex(c.model, c.field); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func recordingRetentionDown(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	for _, c := range recordingRetentionColumns {
+		if c.hasIdx && m.HasIndex(c.model, c.field) {
+			if err := m.DropIndex(c.model, c.field); err != nil {
+				return err
+			}
+		}
+		if m.HasColumn(c.model, c.field) {
+			if err := m.DropColumn(c.model, c.field); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
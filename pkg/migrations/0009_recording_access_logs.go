@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+func recordingAccessLogsUp(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	if m.HasTable(&dbmodels.RecordingAccessLog{}) {
+		return nil
+	}
+	return m.CreateTable(&dbmodels.RecordingAccessLog{})
+}
+
+func recordingAccessLogsDown(tx *gorm.DB, _ *Env) error {
+	return tx.Migrator().DropTable(&dbmodels.RecordingAccessLog{})
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+func tenantInsightsBudgetUp(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	if m.HasColumn(&dbmodels.Tenant{}, "InsightsBudget") {
+		return nil
+	}
+	return m.AddColumn(&dbmodels.Tenant{}, "InsightsBudget")
+}
+
+func tenantInsightsBudgetDown(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	if !m.HasColumn(&dbmodels.Tenant{}, "InsightsBudget") {
+		return nil
+	}
+	return m.DropColumn(&dbmodels.Tenant{}, "InsightsBudget")
+}
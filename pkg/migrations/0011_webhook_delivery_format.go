@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+func webhookDeliveryFormatUp(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	if m.HasColumn(&dbmodels.WebhookDelivery{}, "Format") {
+		return nil
+	}
+	return m.AddColumn(&dbmodels.WebhookDelivery{}, "Format")
+}
+
+func webhookDeliveryFormatDown(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	if !m.HasColumn(&dbmodels.WebhookDelivery{}, "Format") {
+		return nil
+	}
+	return m.DropColumn(&dbmodels.WebhookDelivery{}, "Format")
+}
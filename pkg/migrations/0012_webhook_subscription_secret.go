@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"crypto/rand"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+func webhookSubscriptionSecretUp(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	if !m.HasColumn(&dbmodels.WebhookSubscription{}, "Secret") {
+		if err := m.AddColumn(&dbmodels.WebhookSubscription{}, "Secret"); err != nil {
+			return err
+		}
+	}
+	if !m.HasColumn(&dbmodels.WebhookDelivery{}, "SubscriptionId") {
+		if err := m.AddColumn(&dbmodels.WebhookDelivery{}, "SubscriptionId"); err != nil {
+			return err
+		}
+	}
+
+	// existing subscriptions were signed using the api secret, they need their own now
+	var list []dbmodels.WebhookSubscription
+	if err := tx.Where("secret = ?", "").Find(&list).Error; err != nil {
+		return err
+	}
+	for _, s := range list {
+		if err := tx.Model(&dbmodels.WebhookSubscription{}).Where("id = ?", s.ID).Update("secret", rand.Text()+rand.Text()).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func webhookSubscriptionSecretDown(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	if m.HasColumn(&dbmodels.WebhookDelivery{}, "SubscriptionId") {
+		if err := m.DropColumn(&dbmodels.WebhookDelivery{}, "SubscriptionId"); err != nil {
+			return err
+		}
+	}
+	if m.HasColumn(&dbmodels.WebhookSubscription{}, "Secret") {
+		return m.DropColumn(&dbmodels.WebhookSubscription{}, "Secret")
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+// existing schedules will keep their UTC based recurrence
+func roomScheduleTimezoneUp(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	if !m.HasColumn(&dbmodels.RoomSchedule{}, "Timezone") {
+		return m.AddColumn(&dbmodels.RoomSchedule{}, "Timezone")
+	}
+	return nil
+}
+
+func roomScheduleTimezoneDown(tx *gorm.DB, _ *Env) error {
+	m := tx.Migrator()
+	if m.HasColumn(&dbmodels.RoomSchedule{}, "Timezone") {
+		return m.DropColumn(&dbmodels.RoomSchedule{}, "Timezone")
+	}
+	return nil
+}
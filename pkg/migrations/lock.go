@@ -0,0 +1,79 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+const (
+	migrationLockName = "plugnmeet_schema_migrations"
+	// a migration may need to move files, so we'll wait long enough for the other server to finish
+	migrationLockTimeout = 10 * time.Minute
+)
+
+// withLock runs fn while holding a database lock, so only one server
+// will apply the migrations when several are starting at the same time.
+// The lock belongs to the session, so it is released even if the server crashes.
+func (m *Migrator) withLock(fn func() error) error {
+	var lockSQL, unlockSQL string
+	switch m.db.Dialector.Name() {
+	case "mysql":
+		lockSQL, unlockSQL = "SELECT GET_LOCK(?, ?)", "SELECT RELEASE_LOCK(?)"
+	case "postgres":
+		lockSQL, unlockSQL = "SELECT pg_try_advisory_lock(hashtext(?))", "SELECT pg_advisory_unlock(hashtext(?))"
+	default:
+		// sqlite allows only one writer & has a single connection
+		return fn()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), migrationLockTimeout)
+	defer cancel()
+
+	// session level locks, so we need to use the same connection to acquire & release
+	return m.db.Clauses(dbresolver.Write).Connection(func(conn *gorm.DB) error {
+		if err := acquireMigrationLock(ctx, conn, lockSQL); err != nil {
+			return err
+		}
+		defer func() {
+			if err := conn.Exec(unlockSQL, migrationLockName).Error; err != nil {
+				m.env.Logger.WithError(err).Errorln("failed to release migration lock")
+			}
+		}()
+		return fn()
+	})
+}
+
+func acquireMigrationLock(ctx context.Context, conn *gorm.DB, lockSQL string) error {
+	if conn.Dialector.Name() == "mysql" {
+		// GET_LOCK will wait by itself, 1 means acquired, 0 timed out
+		var res int
+		if err := conn.Raw(lockSQL, migrationLockName, int(migrationLockTimeout.Seconds())).Scan(&res).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if res != 1 {
+			return errors.New("timed out waiting for the migration lock")
+		}
+		return nil
+	}
+
+	for {
+		var acquired bool
+		if err := conn.Raw(lockSQL, migrationLockName).Scan(&acquired).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if acquired {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.New("timed out waiting for the migration lock")
+		case <-time.After(time.Second):
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+var ErrIrreversible = errors.New("migration can't be reverted")
+
+// Env holds the dependencies a migration may need beside the db
+type Env struct {
+	App    *config.AppConfig
+	Logger *logrus.Entry
+
+	afterCommit []func()
+}
+
+// AfterCommit registers fn to run once the migration was committed,
+// e.g. to remove the old files which must be kept if the migration is rolled back.
+func (e *Env) AfterCommit(fn func()) {
+	e.afterCommit = append(e.afterCommit, fn)
+}
+
+// Migration is a single versioned schema change.
+// Version must be unique & never be changed after release.
+// Down can be nil if the migration can't be reverted.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB, env *Env) error
+	Down    func(tx *gorm.DB, env *Env) error
+}
+
+// Status shows the state of a migration
+type Status struct {
+	Version   uint
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	// Unknown is true if the migration was applied but isn't part of this build,
+	// most probably by a newer version of the server
+	Unknown bool
+}
+
+// all the migrations in order, new one should be added at the bottom
+var migrations = []Migration{
+	{Version: 1, Name: "baseline", Up: baselineUp, Down: baselineDown},
+	{Version: 2, Name: "analytics_to_artifacts", Up: analyticsToArtifactsUp, Down: analyticsToArtifactsDown},
+	{Version: 3, Name: "room_templates", Up: roomTemplatesUp, Down: roomTemplatesDown},
+	{Version: 4, Name: "tenants", Up: tenantsUp, Down: tenantsDown},
+	{Version: 5, Name: "webhook_deliveries", Up: webhookDeliveriesUp, Down: webhookDeliveriesDown},
+	{Version: 6, Name: "webhook_subscriptions", Up: webhookSubscriptionsUp, Down: webhookSubscriptionsDown},
+	{Version: 7, Name: "recording_renditions", Up: recordingRenditionsUp, Down: recordingRenditionsDown},
+	{Version: 8, Name: "recording_retention", Up: recordingRetentionUp, Down: recordingRetentionDown},
+	{Version: 9, Name: "recording_access_logs", Up: recordingAccessLogsUp, Down: recordingAccessLogsDown},
+	{Version: 10, Name: "tenant_insights_budget", Up: tenantInsightsBudgetUp, Down: tenantInsightsBudgetDown},
+	{Version: 11, Name: "webhook_delivery_format", Up: webhookDeliveryFormatUp, Down: webhookDeliveryFormatDown},
+	{Version: 12, Name: "webhook_subscription_secret", Up: webhookSubscriptionSecretUp, Down: webhookSubscriptionSecretDown},
+	{Version: 13, Name: "room_schedule_timezone", Up: roomScheduleTimezoneUp, Down: roomScheduleTimezoneDown},
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	env        *Env
+	migrations []Migration
+}
+
+func New(db *gorm.DB, app *config.AppConfig, logger *logrus.Logger) *Migrator {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	return &Migrator{
+		db: db,
+		env: &Env{
+			App:    app,
+			Logger: logger.WithField("service", "migrations"),
+		},
+		migrations: sorted,
+	}
+}
+
+// Up will apply all the pending migrations in order & returns the number of applied migrations.
+// Each migration runs in its own transaction where the driver supports it.
+func (m *Migrator) Up() (count int, err error) {
+	err = m.withLock(func() error {
+		count, err = m.up()
+		return err
+	})
+	return count, err
+}
+
+func (m *Migrator) up() (int, error) {
+	log := m.env.Logger.WithField("method", "Up")
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, mg := range m.migrations {
+		if _, ok := applied[mg.Version]; ok {
+			continue
+		}
+		log.Infof("applying migration %d_%s", mg.Version, mg.Name)
+
+		m.env.afterCommit = nil
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := mg.Up(tx, m.env); err != nil {
+				return err
+			}
+			return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dbmodels.SchemaMigration{
+				Version: mg.Version,
+				Name:    mg.Name,
+			}).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("migration %d_%s failed: %w", mg.Version, mg.Name, err)
+		}
+		for _, fn := range m.env.afterCommit {
+			fn()
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Down will revert the last applied migrations, up to steps
+func (m *Migrator) Down(steps int) (count int, err error) {
+	err = m.withLock(func() error {
+		count, err = m.down(steps)
+		return err
+	})
+	return count, err
+}
+
+func (m *Migrator) down(steps int) (int, error) {
+	log := m.env.Logger.WithField("method", "Down")
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+		mg := m.migrations[i]
+		if _, ok := applied[mg.Version]; !ok {
+			continue
+		}
+		if mg.Down == nil {
+			return count, fmt.Errorf("%d_%s: %w", mg.Version, mg.Name, ErrIrreversible)
+		}
+		log.Infof("reverting migration %d_%s", mg.Version, mg.Name)
+
+		m.env.afterCommit = nil
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := mg.Down(tx, m.env); err != nil {
+				return err
+			}
+			return tx.Delete(&dbmodels.SchemaMigration{}, mg.Version).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("reverting migration %d_%s failed: %w", mg.Version, mg.Name, err)
+		}
+		for _, fn := range m.env.afterCommit {
+			fn()
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Status returns the state of all the known & applied migrations ordered by version
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Status
+	for _, mg := range m.migrations {
+		s := Status{
+			Version: mg.Version,
+			Name:    mg.Name,
+		}
+		if row, ok := applied[mg.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = row.AppliedAt
+			delete(applied, mg.Version)
+		}
+		list = append(list, s)
+	}
+
+	for _, row := range applied {
+		list = append(list, Status{
+			Version:   row.Version,
+			Name:      row.Name,
+			Applied:   true,
+			AppliedAt: row.AppliedAt,
+			Unknown:   true,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+
+	return list, nil
+}
+
+// Pending returns the number of migrations not applied yet
+func (m *Migrator) Pending() (int, error) {
+	list, err := m.Status()
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, s := range list {
+		if !s.Applied {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *Migrator) appliedVersions() (map[uint]dbmodels.SchemaMigration, error) {
+	if err := m.db.AutoMigrate(&dbmodels.SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("failed to prepare schema_migrations table: %w", err)
+	}
+
+	var rows []dbmodels.SchemaMigration
+	if err := m.db.Clauses(dbresolver.Write).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[uint]dbmodels.SchemaMigration, len(rows))
+	for _, r := range rows {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
@@ -0,0 +1,163 @@
+package migrations
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestMigrator(t *testing.T) (*Migrator, *gorm.DB) {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "plugnmeet.db") + "?_pragma=busy_timeout(5000)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open sqlite db: %v", err)
+	}
+
+	log := logrus.New()
+	log.SetLevel(logrus.ErrorLevel)
+	return New(db, &config.AppConfig{}, log), db
+}
+
+func TestMigratorUpSqlite(t *testing.T) {
+	m, db := newTestMigrator(t)
+
+	count, err := m.Up()
+	if err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if count != len(migrations) {
+		t.Fatalf("expected %d applied migrations, got %d", len(migrations), count)
+	}
+
+	// running again must be a no-op
+	count, err = m.Up()
+	if err != nil {
+		t.Fatalf("second Up failed: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected no migration on second Up, got %d", count)
+	}
+
+	pending, err := m.Pending()
+	if err != nil {
+		t.Fatalf("Pending failed: %v", err)
+	}
+	if pending != 0 {
+		t.Fatalf("expected no pending migration, got %d", pending)
+	}
+
+	mg := db.Migrator()
+	for _, table := range []interface{}{
+		&dbmodels.RoomInfo{},
+		&dbmodels.Recording{},
+		&dbmodels.RoomArtifact{},
+		&dbmodels.RoomSchedule{},
+		&dbmodels.RoomTemplate{},
+		&dbmodels.Tenant{},
+	} {
+		if !mg.HasTable(table) {
+			t.Errorf("table of %T is missing", table)
+		}
+		if !mg.HasColumn(table, "TenantId") {
+			t.Errorf("tenant_id column of %T is missing", table)
+		}
+	}
+	if !mg.HasColumn(&dbmodels.Recording{}, "TemplateId") {
+		t.Error("template_id column of recordings is missing")
+	}
+
+	// the original index names are kept, the shared idx_room_id is prefixed with the table
+	for _, idx := range []struct {
+		table interface{}
+		name  string
+	}{
+		{&dbmodels.RoomInfo{}, "sid"},
+		{&dbmodels.RoomInfo{}, "idx_room_id"},
+		{&dbmodels.Recording{}, "idx_record_id"},
+		{&dbmodels.Recording{}, "idx_recordings_room_id"},
+		{&dbmodels.RoomArtifact{}, "idx_artifact_id"},
+		{&dbmodels.RoomArtifact{}, "idx_room_artifacts_room_id"},
+		{&dbmodels.RoomArtifact{}, "idx_type"},
+	} {
+		if !mg.HasIndex(idx.table, idx.name) {
+			t.Errorf("index %s of %T is missing", idx.name, idx.table)
+		}
+	}
+}
+
+func TestMigratorDownSqlite(t *testing.T) {
+	m, db := newTestMigrator(t)
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+
+	count, err := m.Down(1)
+	if err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 reverted migration, got %d", count)
+	}
+	if db.Migrator().HasColumn(&dbmodels.RoomSchedule{}, "Timezone") {
+		t.Error("timezone column of room schedules should be dropped")
+	}
+
+	list, err := m.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	last := list[len(list)-1]
+	if last.Applied {
+		t.Errorf("migration %d_%s should be pending", last.Version, last.Name)
+	}
+	for _, s := range list[:len(list)-1] {
+		if !s.Applied {
+			t.Errorf("migration %d_%s should be applied", s.Version, s.Name)
+		}
+	}
+
+	// revert all the remaining one
+	if _, err = m.Down(len(migrations)); err != nil {
+		t.Fatalf("Down all failed: %v", err)
+	}
+	if db.Migrator().HasTable(&dbmodels.RoomInfo{}) {
+		t.Error("room_info table should be dropped")
+	}
+	pending, err := m.Pending()
+	if err != nil {
+		t.Fatalf("Pending failed: %v", err)
+	}
+	if pending != len(migrations) {
+		t.Errorf("expected %d pending migrations, got %d", len(migrations), pending)
+	}
+}
+
+func TestMigratorStatusUnknownSqlite(t *testing.T) {
+	m, db := newTestMigrator(t)
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+
+	// applied by a newer server
+	if err := db.Create(&dbmodels.SchemaMigration{Version: 9999, Name: "future"}).Error; err != nil {
+		t.Fatalf("failed to insert migration row: %v", err)
+	}
+
+	list, err := m.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	last := list[len(list)-1]
+	if last.Version != 9999 || !last.Unknown || !last.Applied {
+		t.Errorf("expected unknown applied migration 9999, got %+v", last)
+	}
+}
@@ -3,12 +3,15 @@ package models
 import (
 	"context"
 	"fmt"
+	"math"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
 	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
 	"github.com/mynaparrot/plugnmeet-server/pkg/helpers"
 	"github.com/mynaparrot/plugnmeet-server/pkg/services/db"
 	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
@@ -21,6 +24,16 @@ import (
 const (
 	analyticsRoomKey = redisservice.Prefix + "analytics:%s"
 	analyticsUserKey = analyticsRoomKey + ":user:%s"
+	// analyticsPollResultsEvent isn't part of the protocol's events,
+	// it will be exported as room event with name poll_results
+	analyticsPollResultsEvent = "ANALYTICS_EVENT_ROOM_POLL_RESULTS"
+	// analyticsPresenterChangedEvent will be exported as room event with name presenter_changed
+	analyticsPresenterChangedEvent = "ANALYTICS_EVENT_ROOM_PRESENTER_CHANGED"
+	// talk-time statistics from the transcription, exported as user events
+	// with names talk_time_sec, talk_interruptions & talk_words_per_minute
+	analyticsTalkTimeEvent           = "ANALYTICS_EVENT_USER_TALK_TIME_SEC"
+	analyticsTalkInterruptionsEvent  = "ANALYTICS_EVENT_USER_TALK_INTERRUPTIONS"
+	analyticsTalkWordsPerMinuteEvent = "ANALYTICS_EVENT_USER_TALK_WORDS_PER_MINUTE"
 )
 
 type AnalyticsModel struct {
@@ -32,6 +45,7 @@ type AnalyticsModel struct {
 	webhookNotifier *helpers.WebhookNotifier
 	logger          *logrus.Entry
 	artifactModel   *ArtifactModel
+	ltiGradeModel   *LtiGradeModel
 }
 
 type AnalyticsModelArgs struct {
@@ -63,6 +77,11 @@ func (m *AnalyticsModel) SetArtifactModel(am *ArtifactModel) {
 	m.artifactModel = am
 }
 
+// SetLtiGradeModel sets the LtiGradeModel to resolve the circular dependency.
+func (m *AnalyticsModel) SetLtiGradeModel(lgm *LtiGradeModel) {
+	m.ltiGradeModel = lgm
+}
+
 // insertEventData stores an analytics event in Redis based on its type.
 // It uses HSET for events with timestamps and INCRBY or SET for simpler counter or string values.
 func (m *AnalyticsModel) insertEventData(d *plugnmeet.AnalyticsDataMsg, key string) {
@@ -103,6 +122,65 @@ func (m *AnalyticsModel) insertEventData(d *plugnmeet.AnalyticsDataMsg, key stri
 	}
 }
 
+// AddPollResults stores the results of the polls as room event,
+// so that those can be part of the export even after polls were cleaned up.
+func (m *AnalyticsModel) AddPollResults(roomId string, results []string) {
+	if m.app.AnalyticsSettings == nil ||
+		!m.app.AnalyticsSettings.Enabled || len(results) == 0 {
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	val := make(map[string]string, len(results))
+	for i, r := range results {
+		// fields need to be unique
+		val[fmt.Sprintf("%d", now+int64(i))] = r
+	}
+
+	k := fmt.Sprintf(analyticsRoomKey+":room:%s", roomId, analyticsPollResultsEvent)
+	if err := m.rs.AddAnalyticsHSETType(k, val); err != nil {
+		m.logger.WithError(err).Errorln("AddAnalyticsHSETType failed")
+	}
+}
+
+// AddRoomEvent stores a room event which isn't part of the protocol's events
+func (m *AnalyticsModel) AddRoomEvent(roomId, eventName, value string) {
+	if m.app.AnalyticsSettings == nil ||
+		!m.app.AnalyticsSettings.Enabled {
+		return
+	}
+
+	val := map[string]string{
+		fmt.Sprintf("%d", time.Now().UnixMilli()): value,
+	}
+	k := fmt.Sprintf(analyticsRoomKey+":room:%s", roomId, eventName)
+	if err := m.rs.AddAnalyticsHSETType(k, val); err != nil {
+		m.logger.WithError(err).Errorln("AddAnalyticsHSETType failed")
+	}
+}
+
+// AddUserTalkStats stores the talk-time statistics of the participants as user events
+func (m *AnalyticsModel) AddUserTalkStats(roomId string, participants []*TalkStatsInfo) {
+	if m.app.AnalyticsSettings == nil ||
+		!m.app.AnalyticsSettings.Enabled {
+		return
+	}
+
+	for _, p := range participants {
+		key := fmt.Sprintf(analyticsUserKey, roomId, p.UserId)
+		vals := map[string]int64{
+			analyticsTalkTimeEvent:           int64(math.Round(p.TalkTimeSec)),
+			analyticsTalkInterruptionsEvent:  p.Interruptions,
+			analyticsTalkWordsPerMinuteEvent: int64(math.Round(p.WordsPerMinute)),
+		}
+		for event, val := range vals {
+			if err := m.rs.AddAnalyticsStringType(fmt.Sprintf("%s:%s", key, event), strconv.FormatInt(val, 10)); err != nil {
+				m.logger.WithError(err).Errorln("AddAnalyticsStringType failed")
+			}
+		}
+	}
+}
+
 // handleFirstTimeUserJoined records a user's information in Redis the first time they join.
 // It also triggers the insertion of the user_joined event.
 func (m *AnalyticsModel) handleFirstTimeUserJoined(d *plugnmeet.AnalyticsDataMsg, key string) {
@@ -193,7 +271,7 @@ func (m *AnalyticsModel) handleUserTypeEvents(d *plugnmeet.AnalyticsDataMsg) {
 // FetchAnalytics retrieves a paginated list of analytics files.
 // Deprecated: For backward compatibility, it fetches data from both the new artifacts system
 // and the old analytics table, then merges and sorts the results.
-func (m *AnalyticsModel) FetchAnalytics(r *plugnmeet.FetchAnalyticsReq) (*plugnmeet.FetchAnalyticsResult, error) {
+func (m *AnalyticsModel) FetchAnalytics(tenantId string, r *plugnmeet.FetchAnalyticsReq) (*plugnmeet.FetchAnalyticsResult, error) {
 	if r.Limit <= 0 {
 		r.Limit = 20
 	} else if r.Limit > 100 {
@@ -204,7 +282,7 @@ func (m *AnalyticsModel) FetchAnalytics(r *plugnmeet.FetchAnalyticsReq) (*plugnm
 	}
 
 	// 1. Fetch from the new artifacts system
-	artifacts, err := m.artifactModel.FetchArtifacts(&plugnmeet.FetchArtifactsReq{
+	artifacts, err := m.artifactModel.FetchArtifacts(tenantId, &plugnmeet.FetchArtifactsReq{
 		RoomIds: r.RoomIds,
 		Type:    new(plugnmeet.RoomArtifactType_MEETING_ANALYTICS),
 		Limit:   uint64(r.Limit),
@@ -232,10 +310,15 @@ func (m *AnalyticsModel) FetchAnalytics(r *plugnmeet.FetchAnalyticsReq) (*plugnm
 		analytics = append(analytics, analytic)
 	}
 
-	// 2. Fetch from the old analytics table
-	oldData, totalOld, err := m.ds.GetAnalytics(r.RoomIds, uint64(r.From), uint64(r.Limit), &r.OrderBy)
-	if err != nil {
-		return nil, err
+	// 2. Fetch from the old analytics table,
+	// those were created before tenants, so only the root api key can see them
+	var oldData []dbmodels.Analytics
+	var totalOld int64
+	if tenantId == "" {
+		oldData, totalOld, err = m.ds.GetAnalytics(r.RoomIds, uint64(r.From), uint64(r.Limit), &r.OrderBy)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	for _, v := range oldData {
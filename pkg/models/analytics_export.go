@@ -80,12 +80,17 @@ func (m *AnalyticsModel) PrepareToExportAnalytics(roomId, sid, meta string) {
 		return
 	}
 
-	jsonData, err := m.exportAnalyticsToJSON(room, metadata, log)
+	result, jsonData, err := m.exportAnalyticsToJSON(room, metadata, log)
 	if err != nil {
 		log.WithError(err).Error("failed to export analytics to file")
 		return
 	}
 
+	// attendance of LTI users will be sent to LMS, if enabled
+	if m.ltiGradeModel != nil {
+		m.ltiGradeModel.PublishAttendanceScores(result, log)
+	}
+
 	// it's not possible to get room metadata as always
 	// so, if room didn't have activated analytics feature,
 	// we will simply won't create the in exportAnalyticsToFile method
@@ -104,7 +109,7 @@ func (m *AnalyticsModel) PrepareToExportAnalytics(roomId, sid, meta string) {
 	}
 }
 
-func (m *AnalyticsModel) exportAnalyticsToJSON(room *dbmodels.RoomInfo, metadata *plugnmeet.RoomMetadata, log *logrus.Entry) ([]byte, error) {
+func (m *AnalyticsModel) exportAnalyticsToJSON(room *dbmodels.RoomInfo, metadata *plugnmeet.RoomMetadata, log *logrus.Entry) (*plugnmeet.AnalyticsResult, []byte, error) {
 	roomInfo := &plugnmeet.AnalyticsRoomInfo{
 		RoomId:       room.RoomId,
 		RoomTitle:    room.RoomTitle,
@@ -119,7 +124,7 @@ func (m *AnalyticsModel) exportAnalyticsToJSON(room *dbmodels.RoomInfo, metadata
 	allKeys, err := m.rs.AnalyticsScanKeys(scanPattern)
 	if err != nil {
 		log.WithError(err).Error("failed to scan analytics keys for room")
-		return nil, err
+		return nil, nil, err
 	}
 	if len(allKeys) == 0 {
 		log.Info("No analytics keys found, file will contain only basic room info")
@@ -145,7 +150,7 @@ func (m *AnalyticsModel) exportAnalyticsToJSON(room *dbmodels.RoomInfo, metadata
 	users, err := m.rs.AnalyticsGetAllUsers(k)
 	if err != nil {
 		log.WithError(err).Error("failed to get analytics users from redis")
-		return nil, err
+		return nil, nil, err
 	}
 	roomInfo.RoomTotalUsers = int64(len(users))
 	roomInfo.RoomDuration = roomInfo.RoomEnded - roomInfo.RoomCreation
@@ -176,15 +181,16 @@ func (m *AnalyticsModel) exportAnalyticsToJSON(room *dbmodels.RoomInfo, metadata
 		usersInfo = append(usersInfo, userInfo)
 	}
 
+	result := &plugnmeet.AnalyticsResult{
+		Room:  roomInfo,
+		Users: usersInfo,
+	}
+
 	var marshal []byte
 	// it's not possible to get room metadata as always
 	// so, if room didn't have activated analytics feature,
 	// we will simply won't create the file & delete all records
 	if metadata.RoomFeatures.EnableAnalytics {
-		result := &plugnmeet.AnalyticsResult{
-			Room:  roomInfo,
-			Users: usersInfo,
-		}
 		op := protojson.MarshalOptions{
 			EmitUnpopulated: true,
 			UseProtoNames:   true,
@@ -192,7 +198,7 @@ func (m *AnalyticsModel) exportAnalyticsToJSON(room *dbmodels.RoomInfo, metadata
 		marshal, err = op.Marshal(result)
 		if err != nil {
 			log.WithError(err).Error("Failed to marshal analytics result")
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
@@ -205,7 +211,7 @@ func (m *AnalyticsModel) exportAnalyticsToJSON(room *dbmodels.RoomInfo, metadata
 		log.WithError(err).Error("Failed to delete analytics keys from redis")
 	}
 
-	return marshal, err
+	return result, marshal, err
 }
 
 func (m *AnalyticsModel) processEventKey(key, prefix string, eventList *[]*plugnmeet.AnalyticsEventData) {
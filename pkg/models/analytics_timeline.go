@@ -0,0 +1,104 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// AnalyticsTimelineEvent is an event of the session which marks a change of the content,
+// Name is the event name without prefix in lower case e.g. screen_share_status
+type AnalyticsTimelineEvent struct {
+	Time     int64
+	Name     string
+	UserId   string
+	UserName string
+	Value    string
+}
+
+// isTimelineEvent checks the event name of the analytics key.
+// Whiteboard events are sent by the clients, so we'll accept all of those.
+func isTimelineEvent(eventName string) bool {
+	return strings.HasSuffix(eventName, "SCREEN_SHARE_STATUS") ||
+		eventName == analyticsPresenterChangedEvent ||
+		strings.Contains(eventName, "WHITEBOARD")
+}
+
+// GetTimelineEvents returns the timeline events of the room sorted by time.
+// It must be called before the analytics export, as the export removes all the records.
+func (m *AnalyticsModel) GetTimelineEvents(roomId string) ([]*AnalyticsTimelineEvent, error) {
+	if m.app.AnalyticsSettings == nil || !m.app.AnalyticsSettings.Enabled {
+		return nil, nil
+	}
+
+	allKeys, err := m.rs.AnalyticsScanKeys(fmt.Sprintf(analyticsRoomKey, roomId) + ":*")
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string)
+	roomKeyPrefix := fmt.Sprintf(analyticsRoomKey+":room", roomId)
+	if users, err := m.rs.AnalyticsGetAllUsers(roomKeyPrefix + ":users"); err == nil {
+		for id, u := range users {
+			uf := new(plugnmeet.AnalyticsRedisUserInfo)
+			if err := protojson.Unmarshal([]byte(u), uf); err == nil {
+				names[id] = uf.GetName()
+			}
+		}
+	}
+
+	userKeyPrefix := fmt.Sprintf(analyticsRoomKey+":user:", roomId)
+	var events []*AnalyticsTimelineEvent
+	for _, key := range allKeys {
+		var userId, eventName string
+		if rest, ok := strings.CutPrefix(key, userKeyPrefix); ok {
+			userId, eventName, ok = strings.Cut(rest, ":")
+			if !ok {
+				continue
+			}
+		} else if rest, ok := strings.CutPrefix(key, roomKeyPrefix+":"); ok {
+			eventName = rest
+		} else {
+			continue
+		}
+		if !isTimelineEvent(eventName) {
+			continue
+		}
+		if rType, err := m.rs.AnalyticsGetKeyType(key); err != nil || rType != "hash" {
+			continue
+		}
+
+		vals, err := m.rs.GetAnalyticsAllHashTypeVals(key)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(eventName, "ANALYTICS_EVENT_ROOM_"), "ANALYTICS_EVENT_USER_"))
+		for field, val := range vals {
+			ts, err := strconv.ParseInt(field, 10, 64)
+			if err != nil {
+				continue
+			}
+			e := &AnalyticsTimelineEvent{
+				Time:   ts,
+				Name:   name,
+				UserId: userId,
+				Value:  val,
+			}
+			if eventName == analyticsPresenterChangedEvent {
+				// value is the new presenter
+				e.UserId = val
+			}
+			e.UserName = names[e.UserId]
+			events = append(events, e)
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Time < events[j].Time
+	})
+	return events, nil
+}
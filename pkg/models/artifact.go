@@ -18,6 +18,7 @@ import (
 	dbservice "github.com/mynaparrot/plugnmeet-server/pkg/services/db"
 	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
 	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+	storageservice "github.com/mynaparrot/plugnmeet-server/pkg/services/storage"
 	"github.com/sirupsen/logrus"
 	"go.uber.org/fx"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -35,6 +36,7 @@ type ArtifactModel struct {
 	ds              *dbservice.DatabaseService
 	rs              *redisservice.RedisService
 	natsService     *natsservice.NatsService
+	storageService  *storageservice.StorageService
 	webhookNotifier *helpers.WebhookNotifier
 	analyticsModel  *AnalyticsModel
 	log             *logrus.Entry
@@ -47,6 +49,7 @@ type ArtifactModelArgs struct {
 	Ds              *dbservice.DatabaseService
 	RedisService    *redisservice.RedisService
 	NatsService     *natsservice.NatsService
+	StorageService  *storageservice.StorageService
 	WebhookNotifier *helpers.WebhookNotifier
 	AnalyticsModel  *AnalyticsModel
 	Logger          *logrus.Logger
@@ -59,14 +62,17 @@ func NewArtifactModel(args ArtifactModelArgs) *ArtifactModel {
 		ds:              args.Ds,
 		rs:              args.RedisService,
 		natsService:     args.NatsService,
+		storageService:  args.StorageService,
 		webhookNotifier: args.WebhookNotifier,
 		analyticsModel:  args.AnalyticsModel,
 		log:             args.Logger.WithField("model", "artifact"),
 	}
 }
 
+// buildPath returns the relative path (which is also the storage key) & the local path to write the file.
+// For remote storage drivers, the local file works as staging copy & will be uploaded by storeArtifactFile.
 func (m *ArtifactModel) buildPath(fileName, roomId string, artifactType plugnmeet.RoomArtifactType) (relativePath string, absolutePath string, err error) {
-	relativeDir := filepath.Join(strings.ToLower(artifactType.String()), roomId)
+	relativeDir := filepath.Join(strings.ToLower(dbmodels.RoomArtifactType(artifactType).String()), roomId)
 	absoluteDir := filepath.Join(*m.app.ArtifactsSettings.StoragePath, relativeDir)
 
 	err = os.MkdirAll(absoluteDir, 0755)
@@ -118,6 +124,29 @@ func (m *ArtifactModel) runUploadHook(roomId, roomSid string, roomTableId uint64
 	}
 }
 
+// storeArtifactFile uploads the locally written artifact file to the configured storage.
+// Nothing to do for local storage or if the upload hook is in use.
+// If the upload fails, the local copy will be kept & download will fall back to it.
+func (m *ArtifactModel) storeArtifactFile(metadata *plugnmeet.RoomArtifactMetadata, log *logrus.Entry) {
+	if m.app.Hooks != nil || m.storageService.IsLocal() {
+		return
+	}
+	if metadata.FileInfo == nil || metadata.FileInfo.FilePath == "" {
+		return
+	}
+
+	localPath := filepath.Join(*m.app.ArtifactsSettings.StoragePath, metadata.FileInfo.FilePath)
+	err := m.storageService.PutFile(m.ctx, m.storageService.Artifacts(), metadata.FileInfo.FilePath, localPath, metadata.FileInfo.MimeType)
+	if err != nil {
+		log.WithError(err).Error("failed to upload artifact to storage, keeping local copy")
+		return
+	}
+
+	if err := os.Remove(localPath); err != nil {
+		log.WithError(err).Warn("failed to remove local copy of artifact")
+	}
+}
+
 // MoveToTrash moves a specified file to the configured backup/trash directory.
 // It returns the new path of the file in the trash directory.
 func (m *ArtifactModel) MoveToTrash(filePath string) (string, error) {
@@ -225,6 +254,8 @@ func (m *ArtifactModel) HandleAnalyticsEvent(roomId string, eventName plugnmeet.
 func (m *ArtifactModel) createAndSaveArtifact(roomId, roomSid string, roomTableId uint64, artifactType plugnmeet.RoomArtifactType, metadata *plugnmeet.RoomArtifactMetadata, forceSend bool, log *logrus.Entry) (*dbmodels.RoomArtifact, error) {
 	// If a file is associated, run the upload hook to potentially move it and update the metadata path.
 	m.runUploadHook(roomId, roomSid, roomTableId, metadata, log)
+	// Otherwise, move it to the configured storage.
+	m.storeArtifactFile(metadata, log)
 
 	metadataBytes, err := protojson.Marshal(metadata)
 	if err != nil {
@@ -238,6 +269,10 @@ func (m *ArtifactModel) createAndSaveArtifact(roomId, roomSid string, roomTableI
 		Type:        dbmodels.RoomArtifactType(artifactType),
 		Metadata:    string(metadataBytes),
 	}
+	// artifact will belong to the same tenant as the room
+	if roomInfo, err := m.ds.GetRoomInfoByTableId(roomTableId); err == nil && roomInfo != nil {
+		artifact.TenantId = roomInfo.TenantId
+	}
 
 	_, err = m.ds.CreateRoomArtifact(artifact)
 	if err != nil {
@@ -245,10 +280,44 @@ func (m *ArtifactModel) createAndSaveArtifact(roomId, roomSid string, roomTableI
 	}
 
 	m.sendWebhookNotification(ArtifactCreated, roomSid, artifact, metadata, forceSend)
-	log.Infof("successfully created %s artifact (id: %s) for room %s", artifactType.String(), artifact.ArtifactId, roomId)
+	log.Infof("successfully created %s artifact (id: %s) for room %s", artifact.Type, artifact.ArtifactId, roomId)
 	return artifact, nil
 }
 
+// saveArtifactFile writes the content to a new file of the artifact type & saves the artifact,
+// e.g. prefix "polls" & ext "csv" will create polls_<roomSid>-<time>.csv
+func (m *ArtifactModel) saveArtifactFile(prefix string, artifactType plugnmeet.RoomArtifactType, roomId, roomSid string, roomTableId uint64, ext, mimeType string, content []byte, referenceArtifactId *string, log *logrus.Entry) (*dbmodels.RoomArtifact, error) {
+	fileName := fmt.Sprintf("%s_%s-%d.%s", prefix, roomSid, time.Now().UnixMilli(), ext)
+	relativePath, absolutePath, err := m.buildPath(fileName, roomId, artifactType)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(absolutePath, content, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s file: %w", prefix, err)
+	}
+
+	metadata := &plugnmeet.RoomArtifactMetadata{
+		FileInfo: &plugnmeet.RoomArtifactFileInfo{
+			FilePath: relativePath,
+			FileSize: int64(len(content)),
+			MimeType: mimeType,
+		},
+		ReferenceArtifactId: referenceArtifactId,
+	}
+	return m.createAndSaveArtifact(roomId, roomSid, roomTableId, artifactType, metadata, false, log)
+}
+
+// escapeCSVFormulas prefixes the cells which spreadsheet apps would run as formula with a single quote,
+// questions, answers & names are entered by the users.
+func escapeCSVFormulas(record []string) []string {
+	for i, cell := range record {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			record[i] = "'" + cell
+		}
+	}
+	return record
+}
+
 func roundAndPointer(val float64, precision int) *float64 {
 	multiplier := math.Pow10(precision)
 	return new(math.Round(val*multiplier) / multiplier)
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/sirupsen/logrus"
+)
+
+// chatTranscriptExtraDataKey is the key of room metadata extra_data to opt in
+const chatTranscriptExtraDataKey = "chat_transcript"
+
+type chatTranscriptMessage struct {
+	MessageId  string `json:"message_id"`
+	FromUserId string `json:"from_user_id"`
+	FromName   string `json:"from_name"`
+	IsPrivate  bool   `json:"is_private"`
+	ToUserId   string `json:"to_user_id,omitempty"`
+	Message    string `json:"message"`
+	SentAt     int64  `json:"sent_at"`
+}
+
+type chatTranscript struct {
+	RoomId   string                   `json:"room_id"`
+	RoomSid  string                   `json:"room_sid"`
+	Messages []*chatTranscriptMessage `json:"messages"`
+}
+
+// isChatTranscriptEnabled checks if the room has opted in for chat transcript
+func (m *ArtifactModel) isChatTranscriptEnabled(roomMetadata string) bool {
+	if !m.app.ChatTranscript.Enabled {
+		return false
+	}
+	if m.app.ChatTranscript.EnabledForAllRooms {
+		return true
+	}
+	if roomMetadata == "" {
+		return false
+	}
+	meta, err := m.natsService.UnmarshalRoomMetadata(roomMetadata)
+	if err != nil {
+		return false
+	}
+	return meta.GetExtraData()[chatTranscriptExtraDataKey] == "true"
+}
+
+// CreateChatTranscriptArtifact drains the chat messages of the session from the stream
+// & stores them as JSON & plain text artifacts. It should be called before purging the messages of the room.
+func (m *ArtifactModel) CreateChatTranscriptArtifact(roomId, roomSid string, roomTableId uint64, roomMetadata string, log *logrus.Entry) {
+	if !m.isChatTranscriptEnabled(roomMetadata) {
+		return
+	}
+	log = log.WithField("method", "CreateChatTranscriptArtifact")
+
+	messages, err := m.natsService.GetRoomChatMessages(roomId)
+	if err != nil {
+		log.WithError(err).Errorln("failed to get chat messages")
+		return
+	}
+
+	transcript := &chatTranscript{
+		RoomId:   roomId,
+		RoomSid:  roomSid,
+		Messages: make([]*chatTranscriptMessage, 0, len(messages)),
+	}
+	// names are taken from our user info, the client may send anything in the message
+	names := make(map[string]string)
+	for _, sm := range messages {
+		if sm.Msg.GetIsPrivate() && m.app.ChatTranscript.ExcludePrivateMessages {
+			continue
+		}
+		name, ok := names[sm.UserId]
+		if !ok {
+			name = sm.UserId
+			if info, err := m.natsService.GetUserInfo(roomId, sm.UserId); err == nil && info != nil {
+				name = info.GetName()
+			}
+			names[sm.UserId] = name
+		}
+		transcript.Messages = append(transcript.Messages, &chatTranscriptMessage{
+			MessageId:  sm.Msg.GetId(),
+			FromUserId: sm.UserId,
+			FromName:   name,
+			IsPrivate:  sm.Msg.GetIsPrivate(),
+			ToUserId:   sm.Msg.GetToUserId(),
+			Message:    sm.Msg.GetMessage(),
+			SentAt:     sm.Received.UnixMilli(),
+		})
+	}
+	if len(transcript.Messages) == 0 {
+		log.Infoln("no chat messages found, skipping chat transcript")
+		return
+	}
+
+	content, err := json.MarshalIndent(transcript, "", "  ")
+	if err != nil {
+		log.WithError(err).Errorln("failed to marshal chat transcript")
+		return
+	}
+	jsonArtifact, err := m.saveArtifactFile("chat", dbmodels.RoomArtifactTypeChatTranscript, roomId, roomSid, roomTableId, "json", "application/json", content, nil, log)
+	if err != nil {
+		log.WithError(err).Errorln("failed to create chat transcript artifact")
+		return
+	}
+
+	// plain text version for humans, linked with the JSON one
+	if _, err = m.saveArtifactFile("chat", dbmodels.RoomArtifactTypeChatTranscript, roomId, roomSid, roomTableId, "txt", "text/plain", renderChatTranscriptText(transcript), &jsonArtifact.ArtifactId, log); err != nil {
+		log.WithError(err).Errorln("failed to create plain text chat transcript artifact")
+	}
+}
+
+func renderChatTranscriptText(t *chatTranscript) []byte {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Chat transcript for meeting: %s (%s)\n\n", t.RoomId, t.RoomSid))
+	for _, msg := range t.Messages {
+		from := msg.FromName
+		if msg.IsPrivate {
+			from = fmt.Sprintf("%s (private to %s)", msg.FromName, msg.ToUserId)
+		}
+		b.WriteString(fmt.Sprintf("[%s] %s: %s\n", time.UnixMilli(msg.SentAt).UTC().Format(time.RFC3339), from, msg.Message))
+	}
+	return []byte(b.String())
+}
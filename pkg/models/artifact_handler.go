@@ -12,12 +12,14 @@ import (
 	"github.com/mynaparrot/plugnmeet-protocol/hooks"
 	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
 	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
 	"github.com/mynaparrot/plugnmeet-server/pkg/helpers"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
 // FetchArtifacts fetches records from the DB and formats them for the API response.
-func (m *ArtifactModel) FetchArtifacts(req *plugnmeet.FetchArtifactsReq) (*plugnmeet.FetchArtifactsResult, error) {
+// If tenantId isn't empty, only artifacts of that tenant will be returned.
+func (m *ArtifactModel) FetchArtifacts(tenantId string, req *plugnmeet.FetchArtifactsReq) (*plugnmeet.FetchArtifactsResult, error) {
 	if req.Limit <= 0 {
 		req.Limit = 20
 	} else if req.Limit > 100 {
@@ -27,7 +29,7 @@ func (m *ArtifactModel) FetchArtifacts(req *plugnmeet.FetchArtifactsReq) (*plugn
 		req.OrderBy = "DESC"
 	}
 
-	dbArtifacts, total, err := m.ds.GetArtifacts(req.RoomIds, req.RoomSid, req.Type, req.From, req.Limit, &req.OrderBy)
+	dbArtifacts, total, err := m.ds.GetArtifacts(tenantId, req.RoomIds, req.RoomSid, req.Type, req.From, req.Limit, &req.OrderBy)
 	if err != nil {
 		return nil, err
 	}
@@ -101,7 +103,7 @@ func (m *ArtifactModel) GetArtifactInfoByArtifactId(artifactId string) (*plugnme
 
 // generateToken now generates a JWT with a file path.
 func (m *ArtifactModel) generateToken(filePath string) (string, error) {
-	sig, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte(m.app.Client.Secret)}, (&jose.SignerOptions{}).WithType("JWT"))
+	sig, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte(m.natsService.GetClientSecret())}, (&jose.SignerOptions{}).WithType("JWT"))
 
 	if err != nil {
 		return "", err
@@ -150,7 +152,10 @@ func (m *ArtifactModel) VerifyArtifactDownloadJWT(token string) (*hooks.Download
 	}
 
 	out := jwt.Claims{}
-	if err = tok.Claims([]byte(m.app.Client.Secret), &out); err != nil {
+	err = verifyWithSecrets(m.natsService.GetClientSecrets(), func(secret string) error {
+		return tok.Claims([]byte(secret), &out)
+	})
+	if err != nil {
 		return nil, fiber.StatusUnauthorized, err
 	}
 
@@ -186,6 +191,18 @@ func (m *ArtifactModel) VerifyArtifactDownloadJWT(token string) (*hooks.Download
 		}
 	}
 
+	if !m.storageService.IsLocal() {
+		res, err := m.storageService.GetDownloadHookData(m.ctx, m.storageService.Artifacts(), inputPath)
+		if err == nil {
+			return res, fiber.StatusOK, nil
+		}
+		if !errors.Is(err, config.ErrFileNotFound) {
+			log.WithError(err).Error("failed to get download url from storage")
+			return nil, fiber.StatusInternalServerError, errors.New("failed to get download url from storage")
+		}
+		// may be the upload failed, so we'll check the local copy
+	}
+
 	// If no hooks are defined or no output from script, fallback to local.
 	absolutePath, mType, err := helpers.ValidateAndGetAbsFilePath(*m.app.ArtifactsSettings.StoragePath, inputPath)
 	if err != nil {
@@ -228,6 +245,10 @@ func (m *ArtifactModel) DeleteArtifact(req *plugnmeet.DeleteArtifactReq) error {
 				if _, err := m.app.Hooks.RunDeleteHook(&delReq, m.log); err != nil {
 					m.log.Warnf("delete hook script returned an error for artifact: %s", err.Error())
 				}
+			} else if !m.storageService.IsLocal() {
+				if err := m.storageService.Artifacts().Delete(m.ctx, metadata.FileInfo.FilePath); err != nil {
+					m.log.WithError(err).Warn("failed to delete artifact from storage")
+				}
 			} else {
 				// Otherwise, we'll only try to delete if it's a local file.
 				absolutePath := filepath.Join(*m.app.ArtifactsSettings.StoragePath, metadata.FileInfo.FilePath)
@@ -252,7 +273,11 @@ func (m *ArtifactModel) isDownloadable(artifactType plugnmeet.RoomArtifactType)
 	switch artifactType {
 	case plugnmeet.RoomArtifactType_MEETING_ANALYTICS,
 		plugnmeet.RoomArtifactType_MEETING_SUMMARY,
-		plugnmeet.RoomArtifactType_SPEECH_TRANSCRIPTION:
+		plugnmeet.RoomArtifactType_SPEECH_TRANSCRIPTION,
+		dbmodels.RoomArtifactTypeChatTranscript,
+		dbmodels.RoomArtifactTypePollResults,
+		dbmodels.RoomArtifactTypeLiveSummary,
+		dbmodels.RoomArtifactTypeTalkTimeStats:
 		return true
 	}
 
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
+	"github.com/sirupsen/logrus"
+)
+
+type liveSummaryExport struct {
+	RoomId  string `json:"room_id"`
+	RoomSid string `json:"room_sid"`
+	*LiveSummary
+}
+
+// liveSummaryTokensCost calculates the cost using the pricing of the summarize_model of the live_summary service
+func liveSummaryTokensCost(app *config.AppConfig, promptTokens, completionTokens int64) (promptCost, completionCost float64, err error) {
+	if app.Insights == nil {
+		return 0, 0, fmt.Errorf("insights wasn't configured")
+	}
+	model := "default"
+	if service, ok := app.Insights.Services[insights.ServiceTypeLiveSummary]; ok && service != nil {
+		model = service.GetOptionsString("summarize_model", model)
+	}
+	pricing, err := app.Insights.GetServiceModelPricing(insights.ServiceTypeLiveSummary, model)
+	if err != nil {
+		return 0, 0, err
+	}
+	promptCost = (float64(promptTokens) / 1000000) * pricing.InputPricePerMillionTokens
+	completionCost = (float64(completionTokens) / 1000000) * pricing.OutputPricePerMillionTokens
+	return promptCost, completionCost, nil
+}
+
+// CreateLiveSummaryArtifact stores the final live summary as JSON & plain text artifacts,
+// with a usage artifact linked to the JSON one.
+func (m *ArtifactModel) CreateLiveSummaryArtifact(roomId, roomSid string, roomTableId uint64, summary *LiveSummary, promptTokens, completionTokens int64, log *logrus.Entry) {
+	log = log.WithField("method", "CreateLiveSummaryArtifact")
+
+	var jsonArtifactId *string
+	if summary != nil && !summary.IsEmpty() {
+		content, err := json.MarshalIndent(&liveSummaryExport{
+			RoomId:      roomId,
+			RoomSid:     roomSid,
+			LiveSummary: summary,
+		}, "", "  ")
+		if err != nil {
+			log.WithError(err).Errorln("failed to marshal live summary")
+			return
+		}
+		jsonArtifact, err := m.saveArtifactFile("live_summary", dbmodels.RoomArtifactTypeLiveSummary, roomId, roomSid, roomTableId, "json", "application/json", content, nil, log)
+		if err != nil {
+			log.WithError(err).Errorln("failed to create live summary artifact")
+			return
+		}
+		jsonArtifactId = &jsonArtifact.ArtifactId
+
+		if _, err = m.saveArtifactFile("live_summary", dbmodels.RoomArtifactTypeLiveSummary, roomId, roomSid, roomTableId, "txt", "text/plain", []byte(renderLiveSummaryText(roomId, summary)), jsonArtifactId, log); err != nil {
+			log.WithError(err).Errorln("failed to create plain text live summary artifact")
+		}
+	}
+
+	totalTokens := promptTokens + completionTokens
+	if totalTokens == 0 {
+		return
+	}
+	promptCost, completionCost, err := liveSummaryTokensCost(m.app, promptTokens, completionTokens)
+	if err != nil {
+		log.WithError(err).Warnln("could not calculate cost for live_summary")
+	}
+	metadata := &plugnmeet.RoomArtifactMetadata{
+		UsageDetails: &plugnmeet.RoomArtifactMetadata_TokenUsage{
+			TokenUsage: &plugnmeet.RoomArtifactTokenUsage{
+				PromptTokens:                  uint32(promptTokens),
+				CompletionTokens:              uint32(completionTokens),
+				TotalTokens:                   uint32(totalTokens),
+				PromptTokensEstimatedCost:     roundAndPointer(promptCost, 6),
+				CompletionTokensEstimatedCost: roundAndPointer(completionCost, 6),
+				TotalTokensEstimatedCost:      roundAndPointer(promptCost+completionCost, 6),
+			},
+		},
+		ReferenceArtifactId: jsonArtifactId,
+	}
+	if _, err = m.createAndSaveArtifact(roomId, roomSid, roomTableId, dbmodels.RoomArtifactTypeLiveSummaryUsage, metadata, false, log); err != nil {
+		log.WithError(err).Errorln("failed to create live summary usage artifact")
+	}
+}
+
+// renderLiveSummaryText is used for the plain text artifact & the updates sent to the moderators
+func renderLiveSummaryText(roomId string, s *LiveSummary) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Meeting summary so far: %s\n\n%s\n", roomId, s.Summary))
+
+	if len(s.ActionItems) > 0 {
+		b.WriteString("\nAction items:\n")
+		for _, a := range s.ActionItems {
+			b.WriteString("- " + a.Task)
+			var details []string
+			if a.Owner != "" {
+				details = append(details, "owner: "+a.Owner)
+			}
+			if a.DueDate != "" {
+				details = append(details, "due: "+a.DueDate)
+			}
+			if len(details) > 0 {
+				b.WriteString(" (" + strings.Join(details, ", ") + ")")
+			}
+			b.WriteString("\n")
+		}
+	}
+	if len(s.Decisions) > 0 {
+		b.WriteString("\nDecisions:\n")
+		for _, d := range s.Decisions {
+			b.WriteString("- " + d + "\n")
+		}
+	}
+	return b.String()
+}
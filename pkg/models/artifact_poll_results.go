@@ -0,0 +1,83 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/sirupsen/logrus"
+)
+
+type pollResultsExport struct {
+	RoomId  string             `json:"room_id"`
+	RoomSid string             `json:"room_sid"`
+	Polls   []*PollResultsInfo `json:"polls"`
+}
+
+// CreatePollResultsArtifact stores the results of the polls as JSON & CSV artifacts.
+func (m *ArtifactModel) CreatePollResultsArtifact(roomId, roomSid string, roomTableId uint64, polls []*PollResultsInfo, log *logrus.Entry) {
+	log = log.WithField("method", "CreatePollResultsArtifact")
+
+	export := &pollResultsExport{
+		RoomId:  roomId,
+		RoomSid: roomSid,
+		Polls:   polls,
+	}
+	content, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		log.WithError(err).Errorln("failed to marshal poll results")
+		return
+	}
+	jsonArtifact, err := m.saveArtifactFile("polls", dbmodels.RoomArtifactTypePollResults, roomId, roomSid, roomTableId, "json", "application/json", content, nil, log)
+	if err != nil {
+		log.WithError(err).Errorln("failed to create poll results artifact")
+		return
+	}
+
+	// spreadsheet friendly version, linked with the JSON one
+	csvContent, err := renderPollResultsCSV(polls)
+	if err != nil {
+		log.WithError(err).Errorln("failed to render poll results csv")
+		return
+	}
+	if _, err = m.saveArtifactFile("polls", dbmodels.RoomArtifactTypePollResults, roomId, roomSid, roomTableId, "csv", "text/csv", csvContent, &jsonArtifact.ArtifactId, log); err != nil {
+		log.WithError(err).Errorln("failed to create csv poll results artifact")
+	}
+}
+
+// renderPollResultsCSV writes one "option" row per option with the vote count
+// & one "response" row per selected option or answer of the respondent.
+func renderPollResultsCSV(polls []*PollResultsInfo) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"record_type", "poll_id", "poll_type", "question", "option_id", "option_text", "vote_count", "user_id", "user_name", "answer", "is_correct"})
+
+	for _, p := range polls {
+		optionsText := make(map[uint64]string, len(p.Options))
+		for _, o := range p.Options {
+			optionsText[o.Id] = o.Text
+			_ = w.Write(escapeCSVFormulas([]string{"option", p.PollId, p.Type, p.Question, strconv.FormatUint(o.Id, 10), o.Text, strconv.FormatUint(o.VoteCount, 10), "", "", "", ""}))
+		}
+		for _, r := range p.Respondents {
+			isCorrect := ""
+			if r.IsCorrect != nil {
+				isCorrect = strconv.FormatBool(*r.IsCorrect)
+			}
+			if len(r.SelectedOptions) == 0 {
+				_ = w.Write(escapeCSVFormulas([]string{"response", p.PollId, p.Type, p.Question, "", "", "", r.UserId, r.Name, r.Answer, isCorrect}))
+				continue
+			}
+			for _, o := range r.SelectedOptions {
+				_ = w.Write(escapeCSVFormulas([]string{"response", p.PollId, p.Type, p.Question, strconv.FormatUint(o, 10), optionsText[o], "", r.UserId, r.Name, r.Answer, isCorrect}))
+			}
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
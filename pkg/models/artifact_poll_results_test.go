@@ -0,0 +1,41 @@
+package models
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestRenderPollResultsCSVEscapesFormulas(t *testing.T) {
+	polls := []*PollResultsInfo{
+		{
+			PollId:   "poll01",
+			Type:     "text",
+			Question: "=HYPERLINK(\"https://example.com\")",
+			Respondents: []*PollResultsRespondent{
+				{UserId: "user01", Name: "@admin", Answer: "-2+3"},
+				{UserId: "user02", Name: "User", Answer: "fine"},
+			},
+		},
+	}
+
+	content, err := renderPollResultsCSV(polls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(string(content))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(rows))
+	}
+
+	first := rows[1]
+	if first[3] != "'=HYPERLINK(\"https://example.com\")" || first[8] != "'@admin" || first[9] != "'-2+3" {
+		t.Errorf("formulas are not escaped: %v", first)
+	}
+	if second := rows[2]; second[8] != "User" || second[9] != "fine" {
+		t.Errorf("normal cells must not be changed: %v", second)
+	}
+}
@@ -10,6 +10,9 @@ import (
 	"time"
 
 	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/mynaparrot/plugnmeet-server/pkg/helpers"
 	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
 	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
 	"github.com/sirupsen/logrus"
@@ -176,3 +179,34 @@ func (m *ArtifactModel) createSpeechTranscriptionFileArtifact(roomId, roomSid st
 
 	return &fileArtifact.ArtifactId, nil
 }
+
+// CreateUploadedTranscriptionArtifact stores an externally created VTT file,
+// e.g. uploaded using BBB putRecordingTextTrack, as speech transcription artifact of the session.
+func (m *ArtifactModel) CreateUploadedTranscriptionArtifact(roomSid, lang string, content []byte, log *logrus.Entry) (*dbmodels.RoomArtifact, error) {
+	roomInfo, err := m.ds.GetRoomInfoBySid(roomSid, nil)
+	if err != nil {
+		return nil, err
+	}
+	if roomInfo == nil {
+		return nil, config.ErrRoomNotFound
+	}
+
+	fileName := fmt.Sprintf("transcription_%s-%s-%d.vtt", roomSid, helpers.MakeSafeFilename(lang, false), time.Now().UnixMilli())
+	relativePath, absolutePath, err := m.buildPath(fileName, roomInfo.RoomId, plugnmeet.RoomArtifactType_SPEECH_TRANSCRIPTION)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(absolutePath, content, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write transcription file: %w", err)
+	}
+
+	metadata := &plugnmeet.RoomArtifactMetadata{
+		FileInfo: &plugnmeet.RoomArtifactFileInfo{
+			FilePath: relativePath,
+			FileSize: int64(len(content)),
+			MimeType: "text/vtt",
+		},
+	}
+
+	return m.createAndSaveArtifact(roomInfo.RoomId, roomSid, roomInfo.ID, plugnmeet.RoomArtifactType_SPEECH_TRANSCRIPTION, metadata, true, log)
+}
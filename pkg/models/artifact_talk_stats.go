@@ -0,0 +1,69 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/sirupsen/logrus"
+)
+
+type talkStatsExport struct {
+	RoomId       string           `json:"room_id"`
+	RoomSid      string           `json:"room_sid"`
+	Participants []*TalkStatsInfo `json:"participants"`
+	Speakers     []*TalkStatsInfo `json:"speakers"`
+}
+
+// CreateTalkStatsArtifact stores the talk-time statistics as JSON & CSV artifacts.
+func (m *ArtifactModel) CreateTalkStatsArtifact(roomId, roomSid string, roomTableId uint64, speakers, participants []*TalkStatsInfo, log *logrus.Entry) {
+	log = log.WithField("method", "CreateTalkStatsArtifact")
+
+	content, err := json.MarshalIndent(&talkStatsExport{
+		RoomId:       roomId,
+		RoomSid:      roomSid,
+		Participants: participants,
+		Speakers:     speakers,
+	}, "", "  ")
+	if err != nil {
+		log.WithError(err).Errorln("failed to marshal talk stats")
+		return
+	}
+	jsonArtifact, err := m.saveArtifactFile("talk_stats", dbmodels.RoomArtifactTypeTalkTimeStats, roomId, roomSid, roomTableId, "json", "application/json", content, nil, log)
+	if err != nil {
+		log.WithError(err).Errorln("failed to create talk stats artifact")
+		return
+	}
+
+	// spreadsheet friendly version, linked with the JSON one
+	csvContent, err := renderTalkStatsCSV(speakers)
+	if err != nil {
+		log.WithError(err).Errorln("failed to render talk stats csv")
+		return
+	}
+	if _, err = m.saveArtifactFile("talk_stats", dbmodels.RoomArtifactTypeTalkTimeStats, roomId, roomSid, roomTableId, "csv", "text/csv", csvContent, &jsonArtifact.ArtifactId, log); err != nil {
+		log.WithError(err).Errorln("failed to create csv talk stats artifact")
+	}
+}
+
+// renderTalkStatsCSV writes one row per speaker, the names are entered by the users
+func renderTalkStatsCSV(speakers []*TalkStatsInfo) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"user_id", "name", "speaker_label", "talk_time_sec", "talk_time_percent", "words", "words_per_minute", "utterances", "interruptions"})
+
+	formatFloat := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	for _, s := range speakers {
+		_ = w.Write(escapeCSVFormulas([]string{s.UserId, s.Name, s.SpeakerLabel, formatFloat(s.TalkTimeSec), formatFloat(s.TalkTimePercent), strconv.FormatInt(s.Words, 10), formatFloat(s.WordsPerMinute), strconv.FormatInt(s.Utterances, 10), strconv.FormatInt(s.Interruptions, 10)}))
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
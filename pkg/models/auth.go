@@ -37,11 +37,21 @@ func NewAuthModel(args AuthModelArgs) *AuthModel {
 }
 
 func (m *AuthModel) GeneratePNMJoinToken(c *plugnmeet.PlugNmeetTokenClaims) (string, error) {
-	return auth.GeneratePlugNmeetJWTAccessToken(m.app.Client.ApiKey, m.app.Client.Secret, c.UserId, *m.app.Client.TokenValidity, c)
+	return auth.GeneratePlugNmeetJWTAccessToken(m.app.Client.ApiKey, m.natsService.GetClientSecret(), c.UserId, *m.app.Client.TokenValidity, c)
 }
 
+// VerifyPlugNmeetAccessToken verifies the token with all the valid secrets,
+// so tokens issued before a secret rotation will keep working during the grace period
 func (m *AuthModel) VerifyPlugNmeetAccessToken(token string, gracefulPeriod time.Duration) (*plugnmeet.PlugNmeetTokenClaims, error) {
-	return auth.VerifyPlugNmeetAccessToken(m.app.Client.ApiKey, m.app.Client.Secret, token, gracefulPeriod)
+	var claims *plugnmeet.PlugNmeetTokenClaims
+	err := verifyWithSecrets(m.natsService.GetClientSecrets(), func(secret string) (err error) {
+		claims, err = auth.VerifyPlugNmeetAccessToken(m.app.Client.ApiKey, secret, token, gracefulPeriod)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
 }
 
 func (m *AuthModel) UnsafeClaimsWithoutVerification(token string) (*plugnmeet.PlugNmeetTokenClaims, error) {
@@ -74,7 +84,20 @@ func (m *AuthModel) RenewPNMToken(token string, gracefulPeriod time.Duration) (s
 		return "", errors.New("user not found")
 	}
 
-	return auth.GeneratePlugNmeetJWTAccessToken(m.app.Client.ApiKey, m.app.Client.Secret, claims.UserId, *m.app.Client.TokenValidity, claims)
+	return auth.GeneratePlugNmeetJWTAccessToken(m.app.Client.ApiKey, m.natsService.GetClientSecret(), claims.UserId, *m.app.Client.TokenValidity, claims)
+}
+
+// verifyWithSecrets calls verify with each of the secrets till the signature matches.
+// Any error other than signature mismatch, e.g. token expired, will be returned immediately.
+func verifyWithSecrets(secrets []string, verify func(secret string) error) error {
+	var err error
+	for _, secret := range secrets {
+		err = verify(secret)
+		if err == nil || !errors.Is(err, jose.ErrCryptoFailure) {
+			return err
+		}
+	}
+	return err
 }
 
 func (m *AuthModel) ValidateLivekitWebhookToken(body []byte, token string) (bool, error) {
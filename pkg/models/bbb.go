@@ -2,35 +2,49 @@ package models
 
 import (
 	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/helpers"
 	"github.com/mynaparrot/plugnmeet-server/pkg/services/db"
+	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
 	"github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
 	"github.com/sirupsen/logrus"
 	"go.uber.org/fx"
 )
 
 type BBBApiWrapperModel struct {
-	app    *config.AppConfig
-	ds     *dbservice.DatabaseService
-	rs     *redisservice.RedisService
-	rrm    *RecordingModel
-	logger *logrus.Entry
+	app             *config.AppConfig
+	ds              *dbservice.DatabaseService
+	rs              *redisservice.RedisService
+	natsService     *natsservice.NatsService
+	rrm             *RecordingModel
+	fileModel       *FileModel
+	artifactModel   *ArtifactModel
+	webhookNotifier *helpers.WebhookNotifier
+	logger          *logrus.Entry
 }
 
 type BBBApiWrapperModelArgs struct {
 	fx.In
-	App    *config.AppConfig
-	Ds     *dbservice.DatabaseService
-	Rs     *redisservice.RedisService
-	Rrm    *RecordingModel
-	Logger *logrus.Logger
+	App             *config.AppConfig
+	Ds              *dbservice.DatabaseService
+	Rs              *redisservice.RedisService
+	NatsService     *natsservice.NatsService
+	Rrm             *RecordingModel
+	FileModel       *FileModel
+	ArtifactModel   *ArtifactModel
+	WebhookNotifier *helpers.WebhookNotifier
+	Logger          *logrus.Logger
 }
 
 func NewBBBApiWrapperModel(args BBBApiWrapperModelArgs) *BBBApiWrapperModel {
 	return &BBBApiWrapperModel{
-		app:    args.App,
-		ds:     args.Ds,
-		rs:     args.Rs,
-		rrm:    args.Rrm,
-		logger: args.Logger.WithField("model", "bbb"),
+		app:             args.App,
+		ds:              args.Ds,
+		rs:              args.Rs,
+		natsService:     args.NatsService,
+		rrm:             args.Rrm,
+		fileModel:       args.FileModel,
+		artifactModel:   args.ArtifactModel,
+		webhookNotifier: args.WebhookNotifier,
+		logger:          args.Logger.WithField("model", "bbb"),
 	}
 }
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+
+	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
+	"github.com/sirupsen/logrus"
+)
+
+// BBBInsertDocumentReq is the xml body of insertDocument,
+// it uses the same format as pre-upload slides of create.
+type BBBInsertDocumentReq struct {
+	XMLName xml.Name `xml:"modules"`
+	Modules []struct {
+		Name      string         `xml:"name,attr"`
+		Documents []*BBBDocument `xml:"document"`
+	} `xml:"module"`
+}
+
+// BBBDocument either has url to download from or base64 encoded content with name
+type BBBDocument struct {
+	Url      string `xml:"url,attr"`
+	FileName string `xml:"filename,attr"`
+	Name     string `xml:"name,attr"`
+	Content  string `xml:",chardata"`
+}
+
+// GetDocuments returns all the valid documents of the request
+func (r *BBBInsertDocumentReq) GetDocuments() []*BBBDocument {
+	var docs []*BBBDocument
+	for _, module := range r.Modules {
+		for _, doc := range module.Documents {
+			if doc.Url != "" {
+				if _, err := url.ParseRequestURI(doc.Url); err != nil {
+					continue
+				}
+				docs = append(docs, doc)
+			} else if doc.Content != "" && (doc.Name != "" || doc.FileName != "") {
+				docs = append(docs, doc)
+			}
+		}
+	}
+	return docs
+}
+
+// InsertDocuments adds the documents to the whiteboard of a running meeting.
+// Like BBB, conversion happens in background, the documents will be processed one after another.
+func (m *BBBApiWrapperModel) InsertDocuments(roomId string, docs []*BBBDocument) error {
+	if len(docs) == 0 {
+		return fmt.Errorf("no valid document found")
+	}
+
+	info, err := m.natsService.GetRoomInfo(roomId)
+	if err != nil {
+		return err
+	}
+	if info == nil || (info.Status != natsservice.RoomStatusCreated && info.Status != natsservice.RoomStatusActive) {
+		return fmt.Errorf("meeting is not active")
+	}
+
+	log := m.logger.WithFields(logrus.Fields{
+		"roomId":  roomId,
+		"roomSid": info.RoomSid,
+		"method":  "InsertDocuments",
+	})
+	log.Infof("going to insert %d documents", len(docs))
+
+	go func() {
+		maxSize := m.app.UploadFileSettings.MaxSizeWhiteboardFile * 1024 * 1024
+		for _, doc := range docs {
+			var err error
+			if doc.Url != "" {
+				_, err = m.fileModel.DownloadAndProcessWhiteboardFile(roomId, info.RoomSid, doc.Url, maxSize, nil, log.WithField("url", doc.Url))
+			} else {
+				fileName := doc.Name
+				if fileName == "" {
+					fileName = doc.FileName
+				}
+				_, err = m.fileModel.ProcessBase64WhiteboardFile(roomId, info.RoomSid, fileName, doc.Content, log)
+			}
+			if err != nil {
+				log.WithError(err).Errorln("failed to insert document")
+				if notifyErr := m.natsService.NotifyErrorMsg(roomId, "notifications.preloaded-whiteboard-file-processing-error", nil); notifyErr != nil {
+					log.WithError(notifyErr).Error("failed to send notification for whiteboard processing error")
+				}
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/mynaparrot/plugnmeet-protocol/bbbapiwrapper"
+	"github.com/mynaparrot/plugnmeet-server/pkg/helpers"
+)
+
+type BBBHookInfo struct {
+	HookID        string `xml:"hookID"`
+	CallbackURL   string `xml:"callbackURL"`
+	MeetingID     string `xml:"meetingID,omitempty"`
+	PermanentHook bool   `xml:"permanentHook"`
+	RawData       bool   `xml:"rawData"`
+}
+
+type BBBHookCreateRes struct {
+	XMLName       xml.Name `xml:"response"`
+	ReturnCode    string   `xml:"returncode"`
+	HookID        string   `xml:"hookID"`
+	PermanentHook bool     `xml:"permanentHook"`
+	RawData       bool     `xml:"rawData"`
+}
+
+type BBBHookDestroyRes struct {
+	XMLName    xml.Name `xml:"response"`
+	ReturnCode string   `xml:"returncode"`
+	Removed    bool     `xml:"removed"`
+}
+
+type BBBHookListRes struct {
+	XMLName    xml.Name `xml:"response"`
+	ReturnCode string   `xml:"returncode"`
+	Hooks      struct {
+		Hooks []*BBBHookInfo `xml:"hook"`
+	} `xml:"hooks"`
+}
+
+// CreateHook registers the callback url as per-meeting webhook of the meeting.
+// Global hooks aren't supported, use webhook_conf of the config file instead.
+func (m *BBBApiWrapperModel) CreateHook(tenantId, meetingId, callbackUrl string, rawData bool) (*BBBHookInfo, error) {
+	if meetingId == "" {
+		return nil, fmt.Errorf("global hooks are not supported, meetingID is required")
+	}
+	if err := m.webhookNotifier.ValidateWebhookUrl(callbackUrl); err != nil {
+		return nil, fmt.Errorf("invalid callbackURL: %w", err)
+	}
+
+	hook, err := m.webhookNotifier.AddBBBHook(tenantId, bbbapiwrapper.CheckMeetingIdToMatchFormat(meetingId), meetingId, callbackUrl, rawData)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertToBBBHookInfo(hook), nil
+}
+
+// DestroyHook removes a hook
+func (m *BBBApiWrapperModel) DestroyHook(tenantId, hookId string) error {
+	return m.webhookNotifier.RemoveBBBHook(tenantId, hookId)
+}
+
+// ListHooks returns the hooks of the meeting, or all the hooks of the tenant if meetingId is empty
+func (m *BBBApiWrapperModel) ListHooks(tenantId, meetingId string) ([]*BBBHookInfo, error) {
+	var roomId string
+	if meetingId != "" {
+		roomId = bbbapiwrapper.CheckMeetingIdToMatchFormat(meetingId)
+	}
+
+	hooks, err := m.webhookNotifier.ListBBBHooks(tenantId, roomId)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*BBBHookInfo, 0, len(hooks))
+	for _, h := range hooks {
+		list = append(list, convertToBBBHookInfo(h))
+	}
+	return list, nil
+}
+
+func convertToBBBHookInfo(hook *helpers.BBBHook) *BBBHookInfo {
+	return &BBBHookInfo{
+		HookID:      hook.HookId,
+		CallbackURL: hook.CallbackUrl,
+		MeetingID:   hook.MeetingId,
+		RawData:     hook.RawData,
+	}
+}
@@ -7,9 +7,10 @@ import (
 	"time"
 
 	"github.com/mynaparrot/plugnmeet-protocol/bbbapiwrapper"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
 )
 
-func (m *BBBApiWrapperModel) GetRecordings(host string, r *bbbapiwrapper.GetRecordingsReq) ([]*bbbapiwrapper.RecordingInfo, *bbbapiwrapper.Pagination, error) {
+func (m *BBBApiWrapperModel) GetRecordings(host, tenantId string, r *bbbapiwrapper.GetRecordingsReq) ([]*bbbapiwrapper.RecordingInfo, *bbbapiwrapper.Pagination, error) {
 	oriIds := make(map[string]string)
 	if r.Limit == 0 {
 		// let's make it 50 for BBB as not all plugin still support pagination
@@ -27,11 +28,21 @@ func (m *BBBApiWrapperModel) GetRecordings(host string, r *bbbapiwrapper.GetReco
 		}
 	}
 
-	data, total, err := m.ds.GetRecordingsForBBB(rIds, mIds, r.Offset, r.Limit)
+	data, total, err := m.ds.GetRecordingsForBBB(tenantId, rIds, mIds, r.Offset, r.Limit)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	recordIds := make([]string, 0, len(data))
+	for _, v := range data {
+		recordIds = append(recordIds, v.RecordID)
+	}
+	renditions, err := m.ds.GetCompletedRecordingRenditions(recordIds)
+	if err != nil {
+		// recordings are still playable without those
+		m.logger.WithError(err).Errorln("failed to get recording renditions")
+	}
+
 	var recordings []*bbbapiwrapper.RecordingInfo
 	for _, v := range data {
 		recording := &bbbapiwrapper.RecordingInfo{
@@ -48,7 +59,7 @@ func (m *BBBApiWrapperModel) GetRecordings(host string, r *bbbapiwrapper.GetReco
 		}
 
 		// for path, let's create a download link directly
-		url, err := m.createPlayBackURL(host, v.FilePath)
+		url, err := m.createPlayBackURL(host, v.RecordID, v.FilePath)
 		if err != nil {
 			m.logger.Errorln(err)
 			continue
@@ -59,6 +70,7 @@ func (m *BBBApiWrapperModel) GetRecordings(host string, r *bbbapiwrapper.GetReco
 				URL:  url,
 			},
 		}
+		recording.Playback.PlayBackFormat = append(recording.Playback.PlayBackFormat, m.createRenditionPlayBackFormats(host, renditions[v.RecordID])...)
 
 		if mInfo, err := m.ds.GetRoomInfoBySid(v.RoomSid.String, nil); err == nil && mInfo != nil {
 			recording.Name = mInfo.RoomTitle
@@ -91,8 +103,38 @@ func (m *BBBApiWrapperModel) GetRecordings(host string, r *bbbapiwrapper.GetReco
 	return recordings, pagination, nil
 }
 
-func (m *BBBApiWrapperModel) createPlayBackURL(host, path string) (string, error) {
-	token, err := m.rrm.CreateTokenForDownload(path)
+// createRenditionPlayBackFormats adds audio only rendition as podcast & HLS package as hls format
+func (m *BBBApiWrapperModel) createRenditionPlayBackFormats(host string, renditions []dbmodels.RecordingRendition) []bbbapiwrapper.PlayBackFormat {
+	var formats []bbbapiwrapper.PlayBackFormat
+	for _, rr := range renditions {
+		switch rr.Type {
+		case dbmodels.RecordingRenditionTypeAudio:
+			url, err := m.createPlayBackURL(host, rr.RecordID, rr.FilePath)
+			if err != nil {
+				m.logger.Errorln(err)
+				continue
+			}
+			formats = append(formats, bbbapiwrapper.PlayBackFormat{
+				Type: "podcast",
+				URL:  url,
+			})
+		case dbmodels.RecordingRenditionTypeHls:
+			p, err := m.rrm.CreateHlsPlaybackPath(rr.RecordID, rr.FilePath, nil)
+			if err != nil {
+				m.logger.Errorln(err)
+				continue
+			}
+			formats = append(formats, bbbapiwrapper.PlayBackFormat{
+				Type: "hls",
+				URL:  host + p,
+			})
+		}
+	}
+	return formats
+}
+
+func (m *BBBApiWrapperModel) createPlayBackURL(host, recordId, path string) (string, error) {
+	token, err := m.rrm.CreateTokenForDownload(recordId, path, nil)
 	if err != nil {
 		return "", err
 	}
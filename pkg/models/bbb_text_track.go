@@ -0,0 +1,172 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// subtitles of recording metadata having this prefix in url are stored as artifact
+	bbbTextTrackArtifactPrefix = "artifact:"
+	bbbMaxTextTrackSize        = 10 * 1024 * 1024
+)
+
+var bbbTextTrackLangRegex = regexp.MustCompile(`^[a-zA-Z]{2,3}([_-][a-zA-Z0-9]{2,8})*$`)
+
+// BBBTextTrack is a caption or subtitle of a recording in BBB format
+type BBBTextTrack struct {
+	Href   string `json:"href"`
+	Kind   string `json:"kind"`
+	Label  string `json:"label"`
+	Lang   string `json:"lang"`
+	Source string `json:"source"`
+}
+
+// PutRecordingTextTrackReq holds the parameters of putRecordingTextTrack
+type PutRecordingTextTrackReq struct {
+	RecordID string `query:"recordID" form:"recordID"`
+	Kind     string `query:"kind" form:"kind"`
+	Lang     string `query:"lang" form:"lang"`
+	Label    string `query:"label" form:"label"`
+}
+
+// GetRecordingTextTracks returns the subtitles of the recording
+// along with the speech transcriptions of the session.
+func (m *BBBApiWrapperModel) GetRecordingTextTracks(host, recordId string) ([]*BBBTextTrack, error) {
+	recording, err := m.rrm.FetchRecording(recordId)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks := make([]*BBBTextTrack, 0)
+	usedArtifacts := make(map[string]bool)
+	for lang, sub := range recording.GetMetadata().GetSubtitles() {
+		if sub == nil || sub.Url == "" {
+			continue
+		}
+		href := sub.Url
+		if artifactId, ok := strings.CutPrefix(sub.Url, bbbTextTrackArtifactPrefix); ok {
+			usedArtifacts[artifactId] = true
+			href, err = m.createArtifactDownloadURL(host, artifactId)
+			if err != nil {
+				m.logger.WithError(err).Errorln("failed to create text track url")
+				continue
+			}
+		}
+		tracks = append(tracks, &BBBTextTrack{
+			Href:   href,
+			Kind:   "subtitles",
+			Label:  sub.Label,
+			Lang:   lang,
+			Source: "upload",
+		})
+	}
+
+	if recording.RoomSid == "" {
+		return tracks, nil
+	}
+	artifactType := plugnmeet.RoomArtifactType_SPEECH_TRANSCRIPTION
+	artifacts, _, err := m.ds.GetArtifacts("", nil, &recording.RoomSid, &artifactType, 0, 100, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range artifacts {
+		if usedArtifacts[a.ArtifactId] {
+			continue
+		}
+		href, err := m.createArtifactDownloadURL(host, a.ArtifactId)
+		if err != nil {
+			m.logger.WithError(err).Errorln("failed to create text track url")
+			continue
+		}
+		tracks = append(tracks, &BBBTextTrack{
+			Href:   href,
+			Kind:   "captions",
+			Label:  "Transcription",
+			Lang:   "und",
+			Source: "live",
+		})
+	}
+
+	return tracks, nil
+}
+
+// PutRecordingTextTrack stores the uploaded WebVTT file as artifact
+// & adds it to the subtitles of the recording.
+func (m *BBBApiWrapperModel) PutRecordingTextTrack(req *PutRecordingTextTrackReq, content []byte) error {
+	log := m.logger.WithFields(logrus.Fields{
+		"recordId": req.RecordID,
+		"lang":     req.Lang,
+		"method":   "PutRecordingTextTrack",
+	})
+
+	if req.Kind != "subtitles" && req.Kind != "captions" {
+		return fmt.Errorf("invalid kind parameter, expected 'subtitles' or 'captions'")
+	}
+	if !bbbTextTrackLangRegex.MatchString(req.Lang) {
+		return fmt.Errorf("malformed lang param")
+	}
+	if len(content) > bbbMaxTextTrackSize {
+		return fmt.Errorf("file too large")
+	}
+	if !bytes.HasPrefix(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")), []byte("WEBVTT")) {
+		return fmt.Errorf("only WebVTT files are supported")
+	}
+
+	recording, err := m.rrm.FetchRecording(req.RecordID)
+	if err != nil {
+		return err
+	}
+
+	// previous upload of the same language will be replaced
+	previousArtifactId, replace := strings.CutPrefix(recording.GetMetadata().GetSubtitles()[req.Lang].GetUrl(), bbbTextTrackArtifactPrefix)
+
+	artifact, err := m.artifactModel.CreateUploadedTranscriptionArtifact(recording.RoomSid, req.Lang, content, log)
+	if err != nil {
+		return err
+	}
+
+	label := req.Label
+	if label == "" {
+		label = req.Lang
+	}
+	err = m.rrm.UpdateRecordingMetadata(&plugnmeet.UpdateRecordingMetadataReq{
+		RecordId: req.RecordID,
+		Metadata: &plugnmeet.RecordingMetadata{
+			Subtitles: map[string]*plugnmeet.RecordingSubtitle{
+				req.Lang: {
+					Label: label,
+					Url:   bbbTextTrackArtifactPrefix + artifact.ArtifactId,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if replace && previousArtifactId != "" {
+		if err := m.artifactModel.DeleteArtifact(&plugnmeet.DeleteArtifactReq{ArtifactId: previousArtifactId}); err != nil {
+			log.WithError(err).Warnln("failed to delete previous text track")
+		}
+	}
+
+	log.WithField("artifactId", artifact.ArtifactId).Infoln("text track uploaded successfully")
+	return nil
+}
+
+func (m *BBBApiWrapperModel) createArtifactDownloadURL(host, artifactId string) (string, error) {
+	token, err := m.artifactModel.GetArtifactDownloadToken(&plugnmeet.GetArtifactDownloadTokenReq{
+		ArtifactId: artifactId,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/download/artifact/%s", host, token), nil
+}
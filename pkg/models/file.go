@@ -9,34 +9,38 @@ import (
 	"strings"
 
 	"github.com/gabriel-vasile/mimetype"
+	"github.com/mynaparrot/plugnmeet-protocol/hooks"
 	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
 	"github.com/mynaparrot/plugnmeet-server/pkg/config"
 	"github.com/mynaparrot/plugnmeet-server/pkg/services/db"
 	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
 	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+	storageservice "github.com/mynaparrot/plugnmeet-server/pkg/services/storage"
 	"github.com/sirupsen/logrus"
 	"go.uber.org/fx"
 )
 
 type FileModel struct {
-	ctx          context.Context
-	app          *config.AppConfig
-	ds           *dbservice.DatabaseService
-	natsService  *natsservice.NatsService
-	redisService *redisservice.RedisService
-	userModel    *UserModel
-	logger       *logrus.Entry
+	ctx            context.Context
+	app            *config.AppConfig
+	ds             *dbservice.DatabaseService
+	natsService    *natsservice.NatsService
+	redisService   *redisservice.RedisService
+	storageService *storageservice.StorageService
+	userModel      *UserModel
+	logger         *logrus.Entry
 }
 
 type FileModelArgs struct {
 	fx.In
-	Ctx         context.Context
-	App         *config.AppConfig
-	Ds          *dbservice.DatabaseService
-	NatsService *natsservice.NatsService
-	Rs          *redisservice.RedisService
-	Um          *UserModel
-	Logger      *logrus.Logger
+	Ctx            context.Context
+	App            *config.AppConfig
+	Ds             *dbservice.DatabaseService
+	NatsService    *natsservice.NatsService
+	Rs             *redisservice.RedisService
+	StorageService *storageservice.StorageService
+	Um             *UserModel
+	Logger         *logrus.Logger
 }
 
 func NewFileModel(args FileModelArgs) (*FileModel, error) {
@@ -46,13 +50,14 @@ func NewFileModel(args FileModelArgs) (*FileModel, error) {
 	}
 
 	return &FileModel{
-		ctx:          args.Ctx,
-		app:          args.App,
-		ds:           args.Ds,
-		natsService:  args.NatsService,
-		redisService: args.Rs,
-		userModel:    args.Um,
-		logger:       args.Logger.WithField("model", "file"),
+		ctx:            args.Ctx,
+		app:            args.App,
+		ds:             args.Ds,
+		natsService:    args.NatsService,
+		redisService:   args.Rs,
+		storageService: args.StorageService,
+		userModel:      args.Um,
+		logger:         args.Logger.WithField("model", "file"),
 	}, nil
 }
 
@@ -143,6 +148,26 @@ func (m *FileModel) DeleteRoomUploadedDir(roomSid string) error {
 	return err
 }
 
+// GetStoredFileDownloadInfo returns the presigned url of an uploaded file
+// it will return nil if local storage is in use
+func (m *FileModel) GetStoredFileDownloadInfo(ctx context.Context, relativePath string) (*hooks.DownloadHookData, error) {
+	if m.storageService.IsLocal() {
+		return nil, nil
+	}
+	return m.storageService.GetDownloadHookData(ctx, m.storageService.Uploads(), relativePath)
+}
+
+// DeleteRoomStoredFiles removes the uploaded & converted files of the session from remote storage.
+// Local files will be removed by DeleteRoomUploadedDir.
+func (m *FileModel) DeleteRoomStoredFiles(roomSid string) {
+	if roomSid == "" || m.storageService.IsLocal() {
+		return
+	}
+	if err := m.storageService.Uploads().DeletePrefix(m.ctx, roomSid); err != nil {
+		m.logger.WithField("roomSid", roomSid).WithError(err).Errorln("can't delete room files from storage")
+	}
+}
+
 // checkDependencies verifies that required external tools are installed.
 func checkDependencies() error {
 	for _, bin := range []string{"mutool", "soffice", "img2pdf"} {
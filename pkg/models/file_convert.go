@@ -9,6 +9,7 @@ import (
 	_ "image/png"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strconv"
@@ -141,6 +142,14 @@ func (m *FileModel) processAndBroadcastWhiteboardFile(roomId, roomSid, filePath
 	if fullPath == "" {
 		// fallback to default
 		fullPath = filepath.Join(m.app.UploadFileSettings.Path, filePath)
+
+		// the file may be uploaded to storage by another node
+		if _, err := os.Stat(fullPath); os.IsNotExist(err) && !m.storageService.IsLocal() {
+			if err := m.storageService.GetFile(m.ctx, m.storageService.Uploads(), filePath, fullPath); err != nil {
+				log.WithError(err).Error("failed to fetch file from storage")
+				return nil, fmt.Errorf("failed to fetch file from storage")
+			}
+		}
 	}
 
 	fileName := filepath.Base(fullPath)
@@ -199,6 +208,14 @@ func (m *FileModel) processAndBroadcastWhiteboardFile(roomId, roomSid, filePath
 		return nil, fmt.Errorf("failed to write page meta files")
 	}
 
+	// With remote storage, other nodes will serve the pages from the storage.
+	if !m.storageService.IsLocal() {
+		if err := m.uploadConvertedFiles(outputDir, roomSid, fileId); err != nil {
+			log.WithError(err).Error("failed to upload converted images to storage")
+			return nil, fmt.Errorf("failed to upload converted images to storage")
+		}
+	}
+
 	// If hooks are enabled, upload the entire directory of converted images
 	// (page_N.png + page_N_meta.json).
 	if m.app.Hooks != nil {
@@ -642,3 +659,21 @@ func getFileExtension(mime *mimetype.MIME) string {
 	}
 	return ""
 }
+
+// uploadConvertedFiles puts the pages & their meta files under roomSid/fileId of the storage
+func (m *FileModel) uploadConvertedFiles(outputDir, roomSid, fileId string) error {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		key := path.Join(roomSid, fileId, e.Name())
+		if err := m.storageService.PutFile(m.ctx, m.storageService.Uploads(), key, filepath.Join(outputDir, e.Name()), ""); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", e.Name(), err)
+		}
+	}
+	return nil
+}
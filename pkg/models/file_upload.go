@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -90,6 +91,16 @@ func (m *FileModel) ResumableFileUpload(c fiber.Ctx, req *ResumableUploadReq) (*
 					return nil, fiber.NewError(fiber.StatusNoContent, "OK to upload")
 				}
 			}
+			// For remote storage, chunks may be uploaded to another node.
+			if !m.storageService.IsLocal() {
+				key := m.resumableChunkKey(req.RoomSid, safeIdentifier, req.ResumableChunkNumber)
+				info, err := m.storageService.Uploads().Stat(c.RequestCtx(), key)
+				if err != nil || info.Size != req.ResumableCurrentChunkSize {
+					return nil, fiber.NewError(fiber.StatusNoContent, "OK to upload")
+				}
+				res.Msg = "skipping upload as previously uploaded chunk"
+				return res, fiber.NewError(fiber.StatusCreated, "skipping upload as previously uploaded chunk")
+			}
 			// Original logic if no hook is configured.
 			stat, err := os.Stat(chunkPath)
 			if os.IsNotExist(err) {
@@ -162,6 +173,14 @@ func (m *FileModel) ResumableFileUpload(c fiber.Ctx, req *ResumableUploadReq) (*
 					log.WithError(err).Error("resumable upload hook 'part-upload' failed")
 					return nil, fiber.NewError(fiber.StatusServiceUnavailable, "hook failed to upload part")
 				}
+			} else if !m.storageService.IsLocal() {
+				// keep the chunk in storage, so that merge request can be handled by any node
+				key := m.resumableChunkKey(req.RoomSid, safeIdentifier, req.ResumableChunkNumber)
+				if err := m.storageService.PutFile(c.RequestCtx(), m.storageService.Uploads(), key, chunkPath, ""); err != nil {
+					log.WithError(err).Errorln("failed to upload chunk to storage")
+					return nil, fiber.NewError(fiber.StatusServiceUnavailable, "failed to write chunk data")
+				}
+				_ = os.Remove(chunkPath)
 			}
 
 			res.FilePath = "part_uploaded"
@@ -219,6 +238,13 @@ func (m *FileModel) UploadedFileMerge(req *plugnmeet.UploadedFileMergeReq) (*plu
 		tempFolder := filepath.Join(m.app.UploadFileSettings.Path, req.RoomSid, config.UploadFileTempDir)
 		chunkDir := filepath.Join(tempFolder, req.ResumableIdentifier)
 
+		if !m.storageService.IsLocal() {
+			if err := m.fetchResumableChunks(req, chunkDir); err != nil {
+				log.WithError(err).Error("failed to fetch chunks from storage")
+				return nil, fmt.Errorf("requested file's chunks not found for identifier %s, make sure those were uploaded", req.ResumableIdentifier)
+			}
+		}
+
 		if _, err := os.Stat(chunkDir); os.IsNotExist(err) {
 			return nil, fmt.Errorf("requested file's chunks not found for identifier %s, make sure those were uploaded", req.ResumableIdentifier)
 		}
@@ -255,6 +281,15 @@ func (m *FileModel) UploadedFileMerge(req *plugnmeet.UploadedFileMergeReq) (*plu
 		finalPath = filepath.Join(req.RoomSid, safeFilename)
 		fileMimeType = mType.String()
 		fileExtension = strings.Replace(mType.Extension(), ".", "", 1)
+
+		if !m.storageService.IsLocal() {
+			// we'll keep the local copy too, as it may be needed for conversion
+			if err := m.storageService.PutFile(m.ctx, m.storageService.Uploads(), finalPath, combinedFile, fileMimeType); err != nil {
+				log.WithError(err).Error("failed to upload file to storage")
+				return nil, fmt.Errorf("failed to upload file to storage")
+			}
+			m.deleteResumableChunks(req, log)
+		}
 	}
 
 	// Common logic for creating metadata and response
@@ -293,6 +328,35 @@ func (m *FileModel) UploadedFileMerge(req *plugnmeet.UploadedFileMergeReq) (*plu
 	return res, nil
 }
 
+// resumableChunkKey returns the storage key of a chunk, same layout as local temp dir
+func (m *FileModel) resumableChunkKey(roomSid, identifier string, chunkNumber int) string {
+	return path.Join(roomSid, config.UploadFileTempDir, identifier, fmt.Sprintf("part%d", chunkNumber))
+}
+
+// fetchResumableChunks downloads the chunks which are not available locally
+func (m *FileModel) fetchResumableChunks(req *plugnmeet.UploadedFileMergeReq, chunkDir string) error {
+	for i := 1; i <= int(req.ResumableTotalChunks); i++ {
+		chunkPath := filepath.Join(chunkDir, fmt.Sprintf("part%d", i))
+		if _, err := os.Stat(chunkPath); err == nil {
+			continue
+		}
+		key := m.resumableChunkKey(req.RoomSid, req.ResumableIdentifier, i)
+		if err := m.storageService.GetFile(m.ctx, m.storageService.Uploads(), key, chunkPath); err != nil {
+			return fmt.Errorf("failed to fetch chunk %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (m *FileModel) deleteResumableChunks(req *plugnmeet.UploadedFileMergeReq, log *logrus.Entry) {
+	for i := 1; i <= int(req.ResumableTotalChunks); i++ {
+		key := m.resumableChunkKey(req.RoomSid, req.ResumableIdentifier, i)
+		if err := m.storageService.Uploads().Delete(m.ctx, key); err != nil {
+			log.WithError(err).Warnf("failed to delete chunk %d from storage", i)
+		}
+	}
+}
+
 func (m *FileModel) combineResumableFiles(req *plugnmeet.UploadedFileMergeReq, chunksDir, safeFilename string) (string, error) {
 	log := m.logger.WithFields(logrus.Fields{
 		"roomId":              req.RoomId,
@@ -76,3 +76,37 @@ func (m *FileModel) UploadBase64EncodedData(req *plugnmeet.UploadBase64EncodedDa
 		FileExtension: strings.TrimPrefix(mimeType.Extension(), "."),
 	}, nil
 }
+
+// ProcessBase64WhiteboardFile stores a base64 encoded office or pdf file of the room
+// and converts & broadcasts it as whiteboard file. This is a synchronous, long-running task.
+func (m *FileModel) ProcessBase64WhiteboardFile(roomId, roomSid, fileName, encoded string, log *logrus.Entry) (*ConvertWhiteboardFileRes, error) {
+	log = log.WithFields(logrus.Fields{
+		"fileName":   fileName,
+		"sub-method": "ProcessBase64WhiteboardFile",
+	})
+
+	// some clients break lines in the encoded content
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64 data: %w", err)
+	}
+
+	if uint64(len(data)) > m.app.UploadFileSettings.MaxSizeWhiteboardFile*1024*1024 {
+		return nil, fmt.Errorf("file too large: max allowed is %dMB", m.app.UploadFileSettings.MaxSizeWhiteboardFile)
+	}
+	if err := m.ValidateMimeType(mimetype.Detect(data)); err != nil {
+		return nil, err
+	}
+
+	safeFilename := helpers.MakeSafeFilename(fileName, true)
+	saveDir := filepath.Join(m.app.UploadFileSettings.Path, roomSid)
+	if err := os.MkdirAll(saveDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create file directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(saveDir, safeFilename), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	// ConvertAndBroadcastWhiteboardFile expect relative path
+	return m.ConvertAndBroadcastWhiteboardFile(m.ctx, roomId, roomSid, filepath.Join(roomSid, safeFilename), nil, nil, log)
+}
@@ -13,7 +13,9 @@ import (
 	"github.com/mynaparrot/plugnmeet-protocol/hooks"
 	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
 	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/helpers"
 	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
+	dbservice "github.com/mynaparrot/plugnmeet-server/pkg/services/db"
 	insightsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/insights"
 	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
 	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
@@ -47,36 +49,51 @@ type InsightsModel struct {
 	logger        *logrus.Entry
 	lock          sync.RWMutex
 	roomAgents    map[string]*insightsservice.RoomAgent // Maps a unique key (roomName@serviceName) to a dedicated agent
+	ds            *dbservice.DatabaseService
 	redisService  *redisservice.RedisService
 	natsService   *natsservice.NatsService
 	artifactModel *ArtifactModel
+	// used to send the insights_budget_exceeded event
+	webhookNotifier *helpers.WebhookNotifier
+	// used to add the talk-time statistics to the analytics
+	analyticsModel *AnalyticsModel
+	// budgets of the running rooms, see getRoomInsightsBudgets
+	budgetCacheLock sync.Mutex
+	budgetCache     map[string]*roomInsightsBudgets
 }
 
 type InsightsModelArgs struct {
 	fx.In
-	Ctx           context.Context
-	AppConfig     *config.AppConfig
-	RDS           *redis.Client
-	NatsConn      *nats.Conn
-	JS            jetstream.JetStream
-	RedisService  *redisservice.RedisService
-	NatsService   *natsservice.NatsService
-	ArtifactModel *ArtifactModel
-	Logger        *logrus.Logger
+	Ctx             context.Context
+	AppConfig       *config.AppConfig
+	RDS             *redis.Client
+	NatsConn        *nats.Conn
+	JS              jetstream.JetStream
+	Ds              *dbservice.DatabaseService
+	RedisService    *redisservice.RedisService
+	NatsService     *natsservice.NatsService
+	ArtifactModel   *ArtifactModel
+	AnalyticsModel  *AnalyticsModel
+	WebhookNotifier *helpers.WebhookNotifier
+	Logger          *logrus.Logger
 }
 
 func NewInsightsModel(args InsightsModelArgs) *InsightsModel {
 	return &InsightsModel{
-		ctx:           args.Ctx,
-		appConfig:     args.AppConfig,
-		rds:           args.RDS,
-		natsConn:      args.NatsConn,
-		js:            args.JS,
-		redisService:  args.RedisService,
-		natsService:   args.NatsService,
-		roomAgents:    make(map[string]*insightsservice.RoomAgent),
-		artifactModel: args.ArtifactModel,
-		logger:        args.Logger.WithField("model", "insights"),
+		ctx:             args.Ctx,
+		appConfig:       args.AppConfig,
+		rds:             args.RDS,
+		natsConn:        args.NatsConn,
+		js:              args.JS,
+		ds:              args.Ds,
+		redisService:    args.RedisService,
+		natsService:     args.NatsService,
+		roomAgents:      make(map[string]*insightsservice.RoomAgent),
+		budgetCache:     make(map[string]*roomInsightsBudgets),
+		artifactModel:   args.ArtifactModel,
+		analyticsModel:  args.AnalyticsModel,
+		webhookNotifier: args.WebhookNotifier,
+		logger:          args.Logger.WithField("model", "insights"),
 	}
 }
 
@@ -407,5 +424,10 @@ func (s *InsightsModel) OnAfterRoomEnded(dbTableId uint64, roomId, roomSid strin
 		log.WithError(err).Error("Error in agent task cleanup")
 	}
 
+	// needs the transcription history, so before the usage artifacts
+	s.finalizeLiveSummary(dbTableId, roomId, roomSid, log)
+	s.finalizeTalkStats(dbTableId, roomId, roomSid, log)
+	s.cleanupRoomInsightsBudget(roomId, log)
 	s.artifactModel.CreateAllRoomUsageArtifacts(roomId, roomSid, dbTableId, log)
+	s.redisService.DeleteLiveSummary(roomId)
 }
@@ -122,6 +122,7 @@ func (s *InsightsModel) AITextChatRequest(roomId, userId, prompt, streamId strin
 		if err != nil {
 			logger.WithError(err).Error("failed to update token usage")
 		}
+		s.evaluateRoomInsightsBudget(roomId)
 
 		// Trigger background summarization
 		s.CheckAndSummarize(s.ctx, roomId, userId)
@@ -254,6 +255,7 @@ func (s *InsightsModel) CheckAndSummarize(ctx context.Context, roomId, userId st
 	if err != nil {
 		logger.WithError(err).Error("failed to update token usage for summarization")
 	}
+	s.evaluateRoomInsightsBudget(roomId)
 
 	logger.Info("successfully created new summary")
 }
@@ -0,0 +1,592 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
+	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	insightsBudgetExceededEvent = "insights_budget_exceeded"
+
+	// keys of room metadata extra_data to lower the caps of the room budget,
+	// those can't raise a cap of the config or make it unlimited
+	insightsBudgetTranscriptionMinutesExtraDataKey = "insights_budget_transcription_minutes"
+	insightsBudgetTokensExtraDataKey               = "insights_budget_tokens"
+	insightsBudgetCharactersExtraDataKey           = "insights_budget_characters"
+	insightsBudgetCostExtraDataKey                 = "insights_budget_cost"
+
+	insightsBudgetScopeRoom   = "room"
+	insightsBudgetScopeTenant = "tenant"
+
+	insightsBudgetMetricTranscriptionMinutes = "transcription_minutes"
+	insightsBudgetMetricTokens               = "tokens"
+	insightsBudgetMetricCharacters           = "characters"
+	insightsBudgetMetricCost                 = "cost"
+
+	// insightsBudgetCacheTTL is how long the budgets of a room are kept in memory,
+	// changes of the tenant or the metadata of the room will be applied after that.
+	insightsBudgetCacheTTL = time.Minute
+)
+
+// roomInsightsBudgets are the budgets of a running room & its tenant
+type roomInsightsBudgets struct {
+	tenantId  string
+	room      *config.InsightsBudget
+	tenant    *config.InsightsBudget
+	expiresAt time.Time
+}
+
+// insightsUsageCounters are the counters of the services, the cost is calculated with the pricing of the config
+type insightsUsageCounters struct {
+	transcriptionSeconds int64
+	// activeTranscriptionSeconds of the running sessions, those will be counted once the sessions end
+	activeTranscriptionSeconds  int64
+	ttsCharacters               int64
+	translationCharacters       int64
+	aiTextChat                  map[string]int64
+	liveSummaryPromptTokens     int64
+	liveSummaryCompletionTokens int64
+}
+
+// insightsBudgetLimit is a single cap of a budget with its current usage
+type insightsBudgetLimit struct {
+	Scope  string  `json:"scope"`
+	Metric string  `json:"metric"`
+	Limit  float64 `json:"limit"`
+	Used   float64 `json:"used"`
+}
+
+func (l *insightsBudgetLimit) percent() float64 {
+	return l.Used * 100 / l.Limit
+}
+
+// insightsBudgetExceededInfo will be sent as metadata of the insights_budget_exceeded webhook
+type insightsBudgetExceededInfo struct {
+	TenantId        string                 `json:"tenant_id,omitempty"`
+	Limits          []*insightsBudgetLimit `json:"limits"`
+	StoppedServices []insights.ServiceType `json:"stopped_services"`
+}
+
+func validateInsightsBudget(b *config.InsightsBudget) error {
+	if b == nil {
+		return nil
+	}
+	if b.TranscriptionMinutes < 0 || b.Tokens < 0 || b.Characters < 0 || b.Cost < 0 {
+		return fmt.Errorf("insights budget can't be negative, use 0 for unlimited")
+	}
+	return nil
+}
+
+func marshalInsightsBudget(b *config.InsightsBudget) *string {
+	if b.IsUnlimited() {
+		return nil
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil
+	}
+	return new(string(data))
+}
+
+func parseInsightsBudget(val *string) *config.InsightsBudget {
+	if val == nil || *val == "" {
+		return nil
+	}
+	b := new(config.InsightsBudget)
+	if err := json.Unmarshal([]byte(*val), b); err != nil {
+		return nil
+	}
+	return b
+}
+
+// compareInsightsBudget returns the caps of the budget with the usage, unlimited ones will be skipped
+func compareInsightsBudget(scope string, b *config.InsightsBudget, u *redisservice.InsightsBudgetUsage) []*insightsBudgetLimit {
+	if b.IsUnlimited() {
+		return nil
+	}
+	var limits []*insightsBudgetLimit
+	add := func(metric string, limit, used float64) {
+		if limit > 0 {
+			limits = append(limits, &insightsBudgetLimit{Scope: scope, Metric: metric, Limit: limit, Used: used})
+		}
+	}
+	add(insightsBudgetMetricTranscriptionMinutes, float64(b.TranscriptionMinutes), float64(u.TranscriptionSeconds)/60)
+	add(insightsBudgetMetricTokens, float64(b.Tokens), float64(u.Tokens))
+	add(insightsBudgetMetricCharacters, float64(b.Characters), float64(u.Characters))
+	add(insightsBudgetMetricCost, b.Cost, u.Cost)
+	return limits
+}
+
+// insightsBudgetMetricsForService returns the caps which will stop the service
+func insightsBudgetMetricsForService(serviceType insights.ServiceType) []string {
+	switch serviceType {
+	case insights.ServiceTypeTranscription:
+		return []string{insightsBudgetMetricTranscriptionMinutes, insightsBudgetMetricCost}
+	case insights.ServiceTypeTranslation, insights.ServiceTypeSpeechSynthesis:
+		return []string{insightsBudgetMetricCharacters, insightsBudgetMetricCost}
+	case insights.ServiceTypeAITextChat, insights.ServiceTypeMeetingSummarizing, insights.ServiceTypeLiveSummary:
+		return []string{insightsBudgetMetricTokens, insightsBudgetMetricCost}
+	}
+	return []string{insightsBudgetMetricCost}
+}
+
+// roomInsightsBudget returns the budget of the config for every room with the overrides of the room
+func (s *InsightsModel) roomInsightsBudget(meta *plugnmeet.RoomMetadata) *config.InsightsBudget {
+	var configured *config.InsightsBudget
+	if budgets := s.appConfig.Insights.GetBudgets(); budgets != nil {
+		configured = budgets.Room
+	}
+	return applyInsightsBudgetOverrides(configured, meta.GetExtraData())
+}
+
+// applyInsightsBudgetOverrides lowers the caps of the configured budget by the metadata of the room.
+// The metadata can be set by the room creator, so it can't raise a cap or make it unlimited.
+func applyInsightsBudgetOverrides(configured *config.InsightsBudget, extraData map[string]string) *config.InsightsBudget {
+	b := new(config.InsightsBudget)
+	if configured != nil {
+		*b = *configured
+	}
+
+	if v, ok := extraData[insightsBudgetTranscriptionMinutesExtraDataKey]; ok {
+		if o, err := strconv.ParseInt(v, 10, 64); err == nil {
+			b.TranscriptionMinutes = lowerInsightsBudgetCap(b.TranscriptionMinutes, o)
+		}
+	}
+	if v, ok := extraData[insightsBudgetTokensExtraDataKey]; ok {
+		if o, err := strconv.ParseInt(v, 10, 64); err == nil {
+			b.Tokens = lowerInsightsBudgetCap(b.Tokens, o)
+		}
+	}
+	if v, ok := extraData[insightsBudgetCharactersExtraDataKey]; ok {
+		if o, err := strconv.ParseInt(v, 10, 64); err == nil {
+			b.Characters = lowerInsightsBudgetCap(b.Characters, o)
+		}
+	}
+	if v, ok := extraData[insightsBudgetCostExtraDataKey]; ok {
+		if o, err := strconv.ParseFloat(v, 64); err == nil {
+			b.Cost = lowerInsightsBudgetCap(b.Cost, o)
+		}
+	}
+	return b
+}
+
+// lowerInsightsBudgetCap returns the override only if it's a stricter cap, 0 means unlimited for both
+func lowerInsightsBudgetCap[T int64 | float64](configured, override T) T {
+	if override <= 0 {
+		return configured
+	}
+	if configured > 0 && override > configured {
+		return configured
+	}
+	return override
+}
+
+// tenantInsightsBudget returns the monthly budget of the tenant, or the one of the config
+func (s *InsightsModel) tenantInsightsBudget(tenantId string) (*config.InsightsBudget, error) {
+	if tenantId == "" {
+		return nil, nil
+	}
+	t, err := s.ds.GetTenant(tenantId)
+	if err != nil {
+		return nil, err
+	}
+	if t != nil {
+		if b := parseInsightsBudget(t.InsightsBudget); b != nil {
+			return b, nil
+		}
+	}
+	if budgets := s.appConfig.Insights.GetBudgets(); budgets != nil {
+		return budgets.Tenant, nil
+	}
+	return nil, nil
+}
+
+// getRoomInsightsUsageCounters returns the counters of all the services of the room, including the running transcription sessions
+func (s *InsightsModel) getRoomInsightsUsageCounters(roomId string) (*insightsUsageCounters, error) {
+	c := new(insightsUsageCounters)
+
+	transcription, err := s.redisService.GetTranscriptionRoomUsage(s.ctx, roomId, false)
+	if err != nil {
+		return nil, err
+	}
+	c.transcriptionSeconds = transcription[redisservice.TotalUsageField]
+	if c.activeTranscriptionSeconds, err = s.redisService.GetActiveTranscriptionSeconds(s.ctx, roomId); err != nil {
+		return nil, err
+	}
+
+	tts, err := s.redisService.GetTTSServiceRoomUsage(s.ctx, roomId, false)
+	if err != nil {
+		return nil, err
+	}
+	translation, err := s.redisService.GetChatTranslationRoomUsage(s.ctx, roomId, false)
+	if err != nil {
+		return nil, err
+	}
+	c.ttsCharacters = tts[redisservice.TotalUsageField]
+	c.translationCharacters = translation[redisservice.TotalUsageField]
+
+	if c.aiTextChat, err = s.redisService.GetAITextChatRoomUsage(s.ctx, roomId, false); err != nil {
+		return nil, err
+	}
+
+	liveSummary, err := s.redisService.GetLiveSummaryState(roomId)
+	if err != nil {
+		return nil, err
+	}
+	if liveSummary != nil {
+		c.liveSummaryPromptTokens = liveSummary.PromptTokens
+		c.liveSummaryCompletionTokens = liveSummary.CompletionTokens
+	}
+	return c, nil
+}
+
+// tenantInsightsUsageCounters converts the counters of the tenant usage
+func tenantInsightsUsageCounters(usage map[string]int64) *insightsUsageCounters {
+	return &insightsUsageCounters{
+		transcriptionSeconds:        usage[redisservice.InsightsBudgetTranscriptionSecondsField],
+		ttsCharacters:               usage[redisservice.InsightsBudgetTTSCharactersField],
+		translationCharacters:       usage[redisservice.InsightsBudgetTranslationCharactersField],
+		aiTextChat:                  usage,
+		liveSummaryPromptTokens:     usage[redisservice.InsightsBudgetLiveSummaryPromptTokensField],
+		liveSummaryCompletionTokens: usage[redisservice.InsightsBudgetLiveSummaryCompletionTokensField],
+	}
+}
+
+// calculateInsightsUsage sums the counters to compare with a budget,
+// the cost is calculated in the same way as the usage artifacts
+func (s *InsightsModel) calculateInsightsUsage(c *insightsUsageCounters) *redisservice.InsightsBudgetUsage {
+	u := &redisservice.InsightsBudgetUsage{
+		TranscriptionSeconds: c.transcriptionSeconds + c.activeTranscriptionSeconds,
+		Characters:           c.ttsCharacters + c.translationCharacters,
+	}
+
+	if p, err := s.appConfig.Insights.GetServiceModelPricing(insights.ServiceTypeTranscription, "default"); err == nil {
+		u.Cost += float64(u.TranscriptionSeconds) * p.PricePerHour / 3600
+	}
+	if p, err := s.appConfig.Insights.GetServiceModelPricing(insights.ServiceTypeSpeechSynthesis, "default"); err == nil {
+		u.Cost += float64(c.ttsCharacters) / 1000000 * p.PricePerMillionCharacters
+	}
+	if p, err := s.appConfig.Insights.GetServiceModelPricing(insights.ServiceTypeTranslation, "default"); err == nil {
+		u.Cost += float64(c.translationCharacters) / 1000000 * p.PricePerMillionCharacters
+	}
+
+	var aiService *config.ServiceConfig
+	if s.appConfig.Insights.Services != nil {
+		aiService = s.appConfig.Insights.Services[insights.ServiceTypeAITextChat]
+	}
+	for taskType, modelOption := range map[insights.AITaskType]string{
+		insights.AITaskTypeChat:      "chat_model",
+		insights.AITaskTypeSummarize: "summarize_model",
+	} {
+		u.Tokens += c.aiTextChat[fmt.Sprintf(redisservice.AiTextChatTotalTokenFields, taskType)]
+
+		model := "default"
+		if aiService != nil {
+			model = aiService.GetOptionsString(modelOption, model)
+		}
+		if p, err := s.appConfig.Insights.GetServiceModelPricing(insights.ServiceTypeAITextChat, model); err == nil {
+			promptTokens := c.aiTextChat[fmt.Sprintf(redisservice.AiTextChatTotalPromptTokenFields, taskType)]
+			completionTokens := c.aiTextChat[fmt.Sprintf(redisservice.AiTextChatTotalCompletionTokenFields, taskType)]
+			u.Cost += float64(promptTokens)/1000000*p.InputPricePerMillionTokens + float64(completionTokens)/1000000*p.OutputPricePerMillionTokens
+		}
+	}
+
+	u.Tokens += c.liveSummaryPromptTokens + c.liveSummaryCompletionTokens
+	if promptCost, completionCost, err := liveSummaryTokensCost(s.appConfig, c.liveSummaryPromptTokens, c.liveSummaryCompletionTokens); err == nil {
+		u.Cost += promptCost + completionCost
+	}
+
+	return u
+}
+
+// getRoomInsightsBudgets returns the budgets of the running room from the cache,
+// otherwise those will be loaded & the room will be linked with its tenant to count the usage.
+func (s *InsightsModel) getRoomInsightsBudgets(roomId string) (*roomInsightsBudgets, error) {
+	now := time.Now()
+	s.budgetCacheLock.Lock()
+	b, ok := s.budgetCache[roomId]
+	s.budgetCacheLock.Unlock()
+	if ok && now.Before(b.expiresAt) {
+		return b, nil
+	}
+
+	roomInfo, err := s.ds.GetRoomInfoByRoomId(roomId, 1)
+	if err != nil {
+		return nil, err
+	}
+	if roomInfo == nil {
+		return nil, config.ErrRoomNotFound
+	}
+	meta, err := s.natsService.GetRoomMetadataStruct(roomId)
+	if err != nil {
+		return nil, err
+	}
+	tenantBudget, err := s.tenantInsightsBudget(roomInfo.TenantId)
+	if err != nil {
+		return nil, err
+	}
+	if roomInfo.TenantId != "" {
+		if err = s.redisService.SetInsightsBudgetRoomTenant(roomId, roomInfo.TenantId); err != nil {
+			return nil, err
+		}
+	}
+
+	b = &roomInsightsBudgets{
+		tenantId:  roomInfo.TenantId,
+		room:      s.roomInsightsBudget(meta),
+		tenant:    tenantBudget,
+		expiresAt: now.Add(insightsBudgetCacheTTL),
+	}
+
+	s.budgetCacheLock.Lock()
+	defer s.budgetCacheLock.Unlock()
+	// the rooms which have ended on other servers
+	for id, cached := range s.budgetCache {
+		if now.After(cached.expiresAt) {
+			delete(s.budgetCache, id)
+		}
+	}
+	s.budgetCache[roomId] = b
+	return b, nil
+}
+
+// getInsightsBudgetLimits returns the caps of the room & its tenant with the current usage
+func (s *InsightsModel) getInsightsBudgetLimits(roomId string) ([]*insightsBudgetLimit, error) {
+	b, err := s.getRoomInsightsBudgets(roomId)
+	if err != nil {
+		return nil, err
+	}
+	if b.room.IsUnlimited() && b.tenant.IsUnlimited() {
+		return nil, nil
+	}
+
+	counters, err := s.getRoomInsightsUsageCounters(roomId)
+	if err != nil {
+		return nil, err
+	}
+	limits := compareInsightsBudget(insightsBudgetScopeRoom, b.room, s.calculateInsightsUsage(counters))
+
+	if !b.tenant.IsUnlimited() {
+		// the usage of all the rooms of the tenant in this month is counted live,
+		// except the running transcription sessions of the other rooms
+		usage, err := s.redisService.GetInsightsTenantBudgetUsage(b.tenantId)
+		if err != nil {
+			return nil, err
+		}
+		tenantCounters := tenantInsightsUsageCounters(usage)
+		tenantCounters.activeTranscriptionSeconds = counters.activeTranscriptionSeconds
+		limits = append(limits, compareInsightsBudget(insightsBudgetScopeTenant, b.tenant, s.calculateInsightsUsage(tenantCounters))...)
+	}
+
+	return limits, nil
+}
+
+// isInsightsBudgetExceeded checks if any cap which applies to the service was reached
+func isInsightsBudgetExceeded(limits []*insightsBudgetLimit, serviceType insights.ServiceType) bool {
+	metrics := insightsBudgetMetricsForService(serviceType)
+	for _, l := range limits {
+		for _, m := range metrics {
+			if l.Metric == m && l.Used >= l.Limit {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CheckInsightsBudget returns config.ErrInsightsBudgetExceeded if the room or its tenant
+// has reached any cap which applies to the service.
+func (s *InsightsModel) CheckInsightsBudget(roomId string, serviceType insights.ServiceType) error {
+	limits, err := s.getInsightsBudgetLimits(roomId)
+	if err != nil {
+		return err
+	}
+	if isInsightsBudgetExceeded(limits, serviceType) {
+		return config.ErrInsightsBudgetExceeded
+	}
+	return nil
+}
+
+// evaluateRoomInsightsBudget is EvaluateInsightsBudget for a room which may not be known yet
+func (s *InsightsModel) evaluateRoomInsightsBudget(roomId string) {
+	roomInfo, err := s.ds.GetRoomInfoByRoomId(roomId, 1)
+	if err != nil || roomInfo == nil {
+		return
+	}
+	s.EvaluateInsightsBudget(roomInfo)
+}
+
+// EvaluateInsightsBudget warns the moderators once the usage reaches the warning percent of a cap
+// & stops the services of the room which have reached any cap.
+func (s *InsightsModel) EvaluateInsightsBudget(roomInfo *dbmodels.RoomInfo) {
+	log := s.logger.WithFields(logrus.Fields{
+		"roomId":   roomInfo.RoomId,
+		"tenantId": roomInfo.TenantId,
+		"method":   "EvaluateInsightsBudget",
+	})
+
+	meta, err := s.natsService.GetRoomMetadataStruct(roomInfo.RoomId)
+	if err != nil || meta == nil || !meta.GetRoomFeatures().GetInsightsFeatures().GetIsAllow() {
+		return
+	}
+	limits, err := s.getInsightsBudgetLimits(roomInfo.RoomId)
+	if err != nil {
+		log.WithError(err).Errorln("failed to get insights budget")
+		return
+	}
+
+	warningPercent := float64(s.appConfig.Insights.GetBudgets().GetWarningPercent())
+	var exceeded []*insightsBudgetLimit
+	for _, l := range limits {
+		p := l.percent()
+		if p >= 100 {
+			exceeded = append(exceeded, l)
+			continue
+		}
+		if p < warningPercent {
+			continue
+		}
+		if first, err := s.redisService.MarkInsightsBudgetState(roomInfo.RoomId, fmt.Sprintf("warned:%s:%s", l.Scope, l.Metric)); err != nil || !first {
+			continue
+		}
+		log.WithField("limit", l).Infoln("insights budget reached the warning percent")
+		s.notifyInsightsToModerators(roomInfo.RoomId, "insights.budget-warning", plugnmeet.NatsSystemNotificationTypes_NATS_SYSTEM_NOTIFICATION_WARNING)
+	}
+
+	if len(exceeded) > 0 {
+		s.stopInsightsServicesOverBudget(roomInfo, meta, exceeded, log)
+	}
+}
+
+// stopInsightsServicesOverBudget ends the running services affected by the exceeded caps
+func (s *InsightsModel) stopInsightsServicesOverBudget(roomInfo *dbmodels.RoomInfo, meta *plugnmeet.RoomMetadata, exceeded []*insightsBudgetLimit, log *logrus.Entry) {
+	metrics := make(map[string]bool)
+	for _, l := range exceeded {
+		metrics[l.Metric] = true
+	}
+	over := func(serviceType insights.ServiceType) bool {
+		for _, m := range insightsBudgetMetricsForService(serviceType) {
+			if metrics[m] {
+				return true
+			}
+		}
+		return false
+	}
+
+	roomId := roomInfo.RoomId
+	features := meta.GetRoomFeatures().GetInsightsFeatures()
+	var stopped []insights.ServiceType
+	stop := func(serviceType insights.ServiceType, end func(string) error) {
+		if err := end(roomId); err != nil {
+			log.WithError(err).WithField("service", serviceType).Errorln("failed to stop insights service over budget")
+			return
+		}
+		stopped = append(stopped, serviceType)
+	}
+
+	transcription := features.GetTranscriptionFeatures()
+	// speech synthesis runs as part of the transcription
+	if transcription.GetIsEnabled() && (over(insights.ServiceTypeTranscription) || (transcription.GetIsEnabledSpeechSynthesis() && over(insights.ServiceTypeSpeechSynthesis))) {
+		stop(insights.ServiceTypeTranscription, s.EndTranscription)
+	}
+	if features.GetChatTranslationFeatures().GetIsEnabled() && over(insights.ServiceTypeTranslation) {
+		stop(insights.ServiceTypeTranslation, s.ChatEndTranslation)
+	}
+	if features.GetAiFeatures().GetAiTextChatFeatures().GetIsEnabled() && over(insights.ServiceTypeAITextChat) {
+		stop(insights.ServiceTypeAITextChat, s.EndAITextChat)
+	}
+	if features.GetAiFeatures().GetMeetingSummarizationFeatures().GetIsEnabled() && over(insights.ServiceTypeMeetingSummarizing) {
+		stop(insights.ServiceTypeMeetingSummarizing, s.EndEndAIMeetingSummarization)
+	}
+	if liveSummary, err := s.redisService.GetLiveSummaryState(roomId); err == nil && liveSummary != nil && liveSummary.Enabled && over(insights.ServiceTypeLiveSummary) {
+		stop(insights.ServiceTypeLiveSummary, s.EndLiveSummary)
+	}
+
+	// only the caps exceeded for the first time will be reported
+	var newlyExceeded []*insightsBudgetLimit
+	for _, l := range exceeded {
+		if first, err := s.redisService.MarkInsightsBudgetState(roomId, fmt.Sprintf("exceeded:%s:%s", l.Scope, l.Metric)); err == nil && first {
+			newlyExceeded = append(newlyExceeded, l)
+		}
+	}
+	if len(newlyExceeded) == 0 && len(stopped) == 0 {
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"exceeded": newlyExceeded,
+		"stopped":  stopped,
+	}).Warnln("insights budget exceeded")
+	s.notifyInsightsToModerators(roomId, "insights.budget-exceeded", plugnmeet.NatsSystemNotificationTypes_NATS_SYSTEM_NOTIFICATION_ERROR)
+
+	if len(newlyExceeded) > 0 {
+		s.sendInsightsBudgetExceededWebhook(roomInfo, newlyExceeded, stopped, log)
+	}
+}
+
+func (s *InsightsModel) notifyInsightsToModerators(roomId, msg string, msgType plugnmeet.NatsSystemNotificationTypes) {
+	users, err := s.natsService.GetOnlineUsersList(roomId)
+	if err != nil {
+		s.logger.WithError(err).Errorln("failed to get online users")
+		return
+	}
+	for _, u := range users {
+		if !u.IsAdmin {
+			continue
+		}
+		if err := s.natsService.BroadcastSystemNotificationToRoom(roomId, msg, msgType, true, &u.UserId); err != nil {
+			s.logger.WithError(err).Errorln("error broadcasting system notification")
+		}
+	}
+}
+
+func (s *InsightsModel) sendInsightsBudgetExceededWebhook(roomInfo *dbmodels.RoomInfo, limits []*insightsBudgetLimit, stopped []insights.ServiceType, log *logrus.Entry) {
+	if s.webhookNotifier == nil {
+		return
+	}
+
+	info, err := json.Marshal(&insightsBudgetExceededInfo{
+		TenantId:        roomInfo.TenantId,
+		Limits:          limits,
+		StoppedServices: stopped,
+	})
+	if err != nil {
+		log.WithError(err).Errorln("failed to marshal insights budget info")
+		return
+	}
+
+	msg := &plugnmeet.CommonNotifyEvent{
+		Event: new(insightsBudgetExceededEvent),
+		Room: &plugnmeet.NotifyEventRoom{
+			Sid:      &roomInfo.Sid,
+			RoomId:   &roomInfo.RoomId,
+			Metadata: new(string(info)),
+		},
+	}
+	if err := s.webhookNotifier.SendWebhookEvent(msg); err != nil {
+		log.WithError(err).Errorln("error sending insights budget exceeded webhook")
+	}
+}
+
+// cleanupRoomInsightsBudget removes the budget states of the ended room. The link with its tenant will expire by itself,
+// as the last transcription sessions may end after the room & those must be added to the tenant.
+func (s *InsightsModel) cleanupRoomInsightsBudget(roomId string, log *logrus.Entry) {
+	if s.appConfig.Insights == nil {
+		return
+	}
+	if err := s.redisService.DeleteInsightsBudgetState(roomId); err != nil {
+		log.WithError(err).Errorln("failed to delete insights budget state")
+	}
+	s.budgetCacheLock.Lock()
+	delete(s.budgetCache, roomId)
+	s.budgetCacheLock.Unlock()
+}
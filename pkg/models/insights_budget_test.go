@@ -0,0 +1,122 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
+	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+)
+
+func TestApplyInsightsBudgetOverrides(t *testing.T) {
+	configured := &config.InsightsBudget{TranscriptionMinutes: 120, Tokens: 1000, Cost: 5}
+
+	tests := []struct {
+		name      string
+		extraData map[string]string
+		want      config.InsightsBudget
+	}{
+		{"no overrides", nil, *configured},
+		{"lower caps", map[string]string{
+			insightsBudgetTranscriptionMinutesExtraDataKey: "60",
+			insightsBudgetCostExtraDataKey:                 "2.5",
+		}, config.InsightsBudget{TranscriptionMinutes: 60, Tokens: 1000, Cost: 2.5}},
+		{"can't raise caps", map[string]string{
+			insightsBudgetTokensExtraDataKey: "5000",
+			insightsBudgetCostExtraDataKey:   "50",
+		}, *configured},
+		{"can't make caps unlimited", map[string]string{
+			insightsBudgetTranscriptionMinutesExtraDataKey: "0",
+			insightsBudgetTokensExtraDataKey:               "-1",
+		}, *configured},
+		{"invalid values are ignored", map[string]string{
+			insightsBudgetTokensExtraDataKey: "many",
+		}, *configured},
+		{"cap of an unlimited metric", map[string]string{
+			insightsBudgetCharactersExtraDataKey: "300",
+		}, config.InsightsBudget{TranscriptionMinutes: 120, Tokens: 1000, Characters: 300, Cost: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := applyInsightsBudgetOverrides(configured, tt.extraData)
+			if *got != tt.want {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+
+	// without a configured budget, the room can set its own caps
+	got := applyInsightsBudgetOverrides(nil, map[string]string{insightsBudgetTokensExtraDataKey: "500"})
+	if got.Tokens != 500 {
+		t.Errorf("unexpected budget without config: %+v", *got)
+	}
+	if *configured != (config.InsightsBudget{TranscriptionMinutes: 120, Tokens: 1000, Cost: 5}) {
+		t.Error("the configured budget must not be changed")
+	}
+}
+
+func TestCompareInsightsBudget(t *testing.T) {
+	b := &config.InsightsBudget{TranscriptionMinutes: 10, Tokens: 1000}
+	u := &redisservice.InsightsBudgetUsage{TranscriptionSeconds: 600, Tokens: 400, Characters: 99, Cost: 3}
+
+	limits := compareInsightsBudget(insightsBudgetScopeRoom, b, u)
+	if len(limits) != 2 {
+		t.Fatalf("expected only the caps which were set, got %d", len(limits))
+	}
+	if l := limits[0]; l.Metric != insightsBudgetMetricTranscriptionMinutes || l.Used != 10 || l.percent() != 100 {
+		t.Errorf("unexpected transcription limit: %+v", l)
+	}
+	if l := limits[1]; l.Metric != insightsBudgetMetricTokens || l.Used != 400 || l.percent() != 40 {
+		t.Errorf("unexpected tokens limit: %+v", l)
+	}
+
+	if !isInsightsBudgetExceeded(limits, insights.ServiceTypeTranscription) {
+		t.Error("transcription should be over budget")
+	}
+	if isInsightsBudgetExceeded(limits, insights.ServiceTypeAITextChat) {
+		t.Error("AI text chat should be within the budget")
+	}
+	if compareInsightsBudget(insightsBudgetScopeTenant, nil, u) != nil {
+		t.Error("unlimited budget must not have caps")
+	}
+}
+
+func TestCalculateInsightsUsage(t *testing.T) {
+	s := &InsightsModel{appConfig: &config.AppConfig{Insights: &config.InsightsConfig{
+		Services: map[insights.ServiceType]*config.ServiceConfig{
+			insights.ServiceTypeTranscription: {Pricing: map[string]config.ModelPricing{"default": {PricePerHour: 3.6}}},
+			insights.ServiceTypeTranslation:   {Pricing: map[string]config.ModelPricing{"default": {PricePerMillionCharacters: 20}}},
+			insights.ServiceTypeAITextChat: {
+				Options: map[string]interface{}{"chat_model": "small"},
+				Pricing: map[string]config.ModelPricing{"small": {InputPricePerMillionTokens: 1, OutputPricePerMillionTokens: 2}},
+			},
+		},
+	}}}
+
+	chatFields := func(prompt, completion int64) map[string]int64 {
+		return map[string]int64{
+			fmt.Sprintf(redisservice.AiTextChatTotalPromptTokenFields, insights.AITaskTypeChat):     prompt,
+			fmt.Sprintf(redisservice.AiTextChatTotalCompletionTokenFields, insights.AITaskTypeChat): completion,
+			fmt.Sprintf(redisservice.AiTextChatTotalTokenFields, insights.AITaskTypeChat):           prompt + completion,
+		}
+	}
+
+	// the counters of the tenant use the same fields
+	usage := chatFields(1000000, 500000)
+	usage[redisservice.InsightsBudgetTranscriptionSecondsField] = 3000
+	usage[redisservice.InsightsBudgetTranslationCharactersField] = 50000
+	usage[redisservice.InsightsBudgetTTSCharactersField] = 100
+	c := tenantInsightsUsageCounters(usage)
+	c.activeTranscriptionSeconds = 600
+
+	u := s.calculateInsightsUsage(c)
+	if u.TranscriptionSeconds != 3600 || u.Characters != 50100 || u.Tokens != 1500000 {
+		t.Errorf("unexpected usage: %+v", u)
+	}
+	// 1h transcription 3.6 + 50k characters 1.0 + 1M prompt 1.0 + 0.5M completion 1.0, no pricing for tts
+	if math.Abs(u.Cost-6.6) > 1e-9 {
+		t.Errorf("unexpected cost: %f", u.Cost)
+	}
+}
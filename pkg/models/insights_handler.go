@@ -28,6 +28,9 @@ func (s *InsightsModel) TranscriptionConfigure(req *plugnmeet.InsightsTranscript
 	if !insightsFeatures.IsAllow || !insightsFeatures.TranscriptionFeatures.IsAllow {
 		return fmt.Errorf("insights feature wasn't enabled")
 	}
+	if err := s.CheckInsightsBudget(roomId, insights.ServiceTypeTranscription); err != nil {
+		return err
+	}
 
 	insightsFeatures.TranscriptionFeatures.IsEnabled = true
 	insightsFeatures.TranscriptionFeatures.AllowedSpokenLangs = req.AllowedSpokenLangs
@@ -116,6 +119,9 @@ func (s *InsightsModel) TranscriptionUserSession(req *plugnmeet.InsightsTranscri
 		if metadata.RoomFeatures.EndToEndEncryptionFeatures.EnabledSelfInsertEncryptionKey {
 			return fmt.Errorf("insights.feature-disable-while-e2ee-self-key-enabled")
 		}
+		if err := s.CheckInsightsBudget(roomId, insights.ServiceTypeTranscription); err != nil {
+			return err
+		}
 
 		userInfo, err := s.natsService.GetUserInfo(roomId, userId)
 		if err != nil {
@@ -129,6 +135,7 @@ func (s *InsightsModel) TranscriptionUserSession(req *plugnmeet.InsightsTranscri
 			SpokenLang:                  *req.SpokenLang,
 			UserName:                    userInfo.Name,
 			AllowedTranscriptionStorage: req.AllowedTranscriptionStorage,
+			Diarization:                 s.isDiarizationRequested(metadata, userId),
 		}
 		if metadata.RoomFeatures.InsightsFeatures.TranscriptionFeatures.IsEnabledTranslation {
 			options.TransLangs = metadata.RoomFeatures.InsightsFeatures.TranscriptionFeatures.AllowedTransLangs
@@ -207,6 +214,9 @@ func (s *InsightsModel) ChatTranslationConfigure(req *plugnmeet.InsightsChatTran
 	if len(req.AllowedTransLangs) > int(insightsFeatures.ChatTranslationFeatures.MaxSelectedTransLangs) {
 		return fmt.Errorf("max allowed selected languages exceeded")
 	}
+	if err := s.CheckInsightsBudget(roomId, insights.ServiceTypeTranslation); err != nil {
+		return err
+	}
 
 	insightsFeatures.ChatTranslationFeatures.IsEnabled = true
 	insightsFeatures.ChatTranslationFeatures.AllowedTransLangs = req.AllowedTransLangs
@@ -231,6 +241,9 @@ func (s *InsightsModel) ExecuteChatTranslation(ctx context.Context, req *plugnme
 	if !insightsFeatures.IsAllow || !insightsFeatures.ChatTranslationFeatures.IsAllow {
 		return nil, fmt.Errorf("feature wasn't enabled")
 	}
+	if err := s.CheckInsightsBudget(roomId, insights.ServiceTypeTranslation); err != nil {
+		return nil, err
+	}
 
 	opts := insights.TranslationTaskOptions{
 		Text:        req.Text,
@@ -250,6 +263,7 @@ func (s *InsightsModel) ExecuteChatTranslation(ctx context.Context, req *plugnme
 	if err := s.redisService.UpdateChatTranslationUsage(ctx, roomId, userId, len(opts.Text)); err != nil {
 		s.logger.WithError(err).Error("failed to update chat translation usage")
 	}
+	go s.evaluateRoomInsightsBudget(roomId)
 
 	res := &plugnmeet.InsightsTranslateTextRes{
 		Status: true,
@@ -289,6 +303,9 @@ func (s *InsightsModel) AITextChatConfigure(req *plugnmeet.InsightsAITextChatCon
 	if !insightsFeatures.IsAllow || !insightsFeatures.AiFeatures.IsAllow || !insightsFeatures.AiFeatures.AiTextChatFeatures.IsAllow {
 		return fmt.Errorf("insights feature wasn't enabled")
 	}
+	if err := s.CheckInsightsBudget(roomId, insights.ServiceTypeAITextChat); err != nil {
+		return err
+	}
 	aiTextChatFeatures := insightsFeatures.AiFeatures.AiTextChatFeatures
 
 	aiTextChatFeatures.IsEnabled = true
@@ -331,6 +348,9 @@ func (s *InsightsModel) ExecuteAITextChat(req *plugnmeet.InsightsAITextChatConte
 	if !foundUser {
 		return fmt.Errorf("you're not allowed to use this service")
 	}
+	if err := s.CheckInsightsBudget(roomId, insights.ServiceTypeAITextChat); err != nil {
+		return err
+	}
 
 	requestFrom := plugnmeet.InsightsAIRequestSource_INSIGHTS_AI_REQUEST_SOURCE_CHAT
 	if req.RequestFrom != nil {
@@ -388,6 +408,9 @@ func (s *InsightsModel) AIMeetingSummarizationConfig(req *plugnmeet.InsightsAIMe
 	if !insightsFeatures.IsAllow || !insightsFeatures.AiFeatures.IsAllow || !insightsFeatures.AiFeatures.MeetingSummarizationFeatures.IsAllow {
 		return fmt.Errorf("insights feature wasn't enabled")
 	}
+	if err := s.CheckInsightsBudget(roomId, insights.ServiceTypeMeetingSummarizing); err != nil {
+		return err
+	}
 	aiMeetingSummarizationFeatures := insightsFeatures.AiFeatures.MeetingSummarizationFeatures
 
 	aiMeetingSummarizationFeatures.IsEnabled = true
@@ -0,0 +1,377 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
+	insightsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/insights"
+	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultLiveSummaryIntervalSec = 300
+	liveSummaryLockTTL            = 2 * time.Minute
+	liveSummaryRequestTimeout     = time.Minute
+	// time to wait for a running update before creating the final artifact
+	liveSummaryFinalLockWait = 30 * time.Second
+
+	liveSummaryInstruction = `You are taking notes of a meeting. Update the notes using the new part of the transcript below.
+Reply ONLY with a JSON object in this format, without markdown:
+{"summary": "what happened so far in a few short paragraphs", "action_items": [{"task": "", "owner": "", "due_date": ""}], "decisions": [""]}
+Keep the action items & decisions of the previous notes unless the transcript changes them. Use empty strings for unknown owner or due date.`
+)
+
+// LiveSummaryActionItem is a task agreed during the meeting
+type LiveSummaryActionItem struct {
+	Task    string `json:"task"`
+	Owner   string `json:"owner,omitempty"`
+	DueDate string `json:"due_date,omitempty"`
+}
+
+// LiveSummary is the rolling "what happened so far" of the meeting
+type LiveSummary struct {
+	Summary     string                   `json:"summary"`
+	ActionItems []*LiveSummaryActionItem `json:"action_items"`
+	Decisions   []string                 `json:"decisions"`
+	// UpdatedAt in unix ms
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+func (l *LiveSummary) IsEmpty() bool {
+	return l.Summary == "" && len(l.ActionItems) == 0 && len(l.Decisions) == 0
+}
+
+// LiveSummaryInfo is the response of the live summary api
+type LiveSummaryInfo struct {
+	IsRunning   bool         `json:"is_running"`
+	LiveSummary *LiveSummary `json:"live_summary,omitempty"`
+}
+
+// parseLiveSummary reads the JSON of the model's response. Models sometimes wrap it with markdown
+// or ignore the format, in that case the whole text will be used as summary with the previous items.
+func parseLiveSummary(text string, previous *LiveSummary) *LiveSummary {
+	text = strings.TrimSpace(text)
+	if start, end := strings.Index(text, "{"), strings.LastIndex(text, "}"); start >= 0 && end > start {
+		ls := new(LiveSummary)
+		if err := json.Unmarshal([]byte(text[start:end+1]), ls); err == nil && ls.Summary != "" {
+			ls.ActionItems = cleanLiveSummaryActionItems(ls.ActionItems)
+			ls.Decisions = cleanLiveSummaryDecisions(ls.Decisions)
+			return ls
+		}
+	}
+
+	ls := &LiveSummary{Summary: text}
+	if previous != nil {
+		ls.ActionItems = previous.ActionItems
+		ls.Decisions = previous.Decisions
+	}
+	return ls
+}
+
+func cleanLiveSummaryActionItems(items []*LiveSummaryActionItem) []*LiveSummaryActionItem {
+	cleaned := make([]*LiveSummaryActionItem, 0, len(items))
+	for _, a := range items {
+		if a == nil || strings.TrimSpace(a.Task) == "" {
+			continue
+		}
+		cleaned = append(cleaned, a)
+	}
+	return cleaned
+}
+
+func cleanLiveSummaryDecisions(decisions []string) []string {
+	cleaned := make([]string, 0, len(decisions))
+	for _, d := range decisions {
+		if d = strings.TrimSpace(d); d != "" {
+			cleaned = append(cleaned, d)
+		}
+	}
+	return cleaned
+}
+
+func unmarshalLiveSummary(result string) *LiveSummary {
+	if result == "" {
+		return nil
+	}
+	ls := new(LiveSummary)
+	if err := json.Unmarshal([]byte(result), ls); err != nil {
+		return nil
+	}
+	return ls
+}
+
+func (s *InsightsModel) liveSummaryInterval() time.Duration {
+	sec := defaultLiveSummaryIntervalSec
+	if service, ok := s.appConfig.Insights.Services[insights.ServiceTypeLiveSummary]; ok && service != nil {
+		sec = service.GetIntOption("interval_sec", defaultLiveSummaryIntervalSec)
+	}
+	if sec < 30 {
+		sec = 30
+	}
+	return time.Duration(sec) * time.Second
+}
+
+// StartLiveSummary starts summarizing the transcription of the room periodically
+func (s *InsightsModel) StartLiveSummary(roomId, userId string) error {
+	if _, _, err := s.appConfig.Insights.GetProviderAccountForService(insights.ServiceTypeLiveSummary); err != nil {
+		return err
+	}
+
+	meta, err := s.natsService.GetRoomMetadataStruct(roomId)
+	if err != nil {
+		return err
+	}
+	if meta == nil {
+		return config.ErrRoomNotFound
+	}
+	features := meta.GetRoomFeatures().GetInsightsFeatures()
+	if !features.GetIsAllow() {
+		return fmt.Errorf("insights feature isn't allowed in this room")
+	}
+	if !features.GetTranscriptionFeatures().GetIsEnabled() {
+		return fmt.Errorf("transcription needs to be enabled to get live summary")
+	}
+	if err := s.CheckInsightsBudget(roomId, insights.ServiceTypeLiveSummary); err != nil {
+		return err
+	}
+
+	if err := s.redisService.EnableLiveSummary(roomId, userId); err != nil {
+		return err
+	}
+	s.notifyInsightsToModerators(roomId, "insights.live-summary-started", plugnmeet.NatsSystemNotificationTypes_NATS_SYSTEM_NOTIFICATION_INFO)
+	return nil
+}
+
+// EndLiveSummary stops the updates, the latest summary will still be stored as artifact at the end of the session
+func (s *InsightsModel) EndLiveSummary(roomId string) error {
+	if err := s.redisService.DisableLiveSummary(roomId); err != nil {
+		return err
+	}
+	s.notifyInsightsToModerators(roomId, "insights.live-summary-stopped", plugnmeet.NatsSystemNotificationTypes_NATS_SYSTEM_NOTIFICATION_INFO)
+	return nil
+}
+
+// GetLiveSummary returns the latest summary of the room
+func (s *InsightsModel) GetLiveSummary(roomId string) (*LiveSummaryInfo, error) {
+	st, err := s.redisService.GetLiveSummaryState(roomId)
+	if err != nil {
+		return nil, err
+	}
+	info := new(LiveSummaryInfo)
+	if st != nil {
+		info.IsRunning = st.Enabled
+		info.LiveSummary = unmarshalLiveSummary(st.Result)
+	}
+	return info, nil
+}
+
+// RunLiveSummaries updates the summary of the rooms once their interval has passed.
+// It's called by the janitor, so only the leader runs it.
+func (s *InsightsModel) RunLiveSummaries() {
+	if s.appConfig.Insights == nil || !s.appConfig.Insights.Enabled {
+		return
+	}
+	rooms, err := s.redisService.GetLiveSummaryRooms()
+	if err != nil {
+		s.logger.WithError(err).Errorln("failed to get rooms with live summary")
+		return
+	}
+
+	interval := s.liveSummaryInterval()
+	for _, roomId := range rooms {
+		st, err := s.redisService.GetLiveSummaryState(roomId)
+		if err != nil {
+			s.logger.WithError(err).Errorln("failed to get live summary state")
+			continue
+		}
+		if st == nil || !st.Enabled {
+			// expired or stopped
+			_ = s.redisService.DisableLiveSummary(roomId)
+			continue
+		}
+		if time.Since(time.UnixMilli(st.LastRun)) < interval {
+			continue
+		}
+		go func(roomId string) {
+			if _, err := s.updateLiveSummary(roomId, false); err != nil {
+				s.logger.WithError(err).WithField("roomId", roomId).Errorln("failed to update live summary")
+			}
+		}(roomId)
+	}
+}
+
+// updateLiveSummary feeds the new transcription into the model with the previous summary.
+// It returns nil if there was nothing new to summarize or another update is running.
+func (s *InsightsModel) updateLiveSummary(roomId string, final bool) (*LiveSummary, error) {
+	locked, err := s.redisService.LockLiveSummary(roomId, liveSummaryLockTTL)
+	if final {
+		// wait for the running update, otherwise the artifact will miss its result
+		deadline := time.Now().Add(liveSummaryFinalLockWait)
+		for err == nil && !locked && time.Now().Before(deadline) {
+			time.Sleep(time.Second)
+			locked, err = s.redisService.LockLiveSummary(roomId, liveSummaryLockTTL)
+		}
+	}
+	if err != nil || !locked {
+		return nil, err
+	}
+	defer s.redisService.UnlockLiveSummary(roomId)
+
+	st, err := s.redisService.GetLiveSummaryState(roomId)
+	if err != nil || st == nil {
+		return nil, err
+	}
+	transcript, lastChunk, err := s.getNewTranscription(roomId, st.LastChunk)
+	if err != nil || transcript == "" {
+		return nil, err
+	}
+	log := s.logger.WithFields(logrus.Fields{
+		"roomId": roomId,
+		"method": "updateLiveSummary",
+	})
+	// even if the request fails, we'll wait for the next interval
+	if err := s.redisService.SetLiveSummaryLastRun(roomId); err != nil {
+		log.WithError(err).Warnln("failed to set last run of live summary")
+	}
+
+	providerAccount, service, err := s.appConfig.Insights.GetProviderAccountForService(insights.ServiceTypeLiveSummary)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, liveSummaryRequestTimeout)
+	defer cancel()
+	provider, err := insightsservice.NewProvider(&insightsservice.ProviderArgs{
+		Ctx:             ctx,
+		ProviderType:    service.Provider,
+		ProviderAccount: providerAccount,
+		ServiceConfig:   service,
+		RDS:             s.rds,
+		Logger:          log,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	previous := unmarshalLiveSummary(st.Result)
+	var history []*plugnmeet.InsightsAITextChatContent
+	if st.Result != "" {
+		history = append(history, &plugnmeet.InsightsAITextChatContent{
+			Role: plugnmeet.InsightsAITextChatRole_INSIGHTS_AI_TEXT_CHAT_ROLE_SYSTEM,
+			Text: "These are the previous notes of the meeting: " + st.Result,
+		})
+	}
+	history = append(history, &plugnmeet.InsightsAITextChatContent{
+		Role: plugnmeet.InsightsAITextChatRole_INSIGHTS_AI_TEXT_CHAT_ROLE_USER,
+		Text: liveSummaryInstruction + "\n\nTranscript:\n" + transcript,
+	})
+
+	text, promptTokens, completionTokens, err := provider.AIChatTextSummarize(ctx, service.GetOptionsString("summarize_model", ""), history)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := parseLiveSummary(text, previous)
+	summary.UpdatedAt = time.Now().UnixMilli()
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.redisService.UpdateLiveSummaryResult(roomId, string(data), lastChunk, promptTokens, completionTokens); err != nil {
+		return nil, err
+	}
+	log.Infof("live summary updated with %d action items & %d decisions", len(summary.ActionItems), len(summary.Decisions))
+
+	if !final {
+		s.broadcastLiveSummary(roomId, summary)
+		s.evaluateRoomInsightsBudget(roomId)
+	}
+	return summary, nil
+}
+
+// getNewTranscription returns the transcription after the lastChunk in chronological order
+// with the field of the newest chunk.
+func (s *InsightsModel) getNewTranscription(roomId, lastChunk string) (string, string, error) {
+	chunks, err := s.redisService.GetTranscriptionHistory(roomId)
+	if err != nil || len(chunks) == 0 {
+		return "", "", err
+	}
+	last, _ := strconv.ParseInt(lastChunk, 10, 64)
+
+	type chunkKey struct {
+		field string
+		ts    int64
+	}
+	keys := make([]chunkKey, 0, len(chunks))
+	for field := range chunks {
+		ts, err := strconv.ParseInt(field, 10, 64)
+		if err != nil || ts <= last {
+			continue
+		}
+		keys = append(keys, chunkKey{field: field, ts: ts})
+	}
+	if len(keys) == 0 {
+		return "", "", nil
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ts < keys[j].ts
+	})
+
+	var b strings.Builder
+	for _, k := range keys {
+		chunk := new(redisservice.TranscriptionChunk)
+		if err := json.Unmarshal([]byte(chunks[k.field]), chunk); err != nil || chunk.Text == "" {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%s: %s\n", chunk.Name, chunk.Text))
+	}
+	return b.String(), keys[len(keys)-1].field, nil
+}
+
+// broadcastLiveSummary sends the update to the moderators as private system chat message
+func (s *InsightsModel) broadcastLiveSummary(roomId string, summary *LiveSummary) {
+	users, err := s.natsService.GetOnlineUsersList(roomId)
+	if err != nil {
+		s.logger.WithError(err).Errorln("failed to get online users")
+		return
+	}
+	msg := renderLiveSummaryText(roomId, summary)
+	for _, u := range users {
+		if !u.IsAdmin {
+			continue
+		}
+		if err := s.natsService.BroadcastSystemEventToRoom(plugnmeet.NatsMsgServerToClientEvents_SYSTEM_CHAT_MSG, roomId, msg, &u.UserId); err != nil {
+			s.logger.WithError(err).Errorln("failed to broadcast live summary")
+		}
+	}
+}
+
+// finalizeLiveSummary summarizes the remaining transcription & stores the final version as artifact.
+// It should be called before the transcription history gets deleted.
+func (s *InsightsModel) finalizeLiveSummary(dbTableId uint64, roomId, roomSid string, log *logrus.Entry) {
+	st, err := s.redisService.GetLiveSummaryState(roomId)
+	if err != nil {
+		log.WithError(err).Errorln("failed to get live summary state")
+		return
+	}
+	if st == nil {
+		return
+	}
+	if st.Enabled {
+		if _, err = s.updateLiveSummary(roomId, true); err != nil {
+			log.WithError(err).Errorln("failed to create final live summary")
+		}
+		if latest, err := s.redisService.GetLiveSummaryState(roomId); err == nil && latest != nil {
+			st = latest
+		}
+	}
+	s.artifactModel.CreateLiveSummaryArtifact(roomId, roomSid, dbTableId, unmarshalLiveSummary(st.Result), st.PromptTokens, st.CompletionTokens, log)
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestParseLiveSummary(t *testing.T) {
+	previous := &LiveSummary{
+		Summary:     "old notes",
+		ActionItems: []*LiveSummaryActionItem{{Task: "send the slides", Owner: "Alice"}},
+		Decisions:   []string{"release on friday"},
+	}
+
+	t.Run("json", func(t *testing.T) {
+		ls := parseLiveSummary(`{"summary":"new notes","action_items":[{"task":"review","owner":"Bob","due_date":"monday"}],"decisions":["ship it"]}`, previous)
+		if ls.Summary != "new notes" || len(ls.ActionItems) != 1 || ls.ActionItems[0].Owner != "Bob" || ls.ActionItems[0].DueDate != "monday" {
+			t.Errorf("unexpected summary: %+v", ls)
+		}
+		if len(ls.Decisions) != 1 || ls.Decisions[0] != "ship it" {
+			t.Errorf("unexpected decisions: %v", ls.Decisions)
+		}
+	})
+
+	t.Run("wrapped with markdown", func(t *testing.T) {
+		ls := parseLiveSummary("Here are the notes:\n```json\n{\"summary\":\"new notes\",\"action_items\":[],\"decisions\":[]}\n```", previous)
+		if ls.Summary != "new notes" || len(ls.ActionItems) != 0 || len(ls.Decisions) != 0 {
+			t.Errorf("unexpected summary: %+v", ls)
+		}
+	})
+
+	t.Run("empty items are removed", func(t *testing.T) {
+		ls := parseLiveSummary(`{"summary":"new notes","action_items":[{"task":" "},null,{"task":"review"}],"decisions":["", "  ship it  "]}`, nil)
+		if len(ls.ActionItems) != 1 || ls.ActionItems[0].Task != "review" {
+			t.Errorf("unexpected action items: %+v", ls.ActionItems)
+		}
+		if len(ls.Decisions) != 1 || ls.Decisions[0] != "ship it" {
+			t.Errorf("unexpected decisions: %q", ls.Decisions)
+		}
+	})
+
+	t.Run("plain text keeps the previous items", func(t *testing.T) {
+		ls := parseLiveSummary("  The team discussed the release.  ", previous)
+		if ls.Summary != "The team discussed the release." {
+			t.Errorf("unexpected summary: %q", ls.Summary)
+		}
+		if len(ls.ActionItems) != 1 || ls.ActionItems[0].Task != "send the slides" || len(ls.Decisions) != 1 {
+			t.Errorf("previous items must be kept: %+v", ls)
+		}
+	})
+
+	t.Run("json without summary is used as text", func(t *testing.T) {
+		text := `{"notes":"something"}`
+		ls := parseLiveSummary(text, nil)
+		if ls.Summary != text || len(ls.ActionItems) != 0 || len(ls.Decisions) != 0 {
+			t.Errorf("unexpected summary: %+v", ls)
+		}
+	})
+
+	t.Run("invalid json is used as text", func(t *testing.T) {
+		text := `{"summary": "cut off`
+		if ls := parseLiveSummary(text, nil); ls.Summary != text {
+			t.Errorf("unexpected summary: %q", ls.Summary)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
+	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+	"github.com/sirupsen/logrus"
+)
+
+// transcriptionDiarizationExtraDataKey is the key of room metadata extra_data with the comma separated
+// user ids whose tracks need diarization, e.g. the participant of a shared physical room.
+const transcriptionDiarizationExtraDataKey = "transcription_diarization_user_ids"
+
+// TalkStatsInfo is the talk-time of a participant, or of a speaker of their diarized track
+type TalkStatsInfo struct {
+	UserId          string  `json:"user_id"`
+	Name            string  `json:"name"`
+	SpeakerLabel    string  `json:"speaker_label,omitempty"`
+	TalkTimeSec     float64 `json:"talk_time_sec"`
+	TalkTimePercent float64 `json:"talk_time_percent"`
+	Words           int64   `json:"words"`
+	WordsPerMinute  float64 `json:"words_per_minute"`
+	Utterances      int64   `json:"utterances"`
+	Interruptions   int64   `json:"interruptions"`
+}
+
+// isDiarizationRequested checks if the room has asked to separate the speakers of the user's track
+func (s *InsightsModel) isDiarizationRequested(metadata *plugnmeet.RoomMetadata, userId string) bool {
+	if s.appConfig.Insights == nil {
+		return false
+	}
+	service, ok := s.appConfig.Insights.Services[insights.ServiceTypeTranscription]
+	if !ok || service == nil || !service.GetBoolOption("diarization", false) {
+		return false
+	}
+
+	ids, ok := metadata.GetExtraData()[transcriptionDiarizationExtraDataKey]
+	if !ok {
+		return false
+	}
+	for _, id := range strings.Split(ids, ",") {
+		if strings.TrimSpace(id) == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// finalizeTalkStats stores the talk-time statistics as artifact & in the analytics of the participants
+func (s *InsightsModel) finalizeTalkStats(dbTableId uint64, roomId, roomSid string, log *logrus.Entry) {
+	defer s.redisService.DeleteTalkStats(roomId)
+
+	list, err := s.redisService.GetTalkStats(roomId)
+	if err != nil {
+		log.WithError(err).Errorln("failed to get talk stats")
+		return
+	}
+	if len(list) == 0 {
+		return
+	}
+
+	speakers, participants := buildTalkStats(list)
+	s.artifactModel.CreateTalkStatsArtifact(roomId, roomSid, dbTableId, speakers, participants, log)
+	s.analyticsModel.AddUserTalkStats(roomId, participants)
+}
+
+// buildTalkStats returns the stats per speaker & per participant, the speakers
+// of a diarized track are summed up to their participant.
+func buildTalkStats(list []*redisservice.TalkStats) (speakers, participants []*TalkStatsInfo) {
+	byUser := make(map[string]*TalkStatsInfo)
+	var totalMs int64
+	for _, st := range list {
+		totalMs += st.TalkMs
+		speakers = append(speakers, &TalkStatsInfo{
+			UserId:        st.UserId,
+			Name:          st.Name,
+			SpeakerLabel:  st.SpeakerLabel,
+			TalkTimeSec:   float64(st.TalkMs) / 1000,
+			Words:         st.Words,
+			Utterances:    st.Utterances,
+			Interruptions: st.Interruptions,
+		})
+
+		p, ok := byUser[st.UserId]
+		if !ok {
+			p = &TalkStatsInfo{UserId: st.UserId, Name: st.Name}
+			byUser[st.UserId] = p
+			participants = append(participants, p)
+		}
+		p.TalkTimeSec += float64(st.TalkMs) / 1000
+		p.Words += st.Words
+		p.Utterances += st.Utterances
+		p.Interruptions += st.Interruptions
+	}
+
+	for _, l := range [][]*TalkStatsInfo{speakers, participants} {
+		for _, st := range l {
+			if st.TalkTimeSec > 0 {
+				st.WordsPerMinute = roundTalkStat(float64(st.Words) / (st.TalkTimeSec / 60))
+			}
+			if totalMs > 0 {
+				st.TalkTimePercent = roundTalkStat(st.TalkTimeSec * 1000 * 100 / float64(totalMs))
+			}
+			st.TalkTimeSec = roundTalkStat(st.TalkTimeSec)
+		}
+		slices.SortFunc(l, func(a, b *TalkStatsInfo) int {
+			return cmp.Or(cmp.Compare(b.TalkTimeSec, a.TalkTimeSec), strings.Compare(a.UserId+a.SpeakerLabel, b.UserId+b.SpeakerLabel))
+		})
+	}
+	return speakers, participants
+}
+
+func roundTalkStat(v float64) float64 {
+	return math.Round(v*100) / 100
+}
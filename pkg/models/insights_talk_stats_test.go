@@ -0,0 +1,55 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+)
+
+func TestBuildTalkStats(t *testing.T) {
+	speakers, participants := buildTalkStats([]*redisservice.TalkStats{
+		{SpeakerId: "user01", UserId: "user01", Name: "Alice", TalkMs: 30000, Words: 60, Utterances: 3, Interruptions: 1},
+		{SpeakerId: "sip01#Guest-1", UserId: "sip01", Name: "Phone", SpeakerLabel: "Guest-1", TalkMs: 60000, Words: 150, Utterances: 5},
+		{SpeakerId: "sip01#Guest-2", UserId: "sip01", Name: "Phone", SpeakerLabel: "Guest-2", TalkMs: 30000, Words: 40, Utterances: 2, Interruptions: 2},
+	})
+
+	if len(speakers) != 3 {
+		t.Fatalf("expected 3 speakers, got %d", len(speakers))
+	}
+	if s := speakers[0]; s.SpeakerLabel != "Guest-1" || s.TalkTimeSec != 60 || s.TalkTimePercent != 50 || s.WordsPerMinute != 150 {
+		t.Errorf("unexpected first speaker: %+v", s)
+	}
+	// same talk-time is sorted by the user id & label
+	if speakers[1].UserId != "sip01" || speakers[1].SpeakerLabel != "Guest-2" || speakers[2].UserId != "user01" {
+		t.Errorf("unexpected order: %+v, %+v", speakers[1], speakers[2])
+	}
+
+	if len(participants) != 2 {
+		t.Fatalf("expected 2 participants, got %d", len(participants))
+	}
+	p := participants[0]
+	if p.UserId != "sip01" || p.SpeakerLabel != "" || p.TalkTimeSec != 90 || p.TalkTimePercent != 75 {
+		t.Errorf("diarized speakers must be summed up: %+v", p)
+	}
+	if p.Words != 190 || p.Utterances != 7 || p.Interruptions != 2 || p.WordsPerMinute != 126.67 {
+		t.Errorf("unexpected participant counters: %+v", p)
+	}
+	if p := participants[1]; p.UserId != "user01" || p.TalkTimePercent != 25 || p.WordsPerMinute != 120 || p.Interruptions != 1 {
+		t.Errorf("unexpected second participant: %+v", p)
+	}
+
+	if s, p := buildTalkStats(nil); s != nil || p != nil {
+		t.Error("no stats expected without speakers")
+	}
+}
+
+func TestRenderTalkStatsCSVEscapesNames(t *testing.T) {
+	content, err := renderTalkStatsCSV([]*TalkStatsInfo{{UserId: "user01", Name: "=HYPERLINK(\"x\")", TalkTimeSec: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `"'=HYPERLINK(""x"")"`) {
+		t.Errorf("name must be escaped: %s", content)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/helpers"
 	dbservice "github.com/mynaparrot/plugnmeet-server/pkg/services/db"
 	livekitservice "github.com/mynaparrot/plugnmeet-server/pkg/services/livekit"
 	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
@@ -31,8 +32,14 @@ type JanitorModel struct {
 	lk          *livekitservice.LivekitService
 	rm          *RoomModel
 
-	artifactModel *ArtifactModel
-	logger        *logrus.Entry
+	webhookNotifier *helpers.WebhookNotifier
+
+	artifactModel  *ArtifactModel
+	recordingModel *RecordingModel
+	scheduleModel  *ScheduleModel
+	pollModel      *PollModel
+	insightsModel  *InsightsModel
+	logger         *logrus.Entry
 
 	// leader election for janitor
 	leaderLockVal string
@@ -42,17 +49,22 @@ type JanitorModel struct {
 
 type JanitorModelArgs struct {
 	fx.In
-	MainCtx       context.Context
-	App           *config.AppConfig
-	RDS           *redis.Client
-	JS            jetstream.JetStream
-	Ds            *dbservice.DatabaseService
-	Rs            *redisservice.RedisService
-	NatsService   *natsservice.NatsService
-	Lk            *livekitservice.LivekitService
-	Rm            *RoomModel
-	ArtifactModel *ArtifactModel
-	Logger        *logrus.Logger
+	MainCtx         context.Context
+	App             *config.AppConfig
+	RDS             *redis.Client
+	JS              jetstream.JetStream
+	Ds              *dbservice.DatabaseService
+	Rs              *redisservice.RedisService
+	NatsService     *natsservice.NatsService
+	Lk              *livekitservice.LivekitService
+	Rm              *RoomModel
+	WebhookNotifier *helpers.WebhookNotifier
+	ArtifactModel   *ArtifactModel
+	RecordingModel  *RecordingModel
+	ScheduleModel   *ScheduleModel
+	PollModel       *PollModel
+	InsightsModel   *InsightsModel
+	Logger          *logrus.Logger
 }
 
 // NewJanitorModel creates a new JanitorModel.
@@ -60,18 +72,23 @@ func NewJanitorModel(args JanitorModelArgs) *JanitorModel {
 	ctx, cancel := context.WithCancel(args.MainCtx)
 
 	return &JanitorModel{
-		ctx:           ctx,
-		cancel:        cancel,
-		app:           args.App,
-		rds:           args.RDS,
-		js:            args.JS,
-		ds:            args.Ds,
-		rs:            args.Rs,
-		lk:            args.Lk,
-		rm:            args.Rm,
-		artifactModel: args.ArtifactModel,
-		natsService:   args.NatsService,
-		logger:        args.Logger.WithField("model", "janitor"),
+		ctx:             ctx,
+		cancel:          cancel,
+		app:             args.App,
+		rds:             args.RDS,
+		js:              args.JS,
+		ds:              args.Ds,
+		rs:              args.Rs,
+		lk:              args.Lk,
+		rm:              args.Rm,
+		webhookNotifier: args.WebhookNotifier,
+		artifactModel:   args.ArtifactModel,
+		recordingModel:  args.RecordingModel,
+		scheduleModel:   args.ScheduleModel,
+		pollModel:       args.PollModel,
+		insightsModel:   args.InsightsModel,
+		natsService:     args.NatsService,
+		logger:          args.Logger.WithField("model", "janitor"),
 
 		leaderLockTTL: 1 * time.Minute,
 		leaderRenewal: 30 * time.Second,
@@ -145,6 +162,9 @@ func (m *JanitorModel) runJanitorTasks() {
 	nextRoomCheck := time.Now().Add(5 * time.Minute)
 	nextBackupCheck := time.Now().Add(time.Hour)
 	nextSummarizeCheck := time.Now().Add(5 * time.Minute)
+	nextScheduleCheck := time.Now()
+	nextInsightsBudgetCheck := time.Now()
+	nextWebhookDeliveryCheck := time.Now()
 
 	for {
 		select {
@@ -155,20 +175,37 @@ func (m *JanitorModel) runJanitorTasks() {
 			// These tasks run on their own schedule.
 			// The individual locks inside each task ensure safety if the leader changes mid-operation.
 			m.checkRoomWithDuration()
+			m.checkExpiredPolls()
 
+			if now.After(nextScheduleCheck) {
+				m.checkScheduledRooms()
+				nextScheduleCheck = time.Now().Add(30 * time.Second)
+			}
 			if now.After(nextUserCheck) {
 				m.checkOnlineUsersStatus()
 				nextUserCheck = time.Now().Add(time.Minute)
 			}
+			if now.After(nextWebhookDeliveryCheck) {
+				m.republishStaleWebhookDeliveries()
+				nextWebhookDeliveryCheck = time.Now().Add(5 * time.Minute)
+			}
 			if now.After(nextRoomCheck) {
 				m.activeRoomChecker()
 				nextRoomCheck = time.Now().Add(5 * time.Minute)
 			}
 			if now.After(nextBackupCheck) {
 				m.checkDelRecordingBackupPath()
+				m.checkRecordingRetention()
+				m.cleanupRecordingAccessLogs()
 				m.checkDelArtifactsBackupPath()
+				m.cleanupWebhookDeliveries()
 				nextBackupCheck = time.Now().Add(time.Hour)
 			}
+			m.insightsModel.RunLiveSummaries()
+			if now.After(nextInsightsBudgetCheck) {
+				m.checkInsightsBudgets()
+				nextInsightsBudgetCheck = time.Now().Add(m.app.Insights.GetBudgets().GetCheckInterval())
+			}
 			if now.After(nextSummarizeCheck) {
 				m.CheckInsightsPendingSummarizeJobs()
 				nextSummarizeCheck = time.Now().Add(5 * time.Minute)
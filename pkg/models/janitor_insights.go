@@ -111,3 +111,23 @@ func (m *JanitorModel) CheckInsightsPendingSummarizeJobs() {
 		}
 	}
 }
+
+// checkInsightsBudgets stops the insights services of the rooms which have reached their budget.
+// It's mainly for the running transcription sessions, as their usage grows without any request.
+func (m *JanitorModel) checkInsightsBudgets() {
+	if m.app.Insights == nil || !m.app.Insights.Enabled {
+		return
+	}
+
+	activeRooms, err := m.ds.GetActiveRoomsInfo(m.ctx, "")
+	if err != nil {
+		m.logger.WithError(err).Errorln("failed to get active rooms")
+		return
+	}
+	for i := range activeRooms {
+		if activeRooms[i].Sid == "" {
+			continue
+		}
+		m.insightsModel.EvaluateInsightsBudget(&activeRooms[i])
+	}
+}
@@ -0,0 +1,6 @@
+package models
+
+// checkExpiredPolls will close the timed polls once their deadline passes
+func (m *JanitorModel) checkExpiredPolls() {
+	m.pollModel.CloseExpiredPolls()
+}
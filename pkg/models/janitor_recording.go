@@ -5,9 +5,12 @@ import (
 	"path"
 	"time"
 
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
 	"github.com/sirupsen/logrus"
 )
 
+const recordingRetentionBatchSize = 200
+
 func (m *JanitorModel) checkDelRecordingBackupPath() {
 	if !m.app.RecorderInfo.EnableDelRecordingBackup {
 		// nothing to do
@@ -52,3 +55,109 @@ func (m *JanitorModel) checkDelRecordingBackupPath() {
 		}
 	}
 }
+
+// checkRecordingRetention deletes the recordings which are older than their retention days
+// using the regular delete process, so backup & delete hook will work as usual.
+// Before the deletion, recording_expiring webhook will be sent once, at least notify_before_days earlier.
+func (m *JanitorModel) checkRecordingRetention() {
+	rt := m.app.RecorderInfo.Retention
+	if rt == nil || !rt.Enabled {
+		return
+	}
+	log := m.logger.WithField("task", "checkRecordingRetention")
+
+	tenants, err := m.ds.GetTenantsWithRecordingRetention()
+	if err != nil {
+		log.WithError(err).Errorln("failed to get recording retention of tenants")
+		return
+	}
+	templates, err := m.ds.GetRoomTemplatesWithRecordingRetention()
+	if err != nil {
+		log.WithError(err).Errorln("failed to get recording retention of room templates")
+		return
+	}
+	policies := newRecordingRetentionPolicies(rt.PublishedDays, rt.UnpublishedDays, tenants, templates)
+	minDays := policies.minDays()
+	if minDays == 0 {
+		// everything will be kept forever
+		return
+	}
+
+	day := time.Hour * 24
+	notifyBefore := time.Duration(rt.NotifyBeforeDays) * day
+	now := time.Now()
+	// recordings newer than this can't be expiring yet
+	before := now.Add(-time.Duration(minDays)*day + notifyBefore).Unix()
+
+	var lastId uint64
+	for m.ctx.Err() == nil {
+		recordings, err := m.ds.GetRecordingsCreatedBefore(before, lastId, recordingRetentionBatchSize)
+		if err != nil {
+			log.WithError(err).Errorln("failed to get recordings")
+			return
+		}
+
+		for i := range recordings {
+			rec := &recordings[i]
+			lastId = rec.ID
+
+			days := policies.daysFor(rec)
+			if days == 0 {
+				continue
+			}
+			expireAt := time.Unix(rec.CreationTime, 0).Add(time.Duration(days) * day)
+			recLog := log.WithFields(logrus.Fields{
+				"recordId":      rec.RecordID,
+				"roomId":        rec.RoomID,
+				"tenantId":      rec.TenantId,
+				"templateId":    rec.TemplateId,
+				"retentionDays": days,
+			})
+
+			notifiedAt, err := m.rs.GetRecordingExpiringNotifiedAt(rec.RecordID)
+			if err != nil {
+				recLog.WithError(err).Errorln("failed to get recording_expiring notification")
+				continue
+			}
+
+			switch action, deleteAt := recordingRetentionActionFor(now, expireAt, notifiedAt, notifyBefore); action {
+			case recordingRetentionDelete:
+				recLog.Warnln("deleting expired recording")
+				if err := m.recordingModel.DeleteRecording(&plugnmeet.DeleteRecordingReq{RecordId: rec.RecordID}); err != nil {
+					recLog.WithError(err).Errorln("failed to delete expired recording")
+					continue
+				}
+				_ = m.rs.DeleteRecordingExpiringNotified(rec.RecordID)
+			case recordingRetentionNotify:
+				// keep the mark until the recording is gone
+				notify, err := m.rs.MarkRecordingExpiringNotified(rec.RecordID, now, time.Until(deleteAt)+day)
+				if err != nil {
+					recLog.WithError(err).Errorln("failed to mark recording_expiring notification")
+					continue
+				}
+				if notify {
+					m.recordingModel.sendRecordingExpiringWebhook(rec, deleteAt, recLog)
+				}
+			}
+		}
+
+		if len(recordings) < recordingRetentionBatchSize {
+			return
+		}
+	}
+}
+
+// cleanupRecordingAccessLogs removes the recording access logs older than the retention
+func (m *JanitorModel) cleanupRecordingAccessLogs() {
+	log := m.logger.WithField("task", "cleanupRecordingAccessLogs")
+
+	checkTime := time.Now().Add(-m.app.RecorderInfo.AccessLogRetention)
+	deleted, err := m.ds.DeleteRecordingAccessLogsBefore(checkTime)
+	if err != nil {
+		log.WithError(err).Errorln("failed to delete recording access logs")
+		return
+	}
+	if deleted > 0 {
+		log.Infof("deleted %d recording access logs", deleted)
+	}
+}
@@ -0,0 +1,6 @@
+package models
+
+// checkScheduledRooms will create rooms for the schedules which are about to start
+func (m *JanitorModel) checkScheduledRooms() {
+	m.scheduleModel.ProcessDueSchedules()
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// webhookStaleDeliveryAge is the time after a pending delivery will be published again
+const webhookStaleDeliveryAge = 10 * time.Minute
+
+// cleanupWebhookDeliveries removes the webhook delivery logs older than the retention
+func (m *JanitorModel) cleanupWebhookDeliveries() {
+	if !m.app.Client.WebhookConf.Enable {
+		return
+	}
+	log := m.logger.WithField("task", "cleanupWebhookDeliveries")
+
+	checkTime := time.Now().Add(-m.app.Client.WebhookConf.DeliveryLogRetention)
+	deleted, err := m.ds.DeleteWebhookDeliveriesBefore(checkTime)
+	if err != nil {
+		log.WithError(err).Errorln("failed to delete webhook delivery logs")
+		return
+	}
+	if deleted > 0 {
+		log.Infof("deleted %d webhook delivery logs", deleted)
+	}
+}
+
+// republishStaleWebhookDeliveries puts the deliveries which were lost from the queue in it again
+func (m *JanitorModel) republishStaleWebhookDeliveries() {
+	if m.webhookNotifier == nil {
+		return
+	}
+	log := m.logger.WithField("task", "republishStaleWebhookDeliveries")
+
+	published, err := m.webhookNotifier.RepublishStaleDeliveries(webhookStaleDeliveryAge)
+	if err != nil {
+		log.WithError(err).Errorln("failed to publish stale webhook deliveries")
+	}
+	if published > 0 {
+		log.Infof("published %d stale webhook deliveries again", published)
+	}
+}
@@ -17,6 +17,7 @@ type LtiV1Model struct {
 	app    *config.AppConfig
 	rm     *RoomModel
 	um     *UserModel
+	tm     *TemplateModel
 	logger *logrus.Entry
 }
 
@@ -27,6 +28,34 @@ type LtiClaims struct {
 	RoomId              string               `json:"room_id"`
 	RoomTitle           string               `json:"room_title"`
 	LtiCustomParameters *LtiCustomParameters `json:"lti_custom_parameters,omitempty"`
+	// TemplateId comes from custom_template_id launch param
+	TemplateId string `json:"template_id,omitempty"`
+	// V1p3 will be set only for LTI 1.3 launches
+	V1p3 *LtiV1p3Context `json:"lti_v1p3,omitempty"`
+	// Outcome will be set if the LMS accepts score for this user
+	Outcome *LtiOutcome `json:"lti_outcome,omitempty"`
+}
+
+// LtiTokenExtras are the claims of the LTI token on top of plugnmeet.LtiClaims
+type LtiTokenExtras struct {
+	TemplateId string          `json:"template_id,omitempty"`
+	V1p3       *LtiV1p3Context `json:"lti_v1p3,omitempty"`
+	Outcome    *LtiOutcome     `json:"lti_outcome,omitempty"`
+}
+
+// LtiOutcome is where the score of the user should be sent
+type LtiOutcome struct {
+	// LTI 1.1 Basic Outcomes
+	ServiceUrl string `json:"service_url,omitempty"`
+	SourcedId  string `json:"sourced_id,omitempty"`
+	// LTI 1.3 Assignment and Grade Services
+	Issuer         string `json:"issuer,omitempty"`
+	ClientId       string `json:"client_id,omitempty"`
+	ResourceLinkId string `json:"resource_link_id,omitempty"`
+	LineItem       string `json:"line_item,omitempty"`
+	LineItems      string `json:"line_items,omitempty"`
+	// sub of the user in the platform
+	PlatformUserId string `json:"platform_user_id,omitempty"`
 }
 
 type LtiCustomParameters struct {
@@ -60,6 +89,7 @@ type LtiV1ModelArgs struct {
 	App *config.AppConfig
 	Rm  *RoomModel
 	Um  *UserModel
+	Tm  *TemplateModel
 }
 
 func NewLtiV1Model(args LtiV1ModelArgs) *LtiV1Model {
@@ -67,6 +97,7 @@ func NewLtiV1Model(args LtiV1ModelArgs) *LtiV1Model {
 		app:    args.App,
 		rm:     args.Rm,
 		um:     args.Um,
+		tm:     args.Tm,
 		logger: args.Rm.logger.Logger.WithField("model", "lti_v1"),
 	}
 }
@@ -106,14 +137,30 @@ func (m *LtiV1Model) LTIV1Landing(c fiber.Ctx, requests, signingURL string) erro
 	}
 	utils.AssignLTIV1CustomParams(params, claims)
 
-	j, err := m.ToJWT(claims)
+	extras := &LtiTokenExtras{
+		TemplateId: params.Get("custom_template_id"),
+	}
+	// only learners will get score
+	if !claims.IsAdmin && params.Get("lis_outcome_service_url") != "" && params.Get("lis_result_sourcedid") != "" {
+		extras.Outcome = &LtiOutcome{
+			ServiceUrl: params.Get("lis_outcome_service_url"),
+			SourcedId:  params.Get("lis_result_sourcedid"),
+		}
+	}
+
+	j, err := m.ToJWT(claims, extras)
 	if err != nil {
 		return err
 	}
 
+	return m.renderLanding(c, claims, j)
+}
+
+// renderLanding renders the landing page of LTI client
+func (m *LtiV1Model) renderLanding(c fiber.Ctx, claims *plugnmeet.LtiClaims, token string) error {
 	vals := fiber.Map{
 		"Title":   claims.RoomTitle,
-		"Token":   j,
+		"Token":   token,
 		"IsAdmin": claims.IsAdmin,
 	}
 
@@ -12,43 +12,44 @@ import (
 	"github.com/jordic/lti"
 	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
 	"github.com/mynaparrot/plugnmeet-server/pkg/config"
-	"github.com/sirupsen/logrus"
 )
 
 func (m *LtiV1Model) VerifyAuth(requests, signingURL string) (*url.Values, error) {
 	r := strings.Split(requests, "&")
-	p := lti.NewProvider(m.app.Client.Secret, signingURL)
-	p.Method = "POST"
-	p.ConsumerKey = m.app.Client.ApiKey
+	var p *lti.Provider
 	var providedSignature string
 
-	for _, f := range r {
-		t := strings.Split(f, "=")
-		b, _ := url.QueryUnescape(t[1])
-		if t[0] == "oauth_signature" {
-			providedSignature = b
-		} else {
-			p.Add(t[0], b)
+	// LMS may still use the old secret during the grace period of a rotation
+	for _, secret := range m.rm.natsService.GetClientSecrets() {
+		p = lti.NewProvider(secret, signingURL)
+		p.Method = "POST"
+		p.ConsumerKey = m.app.Client.ApiKey
+
+		for _, f := range r {
+			t := strings.Split(f, "=")
+			b, _ := url.QueryUnescape(t[1])
+			if t[0] == "oauth_signature" {
+				providedSignature = b
+			} else {
+				p.Add(t[0], b)
+			}
 		}
-	}
 
-	if p.Get("oauth_consumer_key") != p.ConsumerKey {
-		return nil, config.InvalidConsumerKey
-	}
+		if p.Get("oauth_consumer_key") != p.ConsumerKey {
+			return nil, config.InvalidConsumerKey
+		}
 
-	sign, err := p.Sign()
-	if err != nil {
-		return nil, err
-	}
-	if sign != providedSignature {
-		m.logger.WithFields(logrus.Fields{
-			"calculated": sign,
-			"provided":   providedSignature,
-		}).WithError(err).Errorln("signature verification failed")
-		return nil, config.VerificationFailed
+		sign, err := p.Sign()
+		if err != nil {
+			return nil, err
+		}
+		if sign == providedSignature {
+			return new(p.Params()), nil
+		}
 	}
 
-	return new(p.Params()), nil
+	m.logger.WithField("provided", providedSignature).Errorln("signature verification failed")
+	return nil, config.VerificationFailed
 }
 
 func (m *LtiV1Model) genHashId(id string) string {
@@ -59,8 +60,9 @@ func (m *LtiV1Model) genHashId(id string) string {
 	return hash
 }
 
-func (m *LtiV1Model) ToJWT(c *plugnmeet.LtiClaims) (string, error) {
-	sig, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte(m.app.Client.Secret)},
+// ToJWT generates the token for the LTI client, extras is optional
+func (m *LtiV1Model) ToJWT(c *plugnmeet.LtiClaims, extras *LtiTokenExtras) (string, error) {
+	sig, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte(m.rm.natsService.GetClientSecret())},
 		(&jose.SignerOptions{}).WithType("JWT"))
 	if err != nil {
 		return "", err
@@ -73,7 +75,12 @@ func (m *LtiV1Model) ToJWT(c *plugnmeet.LtiClaims) (string, error) {
 		Subject:   c.UserId,
 	}
 
-	return jwt.Signed(sig).Claims(cl).Claims(c).Serialize()
+	builder := jwt.Signed(sig).Claims(cl).Claims(c)
+	if extras != nil {
+		builder = builder.Claims(extras)
+	}
+
+	return builder.Serialize()
 }
 
 func (m *LtiV1Model) LTIV1VerifyHeaderToken(token string) (*LtiClaims, error) {
@@ -84,7 +91,10 @@ func (m *LtiV1Model) LTIV1VerifyHeaderToken(token string) (*LtiClaims, error) {
 
 	out := jwt.Claims{}
 	claims := &LtiClaims{}
-	if err = tok.Claims([]byte(m.app.Client.Secret), &out, claims); err != nil {
+	err = verifyWithSecrets(m.rm.natsService.GetClientSecrets(), func(secret string) error {
+		return tok.Claims([]byte(secret), &out, claims)
+	})
+	if err != nil {
 		return nil, err
 	}
 	if err = out.Validate(jwt.Expected{Issuer: m.app.Client.ApiKey, Time: time.Now().UTC()}); err != nil {
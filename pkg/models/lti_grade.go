@@ -0,0 +1,392 @@
+package models
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jordic/lti"
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
+	"github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/fx"
+)
+
+const (
+	ltiAgsScoreScope        = "https://purl.imsglobal.org/spec/lti-ags/scope/score"
+	ltiAgsLineItemScope     = "https://purl.imsglobal.org/spec/lti-ags/scope/lineitem"
+	ltiAgsScoreMediaType    = "application/vnd.ims.lis.v1.score+json"
+	ltiAgsLineItemMediaType = "application/vnd.ims.lis.v2.lineitem+json"
+	ltiAgsLineItemsType     = "application/vnd.ims.lis.v2.lineitemcontainer+json"
+	ltiAgsAttendanceTag     = "plugnmeet_attendance"
+)
+
+// LtiGradeModel sends the attendance of LTI users back to the LMS as score
+type LtiGradeModel struct {
+	app         *config.AppConfig
+	rs          *redisservice.RedisService
+	natsService *natsservice.NatsService
+	ltiV1p3     *LtiV1p3Model
+	httpClient  *http.Client
+	logger      *logrus.Entry
+}
+
+type LtiGradeModelArgs struct {
+	fx.In
+	App         *config.AppConfig
+	Rs          *redisservice.RedisService
+	NatsService *natsservice.NatsService
+	LtiV1p3     *LtiV1p3Model
+	Logger      *logrus.Logger
+}
+
+// LtiGradeJob is a single score submission, it will be retried through the queue on failure
+type LtiGradeJob struct {
+	RoomId string `json:"room_id"`
+	UserId string `json:"user_id"`
+	// between 0 & 1
+	Score float64 `json:"score"`
+	// unix milliseconds, when the session was ended
+	Timestamp int64       `json:"timestamp"`
+	Outcome   *LtiOutcome `json:"outcome"`
+}
+
+func NewLtiGradeModel(args LtiGradeModelArgs) *LtiGradeModel {
+	return &LtiGradeModel{
+		app:         args.App,
+		rs:          args.Rs,
+		natsService: args.NatsService,
+		ltiV1p3:     args.LtiV1p3,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		logger:      args.Logger.WithField("model", "lti_grade"),
+	}
+}
+
+// saveOutcome keeps the outcome till the session ends
+func (m *LtiV1Model) saveOutcome(roomId, userId string, outcome *LtiOutcome) error {
+	val, err := json.Marshal(outcome)
+	if err != nil {
+		return err
+	}
+	return m.rm.rs.AddLtiOutcome(roomId, userId, val)
+}
+
+// PublishAttendanceScores calculates the attendance of LTI users from the analytics events
+// & adds the submission jobs to the queue. It must be called before analytics data was removed.
+func (m *LtiGradeModel) PublishAttendanceScores(result *plugnmeet.AnalyticsResult, log *logrus.Entry) {
+	if !m.app.LtiGradeSettings.Enabled || result == nil || result.Room == nil {
+		return
+	}
+	log = log.WithField("operation", "PublishAttendanceScores")
+
+	outcomes, err := m.rs.GetAndDeleteLtiOutcomes(result.Room.RoomId)
+	if err != nil {
+		log.WithError(err).Errorln("failed to get lti outcomes")
+		return
+	}
+	if len(outcomes) == 0 {
+		return
+	}
+
+	start := result.Room.RoomCreation * 1000
+	end := result.Room.RoomEnded * 1000
+	for _, u := range result.Users {
+		val, ok := outcomes[u.UserId]
+		if !ok {
+			continue
+		}
+		outcome := new(LtiOutcome)
+		if err := json.Unmarshal([]byte(val), outcome); err != nil {
+			log.WithError(err).Errorln("failed to unmarshal lti outcome")
+			continue
+		}
+
+		job := &LtiGradeJob{
+			RoomId:    result.Room.RoomId,
+			UserId:    u.UserId,
+			Score:     calculateAttendanceScore(u.Events, start, end),
+			Timestamp: end,
+			Outcome:   outcome,
+		}
+		data, err := json.Marshal(job)
+		if err != nil {
+			log.WithError(err).Errorln("failed to marshal lti grade job")
+			continue
+		}
+
+		msgId := fmt.Sprintf("%s_%d_%s", job.RoomId, job.Timestamp, job.UserId)
+		if err = m.natsService.PublishLtiGradeJob(msgId, data); err != nil {
+			log.WithError(err).WithField("user_id", u.UserId).Errorln("failed to publish lti grade job")
+		}
+	}
+}
+
+// calculateAttendanceScore returns the fraction of the session the user was present,
+// using the time of joined & left events
+func calculateAttendanceScore(events []*plugnmeet.AnalyticsEventData, start, end int64) float64 {
+	if end <= start {
+		return 0
+	}
+
+	type point struct {
+		time   int64
+		joined bool
+	}
+	var points []point
+	for _, e := range events {
+		if e.Name != "joined" && e.Name != "left" {
+			continue
+		}
+		for _, v := range e.Values {
+			points = append(points, point{time: v.Time, joined: e.Name == "joined"})
+		}
+	}
+	slices.SortFunc(points, func(a, b point) int {
+		return cmp.Compare(a.time, b.time)
+	})
+
+	var attended, since int64
+	for _, p := range points {
+		t := min(max(p.time, start), end)
+		if p.joined {
+			if since == 0 {
+				since = t
+			}
+		} else if since > 0 {
+			attended += t - since
+			since = 0
+		}
+	}
+	// never left, so till the end
+	if since > 0 {
+		attended += end - since
+	}
+
+	return min(float64(attended)/float64(end-start), 1)
+}
+
+// SubmitScore sends the score to the LMS
+func (m *LtiGradeModel) SubmitScore(job *LtiGradeJob) error {
+	if job.Outcome == nil {
+		return fmt.Errorf("outcome is missing")
+	}
+	if job.Outcome.ServiceUrl != "" {
+		return m.submitBasicOutcome(job)
+	}
+	return m.submitAgsScore(job)
+}
+
+// submitBasicOutcome uses LTI 1.1 Basic Outcomes service, the request is signed with OAuth 1.0 body hash
+func (m *LtiGradeModel) submitBasicOutcome(job *LtiGradeJob) error {
+	var sourcedId bytes.Buffer
+	if err := xml.EscapeText(&sourcedId, []byte(job.Outcome.SourcedId)); err != nil {
+		return err
+	}
+	body := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<imsx_POXEnvelopeRequest xmlns="http://www.imsglobal.org/services/ltiv1p1/xsd/imsoms_v1p0">
+<imsx_POXHeader><imsx_POXRequestHeaderInfo><imsx_version>V1.0</imsx_version><imsx_messageIdentifier>%s</imsx_messageIdentifier></imsx_POXRequestHeaderInfo></imsx_POXHeader>
+<imsx_POXBody><replaceResultRequest><resultRecord><sourcedGUID><sourcedId>%s</sourcedId></sourcedGUID><result><resultScore><language>en</language><textString>%.4f</textString></resultScore></result></resultRecord></replaceResultRequest></imsx_POXBody>
+</imsx_POXEnvelopeRequest>`, rand.Text(), sourcedId.String(), job.Score)
+
+	u, err := url.Parse(job.Outcome.ServiceUrl)
+	if err != nil {
+		return err
+	}
+	hash := sha1.Sum([]byte(body))
+
+	// query params are part of the signature, but not of the signing url
+	signingUrl := *u
+	signingUrl.RawQuery = ""
+	p := lti.NewProvider(m.natsService.GetClientSecret(), signingUrl.String())
+	p.Method = http.MethodPost
+	p.ConsumerKey = m.app.Client.ApiKey
+	for k := range u.Query() {
+		p.Add(k, u.Query().Get(k))
+	}
+	p.Add("oauth_body_hash", base64.StdEncoding.EncodeToString(hash[:]))
+	if _, err = p.Sign(); err != nil {
+		return err
+	}
+
+	var auth []string
+	for k := range p.Params() {
+		if strings.HasPrefix(k, "oauth_") {
+			auth = append(auth, fmt.Sprintf(`%s="%s"`, k, url.QueryEscape(p.Get(k))))
+		}
+	}
+	slices.Sort(auth)
+
+	req, err := http.NewRequest(http.MethodPost, job.Outcome.ServiceUrl, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+	req.Header.Set("Authorization", "OAuth "+strings.Join(auth, ", "))
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	res := new(struct {
+		CodeMajor   string `xml:"imsx_POXHeader>imsx_POXResponseHeaderInfo>imsx_statusInfo>imsx_codeMajor"`
+		Description string `xml:"imsx_POXHeader>imsx_POXResponseHeaderInfo>imsx_statusInfo>imsx_description"`
+	})
+	if err = xml.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(res); err != nil {
+		return fmt.Errorf("invalid response, status: %d: %w", resp.StatusCode, err)
+	}
+	if res.CodeMajor != "success" {
+		return fmt.Errorf("failed to submit score, code: %s, description: %s", res.CodeMajor, res.Description)
+	}
+
+	return nil
+}
+
+// submitAgsScore uses LTI 1.3 Assignment and Grade Services
+func (m *LtiGradeModel) submitAgsScore(job *LtiGradeJob) error {
+	o := job.Outcome
+	v1p3 := &LtiV1p3Context{
+		Issuer:         o.Issuer,
+		ClientId:       o.ClientId,
+		ResourceLinkId: o.ResourceLinkId,
+	}
+
+	lineItem := o.LineItem
+	if lineItem == "" {
+		var err error
+		if lineItem, err = m.findOrCreateLineItem(v1p3, o.LineItems); err != nil {
+			return err
+		}
+	}
+
+	token, err := m.ltiV1p3.getServiceToken(v1p3, ltiAgsScoreScope)
+	if err != nil {
+		return err
+	}
+
+	u, err := url.Parse(lineItem)
+	if err != nil {
+		return err
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/scores"
+
+	body, err := json.Marshal(map[string]any{
+		"userId":           o.PlatformUserId,
+		"scoreGiven":       job.Score * 100,
+		"scoreMaximum":     100,
+		"comment":          "Attendance",
+		"activityProgress": "Completed",
+		"gradingProgress":  "FullyGraded",
+		"timestamp":        time.UnixMilli(job.Timestamp).UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = m.doAgsRequest(http.MethodPost, u.String(), token, ltiAgsScoreMediaType, "", body)
+	return err
+}
+
+// findOrCreateLineItem returns the attendance line item of the resource link,
+// it will be created if the platform didn't provide one
+func (m *LtiGradeModel) findOrCreateLineItem(v1p3 *LtiV1p3Context, lineItems string) (string, error) {
+	if lineItems == "" {
+		return "", config.ErrLtiV1p3ServiceNotAvailable
+	}
+	token, err := m.ltiV1p3.getServiceToken(v1p3, ltiAgsLineItemScope)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(lineItems)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("resource_link_id", v1p3.ResourceLinkId)
+	q.Set("tag", ltiAgsAttendanceTag)
+	u.RawQuery = q.Encode()
+
+	res, err := m.doAgsRequest(http.MethodGet, u.String(), token, "", ltiAgsLineItemsType, nil)
+	if err != nil {
+		return "", err
+	}
+	var items []struct {
+		Id string `json:"id"`
+	}
+	if err = json.Unmarshal(res, &items); err != nil {
+		return "", err
+	}
+	if len(items) > 0 && items[0].Id != "" {
+		return items[0].Id, nil
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"scoreMaximum":   100,
+		"label":          "Attendance",
+		"tag":            ltiAgsAttendanceTag,
+		"resourceLinkId": v1p3.ResourceLinkId,
+	})
+	if err != nil {
+		return "", err
+	}
+	res, err = m.doAgsRequest(http.MethodPost, lineItems, token, ltiAgsLineItemMediaType, ltiAgsLineItemMediaType, body)
+	if err != nil {
+		return "", err
+	}
+	item := new(struct {
+		Id string `json:"id"`
+	})
+	if err = json.Unmarshal(res, item); err != nil {
+		return "", err
+	}
+	if item.Id == "" {
+		return "", fmt.Errorf("platform didn't return id of the created line item")
+	}
+
+	return item.Id, nil
+}
+
+func (m *LtiGradeModel) doAgsRequest(method, reqUrl, token, contentType, accept string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, reqUrl, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	res, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("request to %s failed, status: %d, body: %s", reqUrl, resp.StatusCode, string(res))
+	}
+
+	return res, nil
+}
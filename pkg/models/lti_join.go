@@ -7,13 +7,15 @@ import (
 	"github.com/mynaparrot/plugnmeet-protocol/utils"
 )
 
-func (m *LtiV1Model) LTIV1JoinRoom(ctx context.Context, c *plugnmeet.LtiClaims) (string, error) {
+// LTIV1JoinRoom will create the room if not active, using the template if templateId isn't empty.
+// outcome is optional, it will be used to send the attendance score after the session
+func (m *LtiV1Model) LTIV1JoinRoom(ctx context.Context, c *plugnmeet.LtiClaims, templateId string, outcome *LtiOutcome) (string, error) {
 	res, _, _ := m.rm.IsRoomActive(&plugnmeet.IsRoomActiveReq{
 		RoomId: c.RoomId,
 	})
 
 	if !res.GetIsActive() {
-		_, err := m.createRoomSession(ctx, c)
+		_, err := m.createRoomSession(ctx, c, templateId)
 		if err != nil {
 			return "", err
 		}
@@ -24,11 +26,25 @@ func (m *LtiV1Model) LTIV1JoinRoom(ctx context.Context, c *plugnmeet.LtiClaims)
 		return "", err
 	}
 
+	if outcome != nil && m.app.LtiGradeSettings.Enabled {
+		if err := m.saveOutcome(c.RoomId, c.UserId, outcome); err != nil {
+			// user can still join
+			m.logger.WithError(err).Errorln("failed to save lti outcome")
+		}
+	}
+
 	return token, nil
 }
 
-func (m *LtiV1Model) createRoomSession(userCtx context.Context, c *plugnmeet.LtiClaims) (*plugnmeet.ActiveRoomInfo, error) {
+func (m *LtiV1Model) createRoomSession(userCtx context.Context, c *plugnmeet.LtiClaims, templateId string) (*plugnmeet.ActiveRoomInfo, error) {
 	req := utils.PrepareLTIV1RoomCreateReq(c)
+	if templateId != "" {
+		var err error
+		req, err = m.tm.ApplyTemplateToReq("", templateId, req)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return m.rm.CreateRoom(userCtx, req)
 }
 
@@ -0,0 +1,329 @@
+package models
+
+import (
+	"cmp"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-protocol/utils"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/fx"
+)
+
+const (
+	ltiV1p3StateTTL     = time.Minute * 10
+	ltiV1p3Version      = "1.3.0"
+	ltiV1p3ResourceLink = "LtiResourceLinkRequest"
+	ltiV1p3DeepLinking  = "LtiDeepLinkingRequest"
+	// the cookie binds the state to the browser which started the login
+	ltiV1p3StateCookiePrefix = "pnm_lti1p3_state_"
+)
+
+type LtiV1p3Model struct {
+	app        *config.AppConfig
+	lti        *LtiV1Model
+	states     ltiV1p3StateStore
+	httpClient *http.Client
+	logger     *logrus.Entry
+
+	lock    sync.Mutex
+	keySets map[string]*ltiV1p3KeySet
+	tokens  map[string]*ltiV1p3ServiceToken
+}
+
+// LtiV1p3Context keeps the information of a LTI 1.3 launch,
+// which will be required later to call the services of the platform
+type LtiV1p3Context struct {
+	Issuer         string `json:"issuer"`
+	ClientId       string `json:"client_id"`
+	DeploymentId   string `json:"deployment_id"`
+	ContextId      string `json:"context_id"`
+	ResourceLinkId string `json:"resource_link_id"`
+	// Names and Role Provisioning Services
+	NrpsUrl string `json:"nrps_url,omitempty"`
+}
+
+// ltiV1p3StateStore keeps the state of the OIDC logins till the platform redirects back,
+// it's implemented by the RedisService
+type ltiV1p3StateStore interface {
+	SaveLtiV1p3State(state string, val []byte, ttl time.Duration) error
+	ConsumeLtiV1p3State(state string) ([]byte, error)
+}
+
+type ltiV1p3LoginState struct {
+	Nonce    string `json:"nonce"`
+	Issuer   string `json:"issuer"`
+	ClientId string `json:"client_id"`
+}
+
+type ltiV1p3IdTokenClaims struct {
+	// sub is the user id in the platform, comes from jwt.Claims
+	sub string
+
+	Nonce         string          `json:"nonce"`
+	Azp           string          `json:"azp,omitempty"`
+	Name          string          `json:"name"`
+	GivenName     string          `json:"given_name"`
+	FamilyName    string          `json:"family_name"`
+	Email         string          `json:"email"`
+	MessageType   string          `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+	Version       string          `json:"https://purl.imsglobal.org/spec/lti/claim/version"`
+	DeploymentId  string          `json:"https://purl.imsglobal.org/spec/lti/claim/deployment_id"`
+	TargetLinkUri string          `json:"https://purl.imsglobal.org/spec/lti/claim/target_link_uri"`
+	Roles         []string        `json:"https://purl.imsglobal.org/spec/lti/claim/roles"`
+	Custom        map[string]any  `json:"https://purl.imsglobal.org/spec/lti/claim/custom,omitempty"`
+	Context       *ltiV1p3Context `json:"https://purl.imsglobal.org/spec/lti/claim/context,omitempty"`
+	ResourceLink  *struct {
+		Id    string `json:"id"`
+		Title string `json:"title"`
+	} `json:"https://purl.imsglobal.org/spec/lti/claim/resource_link,omitempty"`
+	NamesRoleService *struct {
+		ContextMembershipsUrl string `json:"context_memberships_url"`
+	} `json:"https://purl.imsglobal.org/spec/lti-nrps/claim/namesroleservice,omitempty"`
+	AgsEndpoint *struct {
+		Scope     []string `json:"scope"`
+		LineItems string   `json:"lineitems"`
+		LineItem  string   `json:"lineitem"`
+	} `json:"https://purl.imsglobal.org/spec/lti-ags/claim/endpoint,omitempty"`
+	DeepLinkingSettings *ltiV1p3DeepLinkingSettings `json:"https://purl.imsglobal.org/spec/lti-dl/claim/deep_linking_settings,omitempty"`
+}
+
+type ltiV1p3Context struct {
+	Id    string `json:"id"`
+	Label string `json:"label"`
+	Title string `json:"title"`
+}
+
+type LtiV1p3LoginReq struct {
+	Issuer         string
+	LoginHint      string
+	TargetLinkUri  string
+	LtiMessageHint string
+	ClientId       string
+	DeploymentId   string
+}
+
+type LtiV1p3ModelArgs struct {
+	fx.In
+	App   *config.AppConfig
+	LtiV1 *LtiV1Model
+	Rs    *redisservice.RedisService
+}
+
+func NewLtiV1p3Model(args LtiV1p3ModelArgs) *LtiV1p3Model {
+	return &LtiV1p3Model{
+		app:        args.App,
+		lti:        args.LtiV1,
+		states:     args.Rs,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		logger:     args.LtiV1.logger.Logger.WithField("model", "lti_v1p3"),
+		keySets:    make(map[string]*ltiV1p3KeySet),
+		tokens:     make(map[string]*ltiV1p3ServiceToken),
+	}
+}
+
+func (m *LtiV1p3Model) getConfig() (*config.LtiV1p3Config, error) {
+	if m.app.LtiV1p3 == nil || !m.app.LtiV1p3.Enabled {
+		return nil, config.ErrLtiV1p3NotEnabled
+	}
+	return m.app.LtiV1p3, nil
+}
+
+// LTIV1p3Login handles the OIDC login initiation from the platform
+// & returns the url of the authentication request of the platform.
+func (m *LtiV1p3Model) LTIV1p3Login(c fiber.Ctx, req *LtiV1p3LoginReq, redirectUri string) (string, error) {
+	cnf, err := m.getConfig()
+	if err != nil {
+		return "", err
+	}
+	if req.Issuer == "" || req.LoginHint == "" {
+		return "", fmt.Errorf("iss & login_hint are required")
+	}
+
+	platform := cnf.GetPlatform(req.Issuer, req.ClientId)
+	if platform == nil {
+		return "", config.ErrLtiV1p3UnknownPlatform
+	}
+	// it's optional during login, the id_token will be checked again
+	if req.DeploymentId != "" && !platform.IsValidDeployment(req.DeploymentId) {
+		return "", fmt.Errorf("unknown deployment_id: %s", req.DeploymentId)
+	}
+	if !isLtiV1p3ToolUrl(req.TargetLinkUri, redirectUri) {
+		return "", fmt.Errorf("invalid target_link_uri: %s", req.TargetLinkUri)
+	}
+
+	state := rand.Text()
+	ls := &ltiV1p3LoginState{
+		Nonce:    rand.Text(),
+		Issuer:   platform.Issuer,
+		ClientId: platform.ClientId,
+	}
+	val, err := json.Marshal(ls)
+	if err != nil {
+		return "", err
+	}
+	if err = m.states.SaveLtiV1p3State(state, val, ltiV1p3StateTTL); err != nil {
+		return "", err
+	}
+	setLtiV1p3StateCookie(c, state, int(ltiV1p3StateTTL.Seconds()))
+
+	u, err := url.Parse(platform.AuthLoginUrl)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("scope", "openid")
+	q.Set("response_type", "id_token")
+	q.Set("response_mode", "form_post")
+	q.Set("prompt", "none")
+	q.Set("client_id", platform.ClientId)
+	q.Set("redirect_uri", redirectUri)
+	q.Set("login_hint", req.LoginHint)
+	q.Set("state", state)
+	q.Set("nonce", ls.Nonce)
+	if req.LtiMessageHint != "" {
+		q.Set("lti_message_hint", req.LtiMessageHint)
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// LTIV1p3Launch validates the id_token from the platform.
+// Resource link launches will render the same landing page as LTI 1.1
+// & deep linking requests will send back a meeting resource to the platform.
+func (m *LtiV1p3Model) LTIV1p3Launch(c fiber.Ctx, idToken, state, launchUrl string) error {
+	name, _ := ltiV1p3StateCookie(state)
+	platform, claims, err := m.validateIdToken(idToken, state, c.Cookies(name))
+	if err != nil {
+		return err
+	}
+	setLtiV1p3StateCookie(c, state, -1)
+	if claims.TargetLinkUri == "" || !isLtiV1p3ToolUrl(claims.TargetLinkUri, launchUrl) {
+		return fmt.Errorf("invalid target_link_uri: %s", claims.TargetLinkUri)
+	}
+
+	switch claims.MessageType {
+	case ltiV1p3ResourceLink:
+		return m.handleResourceLinkLaunch(c, platform, claims)
+	case ltiV1p3DeepLinking:
+		return m.handleDeepLinkingRequest(c, platform, claims, launchUrl)
+	}
+
+	return fmt.Errorf("unsupported message_type: %s", claims.MessageType)
+}
+
+func (m *LtiV1p3Model) handleResourceLinkLaunch(c fiber.Ctx, platform *config.LtiV1p3Platform, cl *ltiV1p3IdTokenClaims) error {
+	if cl.ResourceLink == nil || cl.ResourceLink.Id == "" {
+		return fmt.Errorf("resource_link claim is missing")
+	}
+	if cl.Context == nil {
+		cl.Context = new(ltiV1p3Context)
+	}
+
+	v1p3 := &LtiV1p3Context{
+		Issuer:         platform.Issuer,
+		ClientId:       platform.ClientId,
+		DeploymentId:   cl.DeploymentId,
+		ContextId:      cl.Context.Id,
+		ResourceLinkId: cl.ResourceLink.Id,
+	}
+	if cl.NamesRoleService != nil {
+		v1p3.NrpsUrl = cl.NamesRoleService.ContextMembershipsUrl
+	}
+
+	claims := &plugnmeet.LtiClaims{
+		UserId:    cl.sub,
+		Name:      cl.displayName(),
+		IsAdmin:   isLtiV1p3Instructor(cl.Roles),
+		RoomId:    m.lti.genHashId(fmt.Sprintf("%s_%s_%s_%s", platform.Issuer, cl.DeploymentId, v1p3.ContextId, v1p3.ResourceLinkId)),
+		RoomTitle: cmp.Or(cl.Context.Label, cl.Context.Title, cl.ResourceLink.Title),
+	}
+	if claims.UserId == "" {
+		return config.UserIdOrEmailRequired
+	}
+
+	// custom parameters are same as LTI 1.1 but without the custom_ prefix
+	params := url.Values{}
+	for k, v := range cl.Custom {
+		params.Set("custom_"+k, fmt.Sprintf("%v", v))
+	}
+	utils.AssignLTIV1CustomParams(&params, claims)
+
+	extras := &LtiTokenExtras{
+		TemplateId: params.Get("custom_template_id"),
+		V1p3:       v1p3,
+	}
+	// only learners will get score
+	if ags := cl.AgsEndpoint; !claims.IsAdmin && ags != nil && (ags.LineItem != "" || ags.LineItems != "") {
+		extras.Outcome = &LtiOutcome{
+			Issuer:         platform.Issuer,
+			ClientId:       platform.ClientId,
+			ResourceLinkId: v1p3.ResourceLinkId,
+			LineItem:       ags.LineItem,
+			LineItems:      ags.LineItems,
+			PlatformUserId: cl.sub,
+		}
+	}
+
+	j, err := m.lti.ToJWT(claims, extras)
+	if err != nil {
+		return err
+	}
+
+	return m.lti.renderLanding(c, claims, j)
+}
+
+func (cl *ltiV1p3IdTokenClaims) displayName() string {
+	name := strings.TrimSpace(cl.Name)
+	if name == "" {
+		name = strings.TrimSpace(cl.GivenName + " " + cl.FamilyName)
+	}
+	if name == "" {
+		name = fmt.Sprintf("%s_%s", "User", cl.sub)
+	}
+	return name
+}
+
+// isLtiV1p3Instructor checks both context & institution roles.
+// Roles can be full URIs or short names, e.g. in NRPS responses
+func isLtiV1p3Instructor(roles []string) bool {
+	for _, r := range roles {
+		if i := strings.LastIndexAny(r, "#/"); i >= 0 {
+			r = r[i+1:]
+		}
+		if r == "Instructor" || r == "Administrator" {
+			return true
+		}
+	}
+	return false
+}
+
+// isLtiV1p3ToolUrl checks that target_link_uri points to the launch url of this tool,
+// so that it can't be used to send the users to another site. Empty is allowed as
+// platforms may not send it during login, the claim of the id_token will be checked again.
+func isLtiV1p3ToolUrl(target, launchUrl string) bool {
+	if target == "" {
+		return true
+	}
+	t, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	l, err := url.Parse(launchUrl)
+	if err != nil {
+		return false
+	}
+	return t.Scheme == l.Scheme && strings.EqualFold(t.Host, l.Host) &&
+		strings.TrimSuffix(t.Path, "/") == strings.TrimSuffix(l.Path, "/")
+}
@@ -0,0 +1,330 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/gofiber/fiber/v3"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+)
+
+const (
+	ltiV1p3KeySetCacheTTL = time.Hour
+	// clock skew between the platform & us
+	ltiV1p3Leeway = time.Minute
+)
+
+var ltiV1p3SignatureAlgorithms = []jose.SignatureAlgorithm{jose.RS256, jose.RS384, jose.RS512}
+
+type ltiV1p3KeySet struct {
+	keys      *jose.JSONWebKeySet
+	fetchedAt time.Time
+}
+
+type ltiV1p3ServiceToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+// ltiV1p3StateCookie returns the name & value of the cookie of the state.
+// The name is unique per state, so parallel logins in the same browser won't overwrite each other.
+func ltiV1p3StateCookie(state string) (string, string) {
+	sum := sha256.Sum256([]byte(state))
+	hash := hex.EncodeToString(sum[:])
+	return ltiV1p3StateCookiePrefix + hash[:16], hash
+}
+
+// setLtiV1p3StateCookie sets the cookie of the state, negative maxAge removes it.
+// The launch is posted by the platform & the tool is usually inside its iframe,
+// so the cookie must be sent cross-site.
+func setLtiV1p3StateCookie(c fiber.Ctx, state string, maxAge int) {
+	name, value := ltiV1p3StateCookie(state)
+	if maxAge < 0 {
+		value = ""
+	}
+	c.Cookie(&fiber.Cookie{
+		Name:        name,
+		Value:       value,
+		MaxAge:      maxAge,
+		Secure:      true,
+		HTTPOnly:    true,
+		SameSite:    fiber.CookieSameSiteNoneMode,
+		Partitioned: true,
+	})
+}
+
+// validateIdToken follows the steps of the 1EdTech security framework
+// to validate the id_token sent by the platform after the OIDC login.
+// stateCookie is the value of the cookie set during the login.
+func (m *LtiV1p3Model) validateIdToken(idToken, state, stateCookie string) (*config.LtiV1p3Platform, *ltiV1p3IdTokenClaims, error) {
+	cnf, err := m.getConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	if idToken == "" || state == "" {
+		return nil, nil, fmt.Errorf("id_token & state are required")
+	}
+	// otherwise, the state of another browser could be used to log in the user (login CSRF)
+	if _, hash := ltiV1p3StateCookie(state); subtle.ConstantTimeCompare([]byte(stateCookie), []byte(hash)) != 1 {
+		return nil, nil, config.ErrLtiV1p3InvalidState
+	}
+
+	// state can be used only once
+	val, err := m.states.ConsumeLtiV1p3State(state)
+	if err != nil {
+		return nil, nil, err
+	}
+	if val == nil {
+		return nil, nil, config.ErrLtiV1p3InvalidState
+	}
+	ls := new(ltiV1p3LoginState)
+	if err = json.Unmarshal(val, ls); err != nil {
+		return nil, nil, err
+	}
+
+	platform := cnf.GetPlatform(ls.Issuer, ls.ClientId)
+	if platform == nil {
+		return nil, nil, config.ErrLtiV1p3UnknownPlatform
+	}
+
+	tok, err := jwt.ParseSigned(idToken, ltiV1p3SignatureAlgorithms)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tok.Headers) == 0 {
+		return nil, nil, fmt.Errorf("id_token has no header")
+	}
+
+	key, err := m.getPlatformKey(platform, tok.Headers[0].KeyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := jwt.Claims{}
+	claims := new(ltiV1p3IdTokenClaims)
+	if err = tok.Claims(key, &out, claims); err != nil {
+		return nil, nil, err
+	}
+	err = out.ValidateWithLeeway(jwt.Expected{
+		Issuer:      platform.Issuer,
+		AnyAudience: jwt.Audience{platform.ClientId},
+		Time:        time.Now().UTC(),
+	}, ltiV1p3Leeway)
+	if err != nil {
+		return nil, nil, err
+	}
+	if out.Expiry == nil || out.IssuedAt == nil {
+		return nil, nil, fmt.Errorf("exp & iat are required in id_token")
+	}
+	if len(out.Audience) > 1 && claims.Azp != platform.ClientId {
+		return nil, nil, fmt.Errorf("invalid azp in id_token")
+	}
+	if claims.Nonce != ls.Nonce {
+		return nil, nil, fmt.Errorf("invalid nonce in id_token")
+	}
+	if claims.Version != ltiV1p3Version {
+		return nil, nil, fmt.Errorf("unsupported lti version: %s", claims.Version)
+	}
+	if !platform.IsValidDeployment(claims.DeploymentId) {
+		return nil, nil, fmt.Errorf("unknown deployment_id: %s", claims.DeploymentId)
+	}
+	claims.sub = out.Subject
+
+	return platform, claims, nil
+}
+
+// getPlatformKey returns the public key of the platform from its JWKS.
+// The key set will be fetched again if the kid can't be found, as platforms rotate keys.
+func (m *LtiV1p3Model) getPlatformKey(platform *config.LtiV1p3Platform, kid string) (*jose.JSONWebKey, error) {
+	m.lock.Lock()
+	ks, ok := m.keySets[platform.KeySetUrl]
+	m.lock.Unlock()
+
+	if ok && time.Since(ks.fetchedAt) < ltiV1p3KeySetCacheTTL {
+		if key := findLtiV1p3Key(ks.keys, kid); key != nil {
+			return key, nil
+		}
+	}
+
+	keys, err := m.fetchPlatformKeySet(platform.KeySetUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	m.lock.Lock()
+	m.keySets[platform.KeySetUrl] = &ltiV1p3KeySet{
+		keys:      keys,
+		fetchedAt: time.Now(),
+	}
+	m.lock.Unlock()
+
+	if key := findLtiV1p3Key(keys, kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("no key found in platform's key set for kid: %s", kid)
+}
+
+func findLtiV1p3Key(keys *jose.JSONWebKeySet, kid string) *jose.JSONWebKey {
+	if kid == "" {
+		// without kid, only a single key can be used
+		if len(keys.Keys) == 1 {
+			return &keys.Keys[0]
+		}
+		return nil
+	}
+	if k := keys.Key(kid); len(k) > 0 {
+		return &k[0]
+	}
+	return nil
+}
+
+func (m *LtiV1p3Model) fetchPlatformKeySet(keySetUrl string) (*jose.JSONWebKeySet, error) {
+	resp, err := m.httpClient.Get(keySetUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch key set, status: %d", resp.StatusCode)
+	}
+
+	keys := new(jose.JSONWebKeySet)
+	if err = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// GetToolKeySet returns the public key of the tool, platforms will use it
+// to verify deep linking responses & client assertions
+func (m *LtiV1p3Model) GetToolKeySet() (*jose.JSONWebKeySet, error) {
+	cnf, err := m.getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &jose.JSONWebKeySet{
+		Keys: []jose.JSONWebKey{{
+			Key:       &cnf.PrivateKey.PublicKey,
+			KeyID:     cnf.KeyId,
+			Algorithm: string(jose.RS256),
+			Use:       "sig",
+		}},
+	}, nil
+}
+
+// signToolJWT signs the claims with the private key of the tool
+func (m *LtiV1p3Model) signToolJWT(claims ...any) (string, error) {
+	cnf, err := m.getConfig()
+	if err != nil {
+		return "", err
+	}
+
+	sig, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: cnf.PrivateKey},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader(jose.HeaderKey("kid"), cnf.KeyId))
+	if err != nil {
+		return "", err
+	}
+
+	builder := jwt.Signed(sig)
+	for _, c := range claims {
+		builder = builder.Claims(c)
+	}
+
+	return builder.Serialize()
+}
+
+// getServiceToken returns an access token to call the services of the platform
+// using the client credentials grant with a signed JWT assertion
+func (m *LtiV1p3Model) getServiceToken(v1p3 *LtiV1p3Context, scopes ...string) (string, error) {
+	cnf, err := m.getConfig()
+	if err != nil {
+		return "", err
+	}
+	platform := cnf.GetPlatform(v1p3.Issuer, v1p3.ClientId)
+	if platform == nil {
+		return "", config.ErrLtiV1p3UnknownPlatform
+	}
+	if platform.AuthTokenUrl == "" {
+		return "", fmt.Errorf("auth_token_url isn't configured for platform: %s", platform.Issuer)
+	}
+
+	slices.Sort(scopes)
+	scope := strings.Join(scopes, " ")
+	cacheKey := platform.Issuer + "|" + platform.ClientId + "|" + scope
+
+	m.lock.Lock()
+	st, ok := m.tokens[cacheKey]
+	m.lock.Unlock()
+	if ok && time.Now().Before(st.expiresAt) {
+		return st.token, nil
+	}
+
+	now := time.Now().UTC()
+	assertion, err := m.signToolJWT(jwt.Claims{
+		Issuer:   platform.ClientId,
+		Subject:  platform.ClientId,
+		Audience: jwt.Audience{platform.AuthTokenAudience},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		ID:       rand.Text(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+	form.Set("client_assertion", assertion)
+	form.Set("scope", scope)
+
+	resp, err := m.httpClient.PostForm(platform.AuthTokenUrl, form)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("failed to get access token, status: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	res := new(struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	})
+	if err = json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return "", err
+	}
+	if res.AccessToken == "" {
+		return "", fmt.Errorf("empty access token from platform")
+	}
+	if res.ExpiresIn <= 0 {
+		res.ExpiresIn = 3600
+	}
+
+	m.lock.Lock()
+	m.tokens[cacheKey] = &ltiV1p3ServiceToken{
+		token: res.AccessToken,
+		// renew a bit earlier, so it won't expire during the request
+		expiresAt: time.Now().Add(time.Duration(res.ExpiresIn)*time.Second - ltiV1p3Leeway),
+	}
+	m.lock.Unlock()
+
+	return res.AccessToken, nil
+}
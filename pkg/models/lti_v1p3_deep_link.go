@@ -0,0 +1,112 @@
+package models
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/rand"
+	"fmt"
+	"html/template"
+	"slices"
+	"time"
+
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/gofiber/fiber/v3"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+)
+
+const ltiV1p3DeepLinkingResponse = "LtiDeepLinkingResponse"
+
+type ltiV1p3DeepLinkingSettings struct {
+	DeepLinkReturnUrl string   `json:"deep_link_return_url"`
+	AcceptTypes       []string `json:"accept_types"`
+	Title             string   `json:"title,omitempty"`
+	Data              string   `json:"data,omitempty"`
+}
+
+type ltiV1p3ContentItem struct {
+	Type   string            `json:"type"`
+	Title  string            `json:"title"`
+	Url    string            `json:"url"`
+	Custom map[string]string `json:"custom,omitempty"`
+}
+
+type ltiV1p3DeepLinkingClaims struct {
+	Nonce        string                `json:"nonce"`
+	MessageType  string                `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+	Version      string                `json:"https://purl.imsglobal.org/spec/lti/claim/version"`
+	DeploymentId string                `json:"https://purl.imsglobal.org/spec/lti/claim/deployment_id"`
+	Data         string                `json:"https://purl.imsglobal.org/spec/lti-dl/claim/data,omitempty"`
+	ContentItems []*ltiV1p3ContentItem `json:"https://purl.imsglobal.org/spec/lti-dl/claim/content_items"`
+}
+
+// the JWT must be sent back to the platform by the browser using form POST
+var ltiV1p3DeepLinkingTmpl = template.Must(template.New("deep_linking").Parse(`<!DOCTYPE html>
+<html>
+<head><title>plugNmeet</title></head>
+<body onload="document.forms[0].submit()">
+<form method="POST" action="{{.ReturnUrl}}">
+<input type="hidden" name="JWT" value="{{.JWT}}"/>
+<noscript><button type="submit">Continue</button></noscript>
+</form>
+</body>
+</html>`))
+
+// handleDeepLinkingRequest creates a meeting resource link in the platform.
+// Every placement of the link will get its own room as the room id depends on resource_link_id.
+func (m *LtiV1p3Model) handleDeepLinkingRequest(c fiber.Ctx, platform *config.LtiV1p3Platform, cl *ltiV1p3IdTokenClaims, launchUrl string) error {
+	settings := cl.DeepLinkingSettings
+	if settings == nil || settings.DeepLinkReturnUrl == "" {
+		return fmt.Errorf("deep_linking_settings claim is missing")
+	}
+	if !isLtiV1p3Instructor(cl.Roles) {
+		return fmt.Errorf("only instructors can create meeting")
+	}
+	if !slices.Contains(settings.AcceptTypes, "ltiResourceLink") {
+		return fmt.Errorf("platform doesn't accept ltiResourceLink")
+	}
+
+	title := settings.Title
+	if cl.Context != nil {
+		title = cmp.Or(title, cl.Context.Title, cl.Context.Label)
+	}
+
+	item := &ltiV1p3ContentItem{
+		Type:  "ltiResourceLink",
+		Title: cmp.Or(title, "plugNmeet meeting"),
+		Url:   launchUrl,
+	}
+	// keep the template of the deep linking request for the meeting
+	if templateId, ok := cl.Custom["template_id"].(string); ok && templateId != "" {
+		item.Custom = map[string]string{"template_id": templateId}
+	}
+
+	now := time.Now().UTC()
+	token, err := m.signToolJWT(jwt.Claims{
+		Issuer:   platform.ClientId,
+		Audience: jwt.Audience{platform.Issuer},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(5 * time.Minute)),
+	}, &ltiV1p3DeepLinkingClaims{
+		Nonce:        rand.Text(),
+		MessageType:  ltiV1p3DeepLinkingResponse,
+		Version:      ltiV1p3Version,
+		DeploymentId: cl.DeploymentId,
+		Data:         settings.Data,
+		ContentItems: []*ltiV1p3ContentItem{item},
+	})
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	err = ltiV1p3DeepLinkingTmpl.Execute(&buf, map[string]string{
+		"ReturnUrl": settings.DeepLinkReturnUrl,
+		"JWT":       token,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.Type("html")
+	return c.Send(buf.Bytes())
+}
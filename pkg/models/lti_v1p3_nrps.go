@@ -0,0 +1,94 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+)
+
+const (
+	ltiV1p3NrpsScope     = "https://purl.imsglobal.org/spec/lti-nrps/scope/contextmembership.readonly"
+	ltiV1p3NrpsMediaType = "application/vnd.ims.lti-nrps.v2.membershipcontainer+json"
+	// to avoid endless loop with a misbehaving platform
+	ltiV1p3NrpsMaxPages = 50
+)
+
+var ltiV1p3NextLinkRegex = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="next"`)
+
+type LtiV1p3Member struct {
+	UserId     string   `json:"user_id"`
+	Name       string   `json:"name,omitempty"`
+	GivenName  string   `json:"given_name,omitempty"`
+	FamilyName string   `json:"family_name,omitempty"`
+	Email      string   `json:"email,omitempty"`
+	Status     string   `json:"status,omitempty"`
+	Roles      []string `json:"roles"`
+	IsAdmin    bool     `json:"is_admin"`
+}
+
+// LTIV1p3FetchMemberships returns the members of the course using
+// Names and Role Provisioning Services of the platform
+func (m *LtiV1p3Model) LTIV1p3FetchMemberships(v1p3 *LtiV1p3Context) ([]*LtiV1p3Member, error) {
+	if v1p3 == nil || v1p3.NrpsUrl == "" {
+		return nil, config.ErrLtiV1p3ServiceNotAvailable
+	}
+
+	token, err := m.getServiceToken(v1p3, ltiV1p3NrpsScope)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []*LtiV1p3Member
+	next := v1p3.NrpsUrl
+	for i := 0; next != "" && i < ltiV1p3NrpsMaxPages; i++ {
+		var list []*LtiV1p3Member
+		list, next, err = m.fetchMembershipsPage(next, token)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, list...)
+	}
+
+	return members, nil
+}
+
+func (m *LtiV1p3Model) fetchMembershipsPage(pageUrl, token string) ([]*LtiV1p3Member, string, error) {
+	req, err := http.NewRequest(http.MethodGet, pageUrl, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", ltiV1p3NrpsMediaType)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, "", fmt.Errorf("failed to fetch memberships, status: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	res := new(struct {
+		Members []*LtiV1p3Member `json:"members"`
+	})
+	if err = json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return nil, "", err
+	}
+	for _, mb := range res.Members {
+		mb.IsAdmin = isLtiV1p3Instructor(mb.Roles)
+	}
+
+	var next string
+	if match := ltiV1p3NextLinkRegex.FindStringSubmatch(resp.Header.Get("Link")); len(match) == 2 {
+		next = match[1]
+	}
+
+	return res.Members, next, nil
+}
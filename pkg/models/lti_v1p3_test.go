@@ -0,0 +1,463 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/gofiber/fiber/v3"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	testLtiClientId     = "client-1"
+	testLtiDeploymentId = "deployment-1"
+	testLtiPlatformKid  = "platform-kid"
+	testLtiLaunchUrl    = "https://tool.example.com/lti/v1p3/launch"
+)
+
+type memLtiV1p3States struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func (s *memLtiV1p3States) SaveLtiV1p3State(state string, val []byte, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[state] = val
+	return nil
+}
+
+func (s *memLtiV1p3States) ConsumeLtiV1p3State(state string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	val, ok := s.values[state]
+	if !ok {
+		return nil, nil
+	}
+	delete(s.values, state)
+	return val, nil
+}
+
+// testLtiPlatform is a fake LMS, its JWKS is served by an httptest server
+type testLtiPlatform struct {
+	key    *rsa.PrivateKey
+	server *httptest.Server
+	issuer string
+}
+
+func newTestLtiPlatform(t *testing.T) *testLtiPlatform {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &testLtiPlatform{key: key}
+	p.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jwks" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+			Key:       &key.PublicKey,
+			KeyID:     testLtiPlatformKid,
+			Algorithm: string(jose.RS256),
+			Use:       "sig",
+		}}})
+	}))
+	t.Cleanup(p.server.Close)
+	p.issuer = p.server.URL
+	return p
+}
+
+// sign creates an id_token, modify can change the claims before signing
+func (p *testLtiPlatform) sign(t *testing.T, nonce string, modify func(c *jwt.Claims, cl map[string]any)) string {
+	t.Helper()
+	sig, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: p.key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader(jose.HeaderKey("kid"), testLtiPlatformKid))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	c := jwt.Claims{
+		Issuer:   p.issuer,
+		Subject:  "user-1",
+		Audience: jwt.Audience{testLtiClientId},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(5 * time.Minute)),
+	}
+	cl := map[string]any{
+		"nonce": nonce,
+		"name":  "Test User",
+		"https://purl.imsglobal.org/spec/lti/claim/message_type":    ltiV1p3ResourceLink,
+		"https://purl.imsglobal.org/spec/lti/claim/version":         ltiV1p3Version,
+		"https://purl.imsglobal.org/spec/lti/claim/deployment_id":   testLtiDeploymentId,
+		"https://purl.imsglobal.org/spec/lti/claim/target_link_uri": testLtiLaunchUrl,
+		"https://purl.imsglobal.org/spec/lti/claim/roles": []string{
+			"http://purl.imsglobal.org/vocab/lis/v2/membership#Instructor",
+		},
+		"https://purl.imsglobal.org/spec/lti/claim/resource_link": map[string]string{"id": "link-1"},
+		"https://purl.imsglobal.org/spec/lti/claim/context":       map[string]string{"id": "course-1", "title": "Course"},
+	}
+	if modify != nil {
+		modify(&c, cl)
+	}
+
+	token, err := jwt.Signed(sig).Claims(c).Claims(cl).Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func newTestLtiV1p3Model(t *testing.T, p *testLtiPlatform) *LtiV1p3Model {
+	t.Helper()
+	toolKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &LtiV1p3Model{
+		app: &config.AppConfig{
+			LtiV1p3: &config.LtiV1p3Config{
+				Enabled: true,
+				Platforms: []config.LtiV1p3Platform{{
+					Issuer:        p.issuer,
+					ClientId:      testLtiClientId,
+					DeploymentIds: []string{testLtiDeploymentId},
+					AuthLoginUrl:  p.issuer + "/auth",
+					KeySetUrl:     p.issuer + "/jwks",
+				}},
+				PrivateKey: toolKey,
+				KeyId:      "tool-kid",
+			},
+		},
+		states:     &memLtiV1p3States{values: make(map[string][]byte)},
+		httpClient: p.server.Client(),
+		logger:     logrus.NewEntry(logrus.New()),
+		keySets:    make(map[string]*ltiV1p3KeySet),
+		tokens:     make(map[string]*ltiV1p3ServiceToken),
+	}
+}
+
+// startLogin runs the OIDC login like the browser & returns the redirect url with the cookies set
+func startLogin(t *testing.T, m *LtiV1p3Model, req *LtiV1p3LoginReq) (string, []*http.Cookie, error) {
+	t.Helper()
+	var redirect string
+	var loginErr error
+	app := fiber.New()
+	app.Get("/login", func(c fiber.Ctx) error {
+		redirect, loginErr = m.LTIV1p3Login(c, req, testLtiLaunchUrl)
+		return nil
+	})
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/login", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return redirect, res.Cookies(), loginErr
+}
+
+// login starts the OIDC login & returns the state & nonce sent to the platform with the state cookie
+func login(t *testing.T, m *LtiV1p3Model, p *testLtiPlatform) (string, string, *http.Cookie) {
+	t.Helper()
+	redirect, cookies, err := startLogin(t, m, &LtiV1p3LoginReq{
+		Issuer:        p.issuer,
+		LoginHint:     "hint",
+		TargetLinkUri: testLtiLaunchUrl,
+		ClientId:      testLtiClientId,
+	})
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	if len(cookies) != 1 {
+		t.Fatalf("expected the state cookie, got %v", cookies)
+	}
+	u, err := url.Parse(redirect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Query().Get("state"), u.Query().Get("nonce"), cookies[0]
+}
+
+func TestLtiV1p3Login(t *testing.T) {
+	p := newTestLtiPlatform(t)
+	m := newTestLtiV1p3Model(t, p)
+
+	redirect, cookies, err := startLogin(t, m, &LtiV1p3LoginReq{
+		Issuer:         p.issuer,
+		LoginHint:      "hint",
+		TargetLinkUri:  testLtiLaunchUrl,
+		LtiMessageHint: "message-hint",
+	})
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+
+	u, err := url.Parse(redirect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Scheme + "://" + u.Host + u.Path; got != p.issuer+"/auth" {
+		t.Errorf("expected redirect to auth_login_url, got %s", got)
+	}
+	q := u.Query()
+	for k, v := range map[string]string{
+		"scope":            "openid",
+		"response_type":    "id_token",
+		"response_mode":    "form_post",
+		"prompt":           "none",
+		"client_id":        testLtiClientId,
+		"redirect_uri":     testLtiLaunchUrl,
+		"login_hint":       "hint",
+		"lti_message_hint": "message-hint",
+	} {
+		if q.Get(k) != v {
+			t.Errorf("expected %s=%s, got %s", k, v, q.Get(k))
+		}
+	}
+	if q.Get("state") == "" || q.Get("nonce") == "" {
+		t.Fatal("state & nonce are required")
+	}
+
+	name, hash := ltiV1p3StateCookie(q.Get("state"))
+	if len(cookies) != 1 || cookies[0].Name != name || cookies[0].Value != hash {
+		t.Fatalf("expected state cookie %s=%s, got %v", name, hash, cookies)
+	}
+	if c := cookies[0]; !c.Secure || !c.HttpOnly || c.SameSite != http.SameSiteNoneMode || c.MaxAge <= 0 {
+		t.Errorf("state cookie must be secure & sent cross-site: %+v", c)
+	}
+
+	val, _ := m.states.ConsumeLtiV1p3State(q.Get("state"))
+	ls := new(ltiV1p3LoginState)
+	if err := json.Unmarshal(val, ls); err != nil {
+		t.Fatalf("state wasn't saved: %v", err)
+	}
+	if ls.Nonce != q.Get("nonce") || ls.Issuer != p.issuer || ls.ClientId != testLtiClientId {
+		t.Errorf("unexpected saved state: %+v", ls)
+	}
+}
+
+func TestLtiV1p3LoginRejects(t *testing.T) {
+	p := newTestLtiPlatform(t)
+	m := newTestLtiV1p3Model(t, p)
+
+	tests := []struct {
+		name string
+		req  *LtiV1p3LoginReq
+	}{
+		{"missing login_hint", &LtiV1p3LoginReq{Issuer: p.issuer}},
+		{"unknown issuer", &LtiV1p3LoginReq{Issuer: "https://other.example.com", LoginHint: "hint"}},
+		{"unknown client", &LtiV1p3LoginReq{Issuer: p.issuer, LoginHint: "hint", ClientId: "other"}},
+		{"unknown deployment", &LtiV1p3LoginReq{Issuer: p.issuer, LoginHint: "hint", DeploymentId: "other"}},
+		{"foreign target_link_uri", &LtiV1p3LoginReq{Issuer: p.issuer, LoginHint: "hint", TargetLinkUri: "https://evil.example.com/lti/v1p3/launch"}},
+		{"other path of the tool", &LtiV1p3LoginReq{Issuer: p.issuer, LoginHint: "hint", TargetLinkUri: "https://tool.example.com/other"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, cookies, err := startLogin(t, m, tt.req); err == nil || len(cookies) != 0 {
+				t.Errorf("expected error without cookie, got %v %v", err, cookies)
+			}
+		})
+	}
+}
+
+func TestLtiV1p3ValidateIdToken(t *testing.T) {
+	p := newTestLtiPlatform(t)
+	m := newTestLtiV1p3Model(t, p)
+
+	state, nonce, cookie := login(t, m, p)
+	platform, claims, err := m.validateIdToken(p.sign(t, nonce, nil), state, cookie.Value)
+	if err != nil {
+		t.Fatalf("valid id_token was rejected: %v", err)
+	}
+	if platform.Issuer != p.issuer {
+		t.Errorf("unexpected platform: %s", platform.Issuer)
+	}
+	if claims.sub != "user-1" || claims.DeploymentId != testLtiDeploymentId || claims.ResourceLink.Id != "link-1" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+	if !isLtiV1p3Instructor(claims.Roles) {
+		t.Error("expected instructor role")
+	}
+
+	// the state can be used only once
+	if _, _, err = m.validateIdToken(p.sign(t, nonce, nil), state, cookie.Value); !errors.Is(err, config.ErrLtiV1p3InvalidState) {
+		t.Errorf("expected invalid state on reuse, got %v", err)
+	}
+}
+
+func TestLtiV1p3ValidateIdTokenRequiresStateCookie(t *testing.T) {
+	p := newTestLtiPlatform(t)
+	m := newTestLtiV1p3Model(t, p)
+
+	// the state of the attacker posted to the browser of the victim
+	state, nonce, cookie := login(t, m, p)
+	_, _, victimCookie := login(t, m, p)
+	for _, value := range []string{"", victimCookie.Value, state} {
+		if _, _, err := m.validateIdToken(p.sign(t, nonce, nil), state, value); !errors.Is(err, config.ErrLtiV1p3InvalidState) {
+			t.Errorf("cookie %q: expected invalid state, got %v", value, err)
+		}
+	}
+
+	// the state is kept for its own browser
+	if _, _, err := m.validateIdToken(p.sign(t, nonce, nil), state, cookie.Value); err != nil {
+		t.Errorf("state with its cookie was rejected: %v", err)
+	}
+}
+
+func TestLtiV1p3ValidateIdTokenRejects(t *testing.T) {
+	p := newTestLtiPlatform(t)
+	m := newTestLtiV1p3Model(t, p)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *jwt.Claims, cl map[string]any)
+		sign   func(t *testing.T, nonce string) string
+	}{
+		{name: "wrong nonce", modify: func(_ *jwt.Claims, cl map[string]any) {
+			cl["nonce"] = "other"
+		}},
+		{name: "wrong audience", modify: func(c *jwt.Claims, _ map[string]any) {
+			c.Audience = jwt.Audience{"other-client"}
+		}},
+		{name: "wrong issuer", modify: func(c *jwt.Claims, _ map[string]any) {
+			c.Issuer = "https://other.example.com"
+		}},
+		{name: "expired", modify: func(c *jwt.Claims, _ map[string]any) {
+			c.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+			c.Expiry = jwt.NewNumericDate(time.Now().Add(-time.Hour + time.Minute))
+		}},
+		{name: "azp required with multiple audiences", modify: func(c *jwt.Claims, _ map[string]any) {
+			c.Audience = jwt.Audience{testLtiClientId, "other-client"}
+		}},
+		{name: "unknown deployment", modify: func(_ *jwt.Claims, cl map[string]any) {
+			cl["https://purl.imsglobal.org/spec/lti/claim/deployment_id"] = "other"
+		}},
+		{name: "wrong version", modify: func(_ *jwt.Claims, cl map[string]any) {
+			cl["https://purl.imsglobal.org/spec/lti/claim/version"] = "1.1"
+		}},
+		{name: "signed by another key", sign: func(t *testing.T, nonce string) string {
+			other := &testLtiPlatform{key: otherKey, issuer: p.issuer}
+			return other.sign(t, nonce, nil)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, nonce, cookie := login(t, m, p)
+			var token string
+			if tt.sign != nil {
+				token = tt.sign(t, nonce)
+			} else {
+				token = p.sign(t, nonce, tt.modify)
+			}
+			if _, _, err := m.validateIdToken(token, state, cookie.Value); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestLtiV1p3LaunchRejectsForeignTargetLinkUri(t *testing.T) {
+	p := newTestLtiPlatform(t)
+	m := newTestLtiV1p3Model(t, p)
+
+	for _, target := range []string{"", "https://evil.example.com/lti/v1p3/launch"} {
+		state, nonce, cookie := login(t, m, p)
+		token := p.sign(t, nonce, func(_ *jwt.Claims, cl map[string]any) {
+			cl["https://purl.imsglobal.org/spec/lti/claim/target_link_uri"] = target
+		})
+
+		app := fiber.New()
+		app.Post("/launch", func(c fiber.Ctx) error {
+			return m.LTIV1p3Launch(c, token, state, testLtiLaunchUrl)
+		})
+		req := httptest.NewRequest(http.MethodPost, "/launch", nil)
+		req.AddCookie(cookie)
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode == http.StatusOK {
+			t.Errorf("target_link_uri %q should be rejected", target)
+		}
+	}
+}
+
+func TestLtiV1p3DeepLinking(t *testing.T) {
+	p := newTestLtiPlatform(t)
+	m := newTestLtiV1p3Model(t, p)
+
+	state, nonce, cookie := login(t, m, p)
+	returnUrl := p.issuer + "/deep_link_return"
+	token := p.sign(t, nonce, func(_ *jwt.Claims, cl map[string]any) {
+		cl["https://purl.imsglobal.org/spec/lti/claim/message_type"] = ltiV1p3DeepLinking
+		cl["https://purl.imsglobal.org/spec/lti/claim/custom"] = map[string]any{"template_id": "tpl-1"}
+		cl["https://purl.imsglobal.org/spec/lti-dl/claim/deep_linking_settings"] = map[string]any{
+			"deep_link_return_url": returnUrl,
+			"accept_types":         []string{"ltiResourceLink"},
+			"data":                 "opaque",
+		}
+	})
+
+	app := fiber.New()
+	app.Post("/launch", func(c fiber.Ctx) error {
+		return m.LTIV1p3Launch(c, token, state, testLtiLaunchUrl)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/launch", nil)
+	req.AddCookie(cookie)
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", res.StatusCode, body)
+	}
+	if !strings.Contains(string(body), `action="`+returnUrl+`"`) {
+		t.Errorf("form must be posted to deep_link_return_url: %s", body)
+	}
+
+	match := regexp.MustCompile(`name="JWT" value="([^"]+)"`).FindStringSubmatch(string(body))
+	if match == nil {
+		t.Fatalf("JWT not found in response: %s", body)
+	}
+	tok, err := jwt.ParseSigned(match[1], ltiV1p3SignatureAlgorithms)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := jwt.Claims{}
+	dl := new(ltiV1p3DeepLinkingClaims)
+	if err = tok.Claims(&m.app.LtiV1p3.PrivateKey.PublicKey, &out, dl); err != nil {
+		t.Fatalf("response must be signed by the tool key: %v", err)
+	}
+	if out.Issuer != testLtiClientId || !out.Audience.Contains(p.issuer) {
+		t.Errorf("unexpected iss/aud: %s %v", out.Issuer, out.Audience)
+	}
+	if dl.MessageType != ltiV1p3DeepLinkingResponse || dl.DeploymentId != testLtiDeploymentId || dl.Data != "opaque" {
+		t.Errorf("unexpected deep linking claims: %+v", dl)
+	}
+	if len(dl.ContentItems) != 1 || dl.ContentItems[0].Url != testLtiLaunchUrl || dl.ContentItems[0].Custom["template_id"] != "tpl-1" {
+		t.Errorf("unexpected content items: %+v", dl.ContentItems)
+	}
+}
@@ -0,0 +1,387 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goccy/go-json"
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	PollTypeSingle   = "single"
+	PollTypeMultiple = "multiple"
+	PollTypeText     = "text"
+	PollTypeRating   = "rating"
+	PollTypeQuiz     = "quiz"
+
+	maxPollRatingScale  = 10
+	maxPollAnswerLength = 2000
+	// pollClosedBySystem will be used as closed_by when a timed poll was closed by deadline
+	pollClosedBySystem = "system"
+)
+
+// PollSettings are the options of the poll which aren't part of plugnmeet.PollInfo.
+// Polls created by CreatePoll don't have settings & are treated as PollTypeSingle.
+type PollSettings struct {
+	Type          string `json:"type"`
+	RatingScale   uint64 `json:"rating_scale,omitempty"`
+	CorrectOption uint64 `json:"correct_option,omitempty"`
+	IsAnonymous   bool   `json:"is_anonymous"`
+	// Deadline in unix timestamp, poll will be closed automatically after it
+	Deadline int64 `json:"deadline,omitempty"`
+}
+
+type PollOptionReq struct {
+	Id   uint64 `json:"id"`
+	Text string `json:"text"`
+}
+
+type CreateAdvancedPollReq struct {
+	RoomId   string           `json:"-"`
+	UserId   string           `json:"-"`
+	Question string           `json:"question"`
+	Type     string           `json:"type"`
+	Options  []*PollOptionReq `json:"options"`
+	// RatingScale is required for rating polls, options from 1 to RatingScale will be created
+	RatingScale uint64 `json:"rating_scale"`
+	// CorrectOption is required for quiz polls
+	CorrectOption uint64 `json:"correct_option"`
+	IsAnonymous   bool   `json:"is_anonymous"`
+	// DurationSec will make the poll timed if set
+	DurationSec uint64 `json:"duration_sec"`
+}
+
+type SubmitAdvancedPollResponseReq struct {
+	RoomId          string   `json:"-"`
+	UserId          string   `json:"-"`
+	PollId          string   `json:"poll_id"`
+	Name            string   `json:"name"`
+	SelectedOptions []uint64 `json:"selected_options"`
+	// Answer is required for free-text polls
+	Answer string `json:"answer"`
+}
+
+type PollQuizScore struct {
+	UserId   string `json:"user_id"`
+	Name     string `json:"name"`
+	Score    uint64 `json:"score"`
+	Answered uint64 `json:"answered"`
+}
+
+// PollAnonymousQuizScore is the result of an anonymous quiz, the users won't be shown
+type PollAnonymousQuizScore struct {
+	PollId   string `json:"poll_id"`
+	Answered uint64 `json:"answered"`
+	Correct  uint64 `json:"correct"`
+}
+
+type PollQuizScoresResult struct {
+	TotalQuizzes uint64 `json:"total_quizzes"`
+	// Scores of the users from the quizzes which aren't anonymous
+	Scores          []*PollQuizScore          `json:"scores"`
+	AnonymousScores []*PollAnonymousQuizScore `json:"anonymous_scores,omitempty"`
+}
+
+// CreateAdvancedPoll creates a poll of any type
+func (m *PollModel) CreateAdvancedPoll(r *CreateAdvancedPollReq) (string, error) {
+	if strings.TrimSpace(r.Question) == "" {
+		return "", errors.New("question is required")
+	}
+	if r.Type == "" {
+		r.Type = PollTypeSingle
+	}
+
+	settings := &PollSettings{
+		Type:        r.Type,
+		IsAnonymous: r.IsAnonymous,
+	}
+	var options []*plugnmeet.CreatePollOptions
+
+	switch r.Type {
+	case PollTypeSingle, PollTypeMultiple, PollTypeQuiz:
+		if len(r.Options) < 2 {
+			return "", errors.New("at least 2 options are required")
+		}
+		for _, o := range r.Options {
+			if o.Id == 0 || strings.TrimSpace(o.Text) == "" {
+				return "", errors.New("option id & text are required")
+			}
+			if slices.ContainsFunc(options, func(e *plugnmeet.CreatePollOptions) bool { return e.Id == o.Id }) {
+				return "", fmt.Errorf("duplicate option id %d", o.Id)
+			}
+			options = append(options, &plugnmeet.CreatePollOptions{Id: o.Id, Text: o.Text})
+		}
+		if r.Type == PollTypeQuiz {
+			if !slices.ContainsFunc(options, func(e *plugnmeet.CreatePollOptions) bool { return e.Id == r.CorrectOption }) {
+				return "", errors.New("correct_option must be one of the options")
+			}
+			settings.CorrectOption = r.CorrectOption
+		}
+	case PollTypeRating:
+		if r.RatingScale < 2 || r.RatingScale > maxPollRatingScale {
+			return "", fmt.Errorf("rating_scale must be between 2 and %d", maxPollRatingScale)
+		}
+		for i := uint64(1); i <= r.RatingScale; i++ {
+			options = append(options, &plugnmeet.CreatePollOptions{Id: i, Text: fmt.Sprintf("%d", i)})
+		}
+		settings.RatingScale = r.RatingScale
+	case PollTypeText:
+		// no options
+	default:
+		return "", fmt.Errorf("unsupported poll type %s", r.Type)
+	}
+
+	if r.DurationSec > 0 {
+		settings.Deadline = time.Now().Add(time.Duration(r.DurationSec) * time.Second).Unix()
+	}
+
+	return m.createPoll(&plugnmeet.CreatePollReq{
+		RoomId:   r.RoomId,
+		UserId:   r.UserId,
+		Question: r.Question,
+		Options:  options,
+	}, settings)
+}
+
+// GetPollSettings returns the settings of the poll,
+// default settings will be returned for polls which were created by CreatePoll
+func (m *PollModel) GetPollSettings(roomId, pollId string) (*PollSettings, error) {
+	s, err := m.rs.GetPollSettings(roomId, pollId)
+	if err != nil {
+		return nil, err
+	}
+	settings := &PollSettings{Type: PollTypeSingle}
+	if s == "" {
+		return settings, nil
+	}
+	if err = json.Unmarshal([]byte(s), settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// GetAllPollSettings returns the settings of all the polls of the room by poll id
+func (m *PollModel) GetAllPollSettings(roomId string) (map[string]*PollSettings, error) {
+	result, err := m.rs.GetAllPollSettings(roomId)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make(map[string]*PollSettings, len(result))
+	for id, s := range result {
+		settings := new(PollSettings)
+		if err = json.Unmarshal([]byte(s), settings); err != nil {
+			continue
+		}
+		list[id] = settings
+	}
+	return list, nil
+}
+
+// UserSubmitAdvancedResponse records the response of the user for any type of poll
+func (m *PollModel) UserSubmitAdvancedResponse(r *SubmitAdvancedPollResponseReq) error {
+	log := m.logger.WithFields(logrus.Fields{
+		"roomId": r.RoomId,
+		"userId": r.UserId,
+		"pollId": r.PollId,
+		"method": "UserSubmitAdvancedResponse",
+	})
+	log.Infoln("request to submit poll response")
+
+	pi, err := m.rs.GetPollInfoByPollId(r.RoomId, r.PollId)
+	if err != nil {
+		return err
+	}
+	if pi == "" {
+		return errors.New("poll not found")
+	}
+	info := new(plugnmeet.PollInfo)
+	if err = protojson.Unmarshal([]byte(pi), info); err != nil {
+		return err
+	}
+	if !info.IsRunning {
+		return errors.New("poll was closed")
+	}
+
+	settings, err := m.GetPollSettings(r.RoomId, r.PollId)
+	if err != nil {
+		return err
+	}
+	if settings.Deadline > 0 && time.Now().Unix() > settings.Deadline {
+		return errors.New("poll deadline has passed")
+	}
+
+	isValidOption := func(id uint64) bool {
+		return slices.ContainsFunc(info.Options, func(o *plugnmeet.CreatePollOptions) bool { return o.Id == id })
+	}
+
+	var answer string
+	switch settings.Type {
+	case PollTypeText:
+		answer = strings.TrimSpace(r.Answer)
+		if answer == "" {
+			return errors.New("answer is required")
+		}
+		if utf8.RuneCountInString(answer) > maxPollAnswerLength {
+			return fmt.Errorf("answer can't be longer than %d characters", maxPollAnswerLength)
+		}
+		r.SelectedOptions = nil
+	case PollTypeMultiple:
+		if len(r.SelectedOptions) == 0 {
+			return errors.New("at least one option must be selected")
+		}
+		slices.Sort(r.SelectedOptions)
+		r.SelectedOptions = slices.Compact(r.SelectedOptions)
+		for _, o := range r.SelectedOptions {
+			if !isValidOption(o) {
+				return fmt.Errorf("invalid option %d", o)
+			}
+		}
+	default:
+		if len(r.SelectedOptions) != 1 {
+			return errors.New("exactly one option must be selected")
+		}
+		if !isValidOption(r.SelectedOptions[0]) {
+			return fmt.Errorf("invalid option %d", r.SelectedOptions[0])
+		}
+	}
+
+	err = m.rs.AddPollResponseWithAnswer(r.RoomId, r.PollId, r.UserId, r.Name, r.SelectedOptions, answer)
+	if err != nil {
+		log.WithError(err).Errorln("failed to add poll response to redis")
+		return err
+	}
+
+	// send analytics
+	m.sendPollVotedAnalytics(r.RoomId, r.UserId, newPollVotedAnalytics(r.PollId, settings, nil, r.SelectedOptions), log)
+
+	log.Info("successfully submitted poll response")
+	return nil
+}
+
+// GetQuizScores returns the scores of the users of all the quiz polls of the room.
+// A user will get one point for every correct answer.
+func (m *PollModel) GetQuizScores(roomId string) (*PollQuizScoresResult, error) {
+	allSettings, err := m.GetAllPollSettings(roomId)
+	if err != nil {
+		return nil, err
+	}
+
+	respondents := make(map[string][]string)
+	for pollId, settings := range allSettings {
+		if settings.Type != PollTypeQuiz {
+			continue
+		}
+		if respondents[pollId], err = m.rs.GetPollAllRespondents(roomId, pollId); err != nil {
+			return nil, err
+		}
+	}
+
+	return calculateQuizScores(allSettings, respondents), nil
+}
+
+// calculateQuizScores counts the correct answers of the quizzes,
+// responses of anonymous quizzes are only counted per quiz without the users.
+func calculateQuizScores(allSettings map[string]*PollSettings, respondents map[string][]string) *PollQuizScoresResult {
+	res := &PollQuizScoresResult{
+		Scores: make([]*PollQuizScore, 0),
+	}
+
+	// map iteration is random, but the result should be stable
+	pollIds := make([]string, 0, len(allSettings))
+	for pollId, settings := range allSettings {
+		if settings.Type == PollTypeQuiz {
+			pollIds = append(pollIds, pollId)
+		}
+	}
+	slices.Sort(pollIds)
+
+	scores := make(map[string]*PollQuizScore)
+	for _, pollId := range pollIds {
+		settings := allSettings[pollId]
+		res.TotalQuizzes++
+
+		var anonymous *PollAnonymousQuizScore
+		if settings.IsAnonymous {
+			anonymous = &PollAnonymousQuizScore{PollId: pollId}
+			res.AnonymousScores = append(res.AnonymousScores, anonymous)
+		}
+
+		for _, r := range respondents[pollId] {
+			userId, selected, name, ok := parsePollRespondent(r)
+			if !ok {
+				continue
+			}
+			correct := slices.Contains(selected, settings.CorrectOption)
+			if anonymous != nil {
+				anonymous.Answered++
+				if correct {
+					anonymous.Correct++
+				}
+				continue
+			}
+
+			s, found := scores[userId]
+			if !found {
+				s = &PollQuizScore{UserId: userId, Name: name}
+				scores[userId] = s
+				res.Scores = append(res.Scores, s)
+			}
+			s.Answered++
+			if correct {
+				s.Score++
+			}
+		}
+	}
+
+	slices.SortStableFunc(res.Scores, func(a, b *PollQuizScore) int {
+		return int(b.Score) - int(a.Score)
+	})
+	return res
+}
+
+// CloseExpiredPolls closes the timed polls which deadline has passed
+func (m *PollModel) CloseExpiredPolls() {
+	list, err := m.rs.GetExpiredPollDeadlines(time.Now().Unix())
+	if err != nil {
+		m.logger.WithError(err).Errorln("failed to get expired poll deadlines")
+		return
+	}
+
+	for _, p := range list {
+		err := m.ClosePoll(&plugnmeet.ClosePollReq{
+			RoomId: p[0],
+			PollId: p[1],
+			UserId: pollClosedBySystem,
+		})
+		if err != nil {
+			// the poll may not exist anymore, so we won't try again
+			if err = m.rs.RemovePollDeadline(p[0], p[1]); err != nil {
+				m.logger.WithError(err).Errorln("failed to remove poll deadline")
+			}
+		}
+	}
+}
+
+// parsePollRespondent parses an entry of all_respondents.
+// format userId:option_id:name, option_id can be comma separated or empty.
+func parsePollRespondent(r string) (userId string, selected []uint64, name string, ok bool) {
+	p := strings.SplitN(r, ":", 3)
+	if len(p) < 3 {
+		return "", nil, "", false
+	}
+	for _, o := range strings.Split(p[1], ",") {
+		if id, err := strconv.ParseUint(o, 10, 64); err == nil {
+			selected = append(selected, id)
+		}
+	}
+	return p[0], selected, p[2], true
+}
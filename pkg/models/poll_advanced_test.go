@@ -0,0 +1,62 @@
+package models
+
+import "testing"
+
+func TestCalculateQuizScores(t *testing.T) {
+	settings := map[string]*PollSettings{
+		"quiz01": {Type: PollTypeQuiz, CorrectOption: 2},
+		"quiz02": {Type: PollTypeQuiz, CorrectOption: 1},
+		"anon01": {Type: PollTypeQuiz, CorrectOption: 1, IsAnonymous: true},
+		"poll01": {Type: PollTypeSingle},
+	}
+	respondents := map[string][]string{
+		"quiz01": {"user01:2:User 1", "user02:1:User 2"},
+		"quiz02": {"user01:1:User 1", "user02:1:User 2"},
+		"anon01": {"user01:1:User 1", "user02:2:User 2", "user03:1:User 3"},
+		"poll01": {"user01:1:User 1"},
+	}
+
+	res := calculateQuizScores(settings, respondents)
+	if res.TotalQuizzes != 3 {
+		t.Errorf("expected 3 quizzes, got %d", res.TotalQuizzes)
+	}
+	if len(res.Scores) != 2 {
+		t.Fatalf("users of anonymous quiz must not be listed, got %d scores", len(res.Scores))
+	}
+	if s := res.Scores[0]; s.UserId != "user01" || s.Score != 2 || s.Answered != 2 {
+		t.Errorf("unexpected first score: %+v", s)
+	}
+	if s := res.Scores[1]; s.UserId != "user02" || s.Score != 1 || s.Answered != 2 {
+		t.Errorf("unexpected second score: %+v", s)
+	}
+
+	if len(res.AnonymousScores) != 1 {
+		t.Fatalf("expected 1 anonymous quiz, got %d", len(res.AnonymousScores))
+	}
+	if a := res.AnonymousScores[0]; a.PollId != "anon01" || a.Answered != 3 || a.Correct != 2 {
+		t.Errorf("unexpected anonymous score: %+v", a)
+	}
+}
+
+func TestNewPollVotedAnalytics(t *testing.T) {
+	option := uint64(2)
+
+	v := newPollVotedAnalytics("poll01", &PollSettings{Type: PollTypeMultiple}, nil, []uint64{1, 3})
+	if v.PollId != "poll01" || len(v.SelectedOptions) != 2 || v.SelectedOption != nil {
+		t.Errorf("selected options must be kept: %+v", v)
+	}
+	v = newPollVotedAnalytics("poll02", &PollSettings{Type: PollTypeSingle}, &option, nil)
+	if v.SelectedOption == nil || *v.SelectedOption != 2 {
+		t.Errorf("selected option must be kept: %+v", v)
+	}
+
+	anonymous := &PollSettings{Type: PollTypeMultiple, IsAnonymous: true}
+	for _, v = range []*pollVotedAnalytics{
+		newPollVotedAnalytics("anon01", anonymous, nil, []uint64{1, 3}),
+		newPollVotedAnalytics("anon02", anonymous, &option, nil),
+	} {
+		if v.SelectedOption != nil || v.SelectedOptions != nil {
+			t.Errorf("anonymous poll must only record the poll id: %+v", v)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"github.com/goccy/go-json"
@@ -11,6 +12,10 @@ import (
 )
 
 func (m *PollModel) CreatePoll(r *plugnmeet.CreatePollReq) (string, error) {
+	return m.createPoll(r, nil)
+}
+
+func (m *PollModel) createPoll(r *plugnmeet.CreatePollReq, settings *PollSettings) (string, error) {
 	log := m.logger.WithFields(logrus.Fields{
 		"roomId": r.RoomId,
 		"userId": r.UserId,
@@ -28,6 +33,18 @@ func (m *PollModel) CreatePoll(r *plugnmeet.CreatePollReq) (string, error) {
 		return "", err
 	}
 
+	// settings should be available before clients know about the poll
+	if settings != nil {
+		marshal, err := json.Marshal(settings)
+		if err != nil {
+			return "", err
+		}
+		if err = m.rs.SavePollSettings(r.RoomId, r.PollId, string(marshal), settings.Deadline); err != nil {
+			log.WithError(err).Errorln("failed to save poll settings")
+			return "", err
+		}
+	}
+
 	err = m.natsService.BroadcastSystemEventToEveryoneExceptUserId(plugnmeet.NatsMsgServerToClientEvents_POLL_CREATED, r.RoomId, r.PollId, r.UserId)
 	if err != nil {
 		log.WithError(err).Errorln("error sending POLL_CREATED event")
@@ -90,32 +107,59 @@ func (m *PollModel) UserSubmitResponse(r *plugnmeet.SubmitPollResponseReq) error
 	})
 	log.Infoln("request to submit poll response")
 
-	err := m.rs.AddPollResponse(r)
+	settings, err := m.GetPollSettings(r.RoomId, r.PollId)
+	if err != nil {
+		return err
+	}
+	if settings.Type == PollTypeText || settings.Type == PollTypeMultiple {
+		return errors.New("this poll type requires submitAdvancedResponse")
+	}
+	if settings.Deadline > 0 && time.Now().Unix() > settings.Deadline {
+		return errors.New("poll deadline has passed")
+	}
+
+	err = m.rs.AddPollResponse(r)
 	if err != nil {
 		log.WithError(err).Errorln("failed to add poll response to redis")
 		return err
 	}
 
 	// send analytics
-	toRecord := struct {
-		PollId         string `json:"poll_id"`
-		SelectedOption uint64 `json:"selected_option"`
-	}{
-		PollId:         r.PollId,
-		SelectedOption: r.SelectedOption,
+	m.sendPollVotedAnalytics(r.RoomId, r.UserId, newPollVotedAnalytics(r.PollId, settings, &r.SelectedOption, nil), log)
+
+	log.Info("successfully submitted poll response")
+	return nil
+}
+
+// pollVotedAnalytics is the value of the voted poll analytics event of the user
+type pollVotedAnalytics struct {
+	PollId          string   `json:"poll_id"`
+	SelectedOption  *uint64  `json:"selected_option,omitempty"`
+	SelectedOptions []uint64 `json:"selected_options,omitempty"`
+}
+
+// newPollVotedAnalytics won't keep the selected options of an anonymous poll,
+// otherwise the analytics export would show who picked what.
+func newPollVotedAnalytics(pollId string, settings *PollSettings, selectedOption *uint64, selectedOptions []uint64) *pollVotedAnalytics {
+	v := &pollVotedAnalytics{PollId: pollId}
+	if !settings.IsAnonymous {
+		v.SelectedOption = selectedOption
+		v.SelectedOptions = selectedOptions
 	}
-	marshal, err := json.Marshal(toRecord)
+	return v
+}
+
+func (m *PollModel) sendPollVotedAnalytics(roomId, userId string, v *pollVotedAnalytics, log *logrus.Entry) {
+	marshal, err := json.Marshal(v)
 	if err != nil {
 		log.WithError(err).Errorln("failed to marshal analytics data")
+		return
 	}
 	m.analyticsModel.HandleEvent(&plugnmeet.AnalyticsDataMsg{
 		EventType: plugnmeet.AnalyticsEventType_ANALYTICS_EVENT_TYPE_USER,
 		EventName: plugnmeet.AnalyticsEvents_ANALYTICS_EVENT_USER_VOTED_POLL,
-		RoomId:    r.RoomId,
-		UserId:    &r.UserId,
+		RoomId:    roomId,
+		UserId:    &userId,
 		HsetValue: new(string(marshal)),
 	})
-
-	log.Info("successfully submitted poll response")
-	return nil
 }
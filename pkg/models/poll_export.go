@@ -0,0 +1,153 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/goccy/go-json"
+	"github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+	"github.com/sirupsen/logrus"
+)
+
+type PollResultsOption struct {
+	Id        uint64 `json:"id"`
+	Text      string `json:"text"`
+	VoteCount uint64 `json:"vote_count"`
+}
+
+type PollResultsRespondent struct {
+	UserId          string   `json:"user_id"`
+	Name            string   `json:"name"`
+	SelectedOptions []uint64 `json:"selected_options"`
+	Answer          string   `json:"answer,omitempty"`
+	IsCorrect       *bool    `json:"is_correct,omitempty"`
+}
+
+type PollResultsInfo struct {
+	PollId         string                   `json:"poll_id"`
+	Type           string                   `json:"type"`
+	IsAnonymous    bool                     `json:"is_anonymous"`
+	CorrectOption  uint64                   `json:"correct_option,omitempty"`
+	Question       string                   `json:"question"`
+	CreatedBy      string                   `json:"created_by"`
+	Created        int64                    `json:"created"`
+	IsRunning      bool                     `json:"is_running"`
+	TotalResponses uint64                   `json:"total_responses"`
+	Options        []*PollResultsOption     `json:"options"`
+	Respondents    []*PollResultsRespondent `json:"respondents"`
+}
+
+// SnapshotPollResults collects the results of all the polls of the room
+// & records them to analytics. It should be called before CleanUpPolls.
+func (m *PollModel) SnapshotPollResults(roomId string) ([]*PollResultsInfo, error) {
+	log := m.logger.WithFields(logrus.Fields{
+		"roomId": roomId,
+		"method": "SnapshotPollResults",
+	})
+
+	polls, err := m.ListPolls(roomId)
+	if err != nil {
+		return nil, err
+	}
+	if len(polls) == 0 {
+		return nil, nil
+	}
+	// ListPolls returns from hash, so order isn't guaranteed
+	sort.SliceStable(polls, func(i, j int) bool {
+		return polls[i].Created < polls[j].Created
+	})
+
+	allSettings, err := m.GetAllPollSettings(roomId)
+	if err != nil {
+		log.WithError(err).Warnln("failed to get poll settings")
+	}
+
+	results := make([]*PollResultsInfo, 0, len(polls))
+	for _, info := range polls {
+		settings, ok := allSettings[info.Id]
+		if !ok {
+			settings = &PollSettings{Type: PollTypeSingle}
+		}
+		res := &PollResultsInfo{
+			PollId:        info.Id,
+			Type:          settings.Type,
+			IsAnonymous:   settings.IsAnonymous,
+			CorrectOption: settings.CorrectOption,
+			Question:      info.Question,
+			CreatedBy:     info.CreatedBy,
+			Created:       info.Created,
+			IsRunning:     info.IsRunning,
+			Options:       make([]*PollResultsOption, 0, len(info.Options)),
+			Respondents:   make([]*PollResultsRespondent, 0),
+		}
+
+		counters, err := m.rs.GetPollCountersByPollId(roomId, info.Id)
+		if err != nil {
+			log.WithError(err).WithField("pollId", info.Id).Warnln("failed to get poll counters")
+		}
+		for _, opt := range info.Options {
+			c, _ := strconv.ParseUint(counters[fmt.Sprintf("%d%s", opt.Id, redisservice.PollCountSuffix)], 10, 64)
+			res.Options = append(res.Options, &PollResultsOption{
+				Id:        uint64(opt.Id),
+				Text:      opt.Text,
+				VoteCount: c,
+			})
+		}
+		res.TotalResponses, _ = strconv.ParseUint(counters[redisservice.PollTotalRespField], 10, 64)
+
+		if settings.IsAnonymous {
+			// only the counters will be kept
+			results = append(results, res)
+			continue
+		}
+
+		allRespondents, err := m.rs.GetPollAllRespondents(roomId, info.Id)
+		if err != nil {
+			log.WithError(err).WithField("pollId", info.Id).Warnln("failed to get poll respondents")
+		}
+		var answers map[string]string
+		if settings.Type == PollTypeText {
+			if answers, err = m.rs.GetPollAnswers(roomId, info.Id); err != nil {
+				log.WithError(err).WithField("pollId", info.Id).Warnln("failed to get poll answers")
+			}
+		}
+		for _, r := range allRespondents {
+			userId, selected, name, ok := parsePollRespondent(r)
+			if !ok {
+				continue
+			}
+			respondent := &PollResultsRespondent{
+				UserId:          userId,
+				Name:            name,
+				SelectedOptions: selected,
+				Answer:          answers[userId],
+			}
+			if settings.Type == PollTypeQuiz {
+				respondent.IsCorrect = new(slices.Contains(selected, settings.CorrectOption))
+			}
+			res.Respondents = append(res.Respondents, respondent)
+		}
+
+		results = append(results, res)
+	}
+
+	m.recordPollResultsToAnalytics(roomId, results, log)
+	return results, nil
+}
+
+// recordPollResultsToAnalytics keeps the results in analytics,
+// because polls will be removed before the analytics export starts.
+func (m *PollModel) recordPollResultsToAnalytics(roomId string, results []*PollResultsInfo, log *logrus.Entry) {
+	values := make([]string, 0, len(results))
+	for _, res := range results {
+		marshal, err := json.Marshal(res)
+		if err != nil {
+			log.WithError(err).Errorln("failed to marshal poll results")
+			continue
+		}
+		values = append(values, string(marshal))
+	}
+	m.analyticsModel.AddPollResults(roomId, values)
+}
@@ -3,8 +3,8 @@ package models
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
-	"strings"
 
 	"github.com/goccy/go-json"
 	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
@@ -46,13 +46,14 @@ func (m *PollModel) UserSelectedOption(roomId, pollId, userId string) (uint64, e
 
 	for i := 0; i < len(allRespondents); i++ {
 		// format userId:option_id:name
-		p := strings.Split(allRespondents[i], ":")
-		if p[0] == userId {
-			voted, err := strconv.ParseUint(p[1], 10, 64)
-			if err != nil {
-				return 0, err
+		uid, selected, _, ok := parsePollRespondent(allRespondents[i])
+		if ok && uid == userId {
+			if len(selected) == 0 {
+				// free-text poll
+				return 0, nil
 			}
-			return voted, nil
+			// for multiple selections, the first one will be returned
+			return selected[0], nil
 		}
 	}
 
@@ -77,10 +78,52 @@ func (m *PollModel) GetPollResponsesDetails(roomId, pollId string) (map[string]s
 		m.logger.WithError(err).Warn("could not fetch all_respondents list")
 	}
 
+	settings, err := m.GetPollSettings(roomId, pollId)
+	if err != nil {
+		return nil, err
+	}
+	if settings.IsAnonymous {
+		// nobody should know who selected what
+		allRespondents = []string{}
+	}
+
 	// Marshal the list into a JSON string to match the original output format
 	jsonRespondents, _ := json.Marshal(allRespondents)
 	result["all_respondents"] = string(jsonRespondents)
 
+	switch settings.Type {
+	case PollTypeText:
+		answers, err := m.rs.GetPollAnswers(roomId, pollId)
+		if err != nil {
+			return nil, err
+		}
+		if settings.IsAnonymous {
+			list := make([]string, 0, len(answers))
+			for _, a := range answers {
+				list = append(list, a)
+			}
+			jsonAnswers, _ := json.Marshal(list)
+			result["answers"] = string(jsonAnswers)
+		} else {
+			jsonAnswers, _ := json.Marshal(answers)
+			result["answers"] = string(jsonAnswers)
+		}
+	case PollTypeQuiz:
+		// score of every user, 1 for the correct answer
+		scores := make(map[string]int)
+		for _, r := range allRespondents {
+			if uid, selected, _, ok := parsePollRespondent(r); ok {
+				scores[uid] = 0
+				if slices.Contains(selected, settings.CorrectOption) {
+					scores[uid] = 1
+				}
+			}
+		}
+		jsonScores, _ := json.Marshal(scores)
+		result["scores"] = string(jsonScores)
+		result["correct_option"] = strconv.FormatUint(settings.CorrectOption, 10)
+	}
+
 	// Ensure total_resp is always present for backward compatibility.
 	if _, ok := result[redisservice.PollTotalRespField]; !ok {
 		result[redisservice.PollTotalRespField] = "0"
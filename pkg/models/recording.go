@@ -12,6 +12,7 @@ import (
 	"github.com/mynaparrot/plugnmeet-server/pkg/services/livekit"
 	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
 	"github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+	"github.com/mynaparrot/plugnmeet-server/pkg/services/storage"
 	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 	"github.com/sirupsen/logrus"
@@ -30,6 +31,9 @@ type RecordingModel struct {
 	um              *UserModel
 	webhookNotifier *helpers.WebhookNotifier
 	natsService     *natsservice.NatsService
+	storageService  *storageservice.StorageService
+	recorderStore   recorderStore
+	recorders       recorderDirectory
 	logger          *logrus.Entry
 }
 
@@ -42,6 +46,7 @@ type RecordingModelArgs struct {
 	Ds              *dbservice.DatabaseService
 	Rs              *redisservice.RedisService
 	NatsService     *natsservice.NatsService
+	StorageService  *storageservice.StorageService
 	AnalyticsModel  *AnalyticsModel
 	Um              *UserModel
 	WebhookNotifier *helpers.WebhookNotifier
@@ -60,6 +65,9 @@ func NewRecordingModel(args RecordingModelArgs) *RecordingModel {
 		um:              args.Um,
 		webhookNotifier: args.WebhookNotifier,
 		natsService:     args.NatsService,
+		storageService:  args.StorageService,
+		recorderStore:   args.Rs,
+		recorders:       args.NatsService,
 		logger:          args.Logger.WithField("model", "recording"),
 	}
 }
@@ -68,9 +76,13 @@ func (m *RecordingModel) ProcessRecorderEvent(r *plugnmeet.RecorderToPlugNmeet,
 	switch r.Task {
 	case plugnmeet.RecordingTasks_START_RECORDING:
 		m.recordingStarted(r)
+		m.consumeRecorderReservation(r)
+		m.keepRecordingWindowStart(r, m.logger.WithField("recordingId", r.RecordingId))
 
 	case plugnmeet.RecordingTasks_END_RECORDING:
 		m.recordingEnded(r)
+		m.renewRecorderReservation(r)
+		m.keepRecordingWindowEnd(r, m.logger.WithField("recordingId", r.RecordingId))
 
 	case plugnmeet.RecordingTasks_START_RTMP:
 		m.rtmpStarted(r)
@@ -85,6 +97,11 @@ func (m *RecordingModel) ProcessRecorderEvent(r *plugnmeet.RecorderToPlugNmeet,
 		}
 		// keep record of this file
 		m.addRecordingInfoFile(r, creation, roomInfo)
+		if err == nil {
+			log := m.logger.WithField("recordingId", r.RecordingId)
+			m.queueRecordingPostProcessing(r.RecordingId, log)
+			m.attachPendingRecordingChapters(r.RecordingId, log)
+		}
 	case plugnmeet.RecordingTasks_RECORDING_TRANSCODING_FINISHED:
 		// delete if we held any lock
 		_ = m.rs.DeleteLockKey(context.Background(), fmt.Sprintf(redisservice.MergeRecordingReqLockKey, r.GetRoomId()))
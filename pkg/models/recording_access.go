@@ -0,0 +1,255 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxUserAgentLen is the size of the user_agent column
+	maxUserAgentLen = 512
+	// requests of the same client & token within this window are counted as one access,
+	// the window will be extended by every request, e.g. while playing HLS.
+	recordingAccessWindow = 5 * time.Minute
+)
+
+// RecordingTokenRestrictions binds the download token, all the fields are optional.
+// Those are sent together with the body of /recording/getDownloadToken.
+type RecordingTokenRestrictions struct {
+	// ViewerId will be kept in the access logs
+	ViewerId string `json:"viewer_id,omitempty"`
+	// AllowedIps can contain IPs or CIDR ranges, e.g. 10.0.0.0/8
+	AllowedIps []string `json:"allowed_ips,omitempty"`
+	// MaxUses is the number of downloads or playbacks allowed, 0 means unlimited
+	MaxUses int `json:"max_uses,omitempty"`
+}
+
+func (r *RecordingTokenRestrictions) validate() error {
+	if r == nil {
+		return nil
+	}
+	if len(r.ViewerId) > 255 {
+		return fmt.Errorf("viewer_id can't be longer than 255 characters")
+	}
+	if r.MaxUses < 0 {
+		return fmt.Errorf("max_uses can't be negative, use 0 for unlimited")
+	}
+	for _, ip := range r.AllowedIps {
+		if _, err := parseIpRange(ip); err != nil {
+			return fmt.Errorf("invalid allowed_ips entry '%s'", ip)
+		}
+	}
+	return nil
+}
+
+// RecordingAccessReq is the information of the client requesting the file
+type RecordingAccessReq struct {
+	ClientIp  string
+	UserAgent string
+}
+
+type FetchRecordingAccessLogsReq struct {
+	RecordId string `json:"record_id"`
+	ViewerId string `json:"viewer_id"`
+	From     uint32 `json:"from"`
+	Limit    uint32 `json:"limit"`
+	OrderBy  string `json:"order_by"`
+}
+
+type RecordingAccessLogInfo struct {
+	RecordId  string `json:"record_id"`
+	FilePath  string `json:"file_path"`
+	ViewerId  string `json:"viewer_id,omitempty"`
+	TokenId   string `json:"token_id,omitempty"`
+	ClientIp  string `json:"client_ip"`
+	UserAgent string `json:"user_agent,omitempty"`
+	Created   int64  `json:"created"`
+}
+
+type FetchRecordingAccessLogsResult struct {
+	TotalLogs int64                     `json:"total_logs"`
+	From      uint32                    `json:"from"`
+	Limit     uint32                    `json:"limit"`
+	OrderBy   string                    `json:"order_by"`
+	LogsList  []*RecordingAccessLogInfo `json:"logs_list"`
+}
+
+// checkRecordingAccess verifies the restrictions of the token for the client for every request.
+// A new access of the client will be counted & written to the access logs, the following requests
+// of the same download or playback (range, HEAD, HLS playlists & segments) are part of it.
+func (m *RecordingModel) checkRecordingAccess(vt *verifiedDownloadToken, access *RecordingAccessReq, log *logrus.Entry) (int, error) {
+	if len(vt.extras.AllowedIps) > 0 && !isIpAllowed(access.ClientIp, vt.extras.AllowedIps) {
+		log.WithField("clientIp", access.ClientIp).Warnln("recording access from not allowed IP")
+		return fiber.StatusForbidden, errors.New("access from this IP is not allowed")
+	}
+
+	isNew, status, err := countRecordingTokenAccess(m.rs, vt, access, log)
+	if err != nil {
+		return status, err
+	}
+	if isNew {
+		m.addRecordingAccessLog(vt, access, log)
+	}
+	return fiber.StatusOK, nil
+}
+
+// recordingTokenCounter counts the accesses & uses of the download tokens, implemented by the redis service
+type recordingTokenCounter interface {
+	MarkRecordingTokenAccess(tokenId, client string, window time.Duration) (bool, error)
+	UnmarkRecordingTokenAccess(tokenId, client string) error
+	IncrementRecordingTokenUses(tokenId string, ttl time.Duration) (int64, error)
+}
+
+// countRecordingTokenAccess returns true if it is a new access of the client, which will be counted
+// against max_uses. A rejected client won't be marked, so its next request will be rejected too.
+func countRecordingTokenAccess(rc recordingTokenCounter, vt *verifiedDownloadToken, access *RecordingAccessReq, log *logrus.Entry) (bool, int, error) {
+	tokenId := vt.claims.ID
+	if tokenId == "" {
+		// issued by an older version
+		tokenId = vt.claims.Subject
+	}
+	tokenKey, clientKey := hashRecordingAccessKey(tokenId), hashRecordingAccessKey(access.ClientIp, access.UserAgent)
+	isNew, err := rc.MarkRecordingTokenAccess(tokenKey, clientKey, recordingAccessWindow)
+	if err != nil {
+		log.WithError(err).Errorln("failed to check the access of the token")
+		return false, fiber.StatusInternalServerError, errors.New("failed to verify token usage")
+	}
+	if !isNew || vt.extras.MaxUses <= 0 || vt.claims.ID == "" {
+		return isNew, fiber.StatusOK, nil
+	}
+
+	ttl := time.Minute
+	if vt.claims.Expiry != nil {
+		ttl = max(time.Until(vt.claims.Expiry.Time()), ttl)
+	}
+	uses, err := rc.IncrementRecordingTokenUses(vt.claims.ID, ttl)
+	if err == nil && uses <= int64(vt.extras.MaxUses) {
+		return true, fiber.StatusOK, nil
+	}
+
+	if unmarkErr := rc.UnmarkRecordingTokenAccess(tokenKey, clientKey); unmarkErr != nil {
+		log.WithError(unmarkErr).Errorln("failed to remove the access of the token")
+	}
+	if err != nil {
+		log.WithError(err).Errorln("failed to count the uses of the token")
+		return false, fiber.StatusInternalServerError, errors.New("failed to verify token usage")
+	}
+	return false, fiber.StatusForbidden, errors.New("token usage limit reached")
+}
+
+func (m *RecordingModel) addRecordingAccessLog(vt *verifiedDownloadToken, access *RecordingAccessReq, log *logrus.Entry) {
+	entry := &dbmodels.RecordingAccessLog{
+		RecordID:  vt.extras.RecordId,
+		FilePath:  vt.claims.Subject,
+		ViewerId:  vt.extras.ViewerId,
+		TokenId:   vt.claims.ID,
+		ClientIp:  access.ClientIp,
+		UserAgent: access.UserAgent,
+	}
+	if len(entry.UserAgent) > maxUserAgentLen {
+		entry.UserAgent = entry.UserAgent[:maxUserAgentLen]
+	}
+	if entry.RecordID != "" {
+		if rec, err := m.ds.GetRecording(entry.RecordID); err == nil && rec != nil {
+			entry.TenantId = rec.TenantId
+		}
+	}
+
+	if _, err := m.ds.InsertRecordingAccessLog(entry); err != nil {
+		// the file should be served anyway
+		log.WithError(err).Errorln("failed to add recording access log")
+	}
+}
+
+// FetchRecordingAccessLogs returns the access logs of the recordings of the tenant
+func (m *RecordingModel) FetchRecordingAccessLogs(tenantId string, r *FetchRecordingAccessLogsReq) (*FetchRecordingAccessLogsResult, error) {
+	if r.Limit <= 0 {
+		r.Limit = 20
+	} else if r.Limit > 100 {
+		r.Limit = 100
+	}
+	if r.OrderBy == "" {
+		r.OrderBy = "DESC"
+	}
+
+	data, total, err := m.ds.GetRecordingAccessLogs(tenantId, r.RecordId, r.ViewerId, uint64(r.From), uint64(r.Limit), &r.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, config.NotFoundErr
+	}
+
+	list := make([]*RecordingAccessLogInfo, 0, len(data))
+	for i := range data {
+		d := &data[i]
+		list = append(list, &RecordingAccessLogInfo{
+			RecordId:  d.RecordID,
+			FilePath:  d.FilePath,
+			ViewerId:  d.ViewerId,
+			TokenId:   d.TokenId,
+			ClientIp:  d.ClientIp,
+			UserAgent: d.UserAgent,
+			Created:   d.Created.Unix(),
+		})
+	}
+
+	return &FetchRecordingAccessLogsResult{
+		TotalLogs: total,
+		From:      r.From,
+		Limit:     r.Limit,
+		OrderBy:   r.OrderBy,
+		LogsList:  list,
+	}, nil
+}
+
+// hashRecordingAccessKey keeps the redis key short whatever the size of the values
+func hashRecordingAccessKey(values ...string) string {
+	h := sha256.New()
+	for _, v := range values {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// parseIpRange accepts a single IP or CIDR range
+func parseIpRange(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return p.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func isIpAllowed(clientIp string, allowed []string) bool {
+	addr, err := netip.ParseAddr(clientIp)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, a := range allowed {
+		if p, err := parseIpRange(a); err == nil && p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
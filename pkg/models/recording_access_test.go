@@ -0,0 +1,71 @@
+package models
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/gofiber/fiber/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// memoryTokenCounter works like the redis service, without the expiry
+type memoryTokenCounter struct {
+	marks map[string]bool
+	uses  map[string]int64
+}
+
+func (c *memoryTokenCounter) MarkRecordingTokenAccess(tokenId, client string, _ time.Duration) (bool, error) {
+	if c.marks[tokenId+client] {
+		return false, nil
+	}
+	c.marks[tokenId+client] = true
+	return true, nil
+}
+
+func (c *memoryTokenCounter) UnmarkRecordingTokenAccess(tokenId, client string) error {
+	delete(c.marks, tokenId+client)
+	return nil
+}
+
+func (c *memoryTokenCounter) IncrementRecordingTokenUses(tokenId string, _ time.Duration) (int64, error) {
+	c.uses[tokenId]++
+	return c.uses[tokenId], nil
+}
+
+func TestCountRecordingTokenAccess(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	log := logrus.NewEntry(logger)
+
+	rc := &memoryTokenCounter{marks: make(map[string]bool), uses: make(map[string]int64)}
+	vt := &verifiedDownloadToken{
+		claims: jwt.Claims{ID: "token01", Subject: "rec.mp4"},
+		extras: recordingTokenClaims{RecordId: "rec01", MaxUses: 1},
+	}
+	first := &RecordingAccessReq{ClientIp: "10.0.0.1", UserAgent: "player"}
+	second := &RecordingAccessReq{ClientIp: "10.0.0.2", UserAgent: "player"}
+
+	isNew, status, err := countRecordingTokenAccess(rc, vt, first, log)
+	if err != nil || !isNew || status != fiber.StatusOK {
+		t.Fatalf("first access should be allowed: %v %d %v", isNew, status, err)
+	}
+	// range requests of the same client are part of the same access
+	if isNew, _, err = countRecordingTokenAccess(rc, vt, first, log); err != nil || isNew {
+		t.Fatalf("following request should be allowed without counting: %v %v", isNew, err)
+	}
+
+	// the rejected client must be rejected again on retry
+	for i := 0; i < 2; i++ {
+		if _, status, err = countRecordingTokenAccess(rc, vt, second, log); err == nil || status != fiber.StatusForbidden {
+			t.Fatalf("retry %d of the second client should be rejected: %d %v", i, status, err)
+		}
+	}
+
+	// unlimited tokens are never rejected
+	vt.extras.MaxUses = 0
+	if _, status, err = countRecordingTokenAccess(rc, vt, &RecordingAccessReq{ClientIp: "10.0.0.3"}, log); err != nil || status != fiber.StatusOK {
+		t.Errorf("unlimited token should be allowed: %d %v", status, err)
+	}
+}
@@ -0,0 +1,400 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	recordingChaptersSuffix        = "_chapters.vtt"
+	recordingSpeakerTimelineSuffix = "_timeline.json"
+	// events closer than this will be merged into one chapter
+	minRecordingChapterGap = 10 * time.Second
+	// transcription chunks only have the time they were received,
+	// so we'll estimate the start using the number of words
+	estimatedSpeechPerWord = 400 * time.Millisecond
+	minSpeechSegment       = time.Second
+	maxSpeechSegment       = 30 * time.Second
+
+	// keys of the recording metadata extra_data
+	recordingChaptersMetadataKey        = "chapters_file"
+	recordingSpeakerTimelineMetadataKey = "speaker_timeline_file"
+)
+
+type recordingChapter struct {
+	StartMs int64  `json:"start_ms"`
+	Title   string `json:"title"`
+}
+
+type recordingSpeakerSegment struct {
+	UserId  string `json:"user_id"`
+	Name    string `json:"name"`
+	StartMs int64  `json:"start_ms"`
+	EndMs   int64  `json:"end_ms"`
+	Text    string `json:"text"`
+}
+
+type recordingSpeakerSummary struct {
+	UserId   string `json:"user_id"`
+	Name     string `json:"name"`
+	TotalMs  int64  `json:"total_ms"`
+	Segments int    `json:"segments"`
+}
+
+// recordingChaptersData is the timeline of a recording, all the times are relative to RecordingStarted
+type recordingChaptersData struct {
+	RecordingId      string                     `json:"recording_id"`
+	RoomId           string                     `json:"room_id"`
+	RoomSid          string                     `json:"room_sid"`
+	RecordingStarted int64                      `json:"recording_started"`
+	DurationMs       int64                      `json:"duration_ms"`
+	Chapters         []*recordingChapter        `json:"chapters"`
+	Speakers         []*recordingSpeakerSummary `json:"speakers"`
+	Segments         []*recordingSpeakerSegment `json:"segments"`
+}
+
+type timedTranscriptionChunk struct {
+	ts    int64 // unix milliseconds
+	chunk redisservice.TranscriptionChunk
+}
+
+// keepRecordingWindowStart stores the start time to align the chapters with the recording
+func (m *RecordingModel) keepRecordingWindowStart(r *plugnmeet.RecorderToPlugNmeet, log *logrus.Entry) {
+	if !m.app.RecorderInfo.EnableChapters || r.RecordingId == "" {
+		return
+	}
+	if err := m.rs.SetRecordingWindowStart(r.RoomId, r.RecordingId, time.Now().UnixMilli()); err != nil {
+		log.WithError(err).Errorln("failed to store recording start time")
+	}
+}
+
+// keepRecordingWindowEnd stores the end time of the recording
+func (m *RecordingModel) keepRecordingWindowEnd(r *plugnmeet.RecorderToPlugNmeet, log *logrus.Entry) {
+	if !m.app.RecorderInfo.EnableChapters || r.RecordingId == "" {
+		return
+	}
+	if err := m.rs.SetRecordingWindowEnd(r.RoomId, r.RecordingId, time.Now().UnixMilli()); err != nil {
+		log.WithError(err).Errorln("failed to store recording end time")
+	}
+}
+
+// GenerateRecordingChapters creates the chapters & speaker timeline for all the recordings of the session.
+// It must be called at the end of the session, before transcription history & analytics are cleaned up.
+// Those will be attached to the recording once it has been proceeded.
+func (m *RecordingModel) GenerateRecordingChapters(roomId, roomSid string, log *logrus.Entry) {
+	if !m.app.RecorderInfo.EnableChapters {
+		return
+	}
+	log = log.WithField("method", "GenerateRecordingChapters")
+
+	windows, err := m.rs.GetRecordingWindows(roomId)
+	if err != nil {
+		log.WithError(err).Errorln("failed to get recording windows")
+		return
+	}
+	if len(windows) == 0 {
+		return
+	}
+	defer m.rs.DeleteRecordingWindows(roomId)
+
+	events, err := m.analyticsModel.GetTimelineEvents(roomId)
+	if err != nil {
+		log.WithError(err).Warnln("failed to get analytics events, chapters will be generated without those")
+	}
+	history, err := m.rs.GetTranscriptionHistory(roomId)
+	if err != nil {
+		log.WithError(err).Warnln("failed to get transcription history, speaker timeline will be empty")
+	}
+	chunks := sortTranscriptionHistory(history)
+
+	now := time.Now().UnixMilli()
+	for _, w := range windows {
+		end := w.End
+		if end < w.Start {
+			// recorder may still be stopping
+			end = now
+		}
+		data := buildRecordingChapters(w.RecordingId, roomId, roomSid, w.Start, end, events, chunks)
+		if len(data.Chapters) < 2 && len(data.Segments) == 0 {
+			log.WithField("recordingId", w.RecordingId).Infoln("nothing happened during the recording, skipping chapters")
+			continue
+		}
+
+		marshal, err := json.Marshal(data)
+		if err != nil {
+			log.WithError(err).Errorln("failed to marshal recording chapters")
+			continue
+		}
+		if err := m.rs.SaveRecordingChapters(w.RecordingId, marshal); err != nil {
+			log.WithError(err).Errorln("failed to save recording chapters")
+			continue
+		}
+
+		// the recording may have been proceeded already
+		if rec, err := m.ds.GetRecording(w.RecordingId); err == nil && rec != nil {
+			m.attachPendingRecordingChapters(w.RecordingId, log)
+		}
+	}
+}
+
+// attachPendingRecordingChapters attaches the generated chapters, if any, to the recording
+func (m *RecordingModel) attachPendingRecordingChapters(recordingId string, log *logrus.Entry) {
+	if !m.app.RecorderInfo.EnableChapters {
+		return
+	}
+	log = log.WithFields(logrus.Fields{
+		"recordingId": recordingId,
+		"method":      "attachPendingRecordingChapters",
+	})
+
+	// only one caller should attach those
+	lock := m.rs.NewLock(fmt.Sprintf(redisservice.RecordingChaptersLockKey, recordingId), time.Minute*5)
+	acquired, err := lock.TryLock(m.ctx)
+	if err != nil {
+		log.WithError(err).Errorln("failed to acquire recording chapters lock")
+		return
+	}
+	if !acquired {
+		log.Infoln("chapters are being attached by another request")
+		return
+	}
+	defer lock.Unlock(m.ctx)
+
+	// the chapters will be kept until attached, so they can be tried again if it fails
+	marshal, err := m.rs.GetRecordingChapters(recordingId)
+	if err != nil {
+		log.WithError(err).Errorln("failed to get recording chapters")
+		return
+	}
+	if marshal == nil {
+		return
+	}
+
+	data := new(recordingChaptersData)
+	if err := json.Unmarshal(marshal, data); err != nil {
+		log.WithError(err).Errorln("failed to unmarshal recording chapters")
+		return
+	}
+	rec, err := m.ds.GetRecording(recordingId)
+	if err != nil || rec == nil {
+		log.WithError(err).Errorln("failed to get recording")
+		return
+	}
+
+	if err := m.attachRecordingChapters(rec, data, log); err != nil {
+		log.WithError(err).Errorln("failed to attach recording chapters")
+		return
+	}
+	if err := m.rs.DeleteRecordingChapters(recordingId); err != nil {
+		log.WithError(err).Warnln("failed to delete attached recording chapters")
+	}
+	log.Infoln("successfully attached chapters & speaker timeline to the recording")
+}
+
+// attachRecordingChapters stores the files next to the recording as renditions
+// & adds their paths to the metadata of the recording
+func (m *RecordingModel) attachRecordingChapters(rec *dbmodels.Recording, data *recordingChaptersData, log *logrus.Entry) error {
+	timeline, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := path.Dir(rec.FilePath)
+	base := strings.TrimSuffix(path.Base(rec.FilePath), path.Ext(rec.FilePath))
+	files := []struct {
+		renditionType string
+		relPath       string
+		mimeType      string
+		metadataKey   string
+		content       []byte
+	}{
+		{dbmodels.RecordingRenditionTypeChapters, path.Join(dir, base+recordingChaptersSuffix), "text/vtt", recordingChaptersMetadataKey, renderRecordingChaptersVTT(data)},
+		{dbmodels.RecordingRenditionTypeSpeakerTimeline, path.Join(dir, base+recordingSpeakerTimelineSuffix), "application/json", recordingSpeakerTimelineMetadataKey, timeline},
+	}
+
+	existing, err := m.ds.GetRecordingRenditions(rec.RecordID)
+	if err != nil {
+		return err
+	}
+
+	extraData := make(map[string]string)
+	for _, f := range files {
+		localPath := m.renditionLocalPath(f.relPath)
+		if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(localPath, f.content, 0644); err != nil {
+			return err
+		}
+		size, err := m.storeRendition(f.renditionType, f.relPath, f.mimeType, log)
+		if err != nil {
+			return err
+		}
+
+		rr := &dbmodels.RecordingRendition{
+			RecordID: rec.RecordID,
+			Type:     f.renditionType,
+		}
+		for _, e := range existing {
+			if e.Type == f.renditionType {
+				rr = &e
+				break
+			}
+		}
+		rr.Status = dbmodels.RecordingRenditionStatusCompleted
+		rr.FilePath = f.relPath
+		rr.FileSize = size
+		rr.MimeType = f.mimeType
+		rr.Attempts++
+		if _, err := m.ds.InsertOrUpdateRecordingRendition(rr); err != nil {
+			return err
+		}
+		extraData[f.metadataKey] = f.relPath
+	}
+
+	return m.UpdateRecordingMetadata(&plugnmeet.UpdateRecordingMetadataReq{
+		RecordId: rec.RecordID,
+		Metadata: &plugnmeet.RecordingMetadata{
+			ExtraData: extraData,
+		},
+	})
+}
+
+// sortTranscriptionHistory converts the history fields from unix nanoseconds & sorts the chunks
+func sortTranscriptionHistory(history map[string]string) []*timedTranscriptionChunk {
+	chunks := make([]*timedTranscriptionChunk, 0, len(history))
+	for k, v := range history {
+		ts, err := strconv.ParseInt(k, 10, 64)
+		if err != nil {
+			continue
+		}
+		c := &timedTranscriptionChunk{ts: time.Unix(0, ts).UnixMilli()}
+		if err := json.Unmarshal([]byte(v), &c.chunk); err != nil {
+			continue
+		}
+		chunks = append(chunks, c)
+	}
+	sort.Slice(chunks, func(i, j int) bool {
+		return chunks[i].ts < chunks[j].ts
+	})
+	return chunks
+}
+
+func buildRecordingChapters(recordingId, roomId, roomSid string, start, end int64, events []*AnalyticsTimelineEvent, chunks []*timedTranscriptionChunk) *recordingChaptersData {
+	data := &recordingChaptersData{
+		RecordingId:      recordingId,
+		RoomId:           roomId,
+		RoomSid:          roomSid,
+		RecordingStarted: start,
+		DurationMs:       end - start,
+		Chapters: []*recordingChapter{
+			{StartMs: 0, Title: "Start"},
+		},
+		Speakers: []*recordingSpeakerSummary{},
+		Segments: []*recordingSpeakerSegment{},
+	}
+
+	for _, e := range events {
+		if e.Time < start || e.Time >= end {
+			continue
+		}
+		title := recordingChapterTitle(e)
+		if title == "" {
+			continue
+		}
+		offset := e.Time - start
+		last := data.Chapters[len(data.Chapters)-1]
+		if offset-last.StartMs < minRecordingChapterGap.Milliseconds() {
+			// the latest change describes the content better
+			last.Title = title
+			continue
+		}
+		data.Chapters = append(data.Chapters, &recordingChapter{StartMs: offset, Title: title})
+	}
+
+	summaries := make(map[string]*recordingSpeakerSummary)
+	lastEnd := make(map[string]int64)
+	for _, c := range chunks {
+		if c.ts < start || c.ts > end {
+			continue
+		}
+		endMs := c.ts - start
+		estimated := time.Duration(len(strings.Fields(c.chunk.Text))) * estimatedSpeechPerWord
+		estimated = min(max(estimated, minSpeechSegment), maxSpeechSegment)
+		startMs := max(endMs-estimated.Milliseconds(), lastEnd[c.chunk.FromUserID], 0)
+		lastEnd[c.chunk.FromUserID] = endMs
+
+		data.Segments = append(data.Segments, &recordingSpeakerSegment{
+			UserId:  c.chunk.FromUserID,
+			Name:    c.chunk.Name,
+			StartMs: startMs,
+			EndMs:   endMs,
+			Text:    c.chunk.Text,
+		})
+
+		s, ok := summaries[c.chunk.FromUserID]
+		if !ok {
+			s = &recordingSpeakerSummary{UserId: c.chunk.FromUserID, Name: c.chunk.Name}
+			summaries[c.chunk.FromUserID] = s
+			data.Speakers = append(data.Speakers, s)
+		}
+		s.TotalMs += endMs - startMs
+		s.Segments++
+	}
+	sort.Slice(data.Speakers, func(i, j int) bool {
+		return data.Speakers[i].TotalMs > data.Speakers[j].TotalMs
+	})
+
+	return data
+}
+
+func recordingChapterTitle(e *AnalyticsTimelineEvent) string {
+	name := e.UserName
+	if name == "" {
+		name = e.UserId
+	}
+
+	switch {
+	case e.Name == "presenter_changed":
+		return fmt.Sprintf("%s is presenting", name)
+	case strings.HasSuffix(e.Name, "screen_share_status"):
+		if e.Value == plugnmeet.AnalyticsStatus_ANALYTICS_STATUS_STARTED.String() {
+			return fmt.Sprintf("%s started screen sharing", name)
+		}
+		return fmt.Sprintf("%s stopped screen sharing", name)
+	case strings.Contains(e.Name, "whiteboard"):
+		// events without value store the time as value
+		if e.Value != "" && e.Value != strconv.FormatInt(e.Time, 10) {
+			return fmt.Sprintf("Whiteboard: %s", e.Value)
+		}
+		return "Whiteboard"
+	}
+	return ""
+}
+
+func renderRecordingChaptersVTT(data *recordingChaptersData) []byte {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for i, c := range data.Chapters {
+		end := data.DurationMs
+		if i+1 < len(data.Chapters) {
+			end = data.Chapters[i+1].StartMs
+		}
+		b.WriteString(fmt.Sprintf("%d\n", i+1))
+		b.WriteString(fmt.Sprintf("%s --> %s\n", formatVTTTimestamp(time.Duration(c.StartMs)*time.Millisecond), formatVTTTimestamp(time.Duration(end)*time.Millisecond)))
+		b.WriteString(c.Title + "\n\n")
+	}
+	return []byte(b.String())
+}
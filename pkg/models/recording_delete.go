@@ -28,6 +28,9 @@ func (m *RecordingModel) DeleteRecording(r *plugnmeet.DeleteRecordingReq) error
 		return err
 	}
 
+	// renditions are stored next to the recording, so those should go first
+	m.deleteRecordingRenditions(r.RecordId, log)
+
 	// If delete hook is configured, we'll use it.
 	if m.app.Hooks != nil {
 		delReq := hooks.DeleteHookData{
@@ -39,6 +42,16 @@ func (m *RecordingModel) DeleteRecording(r *plugnmeet.DeleteRecordingReq) error
 		}
 		// After running the hook (even if it failed), we proceed to delete the DB record.
 		// The hook is fire-and-forget; its failure should not block DB cleanup.
+	} else if !m.storageService.IsLocal() {
+		log.Info("deleting recording file from storage")
+		st := m.storageService.Recordings()
+		if err := st.Delete(m.ctx, recording.FilePath); err != nil {
+			log.WithError(err).Errorln("failed to delete recording file from storage")
+			return fmt.Errorf("delete recording file from storage failed")
+		}
+		if err := st.Delete(m.ctx, recording.FilePath+".json"); err != nil {
+			log.WithError(err).Warnln("failed to delete recording info file from storage")
+		}
 	} else {
 		// Otherwise, if it's a local file, we'll try to delete it.
 		filePath := fmt.Sprintf("%s/%s", m.app.RecorderInfo.RecordingFilesPath, recording.FilePath)
@@ -11,6 +11,7 @@ import (
 	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
 	"github.com/mynaparrot/plugnmeet-protocol/utils"
 	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
 	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
 	"github.com/nats-io/nats.go"
 	"github.com/sirupsen/logrus"
@@ -19,12 +20,19 @@ import (
 
 const recorderResponseTimeout = 3 * time.Second
 
+// DispatchRecorderTask sends the task to a recorder. If the recorder queue is enabled & no recorder is free,
+// the start tasks will be queued and config.ErrRecorderTaskQueued will be returned.
 func (m *RecordingModel) DispatchRecorderTask(req *plugnmeet.RecordingReq) error {
+	return m.dispatchRecorderTask(req, false)
+}
+
+func (m *RecordingModel) dispatchRecorderTask(req *plugnmeet.RecordingReq, fromQueue bool) error {
 	log := m.logger.WithFields(logrus.Fields{
-		"roomId": req.RoomId,
-		"sid":    req.Sid,
-		"task":   req.Task.String(),
-		"method": "DispatchRecorderTask",
+		"roomId":    req.RoomId,
+		"sid":       req.Sid,
+		"task":      req.Task.String(),
+		"fromQueue": fromQueue,
+		"method":    "DispatchRecorderTask",
 	})
 	isStartTask := false
 
@@ -83,15 +91,33 @@ func (m *RecordingModel) DispatchRecorderTask(req *plugnmeet.RecordingReq) error
 		RecordingId: fmt.Sprintf("%s-%d", req.Sid, recordId),
 	}
 
+	var recorderId string
+	if isStartTask {
+		var extraData map[string]string
+		if meta, err := m.natsService.GetRoomMetadataStruct(req.RoomId); err == nil && meta != nil {
+			extraData = meta.GetExtraData()
+		}
+		recorder, reserved := m.selectRecorder(req.RoomId, req.Task, extraData, log)
+		if recorder == nil {
+			return m.onNoRecorderAvailable(req, fromQueue, log)
+		}
+		// requests waiting in the queue before this one will be served first,
+		// unless the room has its own reservation or those can't use the recorder because of their affinity.
+		if !reserved && m.hasQueuedRecorderTasksFor(recorder.RecorderId, req, fromQueue, log) {
+			return m.onNoRecorderAvailable(req, fromQueue, log)
+		}
+		recorderId = recorder.RecorderId
+	}
+
 	switch req.Task {
 	case plugnmeet.RecordingTasks_START_RECORDING:
-		if err := m.addTokenAndRecorder(context.Background(), req, toSend, config.RecorderBot, log); err != nil {
+		if err := m.addTokenAndRecorder(context.Background(), req, toSend, recorderId, config.RecorderBot, log); err != nil {
 			log.WithError(err).Error("failed to add token for recording bot")
 			return fmt.Errorf("Failed to add token for recording bot")
 		}
 	case plugnmeet.RecordingTasks_START_RTMP:
 		toSend.RtmpUrl = req.RtmpUrl
-		if err := m.addTokenAndRecorder(context.Background(), req, toSend, config.RtmpBot, log); err != nil {
+		if err := m.addTokenAndRecorder(context.Background(), req, toSend, recorderId, config.RtmpBot, log); err != nil {
 			log.WithError(err).Error("Failed to add token for rtmp bot")
 			return fmt.Errorf("Failed to add token for rtmp bot")
 		}
@@ -151,19 +177,13 @@ func (m *RecordingModel) DispatchRecorderTask(req *plugnmeet.RecordingReq) error
 	return nil
 }
 
-func (m *RecordingModel) addTokenAndRecorder(ctx context.Context, req *plugnmeet.RecordingReq, rq *plugnmeet.PlugNmeetToRecorder, userId string, log *logrus.Entry) error {
+func (m *RecordingModel) addTokenAndRecorder(ctx context.Context, req *plugnmeet.RecordingReq, rq *plugnmeet.PlugNmeetToRecorder, recorderId, userId string, log *logrus.Entry) error {
 	log = log.WithFields(logrus.Fields{
-		"userId": userId,
-		"method": "addTokenAndRecorder",
+		"userId":     userId,
+		"recorderId": recorderId,
+		"method":     "addTokenAndRecorder",
 	})
-	log.Info("adding token and selecting recorder")
-
-	recorderId := m.selectRecorder(log)
-	if recorderId == "" {
-		err := fmt.Errorf("notifications.no-recorder-available")
-		log.WithError(err).Error("no recorder available")
-		return err
-	}
+	log.Info("adding token and recorder")
 
 	gt := &plugnmeet.GenerateTokenReq{
 		RoomId: req.RoomId,
@@ -189,7 +209,7 @@ func (m *RecordingModel) addTokenAndRecorder(ctx context.Context, req *plugnmeet
 		rq.AccessToken += "&custom_design=" + url.QueryEscape(*req.CustomDesign)
 	}
 
-	log.WithField("recorderId", recorderId).Info("successfully added token and selected recorder")
+	log.Info("successfully added token and recorder")
 	return nil
 }
 
@@ -227,33 +247,74 @@ func calculateRecorderScore(r *utils.RecorderInfo) float64 {
 	return combinedScore
 }
 
-func (m *RecordingModel) selectRecorder(log *logrus.Entry) string {
+// selectRecorder returns the best recorder which has free capacity & matches the affinity of the room.
+// A room with reservation will get its reserved recorder for the recording, reserved will be true in that case.
+func (m *RecordingModel) selectRecorder(roomId string, task plugnmeet.RecordingTasks, extraData map[string]string, log *logrus.Entry) (recorder *utils.RecorderInfo, reserved bool) {
 	log = log.WithField("method", "selectRecorder")
 	log.Info("Selecting a recorder")
 
-	recorders := m.natsService.GetAllActiveRecorders()
+	recorders := m.recorders.GetAllActiveRecorders()
 
 	if len(recorders) < 1 {
 		log.Warn("No active recorders found")
-		return ""
+		return nil, false
+	}
+
+	reservations, err := m.recorderStore.GetRecorderReservations()
+	if err != nil {
+		// we can continue without reservations
+		log.WithError(err).Warn("failed to get recorder reservations")
+	}
+	if own, ok := reservations[roomId]; ok && task == plugnmeet.RecordingTasks_START_RECORDING {
+		for _, r := range recorders {
+			if r.RecorderId == own {
+				log.WithField("selectedRecorderId", own).Info("Selected the reserved recorder of the room")
+				return r, true
+			}
+		}
+		log.WithField("reservedRecorderId", own).Warn("reserved recorder isn't active anymore, selecting another one")
+	}
+
+	reservedSlots := make(map[string]int64)
+	for rId, recorderId := range reservations {
+		if rId != roomId {
+			reservedSlots[recorderId]++
+		}
+	}
+	affinity := natsservice.ParseRecorderLabels(extraData[recorderAffinityExtraDataKey])
+
+	candidates := make([]*utils.RecorderInfo, 0, len(recorders))
+	for _, r := range recorders {
+		if r.MaxLimit > 0 && r.CurrentProgress+reservedSlots[r.RecorderId] >= r.MaxLimit {
+			continue
+		}
+		if !matchRecorderAffinity(m.recorders.GetRecorderLabels(r.RecorderId), affinity) {
+			continue
+		}
+		candidates = append(candidates, r)
+	}
+	if len(candidates) < 1 {
+		log.WithField("affinity", extraData[recorderAffinityExtraDataKey]).Warn("No recorder with free capacity found")
+		return nil, false
 	}
 
 	// Sort recorders based on our new scoring logic. Lower score is better.
-	sort.Slice(recorders, func(i, j int) bool {
-		scoreI := calculateRecorderScore(recorders[i])
-		scoreJ := calculateRecorderScore(recorders[j])
+	sort.Slice(candidates, func(i, j int) bool {
+		scoreI := calculateRecorderScore(candidates[i])
+		scoreJ := calculateRecorderScore(candidates[j])
 		return scoreI < scoreJ
 	})
 
 	// The best recorder is the first one in the sorted list.
-	selected := recorders[0]
+	selected := candidates[0]
 	log.WithFields(logrus.Fields{
 		"selectedRecorderId": selected.RecorderId,
 		"currentProgress":    selected.CurrentProgress,
 		"maxLimit":           selected.MaxLimit,
+		"reserved":           reservedSlots[selected.RecorderId],
 		"cpuScore":           selected.CpuScore,
 		"totalCores":         selected.TotalCores,
 		"finalScore":         calculateRecorderScore(selected),
 	}).Info("Successfully selected a recorder")
-	return selected.RecorderId
+	return selected, false
 }
@@ -5,11 +5,14 @@ import (
 
 	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
 	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
 	"github.com/mynaparrot/plugnmeet-server/pkg/helpers"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
-func (m *RecordingModel) FetchRecordings(r *plugnmeet.FetchRecordingsReq) (*plugnmeet.FetchRecordingsResult, error) {
+// FetchRecordings returns the recordings, if tenantId isn't empty
+// only recordings of that tenant will be returned
+func (m *RecordingModel) FetchRecordings(tenantId string, r *plugnmeet.FetchRecordingsReq) (*plugnmeet.FetchRecordingsResult, error) {
 	if r.Limit <= 0 {
 		r.Limit = 20
 	} else if r.Limit > 100 {
@@ -19,7 +22,7 @@ func (m *RecordingModel) FetchRecordings(r *plugnmeet.FetchRecordingsReq) (*plug
 		r.OrderBy = "DESC"
 	}
 
-	data, total, err := m.ds.GetRecordings(r.RoomIds, r.RoomSid, uint64(r.From), uint64(r.Limit), &r.OrderBy)
+	data, total, err := m.ds.GetRecordings(tenantId, r.RoomIds, r.RoomSid, uint64(r.From), uint64(r.Limit), &r.OrderBy)
 	if err != nil {
 		return nil, err
 	}
@@ -121,3 +124,44 @@ func (m *RecordingModel) RecordingInfo(req *plugnmeet.RecordingInfoReq) (*plugnm
 		RoomInfo:      pastRoomInfo,
 	}, nil
 }
+
+// GetRecordingRenditions returns the renditions of the recording
+// with download path for the completed ones, restrictions is optional to bind the tokens of those paths.
+func (m *RecordingModel) GetRecordingRenditions(recordId string, restrictions *RecordingTokenRestrictions) ([]*RecordingRenditionInfo, error) {
+	if err := restrictions.validate(); err != nil {
+		return nil, err
+	}
+	renditions, err := m.ds.GetRecordingRenditions(recordId)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*RecordingRenditionInfo, 0, len(renditions))
+	for _, rr := range renditions {
+		info := &RecordingRenditionInfo{
+			Type:   rr.Type,
+			Status: rr.Status,
+		}
+		if rr.Status == dbmodels.RecordingRenditionStatusCompleted {
+			info.FilePath = rr.FilePath
+			info.FileSize = rr.FileSize
+			info.MimeType = rr.MimeType
+			if info.DownloadPath, err = m.createRenditionDownloadPath(&rr, restrictions); err != nil {
+				return nil, err
+			}
+		}
+		list = append(list, info)
+	}
+	return list, nil
+}
+
+func (m *RecordingModel) createRenditionDownloadPath(rr *dbmodels.RecordingRendition, restrictions *RecordingTokenRestrictions) (string, error) {
+	if rr.Type == dbmodels.RecordingRenditionTypeHls {
+		return m.CreateHlsPlaybackPath(rr.RecordID, rr.FilePath, restrictions)
+	}
+	token, err := m.CreateTokenForDownload(rr.RecordID, rr.FilePath, restrictions)
+	if err != nil {
+		return "", err
+	}
+	return "/download/recording/" + token, nil
+}
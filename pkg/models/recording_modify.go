@@ -83,6 +83,8 @@ func (m *RecordingModel) addRecordingInfoToDB(r *plugnmeet.RecorderToPlugNmeet,
 		Size:             fmt.Sprintf("%.2f", r.FileSize),
 		FilePath:         r.FilePath,
 		RoomCreationTime: roomInfo.CreationTime,
+		TenantId:         roomInfo.TenantId,
+		TemplateId:       roomInfo.TemplateId,
 	}
 
 	metadata := &plugnmeet.RecordingMetadata{
@@ -142,6 +144,13 @@ func (m *RecordingModel) addRecordingInfoFile(r *plugnmeet.RecorderToPlugNmeet,
 		return
 	}
 
+	var tmpDir string
+	defer func() {
+		if tmpDir != "" {
+			_ = os.RemoveAll(tmpDir)
+		}
+	}()
+
 	p := filepath.Join(m.app.RecorderInfo.RecordingFilesPath, filepath.Dir(r.FilePath))
 	if _, err := os.Stat(p); err != nil && errors.Is(err, os.ErrNotExist) {
 		// this can be expected when using hook system as file was uploaded and deleted
@@ -152,7 +161,7 @@ func (m *RecordingModel) addRecordingInfoFile(r *plugnmeet.RecorderToPlugNmeet,
 			return
 		}
 		// we should clean up as this was created only for hook script
-		defer os.RemoveAll(p)
+		tmpDir = p
 	}
 
 	p = filepath.Join(p, filepath.Base(r.FilePath)+".json")
@@ -175,7 +184,52 @@ func (m *RecordingModel) addRecordingInfoFile(r *plugnmeet.RecorderToPlugNmeet,
 		if _, err := m.app.Hooks.RunUploadHook(&req, log); err != nil {
 			log.WithError(err).Error("failed to run upload hook")
 		}
+	} else if !m.storageService.IsLocal() {
+		// uploading a large recording can take long, so we won't hold the recorder event.
+		// The temporary directory will be removed after the upload.
+		dir := tmpDir
+		tmpDir = ""
+		go func() {
+			m.storeRecordingFiles(r.FilePath, p, log)
+			if dir != "" {
+				_ = os.RemoveAll(dir)
+			}
+		}()
+	}
+}
+
+// storeRecordingFiles uploads the recording & its info file to the configured storage
+// and removes the local copies. If the upload fails, local copies will be kept
+// & download will fall back to those.
+func (m *RecordingModel) storeRecordingFiles(filePath, infoFilePath string, log *logrus.Entry) {
+	st := m.storageService.Recordings()
+	localPath := filepath.Join(m.app.RecorderInfo.RecordingFilesPath, filePath)
+
+	if _, err := os.Stat(localPath); err == nil {
+		log.Infoln("uploading recording file to storage")
+		if err := m.storageService.PutFile(m.ctx, st, filePath, localPath, ""); err != nil {
+			log.WithError(err).Errorln("failed to upload recording file to storage")
+			return
+		}
+		_ = os.Remove(localPath)
+	} else {
+		log.WithError(err).Warnln("recording file not found locally, may be the recorder uploaded it already")
+	}
+
+	if err := m.storageService.PutFile(m.ctx, st, filePath+".json", infoFilePath, "application/json"); err != nil {
+		log.WithError(err).Errorln("failed to upload recording info file to storage")
+		return
+	}
+	_ = os.Remove(infoFilePath)
+
+	// clean up the room directory if nothing left there
+	dir := filepath.Dir(localPath)
+	if dir != m.app.RecorderInfo.RecordingFilesPath {
+		if empty, err := m.isDirEmpty(dir); err == nil && empty {
+			_ = os.Remove(dir)
+		}
 	}
+	log.Infoln("Successfully uploaded recording files to storage")
 }
 
 // UpdateRecordingMetadata updates the metadata of a specific recording.
@@ -335,7 +389,7 @@ func (m *RecordingModel) MergeRecordings(ctx context.Context, req *plugnmeet.Mer
 			roomSid = roomInfo.Sid
 			roomTableId = int64(roomInfo.ID)
 
-			recs, total, err := m.ds.GetRecordings(nil, &roomInfo.Sid, 0, 0, nil)
+			recs, total, err := m.ds.GetRecordings("", nil, &roomInfo.Sid, 0, 0, nil)
 			if err != nil {
 				log.WithError(err).Error("failed to get recordings")
 				return plugnmeet.StatusCode_INTERNAL_SERVER_ERROR, err
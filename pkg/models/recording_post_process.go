@@ -0,0 +1,421 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/mynaparrot/plugnmeet-server/pkg/helpers"
+	"github.com/mynaparrot/plugnmeet-server/pkg/storage"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	recordingPosterSuffix      = "_poster.jpg"
+	recordingHlsDirSuffix      = "_hls"
+	recordingHlsMasterPlaylist = "index.m3u8"
+	hlsPlaylistMimeType        = "application/vnd.apple.mpegurl"
+	hlsSegmentMimeType         = "video/mp2t"
+	// maximum length of the ffmpeg output to keep as error
+	maxRenditionErrorLen = 1024
+)
+
+// RecordingRenditionInfo is the public information of a recording rendition.
+// DownloadPath is relative to the server url.
+type RecordingRenditionInfo struct {
+	Type         string `json:"type"`
+	Status       string `json:"status"`
+	FilePath     string `json:"file_path,omitempty"`
+	FileSize     int64  `json:"file_size"`
+	MimeType     string `json:"mime_type,omitempty"`
+	DownloadPath string `json:"download_path,omitempty"`
+}
+
+// enabledRenditionTypes returns the types of rendition enabled in config
+func (m *RecordingModel) enabledRenditionTypes() []string {
+	pp := m.app.RecorderInfo.PostProcessing
+	if pp == nil || !pp.Enabled {
+		return nil
+	}
+
+	var types []string
+	if pp.EnableThumbnail {
+		types = append(types, dbmodels.RecordingRenditionTypeThumbnail)
+	}
+	if pp.AudioFormat != "" {
+		types = append(types, dbmodels.RecordingRenditionTypeAudio)
+	}
+	if pp.EnableHls {
+		types = append(types, dbmodels.RecordingRenditionTypeHls)
+	}
+	return types
+}
+
+// queueRecordingPostProcessing adds pending renditions of the recording
+// & puts the recording in the post-processing queue
+func (m *RecordingModel) queueRecordingPostProcessing(recordId string, log *logrus.Entry) {
+	types := m.enabledRenditionTypes()
+	if len(types) == 0 {
+		return
+	}
+	log = log.WithField("method", "queueRecordingPostProcessing")
+
+	for _, t := range types {
+		rr := &dbmodels.RecordingRendition{
+			RecordID: recordId,
+			Type:     t,
+			Status:   dbmodels.RecordingRenditionStatusPending,
+		}
+		if _, err := m.ds.InsertOrUpdateRecordingRendition(rr); err != nil {
+			log.WithError(err).WithField("type", t).Errorln("failed to add recording rendition")
+			return
+		}
+	}
+
+	if err := m.natsService.PublishRecordingPostProcessJob(recordId, []byte(recordId)); err != nil {
+		log.WithError(err).Errorln("failed to publish recording post-processing job")
+		return
+	}
+	log.Infof("queued recording for post-processing: %s", strings.Join(types, ", "))
+}
+
+// ProcessRecordingRenditions generates all the unfinished renditions of the recording.
+// It returns true if some renditions failed but have attempts left.
+func (m *RecordingModel) ProcessRecordingRenditions(recordId string) (bool, error) {
+	log := m.logger.WithFields(logrus.Fields{
+		"recordId": recordId,
+		"method":   "ProcessRecordingRenditions",
+	})
+	pp := m.app.RecorderInfo.PostProcessing
+
+	recording, err := m.ds.GetRecording(recordId)
+	if err != nil {
+		return true, err
+	}
+	if recording == nil {
+		// recording was deleted
+		return false, nil
+	}
+
+	renditions, err := m.ds.GetRecordingRenditions(recordId)
+	if err != nil {
+		return true, err
+	}
+	var toProcess []*dbmodels.RecordingRendition
+	for i := range renditions {
+		rr := &renditions[i]
+		if rr.Status != dbmodels.RecordingRenditionStatusCompleted && rr.Attempts < pp.MaxAttempts {
+			toProcess = append(toProcess, rr)
+		}
+	}
+	if len(toProcess) == 0 {
+		return false, nil
+	}
+
+	workDir, err := os.MkdirTemp(m.app.RecorderInfo.RecordingFilesPath, "post-process")
+	if err != nil {
+		return true, err
+	}
+	defer os.RemoveAll(workDir)
+
+	src, srcErr := m.prepareRenditionSource(recording.FilePath, workDir)
+	var retry bool
+	for _, rr := range toProcess {
+		rr.Attempts++
+		if srcErr != nil {
+			m.updateRenditionStatus(rr, srcErr, log)
+			retry = retry || rr.Attempts < pp.MaxAttempts
+			continue
+		}
+
+		rr.Status = dbmodels.RecordingRenditionStatusProcessing
+		if _, err := m.ds.InsertOrUpdateRecordingRendition(rr); err != nil {
+			log.WithError(err).Errorln("failed to update recording rendition")
+		}
+
+		jobErr := m.generateRendition(rr, src, recording.FilePath, log)
+		m.updateRenditionStatus(rr, jobErr, log)
+		if jobErr != nil {
+			retry = retry || rr.Attempts < pp.MaxAttempts
+		}
+	}
+
+	return retry, nil
+}
+
+// prepareRenditionSource returns the local path of the recording,
+// it will be downloaded from the storage if it isn't available locally
+func (m *RecordingModel) prepareRenditionSource(filePath, workDir string) (string, error) {
+	localPath := filepath.Join(m.app.RecorderInfo.RecordingFilesPath, filePath)
+	if _, err := os.Stat(localPath); err == nil {
+		return localPath, nil
+	}
+	if m.storageService.IsLocal() {
+		return "", config.ErrFileNotFound
+	}
+
+	dst := filepath.Join(workDir, filepath.Base(filePath))
+	if err := m.storageService.GetFile(m.ctx, m.storageService.Recordings(), filePath, dst); err != nil {
+		return "", fmt.Errorf("failed to download recording from storage: %w", err)
+	}
+	return dst, nil
+}
+
+func (m *RecordingModel) updateRenditionStatus(rr *dbmodels.RecordingRendition, jobErr error, log *logrus.Entry) {
+	log = log.WithField("type", rr.Type)
+	if jobErr != nil {
+		log.WithError(jobErr).Errorf("attempt %d to generate rendition failed", rr.Attempts)
+		rr.Status = dbmodels.RecordingRenditionStatusFailed
+		rr.LastError = helpers.TruncateString(jobErr.Error(), maxRenditionErrorLen)
+	} else {
+		log.Infoln("successfully generated rendition")
+		rr.Status = dbmodels.RecordingRenditionStatusCompleted
+		rr.LastError = ""
+	}
+	if _, err := m.ds.InsertOrUpdateRecordingRendition(rr); err != nil {
+		log.WithError(err).Errorln("failed to update recording rendition")
+	}
+}
+
+// generateRendition runs ffmpeg for the rendition & stores the output next to the recording
+func (m *RecordingModel) generateRendition(rr *dbmodels.RecordingRendition, src, recordingPath string, log *logrus.Entry) error {
+	pp := m.app.RecorderInfo.PostProcessing
+	dir := path.Dir(recordingPath)
+	base := strings.TrimSuffix(path.Base(recordingPath), path.Ext(recordingPath))
+
+	var relPath, mimeType string
+	var args []string
+	switch rr.Type {
+	case dbmodels.RecordingRenditionTypeThumbnail:
+		relPath = path.Join(dir, base+recordingPosterSuffix)
+		mimeType = "image/jpeg"
+		args = []string{"-i", src, "-vf", "thumbnail,scale=640:-2", "-frames:v", "1", m.renditionLocalPath(relPath)}
+	case dbmodels.RecordingRenditionTypeAudio:
+		relPath = path.Join(dir, base+"."+pp.AudioFormat)
+		if pp.AudioFormat == "opus" {
+			mimeType = "audio/ogg"
+			args = []string{"-i", src, "-vn", "-c:a", "libopus", "-b:a", "64k", m.renditionLocalPath(relPath)}
+		} else {
+			mimeType = "audio/mpeg"
+			args = []string{"-i", src, "-vn", "-c:a", "libmp3lame", "-b:a", "128k", m.renditionLocalPath(relPath)}
+		}
+	case dbmodels.RecordingRenditionTypeHls:
+		relPath = path.Join(dir, base+recordingHlsDirSuffix, recordingHlsMasterPlaylist)
+		mimeType = hlsPlaylistMimeType
+		outDir := m.renditionLocalPath(path.Dir(relPath))
+		// output of the previous attempt
+		_ = os.RemoveAll(outDir)
+		args = buildHlsArgs(src, outDir, pp.HlsVariants, int(pp.HlsSegmentDuration.Seconds()), m.hasAudioStream(src))
+	default:
+		return fmt.Errorf("unknown rendition type: %s", rr.Type)
+	}
+
+	localPath := m.renditionLocalPath(relPath)
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return err
+	}
+	if err := m.runFfmpeg(args); err != nil {
+		return err
+	}
+
+	size, err := m.storeRendition(rr.Type, relPath, mimeType, log)
+	if err != nil {
+		return err
+	}
+
+	rr.FilePath = relPath
+	rr.FileSize = size
+	rr.MimeType = mimeType
+	return nil
+}
+
+func (m *RecordingModel) renditionLocalPath(relPath string) string {
+	return filepath.Join(m.app.RecorderInfo.RecordingFilesPath, filepath.FromSlash(relPath))
+}
+
+func (m *RecordingModel) runFfmpeg(args []string) error {
+	pp := m.app.RecorderInfo.PostProcessing
+	ctx, cancel := context.WithTimeout(m.ctx, pp.JobTimeout)
+	defer cancel()
+
+	args = append([]string{"-hide_banner", "-loglevel", "error", "-y"}, args...)
+	out, err := exec.CommandContext(ctx, pp.FfmpegPath, args...).CombinedOutput()
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("ffmpeg did not finish within %s", pp.JobTimeout)
+		}
+		return fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// hasAudioStream checks if the recording has any audio, e.g. a recording without any microphone won't have it.
+func (m *RecordingModel) hasAudioStream(src string) bool {
+	return m.runFfmpeg([]string{"-i", src, "-map", "0:a:0", "-t", "0", "-f", "null", "-"}) == nil
+}
+
+// buildHlsArgs creates one variant per height, all sharing the same audio if there is any
+func buildHlsArgs(src, outDir string, variants []int, segmentSec int, withAudio bool) []string {
+	var filter strings.Builder
+	fmt.Fprintf(&filter, "[0:v]split=%d", len(variants))
+	for i := range variants {
+		fmt.Fprintf(&filter, "[v%d]", i)
+	}
+	for i, h := range variants {
+		fmt.Fprintf(&filter, ";[v%d]scale=-2:%d[v%dout]", i, h, i)
+	}
+
+	args := []string{"-i", src, "-filter_complex", filter.String()}
+	streamMap := make([]string, 0, len(variants))
+	for i, h := range variants {
+		args = append(args,
+			"-map", fmt.Sprintf("[v%dout]", i),
+			fmt.Sprintf("-c:v:%d", i), "libx264",
+			fmt.Sprintf("-b:v:%d", i), hlsVariantBitrate(h),
+		)
+		if !withAudio {
+			streamMap = append(streamMap, fmt.Sprintf("v:%d", i))
+			continue
+		}
+		args = append(args, "-map", "0:a:0?")
+		streamMap = append(streamMap, fmt.Sprintf("v:%d,a:%d", i, i))
+	}
+	if withAudio {
+		args = append(args, "-c:a", "aac", "-b:a", "128k")
+	}
+
+	// keyframes must be aligned with segments for switching between variants
+	return append(args,
+		"-preset", "veryfast",
+		"-sc_threshold", "0",
+		"-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%d)", segmentSec),
+		"-f", "hls",
+		"-hls_time", strconv.Itoa(segmentSec),
+		"-hls_playlist_type", "vod",
+		"-hls_segment_filename", filepath.Join(outDir, "stream_%v", "segment_%05d.ts"),
+		"-master_pl_name", recordingHlsMasterPlaylist,
+		"-var_stream_map", strings.Join(streamMap, " "),
+		filepath.Join(outDir, "stream_%v", "playlist.m3u8"),
+	)
+}
+
+func hlsVariantBitrate(height int) string {
+	switch {
+	case height >= 1080:
+		return "5000k"
+	case height >= 720:
+		return "2800k"
+	case height >= 480:
+		return "1400k"
+	default:
+		return "800k"
+	}
+}
+
+// storeRendition uploads the generated files to the configured storage
+// & returns the total size of the rendition.
+// If hooks are configured, files will be kept locally.
+func (m *RecordingModel) storeRendition(renditionType, relPath, contentType string, log *logrus.Entry) (int64, error) {
+	upload := m.app.Hooks == nil && !m.storageService.IsLocal()
+	st := m.storageService.Recordings()
+
+	if renditionType != dbmodels.RecordingRenditionTypeHls {
+		localPath := m.renditionLocalPath(relPath)
+		info, err := os.Stat(localPath)
+		if err != nil {
+			return 0, err
+		}
+		if upload {
+			if err := m.storageService.PutFile(m.ctx, st, relPath, localPath, contentType); err != nil {
+				return 0, fmt.Errorf("failed to upload rendition to storage: %w", err)
+			}
+			_ = os.Remove(localPath)
+		}
+		return info.Size(), nil
+	}
+
+	hlsDir := path.Dir(relPath)
+	localDir := m.renditionLocalPath(hlsDir)
+	var size int64
+	err := filepath.WalkDir(localDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		if !upload {
+			return nil
+		}
+
+		rel, err := filepath.Rel(localDir, p)
+		if err != nil {
+			return err
+		}
+		fileType := hlsSegmentMimeType
+		if strings.HasSuffix(p, ".m3u8") {
+			fileType = hlsPlaylistMimeType
+		}
+		return m.storageService.PutFile(m.ctx, st, path.Join(hlsDir, filepath.ToSlash(rel)), p, fileType)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to store HLS files: %w", err)
+	}
+	if upload {
+		_ = os.RemoveAll(localDir)
+		log.Infoln("uploaded HLS files to storage")
+	}
+	return size, nil
+}
+
+// deleteRecordingRenditions removes the files & records of all the renditions of the recording
+func (m *RecordingModel) deleteRecordingRenditions(recordId string, log *logrus.Entry) {
+	renditions, err := m.ds.GetRecordingRenditions(recordId)
+	if err != nil {
+		log.WithError(err).Warnln("failed to get recording renditions")
+		return
+	}
+	if len(renditions) == 0 {
+		return
+	}
+
+	st := m.storageService.Recordings()
+	for _, rr := range renditions {
+		if rr.FilePath == "" {
+			continue
+		}
+		if rr.Type == dbmodels.RecordingRenditionTypeHls {
+			hlsDir := path.Dir(rr.FilePath)
+			_ = os.RemoveAll(m.renditionLocalPath(hlsDir))
+			// the directory only holds the files of this rendition
+			if !m.storageService.IsLocal() {
+				if err := st.DeletePrefix(m.ctx, hlsDir); err != nil {
+					log.WithError(err).WithField("type", rr.Type).Warnln("failed to delete HLS rendition from storage")
+				}
+			}
+			continue
+		}
+
+		_ = os.Remove(m.renditionLocalPath(rr.FilePath))
+		if !m.storageService.IsLocal() {
+			if err := st.Delete(m.ctx, rr.FilePath); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+				log.WithError(err).WithField("type", rr.Type).Warnln("failed to delete rendition from storage")
+			}
+		}
+	}
+
+	if _, err := m.ds.DeleteRecordingRenditions(recordId); err != nil {
+		log.WithError(err).Warnln("failed to delete recording renditions from db")
+	}
+}
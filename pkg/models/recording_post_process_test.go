@@ -0,0 +1,28 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuildHlsArgs(t *testing.T) {
+	args := buildHlsArgs("in.mp4", "out", []int{720, 360}, 6, true)
+	if slices.Contains(args, "0:a:0") || !slices.Contains(args, "0:a:0?") {
+		t.Error("audio must be mapped as optional")
+	}
+	if i := slices.Index(args, "-var_stream_map"); i < 0 || args[i+1] != "v:0,a:0 v:1,a:1" {
+		t.Errorf("unexpected stream map: %v", args)
+	}
+
+	args = buildHlsArgs("in.mp4", "out", []int{720, 360}, 6, false)
+	if slices.Contains(args, "0:a:0?") || slices.Contains(args, "-c:a") {
+		t.Error("audio must not be used for recordings without audio")
+	}
+	if i := slices.Index(args, "-var_stream_map"); i < 0 || args[i+1] != "v:0 v:1" {
+		t.Errorf("unexpected stream map: %v", args)
+	}
+	if !strings.HasSuffix(args[len(args)-1], "playlist.m3u8") {
+		t.Errorf("playlist must be the output: %s", args[len(args)-1])
+	}
+}
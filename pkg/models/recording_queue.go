@@ -0,0 +1,247 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// queue positions of the requests are broadcast to the room using these keys of metadata extra_data
+	recordingQueuePositionExtraDataKey = "recording_queue_position"
+	rtmpQueuePositionExtraDataKey      = "rtmp_queue_position"
+)
+
+func recorderQueueMember(roomId string, task plugnmeet.RecordingTasks) string {
+	return roomId + ":" + task.String()
+}
+
+func parseRecorderQueueMember(member string) (string, plugnmeet.RecordingTasks, bool) {
+	idx := strings.LastIndex(member, ":")
+	if idx < 1 {
+		return "", 0, false
+	}
+	task, ok := plugnmeet.RecordingTasks_value[member[idx+1:]]
+	if !ok {
+		return "", 0, false
+	}
+	return member[:idx], plugnmeet.RecordingTasks(task), true
+}
+
+func (m *RecordingModel) isRecorderQueueEnabled() bool {
+	return m.app.RecorderInfo.Queue != nil && m.app.RecorderInfo.Queue.Enabled
+}
+
+// hasQueuedRecorderTasksFor checks if any request waiting in the queue before this one could use the recorder.
+// A queued request is waiting behind all the others in the queue, a new one behind the whole queue.
+// Requests whose affinity doesn't match the recorder won't hold it back.
+func (m *RecordingModel) hasQueuedRecorderTasksFor(recorderId string, req *plugnmeet.RecordingReq, fromQueue bool, log *logrus.Entry) bool {
+	if !m.isRecorderQueueEnabled() {
+		return false
+	}
+	members, err := m.recorderStore.GetRecorderQueue()
+	if err != nil {
+		log.WithError(err).Errorln("failed to get recorder queue")
+		return false
+	}
+
+	own := recorderQueueMember(req.RoomId, req.Task)
+	labels := m.recorders.GetRecorderLabels(recorderId)
+	for _, member := range members {
+		if member == own {
+			if fromQueue {
+				return false
+			}
+			continue
+		}
+		roomId, _, ok := parseRecorderQueueMember(member)
+		if !ok {
+			continue
+		}
+		meta, err := m.recorders.GetRoomMetadataStruct(roomId)
+		if err != nil || meta == nil {
+			// the room has ended, the request will be removed by the queue
+			continue
+		}
+		if matchRecorderAffinity(labels, natsservice.ParseRecorderLabels(meta.GetExtraData()[recorderAffinityExtraDataKey])) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *RecordingModel) onNoRecorderAvailable(req *plugnmeet.RecordingReq, fromQueue bool, log *logrus.Entry) error {
+	if fromQueue || !m.isRecorderQueueEnabled() {
+		return config.ErrNoRecorderAvailable
+	}
+	return m.enqueueRecorderTask(req, log)
+}
+
+// enqueueRecorderTask adds the request to the recorder queue, it returns config.ErrRecorderTaskQueued on success
+func (m *RecordingModel) enqueueRecorderTask(req *plugnmeet.RecordingReq, log *logrus.Entry) error {
+	member := recorderQueueMember(req.RoomId, req.Task)
+	now := time.Now().UnixMilli()
+
+	added, err := m.recorderStore.AddToRecorderQueue(member, now)
+	if err != nil {
+		log.WithError(err).Errorln("failed to add request to the recorder queue")
+		return err
+	}
+	if !added {
+		// the same request is waiting already
+		return config.ErrRecorderTaskQueued
+	}
+
+	data, err := proto.Marshal(req)
+	if err == nil {
+		err = m.natsService.PublishRecorderQueueRequest(fmt.Sprintf("%s-%d", member, now), data)
+	}
+	if err != nil {
+		log.WithError(err).Errorln("failed to publish request to the recorder queue")
+		_, _ = m.recorderStore.RemoveFromRecorderQueue(member)
+		return err
+	}
+
+	log.Infoln("no recorder available, request has been queued")
+	m.broadcastRecorderQueuePositions(log)
+	return config.ErrRecorderTaskQueued
+}
+
+// ProcessQueuedRecorderTask tries to dispatch the queued request.
+// It returns false if the request should wait for a free recorder, the queue will try it again later
+// & meanwhile the following requests can be dispatched if a recorder matching their affinity is free.
+func (m *RecordingModel) ProcessQueuedRecorderTask(data []byte) bool {
+	req := new(plugnmeet.RecordingReq)
+	if err := proto.Unmarshal(data, req); err != nil {
+		m.logger.WithError(err).Errorln("failed to unmarshal queued recorder task")
+		return true
+	}
+	log := m.logger.WithFields(logrus.Fields{
+		"roomId": req.RoomId,
+		"task":   req.Task.String(),
+		"method": "ProcessQueuedRecorderTask",
+	})
+
+	enqueuedAt, err := m.recorderStore.GetRecorderQueueEnqueuedAt(recorderQueueMember(req.RoomId, req.Task))
+	if err != nil {
+		log.WithError(err).Errorln("failed to get recorder queue entry")
+		return false
+	}
+	if enqueuedAt == 0 {
+		log.Infoln("request was cancelled, skipping")
+		return true
+	}
+
+	room, err := m.ds.GetRoomInfoBySid(req.Sid, new(1))
+	if err != nil {
+		log.WithError(err).Errorln("failed to get room info")
+		return false
+	}
+	if room == nil ||
+		(req.Task == plugnmeet.RecordingTasks_START_RECORDING && room.IsRecording == 1) ||
+		(req.Task == plugnmeet.RecordingTasks_START_RTMP && room.IsActiveRtmp == 1) {
+		log.Infoln("room isn't running or the task is already active, removing request from the queue")
+		m.removeFromRecorderQueue(req.RoomId, req.Task, log)
+		return true
+	}
+
+	if time.Since(time.UnixMilli(enqueuedAt)) > m.app.RecorderInfo.Queue.MaxWaitTime {
+		log.Warnln("no recorder became free within max_wait_time, removing request from the queue")
+		m.removeFromRecorderQueue(req.RoomId, req.Task, log)
+		_ = m.natsService.NotifyErrorMsg(req.RoomId, config.ErrNoRecorderAvailable.Error(), nil)
+		return true
+	}
+
+	err = m.dispatchRecorderTask(req, true)
+	if errors.Is(err, config.ErrNoRecorderAvailable) {
+		return false
+	}
+	m.removeFromRecorderQueue(req.RoomId, req.Task, log)
+	if err != nil {
+		_ = m.natsService.NotifyErrorMsg(req.RoomId, err.Error(), nil)
+		return true
+	}
+	log.Infof("dispatched queued request after %s", time.Since(time.UnixMilli(enqueuedAt)))
+	return true
+}
+
+// CancelQueuedRecorderTask removes the start task of the room from the queue,
+// returns false if the task wasn't queued
+func (m *RecordingModel) CancelQueuedRecorderTask(roomId string, task plugnmeet.RecordingTasks) bool {
+	if !m.isRecorderQueueEnabled() {
+		return false
+	}
+	log := m.logger.WithFields(logrus.Fields{
+		"roomId": roomId,
+		"task":   task.String(),
+		"method": "CancelQueuedRecorderTask",
+	})
+	return m.removeFromRecorderQueue(roomId, task, log)
+}
+
+func (m *RecordingModel) removeFromRecorderQueue(roomId string, task plugnmeet.RecordingTasks, log *logrus.Entry) bool {
+	removed, err := m.recorderStore.RemoveFromRecorderQueue(recorderQueueMember(roomId, task))
+	if err != nil {
+		log.WithError(err).Errorln("failed to remove request from the recorder queue")
+		return false
+	}
+	if removed == 0 {
+		return false
+	}
+
+	m.setRecorderQueuePosition(roomId, task, "", log)
+	m.broadcastRecorderQueuePositions(log)
+	return true
+}
+
+// broadcastRecorderQueuePositions updates the positions in the metadata of the rooms waiting in the queue
+func (m *RecordingModel) broadcastRecorderQueuePositions(log *logrus.Entry) {
+	members, err := m.recorderStore.GetRecorderQueue()
+	if err != nil {
+		log.WithError(err).Errorln("failed to get recorder queue")
+		return
+	}
+	for i, member := range members {
+		roomId, task, ok := parseRecorderQueueMember(member)
+		if !ok {
+			continue
+		}
+		m.setRecorderQueuePosition(roomId, task, strconv.Itoa(i+1), log)
+	}
+}
+
+// setRecorderQueuePosition broadcasts the position if it was changed, an empty position will remove it
+func (m *RecordingModel) setRecorderQueuePosition(roomId string, task plugnmeet.RecordingTasks, position string, log *logrus.Entry) {
+	key := recordingQueuePositionExtraDataKey
+	if task == plugnmeet.RecordingTasks_START_RTMP {
+		key = rtmpQueuePositionExtraDataKey
+	}
+
+	meta, err := m.recorders.GetRoomMetadataStruct(roomId)
+	if err != nil || meta == nil {
+		return
+	}
+	if meta.ExtraData[key] == position {
+		return
+	}
+	if position == "" {
+		delete(meta.ExtraData, key)
+	} else {
+		if meta.ExtraData == nil {
+			meta.ExtraData = make(map[string]string)
+		}
+		meta.ExtraData[key] = position
+	}
+
+	if err := m.recorders.UpdateAndBroadcastRoomMetadata(roomId, meta); err != nil {
+		log.WithError(err).WithField("roomId", roomId).Errorln("failed to broadcast recorder queue position")
+	}
+}
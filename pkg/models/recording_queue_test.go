@@ -0,0 +1,90 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+)
+
+func TestHasQueuedRecorderTasksFor(t *testing.T) {
+	labels := map[string]map[string]string{
+		"rec01": {"region": "eu"},
+	}
+	rooms := map[string]string{
+		"room01": `{}`,
+		"room02": `{}`,
+		"room03": `{"extra_data": {"recorder_affinity": "region=us"}}`,
+		"room04": `{"extra_data": {"recorder_affinity": "region=eu"}}`,
+	}
+	req := &plugnmeet.RecordingReq{RoomId: "room01", Task: plugnmeet.RecordingTasks_START_RECORDING}
+	own := recorderQueueMember("room01", plugnmeet.RecordingTasks_START_RECORDING)
+
+	tests := []struct {
+		name      string
+		disabled  bool
+		queue     []string
+		fromQueue bool
+		want      bool
+	}{
+		{
+			name: "empty queue",
+		},
+		{
+			name:     "queue is disabled",
+			disabled: true,
+			queue:    []string{recorderQueueMember("room02", plugnmeet.RecordingTasks_START_RECORDING)},
+		},
+		{
+			name:  "new request behind a queued one",
+			queue: []string{recorderQueueMember("room02", plugnmeet.RecordingTasks_START_RTMP)},
+			want:  true,
+		},
+		{
+			name:  "queued request with other affinity",
+			queue: []string{recorderQueueMember("room03", plugnmeet.RecordingTasks_START_RECORDING)},
+		},
+		{
+			name:  "queued request with matching affinity",
+			queue: []string{recorderQueueMember("room04", plugnmeet.RecordingTasks_START_RECORDING)},
+			want:  true,
+		},
+		{
+			name:  "queued request of ended room",
+			queue: []string{recorderQueueMember("room05", plugnmeet.RecordingTasks_START_RECORDING)},
+		},
+		{
+			name:      "own request is the first one",
+			queue:     []string{own, recorderQueueMember("room02", plugnmeet.RecordingTasks_START_RECORDING)},
+			fromQueue: true,
+		},
+		{
+			name:      "own request is behind another one",
+			queue:     []string{recorderQueueMember("room02", plugnmeet.RecordingTasks_START_RECORDING), own},
+			fromQueue: true,
+			want:      true,
+		},
+		{
+			name:  "new request skips its own queued one",
+			queue: []string{own},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryRecorderStore()
+			for i, member := range tt.queue {
+				_, _ = store.AddToRecorderQueue(member, int64(i+1))
+			}
+			dir := &memoryRecorderDirectory{labels: labels, rooms: make(map[string]*plugnmeet.RoomMetadata)}
+			for roomId, meta := range rooms {
+				dir.rooms[roomId] = testRoomMetadata(t, meta)
+			}
+			m := newTestRecorderModel(store, dir)
+			m.app.RecorderInfo.Queue.Enabled = !tt.disabled
+
+			if got := m.hasQueuedRecorderTasksFor("rec01", req, tt.fromQueue, m.logger); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-protocol/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// recorderAffinityExtraDataKey is the key of room metadata extra_data to select the recorders by their labels,
+	// e.g. region=eu-west,tier=gpu. A label without value only needs to be present in the recorder.
+	recorderAffinityExtraDataKey = "recorder_affinity"
+	// recorderReservationExtraDataKey is the key of room metadata extra_data to reserve a recorder at creation
+	recorderReservationExtraDataKey = "recorder_reservation"
+	// maxRecorderReservationAttempts is how many recorders will be tried if the selected one is taken concurrently
+	maxRecorderReservationAttempts = 3
+)
+
+// recorderStore keeps the reservations & the queue of the recorders,
+// it's implemented by the RedisService
+type recorderStore interface {
+	GetRecorderReservations() (map[string]string, error)
+	ReserveRecorder(roomId, recorderId string, maxLimit, currentProgress int64) (bool, error)
+	SetRecorderReservation(roomId, recorderId string) error
+	DeleteRecorderReservation(roomId string) error
+	AddToRecorderQueue(member string, enqueuedAt int64) (bool, error)
+	GetRecorderQueueEnqueuedAt(member string) (int64, error)
+	GetRecorderQueue() ([]string, error)
+	RemoveFromRecorderQueue(members ...string) (int64, error)
+}
+
+// recorderDirectory knows the active recorders & the metadata of the rooms waiting for them,
+// it's implemented by the NatsService
+type recorderDirectory interface {
+	GetAllActiveRecorders() []*utils.RecorderInfo
+	GetRecorderLabels(recorderId string) map[string]string
+	GetRoomMetadataStruct(roomId string) (*plugnmeet.RoomMetadata, error)
+	UpdateAndBroadcastRoomMetadata(roomId string, meta interface{}) error
+}
+
+// matchRecorderAffinity checks if the labels of the recorder satisfy all the selectors
+func matchRecorderAffinity(labels, affinity map[string]string) bool {
+	for k, v := range affinity {
+		lv, ok := labels[k]
+		if !ok || (v != "" && lv != v) {
+			return false
+		}
+	}
+	return true
+}
+
+// ReserveRecorder reserves a slot of a recorder for the room, if the room has requested it.
+// The reservation is best effort, the room will start without it if no recorder has free capacity.
+func (m *RecordingModel) ReserveRecorder(roomId string, metadata *plugnmeet.RoomMetadata, log *logrus.Entry) {
+	if metadata.GetExtraData()[recorderReservationExtraDataKey] != "true" {
+		return
+	}
+	log = log.WithField("method", "ReserveRecorder")
+	if !metadata.GetRoomFeatures().GetRecordingFeatures().GetIsAllow() {
+		log.Infoln("recording isn't allowed in this room, skipping recorder reservation")
+		return
+	}
+
+	// other rooms may take the last slot of the selected recorder meanwhile, then we'll try the next one
+	for range maxRecorderReservationAttempts {
+		recorder, reserved := m.selectRecorder(roomId, plugnmeet.RecordingTasks_START_RECORDING, metadata.GetExtraData(), log)
+		if recorder == nil {
+			log.Warnln("no recorder with free capacity to reserve")
+			return
+		}
+		if reserved {
+			return
+		}
+		ok, err := m.recorderStore.ReserveRecorder(roomId, recorder.RecorderId, recorder.MaxLimit, recorder.CurrentProgress)
+		if err != nil {
+			log.WithError(err).Errorln("failed to reserve recorder")
+			return
+		}
+		if ok {
+			log.WithField("recorderId", recorder.RecorderId).Infoln("reserved recorder for the room")
+			return
+		}
+	}
+	log.Warnln("no recorder with free capacity to reserve")
+}
+
+// consumeRecorderReservation removes the reservation once the recording has started,
+// as the recorder is counting it as progress from now on
+func (m *RecordingModel) consumeRecorderReservation(r *plugnmeet.RecorderToPlugNmeet) {
+	if err := m.recorderStore.DeleteRecorderReservation(r.RoomId); err != nil {
+		m.logger.WithError(err).WithField("roomId", r.RoomId).Errorln("failed to delete recorder reservation")
+	}
+}
+
+// renewRecorderReservation reserves the same recorder again after the recording has ended,
+// so the room can start the recording again while it's running
+func (m *RecordingModel) renewRecorderReservation(r *plugnmeet.RecorderToPlugNmeet) {
+	if r.RecorderId == "" {
+		return
+	}
+	meta, err := m.recorders.GetRoomMetadataStruct(r.RoomId)
+	if err != nil || meta == nil || meta.GetExtraData()[recorderReservationExtraDataKey] != "true" {
+		return
+	}
+	if room, err := m.ds.GetRoomInfoBySid(r.RoomSid, new(1)); err != nil || room == nil {
+		return
+	}
+	if err := m.recorderStore.SetRecorderReservation(r.RoomId, r.RecorderId); err != nil {
+		m.logger.WithError(err).WithField("roomId", r.RoomId).Errorln("failed to renew recorder reservation")
+	}
+}
+
+// ReleaseRecorderResources removes the reservation & the queued tasks of the room, it's called when the room ends
+func (m *RecordingModel) ReleaseRecorderResources(roomId string, log *logrus.Entry) {
+	if err := m.recorderStore.DeleteRecorderReservation(roomId); err != nil {
+		log.WithError(err).Errorln("failed to delete recorder reservation")
+	}
+	m.CancelQueuedRecorderTask(roomId, plugnmeet.RecordingTasks_START_RECORDING)
+	m.CancelQueuedRecorderTask(roomId, plugnmeet.RecordingTasks_START_RTMP)
+}
@@ -0,0 +1,257 @@
+package models
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-protocol/utils"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// memoryRecorderStore works like the redis service, without the expiry
+type memoryRecorderStore struct {
+	reservations map[string]string
+	queue        []string
+	enqueuedAt   map[string]int64
+	// beforeReserve runs before the next reservation, e.g. to take the slot concurrently
+	beforeReserve func()
+}
+
+func newMemoryRecorderStore() *memoryRecorderStore {
+	return &memoryRecorderStore{reservations: make(map[string]string), enqueuedAt: make(map[string]int64)}
+}
+
+func (s *memoryRecorderStore) GetRecorderReservations() (map[string]string, error) {
+	return maps.Clone(s.reservations), nil
+}
+
+func (s *memoryRecorderStore) ReserveRecorder(roomId, recorderId string, maxLimit, currentProgress int64) (bool, error) {
+	if s.beforeReserve != nil {
+		s.beforeReserve()
+		s.beforeReserve = nil
+	}
+	var reserved int64
+	for rId, recId := range s.reservations {
+		if rId != roomId && recId == recorderId {
+			reserved++
+		}
+	}
+	if maxLimit > 0 && currentProgress+reserved >= maxLimit {
+		return false, nil
+	}
+	s.reservations[roomId] = recorderId
+	return true, nil
+}
+
+func (s *memoryRecorderStore) SetRecorderReservation(roomId, recorderId string) error {
+	s.reservations[roomId] = recorderId
+	return nil
+}
+
+func (s *memoryRecorderStore) DeleteRecorderReservation(roomId string) error {
+	delete(s.reservations, roomId)
+	return nil
+}
+
+func (s *memoryRecorderStore) AddToRecorderQueue(member string, enqueuedAt int64) (bool, error) {
+	if _, ok := s.enqueuedAt[member]; ok {
+		return false, nil
+	}
+	s.enqueuedAt[member] = enqueuedAt
+	s.queue = append(s.queue, member)
+	return true, nil
+}
+
+func (s *memoryRecorderStore) GetRecorderQueueEnqueuedAt(member string) (int64, error) {
+	return s.enqueuedAt[member], nil
+}
+
+func (s *memoryRecorderStore) GetRecorderQueue() ([]string, error) {
+	return slices.Clone(s.queue), nil
+}
+
+func (s *memoryRecorderStore) RemoveFromRecorderQueue(members ...string) (int64, error) {
+	var removed int64
+	for _, member := range members {
+		if i := slices.Index(s.queue, member); i >= 0 {
+			s.queue = slices.Delete(s.queue, i, i+1)
+			delete(s.enqueuedAt, member)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// memoryRecorderDirectory works like the nats service with the recorders & rooms in its cache
+type memoryRecorderDirectory struct {
+	recorders []*utils.RecorderInfo
+	labels    map[string]map[string]string
+	rooms     map[string]*plugnmeet.RoomMetadata
+}
+
+func (d *memoryRecorderDirectory) GetAllActiveRecorders() []*utils.RecorderInfo {
+	return d.recorders
+}
+
+func (d *memoryRecorderDirectory) GetRecorderLabels(recorderId string) map[string]string {
+	return d.labels[recorderId]
+}
+
+func (d *memoryRecorderDirectory) GetRoomMetadataStruct(roomId string) (*plugnmeet.RoomMetadata, error) {
+	meta, ok := d.rooms[roomId]
+	if !ok {
+		return nil, nil
+	}
+	return proto.Clone(meta).(*plugnmeet.RoomMetadata), nil
+}
+
+func (d *memoryRecorderDirectory) UpdateAndBroadcastRoomMetadata(roomId string, meta interface{}) error {
+	d.rooms[roomId] = meta.(*plugnmeet.RoomMetadata)
+	return nil
+}
+
+func newTestRecorderModel(store *memoryRecorderStore, dir *memoryRecorderDirectory) *RecordingModel {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return &RecordingModel{
+		app: &config.AppConfig{
+			RecorderInfo: config.RecorderInfo{Queue: &config.RecorderQueue{Enabled: true}},
+		},
+		recorderStore: store,
+		recorders:     dir,
+		logger:        logrus.NewEntry(logger),
+	}
+}
+
+func testRoomMetadata(t *testing.T, js string) *plugnmeet.RoomMetadata {
+	t.Helper()
+	meta := new(plugnmeet.RoomMetadata)
+	if err := protojson.Unmarshal([]byte(js), meta); err != nil {
+		t.Fatal(err)
+	}
+	return meta
+}
+
+func TestReserveRecorder(t *testing.T) {
+	const reservationMeta = `{"room_features": {"recording_features": {"is_allow": true}}, "extra_data": {"recorder_reservation": "true"%s}}`
+	newRecorders := func() []*utils.RecorderInfo {
+		return []*utils.RecorderInfo{
+			{RecorderId: "rec01", MaxLimit: 2, CurrentProgress: 0},
+			{RecorderId: "rec02", MaxLimit: 2, CurrentProgress: 1},
+		}
+	}
+	labels := map[string]map[string]string{
+		"rec01": {"region": "eu"},
+		"rec02": {"region": "us", "tier": "gpu"},
+	}
+
+	tests := []struct {
+		name         string
+		meta         string
+		reservations map[string]string
+		// taken is the reservation of another room made concurrently
+		taken map[string]string
+		want  string
+	}{
+		{
+			name: "reservation wasn't requested",
+			meta: `{"room_features": {"recording_features": {"is_allow": true}}}`,
+		},
+		{
+			name: "recording isn't allowed",
+			meta: `{"extra_data": {"recorder_reservation": "true"}}`,
+		},
+		{
+			name: "least busy recorder",
+			meta: fmt.Sprintf(reservationMeta, ""),
+			want: "rec01",
+		},
+		{
+			name: "recorders with matching affinity",
+			meta: fmt.Sprintf(reservationMeta, `, "recorder_affinity": "tier=gpu"`),
+			want: "rec02",
+		},
+		{
+			name:         "reservations of other rooms take the slots",
+			meta:         fmt.Sprintf(reservationMeta, ""),
+			reservations: map[string]string{"room02": "rec01", "room03": "rec01"},
+			want:         "rec02",
+		},
+		{
+			name:         "already reserved recorder is kept",
+			meta:         fmt.Sprintf(reservationMeta, ""),
+			reservations: map[string]string{"room01": "rec02"},
+			want:         "rec02",
+		},
+		{
+			name:  "next recorder if the slot was taken meanwhile",
+			meta:  fmt.Sprintf(reservationMeta, ""),
+			taken: map[string]string{"room02": "rec01", "room03": "rec01"},
+			want:  "rec02",
+		},
+		{
+			name:         "no free slot",
+			meta:         fmt.Sprintf(reservationMeta, ""),
+			reservations: map[string]string{"room02": "rec01", "room03": "rec01", "room04": "rec02"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryRecorderStore()
+			maps.Copy(store.reservations, tt.reservations)
+			if tt.taken != nil {
+				store.beforeReserve = func() { maps.Copy(store.reservations, tt.taken) }
+			}
+			m := newTestRecorderModel(store, &memoryRecorderDirectory{recorders: newRecorders(), labels: labels})
+
+			m.ReserveRecorder("room01", testRoomMetadata(t, tt.meta), m.logger)
+			if got := store.reservations["room01"]; got != tt.want {
+				t.Errorf("expected reservation %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestReleaseRecorderResources(t *testing.T) {
+	store := newMemoryRecorderStore()
+	store.reservations["room01"] = "rec01"
+	store.reservations["room02"] = "rec01"
+	for i, member := range []string{
+		recorderQueueMember("room01", plugnmeet.RecordingTasks_START_RECORDING),
+		recorderQueueMember("room02", plugnmeet.RecordingTasks_START_RECORDING),
+		recorderQueueMember("room01", plugnmeet.RecordingTasks_START_RTMP),
+	} {
+		_, _ = store.AddToRecorderQueue(member, int64(i+1))
+	}
+	dir := &memoryRecorderDirectory{rooms: map[string]*plugnmeet.RoomMetadata{
+		"room01": testRoomMetadata(t, `{"extra_data": {"recording_queue_position": "1", "rtmp_queue_position": "3"}}`),
+		"room02": testRoomMetadata(t, `{"extra_data": {"recording_queue_position": "2"}}`),
+	}}
+	m := newTestRecorderModel(store, dir)
+
+	m.ReleaseRecorderResources("room01", m.logger)
+
+	if _, ok := store.reservations["room01"]; ok {
+		t.Error("reservation of the room must be removed")
+	}
+	if store.reservations["room02"] != "rec01" {
+		t.Error("reservation of the other room must be kept")
+	}
+	if want := []string{recorderQueueMember("room02", plugnmeet.RecordingTasks_START_RECORDING)}; !slices.Equal(store.queue, want) {
+		t.Errorf("expected queue %v, got %v", want, store.queue)
+	}
+	if extra := dir.rooms["room01"].GetExtraData(); extra[recordingQueuePositionExtraDataKey] != "" || extra[rtmpQueuePositionExtraDataKey] != "" {
+		t.Errorf("queue positions of the released room must be removed: %v", extra)
+	}
+	if pos := dir.rooms["room02"].GetExtraData()[recordingQueuePositionExtraDataKey]; pos != "1" {
+		t.Errorf("the other room must move to the first position, got %q", pos)
+	}
+}
@@ -0,0 +1,189 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/mynaparrot/plugnmeet-server/pkg/helpers"
+	"github.com/sirupsen/logrus"
+)
+
+const recordingExpiringEvent = "recording_expiring"
+
+type recordingRetentionAction int
+
+const (
+	recordingRetentionKeep recordingRetentionAction = iota
+	recordingRetentionNotify
+	recordingRetentionDelete
+)
+
+// RecordingRetentionSettings overrides the days to keep the recordings,
+// nil value means the value of the upper level (tenant or config) will be used & 0 means keep forever.
+type RecordingRetentionSettings struct {
+	PublishedDays   *int `json:"published_days,omitempty"`
+	UnpublishedDays *int `json:"unpublished_days,omitempty"`
+}
+
+func (r *RecordingRetentionSettings) validate() error {
+	if r == nil {
+		return nil
+	}
+	if (r.PublishedDays != nil && *r.PublishedDays < 0) || (r.UnpublishedDays != nil && *r.UnpublishedDays < 0) {
+		return fmt.Errorf("recording retention days can't be negative, use 0 to keep forever")
+	}
+	return nil
+}
+
+func (r *RecordingRetentionSettings) values() (published, unpublished *int) {
+	if r == nil {
+		return nil, nil
+	}
+	return r.PublishedDays, r.UnpublishedDays
+}
+
+func toRecordingRetentionSettings(published, unpublished *int) *RecordingRetentionSettings {
+	if published == nil && unpublished == nil {
+		return nil
+	}
+	return &RecordingRetentionSettings{
+		PublishedDays:   published,
+		UnpublishedDays: unpublished,
+	}
+}
+
+// recordingRetentionPolicies resolves the days to keep a recording,
+// the template of the room will win over the tenant, which will win over the config.
+type recordingRetentionPolicies struct {
+	published   int
+	unpublished int
+	tenants     map[string]*RecordingRetentionSettings
+	// templates are kept with their owner tenant
+	templates       map[string]*RecordingRetentionSettings
+	templateTenants map[string]string
+}
+
+func newRecordingRetentionPolicies(published, unpublished int, tenants []dbmodels.Tenant, templates []dbmodels.RoomTemplate) *recordingRetentionPolicies {
+	p := &recordingRetentionPolicies{
+		published:       published,
+		unpublished:     unpublished,
+		tenants:         make(map[string]*RecordingRetentionSettings, len(tenants)),
+		templates:       make(map[string]*RecordingRetentionSettings, len(templates)),
+		templateTenants: make(map[string]string, len(templates)),
+	}
+	for _, t := range tenants {
+		p.tenants[t.TenantId] = toRecordingRetentionSettings(t.RecordingRetentionPublishedDays, t.RecordingRetentionUnpublishedDays)
+	}
+	for _, t := range templates {
+		p.templates[t.TemplateId] = toRecordingRetentionSettings(t.RecordingRetentionPublishedDays, t.RecordingRetentionUnpublishedDays)
+		p.templateTenants[t.TemplateId] = t.TenantId
+	}
+	return p
+}
+
+// minDays returns the lowest number of days of all the policies, 0 if all keep forever
+func (p *recordingRetentionPolicies) minDays() int {
+	days := 0
+	check := func(d int) {
+		if d > 0 && (days == 0 || d < days) {
+			days = d
+		}
+	}
+	check(p.published)
+	check(p.unpublished)
+	for _, s := range []map[string]*RecordingRetentionSettings{p.tenants, p.templates} {
+		for _, r := range s {
+			pub, unpub := r.values()
+			if pub != nil {
+				check(*pub)
+			}
+			if unpub != nil {
+				check(*unpub)
+			}
+		}
+	}
+	return days
+}
+
+// daysFor returns the days to keep the recording, 0 means keep forever
+func (p *recordingRetentionPolicies) daysFor(rec *dbmodels.Recording) int {
+	pick := func(r *RecordingRetentionSettings) *int {
+		pub, unpub := r.values()
+		if rec.Published == 1 {
+			return pub
+		}
+		return unpub
+	}
+
+	// template will only be used if it is shared or belongs to the tenant of the recording
+	if rec.TemplateId != "" {
+		if owner, ok := p.templateTenants[rec.TemplateId]; ok && (owner == "" || owner == rec.TenantId) {
+			if d := pick(p.templates[rec.TemplateId]); d != nil {
+				return *d
+			}
+		}
+	}
+	if rec.TenantId != "" {
+		if d := pick(p.tenants[rec.TenantId]); d != nil {
+			return *d
+		}
+	}
+	if rec.Published == 1 {
+		return p.published
+	}
+	return p.unpublished
+}
+
+// recordingRetentionActionFor decides what to do with the recording expiring at expireAt & returns when
+// it will be deleted. A recording won't be deleted before its recording_expiring notification is notifyBefore old,
+// so the ones already expired when the retention was enabled will be notified first too.
+func recordingRetentionActionFor(now, expireAt time.Time, notifiedAt int64, notifyBefore time.Duration) (recordingRetentionAction, time.Time) {
+	if notifiedAt == 0 {
+		deleteAt := expireAt
+		if notifyAt := now.Add(notifyBefore); notifyAt.After(deleteAt) {
+			deleteAt = notifyAt
+		}
+		if now.After(expireAt.Add(-notifyBefore)) {
+			return recordingRetentionNotify, deleteAt
+		}
+		return recordingRetentionKeep, deleteAt
+	}
+
+	deleteAt := expireAt
+	if notifyEnd := time.Unix(notifiedAt, 0).Add(notifyBefore); notifyEnd.After(deleteAt) {
+		deleteAt = notifyEnd
+	}
+	if now.After(deleteAt) {
+		return recordingRetentionDelete, deleteAt
+	}
+	return recordingRetentionKeep, deleteAt
+}
+
+// sendRecordingExpiringWebhook notifies that the recording will be deleted soon by the retention policy
+func (m *RecordingModel) sendRecordingExpiringWebhook(rec *dbmodels.Recording, expireAt time.Time, log *logrus.Entry) {
+	if m.webhookNotifier == nil {
+		return
+	}
+	size, _ := strconv.ParseFloat(rec.Size, 32)
+	fileSize := helpers.ToFixed(float32(size), 2)
+	msg := &plugnmeet.CommonNotifyEvent{
+		Event: new(recordingExpiringEvent),
+		Room: &plugnmeet.NotifyEventRoom{
+			Sid:    &rec.RoomSid.String,
+			RoomId: &rec.RoomID,
+		},
+		RecordingInfo: &plugnmeet.RecordingInfoEvent{
+			RecordId:    rec.RecordID,
+			RecorderId:  rec.RecorderID,
+			RecorderMsg: fmt.Sprintf("recording will be deleted after %s", expireAt.UTC().Format(time.RFC3339)),
+			FilePath:    &rec.FilePath,
+			FileSize:    &fileSize,
+		},
+	}
+	// the session has ended long ago, so the webhook url should be retrieved from DB
+	m.webhookNotifier.ForceToPutInQueue(msg)
+	log.WithField("expireAt", expireAt.UTC().Format(time.RFC3339)).Infoln("sent recording_expiring webhook")
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+)
+
+func TestRecordingRetentionPolicies(t *testing.T) {
+	tenants := []dbmodels.Tenant{
+		{TenantId: "tenant01", RecordingRetentionPublishedDays: new(60)},
+		{TenantId: "tenant02", RecordingRetentionPublishedDays: new(0), RecordingRetentionUnpublishedDays: new(5)},
+	}
+	templates := []dbmodels.RoomTemplate{
+		{TemplateId: "shared", RecordingRetentionUnpublishedDays: new(3)},
+		{TemplateId: "own", TenantId: "tenant01", RecordingRetentionPublishedDays: new(10)},
+		{TemplateId: "other", TenantId: "tenant02", RecordingRetentionPublishedDays: new(1)},
+	}
+	p := newRecordingRetentionPolicies(30, 7, tenants, templates)
+
+	for _, tc := range []struct {
+		name string
+		rec  dbmodels.Recording
+		want int
+	}{
+		{"config published", dbmodels.Recording{Published: 1}, 30},
+		{"config unpublished", dbmodels.Recording{Published: 0}, 7},
+		{"tenant override", dbmodels.Recording{TenantId: "tenant01", Published: 1}, 60},
+		{"tenant without value uses config", dbmodels.Recording{TenantId: "tenant01", Published: 0}, 7},
+		{"tenant keeps forever", dbmodels.Recording{TenantId: "tenant02", Published: 1}, 0},
+		{"template wins over tenant", dbmodels.Recording{TenantId: "tenant01", TemplateId: "own", Published: 1}, 10},
+		{"template without value uses tenant", dbmodels.Recording{TenantId: "tenant02", TemplateId: "shared", Published: 1}, 0},
+		{"shared template", dbmodels.Recording{TenantId: "tenant02", TemplateId: "shared", Published: 0}, 3},
+		{"template of another tenant is ignored", dbmodels.Recording{TenantId: "tenant01", TemplateId: "other", Published: 1}, 60},
+		{"unknown template", dbmodels.Recording{TemplateId: "removed", Published: 1}, 30},
+	} {
+		if got := p.daysFor(&tc.rec); got != tc.want {
+			t.Errorf("%s: got %d, want %d", tc.name, got, tc.want)
+		}
+	}
+
+	if got := p.minDays(); got != 1 {
+		t.Errorf("minDays: got %d, want 1", got)
+	}
+	if got := newRecordingRetentionPolicies(0, 0, nil, nil).minDays(); got != 0 {
+		t.Errorf("minDays without any policy: got %d, want 0", got)
+	}
+}
+
+func TestRecordingRetentionActionFor(t *testing.T) {
+	day := 24 * time.Hour
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	notifyBefore := 7 * day
+
+	tests := []struct {
+		name       string
+		expireAt   time.Time
+		notifiedAt time.Time
+		want       recordingRetentionAction
+		wantDelete time.Time
+	}{
+		{"not expiring yet", now.Add(10 * day), time.Time{}, recordingRetentionKeep, now.Add(10 * day)},
+		{"expiring soon", now.Add(3 * day), time.Time{}, recordingRetentionNotify, now.Add(7 * day)},
+		{"already expired without notification", now.Add(-30 * day), time.Time{}, recordingRetentionNotify, now.Add(7 * day)},
+		{"notified recently", now.Add(-30 * day), now.Add(-2 * day), recordingRetentionKeep, now.Add(5 * day)},
+		{"notified long enough", now.Add(-day), now.Add(-8 * day), recordingRetentionDelete, now.Add(-day)},
+		{"notified but not expired", now.Add(day), now.Add(-8 * day), recordingRetentionKeep, now.Add(day)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var notifiedAt int64
+			if !tt.notifiedAt.IsZero() {
+				notifiedAt = tt.notifiedAt.Unix()
+			}
+			action, deleteAt := recordingRetentionActionFor(now, tt.expireAt, notifiedAt, notifyBefore)
+			if action != tt.want || !deleteAt.Equal(tt.wantDelete) {
+				t.Errorf("got %d at %s, want %d at %s", action, deleteAt, tt.want, tt.wantDelete)
+			}
+		})
+	}
+
+	// without notify_before_days, it will be notified first & deleted by the next run
+	if action, _ := recordingRetentionActionFor(now, now.Add(-day), 0, 0); action != recordingRetentionNotify {
+		t.Errorf("expired recording must be notified first, got %d", action)
+	}
+	if action, _ := recordingRetentionActionFor(now, now.Add(-day), now.Add(-time.Hour).Unix(), 0); action != recordingRetentionDelete {
+		t.Errorf("notified recording should be deleted, got %d", action)
+	}
+}
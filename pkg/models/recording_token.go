@@ -2,59 +2,129 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/go-jose/go-jose/v4"
 	"github.com/go-jose/go-jose/v4/jwt"
 	"github.com/gofiber/fiber/v3"
-	"github.com/mynaparrot/plugnmeet-protocol/auth"
+	"github.com/google/uuid"
 	"github.com/mynaparrot/plugnmeet-protocol/hooks"
 	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
 	"github.com/mynaparrot/plugnmeet-server/pkg/config"
 	"github.com/mynaparrot/plugnmeet-server/pkg/helpers"
+	"github.com/mynaparrot/plugnmeet-server/pkg/storage"
 )
 
-// GetDownloadToken will use the same JWT token generator as plugNmeet is using
-func (m *RecordingModel) GetDownloadToken(r *plugnmeet.GetDownloadTokenReq) (string, error) {
+// recordingTokenClaims are the additional claims of the download token
+type recordingTokenClaims struct {
+	RecordId   string   `json:"record_id,omitempty"`
+	ViewerId   string   `json:"viewer_id,omitempty"`
+	AllowedIps []string `json:"allowed_ips,omitempty"`
+	MaxUses    int      `json:"max_uses,omitempty"`
+}
+
+// verifiedDownloadToken is the content of a valid download token
+type verifiedDownloadToken struct {
+	claims jwt.Claims
+	extras recordingTokenClaims
+}
+
+// GetDownloadToken generates a token to download the recording,
+// restrictions is optional to bind the token with a viewer, IP ranges & number of uses.
+func (m *RecordingModel) GetDownloadToken(r *plugnmeet.GetDownloadTokenReq, restrictions *RecordingTokenRestrictions) (string, error) {
 	recording, err := m.FetchRecording(r.RecordId)
 	if err != nil {
 		return "", err
 	}
+	if err := restrictions.validate(); err != nil {
+		return "", err
+	}
 
-	return m.CreateTokenForDownload(recording.FilePath)
+	return m.createDownloadToken(recording.FilePath, newRecordingTokenClaims(recording.RecordId, restrictions))
 }
 
-// CreateTokenForDownload will generate token
+// CreateTokenForDownload will generate token, restrictions is optional
 // path format: sub_path/roomSid/filename
-func (m *RecordingModel) CreateTokenForDownload(path string) (string, error) {
-	return auth.GenerateTokenForDownloadRecording(path, m.app.Client.ApiKey, m.app.Client.Secret, m.app.RecorderInfo.TokenValidity)
+func (m *RecordingModel) CreateTokenForDownload(recordId, path string, restrictions *RecordingTokenRestrictions) (string, error) {
+	return m.createDownloadToken(path, newRecordingTokenClaims(recordId, restrictions))
 }
 
-// VerifyRecordingToken verify token & provide file path
-func (m *RecordingModel) VerifyRecordingToken(token string) (*hooks.DownloadHookData, int, error) {
-	log := m.logger.WithField("method", "VerifyRecordingToken")
+func newRecordingTokenClaims(recordId string, restrictions *RecordingTokenRestrictions) *recordingTokenClaims {
+	extras := &recordingTokenClaims{
+		RecordId: recordId,
+	}
+	if restrictions != nil {
+		extras.ViewerId = restrictions.ViewerId
+		extras.AllowedIps = restrictions.AllowedIps
+		extras.MaxUses = restrictions.MaxUses
+	}
+	return extras
+}
+
+// createDownloadToken uses the same claims as the plugNmeet JWT token generator,
+// with an id to count the uses & the extra claims of the recording.
+func (m *RecordingModel) createDownloadToken(path string, extras *recordingTokenClaims) (string, error) {
+	sig, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte(m.natsService.GetClientSecret())}, (&jose.SignerOptions{}).WithType("JWT"))
+	if err != nil {
+		return "", err
+	}
 
+	now := time.Now().UTC()
+	cl := jwt.Claims{
+		ID:        uuid.NewString(),
+		Issuer:    m.app.Client.ApiKey,
+		NotBefore: jwt.NewNumericDate(now),
+		Expiry:    jwt.NewNumericDate(now.Add(m.app.RecorderInfo.TokenValidity)),
+		Subject:   path,
+	}
+	return jwt.Signed(sig).Claims(cl).Claims(extras).Serialize()
+}
+
+// verifyDownloadToken validates the token & returns its claims, subject is the path of the file
+func (m *RecordingModel) verifyDownloadToken(token string) (*verifiedDownloadToken, int, error) {
 	tok, err := jwt.ParseSigned(token, []jose.SignatureAlgorithm{jose.HS256})
 	if err != nil {
 		return nil, fiber.StatusUnauthorized, err
 	}
 
-	out := jwt.Claims{}
-	if err = tok.Claims([]byte(m.app.Client.Secret), &out); err != nil {
+	out := new(verifiedDownloadToken)
+	err = verifyWithSecrets(m.natsService.GetClientSecrets(), func(secret string) error {
+		return tok.Claims([]byte(secret), &out.claims, &out.extras)
+	})
+	if err != nil {
 		return nil, fiber.StatusUnauthorized, err
 	}
 
-	if err = out.Validate(jwt.Expected{Issuer: m.app.Client.ApiKey, Time: time.Now().UTC()}); err != nil {
+	if err = out.claims.Validate(jwt.Expected{Issuer: m.app.Client.ApiKey, Time: time.Now().UTC()}); err != nil {
 		if errors.Is(err, jwt.ErrExpired) {
 			return nil, fiber.StatusUnauthorized, errors.New("token expired")
 		}
 		return nil, fiber.StatusUnauthorized, err
 	}
 
-	inputPath := out.Subject
-	if inputPath == "" {
+	if out.claims.Subject == "" {
 		return nil, fiber.StatusBadRequest, errors.New("invalid file path")
 	}
+	return out, fiber.StatusOK, nil
+}
+
+// VerifyRecordingToken verify token & the restrictions of it for the client, then provide file path
+func (m *RecordingModel) VerifyRecordingToken(token string, access *RecordingAccessReq) (*hooks.DownloadHookData, int, error) {
+	log := m.logger.WithField("method", "VerifyRecordingToken")
+
+	vt, status, err := m.verifyDownloadToken(token)
+	if err != nil {
+		return nil, status, err
+	}
+	if status, err := m.checkRecordingAccess(vt, access, log); err != nil {
+		return nil, status, err
+	}
+	inputPath := vt.claims.Subject
 
 	if m.app.Hooks != nil {
 		// Hooks are defined, so use the pipeline.
@@ -73,6 +143,18 @@ func (m *RecordingModel) VerifyRecordingToken(token string) (*hooks.DownloadHook
 		}
 	}
 
+	if !m.storageService.IsLocal() {
+		res, err := m.storageService.GetDownloadHookData(m.ctx, m.storageService.Recordings(), inputPath)
+		if err == nil {
+			return res, fiber.StatusOK, nil
+		}
+		if !errors.Is(err, config.ErrFileNotFound) {
+			log.WithError(err).Error("failed to get download url from storage")
+			return nil, fiber.StatusInternalServerError, errors.New("failed to get download url from storage")
+		}
+		// may be the upload failed, so we'll check the local copy
+	}
+
 	// If no hooks are defined or no output from script, fallback to local.
 	absolutePath, mType, err := helpers.ValidateAndGetAbsFilePath(m.app.RecorderInfo.RecordingFilesPath, inputPath)
 	if err != nil {
@@ -87,3 +169,82 @@ func (m *RecordingModel) VerifyRecordingToken(token string) (*hooks.DownloadHook
 		MimeType:   mType.String(),
 	}, fiber.StatusOK, nil
 }
+
+// RecordingHlsFile is a file of the HLS rendition to serve.
+// Only one of LocalPath, RedirectUrl or Content will be set.
+type RecordingHlsFile struct {
+	LocalPath   string
+	RedirectUrl string
+	Content     []byte
+	MimeType    string
+}
+
+// CreateHlsPlaybackPath returns the path of the master playlist with a token for the HLS directory,
+// so that players can resolve the relative uris of the playlists. restrictions is optional.
+func (m *RecordingModel) CreateHlsPlaybackPath(recordId, masterPlaylistPath string, restrictions *RecordingTokenRestrictions) (string, error) {
+	token, err := m.CreateTokenForDownload(recordId, path.Dir(masterPlaylistPath), restrictions)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("/download/recording/hls/%s/%s", token, path.Base(masterPlaylistPath)), nil
+}
+
+// VerifyRecordingHlsToken verify token of the HLS directory & provide the requested file of it.
+// Playlists from the storage are served directly as redirected ones can't resolve relative uris.
+func (m *RecordingModel) VerifyRecordingHlsToken(token, file string, access *RecordingAccessReq) (*RecordingHlsFile, int, error) {
+	vt, status, err := m.verifyDownloadToken(token)
+	if err != nil {
+		return nil, status, err
+	}
+	hlsDir := vt.claims.Subject
+	if !strings.HasSuffix(hlsDir, recordingHlsDirSuffix) {
+		return nil, fiber.StatusBadRequest, errors.New("invalid HLS path")
+	}
+	file, err = storage.CleanKey(file)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, err
+	}
+	if status, err := m.checkRecordingAccess(vt, access, m.logger.WithField("method", "VerifyRecordingHlsToken")); err != nil {
+		return nil, status, err
+	}
+	key := path.Join(hlsDir, file)
+
+	mimeType := hlsSegmentMimeType
+	if strings.HasSuffix(file, ".m3u8") {
+		mimeType = hlsPlaylistMimeType
+	}
+
+	localPath := m.renditionLocalPath(key)
+	if _, err := os.Stat(localPath); err == nil {
+		return &RecordingHlsFile{LocalPath: localPath, MimeType: mimeType}, fiber.StatusOK, nil
+	}
+	if m.storageService.IsLocal() {
+		return nil, fiber.StatusNotFound, config.ErrFileNotFound
+	}
+
+	st := m.storageService.Recordings()
+	if mimeType == hlsPlaylistMimeType {
+		r, _, err := st.Get(m.ctx, key)
+		if err != nil {
+			if errors.Is(err, storage.ErrObjectNotFound) {
+				return nil, fiber.StatusNotFound, config.ErrFileNotFound
+			}
+			return nil, fiber.StatusInternalServerError, err
+		}
+		defer r.Close()
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fiber.StatusInternalServerError, err
+		}
+		return &RecordingHlsFile{Content: content, MimeType: mimeType}, fiber.StatusOK, nil
+	}
+
+	res, err := m.storageService.GetDownloadHookData(m.ctx, st, key)
+	if err != nil {
+		if errors.Is(err, config.ErrFileNotFound) {
+			return nil, fiber.StatusNotFound, err
+		}
+		return nil, fiber.StatusInternalServerError, err
+	}
+	return &RecordingHlsFile{RedirectUrl: res.RedirectUrl, MimeType: mimeType}, fiber.StatusOK, nil
+}
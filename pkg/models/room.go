@@ -51,6 +51,8 @@ type RoomModel struct {
 	analyticsModel  *AnalyticsModel
 	breakoutModel   *BreakoutRoomModel
 	insightsModel   *InsightsModel
+	tenantModel     *TenantModel
+	artifactModel   *ArtifactModel
 }
 
 type updateRoomMetadataOpts struct {
@@ -74,6 +76,8 @@ type RoomModelArgs struct {
 	PollModel       *PollModel
 	AnalyticsModel  *AnalyticsModel
 	InsightsModel   *InsightsModel
+	TenantModel     *TenantModel
+	ArtifactModel   *ArtifactModel
 	Logger          *logrus.Logger
 }
 
@@ -92,6 +96,8 @@ func NewRoomModel(args RoomModelArgs) *RoomModel {
 		pollModel:       args.PollModel,
 		analyticsModel:  args.AnalyticsModel,
 		insightsModel:   args.InsightsModel,
+		tenantModel:     args.TenantModel,
+		artifactModel:   args.ArtifactModel,
 		logger:          args.Logger.WithField("model", "room"),
 	}
 }
@@ -183,6 +189,22 @@ func acquireRoomCreationLockWithRetry(ctx context.Context, rs *redisservice.Redi
 	return lockValue, nil
 }
 
+// acquireTenantRoomsQuotaLock locks the rooms quota of the tenant, so that two rooms can't
+// pass the quota check at the same time before any of them was saved to the DB.
+func acquireTenantRoomsQuotaLock(ctx context.Context, rs *redisservice.RedisService, tenantId string, log *logrus.Entry) (*redisservice.Lock, error) {
+	lock := rs.NewLock(fmt.Sprintf(redisservice.TenantRoomsQuotaLockKey, tenantId), defaultRoomCreationLockTTL)
+	err := performWithBackoff(ctx, defaultRoomCreationMaxWaitTime, log, func() (bool, error) {
+		return lock.TryLock(ctx)
+	})
+	if err != nil {
+		if errors.Is(err, timeoutErr) {
+			return nil, errors.New("timeout waiting to acquire rooms quota lock for tenant " + tenantId)
+		}
+		return nil, fmt.Errorf("rooms quota lock acquisition cancelled for tenant '%s': %w", tenantId, err)
+	}
+	return lock, nil
+}
+
 // waitUntilRoomCreationCompletes waits until the room creation lock for the given roomID is released.
 func waitUntilRoomCreationCompletes(ctx context.Context, rs *redisservice.RedisService, roomID string, log *logrus.Entry) error {
 	maxWaitTime := defaultWaitForRoomCreationMaxWaitTime
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
 	"github.com/mynaparrot/plugnmeet-protocol/utils"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
 	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
 	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
 	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
@@ -36,6 +38,10 @@ func (m *RoomModel) CreateRoom(userCtx context.Context, r *plugnmeet.CreateRoomR
 		log.WithError(err).Errorln()
 		return nil, err
 	}
+	if err := m.validateRoomWebhookUrl(r.GetMetadata().GetWebhookUrl()); err != nil {
+		log.WithError(err).Errorln("invalid webhook_url")
+		return nil, err
+	}
 
 	// we'll lock the same room creation until the room is created
 	lockValue, err := acquireRoomCreationLockWithRetry(userCtx, m.rs, r.GetRoomId(), log)
@@ -62,6 +68,20 @@ func (m *RoomModel) CreateRoom(userCtx context.Context, r *plugnmeet.CreateRoomR
 		return nil, err
 	}
 
+	tenantId, err := m.getRoomTenantId(userCtx, r)
+	if err != nil {
+		log.WithError(err).Error("Could not get tenant of the room")
+		return nil, err
+	}
+	if roomDbInfo != nil && roomDbInfo.TenantId != tenantId {
+		if tenantId != "" {
+			log.WithField("tenant_id", tenantId).Warn("room is running under another tenant")
+			return nil, config.ErrTenantAccessDenied
+		}
+		// root api key can access all the rooms, but the room will stay with its tenant
+		tenantId = roomDbInfo.TenantId
+	}
+
 	// handle existing room logic
 	if roomDbInfo != nil && roomDbInfo.Sid != "" {
 		log.Info("Found existing active room in db, attempting to handle it")
@@ -78,17 +98,44 @@ func (m *RoomModel) CreateRoom(userCtx context.Context, r *plugnmeet.CreateRoomR
 		log.Info("Existing room record was stale or mismatched, proceeding to create a new session")
 	}
 
+	// breakout rooms are part of the main room session, so we won't count them
+	unlockQuota := func() {}
+	if tenantId != "" && !r.GetMetadata().GetIsBreakoutRoom() {
+		// hold the lock till the room was saved, otherwise concurrent requests may exceed the quota
+		quotaLock, err := acquireTenantRoomsQuotaLock(userCtx, m.rs, tenantId, log)
+		if err != nil {
+			log.WithError(err).Error("failed to lock rooms quota of tenant")
+			return nil, err
+		}
+		var once sync.Once
+		unlockQuota = func() {
+			once.Do(func() {
+				if err := quotaLock.Unlock(context.Background()); err != nil {
+					log.WithError(err).Error("failed to release rooms quota lock")
+				}
+			})
+		}
+		defer unlockQuota()
+
+		if err := m.tenantModel.CheckRoomsQuota(tenantId); err != nil {
+			log.WithError(err).Warn("rooms quota check failed")
+			return nil, err
+		}
+	}
+
 	// initialize room defaults
 	m.setRoomDefaults(r)
 
 	// prepare DB model
 	roomDbInfo, sid := m.prepareRoomDbInfo(r, roomDbInfo)
+	roomDbInfo.TenantId = tenantId
 
 	// save info to db
 	if _, err := m.ds.InsertOrUpdateRoomInfo(roomDbInfo); err != nil {
 		log.WithError(err).Error("Failed to insert or update room in db")
 		return nil, err
 	}
+	unlockQuota()
 	log = log.WithFields(logrus.Fields{
 		"room_sid": sid,
 	})
@@ -134,6 +181,9 @@ func (m *RoomModel) CreateRoom(userCtx context.Context, r *plugnmeet.CreateRoomR
 		Metadata:     mt,
 	}
 
+	// reserve recorder capacity if requested
+	go m.recordingModel.ReserveRecorder(r.RoomId, r.Metadata, log)
+
 	// create and send room_created webhook
 	go m.sendRoomCreatedWebhook(ari, r.EmptyTimeout, r.MaxParticipants)
 
@@ -279,9 +329,38 @@ func (m *RoomModel) prepareRoomDbInfo(r *plugnmeet.CreateRoomReq, existing *dbmo
 	if r.Metadata.WebhookUrl != nil {
 		existing.WebhookUrl = *r.Metadata.WebhookUrl
 	}
+	existing.TemplateId = r.Metadata.GetExtraData()[templateIdExtraDataKey]
 	return existing, sId
 }
 
+// validateRoomWebhookUrl checks the per-meeting url, it won't be used if per-meeting webhook is disabled
+func (m *RoomModel) validateRoomWebhookUrl(u string) error {
+	if u == "" || m.webhookNotifier == nil || !m.app.Client.WebhookConf.EnableForPerMeeting {
+		return nil
+	}
+	if err := m.webhookNotifier.ValidateWebhookUrl(u); err != nil {
+		return fmt.Errorf("invalid webhook_url: %w", err)
+	}
+	return nil
+}
+
+// getRoomTenantId returns the tenant of the caller,
+// breakout rooms always belong to the tenant of the parent room
+func (m *RoomModel) getRoomTenantId(userCtx context.Context, r *plugnmeet.CreateRoomReq) (string, error) {
+	if !r.GetMetadata().GetIsBreakoutRoom() || r.GetMetadata().GetParentRoomId() == "" {
+		return TenantIdFromContext(userCtx), nil
+	}
+
+	parent, err := m.ds.GetRoomInfoByRoomId(r.GetMetadata().GetParentRoomId(), 1)
+	if err != nil {
+		return "", err
+	}
+	if parent == nil {
+		return "", config.ErrRoomNotFound
+	}
+	return parent.TenantId, nil
+}
+
 // prepareWhiteboardPreloadFile preload whiteboard file
 func (m *RoomModel) prepareWhiteboardPreloadFile(meta *plugnmeet.RoomMetadata, roomId, roomSid string, log *logrus.Entry) {
 	wbf := meta.RoomFeatures.WhiteboardFeatures
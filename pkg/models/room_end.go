@@ -162,6 +162,9 @@ func (m *RoomModel) onAfterRoomEnded(p *onAfterRoomEndedParams) {
 		log.WithError(err).Error("DB error updating status")
 	}
 
+	// Nothing should wait for a recorder anymore
+	m.recordingModel.ReleaseRecorderResources(p.roomId, log)
+
 	// Send a stop signal to any active recorders for this room.
 	_ = m.recordingModel.DispatchRecorderTask(&plugnmeet.RecordingReq{
 		Task:        plugnmeet.RecordingTasks_STOP,
@@ -170,6 +173,9 @@ func (m *RoomModel) onAfterRoomEnded(p *onAfterRoomEndedParams) {
 		RoomTableId: int64(p.dbTableId),
 	})
 
+	// Chapters of the recordings need transcription history & analytics, so before those are cleaned up.
+	m.recordingModel.GenerateRecordingChapters(p.roomId, p.roomSid, log)
+
 	// Trigger room end hook for any external cleanup
 	if m.app.Hooks != nil {
 		log.Info("Running room end hook")
@@ -184,6 +190,7 @@ func (m *RoomModel) onAfterRoomEnded(p *onAfterRoomEndedParams) {
 
 	// If not configured to keep files, delete all uploaded files for this session.
 	if !m.app.UploadFileSettings.KeepForever {
+		m.fileModel.DeleteRoomStoredFiles(p.roomSid)
 		if err := m.fileModel.DeleteRoomUploadedDir(p.roomSid); err != nil {
 			log.WithError(err).Error("Error deleting uploads")
 		}
@@ -194,6 +201,13 @@ func (m *RoomModel) onAfterRoomEnded(p *onAfterRoomEndedParams) {
 		log.WithError(err).Error("Error deleting room duration")
 	}
 
+	// Keep the results of the polls before those are cleaned up.
+	if polls, err := m.pollModel.SnapshotPollResults(p.roomId); err != nil {
+		log.WithError(err).Error("Error getting poll results")
+	} else if len(polls) > 0 {
+		m.artifactModel.CreatePollResultsArtifact(p.roomId, p.roomSid, p.dbTableId, polls, log)
+	}
+
 	// Clean up any polls created during the session.
 	if err := m.pollModel.CleanUpPolls(p.roomId); err != nil {
 		log.WithError(err).Error("Error cleaning polls")
@@ -210,6 +224,9 @@ func (m *RoomModel) onAfterRoomEnded(p *onAfterRoomEndedParams) {
 	// clean any SIP DispatchRule
 	m.lk.DeleteSIPDispatchRule(p.roomId, log)
 
+	// Store the chat messages before those are purged, if the room opted in.
+	m.artifactModel.CreateChatTranscriptArtifact(p.roomId, p.roomSid, p.dbTableId, p.metadata, log)
+
 	// CRITICAL: ==> THIS WILL BE THE LAST <==
 	// Final NATS cleanup: deletes all consumers, messages, and the KV store for this room.
 	m.natsService.OnAfterSessionEndCleanup(p.roomId)
@@ -101,7 +101,7 @@ func (m *RoomModel) GetActiveRoomInfo(ctx context.Context, r *plugnmeet.GetActiv
 }
 
 func (m *RoomModel) GetActiveRoomsInfo(userCtx context.Context) (bool, string, plugnmeet.StatusCode, []*plugnmeet.ActiveRoomWithParticipant) {
-	roomsInfo, err := m.ds.GetActiveRoomsInfo(userCtx)
+	roomsInfo, err := m.ds.GetActiveRoomsInfo(userCtx, TenantIdFromContext(userCtx))
 	if err != nil {
 		return false, err.Error(), plugnmeet.StatusCode_INTERNAL_SERVER_ERROR, nil
 	}
@@ -161,7 +161,7 @@ func (m *RoomModel) FetchPastRooms(userCtx context.Context, r *plugnmeet.FetchPa
 	if r.OrderBy == "" {
 		r.OrderBy = "DESC"
 	}
-	rooms, total, err := m.ds.GetPastRooms(userCtx, r.RoomIds, uint64(r.From), uint64(r.Limit), &r.OrderBy)
+	rooms, total, err := m.ds.GetPastRooms(userCtx, TenantIdFromContext(userCtx), r.RoomIds, uint64(r.From), uint64(r.Limit), &r.OrderBy)
 	if err != nil {
 		return nil, err
 	}
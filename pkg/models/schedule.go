@@ -0,0 +1,219 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	// the timezones of schedules shouldn't depend on the system
+	_ "time/tzdata"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/mynaparrot/plugnmeet-server/pkg/helpers"
+	dbservice "github.com/mynaparrot/plugnmeet-server/pkg/services/db"
+	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/fx"
+)
+
+type ScheduleEventName string
+
+const (
+	ScheduleCreated     ScheduleEventName = "schedule_created"
+	ScheduleUpdated     ScheduleEventName = "schedule_updated"
+	ScheduleDeleted     ScheduleEventName = "schedule_deleted"
+	ScheduleRoomCreated ScheduleEventName = "schedule_room_created"
+	ScheduleFailed      ScheduleEventName = "schedule_failed"
+)
+
+type ScheduleModel struct {
+	ctx             context.Context
+	app             *config.AppConfig
+	ds              *dbservice.DatabaseService
+	rs              *redisservice.RedisService
+	rm              *RoomModel
+	webhookNotifier *helpers.WebhookNotifier
+	logger          *logrus.Entry
+}
+
+type ScheduleModelArgs struct {
+	fx.In
+	Ctx             context.Context
+	App             *config.AppConfig
+	Ds              *dbservice.DatabaseService
+	Rs              *redisservice.RedisService
+	Rm              *RoomModel
+	WebhookNotifier *helpers.WebhookNotifier
+	Logger          *logrus.Logger
+}
+
+func NewScheduleModel(args ScheduleModelArgs) *ScheduleModel {
+	return &ScheduleModel{
+		ctx:             args.Ctx,
+		app:             args.App,
+		ds:              args.Ds,
+		rs:              args.Rs,
+		rm:              args.Rm,
+		webhookNotifier: args.WebhookNotifier,
+		logger:          args.Logger.WithField("model", "schedule"),
+	}
+}
+
+type ScheduleRecurrence struct {
+	// Frequency can be daily or weekly
+	Frequency string `json:"frequency"`
+	// EndAt is the unix timestamp after which no more room will be created
+	EndAt int64 `json:"end_at"`
+	// Timezone is the IANA name, e.g. Europe/Berlin. Rooms will be created
+	// at the same local time even when DST changes. Default: UTC
+	Timezone string `json:"timezone,omitempty"`
+}
+
+type CreateScheduleReq struct {
+	Title      string              `json:"title"`
+	StartAt    int64               `json:"start_at"`
+	Recurrence *ScheduleRecurrence `json:"recurrence,omitempty"`
+	WebhookUrl string              `json:"webhook_url,omitempty"`
+	// Room is the same body as /room/create
+	Room json.RawMessage `json:"room"`
+}
+
+type UpdateScheduleReq struct {
+	ScheduleId string              `json:"schedule_id"`
+	Title      *string             `json:"title,omitempty"`
+	StartAt    *int64              `json:"start_at,omitempty"`
+	Recurrence *ScheduleRecurrence `json:"recurrence,omitempty"`
+	WebhookUrl *string             `json:"webhook_url,omitempty"`
+	Room       json.RawMessage     `json:"room,omitempty"`
+}
+
+type DeleteScheduleReq struct {
+	ScheduleId string `json:"schedule_id"`
+}
+
+type FetchSchedulesReq struct {
+	RoomIds []string `json:"room_ids"`
+	Status  *string  `json:"status,omitempty"`
+	From    uint32   `json:"from"`
+	Limit   uint32   `json:"limit"`
+	OrderBy string   `json:"order_by"`
+}
+
+type ScheduleInfo struct {
+	ScheduleId    string              `json:"schedule_id"`
+	RoomId        string              `json:"room_id"`
+	Title         string              `json:"title"`
+	StartAt       int64               `json:"start_at"`
+	NextStartAt   int64               `json:"next_start_at"`
+	Recurrence    *ScheduleRecurrence `json:"recurrence,omitempty"`
+	Status        string              `json:"status"`
+	WebhookUrl    string              `json:"webhook_url,omitempty"`
+	LastRoomSid   string              `json:"last_room_sid,omitempty"`
+	LastCreatedAt int64               `json:"last_created_at,omitempty"`
+	Room          json.RawMessage     `json:"room,omitempty"`
+}
+
+type FetchSchedulesResult struct {
+	TotalSchedules int64           `json:"total_schedules"`
+	From           uint32          `json:"from"`
+	Limit          uint32          `json:"limit"`
+	OrderBy        string          `json:"order_by"`
+	SchedulesList  []*ScheduleInfo `json:"schedules_list"`
+}
+
+func (m *ScheduleModel) toScheduleInfo(sc *dbmodels.RoomSchedule) *ScheduleInfo {
+	info := &ScheduleInfo{
+		ScheduleId:    sc.ScheduleId,
+		RoomId:        sc.RoomId,
+		Title:         sc.Title,
+		StartAt:       sc.StartAt,
+		NextStartAt:   sc.NextStartAt,
+		Status:        sc.Status,
+		WebhookUrl:    sc.WebhookUrl,
+		LastRoomSid:   sc.LastRoomSid,
+		LastCreatedAt: sc.LastCreatedAt,
+	}
+	if sc.Recurrence != "" && sc.Recurrence != dbmodels.RoomScheduleRecurrenceNone {
+		info.Recurrence = &ScheduleRecurrence{
+			Frequency: sc.Recurrence,
+			EndAt:     sc.RecurrenceEndAt,
+			Timezone:  sc.Timezone,
+		}
+	}
+	if sc.CreateRoomReq != "" {
+		info.Room = json.RawMessage(sc.CreateRoomReq)
+	}
+	return info
+}
+
+// recurrenceDays returns the days between two occurrences, zero for one-time schedules
+func recurrenceDays(recurrence string) int {
+	switch recurrence {
+	case dbmodels.RoomScheduleRecurrenceDaily:
+		return 1
+	case dbmodels.RoomScheduleRecurrenceWeekly:
+		return 7
+	}
+	return 0
+}
+
+// scheduleLocation returns the timezone of the schedule, UTC if unknown
+func scheduleLocation(timezone string) *time.Location {
+	if timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// nextOccurrence returns the first occurrence after the current one & now, zero for one-time schedules.
+// Occurrences are counted from the start time in the timezone of the schedule,
+// so those will keep the local time when DST changes.
+func nextOccurrence(sc *dbmodels.RoomSchedule, now time.Time) int64 {
+	days := recurrenceDays(sc.Recurrence)
+	if days == 0 {
+		return 0
+	}
+	start := time.Unix(sc.StartAt, 0).In(scheduleLocation(sc.Timezone))
+	after := max(sc.NextStartAt, now.Unix())
+
+	// jump close to it instead of going through all the missed occurrences,
+	// an occurrence can't be shifted by more than a day because of DST
+	n := max(1, int((after-sc.StartAt)/int64(days*24*60*60))-1)
+	for {
+		next := start.AddDate(0, 0, n*days).Unix()
+		if next > after {
+			return next
+		}
+		n++
+	}
+}
+
+func (m *ScheduleModel) sendScheduleWebhook(event ScheduleEventName, sc *dbmodels.RoomSchedule) {
+	if m.webhookNotifier == nil {
+		return
+	}
+
+	info, err := json.Marshal(m.toScheduleInfo(sc))
+	if err != nil {
+		m.logger.WithError(err).Errorln("failed to marshal schedule info")
+		return
+	}
+
+	msg := &plugnmeet.CommonNotifyEvent{
+		Event: new(string(event)),
+		Room: &plugnmeet.NotifyEventRoom{
+			RoomId:   &sc.RoomId,
+			Metadata: new(string(info)),
+		},
+	}
+	if sc.LastRoomSid != "" {
+		msg.Room.Sid = &sc.LastRoomSid
+	}
+
+	m.webhookNotifier.SendScheduleWebhookEvent(msg, sc.TenantId, sc.WebhookUrl)
+}
@@ -0,0 +1,206 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// CreateSchedule stores a new schedule, the room will be created by the janitor
+// once the start time comes within the configured lead time
+func (m *ScheduleModel) CreateSchedule(tenantId string, r *CreateScheduleReq, roomReq *plugnmeet.CreateRoomReq) (*ScheduleInfo, error) {
+	log := m.logger.WithFields(logrus.Fields{
+		"room_id": roomReq.GetRoomId(),
+		"method":  "CreateSchedule",
+	})
+
+	if r.StartAt <= time.Now().UTC().Unix() {
+		return nil, fmt.Errorf("start_at must be in the future")
+	}
+	roomReqJson, err := protojson.Marshal(roomReq)
+	if err != nil {
+		return nil, err
+	}
+
+	title := r.Title
+	if title == "" && roomReq.GetMetadata() != nil {
+		title = roomReq.GetMetadata().GetRoomTitle()
+	}
+	webhookUrl := r.WebhookUrl
+	if webhookUrl == "" && roomReq.GetMetadata() != nil {
+		webhookUrl = roomReq.GetMetadata().GetWebhookUrl()
+	}
+	if err = m.rm.validateRoomWebhookUrl(webhookUrl); err != nil {
+		return nil, err
+	}
+
+	sc := &dbmodels.RoomSchedule{
+		ScheduleId:    uuid.NewString(),
+		RoomId:        roomReq.GetRoomId(),
+		Title:         title,
+		StartAt:       r.StartAt,
+		NextStartAt:   r.StartAt,
+		Status:        dbmodels.RoomScheduleStatusScheduled,
+		CreateRoomReq: string(roomReqJson),
+		WebhookUrl:    webhookUrl,
+		TenantId:      tenantId,
+	}
+	if err = setScheduleRecurrence(sc, r.Recurrence); err != nil {
+		return nil, err
+	}
+
+	if _, err := m.ds.InsertOrUpdateRoomSchedule(sc); err != nil {
+		log.WithError(err).Errorln("failed to save schedule")
+		return nil, err
+	}
+	log.WithField("schedule_id", sc.ScheduleId).Info("room schedule created")
+
+	go m.sendScheduleWebhook(ScheduleCreated, sc)
+	return m.toScheduleInfo(sc), nil
+}
+
+// UpdateSchedule modifies an existing schedule,
+// only the provided fields will be changed
+func (m *ScheduleModel) UpdateSchedule(tenantId string, r *UpdateScheduleReq, roomReq *plugnmeet.CreateRoomReq) (*ScheduleInfo, error) {
+	sc, err := m.ds.GetRoomSchedule(r.ScheduleId)
+	if err != nil {
+		return nil, err
+	}
+	if sc == nil || !canAccessTenantResource(tenantId, sc.TenantId) {
+		return nil, config.NotFoundErr
+	}
+	if sc.Status != dbmodels.RoomScheduleStatusScheduled {
+		return nil, fmt.Errorf("schedule with status '%s' can't be modified", sc.Status)
+	}
+
+	if r.Title != nil {
+		sc.Title = *r.Title
+	}
+	if r.WebhookUrl != nil {
+		if err := m.rm.validateRoomWebhookUrl(*r.WebhookUrl); err != nil {
+			return nil, err
+		}
+		sc.WebhookUrl = *r.WebhookUrl
+	}
+	if r.StartAt != nil {
+		if *r.StartAt <= time.Now().UTC().Unix() {
+			return nil, fmt.Errorf("start_at must be in the future")
+		}
+		sc.StartAt = *r.StartAt
+		sc.NextStartAt = *r.StartAt
+	}
+	if r.Recurrence != nil || r.StartAt != nil {
+		rec := r.Recurrence
+		if rec == nil && sc.Recurrence != dbmodels.RoomScheduleRecurrenceNone {
+			rec = &ScheduleRecurrence{
+				Frequency: sc.Recurrence,
+				EndAt:     sc.RecurrenceEndAt,
+				Timezone:  sc.Timezone,
+			}
+		}
+		if err := setScheduleRecurrence(sc, rec); err != nil {
+			return nil, err
+		}
+	}
+	if roomReq != nil {
+		if roomReq.GetRoomId() != sc.RoomId {
+			return nil, fmt.Errorf("room_id of a schedule can't be changed")
+		}
+		roomReqJson, err := protojson.Marshal(roomReq)
+		if err != nil {
+			return nil, err
+		}
+		sc.CreateRoomReq = string(roomReqJson)
+	}
+
+	if _, err := m.ds.InsertOrUpdateRoomSchedule(sc); err != nil {
+		return nil, err
+	}
+
+	go m.sendScheduleWebhook(ScheduleUpdated, sc)
+	return m.toScheduleInfo(sc), nil
+}
+
+// DeleteSchedule removes the schedule, already created rooms won't be affected
+func (m *ScheduleModel) DeleteSchedule(tenantId string, r *DeleteScheduleReq) error {
+	sc, err := m.ds.GetRoomSchedule(r.ScheduleId)
+	if err != nil {
+		return err
+	}
+	if sc == nil || !canAccessTenantResource(tenantId, sc.TenantId) {
+		return config.NotFoundErr
+	}
+
+	if _, err := m.ds.DeleteRoomSchedule(sc.ScheduleId); err != nil {
+		return err
+	}
+
+	go m.sendScheduleWebhook(ScheduleDeleted, sc)
+	return nil
+}
+
+func (m *ScheduleModel) FetchSchedules(tenantId string, r *FetchSchedulesReq) (*FetchSchedulesResult, error) {
+	if r.Limit <= 0 {
+		r.Limit = 20
+	} else if r.Limit > 100 {
+		r.Limit = 100
+	}
+	if r.OrderBy == "" {
+		r.OrderBy = "DESC"
+	}
+
+	data, total, err := m.ds.GetRoomSchedules(tenantId, r.RoomIds, r.Status, uint64(r.From), uint64(r.Limit), &r.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, config.NotFoundErr
+	}
+
+	schedules := make([]*ScheduleInfo, 0, len(data))
+	for i := range data {
+		schedules = append(schedules, m.toScheduleInfo(&data[i]))
+	}
+
+	return &FetchSchedulesResult{
+		TotalSchedules: total,
+		From:           r.From,
+		Limit:          r.Limit,
+		OrderBy:        r.OrderBy,
+		SchedulesList:  schedules,
+	}, nil
+}
+
+// setScheduleRecurrence validates the recurrence & sets it to the schedule
+func setScheduleRecurrence(sc *dbmodels.RoomSchedule, rec *ScheduleRecurrence) error {
+	if rec == nil || rec.Frequency == "" || rec.Frequency == dbmodels.RoomScheduleRecurrenceNone {
+		sc.Recurrence = dbmodels.RoomScheduleRecurrenceNone
+		sc.RecurrenceEndAt = 0
+		sc.Timezone = "UTC"
+		return nil
+	}
+	if recurrenceDays(rec.Frequency) == 0 {
+		return fmt.Errorf("invalid recurrence frequency '%s', allowed: daily, weekly", rec.Frequency)
+	}
+	if rec.EndAt <= sc.NextStartAt {
+		return fmt.Errorf("recurrence end_at must be after start_at")
+	}
+	timezone := rec.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("invalid recurrence timezone '%s'", rec.Timezone)
+	}
+
+	sc.Recurrence = rec.Frequency
+	sc.RecurrenceEndAt = rec.EndAt
+	sc.Timezone = timezone
+	return nil
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// ProcessDueSchedules will create rooms for all the schedules
+// which are going to start within the configured lead time.
+// This should only be called by the janitor leader.
+func (m *ScheduleModel) ProcessDueSchedules() {
+	log := m.logger.WithField("method", "ProcessDueSchedules")
+	now := time.Now().UTC()
+
+	schedules, err := m.ds.GetDueRoomSchedules(now.Add(m.app.ScheduleSettings.CreateLeadTime).Unix())
+	if err != nil {
+		log.WithError(err).Errorln("failed to get due schedules")
+		return
+	}
+
+	for i := range schedules {
+		m.startScheduledRoom(&schedules[i], now)
+	}
+}
+
+func (m *ScheduleModel) startScheduledRoom(sc *dbmodels.RoomSchedule, now time.Time) {
+	log := m.logger.WithFields(logrus.Fields{
+		"schedule_id":   sc.ScheduleId,
+		"room_id":       sc.RoomId,
+		"next_start_at": sc.NextStartAt,
+		"method":        "startScheduledRoom",
+	})
+
+	// an old leader may still be running, so only one should create the room
+	lock := m.rs.NewLock(fmt.Sprintf(redisservice.ScheduleRunLockKey, sc.ScheduleId), defaultRoomCreationLockTTL*2)
+	acquired, err := lock.TryLock(m.ctx)
+	if err != nil {
+		log.WithError(err).Errorln("failed to acquire schedule lock")
+		return
+	}
+	if !acquired {
+		log.Infoln("schedule is being processed by another server")
+		return
+	}
+	defer lock.Unlock(m.ctx)
+
+	// the occurrence may have been processed already before we got the lock
+	current, err := m.ds.GetRoomSchedule(sc.ScheduleId)
+	if err != nil {
+		log.WithError(err).Errorln("failed to get schedule")
+		return
+	}
+	if current == nil || current.Status != dbmodels.RoomScheduleStatusScheduled || current.NextStartAt != sc.NextStartAt {
+		log.Infoln("schedule was processed or changed already, skipping")
+		return
+	}
+	sc = current
+
+	req := new(plugnmeet.CreateRoomReq)
+	if err = protojson.Unmarshal([]byte(sc.CreateRoomReq), req); err != nil {
+		// this won't be fixed by retrying, so we'll skip this occurrence
+		log.WithError(err).Errorln("failed to unmarshal stored create room request")
+		m.moveToNextOccurrence(sc, now, "", log)
+		m.sendScheduleWebhook(ScheduleFailed, sc)
+		return
+	}
+
+	log.Info("creating room for schedule")
+	ari, err := m.rm.CreateRoom(WithTenantId(m.ctx, sc.TenantId), req)
+	if err != nil {
+		log.WithError(err).Errorln("failed to create room for schedule")
+		if now.Unix() < sc.NextStartAt {
+			// still within lead time, we'll try again in the next round
+			return
+		}
+		m.moveToNextOccurrence(sc, now, "", log)
+		m.sendScheduleWebhook(ScheduleFailed, sc)
+		return
+	}
+
+	m.moveToNextOccurrence(sc, now, ari.GetSid(), log)
+	m.sendScheduleWebhook(ScheduleRoomCreated, sc)
+}
+
+// moveToNextOccurrence will set the next start time for recurring schedules,
+// missed occurrences are skipped. Otherwise, the schedule will be marked as completed.
+func (m *ScheduleModel) moveToNextOccurrence(sc *dbmodels.RoomSchedule, now time.Time, roomSid string, log *logrus.Entry) {
+	status := dbmodels.RoomScheduleStatusCompleted
+	nextStartAt := sc.NextStartAt
+
+	if next := nextOccurrence(sc, now); next > 0 && next <= sc.RecurrenceEndAt {
+		nextStartAt = next
+		status = dbmodels.RoomScheduleStatusScheduled
+	}
+
+	createdAt := sc.LastCreatedAt
+	if roomSid != "" {
+		createdAt = now.Unix()
+	} else {
+		roomSid = sc.LastRoomSid
+	}
+
+	if _, err := m.ds.UpdateRoomScheduleRun(sc.ID, nextStartAt, status, roomSid, createdAt); err != nil {
+		log.WithError(err).Errorln("failed to update schedule")
+		return
+	}
+
+	sc.NextStartAt = nextStartAt
+	sc.Status = status
+	sc.LastRoomSid = roomSid
+	sc.LastCreatedAt = createdAt
+	log.WithFields(logrus.Fields{
+		"status":             status,
+		"new_next_start_at":  nextStartAt,
+		"last_room_sid":      roomSid,
+		"last_room_creation": createdAt,
+	}).Info("schedule updated")
+}
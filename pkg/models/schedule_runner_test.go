@@ -0,0 +1,192 @@
+package models
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	dbservice "github.com/mynaparrot/plugnmeet-server/pkg/services/db"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestScheduleModel(t *testing.T) *ScheduleModel {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "plugnmeet.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open sqlite db: %v", err)
+	}
+	if err = db.AutoMigrate(&dbmodels.RoomSchedule{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return &ScheduleModel{
+		ctx:    context.Background(),
+		ds:     dbservice.New(dbservice.Args{Ctx: context.Background(), Db: db, Logger: log}),
+		logger: logrus.NewEntry(log),
+	}
+}
+
+func TestMoveToNextOccurrence(t *testing.T) {
+	utc := func(month time.Month, day, hour int) int64 {
+		return time.Date(2026, month, day, hour, 0, 0, 0, time.UTC).Unix()
+	}
+
+	tests := []struct {
+		name        string
+		recurrence  string
+		timezone    string
+		startAt     int64
+		nextStartAt int64
+		endAt       int64
+		now         int64
+		wantNext    int64
+		wantStatus  string
+	}{
+		{
+			name:       "one-time schedule is completed",
+			recurrence: dbmodels.RoomScheduleRecurrenceNone,
+			startAt:    utc(1, 1, 10),
+			now:        utc(1, 1, 10),
+			wantNext:   utc(1, 1, 10),
+			wantStatus: dbmodels.RoomScheduleStatusCompleted,
+		},
+		{
+			name:       "daily moves to the next day",
+			recurrence: dbmodels.RoomScheduleRecurrenceDaily,
+			startAt:    utc(1, 1, 10),
+			endAt:      utc(2, 1, 0),
+			now:        utc(1, 1, 10),
+			wantNext:   utc(1, 2, 10),
+			wantStatus: dbmodels.RoomScheduleStatusScheduled,
+		},
+		{
+			name:       "missed occurrences are skipped",
+			recurrence: dbmodels.RoomScheduleRecurrenceDaily,
+			startAt:    utc(1, 1, 10),
+			endAt:      utc(2, 1, 0),
+			now:        utc(1, 5, 12),
+			wantNext:   utc(1, 6, 10),
+			wantStatus: dbmodels.RoomScheduleStatusScheduled,
+		},
+		{
+			name:       "occurrence at now is skipped",
+			recurrence: dbmodels.RoomScheduleRecurrenceWeekly,
+			startAt:    utc(1, 1, 10),
+			endAt:      utc(3, 1, 0),
+			now:        utc(1, 8, 10),
+			wantNext:   utc(1, 15, 10),
+			wantStatus: dbmodels.RoomScheduleStatusScheduled,
+		},
+		{
+			name:       "next occurrence after the end date completes",
+			recurrence: dbmodels.RoomScheduleRecurrenceDaily,
+			startAt:    utc(1, 1, 10),
+			endAt:      utc(1, 2, 9),
+			now:        utc(1, 1, 10),
+			wantNext:   utc(1, 1, 10),
+			wantStatus: dbmodels.RoomScheduleStatusCompleted,
+		},
+		{
+			name:       "occurrence on the end date is kept",
+			recurrence: dbmodels.RoomScheduleRecurrenceDaily,
+			startAt:    utc(1, 1, 10),
+			endAt:      utc(1, 2, 10),
+			now:        utc(1, 1, 10),
+			wantNext:   utc(1, 2, 10),
+			wantStatus: dbmodels.RoomScheduleStatusScheduled,
+		},
+		{
+			// 10:00 CET is 09:00 UTC, 10:00 CEST is 08:00 UTC
+			name:       "daily keeps the local time when DST starts",
+			recurrence: dbmodels.RoomScheduleRecurrenceDaily,
+			timezone:   "Europe/Berlin",
+			startAt:    utc(3, 28, 9),
+			endAt:      utc(5, 1, 0),
+			now:        utc(3, 28, 9),
+			wantNext:   utc(3, 29, 8),
+			wantStatus: dbmodels.RoomScheduleStatusScheduled,
+		},
+		{
+			name:       "weekly keeps the local time when DST ends",
+			recurrence: dbmodels.RoomScheduleRecurrenceWeekly,
+			timezone:   "Europe/Berlin",
+			startAt:    utc(10, 20, 8),
+			endAt:      utc(12, 1, 0),
+			now:        utc(10, 20, 8),
+			wantNext:   utc(10, 27, 9),
+			wantStatus: dbmodels.RoomScheduleStatusScheduled,
+		},
+		{
+			name:        "missed occurrences over the DST boundary",
+			recurrence:  dbmodels.RoomScheduleRecurrenceDaily,
+			timezone:    "Europe/Berlin",
+			startAt:     utc(3, 20, 9),
+			nextStartAt: utc(3, 27, 9),
+			endAt:       utc(5, 1, 0),
+			now:         utc(3, 30, 11),
+			wantNext:    utc(3, 31, 8),
+			wantStatus:  dbmodels.RoomScheduleStatusScheduled,
+		},
+		{
+			name:       "UTC schedule doesn't follow DST",
+			recurrence: dbmodels.RoomScheduleRecurrenceDaily,
+			timezone:   "UTC",
+			startAt:    utc(3, 28, 9),
+			endAt:      utc(5, 1, 0),
+			now:        utc(3, 28, 9),
+			wantNext:   utc(3, 29, 9),
+			wantStatus: dbmodels.RoomScheduleStatusScheduled,
+		},
+	}
+
+	m := newTestScheduleModel(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nextStartAt := tt.nextStartAt
+			if nextStartAt == 0 {
+				nextStartAt = tt.startAt
+			}
+			sc := &dbmodels.RoomSchedule{
+				ScheduleId:      tt.name,
+				RoomId:          "room01",
+				StartAt:         tt.startAt,
+				NextStartAt:     nextStartAt,
+				Recurrence:      tt.recurrence,
+				RecurrenceEndAt: tt.endAt,
+				Timezone:        tt.timezone,
+				Status:          dbmodels.RoomScheduleStatusScheduled,
+				CreateRoomReq:   "{}",
+			}
+			if _, err := m.ds.InsertOrUpdateRoomSchedule(sc); err != nil {
+				t.Fatal(err)
+			}
+
+			m.moveToNextOccurrence(sc, time.Unix(tt.now, 0), "RM_01", m.logger)
+			if sc.NextStartAt != tt.wantNext || sc.Status != tt.wantStatus {
+				t.Errorf("expected %s at %s, got %s at %s", tt.wantStatus, time.Unix(tt.wantNext, 0).UTC(),
+					sc.Status, time.Unix(sc.NextStartAt, 0).UTC())
+			}
+
+			stored, err := m.ds.GetRoomSchedule(sc.ScheduleId)
+			if err != nil || stored == nil {
+				t.Fatalf("failed to get schedule: %v", err)
+			}
+			if stored.NextStartAt != tt.wantNext || stored.Status != tt.wantStatus {
+				t.Errorf("stored schedule wasn't updated: %s at %d", stored.Status, stored.NextStartAt)
+			}
+			if stored.LastRoomSid != "RM_01" || stored.LastCreatedAt != tt.now {
+				t.Errorf("unexpected last room: %s at %d", stored.LastRoomSid, stored.LastCreatedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/json"
+	"regexp"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	dbservice "github.com/mynaparrot/plugnmeet-server/pkg/services/db"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/fx"
+)
+
+var templateIdRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// TemplateModel manages the named room templates,
+// those can be referenced during room creation using template_id
+type TemplateModel struct {
+	app    *config.AppConfig
+	ds     *dbservice.DatabaseService
+	logger *logrus.Entry
+}
+
+type TemplateModelArgs struct {
+	fx.In
+	App    *config.AppConfig
+	Ds     *dbservice.DatabaseService
+	Logger *logrus.Logger
+}
+
+func NewTemplateModel(args TemplateModelArgs) *TemplateModel {
+	return &TemplateModel{
+		app:    args.App,
+		ds:     args.Ds,
+		logger: args.Logger.WithField("model", "template"),
+	}
+}
+
+type CreateTemplateReq struct {
+	// TemplateId is optional, a new one will be generated if empty
+	TemplateId  string `json:"template_id,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Settings is the same body as /room/create, except room_id
+	Settings json.RawMessage `json:"settings"`
+	// RecordingRetention overrides the retention of the recordings of rooms created using this template
+	RecordingRetention *RecordingRetentionSettings `json:"recording_retention,omitempty"`
+}
+
+type UpdateTemplateReq struct {
+	TemplateId  string          `json:"template_id"`
+	Name        *string         `json:"name,omitempty"`
+	Description *string         `json:"description,omitempty"`
+	Settings    json.RawMessage `json:"settings,omitempty"`
+	// RecordingRetention will replace the existing one, use an empty object to remove it
+	RecordingRetention *RecordingRetentionSettings `json:"recording_retention,omitempty"`
+}
+
+type TemplateIdReq struct {
+	TemplateId string `json:"template_id"`
+}
+
+type FetchTemplatesReq struct {
+	From    uint32 `json:"from"`
+	Limit   uint32 `json:"limit"`
+	OrderBy string `json:"order_by"`
+}
+
+type TemplateInfo struct {
+	TemplateId         string                      `json:"template_id"`
+	Name               string                      `json:"name"`
+	Description        string                      `json:"description,omitempty"`
+	Settings           json.RawMessage             `json:"settings,omitempty"`
+	RecordingRetention *RecordingRetentionSettings `json:"recording_retention,omitempty"`
+	Created            int64                       `json:"created"`
+	Modified           int64                       `json:"modified"`
+}
+
+type FetchTemplatesResult struct {
+	TotalTemplates int64           `json:"total_templates"`
+	From           uint32          `json:"from"`
+	Limit          uint32          `json:"limit"`
+	OrderBy        string          `json:"order_by"`
+	TemplatesList  []*TemplateInfo `json:"templates_list"`
+}
+
+func (m *TemplateModel) toTemplateInfo(t *dbmodels.RoomTemplate) *TemplateInfo {
+	info := &TemplateInfo{
+		TemplateId:         t.TemplateId,
+		Name:               t.Name,
+		Description:        t.Description,
+		RecordingRetention: toRecordingRetentionSettings(t.RecordingRetentionPublishedDays, t.RecordingRetentionUnpublishedDays),
+		Created:            t.Created.Unix(),
+		Modified:           t.Modified.Unix(),
+	}
+	if t.Settings != "" {
+		info.Settings = json.RawMessage(t.Settings)
+	}
+	return info
+}
@@ -0,0 +1,177 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var createRoomReqDescriptor = (&plugnmeet.CreateRoomReq{}).ProtoReflect().Descriptor()
+
+// templateIdExtraDataKey is the key of room metadata extra_data to keep the used template
+const templateIdExtraDataKey = "template_id"
+
+// ApplyTemplate deep merges the request body of /room/create with the template settings.
+// Values of the request will always win, so any setting of the template can be overridden.
+func (m *TemplateModel) ApplyTemplate(tenantId, templateId string, body []byte) ([]byte, error) {
+	t, err := m.getUsableTemplate(tenantId, templateId)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("room template '%s': %w", templateId, config.NotFoundErr)
+	}
+
+	base, err := decodeRoomReqJSON([]byte(t.Settings))
+	if err != nil {
+		return nil, fmt.Errorf("invalid settings in room template '%s': %w", templateId, err)
+	}
+	override, err := decodeRoomReqJSON(body)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := mergeJSONObjects(base, override)
+	keepTemplateId(merged, templateId)
+	return json.Marshal(merged)
+}
+
+// ApplyTemplateToReq does the same as ApplyTemplate for requests which were prepared by us,
+// e.g. BBB or LTI. As zero values can't be detected there, template values will win for those.
+func (m *TemplateModel) ApplyTemplateToReq(tenantId, templateId string, r *plugnmeet.CreateRoomReq) (*plugnmeet.CreateRoomReq, error) {
+	body, err := protojson.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := m.ApplyTemplate(tenantId, templateId, body)
+	if err != nil {
+		return nil, err
+	}
+
+	out := new(plugnmeet.CreateRoomReq)
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(merged, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// prepareTemplateSettings validates the settings & returns them in normalized form
+func prepareTemplateSettings(settings json.RawMessage) ([]byte, error) {
+	if len(settings) == 0 {
+		return nil, fmt.Errorf("settings is required")
+	}
+
+	obj, err := decodeRoomReqJSON(settings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid settings: %w", err)
+	}
+	if _, ok := obj["roomId"]; ok {
+		return nil, fmt.Errorf("room_id can't be part of template settings")
+	}
+
+	normalized, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	// make sure it's a valid CreateRoomReq
+	if err := protojson.Unmarshal(normalized, new(plugnmeet.CreateRoomReq)); err != nil {
+		return nil, fmt.Errorf("invalid settings: %w", err)
+	}
+
+	return normalized, nil
+}
+
+// decodeRoomReqJSON decodes a CreateRoomReq body as generic object,
+// field names will be normalized, so that bodies using proto names (room_id)
+// & json names (roomId) can be merged together
+func decodeRoomReqJSON(data []byte) (map[string]any, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	// keep numbers as it is, to avoid losing precision of int64 values
+	d.UseNumber()
+
+	obj := make(map[string]any)
+	if err := d.Decode(&obj); err != nil {
+		return nil, err
+	}
+
+	return normalizeProtoJSONKeys(obj, createRoomReqDescriptor).(map[string]any), nil
+}
+
+func normalizeProtoJSONKeys(v any, md protoreflect.MessageDescriptor) any {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+
+	out := make(map[string]any, len(obj))
+	for k, val := range obj {
+		fd := md.Fields().ByJSONName(k)
+		if fd == nil {
+			fd = md.Fields().ByName(protoreflect.Name(k))
+		}
+		if fd == nil {
+			// unknown field, protojson will handle it later
+			out[k] = val
+			continue
+		}
+
+		switch {
+		case fd.IsMap():
+			if vmd := fd.MapValue().Message(); vmd != nil {
+				if mv, ok := val.(map[string]any); ok {
+					for mk, item := range mv {
+						mv[mk] = normalizeProtoJSONKeys(item, vmd)
+					}
+				}
+			}
+		case fd.Message() != nil && fd.IsList():
+			if list, ok := val.([]any); ok {
+				for i := range list {
+					list[i] = normalizeProtoJSONKeys(list[i], fd.Message())
+				}
+			}
+		case fd.Message() != nil:
+			val = normalizeProtoJSONKeys(val, fd.Message())
+		}
+		out[fd.JSONName()] = val
+	}
+
+	return out
+}
+
+// mergeJSONObjects merges override into base recursively,
+// lists & other values of override will replace the base one
+func mergeJSONObjects(base, override map[string]any) map[string]any {
+	for k, v := range override {
+		bm, ok1 := base[k].(map[string]any)
+		om, ok2 := v.(map[string]any)
+		if ok1 && ok2 {
+			base[k] = mergeJSONObjects(bm, om)
+			continue
+		}
+		base[k] = v
+	}
+	return base
+}
+
+// keepTemplateId adds the template_id to the extra_data of room metadata,
+// so that we can find the template of the room later, e.g. for recording retention
+func keepTemplateId(obj map[string]any, templateId string) {
+	meta, ok := obj["metadata"].(map[string]any)
+	if !ok {
+		meta = make(map[string]any)
+		obj["metadata"] = meta
+	}
+	extraData, ok := meta["extraData"].(map[string]any)
+	if !ok {
+		extraData = make(map[string]any)
+		meta["extraData"] = extraData
+	}
+	extraData[templateIdExtraDataKey] = templateId
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func decodeTestJSON(t *testing.T, data string) map[string]any {
+	t.Helper()
+	d := json.NewDecoder(bytes.NewReader([]byte(data)))
+	d.UseNumber()
+	obj := make(map[string]any)
+	if err := d.Decode(&obj); err != nil {
+		t.Fatal(err)
+	}
+	return obj
+}
+
+func TestNormalizeProtoJSONKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		md   protoreflect.MessageDescriptor
+		in   string
+		want string
+	}{
+		{
+			name: "json names are kept",
+			in:   `{"roomId": "room01", "emptyTimeout": 60}`,
+			want: `{"roomId": "room01", "emptyTimeout": 60}`,
+		},
+		{
+			name: "proto names",
+			in:   `{"room_id": "room01", "max_participants": 10}`,
+			want: `{"roomId": "room01", "maxParticipants": 10}`,
+		},
+		{
+			name: "mixed names",
+			in:   `{"room_id": "room01", "emptyTimeout": 60}`,
+			want: `{"roomId": "room01", "emptyTimeout": 60}`,
+		},
+		{
+			name: "nested messages",
+			in:   `{"metadata": {"room_title": "Test", "room_features": {"enable_analytics": true, "recording_features": {"is_allow": true}}}}`,
+			want: `{"metadata": {"roomTitle": "Test", "roomFeatures": {"enableAnalytics": true, "recordingFeatures": {"isAllow": true}}}}`,
+		},
+		{
+			name: "map keys are kept",
+			in:   `{"metadata": {"extra_data": {"template_id": "tpl01", "someKey": "value"}}}`,
+			want: `{"metadata": {"extraData": {"template_id": "tpl01", "someKey": "value"}}}`,
+		},
+		{
+			name: "unknown fields are kept",
+			in:   `{"unknown_field": {"nested_key": 1}}`,
+			want: `{"unknown_field": {"nested_key": 1}}`,
+		},
+		{
+			name: "list of messages",
+			md:   (&plugnmeet.CreatePollReq{}).ProtoReflect().Descriptor(),
+			in:   `{"poll_id": "poll01", "options": [{"id": 1, "text": "yes"}, {"id": 2, "text": "no"}]}`,
+			want: `{"pollId": "poll01", "options": [{"id": 1, "text": "yes"}, {"id": 2, "text": "no"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := tt.md
+			if md == nil {
+				md = createRoomReqDescriptor
+			}
+			got := normalizeProtoJSONKeys(decodeTestJSON(t, tt.in), md)
+			if want := decodeTestJSON(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestMergeJSONObjects(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		override string
+		want     string
+	}{
+		{
+			name:     "empty override",
+			base:     `{"max_participants": 10}`,
+			override: `{}`,
+			want:     `{"maxParticipants": 10}`,
+		},
+		{
+			name:     "proto names in template, json names in request",
+			base:     `{"max_participants": 10, "metadata": {"room_title": "Template"}}`,
+			override: `{"roomId": "room01", "metadata": {"roomTitle": "Room"}}`,
+			want:     `{"roomId": "room01", "maxParticipants": 10, "metadata": {"roomTitle": "Room"}}`,
+		},
+		{
+			name:     "json names in template, proto names in request",
+			base:     `{"maxParticipants": 10, "emptyTimeout": 60}`,
+			override: `{"max_participants": 20}`,
+			want:     `{"maxParticipants": 20, "emptyTimeout": 60}`,
+		},
+		{
+			name:     "nested metadata override keeps the other features",
+			base:     `{"metadata": {"room_title": "Template", "room_features": {"enable_analytics": true, "recording_features": {"is_allow": true}}}}`,
+			override: `{"metadata": {"roomFeatures": {"enableAnalytics": false}}}`,
+			want:     `{"metadata": {"roomTitle": "Template", "roomFeatures": {"enableAnalytics": false, "recordingFeatures": {"isAllow": true}}}}`,
+		},
+		{
+			name:     "extra data is merged by key",
+			base:     `{"metadata": {"extra_data": {"recorder_reservation": "true", "recorder_affinity": "region=eu"}}}`,
+			override: `{"metadata": {"extraData": {"recorder_affinity": "region=us"}}}`,
+			want:     `{"metadata": {"extraData": {"recorder_reservation": "true", "recorder_affinity": "region=us"}}}`,
+		},
+		{
+			name:     "lists are replaced",
+			base:     `{"items": [1, 2, 3], "metadata": {"room_title": "Template"}}`,
+			override: `{"items": [4]}`,
+			want:     `{"items": [4], "metadata": {"roomTitle": "Template"}}`,
+		},
+		{
+			name:     "empty list replaces the template one",
+			base:     `{"items": [1, 2, 3]}`,
+			override: `{"items": []}`,
+			want:     `{"items": []}`,
+		},
+		{
+			name:     "value replaces an object",
+			base:     `{"metadata": {"room_title": "Template"}}`,
+			override: `{"metadata": null}`,
+			want:     `{"metadata": null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, err := decodeRoomReqJSON([]byte(tt.base))
+			if err != nil {
+				t.Fatal(err)
+			}
+			override, err := decodeRoomReqJSON([]byte(tt.override))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := mergeJSONObjects(base, override)
+			if want := decodeTestJSON(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %v, got %v", want, got)
+			}
+		})
+	}
+}
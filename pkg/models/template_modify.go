@@ -0,0 +1,172 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/sirupsen/logrus"
+)
+
+// CreateTemplate stores a new template, templates created by a tenant
+// will only be visible to that tenant. Templates of the root api key are shared with all.
+func (m *TemplateModel) CreateTemplate(tenantId string, r *CreateTemplateReq) (*TemplateInfo, error) {
+	if r.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if r.TemplateId == "" {
+		r.TemplateId = uuid.NewString()
+	} else if !templateIdRegex.MatchString(r.TemplateId) {
+		return nil, fmt.Errorf("template_id can only contain letters, numbers, '-' & '_' and max 64 characters")
+	}
+
+	existing, err := m.ds.GetRoomTemplate(r.TemplateId)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("template with template_id '%s' already exists", r.TemplateId)
+	}
+
+	settings, err := prepareTemplateSettings(r.Settings)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.RecordingRetention.validate(); err != nil {
+		return nil, err
+	}
+
+	t := &dbmodels.RoomTemplate{
+		TemplateId:  r.TemplateId,
+		Name:        r.Name,
+		Description: r.Description,
+		Settings:    string(settings),
+		TenantId:    tenantId,
+	}
+	t.RecordingRetentionPublishedDays, t.RecordingRetentionUnpublishedDays = r.RecordingRetention.values()
+	if _, err := m.ds.InsertOrUpdateRoomTemplate(t); err != nil {
+		m.logger.WithError(err).Errorln("failed to save room template")
+		return nil, err
+	}
+	m.logger.WithField("template_id", t.TemplateId).Info("room template created")
+
+	return m.toTemplateInfo(t), nil
+}
+
+// UpdateTemplate modifies an existing template, only the provided fields will be changed.
+// Rooms which were already created using this template won't be affected.
+func (m *TemplateModel) UpdateTemplate(tenantId string, r *UpdateTemplateReq) (*TemplateInfo, error) {
+	t, err := m.ds.GetRoomTemplate(r.TemplateId)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil || !canAccessTenantResource(tenantId, t.TenantId) {
+		return nil, config.NotFoundErr
+	}
+
+	if r.Name != nil {
+		if *r.Name == "" {
+			return nil, fmt.Errorf("name can't be empty")
+		}
+		t.Name = *r.Name
+	}
+	if r.Description != nil {
+		t.Description = *r.Description
+	}
+	if len(r.Settings) > 0 {
+		settings, err := prepareTemplateSettings(r.Settings)
+		if err != nil {
+			return nil, err
+		}
+		t.Settings = string(settings)
+	}
+	if r.RecordingRetention != nil {
+		if err := r.RecordingRetention.validate(); err != nil {
+			return nil, err
+		}
+		t.RecordingRetentionPublishedDays, t.RecordingRetentionUnpublishedDays = r.RecordingRetention.values()
+	}
+
+	if _, err := m.ds.InsertOrUpdateRoomTemplate(t); err != nil {
+		return nil, err
+	}
+
+	return m.toTemplateInfo(t), nil
+}
+
+func (m *TemplateModel) DeleteTemplate(tenantId string, r *TemplateIdReq) error {
+	t, err := m.ds.GetRoomTemplate(r.TemplateId)
+	if err != nil {
+		return err
+	}
+	if t == nil || !canAccessTenantResource(tenantId, t.TenantId) {
+		return config.NotFoundErr
+	}
+
+	if _, err := m.ds.DeleteRoomTemplate(t.TemplateId); err != nil {
+		return err
+	}
+	m.logger.WithFields(logrus.Fields{
+		"template_id": t.TemplateId,
+		"name":        t.Name,
+	}).Info("room template deleted")
+
+	return nil
+}
+
+func (m *TemplateModel) GetTemplateInfo(tenantId string, r *TemplateIdReq) (*TemplateInfo, error) {
+	t, err := m.getUsableTemplate(tenantId, r.TemplateId)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, config.NotFoundErr
+	}
+	return m.toTemplateInfo(t), nil
+}
+
+func (m *TemplateModel) FetchTemplates(tenantId string, r *FetchTemplatesReq) (*FetchTemplatesResult, error) {
+	if r.Limit <= 0 {
+		r.Limit = 20
+	} else if r.Limit > 100 {
+		r.Limit = 100
+	}
+	if r.OrderBy == "" {
+		r.OrderBy = "DESC"
+	}
+
+	data, total, err := m.ds.GetRoomTemplates(tenantId, uint64(r.From), uint64(r.Limit), &r.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, config.NotFoundErr
+	}
+
+	templates := make([]*TemplateInfo, 0, len(data))
+	for i := range data {
+		templates = append(templates, m.toTemplateInfo(&data[i]))
+	}
+
+	return &FetchTemplatesResult{
+		TotalTemplates: total,
+		From:           r.From,
+		Limit:          r.Limit,
+		OrderBy:        r.OrderBy,
+		TemplatesList:  templates,
+	}, nil
+}
+
+// getUsableTemplate returns the template if it is shared or belongs to the tenant.
+// It returns (nil, nil) if not found.
+func (m *TemplateModel) getUsableTemplate(tenantId, templateId string) (*dbmodels.RoomTemplate, error) {
+	t, err := m.ds.GetRoomTemplate(templateId)
+	if err != nil || t == nil {
+		return nil, err
+	}
+	if t.TenantId != "" && !canAccessTenantResource(tenantId, t.TenantId) {
+		return nil, nil
+	}
+	return t, nil
+}
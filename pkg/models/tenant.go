@@ -0,0 +1,123 @@
+package models
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	dbservice "github.com/mynaparrot/plugnmeet-server/pkg/services/db"
+	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/fx"
+)
+
+var tenantIdRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+type tenantCtxKey struct{}
+
+// WithTenantId returns a copy of ctx carrying the tenant of the caller.
+// An empty tenantId means the root api key from the config file.
+func WithTenantId(ctx context.Context, tenantId string) context.Context {
+	return context.WithValue(ctx, tenantCtxKey{}, tenantId)
+}
+
+// TenantIdFromContext returns the tenant of the caller, empty for the root api key
+func TenantIdFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if id, ok := ctx.Value(tenantCtxKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// canAccessTenantResource checks if the caller can use a resource of ownerTenantId,
+// root api key (empty tenantId) has access to everything
+func canAccessTenantResource(tenantId, ownerTenantId string) bool {
+	return tenantId == "" || tenantId == ownerTenantId
+}
+
+// TenantModel manages the api keys of the tenants, their quotas
+// and verifies that a tenant only access its own resources.
+// The api key of the config file acts as root & has access to everything.
+type TenantModel struct {
+	app         *config.AppConfig
+	ds          *dbservice.DatabaseService
+	natsService *natsservice.NatsService
+	logger      *logrus.Entry
+}
+
+type TenantModelArgs struct {
+	fx.In
+	App         *config.AppConfig
+	Ds          *dbservice.DatabaseService
+	NatsService *natsservice.NatsService
+	Logger      *logrus.Logger
+}
+
+func NewTenantModel(args TenantModelArgs) *TenantModel {
+	return &TenantModel{
+		app:         args.App,
+		ds:          args.Ds,
+		natsService: args.NatsService,
+		logger:      args.Logger.WithField("model", "tenant"),
+	}
+}
+
+type CreateTenantReq struct {
+	// TenantId is optional, a new one will be generated if empty
+	TenantId                  string `json:"tenant_id,omitempty"`
+	Name                      string `json:"name"`
+	MaxConcurrentRooms        int64  `json:"max_concurrent_rooms"`
+	MaxConcurrentParticipants int64  `json:"max_concurrent_participants"`
+	// RecordingRetention overrides the retention of the recordings of this tenant
+	RecordingRetention *RecordingRetentionSettings `json:"recording_retention,omitempty"`
+	// InsightsBudget overrides the monthly budget of the insights services of this tenant
+	InsightsBudget *config.InsightsBudget `json:"insights_budget,omitempty"`
+}
+
+type UpdateTenantReq struct {
+	TenantId                  string  `json:"tenant_id"`
+	Name                      *string `json:"name,omitempty"`
+	MaxConcurrentRooms        *int64  `json:"max_concurrent_rooms,omitempty"`
+	MaxConcurrentParticipants *int64  `json:"max_concurrent_participants,omitempty"`
+	IsActive                  *bool   `json:"is_active,omitempty"`
+	// RecordingRetention will replace the existing one, use an empty object to remove it
+	RecordingRetention *RecordingRetentionSettings `json:"recording_retention,omitempty"`
+	// InsightsBudget will replace the existing one, use an empty object to remove it
+	InsightsBudget *config.InsightsBudget `json:"insights_budget,omitempty"`
+}
+
+type TenantIdReq struct {
+	TenantId string `json:"tenant_id"`
+}
+
+type FetchTenantsReq struct {
+	From    uint32 `json:"from"`
+	Limit   uint32 `json:"limit"`
+	OrderBy string `json:"order_by"`
+}
+
+type TenantInfo struct {
+	TenantId string `json:"tenant_id"`
+	Name     string `json:"name"`
+	ApiKey   string `json:"api_key"`
+	// Secret will only be returned during creation
+	Secret                    string                      `json:"secret,omitempty"`
+	MaxConcurrentRooms        int64                       `json:"max_concurrent_rooms"`
+	MaxConcurrentParticipants int64                       `json:"max_concurrent_participants"`
+	IsActive                  bool                        `json:"is_active"`
+	RecordingRetention        *RecordingRetentionSettings `json:"recording_retention,omitempty"`
+	InsightsBudget            *config.InsightsBudget      `json:"insights_budget,omitempty"`
+	Created                   int64                       `json:"created"`
+	Modified                  int64                       `json:"modified"`
+}
+
+type FetchTenantsResult struct {
+	TotalTenants int64         `json:"total_tenants"`
+	From         uint32        `json:"from"`
+	Limit        uint32        `json:"limit"`
+	OrderBy      string        `json:"order_by"`
+	TenantsList  []*TenantInfo `json:"tenants_list"`
+}
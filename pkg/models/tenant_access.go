@@ -0,0 +1,116 @@
+package models
+
+import (
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+)
+
+// ResolveApiKey returns the tenant & the secrets to verify the request signature.
+// During the grace period of a rotation, the previous secret will be returned too.
+// For the root api key of the config file the tenantId will be empty.
+func (m *TenantModel) ResolveApiKey(apiKey string) (tenantId string, secrets []string, err error) {
+	if subtle.ConstantTimeCompare([]byte(apiKey), []byte(m.app.Client.ApiKey)) == 1 {
+		return "", m.natsService.GetClientSecrets(), nil
+	}
+
+	t, err := m.ds.GetTenantByApiKey(apiKey)
+	if err != nil {
+		return "", nil, err
+	}
+	if t == nil || t.IsActive != 1 {
+		return "", nil, config.InvalidConsumerKey
+	}
+
+	// db always has the active secret, so we'll only use the rotation info if it matches
+	if info := m.natsService.GetApiKeySecrets(apiKey); info != nil && info.Secret == t.Secret {
+		return t.TenantId, info.ValidSecrets(), nil
+	}
+	return t.TenantId, []string{t.Secret}, nil
+}
+
+// CanAccessRoom checks if the room belongs to the tenant.
+// A room which was never created is free to use by any tenant.
+func (m *TenantModel) CanAccessRoom(tenantId, roomId string) (bool, error) {
+	if tenantId == "" || roomId == "" {
+		return true, nil
+	}
+	info, err := m.ds.GetLastRoomInfoByRoomId(roomId)
+	if err != nil {
+		return false, err
+	}
+	return info == nil || info.TenantId == tenantId, nil
+}
+
+// CanAccessRecording checks if the recording belongs to the tenant,
+// unknown recordings will be handled by the actual request
+func (m *TenantModel) CanAccessRecording(tenantId, recordId string) (bool, error) {
+	if tenantId == "" || recordId == "" {
+		return true, nil
+	}
+	rec, err := m.ds.GetRecording(recordId)
+	if err != nil {
+		return false, err
+	}
+	return rec == nil || rec.TenantId == tenantId, nil
+}
+
+// CanAccessArtifact checks if the artifact belongs to the tenant,
+// unknown artifacts will be handled by the actual request
+func (m *TenantModel) CanAccessArtifact(tenantId, artifactId string) (bool, error) {
+	if tenantId == "" || artifactId == "" {
+		return true, nil
+	}
+	artifact, err := m.ds.GetRoomArtifactByArtifactID(artifactId)
+	if err != nil {
+		return false, err
+	}
+	return artifact == nil || artifact.TenantId == tenantId, nil
+}
+
+// CheckRoomsQuota returns error if the tenant can't start one more room
+func (m *TenantModel) CheckRoomsQuota(tenantId string) error {
+	if tenantId == "" {
+		return nil
+	}
+	t, err := m.ds.GetTenant(tenantId)
+	if err != nil {
+		return err
+	}
+	if t == nil || t.MaxConcurrentRooms == 0 {
+		return nil
+	}
+
+	running, err := m.ds.CountTenantRunningRooms(tenantId)
+	if err != nil {
+		return err
+	}
+	if running >= t.MaxConcurrentRooms {
+		return fmt.Errorf("%w (%d)", config.ErrTenantRoomsQuotaExceeded, t.MaxConcurrentRooms)
+	}
+	return nil
+}
+
+// CheckParticipantsQuota returns error if one more participant can't join any room of the tenant
+func (m *TenantModel) CheckParticipantsQuota(tenantId string) error {
+	if tenantId == "" {
+		return nil
+	}
+	t, err := m.ds.GetTenant(tenantId)
+	if err != nil {
+		return err
+	}
+	if t == nil || t.MaxConcurrentParticipants == 0 {
+		return nil
+	}
+
+	joined, err := m.ds.SumTenantJoinedParticipants(tenantId)
+	if err != nil {
+		return err
+	}
+	if joined >= t.MaxConcurrentParticipants {
+		return fmt.Errorf("%w (%d)", config.ErrTenantUsersQuotaExceeded, t.MaxConcurrentParticipants)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/sirupsen/logrus"
+)
+
+// CreateTenant creates a new tenant with a fresh api key & secret.
+// The secret is only returned here, so the caller should store it safely.
+func (m *TenantModel) CreateTenant(r *CreateTenantReq) (*TenantInfo, error) {
+	if r.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if r.MaxConcurrentRooms < 0 || r.MaxConcurrentParticipants < 0 {
+		return nil, fmt.Errorf("limits can't be negative, use 0 for unlimited")
+	}
+	if r.TenantId == "" {
+		r.TenantId = uuid.NewString()
+	} else if !tenantIdRegex.MatchString(r.TenantId) {
+		return nil, fmt.Errorf("tenant_id can only contain letters, numbers, '-' & '_' and max 64 characters")
+	}
+	if err := r.RecordingRetention.validate(); err != nil {
+		return nil, err
+	}
+	if err := validateInsightsBudget(r.InsightsBudget); err != nil {
+		return nil, err
+	}
+
+	existing, err := m.ds.GetTenant(r.TenantId)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("tenant with tenant_id '%s' already exists", r.TenantId)
+	}
+
+	t := &dbmodels.Tenant{
+		TenantId:                  r.TenantId,
+		Name:                      r.Name,
+		ApiKey:                    "pnm_" + randomHex(8),
+		Secret:                    generateSecret(),
+		MaxConcurrentRooms:        r.MaxConcurrentRooms,
+		MaxConcurrentParticipants: r.MaxConcurrentParticipants,
+		IsActive:                  1,
+	}
+	t.RecordingRetentionPublishedDays, t.RecordingRetentionUnpublishedDays = r.RecordingRetention.values()
+	t.InsightsBudget = marshalInsightsBudget(r.InsightsBudget)
+	if _, err := m.ds.InsertOrUpdateTenant(t); err != nil {
+		m.logger.WithError(err).Errorln("failed to save tenant")
+		return nil, err
+	}
+	m.logger.WithFields(logrus.Fields{
+		"tenant_id": t.TenantId,
+		"api_key":   t.ApiKey,
+	}).Info("tenant created")
+
+	info := m.toTenantInfo(t)
+	info.Secret = t.Secret
+	return info, nil
+}
+
+// UpdateTenant modifies an existing tenant, only the provided fields will be changed.
+func (m *TenantModel) UpdateTenant(r *UpdateTenantReq) (*TenantInfo, error) {
+	t, err := m.ds.GetTenant(r.TenantId)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, config.NotFoundErr
+	}
+
+	if r.Name != nil {
+		if *r.Name == "" {
+			return nil, fmt.Errorf("name can't be empty")
+		}
+		t.Name = *r.Name
+	}
+	if r.MaxConcurrentRooms != nil {
+		if *r.MaxConcurrentRooms < 0 {
+			return nil, fmt.Errorf("limits can't be negative, use 0 for unlimited")
+		}
+		t.MaxConcurrentRooms = *r.MaxConcurrentRooms
+	}
+	if r.MaxConcurrentParticipants != nil {
+		if *r.MaxConcurrentParticipants < 0 {
+			return nil, fmt.Errorf("limits can't be negative, use 0 for unlimited")
+		}
+		t.MaxConcurrentParticipants = *r.MaxConcurrentParticipants
+	}
+	if r.IsActive != nil {
+		t.IsActive = 0
+		if *r.IsActive {
+			t.IsActive = 1
+		}
+	}
+	if r.RecordingRetention != nil {
+		if err := r.RecordingRetention.validate(); err != nil {
+			return nil, err
+		}
+		t.RecordingRetentionPublishedDays, t.RecordingRetentionUnpublishedDays = r.RecordingRetention.values()
+	}
+	if r.InsightsBudget != nil {
+		if err := validateInsightsBudget(r.InsightsBudget); err != nil {
+			return nil, err
+		}
+		t.InsightsBudget = marshalInsightsBudget(r.InsightsBudget)
+	}
+
+	if _, err := m.ds.InsertOrUpdateTenant(t); err != nil {
+		return nil, err
+	}
+	return m.toTenantInfo(t), nil
+}
+
+// DeleteTenant removes the tenant, so its api key won't work anymore.
+// Rooms, recordings etc. of the tenant will be kept & stay accessible by the root api key.
+func (m *TenantModel) DeleteTenant(r *TenantIdReq) error {
+	t, err := m.ds.GetTenant(r.TenantId)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return config.NotFoundErr
+	}
+
+	if _, err := m.ds.DeleteTenant(t.TenantId); err != nil {
+		return err
+	}
+	if err := m.natsService.DeleteApiKeySecrets(t.ApiKey); err != nil {
+		m.logger.WithError(err).Warnln("failed to delete rotated secrets of the tenant")
+	}
+	m.logger.WithFields(logrus.Fields{
+		"tenant_id": t.TenantId,
+		"api_key":   t.ApiKey,
+	}).Info("tenant deleted")
+
+	return nil
+}
+
+func (m *TenantModel) FetchTenants(r *FetchTenantsReq) (*FetchTenantsResult, error) {
+	if r.Limit <= 0 {
+		r.Limit = 20
+	} else if r.Limit > 100 {
+		r.Limit = 100
+	}
+	if r.OrderBy == "" {
+		r.OrderBy = "DESC"
+	}
+
+	data, total, err := m.ds.GetTenants(uint64(r.From), uint64(r.Limit), &r.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, config.NotFoundErr
+	}
+
+	tenants := make([]*TenantInfo, 0, len(data))
+	for i := range data {
+		tenants = append(tenants, m.toTenantInfo(&data[i]))
+	}
+
+	return &FetchTenantsResult{
+		TotalTenants: total,
+		From:         r.From,
+		Limit:        r.Limit,
+		OrderBy:      r.OrderBy,
+		TenantsList:  tenants,
+	}, nil
+}
+
+func (m *TenantModel) toTenantInfo(t *dbmodels.Tenant) *TenantInfo {
+	return &TenantInfo{
+		TenantId:                  t.TenantId,
+		Name:                      t.Name,
+		ApiKey:                    t.ApiKey,
+		MaxConcurrentRooms:        t.MaxConcurrentRooms,
+		MaxConcurrentParticipants: t.MaxConcurrentParticipants,
+		IsActive:                  t.IsActive == 1,
+		RecordingRetention:        toRecordingRetentionSettings(t.RecordingRetentionPublishedDays, t.RecordingRetentionUnpublishedDays),
+		InsightsBudget:            parseInsightsBudget(t.InsightsBudget),
+		Created:                   t.Created.Unix(),
+		Modified:                  t.Modified.Unix(),
+	}
+}
+
+// generateSecret returns a random secret of 52 characters
+func generateSecret() string {
+	return rand.Text() + rand.Text()
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
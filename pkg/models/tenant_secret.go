@@ -0,0 +1,191 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
+	"github.com/sirupsen/logrus"
+)
+
+type ApiKeyReq struct {
+	// ApiKey is optional, default is the api key used for the request
+	ApiKey string `json:"api_key,omitempty"`
+}
+
+type RotateSecretReq struct {
+	ApiKey string `json:"api_key,omitempty"`
+	// GracePeriod in seconds for which the old secret will be accepted,
+	// default is client.secret_rotation_grace_period. Use 0 to revoke it immediately.
+	GracePeriod *int64 `json:"grace_period,omitempty"`
+}
+
+type ApiKeySecretInfo struct {
+	ApiKey   string `json:"api_key"`
+	TenantId string `json:"tenant_id,omitempty"`
+	// Secret will only be returned after rotation
+	Secret                  string `json:"secret,omitempty"`
+	RotatedAt               int64  `json:"rotated_at,omitempty"`
+	PreviousSecretValid     bool   `json:"previous_secret_valid"`
+	PreviousSecretExpiresAt int64  `json:"previous_secret_expires_at,omitempty"`
+}
+
+// RotateSecret generates a new secret for the api key. The old secret will be accepted
+// till the grace period ends, so integrations can be updated without downtime.
+// The new secret is shared with all the nodes using NATS KV.
+func (m *TenantModel) RotateSecret(callerTenantId string, r *RotateSecretReq) (*ApiKeySecretInfo, error) {
+	apiKey, tenantId, err := m.resolveTargetApiKey(callerTenantId, r.ApiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	grace := *m.app.Client.SecretRotationGracePeriod
+	if r.GracePeriod != nil {
+		if *r.GracePeriod < 0 {
+			return nil, fmt.Errorf("grace_period can't be negative")
+		}
+		grace = time.Duration(*r.GracePeriod) * time.Second
+	}
+
+	now := time.Now()
+	info := &natsservice.ApiKeySecrets{
+		ApiKey:    apiKey,
+		Secret:    generateSecret(),
+		RotatedAt: now.Unix(),
+	}
+
+	if tenantId == "" {
+		if grace > 0 {
+			info.PreviousSecret = m.natsService.GetClientSecret()
+			info.PreviousExpiresAt = now.Add(grace).Unix()
+		}
+		if err := m.natsService.SaveApiKeySecrets(info); err != nil {
+			return nil, err
+		}
+	} else {
+		t, err := m.ds.GetTenant(tenantId)
+		if err != nil {
+			return nil, err
+		}
+		if t == nil {
+			return nil, config.NotFoundErr
+		}
+		if grace > 0 {
+			info.PreviousSecret = t.Secret
+			info.PreviousExpiresAt = now.Add(grace).Unix()
+		}
+		// DB first, which is the source of truth. If KV can't be updated,
+		// we'll restore the old secret, so both keep accepting the same one.
+		oldSecret := t.Secret
+		t.Secret = info.Secret
+		if _, err := m.ds.InsertOrUpdateTenant(t); err != nil {
+			return nil, err
+		}
+		if err := m.natsService.SaveApiKeySecrets(info); err != nil {
+			t.Secret = oldSecret
+			if _, rErr := m.ds.InsertOrUpdateTenant(t); rErr != nil {
+				m.logger.WithError(rErr).WithField("tenant_id", tenantId).Errorln("failed to restore old secret of tenant")
+			}
+			return nil, err
+		}
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"api_key":      apiKey,
+		"tenant_id":    tenantId,
+		"grace_period": grace.String(),
+	}).Info("api key secret rotated")
+
+	res := m.toApiKeySecretInfo(tenantId, info)
+	res.Secret = info.Secret
+	return res, nil
+}
+
+// GetSecretStatus returns the rotation status of the api key
+func (m *TenantModel) GetSecretStatus(callerTenantId string, r *ApiKeyReq) (*ApiKeySecretInfo, error) {
+	apiKey, tenantId, err := m.resolveTargetApiKey(callerTenantId, r.ApiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	info := m.natsService.GetApiKeySecrets(apiKey)
+	if info == nil {
+		return &ApiKeySecretInfo{
+			ApiKey:   apiKey,
+			TenantId: tenantId,
+		}, nil
+	}
+	return m.toApiKeySecretInfo(tenantId, info), nil
+}
+
+// RevokePreviousSecret ends the grace period of the old secret immediately
+func (m *TenantModel) RevokePreviousSecret(callerTenantId string, r *ApiKeyReq) (*ApiKeySecretInfo, error) {
+	apiKey, tenantId, err := m.resolveTargetApiKey(callerTenantId, r.ApiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	info := m.natsService.GetApiKeySecrets(apiKey)
+	if info == nil || info.PreviousSecret == "" {
+		return nil, fmt.Errorf("api key doesn't have any previous secret")
+	}
+	info.PreviousSecret = ""
+	info.PreviousExpiresAt = 0
+	if err := m.natsService.SaveApiKeySecrets(info); err != nil {
+		return nil, err
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"api_key":   apiKey,
+		"tenant_id": tenantId,
+	}).Info("previous secret of api key revoked")
+
+	return m.toApiKeySecretInfo(tenantId, info), nil
+}
+
+// resolveTargetApiKey returns the api key to manage & its tenant.
+// A tenant can only manage its own api key, the root api key can manage all.
+func (m *TenantModel) resolveTargetApiKey(callerTenantId, apiKey string) (string, string, error) {
+	if apiKey == "" || apiKey == m.app.Client.ApiKey {
+		if callerTenantId == "" {
+			return m.app.Client.ApiKey, "", nil
+		}
+		if apiKey != "" {
+			return "", "", config.ErrTenantAccessDenied
+		}
+		t, err := m.ds.GetTenant(callerTenantId)
+		if err != nil {
+			return "", "", err
+		}
+		if t == nil {
+			return "", "", config.NotFoundErr
+		}
+		return t.ApiKey, t.TenantId, nil
+	}
+
+	t, err := m.ds.GetTenantByApiKey(apiKey)
+	if err != nil {
+		return "", "", err
+	}
+	if t == nil {
+		return "", "", config.NotFoundErr
+	}
+	if !canAccessTenantResource(callerTenantId, t.TenantId) {
+		return "", "", config.ErrTenantAccessDenied
+	}
+	return t.ApiKey, t.TenantId, nil
+}
+
+func (m *TenantModel) toApiKeySecretInfo(tenantId string, info *natsservice.ApiKeySecrets) *ApiKeySecretInfo {
+	res := &ApiKeySecretInfo{
+		ApiKey:    info.ApiKey,
+		TenantId:  tenantId,
+		RotatedAt: info.RotatedAt,
+	}
+	if len(info.ValidSecrets()) > 1 {
+		res.PreviousSecretValid = true
+		res.PreviousSecretExpiresAt = info.PreviousExpiresAt
+	}
+	return res
+}
@@ -18,6 +18,7 @@ type UserModel struct {
 	natsService    *natsservice.NatsService
 	analyticsModel *AnalyticsModel
 	am             *AuthModel
+	tm             *TenantModel
 	logger         *logrus.Entry
 }
 
@@ -30,6 +31,7 @@ type UserModelArgs struct {
 	NatsService    *natsservice.NatsService
 	AnalyticsModel *AnalyticsModel
 	Am             *AuthModel
+	Tm             *TenantModel
 	Logger         *logrus.Logger
 }
 
@@ -42,6 +44,7 @@ func NewUserModel(args UserModelArgs) *UserModel {
 		natsService:    args.NatsService,
 		analyticsModel: args.AnalyticsModel,
 		am:             args.Am,
+		tm:             args.Tm,
 		logger:         args.Logger.WithField("model", "user"),
 	}
 }
@@ -66,6 +66,14 @@ func (m *UserModel) GetPNMJoinToken(ctx context.Context, g *plugnmeet.GenerateTo
 		return "", err
 	}
 
+	// Step 5: Make sure the tenant of the room hasn't reached its participants limit.
+	if g.UserInfo.UserId != config.RecorderBot && g.UserInfo.UserId != config.RtmpBot {
+		if err := m.checkTenantParticipantsQuota(rInfo.DbTableId); err != nil {
+			log.WithError(err).Warnln("participants quota check failed")
+			return "", err
+		}
+	}
+
 	if g.UserInfo.UserMetadata == nil {
 		g.UserInfo.UserMetadata = new(plugnmeet.UserMetadata)
 	}
@@ -73,13 +81,13 @@ func (m *UserModel) GetPNMJoinToken(ctx context.Context, g *plugnmeet.GenerateTo
 		g.UserInfo.UserMetadata.RaisedHand = new(plugnmeet.UserRaisedHand)
 	}
 
-	// Step 5: If no external user ID is provided, use the internal user ID as the default.
+	// Step 6: If no external user ID is provided, use the internal user ID as the default.
 	if g.UserInfo.UserMetadata.ExUserId == nil || *g.UserInfo.UserMetadata.ExUserId == "" {
 		// if empty, then we'll use the default user id
 		g.UserInfo.UserMetadata.ExUserId = new(strings.Clone(g.UserInfo.UserId))
 	}
 
-	// Step 6: Handle user ID generation and duplicate user checks.
+	// Step 7: Handle user ID generation and duplicate user checks.
 	if meta.RoomFeatures.AutoGenUserId != nil && *meta.RoomFeatures.AutoGenUserId {
 		if g.UserInfo.UserId != config.RecorderBot && g.UserInfo.UserId != config.RtmpBot {
 			// we'll auto generate user id no matter what sent
@@ -111,7 +119,7 @@ func (m *UserModel) GetPNMJoinToken(ctx context.Context, g *plugnmeet.GenerateTo
 		}
 	}
 
-	// Step 7: Validate the format of the final user ID.
+	// Step 8: Validate the format of the final user ID.
 	if !validUserIDRegex.MatchString(g.UserInfo.UserId) {
 		err = fmt.Errorf("user_id should only contain ASCII letters (a-z A-Z), digits (0-9) or -_")
 		log.WithError(err).Errorln()
@@ -129,7 +137,7 @@ func (m *UserModel) GetPNMJoinToken(ctx context.Context, g *plugnmeet.GenerateTo
 		return "", err
 	}
 
-	// Step 8: Assign permissions and lock settings based on whether the user is an admin.
+	// Step 9: Assign permissions and lock settings based on whether the user is an admin.
 	if g.UserInfo.IsAdmin {
 		g.UserInfo.UserMetadata.IsAdmin = true
 		g.UserInfo.UserMetadata.WaitForApproval = false
@@ -161,14 +169,14 @@ func (m *UserModel) GetPNMJoinToken(ctx context.Context, g *plugnmeet.GenerateTo
 		g.UserInfo.UserMetadata.RecordWebcam = new(true)
 	}
 
-	// Step 9: Add the user's information to the NATS key-value store for the room.
+	// Step 10: Add the user's information to the NATS key-value store for the room.
 	err = m.natsService.AddUser(g.RoomId, g.UserInfo.UserId, g.UserInfo.Name, g.UserInfo.IsAdmin, g.UserInfo.UserMetadata.IsPresenter, g.UserInfo.UserMetadata, g.UserInfo.ClientType)
 	if err != nil {
 		log.WithError(err).Errorln("failed to add user to nats")
 		return "", err
 	}
 
-	// Step 10: Generate and return the final JWT for the client to use.
+	// Step 11: Generate and return the final JWT for the client to use.
 	c := &plugnmeet.PlugNmeetTokenClaims{
 		Name:       g.UserInfo.Name,
 		UserId:     g.UserInfo.UserId,
@@ -212,3 +220,16 @@ func (m *UserModel) waitForUserToBeOffline(ctx context.Context, roomID, userID s
 		}
 	}
 }
+
+// checkTenantParticipantsQuota returns error if the tenant of the room
+// can't have one more participant
+func (m *UserModel) checkTenantParticipantsQuota(roomTableId uint64) error {
+	roomInfo, err := m.ds.GetRoomInfoByTableId(roomTableId)
+	if err != nil {
+		return err
+	}
+	if roomInfo == nil || roomInfo.TenantId == "" {
+		return nil
+	}
+	return m.tm.CheckParticipantsQuota(roomInfo.TenantId)
+}
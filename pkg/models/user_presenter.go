@@ -80,6 +80,8 @@ func (m *UserModel) SwitchPresenter(r *plugnmeet.SwitchPresenterReq) error {
 		}
 	}
 
+	m.analyticsModel.AddRoomEvent(r.RoomId, analyticsPresenterChangedEvent, newPresenterId)
+
 	log.Info("Presenter switch process completed successfully")
 	return nil
 }
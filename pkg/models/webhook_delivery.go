@@ -0,0 +1,113 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+)
+
+type FetchWebhookDeliveriesReq struct {
+	RoomId  string `json:"room_id"`
+	Event   string `json:"event"`
+	Status  string `json:"status"`
+	From    uint32 `json:"from"`
+	Limit   uint32 `json:"limit"`
+	OrderBy string `json:"order_by"`
+}
+
+type RedeliverWebhookReq struct {
+	DeliveryIds []string `json:"delivery_ids"`
+}
+
+type WebhookDeliveryInfo struct {
+	DeliveryId     string          `json:"delivery_id"`
+	RoomId         string          `json:"room_id"`
+	RoomSid        string          `json:"room_sid"`
+	Event          string          `json:"event"`
+	Url            string          `json:"url"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastStatusCode int             `json:"last_status_code"`
+	LastError      string          `json:"last_error,omitempty"`
+	Payload        json.RawMessage `json:"payload,omitempty"`
+	Created        int64           `json:"created"`
+	Modified       int64           `json:"modified"`
+}
+
+type FetchWebhookDeliveriesResult struct {
+	TotalDeliveries int64                  `json:"total_deliveries"`
+	From            uint32                 `json:"from"`
+	Limit           uint32                 `json:"limit"`
+	OrderBy         string                 `json:"order_by"`
+	DeliveriesList  []*WebhookDeliveryInfo `json:"deliveries_list"`
+}
+
+// FetchWebhookDeliveries returns the delivery logs of the tenant
+func (m *WebhookModel) FetchWebhookDeliveries(tenantId string, r *FetchWebhookDeliveriesReq) (*FetchWebhookDeliveriesResult, error) {
+	if r.Limit <= 0 {
+		r.Limit = 20
+	} else if r.Limit > 100 {
+		r.Limit = 100
+	}
+	if r.OrderBy == "" {
+		r.OrderBy = "DESC"
+	}
+
+	data, total, err := m.ds.GetWebhookDeliveries(tenantId, r.RoomId, r.Event, r.Status, uint64(r.From), uint64(r.Limit), &r.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, config.NotFoundErr
+	}
+
+	list := make([]*WebhookDeliveryInfo, 0, len(data))
+	for i := range data {
+		d := &data[i]
+		info := &WebhookDeliveryInfo{
+			DeliveryId:     d.DeliveryId,
+			RoomId:         d.RoomId,
+			RoomSid:        d.RoomSid,
+			Event:          d.Event,
+			Url:            d.Url,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			Created:        d.Created.Unix(),
+			Modified:       d.Modified.Unix(),
+		}
+		if json.Valid([]byte(d.Payload)) {
+			info.Payload = json.RawMessage(d.Payload)
+		}
+		list = append(list, info)
+	}
+
+	return &FetchWebhookDeliveriesResult{
+		TotalDeliveries: total,
+		From:            r.From,
+		Limit:           r.Limit,
+		OrderBy:         r.OrderBy,
+		DeliveriesList:  list,
+	}, nil
+}
+
+// RedeliverWebhook sends the deliveries again, useful for the dead ones
+func (m *WebhookModel) RedeliverWebhook(tenantId string, r *RedeliverWebhookReq) error {
+	return m.webhookNotifier.Redeliver(tenantId, r.DeliveryIds...)
+}
+
+// AttemptWebhookDelivery sends a queued delivery,
+// it returns the delay before the next attempt if it should be retried.
+func (m *WebhookModel) AttemptWebhookDelivery(deliveryId string) (*dbmodels.WebhookDelivery, time.Duration, error) {
+	d, err := m.webhookNotifier.AttemptDelivery(deliveryId)
+	if err != nil || d == nil {
+		return nil, 0, err
+	}
+	if d.Status == dbmodels.WebhookDeliveryStatusRetrying {
+		return d, m.webhookNotifier.WebhookRetryDelay(d.Attempts), nil
+	}
+	return d, 0, nil
+}
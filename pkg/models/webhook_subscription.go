@@ -0,0 +1,246 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"github.com/sirupsen/logrus"
+)
+
+type CreateWebhookSubscriptionReq struct {
+	Url string `json:"url"`
+	// Events to receive, empty means all events
+	Events       []string `json:"events,omitempty"`
+	RoomIdPrefix string   `json:"room_id_prefix,omitempty"`
+}
+
+type UpdateWebhookSubscriptionReq struct {
+	SubscriptionId string   `json:"subscription_id"`
+	Url            *string  `json:"url,omitempty"`
+	Events         []string `json:"events,omitempty"`
+	RoomIdPrefix   *string  `json:"room_id_prefix,omitempty"`
+	IsActive       *bool    `json:"is_active,omitempty"`
+	// RegenerateSecret will replace the secret used to sign the requests
+	RegenerateSecret bool `json:"regenerate_secret,omitempty"`
+}
+
+type WebhookSubscriptionIdReq struct {
+	SubscriptionId string `json:"subscription_id"`
+}
+
+type FetchWebhookSubscriptionsReq struct {
+	From    uint32 `json:"from"`
+	Limit   uint32 `json:"limit"`
+	OrderBy string `json:"order_by"`
+}
+
+type WebhookSubscriptionInfo struct {
+	SubscriptionId string   `json:"subscription_id"`
+	Url            string   `json:"url"`
+	Events         []string `json:"events"`
+	RoomIdPrefix   string   `json:"room_id_prefix,omitempty"`
+	IsActive       bool     `json:"is_active"`
+	// Secret is only returned when it was created or regenerated
+	Secret   string `json:"secret,omitempty"`
+	Created  int64  `json:"created"`
+	Modified int64  `json:"modified"`
+}
+
+type FetchWebhookSubscriptionsResult struct {
+	TotalSubscriptions int64                      `json:"total_subscriptions"`
+	From               uint32                     `json:"from"`
+	Limit              uint32                     `json:"limit"`
+	OrderBy            string                     `json:"order_by"`
+	SubscriptionsList  []*WebhookSubscriptionInfo `json:"subscriptions_list"`
+}
+
+func toWebhookSubscriptionInfo(s *dbmodels.WebhookSubscription) *WebhookSubscriptionInfo {
+	info := &WebhookSubscriptionInfo{
+		SubscriptionId: s.SubscriptionId,
+		Url:            s.Url,
+		Events:         []string{},
+		RoomIdPrefix:   s.RoomIdPrefix,
+		IsActive:       s.IsActive == 1,
+		Created:        s.Created.Unix(),
+		Modified:       s.Modified.Unix(),
+	}
+	if s.Events != "" {
+		info.Events = strings.Split(s.Events, ",")
+	}
+	return info
+}
+
+// CreateWebhookSubscription adds a new endpoint to receive the webhook events.
+// Subscriptions of a tenant will only receive events of the tenant's rooms.
+func (m *WebhookModel) CreateWebhookSubscription(tenantId string, r *CreateWebhookSubscriptionReq) (*WebhookSubscriptionInfo, error) {
+	if !m.app.Client.WebhookConf.Enable {
+		return nil, fmt.Errorf("webhook is not enabled")
+	}
+	if err := m.webhookNotifier.ValidateWebhookUrl(r.Url); err != nil {
+		return nil, err
+	}
+	events, err := prepareWebhookSubscriptionEvents(r.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &dbmodels.WebhookSubscription{
+		SubscriptionId: uuid.NewString(),
+		TenantId:       tenantId,
+		Url:            r.Url,
+		Events:         events,
+		RoomIdPrefix:   r.RoomIdPrefix,
+		IsActive:       1,
+		Secret:         generateSecret(),
+	}
+	if _, err := m.ds.InsertOrUpdateWebhookSubscription(s); err != nil {
+		m.logger.WithError(err).Errorln("failed to save webhook subscription")
+		return nil, err
+	}
+	m.logger.WithFields(logrus.Fields{
+		"subscription_id": s.SubscriptionId,
+		"url":             s.Url,
+	}).Info("webhook subscription created")
+
+	m.webhookNotifier.RefreshSubscriptions()
+	info := toWebhookSubscriptionInfo(s)
+	info.Secret = s.Secret
+	return info, nil
+}
+
+// UpdateWebhookSubscription modifies an existing subscription, only the provided fields will be changed.
+func (m *WebhookModel) UpdateWebhookSubscription(tenantId string, r *UpdateWebhookSubscriptionReq) (*WebhookSubscriptionInfo, error) {
+	s, err := m.ds.GetWebhookSubscription(r.SubscriptionId)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil || !canAccessTenantResource(tenantId, s.TenantId) {
+		return nil, config.NotFoundErr
+	}
+
+	if r.Url != nil {
+		if err := m.webhookNotifier.ValidateWebhookUrl(*r.Url); err != nil {
+			return nil, err
+		}
+		s.Url = *r.Url
+	}
+	if r.Events != nil {
+		events, err := prepareWebhookSubscriptionEvents(r.Events)
+		if err != nil {
+			return nil, err
+		}
+		s.Events = events
+	}
+	if r.RoomIdPrefix != nil {
+		s.RoomIdPrefix = *r.RoomIdPrefix
+	}
+	if r.IsActive != nil {
+		s.IsActive = 0
+		if *r.IsActive {
+			s.IsActive = 1
+		}
+	}
+	if r.RegenerateSecret {
+		s.Secret = generateSecret()
+	}
+
+	if _, err := m.ds.InsertOrUpdateWebhookSubscription(s); err != nil {
+		return nil, err
+	}
+
+	m.webhookNotifier.RefreshSubscriptions()
+	info := toWebhookSubscriptionInfo(s)
+	if r.RegenerateSecret {
+		info.Secret = s.Secret
+	}
+	return info, nil
+}
+
+func (m *WebhookModel) DeleteWebhookSubscription(tenantId string, r *WebhookSubscriptionIdReq) error {
+	s, err := m.ds.GetWebhookSubscription(r.SubscriptionId)
+	if err != nil {
+		return err
+	}
+	if s == nil || !canAccessTenantResource(tenantId, s.TenantId) {
+		return config.NotFoundErr
+	}
+
+	if _, err := m.ds.DeleteWebhookSubscription(s.SubscriptionId); err != nil {
+		return err
+	}
+	m.logger.WithFields(logrus.Fields{
+		"subscription_id": s.SubscriptionId,
+		"url":             s.Url,
+	}).Info("webhook subscription deleted")
+
+	m.webhookNotifier.RefreshSubscriptions()
+	return nil
+}
+
+func (m *WebhookModel) GetWebhookSubscriptionInfo(tenantId string, r *WebhookSubscriptionIdReq) (*WebhookSubscriptionInfo, error) {
+	s, err := m.ds.GetWebhookSubscription(r.SubscriptionId)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil || !canAccessTenantResource(tenantId, s.TenantId) {
+		return nil, config.NotFoundErr
+	}
+	return toWebhookSubscriptionInfo(s), nil
+}
+
+func (m *WebhookModel) FetchWebhookSubscriptions(tenantId string, r *FetchWebhookSubscriptionsReq) (*FetchWebhookSubscriptionsResult, error) {
+	if r.Limit <= 0 {
+		r.Limit = 20
+	} else if r.Limit > 100 {
+		r.Limit = 100
+	}
+	if r.OrderBy == "" {
+		r.OrderBy = "DESC"
+	}
+
+	data, total, err := m.ds.GetWebhookSubscriptions(tenantId, uint64(r.From), uint64(r.Limit), &r.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, config.NotFoundErr
+	}
+
+	list := make([]*WebhookSubscriptionInfo, 0, len(data))
+	for i := range data {
+		list = append(list, toWebhookSubscriptionInfo(&data[i]))
+	}
+
+	return &FetchWebhookSubscriptionsResult{
+		TotalSubscriptions: total,
+		From:               r.From,
+		Limit:              r.Limit,
+		OrderBy:            r.OrderBy,
+		SubscriptionsList:  list,
+	}, nil
+}
+
+// prepareWebhookSubscriptionEvents returns the event names to store in db
+func prepareWebhookSubscriptionEvents(events []string) (string, error) {
+	var list []string
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		if e == "" || slices.Contains(list, e) {
+			continue
+		}
+		if strings.Contains(e, ",") {
+			return "", fmt.Errorf("invalid event name '%s'", e)
+		}
+		list = append(list, e)
+	}
+
+	joined := strings.Join(list, ",")
+	if len(joined) > 1024 {
+		return "", fmt.Errorf("too many events")
+	}
+	return joined, nil
+}
@@ -10,12 +10,15 @@ import (
 )
 
 // GetArtifacts retrieves a paginated and sorted list of artifacts,
-// optionally filtered by room IDs, roomSid and artifact type, and returns the total count.
-func (s *DatabaseService) GetArtifacts(roomIds []string, roomSid *string, artifactType *plugnmeet.RoomArtifactType, offset, limit uint64, direction *string) ([]*dbmodels.RoomArtifact, int64, error) {
+// optionally filtered by tenant, room IDs, roomSid and artifact type, and returns the total count.
+func (s *DatabaseService) GetArtifacts(tenantId string, roomIds []string, roomSid *string, artifactType *plugnmeet.RoomArtifactType, offset, limit uint64, direction *string) ([]*dbmodels.RoomArtifact, int64, error) {
 	var artifacts []*dbmodels.RoomArtifact
 	var total int64
 
 	tx := s.db.Model(&dbmodels.RoomArtifact{})
+	if tenantId != "" {
+		tx.Where("tenant_id = ?", tenantId)
+	}
 
 	if roomSid != nil {
 		// Use a subquery to avoid the N+1 problem
@@ -27,7 +30,7 @@ func (s *DatabaseService) GetArtifacts(roomIds []string, roomSid *string, artifa
 
 	if artifactType != nil {
 		// Convert the enum to its string name for the query
-		tx.Where("type = ?", artifactType.String())
+		tx.Where("type = ?", dbmodels.RoomArtifactType(*artifactType).String())
 	}
 
 	// Get the total count before applying limit and offset
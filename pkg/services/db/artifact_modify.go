@@ -46,7 +46,11 @@ func (s *DatabaseService) IsAllowToDeleteArtifact(artifactType plugnmeet.RoomArt
 	switch artifactType {
 	case plugnmeet.RoomArtifactType_MEETING_ANALYTICS,
 		plugnmeet.RoomArtifactType_MEETING_SUMMARY,
-		plugnmeet.RoomArtifactType_SPEECH_TRANSCRIPTION:
+		plugnmeet.RoomArtifactType_SPEECH_TRANSCRIPTION,
+		dbmodels.RoomArtifactTypeChatTranscript,
+		dbmodels.RoomArtifactTypePollResults,
+		dbmodels.RoomArtifactTypeLiveSummary,
+		dbmodels.RoomArtifactTypeTalkTimeStats:
 		return true
 	}
 
@@ -3,7 +3,6 @@ package dbservice
 import (
 	"context"
 
-	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
 	"github.com/sirupsen/logrus"
 	"go.uber.org/fx"
 	"gorm.io/gorm"
@@ -29,15 +28,3 @@ func New(args Args) *DatabaseService {
 
 	return s
 }
-
-// AutoMigrate should be run after initializing of dbservice to ensure DB is ready before using it
-// don't use OnStart hook for AutoMigrate
-func (s *DatabaseService) AutoMigrate() error {
-	log := s.logger.WithField("method", "AutoMigrate")
-	err := s.db.AutoMigrate(&dbmodels.RoomInfo{}, &dbmodels.Recording{}, &dbmodels.RoomArtifact{}, &dbmodels.Analytics{})
-	if err != nil {
-		log.WithError(err).Error("Failed to migrate database")
-		return err
-	}
-	return nil
-}
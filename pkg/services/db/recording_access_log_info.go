@@ -0,0 +1,49 @@
+package dbservice
+
+import (
+	"errors"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+// GetRecordingAccessLogs retrieves a paginated list of access logs,
+// optionally filtered by tenant, recording & viewer, and returns the total count.
+func (s *DatabaseService) GetRecordingAccessLogs(tenantId, recordId, viewerId string, offset, limit uint64, direction *string) ([]dbmodels.RecordingAccessLog, int64, error) {
+	var logs []dbmodels.RecordingAccessLog
+	var total int64
+
+	d := s.db.Model(&dbmodels.RecordingAccessLog{})
+	if tenantId != "" {
+		d.Where("tenant_id = ?", tenantId)
+	}
+	if recordId != "" {
+		d.Where("record_id = ?", recordId)
+	}
+	if viewerId != "" {
+		d.Where("viewer_id = ?", viewerId)
+	}
+
+	if err := d.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return logs, 0, nil
+	}
+
+	if limit == 0 {
+		limit = 20
+	}
+
+	orderBy := "DESC"
+	if direction != nil && *direction == "ASC" {
+		orderBy = "ASC"
+	}
+
+	result := d.Offset(int(offset)).Limit(int(limit)).Order("id " + orderBy).Find(&logs)
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, 0, result.Error
+	}
+
+	return logs, total, nil
+}
@@ -0,0 +1,27 @@
+package dbservice
+
+import (
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+)
+
+// InsertRecordingAccessLog will insert a new access log
+func (s *DatabaseService) InsertRecordingAccessLog(info *dbmodels.RecordingAccessLog) (int64, error) {
+	result := s.db.Create(info)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// DeleteRecordingAccessLogsBefore removes the access logs created before the time
+func (s *DatabaseService) DeleteRecordingAccessLogsBefore(before time.Time) (int64, error) {
+	result := s.db.Where("created < ?", before).Delete(&dbmodels.RecordingAccessLog{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
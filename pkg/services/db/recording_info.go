@@ -8,11 +8,16 @@ import (
 	"gorm.io/gorm"
 )
 
-func (s *DatabaseService) GetRecordings(roomIds []string, roomSid *string, offset, limit uint64, direction *string) ([]dbmodels.Recording, int64, error) {
+// GetRecordings returns a paginated list of recordings,
+// if tenantId is empty then recordings of all the tenants will be returned
+func (s *DatabaseService) GetRecordings(tenantId string, roomIds []string, roomSid *string, offset, limit uint64, direction *string) ([]dbmodels.Recording, int64, error) {
 	var recordings []dbmodels.Recording
 	var total int64
 
 	d := s.db.Model(&dbmodels.Recording{})
+	if tenantId != "" {
+		d.Where("tenant_id = ?", tenantId)
+	}
 
 	if roomSid != nil {
 		d.Where("room_sid = ?", *roomSid)
@@ -62,11 +67,28 @@ func (s *DatabaseService) GetRecording(recordId string) (*dbmodels.Recording, er
 	return info, nil
 }
 
-func (s *DatabaseService) GetRecordingsForBBB(recordIds, meetingIds []string, offset, limit uint64) ([]dbmodels.Recording, int64, error) {
+// GetRecordingsCreatedBefore returns recordings created before the given unix time in ascending order of id,
+// afterId can be used to fetch the next batch
+func (s *DatabaseService) GetRecordingsCreatedBefore(before int64, afterId uint64, limit int) ([]dbmodels.Recording, error) {
+	var recordings []dbmodels.Recording
+	err := s.db.Model(&dbmodels.Recording{}).
+		Where("creation_time <= ? AND id > ?", before, afterId).
+		Order("id ASC").Limit(limit).
+		Find(&recordings).Error
+	if err != nil {
+		return nil, err
+	}
+	return recordings, nil
+}
+
+func (s *DatabaseService) GetRecordingsForBBB(tenantId string, recordIds, meetingIds []string, offset, limit uint64) ([]dbmodels.Recording, int64, error) {
 	var recordings []dbmodels.Recording
 	var total int64
 
 	d := s.db.Model(&dbmodels.Recording{})
+	if tenantId != "" {
+		d.Where("tenant_id = ?", tenantId)
+	}
 
 	if len(recordIds) > 0 {
 		d.Where("record_id IN ?", recordIds)
@@ -0,0 +1,39 @@
+package dbservice
+
+import (
+	"errors"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+// GetRecordingRenditions returns all the renditions of the recording
+func (s *DatabaseService) GetRecordingRenditions(recordId string) ([]dbmodels.RecordingRendition, error) {
+	var renditions []dbmodels.RecordingRendition
+
+	result := s.db.Where("record_id = ?", recordId).Order("id ASC").Find(&renditions)
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, result.Error
+	}
+
+	return renditions, nil
+}
+
+// GetCompletedRecordingRenditions returns the completed renditions of the recordings by record id
+func (s *DatabaseService) GetCompletedRecordingRenditions(recordIds []string) (map[string][]dbmodels.RecordingRendition, error) {
+	var renditions []dbmodels.RecordingRendition
+	list := make(map[string][]dbmodels.RecordingRendition)
+	if len(recordIds) == 0 {
+		return list, nil
+	}
+
+	result := s.db.Where("record_id IN ? AND status = ?", recordIds, dbmodels.RecordingRenditionStatusCompleted).Order("id ASC").Find(&renditions)
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, result.Error
+	}
+
+	for _, r := range renditions {
+		list[r.RecordID] = append(list[r.RecordID], r)
+	}
+	return list, nil
+}
@@ -0,0 +1,26 @@
+package dbservice
+
+import (
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+)
+
+// InsertOrUpdateRecordingRendition will insert a new rendition
+// or update the existing one if the table ID was set
+func (s *DatabaseService) InsertOrUpdateRecordingRendition(info *dbmodels.RecordingRendition) (int64, error) {
+	result := s.db.Save(info)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// DeleteRecordingRenditions removes all the renditions of the recording
+func (s *DatabaseService) DeleteRecordingRenditions(recordId string) (int64, error) {
+	result := s.db.Where("record_id = ?", recordId).Delete(&dbmodels.RecordingRendition{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
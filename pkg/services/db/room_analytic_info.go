@@ -36,13 +36,3 @@ func (s *DatabaseService) GetAnalytics(roomIds []string, offset, limit uint64, d
 
 	return analytics, total, nil
 }
-
-// GetAllAnalyticsFiles retrieves all records from the pnm_room_analytics table for migration.
-func (s *DatabaseService) GetAllAnalyticsFiles() ([]*dbmodels.Analytics, error) {
-	var analytics []*dbmodels.Analytics
-	result := s.db.Find(&analytics)
-	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		return nil, result.Error
-	}
-	return analytics, nil
-}
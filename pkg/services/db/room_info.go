@@ -61,10 +61,32 @@ func (s *DatabaseService) GetRoomInfoByTableId(tableId uint64) (*dbmodels.RoomIn
 	return info, nil
 }
 
-func (s *DatabaseService) GetActiveRoomsInfo(ctx context.Context) ([]dbmodels.RoomInfo, error) {
+// GetLastRoomInfoByRoomId returns the latest record of the room, running or not.
+// It returns (nil, nil) if the record is not found.
+func (s *DatabaseService) GetLastRoomInfoByRoomId(roomId string) (*dbmodels.RoomInfo, error) {
+	info := new(dbmodels.RoomInfo)
+	cond := &dbmodels.RoomInfo{
+		RoomId: roomId,
+	}
+
+	result := s.db.Where(cond).Order("id DESC").Take(info)
+	switch {
+	case errors.Is(result.Error, gorm.ErrRecordNotFound):
+		return nil, nil
+	case result.Error != nil:
+		return nil, result.Error
+	}
+
+	return info, nil
+}
+
+// GetActiveRoomsInfo returns all the running rooms,
+// if tenantId is empty then rooms of all the tenants will be returned
+func (s *DatabaseService) GetActiveRoomsInfo(ctx context.Context, tenantId string) ([]dbmodels.RoomInfo, error) {
 	var rooms []dbmodels.RoomInfo
 	cond := &dbmodels.RoomInfo{
 		IsRunning: 1,
+		TenantId:  tenantId,
 	}
 
 	result := s.db.WithContext(ctx).Where(cond).Find(&rooms)
@@ -78,16 +100,18 @@ func (s *DatabaseService) GetActiveRoomsInfo(ctx context.Context) ([]dbmodels.Ro
 	return rooms, nil
 }
 
-func (s *DatabaseService) GetPastRooms(ctx context.Context, roomIds []string, offset, limit uint64, direction *string) ([]dbmodels.RoomInfo, int64, error) {
+func (s *DatabaseService) GetPastRooms(ctx context.Context, tenantId string, roomIds []string, offset, limit uint64, direction *string) ([]dbmodels.RoomInfo, int64, error) {
 	var roomsInfo []dbmodels.RoomInfo
 	var total int64
 	cond := &dbmodels.RoomInfo{
 		IsRunning: 0,
+		TenantId:  tenantId,
 	}
 
 	d := s.db.WithContext(ctx).Model(&dbmodels.RoomInfo{}).Where(cond)
 	if len(roomIds) > 0 {
-		d.Where("roomId IN ?", roomIds)
+		// column name is camel case, so let gorm quote it for us
+		d.Where(map[string]interface{}{"roomId": roomIds})
 	}
 
 	if err := d.Count(&total).Error; err != nil {
@@ -105,8 +105,16 @@ func (s *DatabaseService) IncrementOrDecrementNumParticipants(sId, operator stri
 	if operator != "+" && operator != "-" {
 		return 0, errors.New("invalid operator for IncrementOrDecrementNumParticipants")
 	}
+	// joined_participants is unsigned in mysql, so we'll need to cast before subtracting
+	expr := "GREATEST(CAST(joined_participants AS SIGNED)" + operator + " 1, 0)"
+	switch s.db.Dialector.Name() {
+	case "postgres":
+		expr = "GREATEST(joined_participants " + operator + " 1, 0)"
+	case "sqlite":
+		expr = "MAX(joined_participants " + operator + " 1, 0)"
+	}
 	update := map[string]interface{}{
-		"joined_participants": gorm.Expr("GREATEST(CAST(joined_participants AS SIGNED)" + operator + " 1, 0)"),
+		"joined_participants": gorm.Expr(expr),
 	}
 
 	result := s.db.Model(&dbmodels.RoomInfo{}).Where("sid = ?", sId).Updates(update)
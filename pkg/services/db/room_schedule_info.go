@@ -0,0 +1,85 @@
+package dbservice
+
+import (
+	"errors"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+// GetRoomSchedule retrieves a single schedule by its unique schedule_id.
+// It returns (nil, nil) if the record is not found.
+func (s *DatabaseService) GetRoomSchedule(scheduleId string) (*dbmodels.RoomSchedule, error) {
+	info := new(dbmodels.RoomSchedule)
+	cond := &dbmodels.RoomSchedule{
+		ScheduleId: scheduleId,
+	}
+
+	result := s.db.Where(cond).Take(info)
+	switch {
+	case errors.Is(result.Error, gorm.ErrRecordNotFound):
+		return nil, nil
+	case result.Error != nil:
+		return nil, result.Error
+	}
+
+	return info, nil
+}
+
+// GetRoomSchedules retrieves a paginated list of schedules,
+// optionally filtered by tenant, room IDs and status, and returns the total count.
+func (s *DatabaseService) GetRoomSchedules(tenantId string, roomIds []string, status *string, offset, limit uint64, direction *string) ([]dbmodels.RoomSchedule, int64, error) {
+	var schedules []dbmodels.RoomSchedule
+	var total int64
+
+	d := s.db.Model(&dbmodels.RoomSchedule{})
+	if tenantId != "" {
+		d.Where("tenant_id = ?", tenantId)
+	}
+	if len(roomIds) > 0 {
+		d.Where("room_id IN ?", roomIds)
+	}
+	if status != nil && *status != "" {
+		d.Where("status = ?", *status)
+	}
+
+	if err := d.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if total == 0 {
+		return schedules, 0, nil
+	}
+
+	if limit == 0 {
+		limit = 20
+	}
+
+	orderBy := "DESC"
+	if direction != nil && *direction == "ASC" {
+		orderBy = "ASC"
+	}
+
+	result := d.Offset(int(offset)).Limit(int(limit)).Order("next_start_at " + orderBy).Find(&schedules)
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, 0, result.Error
+	}
+
+	return schedules, total, nil
+}
+
+// GetDueRoomSchedules returns all active schedules whose next start time
+// is at or before the given unix timestamp.
+func (s *DatabaseService) GetDueRoomSchedules(before int64) ([]dbmodels.RoomSchedule, error) {
+	var schedules []dbmodels.RoomSchedule
+	cond := &dbmodels.RoomSchedule{
+		Status: dbmodels.RoomScheduleStatusScheduled,
+	}
+
+	result := s.db.Where(cond).Where("next_start_at <= ?", before).Order("next_start_at ASC").Find(&schedules)
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, result.Error
+	}
+
+	return schedules, nil
+}
@@ -0,0 +1,57 @@
+package dbservice
+
+import (
+	"errors"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+// InsertOrUpdateRoomSchedule will insert a new schedule
+// or update the existing one if the table ID was set
+func (s *DatabaseService) InsertOrUpdateRoomSchedule(info *dbmodels.RoomSchedule) (int64, error) {
+	result := s.db.Save(info)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// UpdateRoomScheduleRun stores the outcome of an automatic room creation
+// and moves the schedule to its next occurrence or new status.
+func (s *DatabaseService) UpdateRoomScheduleRun(id uint64, nextStartAt int64, status, roomSid string, createdAt int64) (int64, error) {
+	cond := &dbmodels.RoomSchedule{
+		ID: id,
+	}
+
+	update := map[string]interface{}{
+		"next_start_at":   nextStartAt,
+		"status":          status,
+		"last_room_sid":   roomSid,
+		"last_created_at": createdAt,
+	}
+
+	result := s.db.Model(&dbmodels.RoomSchedule{}).Where(cond).Updates(update)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (s *DatabaseService) DeleteRoomSchedule(scheduleId string) (int64, error) {
+	cond := &dbmodels.RoomSchedule{
+		ScheduleId: scheduleId,
+	}
+
+	result := s.db.Where(cond).Delete(&dbmodels.RoomSchedule{})
+	switch {
+	case errors.Is(result.Error, gorm.ErrRecordNotFound):
+		return 0, nil
+	case result.Error != nil:
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,74 @@
+package dbservice
+
+import (
+	"errors"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+// GetRoomTemplate retrieves a single template by its unique template_id.
+// It returns (nil, nil) if the record is not found.
+func (s *DatabaseService) GetRoomTemplate(templateId string) (*dbmodels.RoomTemplate, error) {
+	info := new(dbmodels.RoomTemplate)
+	cond := &dbmodels.RoomTemplate{
+		TemplateId: templateId,
+	}
+
+	result := s.db.Where(cond).Take(info)
+	switch {
+	case errors.Is(result.Error, gorm.ErrRecordNotFound):
+		return nil, nil
+	case result.Error != nil:
+		return nil, result.Error
+	}
+
+	return info, nil
+}
+
+// GetRoomTemplates retrieves a paginated list of templates and returns the total count.
+// For a tenant, own templates plus the shared one (without tenant) will be returned.
+func (s *DatabaseService) GetRoomTemplates(tenantId string, offset, limit uint64, direction *string) ([]dbmodels.RoomTemplate, int64, error) {
+	var templates []dbmodels.RoomTemplate
+	var total int64
+
+	d := s.db.Model(&dbmodels.RoomTemplate{})
+	if tenantId != "" {
+		d.Where("tenant_id IN ?", []string{"", tenantId})
+	}
+	if err := d.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if total == 0 {
+		return templates, 0, nil
+	}
+
+	if limit == 0 {
+		limit = 20
+	}
+
+	orderBy := "DESC"
+	if direction != nil && *direction == "ASC" {
+		orderBy = "ASC"
+	}
+
+	result := d.Offset(int(offset)).Limit(int(limit)).Order("id " + orderBy).Find(&templates)
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, 0, result.Error
+	}
+
+	return templates, total, nil
+}
+
+// GetRoomTemplatesWithRecordingRetention returns all the templates which override the recording retention
+func (s *DatabaseService) GetRoomTemplatesWithRecordingRetention() ([]dbmodels.RoomTemplate, error) {
+	var templates []dbmodels.RoomTemplate
+	err := s.db.Model(&dbmodels.RoomTemplate{}).
+		Where("recording_retention_published_days IS NOT NULL OR recording_retention_unpublished_days IS NOT NULL").
+		Find(&templates).Error
+	if err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
@@ -0,0 +1,119 @@
+package dbservice
+
+import (
+	"errors"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+// GetTenant retrieves a single tenant by its unique tenant_id.
+// It returns (nil, nil) if the record is not found.
+func (s *DatabaseService) GetTenant(tenantId string) (*dbmodels.Tenant, error) {
+	info := new(dbmodels.Tenant)
+	cond := &dbmodels.Tenant{
+		TenantId: tenantId,
+	}
+
+	result := s.db.Where(cond).Take(info)
+	switch {
+	case errors.Is(result.Error, gorm.ErrRecordNotFound):
+		return nil, nil
+	case result.Error != nil:
+		return nil, result.Error
+	}
+
+	return info, nil
+}
+
+// GetTenantByApiKey retrieves a single tenant by its api key.
+// It returns (nil, nil) if the record is not found.
+func (s *DatabaseService) GetTenantByApiKey(apiKey string) (*dbmodels.Tenant, error) {
+	info := new(dbmodels.Tenant)
+	cond := &dbmodels.Tenant{
+		ApiKey: apiKey,
+	}
+
+	result := s.db.Where(cond).Take(info)
+	switch {
+	case errors.Is(result.Error, gorm.ErrRecordNotFound):
+		return nil, nil
+	case result.Error != nil:
+		return nil, result.Error
+	}
+
+	return info, nil
+}
+
+// GetTenants retrieves a paginated list of tenants and returns the total count.
+func (s *DatabaseService) GetTenants(offset, limit uint64, direction *string) ([]dbmodels.Tenant, int64, error) {
+	var tenants []dbmodels.Tenant
+	var total int64
+
+	d := s.db.Model(&dbmodels.Tenant{})
+	if err := d.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if total == 0 {
+		return tenants, 0, nil
+	}
+
+	if limit == 0 {
+		limit = 20
+	}
+
+	orderBy := "DESC"
+	if direction != nil && *direction == "ASC" {
+		orderBy = "ASC"
+	}
+
+	result := d.Offset(int(offset)).Limit(int(limit)).Order("id " + orderBy).Find(&tenants)
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, 0, result.Error
+	}
+
+	return tenants, total, nil
+}
+
+// CountTenantRunningRooms returns the number of running rooms of the tenant, breakout rooms are excluded
+func (s *DatabaseService) CountTenantRunningRooms(tenantId string) (int64, error) {
+	var total int64
+	cond := map[string]any{
+		"tenant_id":        tenantId,
+		"is_running":       1,
+		"is_breakout_room": 0,
+	}
+
+	if err := s.db.Model(&dbmodels.RoomInfo{}).Where(cond).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// SumTenantJoinedParticipants returns the number of joined participants in all the running rooms of the tenant
+func (s *DatabaseService) SumTenantJoinedParticipants(tenantId string) (int64, error) {
+	var total int64
+	cond := &dbmodels.RoomInfo{
+		TenantId:  tenantId,
+		IsRunning: 1,
+	}
+
+	err := s.db.Model(&dbmodels.RoomInfo{}).Where(cond).Select("COALESCE(SUM(joined_participants), 0)").Scan(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// GetTenantsWithRecordingRetention returns all the tenants which override the recording retention
+func (s *DatabaseService) GetTenantsWithRecordingRetention() ([]dbmodels.Tenant, error) {
+	var tenants []dbmodels.Tenant
+	err := s.db.Model(&dbmodels.Tenant{}).
+		Where("recording_retention_published_days IS NOT NULL OR recording_retention_unpublished_days IS NOT NULL").
+		Find(&tenants).Error
+	if err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
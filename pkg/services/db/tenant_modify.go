@@ -0,0 +1,35 @@
+package dbservice
+
+import (
+	"errors"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+// InsertOrUpdateTenant will insert a new tenant
+// or update the existing one if the table ID was set
+func (s *DatabaseService) InsertOrUpdateTenant(info *dbmodels.Tenant) (int64, error) {
+	result := s.db.Save(info)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (s *DatabaseService) DeleteTenant(tenantId string) (int64, error) {
+	cond := &dbmodels.Tenant{
+		TenantId: tenantId,
+	}
+
+	result := s.db.Where(cond).Delete(&dbmodels.Tenant{})
+	switch {
+	case errors.Is(result.Error, gorm.ErrRecordNotFound):
+		return 0, nil
+	case result.Error != nil:
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,122 @@
+package dbservice
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+// GetWebhookDelivery retrieves a single delivery by its unique delivery_id.
+// It returns (nil, nil) if the record is not found.
+func (s *DatabaseService) GetWebhookDelivery(deliveryId string) (*dbmodels.WebhookDelivery, error) {
+	info := new(dbmodels.WebhookDelivery)
+	cond := &dbmodels.WebhookDelivery{
+		DeliveryId: deliveryId,
+	}
+
+	result := s.db.Where(cond).Take(info)
+	switch {
+	case errors.Is(result.Error, gorm.ErrRecordNotFound):
+		return nil, nil
+	case result.Error != nil:
+		return nil, result.Error
+	}
+
+	return info, nil
+}
+
+// GetWebhookDeliveries retrieves a paginated list of deliveries,
+// optionally filtered by tenant, room, event & status, and returns the total count.
+func (s *DatabaseService) GetWebhookDeliveries(tenantId, roomId, event, status string, offset, limit uint64, direction *string) ([]dbmodels.WebhookDelivery, int64, error) {
+	var deliveries []dbmodels.WebhookDelivery
+	var total int64
+
+	d := s.db.Model(&dbmodels.WebhookDelivery{})
+	if tenantId != "" {
+		d.Where("tenant_id = ?", tenantId)
+	}
+	if roomId != "" {
+		d.Where("room_id = ?", roomId)
+	}
+	if event != "" {
+		d.Where("event = ?", event)
+	}
+	if status != "" {
+		d.Where("status = ?", status)
+	}
+
+	if err := d.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return deliveries, 0, nil
+	}
+
+	if limit == 0 {
+		limit = 20
+	}
+
+	orderBy := "DESC"
+	if direction != nil && *direction == "ASC" {
+		orderBy = "ASC"
+	}
+
+	result := d.Offset(int(offset)).Limit(int(limit)).Order("id " + orderBy).Find(&deliveries)
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, 0, result.Error
+	}
+
+	return deliveries, total, nil
+}
+
+// GetPreviousQueuedWebhookDelivery returns the oldest pending or retrying delivery of the room & url
+// created before the table id. It returns (nil, nil) if there is none.
+func (s *DatabaseService) GetPreviousQueuedWebhookDelivery(roomId, url string, id uint64) (*dbmodels.WebhookDelivery, error) {
+	return s.getQueuedWebhookDelivery(roomId, url, "id < ?", id)
+}
+
+// GetNextQueuedWebhookDelivery returns the oldest pending or retrying delivery of the room & url
+// created after the table id. It returns (nil, nil) if there is none.
+func (s *DatabaseService) GetNextQueuedWebhookDelivery(roomId, url string, id uint64) (*dbmodels.WebhookDelivery, error) {
+	return s.getQueuedWebhookDelivery(roomId, url, "id > ?", id)
+}
+
+func (s *DatabaseService) getQueuedWebhookDelivery(roomId, url, idCond string, id uint64) (*dbmodels.WebhookDelivery, error) {
+	info := new(dbmodels.WebhookDelivery)
+	result := s.db.Where("room_id = ? AND url = ?", roomId, url).
+		Where("status IN ?", []string{dbmodels.WebhookDeliveryStatusPending, dbmodels.WebhookDeliveryStatusRetrying}).
+		Where(idCond, id).
+		Order("id ASC").
+		Take(info)
+	switch {
+	case errors.Is(result.Error, gorm.ErrRecordNotFound):
+		return nil, nil
+	case result.Error != nil:
+		return nil, result.Error
+	}
+
+	return info, nil
+}
+
+// GetStaleWebhookDeliveries returns the pending deliveries not modified since pendingBefore & the retrying ones
+// not modified since retryingBefore, skipping those waiting for an older delivery of the same room & url.
+func (s *DatabaseService) GetStaleWebhookDeliveries(pendingBefore, retryingBefore time.Time, limit int) ([]dbmodels.WebhookDelivery, error) {
+	var deliveries []dbmodels.WebhookDelivery
+	table := (&dbmodels.WebhookDelivery{}).TableName()
+	queued := []string{dbmodels.WebhookDeliveryStatusPending, dbmodels.WebhookDeliveryStatusRetrying}
+
+	result := s.db.Table(table+" AS d").
+		Where("(d.status = ? AND d.modified < ?) OR (d.status = ? AND d.modified < ?)",
+			dbmodels.WebhookDeliveryStatusPending, pendingBefore, dbmodels.WebhookDeliveryStatusRetrying, retryingBefore).
+		Where("NOT EXISTS (SELECT 1 FROM "+table+" AS p WHERE p.room_id = d.room_id AND p.url = d.url AND p.id < d.id AND p.status IN ?)", queued).
+		Order("d.id ASC").
+		Limit(limit).
+		Find(&deliveries)
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, result.Error
+	}
+
+	return deliveries, nil
+}
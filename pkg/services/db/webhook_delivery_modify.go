@@ -0,0 +1,58 @@
+package dbservice
+
+import (
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+)
+
+// InsertOrUpdateWebhookDelivery will insert a new delivery
+// or update the existing one if the table ID was set
+func (s *DatabaseService) InsertOrUpdateWebhookDelivery(info *dbmodels.WebhookDelivery) (int64, error) {
+	result := s.db.Save(info)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// InsertWebhookDeliveries will insert multiple new deliveries at once
+func (s *DatabaseService) InsertWebhookDeliveries(list []*dbmodels.WebhookDelivery) (int64, error) {
+	if len(list) == 0 {
+		return 0, nil
+	}
+	result := s.db.CreateInBatches(list, 100)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// DeleteWebhookDeliveriesBefore removes the delivery logs created before the time
+func (s *DatabaseService) DeleteWebhookDeliveriesBefore(before time.Time) (int64, error) {
+	result := s.db.Where("created < ?", before).Delete(&dbmodels.WebhookDelivery{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// MarkQueuedWebhookDeliveriesDead moves the pending & retrying deliveries of the room & url
+// created after the table id to the dead-letter
+func (s *DatabaseService) MarkQueuedWebhookDeliveriesDead(roomId, url string, id uint64, lastError string) (int64, error) {
+	result := s.db.Model(&dbmodels.WebhookDelivery{}).
+		Where("room_id = ? AND url = ? AND id > ?", roomId, url, id).
+		Where("status IN ?", []string{dbmodels.WebhookDeliveryStatusPending, dbmodels.WebhookDeliveryStatusRetrying}).
+		Updates(map[string]interface{}{
+			"status":     dbmodels.WebhookDeliveryStatusDead,
+			"last_error": lastError,
+		})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
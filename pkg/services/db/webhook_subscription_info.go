@@ -0,0 +1,74 @@
+package dbservice
+
+import (
+	"errors"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/dbmodels"
+	"gorm.io/gorm"
+)
+
+// GetWebhookSubscription retrieves a single subscription by its unique subscription_id.
+// It returns (nil, nil) if the record is not found.
+func (s *DatabaseService) GetWebhookSubscription(subscriptionId string) (*dbmodels.WebhookSubscription, error) {
+	info := new(dbmodels.WebhookSubscription)
+	cond := &dbmodels.WebhookSubscription{
+		SubscriptionId: subscriptionId,
+	}
+
+	result := s.db.Where(cond).Take(info)
+	switch {
+	case errors.Is(result.Error, gorm.ErrRecordNotFound):
+		return nil, nil
+	case result.Error != nil:
+		return nil, result.Error
+	}
+
+	return info, nil
+}
+
+// GetWebhookSubscriptions retrieves a paginated list of subscriptions of the tenant and returns the total count.
+func (s *DatabaseService) GetWebhookSubscriptions(tenantId string, offset, limit uint64, direction *string) ([]dbmodels.WebhookSubscription, int64, error) {
+	var subscriptions []dbmodels.WebhookSubscription
+	var total int64
+
+	d := s.db.Model(&dbmodels.WebhookSubscription{})
+	if tenantId != "" {
+		d.Where("tenant_id = ?", tenantId)
+	}
+	if err := d.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if total == 0 {
+		return subscriptions, 0, nil
+	}
+
+	if limit == 0 {
+		limit = 20
+	}
+
+	orderBy := "DESC"
+	if direction != nil && *direction == "ASC" {
+		orderBy = "ASC"
+	}
+
+	result := d.Offset(int(offset)).Limit(int(limit)).Order("id " + orderBy).Find(&subscriptions)
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, 0, result.Error
+	}
+
+	return subscriptions, total, nil
+}
+
+// GetActiveWebhookSubscriptions returns the active subscriptions which may receive events of the tenant's rooms.
+// Subscriptions of the root api key (without tenant) will receive events of all tenants.
+func (s *DatabaseService) GetActiveWebhookSubscriptions(tenantId string) ([]dbmodels.WebhookSubscription, error) {
+	var subscriptions []dbmodels.WebhookSubscription
+
+	result := s.db.Where("is_active = ? AND tenant_id IN ?", 1, []string{"", tenantId}).Order("id ASC").Find(&subscriptions)
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, result.Error
+	}
+
+	return subscriptions, nil
+}
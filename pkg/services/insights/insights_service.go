@@ -8,6 +8,7 @@ import (
 	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
 	"github.com/mynaparrot/plugnmeet-server/pkg/insights/providers/azure"
 	"github.com/mynaparrot/plugnmeet-server/pkg/insights/providers/google"
+	"github.com/mynaparrot/plugnmeet-server/pkg/insights/providers/local"
 	"github.com/mynaparrot/plugnmeet-server/pkg/insights/providers/openai"
 	natsservice "github.com/mynaparrot/plugnmeet-server/pkg/services/nats"
 	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
@@ -38,6 +39,8 @@ func NewProvider(args *ProviderArgs) (insights.Provider, error) {
 		return google.NewProvider(args.Ctx, args.ProviderAccount, args.ServiceConfig, log)
 	case config.ProviderOpenAI:
 		return openai.NewProvider(args.Ctx, args.ProviderAccount, args.ServiceConfig, log, args.RDS)
+	case config.ProviderLocal:
+		return local.NewProvider(args.Ctx, args.ProviderAccount, args.ServiceConfig, log)
 	default:
 		return nil, fmt.Errorf("unknown AI provider type: %s", args.ProviderType)
 	}
@@ -62,7 +65,7 @@ func NewTask(args *TaskArgs) (insights.Task, error) {
 	case insights.ServiceTypeTranscription:
 		return NewTranscriptionTask(args.AppConf, args.NatsConn, args.ServiceConfig, args.ProviderAccount, args.NatsService, args.RedisService, args.Logger)
 	case insights.ServiceTypeTranslation:
-		return NewTranslationTask(args.ServiceConfig, args.ProviderAccount, args.RedisService, args.Logger)
+		return NewTranslationTask(args.AppConf, args.ServiceConfig, args.ProviderAccount, args.RedisService, args.Logger)
 	case insights.ServiceTypeMeetingSummarizing:
 		return NewMeetingSummarizingTask(args.Ctx, args.AppConf, args.JS, args.ServiceConfig, args.Logger)
 	default:
@@ -0,0 +1,119 @@
+package insightsservice
+
+import (
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
+	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+	"github.com/sirupsen/logrus"
+)
+
+// providerSelector orders the accounts of a service by the strategy & the shared health,
+// and keeps the health up to date with the result of each attempt.
+type providerSelector struct {
+	conf         *config.InsightsConfig
+	serviceType  insights.ServiceType
+	redisService *redisservice.RedisService
+	logger       *logrus.Entry
+	// used if the service can't be found in the config anymore
+	fallback *config.ServiceCandidate
+}
+
+func newProviderSelector(conf *config.InsightsConfig, serviceType insights.ServiceType, service *config.ServiceConfig, account *config.ProviderAccount, redisService *redisservice.RedisService, logger *logrus.Entry) *providerSelector {
+	return &providerSelector{
+		conf:         conf,
+		serviceType:  serviceType,
+		redisService: redisService,
+		logger:       logger.WithField("serviceType", serviceType),
+		fallback:     &config.ServiceCandidate{Account: account, Service: service},
+	}
+}
+
+// candidates returns the accounts to try in order. Unhealthy accounts are moved to the end,
+// so those will be used only when all the others have failed.
+func (s *providerSelector) candidates() []*config.ServiceCandidate {
+	var all []*config.ServiceCandidate
+	if s.conf != nil {
+		var err error
+		if all, err = s.conf.GetServiceCandidates(s.serviceType); err != nil {
+			s.logger.WithError(err).Warnln("failed to get accounts of the service")
+		}
+	}
+	if len(all) == 0 {
+		return []*config.ServiceCandidate{s.fallback}
+	}
+	if len(all) == 1 {
+		return all
+	}
+
+	if all[0].Service.Strategy == config.ServiceStrategyRoundRobin {
+		if n, err := s.redisService.NextInsightsRoundRobin(string(s.serviceType)); err == nil {
+			all = rotateCandidates(all, n)
+		} else {
+			s.logger.WithError(err).Warnln("failed to get round robin counter")
+		}
+	}
+
+	refs := make([]redisservice.InsightsAccountRef, len(all))
+	for i, c := range all {
+		refs[i] = redisservice.InsightsAccountRef{Provider: string(c.Service.Provider), AccountId: c.Account.ID}
+	}
+	health, err := s.redisService.GetInsightsAccountsHealth(refs)
+	if err != nil {
+		// we'll try the accounts in order
+		s.logger.WithError(err).Warnln("failed to get health of the accounts")
+		return all
+	}
+	return orderCandidatesByHealth(all, health)
+}
+
+// rotateCandidates starts the list from the n-th account
+func rotateCandidates(all []*config.ServiceCandidate, n int64) []*config.ServiceCandidate {
+	offset := int(n % int64(len(all)))
+	if offset < 0 {
+		offset += len(all)
+	}
+	rotated := make([]*config.ServiceCandidate, 0, len(all))
+	return append(append(rotated, all[offset:]...), all[:offset]...)
+}
+
+// orderCandidatesByHealth moves the accounts with open circuit to the end, keeping the order otherwise.
+// health must have the same order as the candidates.
+func orderCandidatesByHealth(all []*config.ServiceCandidate, health []*redisservice.InsightsAccountHealth) []*config.ServiceCandidate {
+	healthy := make([]*config.ServiceCandidate, 0, len(all))
+	var unhealthy []*config.ServiceCandidate
+	for i, c := range all {
+		if i < len(health) && health[i].IsOpen() {
+			unhealthy = append(unhealthy, c)
+			continue
+		}
+		healthy = append(healthy, c)
+	}
+	return append(healthy, unhealthy...)
+}
+
+func (s *providerSelector) reportFailure(c *config.ServiceCandidate, err error) {
+	log := s.logger.WithFields(logrus.Fields{
+		"provider":  c.Service.Provider,
+		"accountId": c.Account.ID,
+	})
+	log.WithError(err).Warnln("insights provider account failed")
+
+	var failover *config.InsightsFailover
+	if s.conf != nil {
+		failover = s.conf.Failover
+	}
+	opened, rErr := s.redisService.RecordInsightsAccountFailure(string(c.Service.Provider), c.Account.ID, err.Error(), failover.GetFailureThreshold(), failover.GetOpenDuration())
+	if rErr != nil {
+		log.WithError(rErr).Errorln("failed to record insights account failure")
+		return
+	}
+	if opened {
+		log.Warnf("insights provider account marked as unhealthy for %s", failover.GetOpenDuration())
+	}
+}
+
+func (s *providerSelector) reportSuccess(c *config.ServiceCandidate) {
+	if err := s.redisService.RecordInsightsAccountSuccess(string(c.Service.Provider), c.Account.ID); err != nil {
+		s.logger.WithError(err).Errorln("failed to record insights account success")
+	}
+}
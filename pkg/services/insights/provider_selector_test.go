@@ -0,0 +1,89 @@
+package insightsservice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+)
+
+func testCandidates(ids ...string) []*config.ServiceCandidate {
+	list := make([]*config.ServiceCandidate, len(ids))
+	for i, id := range ids {
+		list[i] = &config.ServiceCandidate{
+			Account: &config.ProviderAccount{ID: id},
+			Service: &config.ServiceConfig{Provider: config.ProviderAzure, ID: id},
+		}
+	}
+	return list
+}
+
+func candidateIds(list []*config.ServiceCandidate) []string {
+	ids := make([]string, len(list))
+	for i, c := range list {
+		ids[i] = c.Account.ID
+	}
+	return ids
+}
+
+func equalIds(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRotateCandidates(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want []string
+	}{
+		{0, []string{"a", "b", "c"}},
+		{1, []string{"b", "c", "a"}},
+		{2, []string{"c", "a", "b"}},
+		{3, []string{"a", "b", "c"}},
+		{-1, []string{"c", "a", "b"}},
+	}
+	for _, tt := range tests {
+		all := testCandidates("a", "b", "c")
+		got := candidateIds(rotateCandidates(all, tt.n))
+		if !equalIds(got, tt.want) {
+			t.Errorf("rotateCandidates(%d) = %v, want %v", tt.n, got, tt.want)
+		}
+		if !equalIds(candidateIds(all), []string{"a", "b", "c"}) {
+			t.Errorf("rotateCandidates(%d) must not change the list of the config", tt.n)
+		}
+	}
+}
+
+func TestOrderCandidatesByHealth(t *testing.T) {
+	open := &redisservice.InsightsAccountHealth{OpenUntil: time.Now().Add(time.Minute).UnixMilli()}
+	expired := &redisservice.InsightsAccountHealth{ConsecutiveFailures: 2, OpenUntil: time.Now().Add(-time.Minute).UnixMilli()}
+	healthy := &redisservice.InsightsAccountHealth{}
+
+	tests := []struct {
+		name   string
+		health []*redisservice.InsightsAccountHealth
+		want   []string
+	}{
+		{"all healthy", []*redisservice.InsightsAccountHealth{healthy, healthy, healthy}, []string{"a", "b", "c"}},
+		{"open circuit moves to the end", []*redisservice.InsightsAccountHealth{open, healthy, healthy}, []string{"b", "c", "a"}},
+		{"expired circuit gets another chance", []*redisservice.InsightsAccountHealth{expired, healthy, open}, []string{"a", "b", "c"}},
+		{"all open keep the order", []*redisservice.InsightsAccountHealth{open, open, open}, []string{"a", "b", "c"}},
+		{"missing health counts as healthy", nil, []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := candidateIds(orderCandidatesByHealth(testCandidates("a", "b", "c"), tt.health))
+			if !equalIds(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -42,14 +42,96 @@ func NewTranscriptionTask(appConf *config.AppConfig, natsConn *nats.Conn, servic
 	}, nil
 }
 
+// transcriptionRun keeps the state of one RunAudioStream across the accounts it fails over to
+type transcriptionRun struct {
+	roomId           string
+	userId           string
+	synthesisChannel string
+	// a stream was created with any of the accounts
+	opened    bool
+	started   bool
+	lastError string
+	// start of the utterance in progress in unix ms, set by the first partial result
+	utteranceStart int64
+	interrupted    bool
+}
+
 // RunAudioStream implements the insights.Task interface.
+// If the stream of an account fails, the audio will continue with the next account of the service.
 func (t *TranscriptionTask) RunAudioStream(ctx context.Context, audioStream <-chan media.PCM16Sample, roomTableId uint64, roomId, userId string, options []byte) error {
+	log := t.logger.WithFields(logrus.Fields{
+		"method":      "RunAudioStream",
+		"roomId":      roomId,
+		"roomTableId": roomTableId,
+		"userId":      userId,
+	})
+
+	run := &transcriptionRun{
+		roomId:           roomId,
+		userId:           userId,
+		synthesisChannel: fmt.Sprintf(insights.SynthesisNatsChannel, roomId),
+	}
+	defer func() {
+		if !run.opened {
+			return
+		}
+		if _, err := t.redisService.HandleTranscriptionUsage(roomId, userId, false); err != nil {
+			log.WithError(err).Errorln("update user usage failed")
+		}
+
+		if err := t.natsService.BroadcastSystemNotificationToRoom(roomId, "speech-services.service-stopped", plugnmeet.NatsSystemNotificationTypes_NATS_SYSTEM_NOTIFICATION_INFO, false, &userId); err != nil {
+			log.WithError(err).Errorln("error broadcasting system notification")
+		}
+	}()
+
+	options = t.applyDiarization(userId, options, log)
+	diarize := isDiarizationEnabled(options)
+
+	selector := newProviderSelector(t.appConf.Insights, insights.ServiceTypeTranscription, t.service, t.account, t.redisService, t.logger)
+	candidates := selector.candidates()
+
+	var lastErr error
+	for i, c := range candidates {
+		cLog := log.WithFields(logrus.Fields{
+			"provider":  c.Service.Provider,
+			"accountId": c.Account.ID,
+		})
+		if diarize && c.Service.Provider != config.ProviderAzure {
+			cLog.Warnln("the provider can't separate the speakers, continuing without diarization")
+		}
+		err := t.runStream(ctx, c, audioStream, options, run, cLog)
+		if err == nil {
+			if run.started {
+				selector.reportSuccess(c)
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		selector.reportFailure(c, err)
+		lastErr = err
+		if i < len(candidates)-1 {
+			cLog.WithError(err).Warnln("transcription failed, failing over to the next account")
+		}
+	}
+
+	if lastErr != nil && !run.started {
+		return lastErr
+	}
+	return nil
+}
+
+// runStream pipes the audio to a stream of the account until the audio ends.
+// It returns an error if the stream of the account has failed.
+func (t *TranscriptionTask) runStream(ctx context.Context, c *config.ServiceCandidate, audioStream <-chan media.PCM16Sample, options []byte, run *transcriptionRun, log *logrus.Entry) error {
 	// Use the factory to create a provider instance.
 	args := &ProviderArgs{
 		Ctx:             ctx,
-		ProviderType:    t.service.Provider,
-		ProviderAccount: t.account,
-		ServiceConfig:   t.service,
+		ProviderType:    c.Service.Provider,
+		ProviderAccount: c.Account,
+		ServiceConfig:   c.Service,
 		RDS:             t.redisService.GetRedisClient(),
 		Logger:          t.logger,
 	}
@@ -57,17 +139,12 @@ func (t *TranscriptionTask) RunAudioStream(ctx context.Context, audioStream <-ch
 	if err != nil {
 		return err
 	}
-	log := t.logger.WithFields(logrus.Fields{
-		"method":      "RunAudioStream",
-		"roomId":      roomId,
-		"roomTableId": roomTableId,
-		"userId":      userId,
-	})
 
-	stream, err := provider.CreateTranscription(ctx, roomId, userId, options)
+	stream, err := provider.CreateTranscription(ctx, run.roomId, run.userId, options)
 	if err != nil {
 		return err
 	}
+	run.opened = true
 
 	// Derive a task-local context so either goroutine can signal the other to
 	// stop without affecting the caller's context.
@@ -76,6 +153,10 @@ func (t *TranscriptionTask) RunAudioStream(ctx context.Context, audioStream <-ch
 	var wg sync.WaitGroup
 	wg.Add(2)
 
+	// set by the audio goroutine, read after wg.Wait()
+	var audioEnded bool
+	var writeErr error
+
 	// Goroutine to pipe audio to the provider.
 	go func() {
 		defer wg.Done()
@@ -93,6 +174,7 @@ func (t *TranscriptionTask) RunAudioStream(ctx context.Context, audioStream <-ch
 			case pcmSample, ok := <-audioStream:
 				if !ok {
 					log.Infoln("audio stream closed")
+					audioEnded = true
 					return
 				}
 				if err := stream.WriteSample(pcmSample); err != nil {
@@ -100,6 +182,7 @@ func (t *TranscriptionTask) RunAudioStream(ctx context.Context, audioStream <-ch
 					// A nil ctx error here means the stream is done for good.
 					if errors.Is(err, context.Canceled) || consecutiveErrors >= maxConsecutiveErrors {
 						log.WithError(err).Errorf("stopping audio pipe after %d consecutive write failures", consecutiveErrors)
+						writeErr = err
 						return
 					}
 					log.WithError(err).Warnf("transient audio write failure (%d/%d)", consecutiveErrors, maxConsecutiveErrors)
@@ -114,17 +197,6 @@ func (t *TranscriptionTask) RunAudioStream(ctx context.Context, audioStream <-ch
 	go func() {
 		defer wg.Done()
 		defer cancel() // notify the audio goroutine that results are done
-		defer func() {
-			if _, err := t.redisService.HandleTranscriptionUsage(roomId, userId, false); err != nil {
-				log.WithError(err).Errorln("update user usage failed")
-			}
-
-			if err := t.natsService.BroadcastSystemNotificationToRoom(roomId, "speech-services.service-stopped", plugnmeet.NatsSystemNotificationTypes_NATS_SYSTEM_NOTIFICATION_INFO, false, &userId); err != nil {
-				log.WithError(err).Errorln("error broadcasting system notification")
-			}
-		}()
-
-		synthesisChannel := fmt.Sprintf(insights.SynthesisNatsChannel, roomId)
 
 		// The loop breaks when the stream is closed or the task context is cancelled.
 		resultsCh := stream.Results()
@@ -134,14 +206,14 @@ func (t *TranscriptionTask) RunAudioStream(ctx context.Context, audioStream <-ch
 				// Drain any buffered results that the provider emitted before
 				// Close() takes effect; exit when the channel closes.
 				for event := range resultsCh {
-					t.handleTranscriptionEvent(event, roomId, userId, synthesisChannel, log)
+					t.handleTranscriptionEvent(event, run, log)
 				}
 				return
 			case event, ok := <-resultsCh:
 				if !ok {
 					return
 				}
-				t.handleTranscriptionEvent(event, roomId, userId, synthesisChannel, log)
+				t.handleTranscriptionEvent(event, run, log)
 			}
 		}
 	}()
@@ -149,13 +221,33 @@ func (t *TranscriptionTask) RunAudioStream(ctx context.Context, audioStream <-ch
 	// Block until both goroutines finish so the caller (e.g. room_agent) can
 	// properly wait for the full task lifecycle via its own WaitGroup.
 	wg.Wait()
-	return nil
+
+	switch {
+	case writeErr != nil:
+		return writeErr
+	case audioEnded || ctx.Err() != nil:
+		return nil
+	case run.lastError != "":
+		// the provider has closed the stream while the audio is still coming
+		return errors.New(run.lastError)
+	default:
+		return errors.New("transcription stream closed unexpectedly")
+	}
 }
 
 // handleTranscriptionEvent processes a single transcription event.
-func (t *TranscriptionTask) handleTranscriptionEvent(event *insights.TranscriptionEvent, roomId, userId, synthesisChannel string, log *logrus.Entry) {
+func (t *TranscriptionTask) handleTranscriptionEvent(event *insights.TranscriptionEvent, run *transcriptionRun, log *logrus.Entry) {
+	roomId, userId := run.roomId, run.userId
 	switch event.Type {
 	case insights.EventTypePartialResult, insights.EventTypeFinalResult:
+		if event.Result.AllowedTranscriptionStorage {
+			// the talk stats are exported as artifact, so they need the same consent as the transcript
+			t.recordTalkStats(event, run, log)
+		}
+		if event.SpeakerLabel != "" {
+			// the clients & the history will show the speaker of the diarized track
+			event.Result.FromUserName = fmt.Sprintf("%s (%s)", event.Result.FromUserName, event.SpeakerLabel)
+		}
 		marshal, err := protojson.Marshal(event.Result)
 		if err != nil {
 			log.WithError(err).Error("failed to marshal transcription result")
@@ -168,7 +260,7 @@ func (t *TranscriptionTask) handleTranscriptionEvent(event *insights.Transcripti
 
 		// If we have a final result, publish it to the dedicated synthesis SynthesisNatsChannel.
 		if event.Type == insights.EventTypeFinalResult {
-			if err = t.natsConn.Publish(synthesisChannel, marshal); err != nil {
+			if err = t.natsConn.Publish(run.synthesisChannel, marshal); err != nil {
 				log.WithError(err).Errorln("error publishing to synthesis SynthesisNatsChannel")
 			}
 			if event.Result.AllowedTranscriptionStorage {
@@ -179,6 +271,13 @@ func (t *TranscriptionTask) handleTranscriptionEvent(event *insights.Transcripti
 		}
 
 	case insights.EventTypeSessionStarted:
+		run.lastError = ""
+		if run.started {
+			// failed over to another account, the session continues for the user
+			log.Infoln("transcription session continued with another account")
+			return
+		}
+		run.started = true
 		if _, err := t.redisService.HandleTranscriptionUsage(roomId, userId, true); err != nil {
 			log.WithError(err).Errorln("update user usage failed")
 		}
@@ -192,6 +291,7 @@ func (t *TranscriptionTask) handleTranscriptionEvent(event *insights.Transcripti
 	case insights.EventTypeSessionStopped:
 		log.Infoln("transcription session stopped")
 	case insights.EventTypeError:
+		run.lastError = event.Error
 		log.Errorln("insights provider error: ", event.Error)
 	}
 }
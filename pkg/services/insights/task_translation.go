@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/livekit/media-sdk"
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
 	"github.com/mynaparrot/plugnmeet-server/pkg/config"
 	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
 	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
@@ -16,14 +17,16 @@ import (
 
 // TranslationTask implements the insights.Task interface for stateless text translation.
 type TranslationTask struct {
+	appConf      *config.AppConfig
 	service      *config.ServiceConfig
 	account      *config.ProviderAccount
 	redisService *redisservice.RedisService
 	logger       *logrus.Entry
 }
 
-func NewTranslationTask(serviceConfig *config.ServiceConfig, providerAccount *config.ProviderAccount, redisService *redisservice.RedisService, logger *logrus.Entry) (insights.Task, error) {
+func NewTranslationTask(appConf *config.AppConfig, serviceConfig *config.ServiceConfig, providerAccount *config.ProviderAccount, redisService *redisservice.RedisService, logger *logrus.Entry) (insights.Task, error) {
 	return &TranslationTask{
+		appConf:      appConf,
 		service:      serviceConfig,
 		account:      providerAccount,
 		redisService: redisService,
@@ -42,12 +45,35 @@ func (t *TranslationTask) RunStateless(ctx context.Context, options []byte) (int
 		return nil, errors.New("text and at least one target_lang are required for translation")
 	}
 
+	selector := newProviderSelector(t.appConf.Insights, insights.ServiceTypeTranslation, t.service, t.account, t.redisService, t.logger)
+
+	// try the accounts in order until one succeeds
+	var lastErr error
+	for _, c := range selector.candidates() {
+		result, err := t.translate(ctx, c, &opts)
+		if err != nil {
+			if ctx.Err() != nil {
+				// cancelled by the caller, not a failure of the account
+				return nil, err
+			}
+			selector.reportFailure(c, err)
+			lastErr = err
+			continue
+		}
+		selector.reportSuccess(c)
+		return result, nil
+	}
+
+	return nil, lastErr
+}
+
+func (t *TranslationTask) translate(ctx context.Context, c *config.ServiceCandidate, opts *insights.TranslationTaskOptions) (*plugnmeet.InsightsTextTranslationResult, error) {
 	// Use the factory to create a provider instance.
 	args := &ProviderArgs{
 		Ctx:             ctx,
-		ProviderType:    t.service.Provider,
-		ProviderAccount: t.account,
-		ServiceConfig:   t.service,
+		ProviderType:    c.Service.Provider,
+		ProviderAccount: c.Account,
+		ServiceConfig:   c.Service,
 		RDS:             t.redisService.GetRedisClient(),
 		Logger:          t.logger,
 	}
@@ -60,12 +86,7 @@ func (t *TranslationTask) RunStateless(ctx context.Context, options []byte) (int
 	defer cancel()
 
 	// Call the provider's synchronous TranslateText method
-	result, err := provider.TranslateText(opCtx, opts.Text, opts.SourceLang, opts.TargetLangs)
-	if err != nil {
-		return nil, err
-	}
-
-	return result, nil
+	return provider.TranslateText(opCtx, opts.Text, opts.SourceLang, opts.TargetLangs)
 }
 
 // RunAudioStream is not implemented for TranslationTask as it's a stateless service.
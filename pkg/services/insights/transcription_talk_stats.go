@@ -0,0 +1,114 @@
+package insightsservice
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/insights"
+	redisservice "github.com/mynaparrot/plugnmeet-server/pkg/services/redis"
+	"github.com/sirupsen/logrus"
+)
+
+// estimatedMsPerWord is used for the talk-time if the provider
+// neither sends partial results nor the duration of the speech
+const estimatedMsPerWord = 400
+
+// applyDiarization enables the diarization for the SIP dial-in tracks,
+// as several people may share the same phone line.
+func (t *TranscriptionTask) applyDiarization(userId string, options []byte, log *logrus.Entry) []byte {
+	if !strings.HasPrefix(userId, config.SipUserIdPrefix) || !t.service.GetBoolOption("diarize_sip_users", false) {
+		return options
+	}
+
+	opts := new(insights.TranscriptionOptions)
+	if len(options) > 0 {
+		if err := json.Unmarshal(options, opts); err != nil {
+			log.WithError(err).Warnln("failed to unmarshal transcription options, continuing without diarization")
+			return options
+		}
+	}
+	if opts.Diarization {
+		return options
+	}
+	opts.Diarization = true
+
+	marshal, err := json.Marshal(opts)
+	if err != nil {
+		log.WithError(err).Warnln("failed to marshal transcription options, continuing without diarization")
+		return options
+	}
+	return marshal
+}
+
+// isDiarizationEnabled checks if the options ask to separate the speakers,
+// only Azure supports it for now.
+func isDiarizationEnabled(options []byte) bool {
+	if len(options) == 0 {
+		return false
+	}
+	opts := new(insights.TranscriptionOptions)
+	if err := json.Unmarshal(options, opts); err != nil {
+		return false
+	}
+	return opts.Diarization
+}
+
+// recordTalkStats keeps the talk-time, words & interruptions of the speaker.
+// Interruptions are detected between the tracks, so those between the speakers
+// of the same diarized track aren't counted.
+func (t *TranscriptionTask) recordTalkStats(event *insights.TranscriptionEvent, run *transcriptionRun, log *logrus.Entry) {
+	now := time.Now().UnixMilli()
+
+	if event.Type == insights.EventTypePartialResult {
+		if run.utteranceStart > 0 {
+			return
+		}
+		run.utteranceStart = now
+		interrupted, err := t.redisService.StartTalkUtterance(run.roomId, run.userId, now)
+		if err != nil {
+			log.WithError(err).Errorln("failed to start talk utterance")
+		}
+		run.interrupted = interrupted
+		return
+	}
+
+	words := len(strings.Fields(event.Result.Text))
+	start, interrupted := run.utteranceStart, run.interrupted
+	run.utteranceStart, run.interrupted = 0, false
+	if words == 0 {
+		return
+	}
+
+	hadPartial := start > 0
+	if event.Duration > 0 {
+		start = now - event.Duration.Milliseconds()
+	} else if !hadPartial {
+		start = now - int64(words*estimatedMsPerWord)
+	}
+	if !hadPartial {
+		var err error
+		if interrupted, err = t.redisService.StartTalkUtterance(run.roomId, run.userId, start); err != nil {
+			log.WithError(err).Errorln("failed to start talk utterance")
+		}
+	}
+
+	speakerId := run.userId
+	if event.SpeakerLabel != "" {
+		speakerId = run.userId + "#" + event.SpeakerLabel
+	}
+	err := t.redisService.AddTalkUtterance(run.roomId, &redisservice.TalkUtterance{
+		SpeakerId:    speakerId,
+		UserId:       run.userId,
+		Name:         event.Result.FromUserName,
+		SpeakerLabel: event.SpeakerLabel,
+		Start:        start,
+		End:          now,
+		Words:        words,
+		Interrupted:  interrupted,
+	})
+	if err != nil {
+		log.WithError(err).Errorln("failed to add talk utterance")
+	}
+}
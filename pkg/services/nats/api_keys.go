@@ -0,0 +1,109 @@
+package natsservice
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// ApiKeysBucket keeps the secrets of rotated api keys, so all the nodes can pick them up without restart
+const ApiKeysBucket = Prefix + "api-keys"
+
+// ApiKeySecrets holds the active secret of an api key
+// & the previous one which stays valid till PreviousExpiresAt
+type ApiKeySecrets struct {
+	ApiKey            string `json:"api_key"`
+	Secret            string `json:"secret"`
+	PreviousSecret    string `json:"previous_secret,omitempty"`
+	PreviousExpiresAt int64  `json:"previous_expires_at,omitempty"`
+	RotatedAt         int64  `json:"rotated_at"`
+}
+
+// ValidSecrets returns the active secret first,
+// followed by the previous one if it is still within its grace window
+func (a *ApiKeySecrets) ValidSecrets() []string {
+	secrets := []string{a.Secret}
+	if a.PreviousSecret != "" && time.Now().Unix() < a.PreviousExpiresAt {
+		secrets = append(secrets, a.PreviousSecret)
+	}
+	return secrets
+}
+
+// createApiKeysKVAndWatch ensures the api keys bucket exists and loads it into the cache.
+func (s *NatsService) createApiKeysKVAndWatch() error {
+	kv, err := s.js.CreateOrUpdateKeyValue(s.ctx, jetstream.KeyValueConfig{
+		Bucket:      ApiKeysBucket,
+		Description: "plugNmeet rotated api key secrets",
+		Replicas:    s.app.NatsInfo.NumReplicas,
+	})
+	if err != nil {
+		s.logger.WithError(err).Errorf("could not create api keys bucket %s", ApiKeysBucket)
+		return err
+	}
+	s.logger.Infof("Successfully created/updated api keys bucket: %s", ApiKeysBucket)
+
+	return s.cs.watchApiKeysKV(kv, s.logger)
+}
+
+// formatApiKeyKey encodes the api key, so any character can be used in the api key
+func (s *NatsService) formatApiKeyKey(apiKey string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(apiKey))
+}
+
+// GetApiKeySecrets returns the rotation info of the api key from the local cache,
+// nil if the api key was never rotated
+func (s *NatsService) GetApiKeySecrets(apiKey string) *ApiKeySecrets {
+	return s.cs.getCachedApiKeySecrets(apiKey)
+}
+
+// SaveApiKeySecrets stores the rotation info, all the nodes will receive it through their watcher
+func (s *NatsService) SaveApiKeySecrets(info *ApiKeySecrets) error {
+	kv, err := s.js.KeyValue(s.ctx, ApiKeysBucket)
+	if err != nil {
+		return err
+	}
+	marshal, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	_, err = kv.Put(s.ctx, s.formatApiKeyKey(info.ApiKey), marshal)
+	if err != nil {
+		return err
+	}
+	// update our own cache immediately, so the following requests will get the new secret
+	s.cs.setApiKeySecretsCache(info)
+	return nil
+}
+
+// DeleteApiKeySecrets removes the rotation info of the api key
+func (s *NatsService) DeleteApiKeySecrets(apiKey string) error {
+	kv, err := s.js.KeyValue(s.ctx, ApiKeysBucket)
+	if err != nil {
+		return err
+	}
+	if err = kv.Purge(s.ctx, s.formatApiKeyKey(apiKey)); err != nil {
+		return err
+	}
+	s.cs.deleteApiKeySecretsCache(apiKey)
+	return nil
+}
+
+// GetClientSecret returns the active secret of the root api key,
+// which will be different from the config file once it was rotated
+func (s *NatsService) GetClientSecret() string {
+	if info := s.cs.getCachedApiKeySecrets(s.app.Client.ApiKey); info != nil {
+		return info.Secret
+	}
+	return s.app.Client.Secret
+}
+
+// GetClientSecrets returns all the secrets of the root api key which are accepted now
+func (s *NatsService) GetClientSecrets() []string {
+	if info := s.cs.getCachedApiKeySecrets(s.app.Client.ApiKey); info != nil {
+		return info.ValidSecrets()
+	}
+	return []string{s.app.Client.Secret}
+}
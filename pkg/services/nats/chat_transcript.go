@@ -0,0 +1,130 @@
+package natsservice
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
+	"github.com/nats-io/nats.go/jetstream"
+	"google.golang.org/protobuf/proto"
+)
+
+const chatTranscriptFetchBatch = 500
+
+// StoredChatMessage is a chat message captured by the chat transcript stream
+type StoredChatMessage struct {
+	Msg *plugnmeet.ChatMessage
+	// UserId is the sender taken from the subject, the client can only publish with its own
+	UserId   string
+	Received time.Time
+}
+
+// createChatTranscriptStream captures the chat messages of all the rooms,
+// those are published using core pub/sub, so they aren't part of the room stream.
+// Only the messages published to {chat}.{roomId}.{userId} are captured,
+// in the shared subject of the room we can't verify the sender.
+func (s *NatsService) createChatTranscriptStream() error {
+	if !s.app.ChatTranscript.Enabled {
+		return nil
+	}
+
+	_, err := s.js.CreateOrUpdateStream(s.ctx, jetstream.StreamConfig{
+		Name:        ChatTranscriptsStream,
+		Description: "plugNmeet chat messages for transcripts",
+		Replicas:    s.app.NatsInfo.NumReplicas,
+		Retention:   jetstream.LimitsPolicy,
+		MaxAge:      s.app.ChatTranscript.MaxAge,
+		Subjects: []string{
+			fmt.Sprintf("%s.*.*", s.app.NatsInfo.Subjects.Chat),
+		},
+	})
+	if err != nil {
+		s.logger.WithError(err).Errorf("error creating chat transcript stream: %s", ChatTranscriptsStream)
+		return err
+	}
+	s.logger.Infof("Successfully created/updated chat transcript stream: %s", ChatTranscriptsStream)
+	return nil
+}
+
+// GetRoomChatMessages drains all the stored chat messages of the room in order.
+// Messages will stay in the stream, use PurgeRoomChatMessages to remove them.
+func (s *NatsService) GetRoomChatMessages(roomId string) ([]*StoredChatMessage, error) {
+	if !s.app.ChatTranscript.Enabled {
+		return nil, nil
+	}
+	subject := fmt.Sprintf("%s.%s.*", s.app.NatsInfo.Subjects.Chat, roomId)
+
+	stream, err := s.js.Stream(s.ctx, ChatTranscriptsStream)
+	if err != nil {
+		return nil, err
+	}
+	info, err := stream.Info(s.ctx, jetstream.WithSubjectFilter(subject))
+	if err != nil {
+		return nil, err
+	}
+	var total uint64
+	for _, count := range info.State.Subjects {
+		total += count
+	}
+	if total == 0 {
+		return nil, nil
+	}
+
+	cons, err := stream.OrderedConsumer(s.ctx, jetstream.OrderedConsumerConfig{
+		FilterSubjects: []string{subject},
+		DeliverPolicy:  jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*StoredChatMessage, 0, total)
+	var received uint64
+	for received < total {
+		batch, err := cons.Fetch(chatTranscriptFetchBatch, jetstream.FetchMaxWait(time.Second*2))
+		if err != nil {
+			return nil, err
+		}
+		count := 0
+		for msg := range batch.Messages() {
+			count++
+			received++
+			chatMsg := new(plugnmeet.ChatMessage)
+			if err := proto.Unmarshal(msg.Data(), chatMsg); err != nil {
+				// invalid message, skip it
+				continue
+			}
+			stored := &StoredChatMessage{
+				Msg:    chatMsg,
+				UserId: msg.Subject()[strings.LastIndex(msg.Subject(), ".")+1:],
+			}
+			if meta, err := msg.Metadata(); err == nil {
+				stored.Received = meta.Timestamp
+			}
+			messages = append(messages, stored)
+		}
+		if err := batch.Error(); err != nil && !errors.Is(err, jetstream.ErrNoMessages) {
+			return nil, err
+		}
+		if count == 0 {
+			// nothing more to read
+			break
+		}
+	}
+
+	return messages, nil
+}
+
+// PurgeRoomChatMessages removes the stored chat messages of the room
+func (s *NatsService) PurgeRoomChatMessages(roomId string) error {
+	if !s.app.ChatTranscript.Enabled {
+		return nil
+	}
+	stream, err := s.js.Stream(s.ctx, ChatTranscriptsStream)
+	if err != nil {
+		return err
+	}
+	return stream.Purge(s.ctx, jetstream.WithPurgeSubject(fmt.Sprintf("%s.%s.*", s.app.NatsInfo.Subjects.Chat, roomId)))
+}
@@ -18,6 +18,27 @@ const (
 	maxTranscodingRetries = 3
 	// in transcoder we've msg.InProgress() update loop but still we can set time little bit longer
 	maxTranscodingAckWait = time.Minute * 10
+
+	LtiGradeJobsStream  = Prefix + "lti-grade-jobs"
+	LtiGradeJobsSubject = Prefix + "lti-grade-jobs"
+
+	WebhookDeliveriesStream  = Prefix + "webhook-deliveries"
+	WebhookDeliveriesSubject = Prefix + "webhook-deliveries"
+	// deliveries waiting for the retry are counted as pending too,
+	// so it's the maximum number of urls which can be retried at the same time
+	webhookDeliveryMaxAckPending = 1000
+
+	ChatTranscriptsStream = Prefix + "chat-transcripts"
+
+	RecordingPostProcessStream  = Prefix + "recording-post-process"
+	RecordingPostProcessSubject = Prefix + "recording-post-process"
+	// worker will keep the message in progress while ffmpeg is running
+	recordingPostProcessAckWait = time.Minute * 5
+
+	RecorderQueueStream  = Prefix + "recorder-queue"
+	RecorderQueueSubject = Prefix + "recorder-queue"
+	// worker will keep the message in progress while waiting for a free recorder
+	recorderQueueAckWait = time.Minute
 )
 
 func (s *NatsService) CreateSystemJsWorkerStreamWithConsumer(ctx context.Context, prefix string, log *logrus.Entry) (jetstream.Consumer, error) {
@@ -165,6 +186,164 @@ func (s *NatsService) CreateSummarizeJobStreamWithConsumer(ctx context.Context,
 	return consumer, nil
 }
 
+// CreateLtiGradeJobStreamWithConsumer creates the queue to submit scores to the LMS, failed jobs will be retried
+func (s *NatsService) CreateLtiGradeJobStreamWithConsumer(ctx context.Context, log *logrus.Entry) (jetstream.Consumer, error) {
+	stream, err := s.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:        LtiGradeJobsStream,
+		Description: "plugNmeet LTI grade submission jobs",
+		Retention:   jetstream.WorkQueuePolicy,
+		Replicas:    s.app.NatsInfo.NumReplicas,
+		Subjects: []string{
+			LtiGradeJobsSubject,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("error creating lti grade job stream")
+		return nil, err
+	}
+	log.Info("Created/Updated lti grade job stream")
+
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:    LtiGradeJobsSubject + "-durable",
+		AckPolicy:  jetstream.AckExplicitPolicy,
+		MaxDeliver: s.app.LtiGradeSettings.MaxRetries,
+		AckWait:    time.Minute,
+	})
+	if err != nil {
+		log.WithError(err).Error("error creating lti grade job consumer")
+		return nil, err
+	}
+	log.Info("Created/Updated lti grade job consumer")
+
+	return consumer, nil
+}
+
+// PublishLtiGradeJob adds a score submission job to the queue
+func (s *NatsService) PublishLtiGradeJob(msgId string, data []byte) error {
+	_, err := s.js.Publish(s.ctx, LtiGradeJobsSubject, data, jetstream.WithMsgID(msgId), jetstream.WithExpectStream(LtiGradeJobsStream))
+	return err
+}
+
+// CreateWebhookDeliveryStreamWithConsumer creates the queue of outgoing webhook requests.
+// Retries are controlled by the consumer of the queue, so there is no limit of deliveries here.
+func (s *NatsService) CreateWebhookDeliveryStreamWithConsumer(ctx context.Context, log *logrus.Entry) (jetstream.Consumer, error) {
+	stream, err := s.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:        WebhookDeliveriesStream,
+		Description: "plugNmeet outgoing webhook deliveries",
+		Retention:   jetstream.WorkQueuePolicy,
+		Replicas:    s.app.NatsInfo.NumReplicas,
+		Subjects: []string{
+			WebhookDeliveriesSubject,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("error creating webhook delivery stream")
+		return nil, err
+	}
+	log.Info("Created/Updated webhook delivery stream")
+
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       WebhookDeliveriesSubject + "-durable",
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		MaxDeliver:    -1,
+		AckWait:       s.app.Client.WebhookConf.RequestTimeout + time.Minute,
+		MaxAckPending: webhookDeliveryMaxAckPending,
+	})
+	if err != nil {
+		log.WithError(err).Error("error creating webhook delivery consumer")
+		return nil, err
+	}
+	log.Info("Created/Updated webhook delivery consumer")
+
+	return consumer, nil
+}
+
+// PublishWebhookDelivery adds a delivery to the queue
+func (s *NatsService) PublishWebhookDelivery(msgId string, data []byte) error {
+	_, err := s.js.Publish(s.ctx, WebhookDeliveriesSubject, data, jetstream.WithMsgID(msgId), jetstream.WithExpectStream(WebhookDeliveriesStream))
+	return err
+}
+
+// CreateRecordingPostProcessStreamWithConsumer creates the queue of recording post-processing jobs.
+// Attempts are tracked in the DB by the consumer of the queue.
+func (s *NatsService) CreateRecordingPostProcessStreamWithConsumer(ctx context.Context, log *logrus.Entry) (jetstream.Consumer, error) {
+	stream, err := s.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:        RecordingPostProcessStream,
+		Description: "plugNmeet recording post-processing jobs",
+		Retention:   jetstream.WorkQueuePolicy,
+		Replicas:    s.app.NatsInfo.NumReplicas,
+		Subjects: []string{
+			RecordingPostProcessSubject,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("error creating recording post-processing stream")
+		return nil, err
+	}
+	log.Info("Created/Updated recording post-processing stream")
+
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:    RecordingPostProcessSubject + "-durable",
+		AckPolicy:  jetstream.AckExplicitPolicy,
+		MaxDeliver: -1,
+		AckWait:    recordingPostProcessAckWait,
+	})
+	if err != nil {
+		log.WithError(err).Error("error creating recording post-processing consumer")
+		return nil, err
+	}
+	log.Info("Created/Updated recording post-processing consumer")
+
+	return consumer, nil
+}
+
+// PublishRecordingPostProcessJob adds a recording to the post-processing queue
+func (s *NatsService) PublishRecordingPostProcessJob(msgId string, data []byte) error {
+	_, err := s.js.Publish(s.ctx, RecordingPostProcessSubject, data, jetstream.WithMsgID(msgId), jetstream.WithExpectStream(RecordingPostProcessStream))
+	return err
+}
+
+// CreateRecorderQueueStreamWithConsumer creates the queue of recording requests waiting for a free recorder.
+// Only one request is handed out at a time, a request which has to wait is redelivered later,
+// the order among the requests competing for the same recorders is kept by the queue in redis.
+func (s *NatsService) CreateRecorderQueueStreamWithConsumer(ctx context.Context, log *logrus.Entry) (jetstream.Consumer, error) {
+	stream, err := s.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:        RecorderQueueStream,
+		Description: "plugNmeet recording requests waiting for a recorder",
+		Retention:   jetstream.WorkQueuePolicy,
+		Replicas:    s.app.NatsInfo.NumReplicas,
+		Subjects: []string{
+			RecorderQueueSubject,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("error creating recorder queue stream")
+		return nil, err
+	}
+	log.Info("Created/Updated recorder queue stream")
+
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       RecorderQueueSubject + "-durable",
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		MaxDeliver:    -1,
+		MaxAckPending: 1,
+		AckWait:       recorderQueueAckWait,
+	})
+	if err != nil {
+		log.WithError(err).Error("error creating recorder queue consumer")
+		return nil, err
+	}
+	log.Info("Created/Updated recorder queue consumer")
+
+	return consumer, nil
+}
+
+// PublishRecorderQueueRequest adds a recording request to the recorder queue
+func (s *NatsService) PublishRecorderQueueRequest(msgId string, data []byte) error {
+	_, err := s.js.Publish(s.ctx, RecorderQueueSubject, data, jetstream.WithMsgID(msgId), jetstream.WithExpectStream(RecorderQueueStream))
+	return err
+}
+
 func (s *NatsService) DeleteConsumer(roomId, userId string) {
 	durableName := fmt.Sprintf(DurableNameTpl, roomId, userId)
 	_ = s.js.DeleteConsumer(s.ctx, s.app.NatsInfo.RoomStreamName, durableName)
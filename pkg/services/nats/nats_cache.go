@@ -42,6 +42,11 @@ type NatsCacheService struct {
 	// Lock for the global recorder store
 	recorderLock   sync.RWMutex
 	recordersStore map[string]*utils.RecorderInfo
+	recorderLabels map[string]map[string]string
+
+	// Lock for the rotated api keys store
+	apiKeysLock  sync.RWMutex
+	apiKeysStore map[string]*ApiKeySecrets
 }
 
 func newNatsCacheService(ctx context.Context, log *logrus.Entry) *NatsCacheService {
@@ -53,6 +58,8 @@ func newNatsCacheService(ctx context.Context, log *logrus.Entry) *NatsCacheServi
 		roomUsersInfoStore: make(map[string]map[string]CachedUserInfoEntry),
 		roomFilesStore:     make(map[string]map[string]*plugnmeet.RoomUploadedFileMetadata),
 		recordersStore:     make(map[string]*utils.RecorderInfo),
+		recorderLabels:     make(map[string]map[string]string),
+		apiKeysStore:       make(map[string]*ApiKeySecrets),
 		logger:             log.WithField("sub-service", "nats-cache"),
 	}
 }
@@ -0,0 +1,101 @@
+package natsservice
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/sirupsen/logrus"
+)
+
+// watchApiKeysKV will load the api keys bucket & keep watching for rotations.
+// It only returns after the initial values were received,
+// otherwise requests using a rotated secret may fail during startup.
+func (ncs *NatsCacheService) watchApiKeysKV(kv jetstream.KeyValue, log *logrus.Entry) error {
+	log = log.WithField("sub-service", "api-keys-cache-watcher")
+
+	watcher, err := kv.WatchAll(ncs.serviceCtx)
+	if err != nil {
+		log.WithError(err).Errorln("Error starting NATS KV api keys watcher")
+		return err
+	}
+	log.Infof("NATS KV api keys watcher started for bucket: %s", kv.Bucket())
+
+	ready := make(chan struct{})
+	go func() {
+		defer func() {
+			log.Infof("NATS KV api keys watcher stopped")
+			_ = watcher.Stop()
+		}()
+
+		initialized := false
+		for {
+			select {
+			case <-ncs.serviceCtx.Done():
+				return
+			case entry, ok := <-watcher.Updates():
+				if !ok {
+					return // Channel closed
+				}
+				if entry == nil {
+					// all the initial values were delivered
+					if !initialized {
+						initialized = true
+						close(ready)
+					}
+					continue
+				}
+				ncs.updateApiKeysCache(entry, log)
+			}
+		}
+	}()
+
+	select {
+	case <-ready:
+		return nil
+	case <-time.After(10 * time.Second):
+		return errors.New("timeout waiting for initial values of api keys bucket")
+	}
+}
+
+func (ncs *NatsCacheService) updateApiKeysCache(entry jetstream.KeyValueEntry, log *logrus.Entry) {
+	if entry.Operation() == jetstream.KeyValueDelete || entry.Operation() == jetstream.KeyValuePurge {
+		if apiKey, err := base64.RawURLEncoding.DecodeString(entry.Key()); err == nil {
+			ncs.deleteApiKeySecretsCache(string(apiKey))
+		}
+		return
+	}
+
+	info := new(ApiKeySecrets)
+	if err := json.Unmarshal(entry.Value(), info); err != nil {
+		log.WithError(err).Errorf("invalid value for key %s", entry.Key())
+		return
+	}
+
+	ncs.setApiKeySecretsCache(info)
+}
+
+func (ncs *NatsCacheService) setApiKeySecretsCache(info *ApiKeySecrets) {
+	ncs.apiKeysLock.Lock()
+	defer ncs.apiKeysLock.Unlock()
+	ncs.apiKeysStore[info.ApiKey] = new(*info)
+}
+
+func (ncs *NatsCacheService) deleteApiKeySecretsCache(apiKey string) {
+	ncs.apiKeysLock.Lock()
+	defer ncs.apiKeysLock.Unlock()
+	delete(ncs.apiKeysStore, apiKey)
+}
+
+// getCachedApiKeySecrets returns a copy of the rotation info of the api key
+func (ncs *NatsCacheService) getCachedApiKeySecrets(apiKey string) *ApiKeySecrets {
+	ncs.apiKeysLock.RLock()
+	defer ncs.apiKeysLock.RUnlock()
+
+	if info, ok := ncs.apiKeysStore[apiKey]; ok {
+		return new(*info)
+	}
+	return nil
+}
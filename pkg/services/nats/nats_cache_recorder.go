@@ -2,6 +2,7 @@ package natsservice
 
 import (
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
@@ -9,6 +10,25 @@ import (
 	"github.com/nats-io/nats.go/jetstream"
 )
 
+// RecorderLabelsField is the field of the recorder info KV in which the recorder
+// publishes its labels, e.g. region=eu-west,tier=gpu
+const RecorderLabelsField = "labels"
+
+// ParseRecorderLabels parses comma separated key=value pairs.
+// A key without value is stored with an empty value.
+func ParseRecorderLabels(val string) map[string]string {
+	labels := make(map[string]string)
+	for _, l := range strings.Split(val, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(l), "=")
+		k = strings.TrimSpace(k)
+		if k == "" {
+			continue
+		}
+		labels[k] = strings.TrimSpace(v)
+	}
+	return labels
+}
+
 // updateRecorderCache is called by the recorder watcher to update the global recorder cache.
 func (ncs *NatsCacheService) updateRecorderCache(entry jetstream.KeyValueEntry) {
 	ncs.recorderLock.Lock()
@@ -26,6 +46,7 @@ func (ncs *NatsCacheService) updateRecorderCache(entry jetstream.KeyValueEntry)
 			ncs.logger.Infof("recorder %s went offline, removing from local cache", recorderId)
 			delete(ncs.recordersStore, recorderId)
 		}
+		delete(ncs.recorderLabels, recorderId)
 		return
 	}
 
@@ -38,6 +59,10 @@ func (ncs *NatsCacheService) updateRecorderCache(entry jetstream.KeyValueEntry)
 	}
 
 	val := string(entry.Value())
+	if field == RecorderLabelsField {
+		ncs.recorderLabels[recorderId] = ParseRecorderLabels(val)
+		return
+	}
 	fieldKey, _ := strconv.Atoi(field)
 
 	switch plugnmeet.RecorderInfoKeys(fieldKey) {
@@ -90,3 +115,15 @@ func (ncs *NatsCacheService) getCachedRecorderInfo(recorderId string) (*utils.Re
 
 	return nil, false
 }
+
+// getCachedRecorderLabels retrieves the labels of the recorder from the cache.
+func (ncs *NatsCacheService) getCachedRecorderLabels(recorderId string) map[string]string {
+	ncs.recorderLock.RLock()
+	defer ncs.recorderLock.RUnlock()
+
+	labels := make(map[string]string, len(ncs.recorderLabels[recorderId]))
+	for k, v := range ncs.recorderLabels[recorderId] {
+		labels[k] = v
+	}
+	return labels
+}
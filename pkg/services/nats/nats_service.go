@@ -83,6 +83,14 @@ func (s *NatsService) Initialized(lc fx.Lifecycle) error {
 				s.logger.WithError(err).Error("failed to initialize recorder KV and watch")
 				return err
 			}
+			if err := s.createApiKeysKVAndWatch(); err != nil {
+				s.logger.WithError(err).Error("failed to initialize api keys KV and watch")
+				return err
+			}
+			if err := s.createChatTranscriptStream(); err != nil {
+				s.logger.WithError(err).Error("failed to initialize chat transcript stream")
+				return err
+			}
 			return nil
 		},
 		OnStop: func(_ context.Context) error {
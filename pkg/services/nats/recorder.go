@@ -36,3 +36,8 @@ func (s *NatsService) GetRecorderInfo(recorderId string) (*utils.RecorderInfo, e
 	}
 	return nil, nil
 }
+
+// GetRecorderLabels returns the labels published by the recorder, e.g. region or tier.
+func (s *NatsService) GetRecorderLabels(recorderId string) map[string]string {
+	return s.cs.getCachedRecorderLabels(recorderId)
+}
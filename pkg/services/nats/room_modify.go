@@ -166,5 +166,6 @@ func (s *NatsService) OnAfterSessionEndCleanup(roomId string) {
 	// silently delete everything without log
 	_ = s.deleteAllUserConsumers(roomId)
 	_ = s.PurgeRoomMessagesFromStream(roomId)
+	_ = s.PurgeRoomChatMessages(roomId)
 	_ = s.DeleteRoom(roomId)
 }
@@ -0,0 +1,60 @@
+package redisservice
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	bbbHooksHashKey = Prefix + "bbbHooks"  // A single HASH key for all BBB hooks, field: hookId
+	bbbHookIdKey    = Prefix + "bbbHookId" // counter to generate numeric hook ids like BBB
+)
+
+// NewBBBHookId returns a new unique id for a BBB hook
+func (s *RedisService) NewBBBHookId() (string, error) {
+	id, err := s.rc.Incr(s.ctx, bbbHookIdKey).Result()
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+// AddBBBHook adds a hook and sets a TTL on that specific hash field.
+func (s *RedisService) AddBBBHook(hookId string, val []byte) error {
+	pipe := s.rc.Pipeline()
+	pipe.HSet(s.ctx, bbbHooksHashKey, hookId, val)
+	pipe.HExpire(s.ctx, bbbHooksHashKey, DefaultTTL, hookId)
+	_, err := pipe.Exec(s.ctx)
+	return err
+}
+
+// GetBBBHook returns a hook by id, nil if not found
+func (s *RedisService) GetBBBHook(hookId string) ([]byte, error) {
+	val, err := s.rc.HGet(s.ctx, bbbHooksHashKey, hookId).Bytes()
+	switch {
+	case errors.Is(err, redis.Nil):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	return val, nil
+}
+
+// GetAllBBBHooks returns all the hooks as hookId => value
+func (s *RedisService) GetAllBBBHooks() (map[string]string, error) {
+	val, err := s.rc.HGetAll(s.ctx, bbbHooksHashKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	return val, nil
+}
+
+// DeleteBBBHooks removes hooks by ids
+func (s *RedisService) DeleteBBBHooks(hookIds ...string) error {
+	if len(hookIds) == 0 {
+		return nil
+	}
+	return s.rc.HDel(s.ctx, bbbHooksHashKey, hookIds...).Err()
+}
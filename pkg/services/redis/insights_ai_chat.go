@@ -105,6 +105,11 @@ func (s *RedisService) UpdateAITextChatUsage(ctx context.Context, roomId, userId
 	pipe.HIncrBy(ctx, key, totalTokensKey, int64(totalTokens))
 
 	pipe.Expire(ctx, key, 24*time.Hour)
+	s.incrInsightsTenantBudgetUsage(ctx, pipe, roomId, map[string]int64{
+		totalPromptKey:     int64(promptTokens),
+		totalCompletionKey: int64(completionTokens),
+		totalTokensKey:     int64(totalTokens),
+	})
 	_, err := pipe.Exec(ctx)
 	return err
 }
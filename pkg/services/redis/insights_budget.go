@@ -0,0 +1,117 @@
+package redisservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	insightsTenantBudgetUsageKey = Prefix + "insights:budget:tenant:%s:%s" // HASH, tenant id & month
+	insightsBudgetStateKey       = Prefix + "insights:budget:state:%s"     // HASH, room id
+	insightsBudgetRoomTenantKey  = Prefix + "insights:budget:room_tenant"  // HASH, field: room id, value: tenant id
+
+	// counters of the tenant usage, the AI text chat uses the same fields as the room usage.
+	// The cost is calculated with the pricing of the config, same as for the rooms.
+	InsightsBudgetTranscriptionSecondsField        = "transcription_seconds"
+	InsightsBudgetTTSCharactersField               = "tts_characters"
+	InsightsBudgetTranslationCharactersField       = "translation_characters"
+	InsightsBudgetLiveSummaryPromptTokensField     = "live_summary_prompt_tokens"
+	InsightsBudgetLiveSummaryCompletionTokensField = "live_summary_completion_tokens"
+
+	// keep the usage of the month a little longer than the month itself
+	insightsTenantBudgetUsageTTL = time.Hour * 24 * 40
+)
+
+// InsightsBudgetUsage is the usage of the insights services to compare with a budget
+type InsightsBudgetUsage struct {
+	TranscriptionSeconds int64
+	Tokens               int64
+	Characters           int64
+	Cost                 float64
+}
+
+func insightsBudgetMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// SetInsightsBudgetRoomTenant links the running room with its tenant,
+// so the usage of the room will be added to the tenant at the same time.
+func (s *RedisService) SetInsightsBudgetRoomTenant(roomId, tenantId string) error {
+	pipe := s.rc.Pipeline()
+	pipe.HSet(s.ctx, insightsBudgetRoomTenantKey, roomId, tenantId)
+	pipe.HExpire(s.ctx, insightsBudgetRoomTenantKey, DefaultTTL, roomId)
+	_, err := pipe.Exec(s.ctx)
+	return err
+}
+
+// insightsTenantBudgetUsageKeyOfRoom returns the usage key of the tenant of the room in the current month,
+// empty if the room doesn't belong to a tenant
+func (s *RedisService) insightsTenantBudgetUsageKeyOfRoom(ctx context.Context, roomId string) (string, error) {
+	tenantId, err := s.rc.HGet(ctx, insightsBudgetRoomTenantKey, roomId).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", nil
+		}
+		return "", err
+	}
+	if tenantId == "" {
+		return "", nil
+	}
+	return fmt.Sprintf(insightsTenantBudgetUsageKey, tenantId, insightsBudgetMonth(time.Now())), nil
+}
+
+// incrInsightsTenantBudgetUsage adds the usage of the room to its tenant within the same pipeline
+// which updates the usage of the room
+func (s *RedisService) incrInsightsTenantBudgetUsage(ctx context.Context, pipe redis.Pipeliner, roomId string, fields map[string]int64) {
+	key, err := s.insightsTenantBudgetUsageKeyOfRoom(ctx, roomId)
+	if err != nil {
+		s.logger.WithError(err).WithField("roomId", roomId).Errorln("failed to get tenant of the room for insights budget")
+		return
+	}
+	if key == "" {
+		return
+	}
+	for field, val := range fields {
+		pipe.HIncrBy(ctx, key, field, val)
+	}
+	pipe.Expire(ctx, key, insightsTenantBudgetUsageTTL)
+}
+
+// GetInsightsTenantBudgetUsage returns the counters of the usage of the tenant in the current month
+func (s *RedisService) GetInsightsTenantBudgetUsage(tenantId string) (map[string]int64, error) {
+	key := fmt.Sprintf(insightsTenantBudgetUsageKey, tenantId, insightsBudgetMonth(time.Now()))
+	vals, err := s.rc.HGetAll(s.ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]int64, len(vals))
+	for field, val := range vals {
+		usage[field], _ = strconv.ParseInt(val, 10, 64)
+	}
+	return usage, nil
+}
+
+// MarkInsightsBudgetState sets the state of the budget of the room, e.g. a warning was sent.
+// It returns true only for the first time, so the same action won't be repeated.
+func (s *RedisService) MarkInsightsBudgetState(roomId, state string) (bool, error) {
+	key := fmt.Sprintf(insightsBudgetStateKey, roomId)
+
+	pipe := s.rc.TxPipeline()
+	set := pipe.HSetNX(s.ctx, key, state, time.Now().Unix())
+	pipe.Expire(s.ctx, key, DefaultTTL)
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return false, err
+	}
+	return set.Val(), nil
+}
+
+// DeleteInsightsBudgetState removes the states of the budget of the room
+func (s *RedisService) DeleteInsightsBudgetState(roomId string) error {
+	return s.rc.Del(s.ctx, fmt.Sprintf(insightsBudgetStateKey, roomId)).Err()
+}
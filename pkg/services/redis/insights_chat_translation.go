@@ -20,6 +20,7 @@ func (s *RedisService) UpdateChatTranslationUsage(ctx context.Context, roomId, u
 	pipe.HIncrBy(ctx, key, userId, int64(incBy))
 	pipe.HIncrBy(ctx, key, TotalUsageField, int64(incBy))
 	pipe.Expire(ctx, key, time.Hour*24)
+	s.incrInsightsTenantBudgetUsage(ctx, pipe, roomId, map[string]int64{InsightsBudgetTranslationCharactersField: int64(incBy)})
 	_, err := pipe.Exec(ctx)
 	return err
 }
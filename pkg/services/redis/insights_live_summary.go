@@ -0,0 +1,120 @@
+package redisservice
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	insightsLiveSummaryKey      = Prefix + "insights:live_summary:%s"      // HASH per room
+	insightsLiveSummaryLockKey  = Prefix + "insights:live_summary_lock:%s" // lock per room
+	insightsLiveSummaryRoomsKey = Prefix + "insights:live_summary_rooms"   // SET of rooms with live summary enabled
+
+	liveSummaryStartedBy        = "started_by"
+	liveSummaryEnabled          = "enabled"
+	liveSummaryLastChunk        = "last_chunk"
+	liveSummaryLastRun          = "last_run"
+	liveSummaryResult           = "result"
+	liveSummaryPromptTokens     = "prompt_tokens"
+	liveSummaryCompletionTokens = "completion_tokens"
+)
+
+// LiveSummaryState is the state of the live summary of a room
+type LiveSummaryState struct {
+	Enabled   bool
+	StartedBy string
+	// LastChunk is the field of the last transcription chunk which was summarized
+	LastChunk string
+	// LastRun in unix ms
+	LastRun int64
+	// Result is the latest summary in JSON
+	Result           string
+	PromptTokens     int64
+	CompletionTokens int64
+}
+
+// EnableLiveSummary marks the live summary of the room as running
+func (s *RedisService) EnableLiveSummary(roomId, userId string) error {
+	key := fmt.Sprintf(insightsLiveSummaryKey, roomId)
+	pipe := s.rc.TxPipeline()
+	pipe.HSet(s.ctx, key, liveSummaryEnabled, 1, liveSummaryStartedBy, userId)
+	pipe.Expire(s.ctx, key, DefaultTTL)
+	pipe.SAdd(s.ctx, insightsLiveSummaryRoomsKey, roomId)
+	_, err := pipe.Exec(s.ctx)
+	return err
+}
+
+// DisableLiveSummary stops the updates, but keeps the latest summary for the artifact
+func (s *RedisService) DisableLiveSummary(roomId string) error {
+	pipe := s.rc.TxPipeline()
+	pipe.HDel(s.ctx, fmt.Sprintf(insightsLiveSummaryKey, roomId), liveSummaryEnabled)
+	pipe.SRem(s.ctx, insightsLiveSummaryRoomsKey, roomId)
+	_, err := pipe.Exec(s.ctx)
+	return err
+}
+
+// GetLiveSummaryRooms returns the rooms with live summary enabled
+func (s *RedisService) GetLiveSummaryRooms() ([]string, error) {
+	return s.rc.SMembers(s.ctx, insightsLiveSummaryRoomsKey).Result()
+}
+
+// GetLiveSummaryState returns nil if live summary was never started in the room
+func (s *RedisService) GetLiveSummaryState(roomId string) (*LiveSummaryState, error) {
+	vals, err := s.rc.HGetAll(s.ctx, fmt.Sprintf(insightsLiveSummaryKey, roomId)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(vals) == 0 {
+		return nil, nil
+	}
+
+	st := &LiveSummaryState{
+		Enabled:   vals[liveSummaryEnabled] == "1",
+		StartedBy: vals[liveSummaryStartedBy],
+		LastChunk: vals[liveSummaryLastChunk],
+		Result:    vals[liveSummaryResult],
+	}
+	st.LastRun, _ = strconv.ParseInt(vals[liveSummaryLastRun], 10, 64)
+	st.PromptTokens, _ = strconv.ParseInt(vals[liveSummaryPromptTokens], 10, 64)
+	st.CompletionTokens, _ = strconv.ParseInt(vals[liveSummaryCompletionTokens], 10, 64)
+	return st, nil
+}
+
+// SetLiveSummaryLastRun records the time of an attempt, so failed ones won't be retried immediately
+func (s *RedisService) SetLiveSummaryLastRun(roomId string) error {
+	return s.rc.HSet(s.ctx, fmt.Sprintf(insightsLiveSummaryKey, roomId), liveSummaryLastRun, time.Now().UnixMilli()).Err()
+}
+
+// UpdateLiveSummaryResult stores the new summary with the token usage of the request
+func (s *RedisService) UpdateLiveSummaryResult(roomId, result, lastChunk string, promptTokens, completionTokens uint32) error {
+	key := fmt.Sprintf(insightsLiveSummaryKey, roomId)
+	pipe := s.rc.TxPipeline()
+	pipe.HSet(s.ctx, key, liveSummaryResult, result, liveSummaryLastChunk, lastChunk, liveSummaryLastRun, time.Now().UnixMilli())
+	pipe.HIncrBy(s.ctx, key, liveSummaryPromptTokens, int64(promptTokens))
+	pipe.HIncrBy(s.ctx, key, liveSummaryCompletionTokens, int64(completionTokens))
+	pipe.Expire(s.ctx, key, DefaultTTL)
+	s.incrInsightsTenantBudgetUsage(s.ctx, pipe, roomId, map[string]int64{
+		InsightsBudgetLiveSummaryPromptTokensField:     int64(promptTokens),
+		InsightsBudgetLiveSummaryCompletionTokensField: int64(completionTokens),
+	})
+	_, err := pipe.Exec(s.ctx)
+	return err
+}
+
+// LockLiveSummary makes sure only one update of the room runs at a time
+func (s *RedisService) LockLiveSummary(roomId string, ttl time.Duration) (bool, error) {
+	return s.rc.SetNX(s.ctx, fmt.Sprintf(insightsLiveSummaryLockKey, roomId), 1, ttl).Result()
+}
+
+func (s *RedisService) UnlockLiveSummary(roomId string) {
+	_ = s.rc.Del(s.ctx, fmt.Sprintf(insightsLiveSummaryLockKey, roomId)).Err()
+}
+
+// DeleteLiveSummary removes the state of the room
+func (s *RedisService) DeleteLiveSummary(roomId string) {
+	pipe := s.rc.TxPipeline()
+	pipe.Del(s.ctx, fmt.Sprintf(insightsLiveSummaryKey, roomId))
+	pipe.SRem(s.ctx, insightsLiveSummaryRoomsKey, roomId)
+	_, _ = pipe.Exec(s.ctx)
+}
@@ -0,0 +1,108 @@
+package redisservice
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	insightsAccountHealthKey = Prefix + "insights:account_health:%s:%s" // HASH, provider & account id
+	insightsRoundRobinKey    = Prefix + "insights:round_robin:%s"       // counter per service
+
+	insightsHealthFailures    = "failures"
+	insightsHealthOpenUntil   = "open_until"
+	insightsHealthLastError   = "last_error"
+	insightsHealthLastFailure = "last_failure"
+)
+
+// InsightsAccountHealth is the shared state of the circuit breaker of an account
+type InsightsAccountHealth struct {
+	// ConsecutiveFailures since the last success
+	ConsecutiveFailures int64
+	// OpenUntil in unix ms, the account is unhealthy until then
+	OpenUntil   int64
+	LastError   string
+	LastFailure int64
+}
+
+// InsightsAccountRef identifies the account of a provider
+type InsightsAccountRef struct {
+	Provider  string
+	AccountId string
+}
+
+// IsOpen returns true if the account shouldn't be used now
+func (h *InsightsAccountHealth) IsOpen() bool {
+	return h.OpenUntil > time.Now().UnixMilli()
+}
+
+// RecordInsightsAccountFailure counts the failure & opens the circuit once the threshold was reached.
+// It returns true if the circuit was opened by this failure.
+func (s *RedisService) RecordInsightsAccountFailure(provider, accountId, errMsg string, threshold int, openDuration time.Duration) (bool, error) {
+	key := fmt.Sprintf(insightsAccountHealthKey, provider, accountId)
+	now := time.Now()
+
+	pipe := s.rc.TxPipeline()
+	failures := pipe.HIncrBy(s.ctx, key, insightsHealthFailures, 1)
+	pipe.HSet(s.ctx, key, insightsHealthLastError, errMsg, insightsHealthLastFailure, now.UnixMilli())
+	pipe.Expire(s.ctx, key, DefaultTTL)
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return false, err
+	}
+
+	if failures.Val() < int64(threshold) {
+		return false, nil
+	}
+	// reset the counter, so after the open duration the account gets another chance
+	err := s.rc.HSet(s.ctx, key, insightsHealthOpenUntil, now.Add(openDuration).UnixMilli(), insightsHealthFailures, 0).Err()
+	return err == nil, err
+}
+
+// RecordInsightsAccountSuccess closes the circuit of the account
+func (s *RedisService) RecordInsightsAccountSuccess(provider, accountId string) error {
+	key := fmt.Sprintf(insightsAccountHealthKey, provider, accountId)
+	return s.rc.HDel(s.ctx, key, insightsHealthFailures, insightsHealthOpenUntil).Err()
+}
+
+// GetInsightsAccountsHealth returns the health of the accounts in the same order using a single round-trip,
+// an empty state for the accounts which never failed
+func (s *RedisService) GetInsightsAccountsHealth(accounts []InsightsAccountRef) ([]*InsightsAccountHealth, error) {
+	pipe := s.rc.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(accounts))
+	for i, a := range accounts {
+		cmds[i] = pipe.HGetAll(s.ctx, fmt.Sprintf(insightsAccountHealthKey, a.Provider, a.AccountId))
+	}
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return nil, err
+	}
+
+	list := make([]*InsightsAccountHealth, len(accounts))
+	for i, cmd := range cmds {
+		list[i] = parseInsightsAccountHealth(cmd.Val())
+	}
+	return list, nil
+}
+
+func parseInsightsAccountHealth(vals map[string]string) *InsightsAccountHealth {
+	h := new(InsightsAccountHealth)
+	h.ConsecutiveFailures, _ = strconv.ParseInt(vals[insightsHealthFailures], 10, 64)
+	h.OpenUntil, _ = strconv.ParseInt(vals[insightsHealthOpenUntil], 10, 64)
+	h.LastFailure, _ = strconv.ParseInt(vals[insightsHealthLastFailure], 10, 64)
+	h.LastError = vals[insightsHealthLastError]
+	return h
+}
+
+// NextInsightsRoundRobin returns an increasing number to select the next account of the service
+func (s *RedisService) NextInsightsRoundRobin(serviceType string) (int64, error) {
+	key := fmt.Sprintf(insightsRoundRobinKey, serviceType)
+	pipe := s.rc.TxPipeline()
+	val := pipe.Incr(s.ctx, key)
+	pipe.Expire(s.ctx, key, DefaultTTL)
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return 0, err
+	}
+	return val.Val(), nil
+}
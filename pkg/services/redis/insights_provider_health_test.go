@@ -0,0 +1,35 @@
+package redisservice
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseInsightsAccountHealth(t *testing.T) {
+	openUntil := time.Now().Add(time.Minute).UnixMilli()
+	h := parseInsightsAccountHealth(map[string]string{
+		insightsHealthFailures:    "0",
+		insightsHealthOpenUntil:   strconv.FormatInt(openUntil, 10),
+		insightsHealthLastError:   "timeout",
+		insightsHealthLastFailure: "1000",
+	})
+	if !h.IsOpen() || h.OpenUntil != openUntil || h.LastError != "timeout" || h.LastFailure != 1000 {
+		t.Errorf("unexpected health: %+v", h)
+	}
+
+	// never failed
+	h = parseInsightsAccountHealth(map[string]string{})
+	if h.IsOpen() || h.ConsecutiveFailures != 0 {
+		t.Errorf("account without state must be healthy: %+v", h)
+	}
+
+	// failures below the threshold don't open the circuit, after the open duration it's closed again
+	h = parseInsightsAccountHealth(map[string]string{
+		insightsHealthFailures:  "2",
+		insightsHealthOpenUntil: strconv.FormatInt(time.Now().Add(-time.Second).UnixMilli(), 10),
+	})
+	if h.IsOpen() || h.ConsecutiveFailures != 2 {
+		t.Errorf("expired circuit must be closed: %+v", h)
+	}
+}
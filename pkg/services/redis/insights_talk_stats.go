@@ -0,0 +1,155 @@
+package redisservice
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	insightsTalkStatsKey  = Prefix + "insights:talk_stats:%s"  // HASH per room, fields are metric:speakerId
+	insightsTalkActiveKey = Prefix + "insights:talk_active:%s" // HASH per room, userId => start:end of the latest utterance
+
+	talkStatsUserId        = "user_id"
+	talkStatsName          = "name"
+	talkStatsLabel         = "label"
+	talkStatsTalkMs        = "talk_ms"
+	talkStatsWords         = "words"
+	talkStatsUtterances    = "utterances"
+	talkStatsInterruptions = "interruptions"
+
+	// an utterance which hasn't ended within this time won't be counted as in progress anymore
+	talkUtteranceStaleMs = 60 * 1000
+)
+
+// TalkUtterance is a final transcription result of a speaker
+type TalkUtterance struct {
+	// SpeakerId is the userId, or userId with the label for a diarized track
+	SpeakerId    string
+	UserId       string
+	Name         string
+	SpeakerLabel string
+	// Start & End in unix ms
+	Start       int64
+	End         int64
+	Words       int
+	Interrupted bool
+}
+
+// TalkStats is the talk-time of a speaker of the room
+type TalkStats struct {
+	SpeakerId     string
+	UserId        string
+	Name          string
+	SpeakerLabel  string
+	TalkMs        int64
+	Words         int64
+	Utterances    int64
+	Interruptions int64
+}
+
+// startTalkUtteranceScript marks the user as speaking & checks the other users in the same step,
+// so the tracks which start at the same time see each other.
+// KEYS[1]: active key, ARGV: userId, start, stale ms, ttl in seconds
+const startTalkUtteranceScript = `
+local start = tonumber(ARGV[2])
+local interrupted = 0
+local all = redis.call("HGETALL", KEYS[1])
+for i = 1, #all, 2 do
+    if all[i] ~= ARGV[1] then
+        local sep = string.find(all[i + 1], ":", 1, true)
+        if sep then
+            local otherStart = tonumber(string.sub(all[i + 1], 1, sep - 1)) or 0
+            local otherEnd = tonumber(string.sub(all[i + 1], sep + 1)) or 0
+            if otherStart < start and ((otherEnd == 0 and start - otherStart < tonumber(ARGV[3])) or otherEnd > start) then
+                interrupted = 1
+                break
+            end
+        end
+    end
+end
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[2] .. ":0")
+redis.call("EXPIRE", KEYS[1], ARGV[4])
+return interrupted
+`
+
+// StartTalkUtterance marks the user as speaking from the start & reports if
+// another user was speaking at that time.
+func (s *RedisService) StartTalkUtterance(roomId, userId string, start int64) (bool, error) {
+	key := fmt.Sprintf(insightsTalkActiveKey, roomId)
+	res, err := s.startTalkUtteranceScriptExec.Run(s.ctx, s.rc, []string{key}, userId, start, talkUtteranceStaleMs, int64(DefaultTTL.Seconds())).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+// AddTalkUtterance adds the utterance to the stats of the speaker & ends it
+func (s *RedisService) AddTalkUtterance(roomId string, u *TalkUtterance) error {
+	key := fmt.Sprintf(insightsTalkStatsKey, roomId)
+	activeKey := fmt.Sprintf(insightsTalkActiveKey, roomId)
+	field := func(metric string) string {
+		return metric + ":" + u.SpeakerId
+	}
+
+	pipe := s.rc.TxPipeline()
+	pipe.HSet(s.ctx, key, field(talkStatsUserId), u.UserId, field(talkStatsName), u.Name, field(talkStatsLabel), u.SpeakerLabel)
+	pipe.HIncrBy(s.ctx, key, field(talkStatsTalkMs), u.End-u.Start)
+	pipe.HIncrBy(s.ctx, key, field(talkStatsWords), int64(u.Words))
+	pipe.HIncrBy(s.ctx, key, field(talkStatsUtterances), 1)
+	if u.Interrupted {
+		pipe.HIncrBy(s.ctx, key, field(talkStatsInterruptions), 1)
+	}
+	pipe.Expire(s.ctx, key, DefaultTTL)
+	pipe.HSet(s.ctx, activeKey, u.UserId, fmt.Sprintf("%d:%d", u.Start, u.End))
+	pipe.Expire(s.ctx, activeKey, DefaultTTL)
+	_, err := pipe.Exec(s.ctx)
+	return err
+}
+
+// GetTalkStats returns the stats of all the speakers of the room
+func (s *RedisService) GetTalkStats(roomId string) ([]*TalkStats, error) {
+	vals, err := s.rc.HGetAll(s.ctx, fmt.Sprintf(insightsTalkStatsKey, roomId)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	speakers := make(map[string]*TalkStats)
+	var list []*TalkStats
+	for f, v := range vals {
+		metric, speakerId, ok := strings.Cut(f, ":")
+		if !ok {
+			continue
+		}
+		st, ok := speakers[speakerId]
+		if !ok {
+			st = &TalkStats{SpeakerId: speakerId}
+			speakers[speakerId] = st
+			list = append(list, st)
+		}
+
+		n, _ := strconv.ParseInt(v, 10, 64)
+		switch metric {
+		case talkStatsUserId:
+			st.UserId = v
+		case talkStatsName:
+			st.Name = v
+		case talkStatsLabel:
+			st.SpeakerLabel = v
+		case talkStatsTalkMs:
+			st.TalkMs = n
+		case talkStatsWords:
+			st.Words = n
+		case talkStatsUtterances:
+			st.Utterances = n
+		case talkStatsInterruptions:
+			st.Interruptions = n
+		}
+	}
+	return list, nil
+}
+
+// DeleteTalkStats removes the stats of the room
+func (s *RedisService) DeleteTalkStats(roomId string) {
+	_ = s.rc.Del(s.ctx, fmt.Sprintf(insightsTalkStatsKey, roomId), fmt.Sprintf(insightsTalkActiveKey, roomId)).Err()
+}
@@ -57,6 +57,7 @@ func (s *RedisService) HandleTranscriptionUsage(roomId, userId string, isStarted
 	pipe.HIncrBy(s.ctx, usageKey, userId, duration)
 	pipe.HIncrBy(s.ctx, usageKey, TotalUsageField, duration)
 	pipe.Expire(s.ctx, usageKey, 24*time.Hour)
+	s.incrInsightsTenantBudgetUsage(s.ctx, pipe, roomId, map[string]int64{InsightsBudgetTranscriptionSecondsField: duration})
 	_, err = pipe.Exec(s.ctx)
 
 	if err != nil {
@@ -66,6 +67,26 @@ func (s *RedisService) HandleTranscriptionUsage(roomId, userId string, isStarted
 	return duration, nil
 }
 
+// GetActiveTranscriptionSeconds returns the duration of the running sessions of the room,
+// which will be added to the usage once those end.
+func (s *RedisService) GetActiveTranscriptionSeconds(ctx context.Context, roomId string) (int64, error) {
+	sessions, err := s.rc.HGetAll(ctx, fmt.Sprintf(TranscriptionSessionsKey, roomId)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().Unix()
+	var total int64
+	for _, v := range sessions {
+		startTime, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || startTime > now {
+			continue
+		}
+		total += now - startTime
+	}
+	return total, nil
+}
+
 // GetTranscriptionUserUsage retrieves the transcription usage for a single user.
 func (s *RedisService) GetTranscriptionUserUsage(ctx context.Context, roomId, userId string) (int64, error) {
 	key := fmt.Sprintf(TranscriptionUsageKey, roomId)
@@ -21,6 +21,7 @@ func (s *RedisService) UpdateTTSServiceUsage(ctx context.Context, roomId, userId
 	pipe.HIncrBy(ctx, key, fmt.Sprintf("lang:%s", language), int64(incBy))
 	pipe.HIncrBy(ctx, key, TotalUsageField, int64(incBy))
 	pipe.Expire(ctx, key, time.Hour*24)
+	s.incrInsightsTenantBudgetUsage(ctx, pipe, roomId, map[string]int64{InsightsBudgetTTSCharactersField: int64(incBy)})
 	_, err := pipe.Exec(ctx)
 	return err
 }
@@ -14,8 +14,12 @@ import (
 const (
 	RoomCreationLockKey      = Prefix + "roomCreationLock-%s"
 	janitorLockKey           = Prefix + "janitorLeaderLock"
-	RecorderTaskLockKey      = Prefix + "recorderTaskLock-%s-%s" // roomID, taskType
-	MergeRecordingReqLockKey = Prefix + "mergeRecording-%s"      // roomSid
+	RecorderTaskLockKey      = Prefix + "recorderTaskLock-%s-%s"   // roomID, taskType
+	MergeRecordingReqLockKey = Prefix + "mergeRecording-%s"        // roomSid
+	TenantRoomsQuotaLockKey  = Prefix + "tenantRoomsQuotaLock-%s"  // tenantId
+	RecordingChaptersLockKey = Prefix + "recordingChaptersLock-%s" // recordingId
+	WebhookDeliveryLockKey   = Prefix + "webhookDeliveryLock-%s"   // hash of the room & url
+	ScheduleRunLockKey       = Prefix + "scheduleRunLock-%s"       // scheduleId
 )
 
 // unlockScript is a Lua script for atomic check-and-delete.
@@ -0,0 +1,57 @@
+package redisservice
+
+import (
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	ltiV1p3StateKey = Prefix + "ltiV1p3State:"
+	ltiOutcomesKey  = Prefix + "ltiOutcomes:"
+)
+
+// SaveLtiV1p3State keeps the data of an OIDC login initiation till the platform redirects back
+func (s *RedisService) SaveLtiV1p3State(state string, val []byte, ttl time.Duration) error {
+	// e.g. key: pnm:ltiV1p3State:{state}
+	return s.rc.Set(s.ctx, ltiV1p3StateKey+state, val, ttl).Err()
+}
+
+// ConsumeLtiV1p3State returns the data of the state & removes it,
+// so the same state & nonce can't be used again
+func (s *RedisService) ConsumeLtiV1p3State(state string) ([]byte, error) {
+	result, err := s.rc.GetDel(s.ctx, ltiV1p3StateKey+state).Bytes()
+	switch {
+	case errors.Is(err, redis.Nil):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// AddLtiOutcome keeps where the score of the user should be sent after the session
+func (s *RedisService) AddLtiOutcome(roomId, userId string, val []byte) error {
+	// e.g. key: pnm:ltiOutcomes:{roomId}
+	key := ltiOutcomesKey + roomId
+	pipe := s.rc.Pipeline()
+	pipe.HSet(s.ctx, key, userId, val)
+	pipe.Expire(s.ctx, key, DefaultTTL)
+	_, err := pipe.Exec(s.ctx)
+	return err
+}
+
+// GetAndDeleteLtiOutcomes returns all the outcomes of the room & removes them
+func (s *RedisService) GetAndDeleteLtiOutcomes(roomId string) (map[string]string, error) {
+	key := ltiOutcomesKey + roomId
+	pipe := s.rc.TxPipeline()
+	res := pipe.HGetAll(s.ctx, key)
+	pipe.Del(s.ctx, key)
+	if _, err := pipe.Exec(s.ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	return res.Val(), nil
+}
@@ -38,6 +38,8 @@ func (s *RedisService) ClosePoll(r *plugnmeet.ClosePollReq) error {
 		}
 
 		tx.HSet(s.ctx, key, r.PollId, string(marshal))
+		// no need to close it again by deadline
+		tx.ZRem(s.ctx, pollDeadlinesKey, r.RoomId+":"+r.PollId)
 
 		return nil
 	}, key)
@@ -55,14 +57,20 @@ func (s *RedisService) CleanUpPolls(roomId string, pollIds []string) error {
 		votedUsersKey := fmt.Sprintf("%s%s", respondentsKey, pollVotedUsersSubKey)
 		// e.g. pnm:polls:{roomId}:respondents:{pollId}:all_respondents
 		allRespondentsKey := fmt.Sprintf("%s%s", respondentsKey, pollAllResSubKey)
+		// e.g. pnm:polls:{roomId}:respondents:{pollId}:answers
+		answersKey := fmt.Sprintf("%s%s", respondentsKey, pollAnswersSubKey)
 		pp.Del(s.ctx, respondentsKey)
 		pp.Del(s.ctx, votedUsersKey)
 		pp.Del(s.ctx, allRespondentsKey)
+		pp.Del(s.ctx, answersKey)
+		pp.ZRem(s.ctx, pollDeadlinesKey, roomId+":"+id)
 	}
 
 	// e.g. pnm:polls:{roomId}
 	roomKey := pollsKey + roomId
 	pp.Del(s.ctx, roomKey)
+	// e.g. pnm:polls:{roomId}:settings
+	pp.Del(s.ctx, roomKey+pollSettingsSubKey)
 
 	_, err := pp.Exec(s.ctx)
 	if err != nil {
@@ -71,3 +79,8 @@ func (s *RedisService) CleanUpPolls(roomId string, pollIds []string) error {
 
 	return nil
 }
+
+// RemovePollDeadline removes the poll from the list of timed polls
+func (s *RedisService) RemovePollDeadline(roomId, pollId string) error {
+	return s.rc.ZRem(s.ctx, pollDeadlinesKey, roomId+":"+pollId).Err()
+}
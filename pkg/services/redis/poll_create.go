@@ -3,6 +3,8 @@ package redisservice
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/mynaparrot/plugnmeet-protocol/plugnmeet"
@@ -14,8 +16,12 @@ const (
 	pollRespondentsSubKey = ":respondents:"
 	pollVotedUsersSubKey  = ":voted_users"
 	pollAllResSubKey      = ":all_respondents"
-	PollTotalRespField    = "total_resp"
-	PollCountSuffix       = "_count"
+	pollAnswersSubKey     = ":answers"
+	pollSettingsSubKey    = ":settings"
+	// pollDeadlinesKey is a ZSET of roomId:pollId scored by the deadline of timed polls
+	pollDeadlinesKey   = Prefix + "polls_deadlines"
+	PollTotalRespField = "total_resp"
+	PollCountSuffix    = "_count"
 )
 
 func (s *RedisService) CreateRoomPoll(roomId string, val map[string]string) error {
@@ -31,10 +37,17 @@ func (s *RedisService) CreateRoomPoll(roomId string, val map[string]string) erro
 }
 
 func (s *RedisService) AddPollResponse(r *plugnmeet.SubmitPollResponseReq) error {
+	return s.AddPollResponseWithAnswer(r.RoomId, r.PollId, r.UserId, r.Name, []uint64{r.SelectedOption}, "")
+}
+
+// AddPollResponseWithAnswer records the response of the user,
+// counters of all the selected options will be increased.
+// answer is optional & will be stored as it is, e.g. the text of free-text polls.
+func (s *RedisService) AddPollResponseWithAnswer(roomId, pollId, userId, name string, selectedOptions []uint64, answer string) error {
 	// respondentsKey is the base key for a specific poll's responses.
 	// It's a HASH that stores counters like total_resp, 1_count, etc.
 	// e.g. pnm:polls:room_id:respondents:poll_id
-	respondentsKey := fmt.Sprintf("%s%s%s%s", pollsKey, r.RoomId, pollRespondentsSubKey, r.PollId)
+	respondentsKey := fmt.Sprintf("%s%s%s%s", pollsKey, roomId, pollRespondentsSubKey, pollId)
 
 	// votedUsersKey is a SET that stores the user IDs of everyone who has voted.
 	// Used for O(1) check to see if a user has already voted.
@@ -45,9 +58,13 @@ func (s *RedisService) AddPollResponse(r *plugnmeet.SubmitPollResponseReq) error
 	// e.g. pnm:polls:room_id:respondents:poll_id:all_respondents
 	allRespondentsKey := fmt.Sprintf("%s%s", respondentsKey, pollAllResSubKey)
 
+	// answersKey is a HASH that stores the answer of the user by user id.
+	// e.g. pnm:polls:room_id:respondents:poll_id:answers
+	answersKey := fmt.Sprintf("%s%s", respondentsKey, pollAnswersSubKey)
+
 	return s.rc.Watch(s.ctx, func(tx *redis.Tx) error {
 		// Check if the user has already voted using a Set for O(1) lookup.
-		voted, err := tx.SIsMember(s.ctx, votedUsersKey, r.UserId).Result()
+		voted, err := tx.SIsMember(s.ctx, votedUsersKey, userId).Result()
 		if err != nil && !errors.Is(err, redis.Nil) {
 			return err
 		}
@@ -56,24 +73,56 @@ func (s *RedisService) AddPollResponse(r *plugnmeet.SubmitPollResponseReq) error
 		}
 
 		// format userId:option_id:name
-		voteData := fmt.Sprintf("%s:%d:%s", r.UserId, r.SelectedOption, r.Name)
+		// for multiple selections, option_id will be comma separated
+		opts := make([]string, 0, len(selectedOptions))
+		for _, o := range selectedOptions {
+			opts = append(opts, strconv.FormatUint(o, 10))
+		}
+		voteData := fmt.Sprintf("%s:%s:%s", userId, strings.Join(opts, ","), name)
 
 		// Queue commands directly on the transaction object.
 		// Add user to the set of voters.
-		tx.SAdd(s.ctx, votedUsersKey, r.UserId)
+		tx.SAdd(s.ctx, votedUsersKey, userId)
 		tx.Expire(s.ctx, votedUsersKey, time.Hour*24)
 
 		// Add the vote details to a list.
 		tx.RPush(s.ctx, allRespondentsKey, voteData)
 		tx.Expire(s.ctx, allRespondentsKey, time.Hour*24)
 
+		if answer != "" {
+			tx.HSet(s.ctx, answersKey, userId, answer)
+			tx.Expire(s.ctx, answersKey, time.Hour*24)
+		}
+
 		// Increment the total response counter.
 		tx.HIncrBy(s.ctx, respondentsKey, PollTotalRespField, 1)
-		// Increment the specific option counter.
-		tx.HIncrBy(s.ctx, respondentsKey, fmt.Sprintf("%d%s", r.SelectedOption, PollCountSuffix), 1)
+		// Increment the specific option counters.
+		for _, o := range selectedOptions {
+			tx.HIncrBy(s.ctx, respondentsKey, fmt.Sprintf("%d%s", o, PollCountSuffix), 1)
+		}
 		tx.Expire(s.ctx, respondentsKey, time.Hour*24)
 		// The commands will be executed when the function returns.
 
 		return nil
 	}, votedUsersKey)
 }
+
+// SavePollSettings stores the settings of the poll.
+// If deadline is set, the poll will be added to the list of timed polls.
+func (s *RedisService) SavePollSettings(roomId, pollId, settings string, deadline int64) error {
+	// e.g. key: pnm:polls:{roomId}:settings
+	key := pollsKey + roomId + pollSettingsSubKey
+
+	pipe := s.rc.Pipeline()
+	pipe.HSet(s.ctx, key, pollId, settings)
+	pipe.Expire(s.ctx, key, time.Hour*24)
+	if deadline > 0 {
+		pipe.ZAdd(s.ctx, pollDeadlinesKey, redis.Z{
+			Score:  float64(deadline),
+			Member: roomId + ":" + pollId,
+		})
+	}
+
+	_, err := pipe.Exec(s.ctx)
+	return err
+}
@@ -3,6 +3,8 @@ package redisservice
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -91,3 +93,69 @@ func (s *RedisService) GetPollInfoByPollId(roomId, pollId string) (string, error
 
 	return result, nil
 }
+
+func (s *RedisService) GetPollSettings(roomId, pollId string) (string, error) {
+	// e.g. key: pnm:polls:{roomId}:settings
+	result, err := s.rc.HGet(s.ctx, pollsKey+roomId+pollSettingsSubKey, pollId).Result()
+
+	switch {
+	case errors.Is(err, redis.Nil):
+		return "", nil
+	case err != nil:
+		return "", err
+	}
+
+	return result, nil
+}
+
+func (s *RedisService) GetAllPollSettings(roomId string) (map[string]string, error) {
+	// e.g. key: pnm:polls:{roomId}:settings
+	result, err := s.rc.HGetAll(s.ctx, pollsKey+roomId+pollSettingsSubKey).Result()
+
+	switch {
+	case errors.Is(err, redis.Nil):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *RedisService) GetPollAnswers(roomId, pollId string) (map[string]string, error) {
+	// e.g. key: pnm:polls:{roomId}:respondents:{pollId}:answers
+	key := fmt.Sprintf("%s%s%s%s%s", pollsKey, roomId, pollRespondentsSubKey, pollId, pollAnswersSubKey)
+	result, err := s.rc.HGetAll(s.ctx, key).Result()
+
+	switch {
+	case errors.Is(err, redis.Nil):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetExpiredPollDeadlines returns roomId & pollId pairs of the timed polls
+// which deadline has passed
+func (s *RedisService) GetExpiredPollDeadlines(until int64) ([][2]string, error) {
+	result, err := s.rc.ZRangeByScore(s.ctx, pollDeadlinesKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(until, 10),
+	}).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	list := make([][2]string, 0, len(result))
+	for _, m := range result {
+		// format roomId:pollId, poll id won't contain colon
+		i := strings.LastIndex(m, ":")
+		if i < 1 {
+			continue
+		}
+		list = append(list, [2]string{m[:i], m[i+1:]})
+	}
+	return list, nil
+}
@@ -0,0 +1,101 @@
+package redisservice
+
+import (
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	recorderQueueKey        = Prefix + "recorderQueue"        // SORTED SET of the queued tasks, score: enqueue time in ms
+	recorderReservationsKey = Prefix + "recorderReservations" // A single HASH key, field: roomId, value: recorderId
+)
+
+// AddToRecorderQueue adds the task to the queue, returns false if it was already queued
+func (s *RedisService) AddToRecorderQueue(member string, enqueuedAt int64) (bool, error) {
+	added, err := s.rc.ZAddNX(s.ctx, recorderQueueKey, redis.Z{
+		Score:  float64(enqueuedAt),
+		Member: member,
+	}).Result()
+	if err != nil {
+		return false, err
+	}
+	return added > 0, nil
+}
+
+// GetRecorderQueueEnqueuedAt returns the enqueue time in ms of the task, 0 if it isn't in the queue anymore
+func (s *RedisService) GetRecorderQueueEnqueuedAt(member string) (int64, error) {
+	score, err := s.rc.ZScore(s.ctx, recorderQueueKey, member).Result()
+	switch {
+	case errors.Is(err, redis.Nil):
+		return 0, nil
+	case err != nil:
+		return 0, err
+	}
+	return int64(score), nil
+}
+
+// GetRecorderQueue returns all the queued tasks in order
+func (s *RedisService) GetRecorderQueue() ([]string, error) {
+	return s.rc.ZRange(s.ctx, recorderQueueKey, 0, -1).Result()
+}
+
+// RemoveFromRecorderQueue returns the number of removed tasks
+func (s *RedisService) RemoveFromRecorderQueue(members ...string) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	return s.rc.ZRem(s.ctx, recorderQueueKey, args...).Result()
+}
+
+// reserveRecorderScript sets the reservation only if the recorder has still a free slot,
+// counting the reservations of the other rooms.
+// KEYS[1]: reservations key, ARGV: roomId, recorderId, maxLimit, currentProgress, ttl in seconds
+const reserveRecorderScript = `
+local reserved = 0
+local all = redis.call("HGETALL", KEYS[1])
+for i = 1, #all, 2 do
+    if all[i] ~= ARGV[1] and all[i + 1] == ARGV[2] then
+        reserved = reserved + 1
+    end
+end
+local maxLimit = tonumber(ARGV[3])
+if maxLimit > 0 and tonumber(ARGV[4]) + reserved >= maxLimit then
+    return 0
+end
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+redis.call("HEXPIRE", KEYS[1], ARGV[5], "FIELDS", 1, ARGV[1])
+return 1
+`
+
+// ReserveRecorder atomically reserves a slot of the recorder for the room,
+// returns false if the recorder has no free slot anymore. maxLimit 0 means unlimited.
+func (s *RedisService) ReserveRecorder(roomId, recorderId string, maxLimit, currentProgress int64) (bool, error) {
+	res, err := s.reserveRecorderScriptExec.Run(s.ctx, s.rc, []string{recorderReservationsKey}, roomId, recorderId, maxLimit, currentProgress, int64(DefaultTTL.Seconds())).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+// SetRecorderReservation reserves a slot of the recorder for the room
+func (s *RedisService) SetRecorderReservation(roomId, recorderId string) error {
+	pipe := s.rc.Pipeline()
+	pipe.HSet(s.ctx, recorderReservationsKey, roomId, recorderId)
+	pipe.HExpire(s.ctx, recorderReservationsKey, DefaultTTL, roomId)
+	_, err := pipe.Exec(s.ctx)
+	return err
+}
+
+// GetRecorderReservations returns all the reservations as roomId => recorderId
+func (s *RedisService) GetRecorderReservations() (map[string]string, error) {
+	return s.rc.HGetAll(s.ctx, recorderReservationsKey).Result()
+}
+
+func (s *RedisService) DeleteRecorderReservation(roomId string) error {
+	return s.rc.HDel(s.ctx, recorderReservationsKey, roomId).Err()
+}
@@ -0,0 +1,114 @@
+package redisservice
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	recordingWindowsKey  = Prefix + "recording_windows:%s"  // A HASH for each room
+	recordingChaptersKey = Prefix + "recording_chapters:%s" // A STRING for each recording
+	// transcoding of a long recording may take a while
+	recordingChaptersTTL = time.Hour * 72
+
+	recordingWindowStartField = ":start"
+	recordingWindowEndField   = ":end"
+)
+
+// RecordingWindow is the time range of a recording in unix milliseconds,
+// End will be 0 if the recording has not ended yet.
+type RecordingWindow struct {
+	RecordingId string
+	Start       int64
+	End         int64
+}
+
+// SetRecordingWindowStart keeps the start time of the recording in the room
+func (s *RedisService) SetRecordingWindowStart(roomId, recordingId string, ts int64) error {
+	return s.setRecordingWindowField(roomId, recordingId+recordingWindowStartField, ts)
+}
+
+// SetRecordingWindowEnd keeps the end time of the recording in the room
+func (s *RedisService) SetRecordingWindowEnd(roomId, recordingId string, ts int64) error {
+	return s.setRecordingWindowField(roomId, recordingId+recordingWindowEndField, ts)
+}
+
+func (s *RedisService) setRecordingWindowField(roomId, field string, ts int64) error {
+	key := fmt.Sprintf(recordingWindowsKey, roomId)
+	pipe := s.rc.Pipeline()
+	pipe.HSet(s.ctx, key, field, ts)
+	pipe.Expire(s.ctx, key, DefaultTTL)
+	_, err := pipe.Exec(s.ctx)
+	return err
+}
+
+// GetRecordingWindows returns all the started recordings of the room
+func (s *RedisService) GetRecordingWindows(roomId string) ([]*RecordingWindow, error) {
+	result, err := s.rc.HGetAll(s.ctx, fmt.Sprintf(recordingWindowsKey, roomId)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	windows := make(map[string]*RecordingWindow)
+	for field, val := range result {
+		ts, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			continue
+		}
+		recordingId, isStart := strings.CutSuffix(field, recordingWindowStartField)
+		if !isStart {
+			recordingId = strings.TrimSuffix(field, recordingWindowEndField)
+		}
+		w, ok := windows[recordingId]
+		if !ok {
+			w = &RecordingWindow{RecordingId: recordingId}
+			windows[recordingId] = w
+		}
+		if isStart {
+			w.Start = ts
+		} else {
+			w.End = ts
+		}
+	}
+
+	list := make([]*RecordingWindow, 0, len(windows))
+	for _, w := range windows {
+		if w.Start > 0 {
+			list = append(list, w)
+		}
+	}
+	return list, nil
+}
+
+// DeleteRecordingWindows removes the recording windows of the room
+func (s *RedisService) DeleteRecordingWindows(roomId string) {
+	_ = s.rc.Del(s.ctx, fmt.Sprintf(recordingWindowsKey, roomId)).Err()
+}
+
+// SaveRecordingChapters keeps the generated chapters until the recording was proceeded
+func (s *RedisService) SaveRecordingChapters(recordingId string, data []byte) error {
+	return s.rc.Set(s.ctx, fmt.Sprintf(recordingChaptersKey, recordingId), data, recordingChaptersTTL).Err()
+}
+
+// GetRecordingChapters returns the generated chapters of the recording.
+// It returns nil if nothing was generated.
+func (s *RedisService) GetRecordingChapters(recordingId string) ([]byte, error) {
+	data, err := s.rc.Get(s.ctx, fmt.Sprintf(recordingChaptersKey, recordingId)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// DeleteRecordingChapters removes the chapters after those were attached to the recording
+func (s *RedisService) DeleteRecordingChapters(recordingId string) error {
+	return s.rc.Del(s.ctx, fmt.Sprintf(recordingChaptersKey, recordingId)).Err()
+}
@@ -0,0 +1,31 @@
+package redisservice
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const recordingExpiringNotifiedKey = Prefix + "recording_expiring_notified:%s"
+
+// MarkRecordingExpiringNotified keeps the time of the recording_expiring notification,
+// returns true if the notification hasn't been marked as sent before
+func (s *RedisService) MarkRecordingExpiringNotified(recordId string, notifiedAt time.Time, ttl time.Duration) (bool, error) {
+	return s.rc.SetNX(s.ctx, fmt.Sprintf(recordingExpiringNotifiedKey, recordId), notifiedAt.Unix(), ttl).Result()
+}
+
+// GetRecordingExpiringNotifiedAt returns the unix time of the recording_expiring notification, 0 if not sent yet
+func (s *RedisService) GetRecordingExpiringNotifiedAt(recordId string) (int64, error) {
+	notifiedAt, err := s.rc.Get(s.ctx, fmt.Sprintf(recordingExpiringNotifiedKey, recordId)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return notifiedAt, err
+}
+
+// DeleteRecordingExpiringNotified removes the mark of the notification
+func (s *RedisService) DeleteRecordingExpiringNotified(recordId string) error {
+	return s.rc.Del(s.ctx, fmt.Sprintf(recordingExpiringNotifiedKey, recordId)).Err()
+}
@@ -0,0 +1,45 @@
+package redisservice
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	recordingTokenUsesKey   = Prefix + "recording_token_uses:%s"
+	recordingTokenAccessKey = Prefix + "recording_token_access:%s:%s" // token id & client
+)
+
+// IncrementRecordingTokenUses increments & returns the number of uses of the download token,
+// ttl should be the remaining validity of the token
+func (s *RedisService) IncrementRecordingTokenUses(tokenId string, ttl time.Duration) (int64, error) {
+	key := fmt.Sprintf(recordingTokenUsesKey, tokenId)
+	pipe := s.rc.TxPipeline()
+	incr := pipe.Incr(s.ctx, key)
+	pipe.Expire(s.ctx, key, ttl)
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// MarkRecordingTokenAccess returns true if it is a new access of the client using the token.
+// The following requests within the window, e.g. range requests or HLS segments,
+// will extend the window & return false.
+func (s *RedisService) MarkRecordingTokenAccess(tokenId, client string, window time.Duration) (bool, error) {
+	key := fmt.Sprintf(recordingTokenAccessKey, tokenId, client)
+	isNew, err := s.rc.SetNX(s.ctx, key, 1, window).Result()
+	if err != nil {
+		return false, err
+	}
+	if !isNew {
+		_ = s.rc.Expire(s.ctx, key, window).Err()
+	}
+	return isNew, nil
+}
+
+// UnmarkRecordingTokenAccess removes the access of the client,
+// so the next request will be checked as a new access
+func (s *RedisService) UnmarkRecordingTokenAccess(tokenId, client string) error {
+	return s.rc.Del(s.ctx, fmt.Sprintf(recordingTokenAccessKey, tokenId, client)).Err()
+}
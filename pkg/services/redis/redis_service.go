@@ -16,11 +16,13 @@ const (
 )
 
 type RedisService struct {
-	ctx              context.Context
-	rc               *redis.Client
-	unlockScriptExec *redis.Script
-	renewScriptExec  *redis.Script
-	logger           *logrus.Entry
+	ctx                          context.Context
+	rc                           *redis.Client
+	unlockScriptExec             *redis.Script
+	renewScriptExec              *redis.Script
+	reserveRecorderScriptExec    *redis.Script
+	startTalkUtteranceScriptExec *redis.Script
+	logger                       *logrus.Entry
 }
 
 type Args struct {
@@ -32,11 +34,13 @@ type Args struct {
 
 func New(args Args) *RedisService {
 	return &RedisService{
-		ctx:              args.Ctx,
-		rc:               args.Rc,
-		unlockScriptExec: redis.NewScript(unlockScript),
-		renewScriptExec:  redis.NewScript(renewScript),
-		logger:           args.Logger.WithField("service", "redis"),
+		ctx:                          args.Ctx,
+		rc:                           args.Rc,
+		unlockScriptExec:             redis.NewScript(unlockScript),
+		renewScriptExec:              redis.NewScript(renewScript),
+		reserveRecorderScriptExec:    redis.NewScript(reserveRecorderScript),
+		startTalkUtteranceScriptExec: redis.NewScript(startTalkUtteranceScript),
+		logger:                       args.Logger.WithField("service", "redis"),
 	}
 }
 
@@ -0,0 +1,153 @@
+package storageservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/mynaparrot/plugnmeet-protocol/hooks"
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/storage"
+	"github.com/mynaparrot/plugnmeet-server/pkg/storage/local"
+	"github.com/mynaparrot/plugnmeet-server/pkg/storage/s3"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/fx"
+)
+
+// StorageService gives access to the configured storage driver
+// for recordings, artifacts and uploaded files.
+type StorageService struct {
+	conf       *config.StorageConfig
+	recordings storage.Storage
+	artifacts  storage.Storage
+	uploads    storage.Storage
+	logger     *logrus.Entry
+}
+
+type Args struct {
+	fx.In
+	App    *config.AppConfig
+	Logger *logrus.Logger
+}
+
+func New(args Args) (*StorageService, error) {
+	app := args.App
+	s := &StorageService{
+		conf:   app.Storage,
+		logger: args.Logger.WithField("service", "storage"),
+	}
+
+	switch s.conf.Driver {
+	case config.StorageDriverLocal:
+		s.recordings = local.NewLocalStorage(app.RecorderInfo.RecordingFilesPath)
+		s.artifacts = local.NewLocalStorage(*app.ArtifactsSettings.StoragePath)
+		s.uploads = local.NewLocalStorage(app.UploadFileSettings.Path)
+	case config.StorageDriverS3:
+		var err error
+		if s.recordings, err = s3.NewS3Storage(s.conf.S3, "recordings"); err != nil {
+			return nil, err
+		}
+		if s.artifacts, err = s3.NewS3Storage(s.conf.S3, "artifacts"); err != nil {
+			return nil, err
+		}
+		if s.uploads, err = s3.NewS3Storage(s.conf.S3, "uploads"); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown storage driver: '%s'", s.conf.Driver)
+	}
+
+	s.logger.Infof("using '%s' storage driver", s.conf.Driver)
+	return s, nil
+}
+
+// IsLocal returns true if files are kept in the local paths,
+// in that case files are already in place & nothing to upload
+func (s *StorageService) IsLocal() bool {
+	return s.conf.Driver == config.StorageDriverLocal
+}
+
+func (s *StorageService) Recordings() storage.Storage {
+	return s.recordings
+}
+
+func (s *StorageService) Artifacts() storage.Storage {
+	return s.artifacts
+}
+
+func (s *StorageService) Uploads() storage.Storage {
+	return s.uploads
+}
+
+// PutFile uploads a local file to the storage under key,
+// content type will be detected if empty
+func (s *StorageService) PutFile(ctx context.Context, st storage.Storage, key, localPath, contentType string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if contentType == "" {
+		if mType, err := mimetype.DetectFile(localPath); err == nil {
+			contentType = mType.String()
+		}
+	}
+
+	return st.Put(ctx, key, f, stat.Size(), contentType)
+}
+
+// GetFile downloads the object to the local path
+func (s *StorageService) GetFile(ctx context.Context, st storage.Storage, key, localPath string) error {
+	r, _, err := st.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		_ = os.Remove(localPath)
+		return err
+	}
+	return nil
+}
+
+// GetDownloadHookData returns the redirect information for the stored object
+// in the same format as download hooks, so controllers can handle both the same way
+func (s *StorageService) GetDownloadHookData(ctx context.Context, st storage.Storage, key string) (*hooks.DownloadHookData, error) {
+	info, err := st.Stat(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, config.ErrFileNotFound
+		}
+		return nil, err
+	}
+
+	u, err := st.PresignedURL(ctx, key, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &hooks.DownloadHookData{
+		Action:      hooks.DownloadHookDataActionRedirect,
+		RedirectUrl: u,
+		MimeType:    info.ContentType,
+	}, nil
+}
@@ -0,0 +1,133 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/mynaparrot/plugnmeet-server/pkg/storage"
+)
+
+// LocalStorage keeps the objects as regular files under the root directory.
+type LocalStorage struct {
+	root string
+}
+
+func NewLocalStorage(root string) *LocalStorage {
+	return &LocalStorage{
+		root: filepath.Clean(root),
+	}
+}
+
+// Path returns the absolute file path for the key
+func (s *LocalStorage) Path(key string) (string, error) {
+	key, err := storage.CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+func (s *LocalStorage) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	p, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// write to a temporary file first, so a reader will never get a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), p)
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, *storage.ObjectInfo, error) {
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	p, _ := s.Path(key)
+
+	f, err := os.Open(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, storage.ErrObjectNotFound
+		}
+		return nil, nil, err
+	}
+	return f, info, nil
+}
+
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	p, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) DeletePrefix(_ context.Context, prefix string) error {
+	p, err := s.Path(prefix)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(p)
+}
+
+func (s *LocalStorage) Stat(_ context.Context, key string) (*storage.ObjectInfo, error) {
+	p, err := s.Path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := os.Stat(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, storage.ErrObjectNotFound
+		}
+		return nil, err
+	}
+	if st.IsDir() {
+		return nil, storage.ErrObjectNotFound
+	}
+
+	info := &storage.ObjectInfo{
+		Key:          key,
+		Size:         st.Size(),
+		LastModified: st.ModTime(),
+	}
+	if mType, err := mimetype.DetectFile(p); err == nil {
+		info.ContentType = mType.String()
+	}
+	return info, nil
+}
+
+// PresignedURL isn't possible for local files, those will be served by plugNmeet itself
+func (s *LocalStorage) PresignedURL(_ context.Context, _ string, _ time.Duration) (string, error) {
+	return "", storage.ErrPresignNotSupported
+}
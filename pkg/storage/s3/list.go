@@ -0,0 +1,89 @@
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/storage"
+)
+
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// DeletePrefix removes all the objects under the prefix using ListObjectsV2
+func (s *S3Storage) DeletePrefix(ctx context.Context, prefix string) error {
+	prefix, err := storage.CleanKey(prefix)
+	if err != nil {
+		return err
+	}
+	fullPrefix := prefix + "/"
+	if s.prefix != "" {
+		fullPrefix = s.prefix + "/" + fullPrefix
+	}
+
+	var token string
+	for {
+		result, err := s.listObjects(ctx, fullPrefix, token)
+		if err != nil {
+			return err
+		}
+		for _, c := range result.Contents {
+			key := c.Key
+			if s.prefix != "" {
+				key = strings.TrimPrefix(key, s.prefix+"/")
+			}
+			if err := s.Delete(ctx, key); err != nil {
+				return err
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *S3Storage) listObjects(ctx context.Context, prefix, token string) (*listBucketResult, error) {
+	u := *s.endpoint
+	if s.conf.UsePathStyle {
+		u.Path = strings.TrimRight(u.Path, "/") + "/" + s.conf.Bucket
+	} else {
+		u.Host = s.conf.Bucket + "." + u.Host
+		u.Path = strings.TrimRight(u.Path, "/") + "/"
+	}
+	q := url.Values{}
+	q.Set("list-type", "2")
+	q.Set("prefix", prefix)
+	if token != "" {
+		q.Set("continuation-token", token)
+	}
+	u.RawQuery = buildCanonicalQuery(q)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if err := checkResponse(res); err != nil {
+		return nil, err
+	}
+
+	result := new(listBucketResult)
+	if err := xml.NewDecoder(res.Body).Decode(result); err != nil {
+		return nil, fmt.Errorf("failed to decode list objects response: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,210 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// objects up to this size will be uploaded using a single PUT
+	multipartThreshold = 64 << 20
+	// S3 requires at least 5MB for every part except the last one
+	minPartSize = 16 << 20
+	maxParts    = 10000
+)
+
+type initiateMultipartUploadResult struct {
+	UploadId string `xml:"UploadId"`
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+type s3Error struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string   `xml:"Code"`
+	Message string   `xml:"Message"`
+}
+
+// partSizeFor returns the size of the parts, so the object fits in maxParts.
+// For unknown size, minPartSize will be used.
+func partSizeFor(size int64) int64 {
+	if size <= 0 {
+		return minPartSize
+	}
+	return max(minPartSize, (size+maxParts-1)/maxParts)
+}
+
+// putMultipart uploads the content in fixed-size parts, so only one part will be kept in memory.
+// The first part may be already read by the caller.
+func (s *S3Storage) putMultipart(ctx context.Context, key string, first []byte, r io.Reader, size int64, contentType string) error {
+	uploadId, err := s.createMultipartUpload(ctx, key, contentType)
+	if err != nil {
+		return err
+	}
+
+	parts, err := s.uploadParts(ctx, key, uploadId, first, r, partSizeFor(size))
+	if err == nil {
+		err = s.completeMultipartUpload(ctx, key, uploadId, parts)
+	}
+	if err != nil {
+		// the context may be cancelled already, otherwise the parts would be kept in the bucket
+		abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+		if abortErr := s.abortMultipartUpload(abortCtx, key, uploadId); abortErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to abort multipart upload: %w", abortErr))
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *S3Storage) uploadParts(ctx context.Context, key, uploadId string, first []byte, r io.Reader, partSize int64) ([]completedPart, error) {
+	var parts []completedPart
+	buf := make([]byte, partSize)
+
+	for num := 1; ; num++ {
+		if num > maxParts {
+			return nil, fmt.Errorf("object is too large, more than %d parts", maxParts)
+		}
+		// the buffer of the caller may be smaller than a part
+		n := copy(buf, first)
+		first = first[n:]
+		read, err := io.ReadFull(r, buf[n:])
+		n += read
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		if n == 0 && num > 1 {
+			return parts, nil
+		}
+
+		etag, err := s.uploadPart(ctx, key, uploadId, num, buf[:n])
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, completedPart{PartNumber: num, ETag: etag})
+
+		if n < len(buf) {
+			return parts, nil
+		}
+	}
+}
+
+func (s *S3Storage) createMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	req, err := s.newMultipartRequest(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return "", err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	res, err := s.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if err := checkResponse(res); err != nil {
+		return "", err
+	}
+
+	result := new(initiateMultipartUploadResult)
+	if err := xml.NewDecoder(res.Body).Decode(result); err != nil {
+		return "", fmt.Errorf("failed to decode multipart upload result: %w", err)
+	}
+	if result.UploadId == "" {
+		return "", fmt.Errorf("storage didn't return upload id")
+	}
+	return result.UploadId, nil
+}
+
+func (s *S3Storage) uploadPart(ctx context.Context, key, uploadId string, num int, data []byte) (string, error) {
+	q := url.Values{"partNumber": {strconv.Itoa(num)}, "uploadId": {uploadId}}
+	req, err := s.newMultipartRequest(ctx, http.MethodPut, key, q, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = int64(len(data))
+
+	res, err := s.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if err := checkResponse(res); err != nil {
+		return "", err
+	}
+	return res.Header.Get("ETag"), nil
+}
+
+func (s *S3Storage) completeMultipartUpload(ctx context.Context, key, uploadId string, parts []completedPart) error {
+	body, err := xml.Marshal(completeMultipartUpload{Parts: parts})
+	if err != nil {
+		return err
+	}
+	req, err := s.newMultipartRequest(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadId}}, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Type", "application/xml")
+
+	res, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if err := checkResponse(res); err != nil {
+		return err
+	}
+
+	// S3 may return 200 OK with an error in the body
+	msg, err := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+	e := new(s3Error)
+	if xml.Unmarshal(msg, e) == nil && e.Code != "" {
+		return fmt.Errorf("failed to complete multipart upload: %s: %s", e.Code, e.Message)
+	}
+	return nil
+}
+
+func (s *S3Storage) abortMultipartUpload(ctx context.Context, key, uploadId string) error {
+	req, err := s.newMultipartRequest(ctx, http.MethodDelete, key, url.Values{"uploadId": {uploadId}}, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return checkResponse(res)
+}
+
+func (s *S3Storage) newMultipartRequest(ctx context.Context, method, key string, q url.Values, body io.Reader) (*http.Request, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = buildCanonicalQuery(q)
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
@@ -0,0 +1,216 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mynaparrot/plugnmeet-server/pkg/config"
+	"github.com/mynaparrot/plugnmeet-server/pkg/storage"
+)
+
+// S3Storage is a minimal client for any S3 compatible storage.
+// It only implements the few calls we need, so we don't have to pull the full SDK.
+type S3Storage struct {
+	conf       *config.S3StorageConfig
+	endpoint   *url.URL
+	prefix     string
+	httpClient *http.Client
+}
+
+// NewS3Storage returns a storage for the bucket, all keys will be placed under prefix
+func NewS3Storage(conf *config.S3StorageConfig, prefix string) (*S3Storage, error) {
+	u, err := url.Parse(strings.TrimRight(conf.Endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint '%s', must be like https://s3.amazonaws.com", conf.Endpoint)
+	}
+
+	return &S3Storage{
+		conf:     conf,
+		endpoint: u,
+		prefix:   strings.Trim(path.Join(conf.Prefix, prefix), "/"),
+		httpClient: &http.Client{
+			Timeout: 0, // uploads of recordings can take long, we'll rely on context instead
+		},
+	}, nil
+}
+
+// Put uploads the object, large objects or objects of unknown size
+// will be uploaded in parts as a single PUT is limited to 5GB
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if size > multipartThreshold {
+		return s.putMultipart(ctx, key, nil, r, size, contentType)
+	}
+	if size < 0 {
+		// S3 requires content length, read up to the threshold to decide
+		buf, err := io.ReadAll(io.LimitReader(r, multipartThreshold+1))
+		if err != nil {
+			return err
+		}
+		if len(buf) > multipartThreshold {
+			return s.putMultipart(ctx, key, buf, r, size, contentType)
+		}
+		r = bytes.NewReader(buf)
+		size = int64(len(buf))
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	res, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return checkResponse(res)
+}
+
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, *storage.ObjectInfo, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := s.do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkResponse(res); err != nil {
+		res.Body.Close()
+		return nil, nil, err
+	}
+
+	return res.Body, toObjectInfo(key, res), nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	err = checkResponse(res)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (s *S3Storage) Stat(ctx context.Context, key string) (*storage.ObjectInfo, error) {
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if err := checkResponse(res); err != nil {
+		return nil, err
+	}
+	return toObjectInfo(key, res), nil
+}
+
+func (s *S3Storage) PresignedURL(_ context.Context, key string, expiry time.Duration) (string, error) {
+	if expiry <= 0 {
+		expiry = s.conf.PresignedUrlExpiry
+	}
+	u, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+
+	s.presign(u, expiry, time.Now().UTC())
+	return u.String(), nil
+}
+
+func (s *S3Storage) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
+
+func (s *S3Storage) do(req *http.Request) (*http.Response, error) {
+	s.sign(req, time.Now().UTC())
+	return s.httpClient.Do(req)
+}
+
+// objectURL builds the url of the object based on path or virtual host style
+func (s *S3Storage) objectURL(key string) (*url.URL, error) {
+	key, err := storage.CleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if s.prefix != "" {
+		key = s.prefix + "/" + key
+	}
+
+	u := *s.endpoint
+	if s.conf.UsePathStyle {
+		u.Path = strings.TrimRight(u.Path, "/") + "/" + s.conf.Bucket + "/" + key
+	} else {
+		u.Host = s.conf.Bucket + "." + u.Host
+		u.Path = strings.TrimRight(u.Path, "/") + "/" + key
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	return &u, nil
+}
+
+func toObjectInfo(key string, res *http.Response) *storage.ObjectInfo {
+	info := &storage.ObjectInfo{
+		Key:         key,
+		Size:        res.ContentLength,
+		ContentType: res.Header.Get("Content-Type"),
+	}
+	if v := res.Header.Get("Content-Length"); v != "" && info.Size < 0 {
+		info.Size, _ = strconv.ParseInt(v, 10, 64)
+	}
+	if t, err := http.ParseTime(res.Header.Get("Last-Modified")); err == nil {
+		info.LastModified = t
+	}
+	return info
+}
+
+func checkResponse(res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+	if res.StatusCode == http.StatusNotFound {
+		return storage.ErrObjectNotFound
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return fmt.Errorf("s3 request failed with status %d: %s", res.StatusCode, strings.TrimSpace(string(msg)))
+}
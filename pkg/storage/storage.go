@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrObjectNotFound      = errors.New("object not found")
+	ErrPresignNotSupported = errors.New("presigned url is not supported by this storage driver")
+	ErrInvalidKey          = errors.New("invalid object key")
+)
+
+// ObjectInfo holds basic information about a stored object.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Storage is the master interface for all storage drivers.
+// Keys are always relative, slash separated paths, e.g. roomSid/file.pdf
+type Storage interface {
+	// Put stores the content of r under key, size can be -1 if unknown
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get returns the content of the object, caller must close the reader
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix removes all the objects under the prefix, e.g. roomSid
+	DeletePrefix(ctx context.Context, prefix string) error
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// PresignedURL returns a temporary url to download the object directly from the storage
+	PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// CleanKey normalizes the key & prevents path traversal
+func CleanKey(key string) (string, error) {
+	key = strings.ReplaceAll(key, "\\", "/")
+	for _, seg := range strings.Split(key, "/") {
+		if seg == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	key = strings.TrimPrefix(path.Clean("/"+key), "/")
+	if key == "" {
+		return "", ErrInvalidKey
+	}
+	return key, nil
+}
@@ -0,0 +1,28 @@
+package storage
+
+import "testing"
+
+func TestCleanKey(t *testing.T) {
+	for key, want := range map[string]string{
+		"room01/my..file.pdf": "room01/my..file.pdf",
+		"/room01//file.pdf":   "room01/file.pdf",
+		"room01\\file.pdf":    "room01/file.pdf",
+		"room01/./file.pdf":   "room01/file.pdf",
+		"../etc/passwd":       "",
+		"room01/../file.pdf":  "",
+		"room01\\..\\file":    "",
+		"..":                  "",
+		"":                    "",
+	} {
+		got, err := CleanKey(key)
+		if want == "" {
+			if err == nil {
+				t.Errorf("%q should be rejected, got %q", key, got)
+			}
+			continue
+		}
+		if err != nil || got != want {
+			t.Errorf("%q: expected %q, got %q %v", key, want, got, err)
+		}
+	}
+}